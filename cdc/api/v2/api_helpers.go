@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tiflow/cdc/owner"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columnselector"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columntransformer"
 	"github.com/pingcap/tiflow/cdc/sink/validator"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
@@ -556,7 +557,15 @@ func (h APIV2HelpersImpl) getVerifiedTables(
 		return nil, nil, err
 	}
 
-	if !sink.IsMQScheme(scheme) {
+	transformer, err := columntransformer.New(replicaConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !sink.IsMQScheme(scheme) && !sink.IsRedisScheme(scheme) {
+		err = transformer.VerifyTables(tableInfos, nil)
+		if err != nil {
+			return nil, nil, err
+		}
 		return ineligibleTables, eligibleTables, nil
 	}
 
@@ -564,6 +573,10 @@ func (h APIV2HelpersImpl) getVerifiedTables(
 	if err != nil {
 		return nil, nil, err
 	}
+	err = transformer.VerifyTables(tableInfos, eventRouter)
+	if err != nil {
+		return nil, nil, err
+	}
 	err = eventRouter.VerifyTables(tableInfos)
 	if err != nil {
 		return nil, nil, err
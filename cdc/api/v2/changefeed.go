@@ -563,6 +563,17 @@ func (h *OpenAPIV2) getChangeFeed(c *gin.Context) {
 	}
 	detail := toAPIModel(cfInfo, status.ResolvedTs,
 		status.CheckpointTs, taskStatus, true)
+	if cfInfo.State == model.StateNormal {
+		for _, p := range status.SlowPartitions {
+			detail.SlowPartitions = append(detail.SlowPartitions, SlowPartition{
+				CaptureID: p.CaptureID,
+				Topic:     p.Topic,
+				Partition: p.Partition,
+				Since:     model.JSONTime(p.Since),
+			})
+		}
+		detail.ConflictCount = status.ConflictCount
+	}
 	c.JSON(http.StatusOK, detail)
 }
 
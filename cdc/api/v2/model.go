@@ -259,12 +259,19 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 			UseFileBackend:        c.Consistent.UseFileBackend,
 			Compression:           c.Consistent.Compression,
 			FlushConcurrency:      c.Consistent.FlushConcurrency,
+			RetentionInMs:         c.Consistent.RetentionInMs,
 		}
 		if c.Consistent.MemoryUsage != nil {
 			res.Consistent.MemoryUsage = &config.ConsistentMemoryUsage{
 				MemoryQuotaPercentage: c.Consistent.MemoryUsage.MemoryQuotaPercentage,
 			}
 		}
+		if c.Consistent.Compaction != nil {
+			res.Consistent.Compaction = &config.ConsistentCompaction{
+				IntervalInMs:   c.Consistent.Compaction.IntervalInMs,
+				MaxSegmentSize: c.Consistent.Compaction.MaxSegmentSize,
+			}
+		}
 	}
 	if c.Sink != nil {
 		var dispatchRules []*config.DispatchRule
@@ -276,6 +283,10 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 				IndexName:      rule.IndexName,
 				Columns:        rule.Columns,
 				TopicRule:      rule.TopicRule,
+				DDLTopic:       rule.DDLTopic,
+				AllowedTopics:  rule.AllowedTopics,
+				OverflowTopic:  rule.OverflowTopic,
+				MaxTopics:      rule.MaxTopics,
 			})
 		}
 		var columnSelectors []*config.ColumnSelector
@@ -285,6 +296,17 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 				Columns: selector.Columns,
 			})
 		}
+		var columnTransformers []*config.ColumnTransformer
+		for _, rule := range c.Sink.ColumnTransformers {
+			columnTransformers = append(columnTransformers, &config.ColumnTransformer{
+				Matcher: rule.Matcher,
+				Columns: rule.Columns,
+				Action:  rule.Action,
+				Salt:    rule.Salt,
+				Length:  rule.Length,
+				NewName: rule.NewName,
+			})
+		}
 		var csvConfig *config.CSVConfig
 		if c.Sink.CSVConfig != nil {
 			csvConfig = &config.CSVConfig{
@@ -318,6 +340,7 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 				AuthTLSCertificatePath:  c.Sink.PulsarConfig.AuthTLSCertificatePath,
 				AuthTLSPrivateKeyPath:   c.Sink.PulsarConfig.AuthTLSPrivateKeyPath,
 				OutputRawChangeEvent:    c.Sink.PulsarConfig.OutputRawChangeEvent,
+				BatcherBuilder:          (*config.PulsarBatcherBuilder)(c.Sink.PulsarConfig.BatcherBuilder),
 			}
 			if c.Sink.PulsarConfig.OAuth2 != nil {
 				pulsarConfig.OAuth2 = &config.OAuth2{
@@ -328,6 +351,17 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 					OAuth2Scope:      c.Sink.PulsarConfig.OAuth2.OAuth2Scope,
 				}
 			}
+			if c.Sink.PulsarConfig.LargeMessageHandle != nil {
+				oldConfig := c.Sink.PulsarConfig.LargeMessageHandle
+				pulsarConfig.LargeMessageHandle = &config.LargeMessageHandleConfig{
+					LargeMessageHandleOption:      oldConfig.LargeMessageHandleOption,
+					LargeMessageHandleCompression: oldConfig.LargeMessageHandleCompression,
+					ClaimCheckStorageURI:          oldConfig.ClaimCheckStorageURI,
+					ClaimCheckRawValue:            oldConfig.ClaimCheckRawValue,
+					ClaimCheckRetention:           oldConfig.ClaimCheckRetention,
+					ClaimCheckCleanupCronSpec:     oldConfig.ClaimCheckCleanupCronSpec,
+				}
+			}
 		}
 
 		var kafkaConfig *config.KafkaConfig
@@ -353,6 +387,8 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 					LargeMessageHandleCompression: oldConfig.LargeMessageHandleCompression,
 					ClaimCheckStorageURI:          oldConfig.ClaimCheckStorageURI,
 					ClaimCheckRawValue:            oldConfig.ClaimCheckRawValue,
+					ClaimCheckRetention:           oldConfig.ClaimCheckRetention,
+					ClaimCheckCleanupCronSpec:     oldConfig.ClaimCheckCleanupCronSpec,
 				}
 			}
 
@@ -368,43 +404,48 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 			}
 
 			kafkaConfig = &config.KafkaConfig{
-				PartitionNum:                 c.Sink.KafkaConfig.PartitionNum,
-				ReplicationFactor:            c.Sink.KafkaConfig.ReplicationFactor,
-				KafkaVersion:                 c.Sink.KafkaConfig.KafkaVersion,
-				MaxMessageBytes:              c.Sink.KafkaConfig.MaxMessageBytes,
-				Compression:                  c.Sink.KafkaConfig.Compression,
-				KafkaClientID:                c.Sink.KafkaConfig.KafkaClientID,
-				AutoCreateTopic:              c.Sink.KafkaConfig.AutoCreateTopic,
-				DialTimeout:                  c.Sink.KafkaConfig.DialTimeout,
-				WriteTimeout:                 c.Sink.KafkaConfig.WriteTimeout,
-				ReadTimeout:                  c.Sink.KafkaConfig.ReadTimeout,
-				RequiredAcks:                 c.Sink.KafkaConfig.RequiredAcks,
-				SASLUser:                     c.Sink.KafkaConfig.SASLUser,
-				SASLPassword:                 c.Sink.KafkaConfig.SASLPassword,
-				SASLMechanism:                c.Sink.KafkaConfig.SASLMechanism,
-				SASLGssAPIAuthType:           c.Sink.KafkaConfig.SASLGssAPIAuthType,
-				SASLGssAPIKeytabPath:         c.Sink.KafkaConfig.SASLGssAPIKeytabPath,
-				SASLGssAPIKerberosConfigPath: c.Sink.KafkaConfig.SASLGssAPIKerberosConfigPath,
-				SASLGssAPIServiceName:        c.Sink.KafkaConfig.SASLGssAPIServiceName,
-				SASLGssAPIUser:               c.Sink.KafkaConfig.SASLGssAPIUser,
-				SASLGssAPIPassword:           c.Sink.KafkaConfig.SASLGssAPIPassword,
-				SASLGssAPIRealm:              c.Sink.KafkaConfig.SASLGssAPIRealm,
-				SASLGssAPIDisablePafxfast:    c.Sink.KafkaConfig.SASLGssAPIDisablePafxfast,
-				SASLOAuthClientID:            c.Sink.KafkaConfig.SASLOAuthClientID,
-				SASLOAuthClientSecret:        c.Sink.KafkaConfig.SASLOAuthClientSecret,
-				SASLOAuthTokenURL:            c.Sink.KafkaConfig.SASLOAuthTokenURL,
-				SASLOAuthScopes:              c.Sink.KafkaConfig.SASLOAuthScopes,
-				SASLOAuthGrantType:           c.Sink.KafkaConfig.SASLOAuthGrantType,
-				SASLOAuthAudience:            c.Sink.KafkaConfig.SASLOAuthAudience,
-				EnableTLS:                    c.Sink.KafkaConfig.EnableTLS,
-				CA:                           c.Sink.KafkaConfig.CA,
-				Cert:                         c.Sink.KafkaConfig.Cert,
-				Key:                          c.Sink.KafkaConfig.Key,
-				InsecureSkipVerify:           c.Sink.KafkaConfig.InsecureSkipVerify,
-				CodecConfig:                  codeConfig,
-				LargeMessageHandle:           largeMessageHandle,
-				GlueSchemaRegistryConfig:     glueSchemaRegistryConfig,
-				OutputRawChangeEvent:         c.Sink.KafkaConfig.OutputRawChangeEvent,
+				PartitionNum:                      c.Sink.KafkaConfig.PartitionNum,
+				ReplicationFactor:                 c.Sink.KafkaConfig.ReplicationFactor,
+				KafkaVersion:                      c.Sink.KafkaConfig.KafkaVersion,
+				MaxMessageBytes:                   c.Sink.KafkaConfig.MaxMessageBytes,
+				Compression:                       c.Sink.KafkaConfig.Compression,
+				KafkaClientID:                     c.Sink.KafkaConfig.KafkaClientID,
+				AutoCreateTopic:                   c.Sink.KafkaConfig.AutoCreateTopic,
+				DialTimeout:                       c.Sink.KafkaConfig.DialTimeout,
+				WriteTimeout:                      c.Sink.KafkaConfig.WriteTimeout,
+				ReadTimeout:                       c.Sink.KafkaConfig.ReadTimeout,
+				RequiredAcks:                      c.Sink.KafkaConfig.RequiredAcks,
+				SASLUser:                          c.Sink.KafkaConfig.SASLUser,
+				SASLPassword:                      c.Sink.KafkaConfig.SASLPassword,
+				SASLMechanism:                     c.Sink.KafkaConfig.SASLMechanism,
+				SASLGssAPIAuthType:                c.Sink.KafkaConfig.SASLGssAPIAuthType,
+				SASLGssAPIKeytabPath:              c.Sink.KafkaConfig.SASLGssAPIKeytabPath,
+				SASLGssAPIKerberosConfigPath:      c.Sink.KafkaConfig.SASLGssAPIKerberosConfigPath,
+				SASLGssAPIServiceName:             c.Sink.KafkaConfig.SASLGssAPIServiceName,
+				SASLGssAPIUser:                    c.Sink.KafkaConfig.SASLGssAPIUser,
+				SASLGssAPIPassword:                c.Sink.KafkaConfig.SASLGssAPIPassword,
+				SASLGssAPIRealm:                   c.Sink.KafkaConfig.SASLGssAPIRealm,
+				SASLGssAPIDisablePafxfast:         c.Sink.KafkaConfig.SASLGssAPIDisablePafxfast,
+				SASLOAuthClientID:                 c.Sink.KafkaConfig.SASLOAuthClientID,
+				SASLOAuthClientSecret:             c.Sink.KafkaConfig.SASLOAuthClientSecret,
+				SASLOAuthTokenURL:                 c.Sink.KafkaConfig.SASLOAuthTokenURL,
+				SASLOAuthScopes:                   c.Sink.KafkaConfig.SASLOAuthScopes,
+				SASLOAuthGrantType:                c.Sink.KafkaConfig.SASLOAuthGrantType,
+				SASLOAuthAudience:                 c.Sink.KafkaConfig.SASLOAuthAudience,
+				EnableTLS:                         c.Sink.KafkaConfig.EnableTLS,
+				CA:                                c.Sink.KafkaConfig.CA,
+				Cert:                              c.Sink.KafkaConfig.Cert,
+				Key:                               c.Sink.KafkaConfig.Key,
+				InsecureSkipVerify:                c.Sink.KafkaConfig.InsecureSkipVerify,
+				CodecConfig:                       codeConfig,
+				LargeMessageHandle:                largeMessageHandle,
+				GlueSchemaRegistryConfig:          glueSchemaRegistryConfig,
+				OutputRawChangeEvent:              c.Sink.KafkaConfig.OutputRawChangeEvent,
+				ExactlyOnce:                       c.Sink.KafkaConfig.ExactlyOnce,
+				TransactionMarkerTopic:            c.Sink.KafkaConfig.TransactionMarkerTopic,
+				SASLAWSRegion:                     c.Sink.KafkaConfig.SASLAWSRegion,
+				SASLAWSProfile:                    c.Sink.KafkaConfig.SASLAWSProfile,
+				SASLEventHubsConnectionStringFile: c.Sink.KafkaConfig.SASLEventHubsConnectionStringFile,
 			}
 		}
 		var mysqlConfig *config.MySQLConfig
@@ -425,6 +466,7 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 				EnableBatchDML:               c.Sink.MySQLConfig.EnableBatchDML,
 				EnableMultiStatement:         c.Sink.MySQLConfig.EnableMultiStatement,
 				EnableCachePreparedStatement: c.Sink.MySQLConfig.EnableCachePreparedStatement,
+				ConflictResolution:           c.Sink.MySQLConfig.ConflictResolution.toInternal(),
 			}
 		}
 		var cloudStorageConfig *config.CloudStorageConfig
@@ -458,6 +500,7 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 			Protocol:                         c.Sink.Protocol,
 			CSVConfig:                        csvConfig,
 			ColumnSelectors:                  columnSelectors,
+			ColumnTransformers:               columnTransformers,
 			SchemaRegistry:                   c.Sink.SchemaRegistry,
 			EncoderConcurrency:               c.Sink.EncoderConcurrency,
 			Terminator:                       c.Sink.Terminator,
@@ -468,6 +511,7 @@ func (c *ReplicaConfig) toInternalReplicaConfigWithOriginConfig(
 			OnlyOutputUpdatedColumns:         c.Sink.OnlyOutputUpdatedColumns,
 			DeleteOnlyOutputHandleKeyColumns: c.Sink.DeleteOnlyOutputHandleKeyColumns,
 			ContentCompatible:                c.Sink.ContentCompatible,
+			EnableSchemaVersion:              c.Sink.EnableSchemaVersion,
 			KafkaConfig:                      kafkaConfig,
 			MySQLConfig:                      mysqlConfig,
 			PulsarConfig:                     pulsarConfig,
@@ -580,6 +624,10 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 				IndexName:     rule.IndexName,
 				Columns:       rule.Columns,
 				TopicRule:     rule.TopicRule,
+				DDLTopic:      rule.DDLTopic,
+				AllowedTopics: rule.AllowedTopics,
+				OverflowTopic: rule.OverflowTopic,
+				MaxTopics:     rule.MaxTopics,
 			})
 		}
 		var columnSelectors []*ColumnSelector
@@ -589,6 +637,17 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 				Columns: selector.Columns,
 			})
 		}
+		var columnTransformers []*ColumnTransformer
+		for _, rule := range cloned.Sink.ColumnTransformers {
+			columnTransformers = append(columnTransformers, &ColumnTransformer{
+				Matcher: rule.Matcher,
+				Columns: rule.Columns,
+				Action:  rule.Action,
+				Salt:    rule.Salt,
+				Length:  rule.Length,
+				NewName: rule.NewName,
+			})
+		}
 		var csvConfig *CSVConfig
 		if cloned.Sink.CSVConfig != nil {
 			csvConfig = &CSVConfig{
@@ -624,6 +683,8 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 					LargeMessageHandleCompression: oldConfig.LargeMessageHandleCompression,
 					ClaimCheckStorageURI:          oldConfig.ClaimCheckStorageURI,
 					ClaimCheckRawValue:            oldConfig.ClaimCheckRawValue,
+					ClaimCheckRetention:           oldConfig.ClaimCheckRetention,
+					ClaimCheckCleanupCronSpec:     oldConfig.ClaimCheckCleanupCronSpec,
 				}
 			}
 
@@ -639,43 +700,48 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 			}
 
 			kafkaConfig = &KafkaConfig{
-				PartitionNum:                 cloned.Sink.KafkaConfig.PartitionNum,
-				ReplicationFactor:            cloned.Sink.KafkaConfig.ReplicationFactor,
-				KafkaVersion:                 cloned.Sink.KafkaConfig.KafkaVersion,
-				MaxMessageBytes:              cloned.Sink.KafkaConfig.MaxMessageBytes,
-				Compression:                  cloned.Sink.KafkaConfig.Compression,
-				KafkaClientID:                cloned.Sink.KafkaConfig.KafkaClientID,
-				AutoCreateTopic:              cloned.Sink.KafkaConfig.AutoCreateTopic,
-				DialTimeout:                  cloned.Sink.KafkaConfig.DialTimeout,
-				WriteTimeout:                 cloned.Sink.KafkaConfig.WriteTimeout,
-				ReadTimeout:                  cloned.Sink.KafkaConfig.ReadTimeout,
-				RequiredAcks:                 cloned.Sink.KafkaConfig.RequiredAcks,
-				SASLUser:                     cloned.Sink.KafkaConfig.SASLUser,
-				SASLPassword:                 cloned.Sink.KafkaConfig.SASLPassword,
-				SASLMechanism:                cloned.Sink.KafkaConfig.SASLMechanism,
-				SASLGssAPIAuthType:           cloned.Sink.KafkaConfig.SASLGssAPIAuthType,
-				SASLGssAPIKeytabPath:         cloned.Sink.KafkaConfig.SASLGssAPIKeytabPath,
-				SASLGssAPIKerberosConfigPath: cloned.Sink.KafkaConfig.SASLGssAPIKerberosConfigPath,
-				SASLGssAPIServiceName:        cloned.Sink.KafkaConfig.SASLGssAPIServiceName,
-				SASLGssAPIUser:               cloned.Sink.KafkaConfig.SASLGssAPIUser,
-				SASLGssAPIPassword:           cloned.Sink.KafkaConfig.SASLGssAPIPassword,
-				SASLGssAPIRealm:              cloned.Sink.KafkaConfig.SASLGssAPIRealm,
-				SASLGssAPIDisablePafxfast:    cloned.Sink.KafkaConfig.SASLGssAPIDisablePafxfast,
-				SASLOAuthClientID:            cloned.Sink.KafkaConfig.SASLOAuthClientID,
-				SASLOAuthClientSecret:        cloned.Sink.KafkaConfig.SASLOAuthClientSecret,
-				SASLOAuthTokenURL:            cloned.Sink.KafkaConfig.SASLOAuthTokenURL,
-				SASLOAuthScopes:              cloned.Sink.KafkaConfig.SASLOAuthScopes,
-				SASLOAuthGrantType:           cloned.Sink.KafkaConfig.SASLOAuthGrantType,
-				SASLOAuthAudience:            cloned.Sink.KafkaConfig.SASLOAuthAudience,
-				EnableTLS:                    cloned.Sink.KafkaConfig.EnableTLS,
-				CA:                           cloned.Sink.KafkaConfig.CA,
-				Cert:                         cloned.Sink.KafkaConfig.Cert,
-				Key:                          cloned.Sink.KafkaConfig.Key,
-				InsecureSkipVerify:           cloned.Sink.KafkaConfig.InsecureSkipVerify,
-				CodecConfig:                  codeConfig,
-				LargeMessageHandle:           largeMessageHandle,
-				GlueSchemaRegistryConfig:     glueSchemaRegistryConfig,
-				OutputRawChangeEvent:         cloned.Sink.KafkaConfig.OutputRawChangeEvent,
+				PartitionNum:                      cloned.Sink.KafkaConfig.PartitionNum,
+				ReplicationFactor:                 cloned.Sink.KafkaConfig.ReplicationFactor,
+				KafkaVersion:                      cloned.Sink.KafkaConfig.KafkaVersion,
+				MaxMessageBytes:                   cloned.Sink.KafkaConfig.MaxMessageBytes,
+				Compression:                       cloned.Sink.KafkaConfig.Compression,
+				KafkaClientID:                     cloned.Sink.KafkaConfig.KafkaClientID,
+				AutoCreateTopic:                   cloned.Sink.KafkaConfig.AutoCreateTopic,
+				DialTimeout:                       cloned.Sink.KafkaConfig.DialTimeout,
+				WriteTimeout:                      cloned.Sink.KafkaConfig.WriteTimeout,
+				ReadTimeout:                       cloned.Sink.KafkaConfig.ReadTimeout,
+				RequiredAcks:                      cloned.Sink.KafkaConfig.RequiredAcks,
+				SASLUser:                          cloned.Sink.KafkaConfig.SASLUser,
+				SASLPassword:                      cloned.Sink.KafkaConfig.SASLPassword,
+				SASLMechanism:                     cloned.Sink.KafkaConfig.SASLMechanism,
+				SASLGssAPIAuthType:                cloned.Sink.KafkaConfig.SASLGssAPIAuthType,
+				SASLGssAPIKeytabPath:              cloned.Sink.KafkaConfig.SASLGssAPIKeytabPath,
+				SASLGssAPIKerberosConfigPath:      cloned.Sink.KafkaConfig.SASLGssAPIKerberosConfigPath,
+				SASLGssAPIServiceName:             cloned.Sink.KafkaConfig.SASLGssAPIServiceName,
+				SASLGssAPIUser:                    cloned.Sink.KafkaConfig.SASLGssAPIUser,
+				SASLGssAPIPassword:                cloned.Sink.KafkaConfig.SASLGssAPIPassword,
+				SASLGssAPIRealm:                   cloned.Sink.KafkaConfig.SASLGssAPIRealm,
+				SASLGssAPIDisablePafxfast:         cloned.Sink.KafkaConfig.SASLGssAPIDisablePafxfast,
+				SASLOAuthClientID:                 cloned.Sink.KafkaConfig.SASLOAuthClientID,
+				SASLOAuthClientSecret:             cloned.Sink.KafkaConfig.SASLOAuthClientSecret,
+				SASLOAuthTokenURL:                 cloned.Sink.KafkaConfig.SASLOAuthTokenURL,
+				SASLOAuthScopes:                   cloned.Sink.KafkaConfig.SASLOAuthScopes,
+				SASLOAuthGrantType:                cloned.Sink.KafkaConfig.SASLOAuthGrantType,
+				SASLOAuthAudience:                 cloned.Sink.KafkaConfig.SASLOAuthAudience,
+				EnableTLS:                         cloned.Sink.KafkaConfig.EnableTLS,
+				CA:                                cloned.Sink.KafkaConfig.CA,
+				Cert:                              cloned.Sink.KafkaConfig.Cert,
+				Key:                               cloned.Sink.KafkaConfig.Key,
+				InsecureSkipVerify:                cloned.Sink.KafkaConfig.InsecureSkipVerify,
+				CodecConfig:                       codeConfig,
+				LargeMessageHandle:                largeMessageHandle,
+				GlueSchemaRegistryConfig:          glueSchemaRegistryConfig,
+				OutputRawChangeEvent:              cloned.Sink.KafkaConfig.OutputRawChangeEvent,
+				ExactlyOnce:                       cloned.Sink.KafkaConfig.ExactlyOnce,
+				TransactionMarkerTopic:            cloned.Sink.KafkaConfig.TransactionMarkerTopic,
+				SASLAWSRegion:                     cloned.Sink.KafkaConfig.SASLAWSRegion,
+				SASLAWSProfile:                    cloned.Sink.KafkaConfig.SASLAWSProfile,
+				SASLEventHubsConnectionStringFile: cloned.Sink.KafkaConfig.SASLEventHubsConnectionStringFile,
 			}
 		}
 		var mysqlConfig *MySQLConfig
@@ -696,6 +762,7 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 				EnableBatchDML:               cloned.Sink.MySQLConfig.EnableBatchDML,
 				EnableMultiStatement:         cloned.Sink.MySQLConfig.EnableMultiStatement,
 				EnableCachePreparedStatement: cloned.Sink.MySQLConfig.EnableCachePreparedStatement,
+				ConflictResolution:           toAPIConflictResolutionConfig(cloned.Sink.MySQLConfig.ConflictResolution),
 			}
 		}
 		var pulsarConfig *PulsarConfig
@@ -719,6 +786,7 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 				AuthTLSCertificatePath:  cloned.Sink.PulsarConfig.AuthTLSCertificatePath,
 				AuthTLSPrivateKeyPath:   cloned.Sink.PulsarConfig.AuthTLSPrivateKeyPath,
 				OutputRawChangeEvent:    cloned.Sink.PulsarConfig.OutputRawChangeEvent,
+				BatcherBuilder:          (*string)(cloned.Sink.PulsarConfig.BatcherBuilder),
 			}
 			if cloned.Sink.PulsarConfig.OAuth2 != nil {
 				pulsarConfig.OAuth2 = &PulsarOAuth2{
@@ -729,6 +797,17 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 					OAuth2Scope:      cloned.Sink.PulsarConfig.OAuth2.OAuth2Scope,
 				}
 			}
+			if cloned.Sink.PulsarConfig.LargeMessageHandle != nil {
+				oldConfig := cloned.Sink.PulsarConfig.LargeMessageHandle
+				pulsarConfig.LargeMessageHandle = &LargeMessageHandleConfig{
+					LargeMessageHandleOption:      oldConfig.LargeMessageHandleOption,
+					LargeMessageHandleCompression: oldConfig.LargeMessageHandleCompression,
+					ClaimCheckStorageURI:          oldConfig.ClaimCheckStorageURI,
+					ClaimCheckRawValue:            oldConfig.ClaimCheckRawValue,
+					ClaimCheckRetention:           oldConfig.ClaimCheckRetention,
+					ClaimCheckCleanupCronSpec:     oldConfig.ClaimCheckCleanupCronSpec,
+				}
+			}
 		}
 		var cloudStorageConfig *CloudStorageConfig
 		if cloned.Sink.CloudStorageConfig != nil {
@@ -761,6 +840,7 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 			DispatchRules:                    dispatchRules,
 			CSVConfig:                        csvConfig,
 			ColumnSelectors:                  columnSelectors,
+			ColumnTransformers:               columnTransformers,
 			EncoderConcurrency:               cloned.Sink.EncoderConcurrency,
 			Terminator:                       cloned.Sink.Terminator,
 			DateSeparator:                    cloned.Sink.DateSeparator,
@@ -770,6 +850,7 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 			OnlyOutputUpdatedColumns:         cloned.Sink.OnlyOutputUpdatedColumns,
 			DeleteOnlyOutputHandleKeyColumns: cloned.Sink.DeleteOnlyOutputHandleKeyColumns,
 			ContentCompatible:                cloned.Sink.ContentCompatible,
+			EnableSchemaVersion:              cloned.Sink.EnableSchemaVersion,
 			KafkaConfig:                      kafkaConfig,
 			MySQLConfig:                      mysqlConfig,
 			PulsarConfig:                     pulsarConfig,
@@ -818,12 +899,19 @@ func ToAPIReplicaConfig(c *config.ReplicaConfig) *ReplicaConfig {
 			UseFileBackend:        cloned.Consistent.UseFileBackend,
 			Compression:           cloned.Consistent.Compression,
 			FlushConcurrency:      cloned.Consistent.FlushConcurrency,
+			RetentionInMs:         cloned.Consistent.RetentionInMs,
 		}
 		if cloned.Consistent.MemoryUsage != nil {
 			res.Consistent.MemoryUsage = &ConsistentMemoryUsage{
 				MemoryQuotaPercentage: cloned.Consistent.MemoryUsage.MemoryQuotaPercentage,
 			}
 		}
+		if cloned.Consistent.Compaction != nil {
+			res.Consistent.Compaction = &ConsistentCompaction{
+				IntervalInMs:   cloned.Consistent.Compaction.IntervalInMs,
+				MaxSegmentSize: cloned.Consistent.Compaction.MaxSegmentSize,
+			}
+		}
 	}
 
 	if cloned.Mounter != nil {
@@ -943,34 +1031,36 @@ type Table struct {
 // SinkConfig represents sink config for a changefeed
 // This is a duplicate of config.SinkConfig
 type SinkConfig struct {
-	Protocol                         *string             `json:"protocol,omitempty"`
-	SchemaRegistry                   *string             `json:"schema_registry,omitempty"`
-	CSVConfig                        *CSVConfig          `json:"csv,omitempty"`
-	DispatchRules                    []*DispatchRule     `json:"dispatchers,omitempty"`
-	ColumnSelectors                  []*ColumnSelector   `json:"column_selectors,omitempty"`
-	TxnAtomicity                     *string             `json:"transaction_atomicity,omitempty"`
-	EncoderConcurrency               *int                `json:"encoder_concurrency,omitempty"`
-	Terminator                       *string             `json:"terminator,omitempty"`
-	DateSeparator                    *string             `json:"date_separator,omitempty"`
-	EnablePartitionSeparator         *bool               `json:"enable_partition_separator,omitempty"`
-	FileIndexWidth                   *int                `json:"file_index_width,omitempty"`
-	EnableKafkaSinkV2                *bool               `json:"enable_kafka_sink_v2,omitempty"`
-	OnlyOutputUpdatedColumns         *bool               `json:"only_output_updated_columns,omitempty"`
-	DeleteOnlyOutputHandleKeyColumns *bool               `json:"delete_only_output_handle_key_columns"`
-	ContentCompatible                *bool               `json:"content_compatible"`
-	SafeMode                         *bool               `json:"safe_mode,omitempty"`
-	KafkaConfig                      *KafkaConfig        `json:"kafka_config,omitempty"`
-	PulsarConfig                     *PulsarConfig       `json:"pulsar_config,omitempty"`
-	MySQLConfig                      *MySQLConfig        `json:"mysql_config,omitempty"`
-	CloudStorageConfig               *CloudStorageConfig `json:"cloud_storage_config,omitempty"`
-	AdvanceTimeoutInSec              *uint               `json:"advance_timeout,omitempty"`
-	SendBootstrapIntervalInSec       *int64              `json:"send_bootstrap_interval_in_sec,omitempty"`
-	SendBootstrapInMsgCount          *int32              `json:"send_bootstrap_in_msg_count,omitempty"`
-	SendBootstrapToAllPartition      *bool               `json:"send_bootstrap_to_all_partition,omitempty"`
-	SendAllBootstrapAtStart          *bool               `json:"send-all-bootstrap-at-start,omitempty"`
-	DebeziumDisableSchema            *bool               `json:"debezium_disable_schema,omitempty"`
-	DebeziumConfig                   *DebeziumConfig     `json:"debezium,omitempty"`
-	OpenProtocolConfig               *OpenProtocolConfig `json:"open,omitempty"`
+	Protocol                         *string              `json:"protocol,omitempty"`
+	SchemaRegistry                   *string              `json:"schema_registry,omitempty"`
+	CSVConfig                        *CSVConfig           `json:"csv,omitempty"`
+	DispatchRules                    []*DispatchRule      `json:"dispatchers,omitempty"`
+	ColumnSelectors                  []*ColumnSelector    `json:"column_selectors,omitempty"`
+	ColumnTransformers               []*ColumnTransformer `json:"column_transformers,omitempty"`
+	TxnAtomicity                     *string              `json:"transaction_atomicity,omitempty"`
+	EncoderConcurrency               *int                 `json:"encoder_concurrency,omitempty"`
+	Terminator                       *string              `json:"terminator,omitempty"`
+	DateSeparator                    *string              `json:"date_separator,omitempty"`
+	EnablePartitionSeparator         *bool                `json:"enable_partition_separator,omitempty"`
+	FileIndexWidth                   *int                 `json:"file_index_width,omitempty"`
+	EnableKafkaSinkV2                *bool                `json:"enable_kafka_sink_v2,omitempty"`
+	OnlyOutputUpdatedColumns         *bool                `json:"only_output_updated_columns,omitempty"`
+	DeleteOnlyOutputHandleKeyColumns *bool                `json:"delete_only_output_handle_key_columns"`
+	ContentCompatible                *bool                `json:"content_compatible"`
+	EnableSchemaVersion              *bool                `json:"enable_schema_version,omitempty"`
+	SafeMode                         *bool                `json:"safe_mode,omitempty"`
+	KafkaConfig                      *KafkaConfig         `json:"kafka_config,omitempty"`
+	PulsarConfig                     *PulsarConfig        `json:"pulsar_config,omitempty"`
+	MySQLConfig                      *MySQLConfig         `json:"mysql_config,omitempty"`
+	CloudStorageConfig               *CloudStorageConfig  `json:"cloud_storage_config,omitempty"`
+	AdvanceTimeoutInSec              *uint                `json:"advance_timeout,omitempty"`
+	SendBootstrapIntervalInSec       *int64               `json:"send_bootstrap_interval_in_sec,omitempty"`
+	SendBootstrapInMsgCount          *int32               `json:"send_bootstrap_in_msg_count,omitempty"`
+	SendBootstrapToAllPartition      *bool                `json:"send_bootstrap_to_all_partition,omitempty"`
+	SendAllBootstrapAtStart          *bool                `json:"send-all-bootstrap-at-start,omitempty"`
+	DebeziumDisableSchema            *bool                `json:"debezium_disable_schema,omitempty"`
+	DebeziumConfig                   *DebeziumConfig      `json:"debezium,omitempty"`
+	OpenProtocolConfig               *OpenProtocolConfig  `json:"open,omitempty"`
 }
 
 // CSVConfig denotes the csv config
@@ -992,6 +1082,8 @@ type LargeMessageHandleConfig struct {
 	LargeMessageHandleCompression string `json:"large_message_handle_compression"`
 	ClaimCheckStorageURI          string `json:"claim_check_storage_uri"`
 	ClaimCheckRawValue            bool   `json:"claim_check_raw_value"`
+	ClaimCheckRetention           string `json:"claim_check_retention,omitempty"`
+	ClaimCheckCleanupCronSpec     string `json:"claim_check_cleanup_cron_spec,omitempty"`
 }
 
 // DispatchRule represents partition rule for a table
@@ -1002,6 +1094,10 @@ type DispatchRule struct {
 	IndexName     string   `json:"index,omitempty"`
 	Columns       []string `json:"columns,omitempty"`
 	TopicRule     string   `json:"topic,omitempty"`
+	DDLTopic      string   `json:"ddl_topic,omitempty"`
+	AllowedTopics []string `json:"allowed_topics,omitempty"`
+	OverflowTopic string   `json:"overflow_topic,omitempty"`
+	MaxTopics     int      `json:"max_topics,omitempty"`
 }
 
 // ColumnSelector represents a column selector for a table.
@@ -1011,6 +1107,17 @@ type ColumnSelector struct {
 	Columns []string `json:"columns,omitempty"`
 }
 
+// ColumnTransformer represents a column transformer for a table.
+// This is a duplicate of config.ColumnTransformer
+type ColumnTransformer struct {
+	Matcher []string `json:"matcher,omitempty"`
+	Columns []string `json:"columns,omitempty"`
+	Action  string   `json:"action,omitempty"`
+	Salt    string   `json:"salt,omitempty"`
+	Length  int      `json:"length,omitempty"`
+	NewName string   `json:"new_name,omitempty"`
+}
+
 // ConsistentConfig represents replication consistency config for a changefeed
 // This is a duplicate of config.ConsistentConfig
 type ConsistentConfig struct {
@@ -1026,6 +1133,9 @@ type ConsistentConfig struct {
 	FlushConcurrency      int    `json:"flush_concurrency,omitempty"`
 
 	MemoryUsage *ConsistentMemoryUsage `json:"memory_usage"`
+	Compaction  *ConsistentCompaction  `json:"compaction,omitempty"`
+	// RetentionInMs is the duration(ms) of the redo logs retained before the checkpoint.
+	RetentionInMs int64 `json:"retention,omitempty"`
 }
 
 // ConsistentMemoryUsage represents memory usage of Consistent module.
@@ -1033,6 +1143,12 @@ type ConsistentMemoryUsage struct {
 	MemoryQuotaPercentage uint64 `json:"memory_quota_percentage"`
 }
 
+// ConsistentCompaction represents the compaction config of redo log segments.
+type ConsistentCompaction struct {
+	IntervalInMs   int64 `json:"interval"`
+	MaxSegmentSize int64 `json:"max_segment_size"`
+}
+
 // ChangefeedSchedulerConfig is per changefeed scheduler settings.
 // This is a duplicate of config.ChangefeedSchedulerConfig
 type ChangefeedSchedulerConfig struct {
@@ -1087,6 +1203,17 @@ type ChangeFeedInfo struct {
 	CheckpointTs   uint64                    `json:"checkpoint_ts"`
 	CheckpointTime model.JSONTime            `json:"checkpoint_time"`
 	TaskStatus     []model.CaptureTaskStatus `json:"task_status,omitempty"`
+	SlowPartitions []SlowPartition           `json:"slow_partitions,omitempty"`
+	ConflictCount  uint64                    `json:"conflict_count,omitempty"`
+}
+
+// SlowPartition is a partition of the MQ sink whose in-flight messages
+// are not acknowledged in time.
+type SlowPartition struct {
+	CaptureID string         `json:"capture_id"`
+	Topic     string         `json:"topic"`
+	Partition int32          `json:"partition"`
+	Since     model.JSONTime `json:"since"`
 }
 
 // SyncedStatus describes the detail of a changefeed's synced status
@@ -1213,6 +1340,9 @@ type PulsarConfig struct {
 	AuthTLSPrivateKeyPath   *string       `json:"auth-tls-private-key-path,omitempty"`
 	OAuth2                  *PulsarOAuth2 `json:"oauth2,omitempty"`
 	OutputRawChangeEvent    *bool         `json:"output-raw-change-event,omitempty"`
+	BatcherBuilder          *string       `json:"batcher-builder,omitempty"`
+
+	LargeMessageHandle *LargeMessageHandleConfig `json:"large-message-handle,omitempty"`
 }
 
 // PulsarOAuth2 is the configuration for OAuth2
@@ -1263,6 +1393,12 @@ type KafkaConfig struct {
 	LargeMessageHandle           *LargeMessageHandleConfig `json:"large_message_handle,omitempty"`
 	GlueSchemaRegistryConfig     *GlueSchemaRegistryConfig `json:"glue_schema_registry_config,omitempty"`
 	OutputRawChangeEvent         *bool                     `json:"output_raw_change_event,omitempty"`
+	ExactlyOnce                  *bool                     `json:"exactly_once,omitempty"`
+	TransactionMarkerTopic       *string                   `json:"transaction_marker_topic,omitempty"`
+
+	SASLAWSRegion                     *string `json:"sasl_aws_region,omitempty"`
+	SASLAWSProfile                    *string `json:"sasl_aws_profile,omitempty"`
+	SASLEventHubsConnectionStringFile *string `json:"sasl_event_hubs_connection_string_file,omitempty"`
 }
 
 // MySQLConfig represents a MySQL sink configuration
@@ -1282,6 +1418,44 @@ type MySQLConfig struct {
 	EnableBatchDML               *bool   `json:"enable_batch_dml,omitempty"`
 	EnableMultiStatement         *bool   `json:"enable_multi_statement,omitempty"`
 	EnableCachePreparedStatement *bool   `json:"enable_cache_prepared_statement,omitempty"`
+
+	ConflictResolution *ConflictResolutionConfig `json:"conflict_resolution,omitempty"`
+}
+
+// ConflictResolutionConfig represents the conflict resolution configuration of the MySQL sink.
+// This is a duplicate of config.ConflictResolutionConfig
+type ConflictResolutionConfig struct {
+	Policy        string `json:"policy"`
+	VersionColumn string `json:"version_column"`
+	Priority      string `json:"priority,omitempty"`
+	ConflictTable string `json:"conflict_table,omitempty"`
+	ConflictFile  string `json:"conflict_file,omitempty"`
+}
+
+func (c *ConflictResolutionConfig) toInternal() *config.ConflictResolutionConfig {
+	if c == nil {
+		return nil
+	}
+	return &config.ConflictResolutionConfig{
+		Policy:        c.Policy,
+		VersionColumn: c.VersionColumn,
+		Priority:      c.Priority,
+		ConflictTable: c.ConflictTable,
+		ConflictFile:  c.ConflictFile,
+	}
+}
+
+func toAPIConflictResolutionConfig(c *config.ConflictResolutionConfig) *ConflictResolutionConfig {
+	if c == nil {
+		return nil
+	}
+	return &ConflictResolutionConfig{
+		Policy:        c.Policy,
+		VersionColumn: c.VersionColumn,
+		Priority:      c.Priority,
+		ConflictTable: c.ConflictTable,
+		ConflictFile:  c.ConflictFile,
+	}
 }
 
 // CloudStorageConfig represents a cloud storage sink configuration
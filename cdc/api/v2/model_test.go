@@ -136,6 +136,11 @@ func TestToAPIReplicaConfig(t *testing.T) {
 		MaxLogSize:        99,
 		FlushIntervalInMs: 10,
 		Storage:           "s3",
+		Compaction: &config.ConsistentCompaction{
+			IntervalInMs:   60000,
+			MaxSegmentSize: 256,
+		},
+		RetentionInMs: 3600000,
 	}
 	cfg.Filter = &config.FilterConfig{
 		Rules:            []string{"a", "b", "c"},
@@ -357,7 +357,10 @@ func (info *ChangeFeedInfo) RmUnusedFields() {
 	if sink.IsBlackHoleScheme(uri.Scheme) {
 		return
 	}
-	if !sink.IsMQScheme(uri.Scheme) {
+	if sink.IsRedisScheme(uri.Scheme) {
+		// redis sink shares the dispatchers and the encoder configs with MQ sinks.
+		info.rmKafkaOnlyFields()
+	} else if !sink.IsMQScheme(uri.Scheme) {
 		info.rmMQOnlyFields()
 	} else {
 		// remove schema registry for MQ downstream with
@@ -367,7 +370,8 @@ func (info *ChangeFeedInfo) RmUnusedFields() {
 		}
 	}
 
-	if !sink.IsStorageScheme(uri.Scheme) {
+	// the iceberg sink shares the cloud storage config with the storage sink.
+	if !sink.IsStorageScheme(uri.Scheme) && !sink.IsIcebergScheme(uri.Scheme) {
 		info.rmStorageOnlyFields()
 	}
 
@@ -394,6 +398,12 @@ func (info *ChangeFeedInfo) rmMQOnlyFields() {
 	info.Config.Sink.KafkaConfig = nil
 }
 
+func (info *ChangeFeedInfo) rmKafkaOnlyFields() {
+	info.Config.Sink.SchemaRegistry = nil
+	info.Config.Sink.EnableKafkaSinkV2 = nil
+	info.Config.Sink.KafkaConfig = nil
+}
+
 func (info *ChangeFeedInfo) rmStorageOnlyFields() {
 	info.Config.Sink.CSVConfig = nil
 	info.Config.Sink.DateSeparator = nil
@@ -583,6 +593,10 @@ func (info *ChangeFeedInfo) fixScheduler(inheritV66 bool) {
 type ChangeFeedStatusForAPI struct {
 	ResolvedTs   uint64 `json:"resolved-ts"`
 	CheckpointTs uint64 `json:"checkpoint-ts"`
+	// SlowPartitions are the slow partitions of the MQ sink reported by all captures.
+	SlowPartitions []SlowPartition `json:"slow-partitions,omitempty"`
+	// ConflictCount is the number of the conflicted rows reported by all captures.
+	ConflictCount uint64 `json:"conflict-count,omitempty"`
 }
 
 // ChangeFeedSyncedStatusForAPI uses to transfer the synced status of changefeed for API.
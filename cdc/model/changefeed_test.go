@@ -125,6 +125,28 @@ func TestRmUnusedField(t *testing.T) {
 		)
 		require.Nil(t, kcCf.Config.Sink.CSVConfig)
 	}
+
+	// 5. redis downstream keeps the dispatchers
+	{
+		rdCf := &ChangeFeedInfo{
+			SinkURI: "redis://",
+			Config: &config.ReplicaConfig{
+				Sink: &config.SinkConfig{
+					Protocol:       util.AddressOf(config.ProtocolCanalJSON.String()),
+					SchemaRegistry: util.AddressOf(defaultRegistry),
+					DispatchRules: []*config.DispatchRule{
+						{Matcher: []string{"test.*"}, PartitionRule: "index-value"},
+					},
+					KafkaConfig: &config.KafkaConfig{},
+				},
+			},
+		}
+		rdCf.VerifyAndComplete()
+		require.Nil(t, rdCf.Config.Sink.SchemaRegistry)
+		require.Nil(t, rdCf.Config.Sink.KafkaConfig)
+		require.Len(t, rdCf.Config.Sink.DispatchRules, 1)
+		require.Equal(t, config.ProtocolCanalJSON.String(), util.GetOrZero(rdCf.Config.Sink.Protocol))
+	}
 }
 
 func TestFillV1(t *testing.T) {
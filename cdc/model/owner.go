@@ -16,6 +16,7 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/pingcap/errors"
 	timodel "github.com/pingcap/tidb/pkg/meta/model"
@@ -95,6 +96,30 @@ type TaskPosition struct {
 	Error *RunningError `json:"error"`
 	// Warning when module error happens
 	Warning *RunningError `json:"warning"`
+	// SlowPartitions are the partitions of the MQ sink whose messages
+	// are not acknowledged in time.
+	SlowPartitions []SlowPartition `json:"slow-partitions,omitempty"`
+	// ConflictCount is the number of the rows conflicted with the downstream
+	// rows in the BDR mode, which are detected by the MySQL sink.
+	ConflictCount uint64 `json:"conflict-count,omitempty"`
+}
+
+// SlowPartition is a partition of the MQ sink whose in-flight messages are not
+// acknowledged by the broker in time, which holds back the changefeed.
+type SlowPartition struct {
+	// CaptureID is the capture which sends messages to the partition,
+	// it's only filled when the slow partitions are collected by the owner.
+	CaptureID CaptureID `json:"capture-id,omitempty"`
+	Topic     string    `json:"topic"`
+	Partition int32     `json:"partition"`
+	// Since is the time when the oldest unacknowledged message was sent.
+	Since time.Time `json:"since"`
+}
+
+// Equal returns whether the two slow partitions are the same.
+func (p SlowPartition) Equal(other SlowPartition) bool {
+	return p.CaptureID == other.CaptureID && p.Topic == other.Topic &&
+		p.Partition == other.Partition && p.Since.Equal(other.Since)
 }
 
 // Marshal returns the json marshal format of a TaskStatus
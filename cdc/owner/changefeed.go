@@ -17,6 +17,8 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -38,6 +40,8 @@ import (
 	pfilter "github.com/pingcap/tiflow/pkg/filter"
 	"github.com/pingcap/tiflow/pkg/pdutil"
 	redoCfg "github.com/pingcap/tiflow/pkg/redo"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/kafka/claimcheck"
 	"github.com/pingcap/tiflow/pkg/sink/observer"
 	"github.com/pingcap/tiflow/pkg/txnutil/gc"
 	"github.com/pingcap/tiflow/pkg/upstream"
@@ -185,6 +189,16 @@ type changefeed struct {
 	// The latest changefeed info and status from meta storage. they are updated in every Tick.
 	latestInfo   *model.ChangeFeedInfo
 	latestStatus *model.ChangeFeedStatus
+	// slowPartitions are the slow partitions of the MQ sink reported by the processors,
+	// they are updated in every Tick.
+	slowPartitions []model.SlowPartition
+	// conflictCount is the number of the conflicted rows reported by the processors,
+	// it's updated in every Tick.
+	conflictCount uint64
+	// conflictCounts is the last conflict count reported by each capture, the counts
+	// of the captures gone are kept in retiredConflictCount, so the sum never decreases.
+	conflictCounts       map[model.CaptureID]uint64
+	retiredConflictCount uint64
 }
 
 func (c *changefeed) GetScheduler() scheduler.Scheduler {
@@ -265,6 +279,53 @@ func newChangefeed4Test(
 	return c
 }
 
+// updateSlowPartitions collects the slow partitions reported by the processors.
+func (c *changefeed) updateSlowPartitions(taskPositions map[model.CaptureID]*model.TaskPosition) {
+	c.slowPartitions = c.slowPartitions[:0]
+	for captureID, position := range taskPositions {
+		if position == nil {
+			continue
+		}
+		for _, p := range position.SlowPartitions {
+			p.CaptureID = captureID
+			c.slowPartitions = append(c.slowPartitions, p)
+		}
+	}
+	sort.Slice(c.slowPartitions, func(i, j int) bool {
+		a, b := c.slowPartitions[i], c.slowPartitions[j]
+		if a.CaptureID != b.CaptureID {
+			return a.CaptureID < b.CaptureID
+		}
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.Partition < b.Partition
+	})
+}
+
+// updateConflictCount sums the conflicted rows reported by the processors.
+func (c *changefeed) updateConflictCount(taskPositions map[model.CaptureID]*model.TaskPosition) {
+	if c.conflictCounts == nil {
+		c.conflictCounts = make(map[model.CaptureID]uint64)
+	}
+	for captureID, count := range c.conflictCounts {
+		if _, ok := taskPositions[captureID]; !ok {
+			c.retiredConflictCount += count
+			delete(c.conflictCounts, captureID)
+		}
+	}
+	c.conflictCount = c.retiredConflictCount
+	for captureID, position := range taskPositions {
+		var count uint64
+		if position != nil {
+			count = position.ConflictCount
+		}
+		count = max(count, c.conflictCounts[captureID])
+		c.conflictCounts[captureID] = count
+		c.conflictCount += count
+	}
+}
+
 func (c *changefeed) Tick(ctx context.Context,
 	cfInfo *model.ChangeFeedInfo,
 	cfStatus *model.ChangeFeedStatus,
@@ -787,6 +848,7 @@ func (c *changefeed) releaseResources(ctx context.Context) {
 	// Must clean redo manager before calling cancel, otherwise
 	// the manager can be closed internally.
 	c.cleanupRedoManager(ctx, c.latestInfo)
+	c.cleanupClaimCheck(ctx, c.latestInfo)
 	c.cleanupChangefeedServiceGCSafePoints(ctx)
 
 	if c.cancel != nil {
@@ -898,6 +960,59 @@ func (c *changefeed) cleanupRedoManager(ctx context.Context, cfInfo *model.Chang
 	}
 }
 
+// cleanupClaimCheck removes all claim-check files of the removed changefeed.
+func (c *changefeed) cleanupClaimCheck(ctx context.Context, cfInfo *model.ChangeFeedInfo) {
+	if !c.isRemoved || cfInfo == nil || cfInfo.Config == nil || cfInfo.Config.Sink == nil {
+		return
+	}
+	cleaner, err := claimcheck.NewCleaner(ctx, getLargeMessageHandle(cfInfo), c.id)
+	if err != nil {
+		log.Error("cleanup claim-check files failed",
+			zap.String("namespace", c.id.Namespace),
+			zap.String("changefeed", c.id.ID),
+			zap.Error(err))
+		return
+	}
+	if cleaner == nil {
+		return
+	}
+	defer cleaner.Close()
+	count, err := cleaner.RemoveAllFiles(ctx)
+	if err != nil {
+		log.Error("cleanup claim-check files failed",
+			zap.String("namespace", c.id.Namespace),
+			zap.String("changefeed", c.id.ID),
+			zap.Error(err))
+		return
+	}
+	log.Info("cleanup claim-check files of the removed changefeed",
+		zap.String("namespace", c.id.Namespace),
+		zap.String("changefeed", c.id.ID),
+		zap.Int("count", count))
+}
+
+// getLargeMessageHandle returns the large message handle config of the MQ sink,
+// it's selected by the sink scheme, since the kafka config and the pulsar config
+// can be set at the same time.
+func getLargeMessageHandle(cfInfo *model.ChangeFeedInfo) *config.LargeMessageHandleConfig {
+	sinkURI, err := url.Parse(cfInfo.SinkURI)
+	if err != nil {
+		return nil
+	}
+	scheme := sink.GetScheme(sinkURI)
+	switch {
+	case sink.IsPulsarScheme(scheme):
+		if cfInfo.Config.Sink.PulsarConfig != nil {
+			return cfInfo.Config.Sink.PulsarConfig.LargeMessageHandle
+		}
+	case sink.IsMQScheme(scheme):
+		if cfInfo.Config.Sink.KafkaConfig != nil {
+			return cfInfo.Config.Sink.KafkaConfig.LargeMessageHandle
+		}
+	}
+	return nil
+}
+
 func (c *changefeed) cleanupChangefeedServiceGCSafePoints(ctx context.Context) {
 	if !c.isRemoved {
 		return
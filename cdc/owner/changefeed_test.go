@@ -737,3 +737,69 @@ func TestBarrierAdvance(t *testing.T) {
 		}
 	}
 }
+
+func TestUpdateSlowPartitions(t *testing.T) {
+	t.Parallel()
+
+	since := time.Now()
+	cf := &changefeed{}
+	cf.updateSlowPartitions(map[model.CaptureID]*model.TaskPosition{
+		"capture-2": {SlowPartitions: []model.SlowPartition{{Topic: "a", Partition: 0, Since: since}}},
+		"capture-1": {SlowPartitions: []model.SlowPartition{
+			{Topic: "b", Partition: 1, Since: since},
+			{Topic: "a", Partition: 2, Since: since},
+		}},
+		"capture-3": nil,
+	})
+	require.Equal(t, []model.SlowPartition{
+		{CaptureID: "capture-1", Topic: "a", Partition: 2, Since: since},
+		{CaptureID: "capture-1", Topic: "b", Partition: 1, Since: since},
+		{CaptureID: "capture-2", Topic: "a", Partition: 0, Since: since},
+	}, cf.slowPartitions)
+
+	cf.updateSlowPartitions(map[model.CaptureID]*model.TaskPosition{"capture-1": {}})
+	require.Empty(t, cf.slowPartitions)
+}
+
+func TestUpdateConflictCount(t *testing.T) {
+	t.Parallel()
+
+	cf := &changefeed{}
+	cf.updateConflictCount(map[model.CaptureID]*model.TaskPosition{
+		"capture-1": {ConflictCount: 2},
+		"capture-2": {ConflictCount: 3},
+		"capture-3": nil,
+	})
+	require.Equal(t, uint64(5), cf.conflictCount)
+
+	// The count of the capture gone is kept, and the count of a capture never decreases.
+	cf.updateConflictCount(map[model.CaptureID]*model.TaskPosition{"capture-1": {}})
+	require.Equal(t, uint64(5), cf.conflictCount)
+
+	cf.updateConflictCount(map[model.CaptureID]*model.TaskPosition{
+		"capture-1": {ConflictCount: 4},
+		"capture-4": {ConflictCount: 1},
+	})
+	require.Equal(t, uint64(8), cf.conflictCount)
+}
+
+func TestGetLargeMessageHandle(t *testing.T) {
+	t.Parallel()
+
+	kafkaHandle := &config.LargeMessageHandleConfig{ClaimCheckStorageURI: "file:///kafka"}
+	pulsarHandle := &config.LargeMessageHandleConfig{ClaimCheckStorageURI: "file:///pulsar"}
+	newInfo := func(sinkURI string, kafka, pulsar *config.LargeMessageHandleConfig) *model.ChangeFeedInfo {
+		cfg := config.GetDefaultReplicaConfig()
+		cfg.Sink.KafkaConfig = &config.KafkaConfig{LargeMessageHandle: kafka}
+		cfg.Sink.PulsarConfig = &config.PulsarConfig{LargeMessageHandle: pulsar}
+		return &model.ChangeFeedInfo{SinkURI: sinkURI, Config: cfg}
+	}
+
+	// the pulsar changefeed with an empty kafka config.
+	require.Equal(t, pulsarHandle,
+		getLargeMessageHandle(newInfo("pulsar://127.0.0.1:6650/topic", nil, pulsarHandle)))
+	require.Equal(t, kafkaHandle,
+		getLargeMessageHandle(newInfo("kafka://127.0.0.1:9092/topic", kafkaHandle, pulsarHandle)))
+	require.Nil(t, getLargeMessageHandle(newInfo("mysql://127.0.0.1:3306", kafkaHandle, pulsarHandle)))
+	require.Nil(t, getLargeMessageHandle(newInfo("pulsar://127.0.0.1:6650/topic", kafkaHandle, nil)))
+}
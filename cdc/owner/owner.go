@@ -16,6 +16,7 @@ package owner
 import (
 	"context"
 	"io"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -233,6 +234,8 @@ func (o *ownerImpl) Tick(stdCtx context.Context, rawState orchestrator.ReactorSt
 		if !preflightCheck(changefeedState, captures) {
 			continue
 		}
+		cfReactor.updateSlowPartitions(changefeedState.TaskPositions)
+		cfReactor.updateConflictCount(changefeedState.TaskPositions)
 		checkpointTs, minTableBarrierTs := cfReactor.Tick(stdCtx, changefeedState.Info, changefeedState.Status, captures)
 		updateStatus(changefeedState, checkpointTs, minTableBarrierTs)
 	}
@@ -672,6 +675,8 @@ func (o *ownerImpl) handleQueries(query *Query) error {
 		ret := &model.ChangeFeedStatusForAPI{}
 		ret.ResolvedTs = cfReactor.resolvedTs
 		ret.CheckpointTs = cfReactor.latestStatus.CheckpointTs
+		ret.SlowPartitions = slices.Clone(cfReactor.slowPartitions)
+		ret.ConflictCount = cfReactor.conflictCount
 		query.Data = ret
 	case QueryChangeFeedSyncedStatus:
 		cfReactor, ok := o.changefeeds[query.ChangeFeedID]
@@ -17,6 +17,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"slices"
 	"time"
 
 	"github.com/pingcap/errors"
@@ -136,6 +137,9 @@ func (m *managerImpl) Tick(stdCtx context.Context, state orchestrator.ReactorSta
 				m.captureInfo, changefeedID, up, m.liveness,
 				currentChangefeedEpoch, &cfg, m.globalVars.EtcdClient,
 				m.globalVars)
+			if position := changefeedState.TaskPositions[m.captureInfo.ID]; position != nil {
+				p.restoreConflictCount(position.ConflictCount)
+			}
 			m.processors[changefeedID] = p
 		}
 		if currentChangefeedEpoch != p.changefeedEpoch {
@@ -165,7 +169,10 @@ func (m *managerImpl) Tick(stdCtx context.Context, state orchestrator.ReactorSta
 			// patchProcessorErr have already patched its error to tell the owner
 			// manager can just close the processor and continue to tick other processors
 			m.closeProcessor(changefeedID)
+			continue
 		}
+		patchSlowPartitions(p.captureInfo, changefeedState, p.slowPartitions())
+		patchConflictCount(p.captureInfo, changefeedState, p.conflictCount())
 	}
 	// check if the processors in memory is leaked
 	if len(globalState.Changefeeds)-inactiveChangefeedCount != len(m.processors) {
@@ -277,6 +284,42 @@ func patchProcessorWarning(captureInfo *model.CaptureInfo,
 		})
 }
 
+// patchSlowPartitions reports the slow partitions of the MQ sink to the owner,
+// the task position is only patched if the slow partitions are changed.
+func patchSlowPartitions(captureInfo *model.CaptureInfo,
+	changefeed *orchestrator.ChangefeedReactorState, slowPartitions []model.SlowPartition,
+) {
+	changefeed.PatchTaskPosition(captureInfo.ID,
+		func(position *model.TaskPosition) (*model.TaskPosition, bool, error) {
+			if position == nil {
+				position = &model.TaskPosition{}
+			}
+			if slices.EqualFunc(position.SlowPartitions, slowPartitions, model.SlowPartition.Equal) {
+				return position, false, nil
+			}
+			position.SlowPartitions = slowPartitions
+			return position, true, nil
+		})
+}
+
+// patchConflictCount reports the number of the conflicted rows to the owner,
+// the task position is only patched if the count is changed.
+func patchConflictCount(captureInfo *model.CaptureInfo,
+	changefeed *orchestrator.ChangefeedReactorState, conflictCount uint64,
+) {
+	changefeed.PatchTaskPosition(captureInfo.ID,
+		func(position *model.TaskPosition) (*model.TaskPosition, bool, error) {
+			if position == nil {
+				position = &model.TaskPosition{}
+			}
+			if position.ConflictCount == conflictCount {
+				return position, false, nil
+			}
+			position.ConflictCount = conflictCount
+			return position, true, nil
+		})
+}
+
 func (m *managerImpl) closeProcessor(changefeedID model.ChangeFeedID) {
 	processor, exist := m.processors[changefeedID]
 	if exist {
@@ -17,6 +17,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"maps"
 	"math"
 	"testing"
 	"time"
@@ -303,3 +304,44 @@ func TestManagerLiveness(t *testing.T) {
 	s.liveness.Store(model.LivenessCaptureStopping)
 	require.Equal(t, model.LivenessCaptureStopping, p.liveness.Load())
 }
+
+func TestPatchSlowPartitions(t *testing.T) {
+	changefeedID := model.DefaultChangeFeedID("test-changefeed")
+	captureInfo := &model.CaptureInfo{ID: "capture-test", AdvertiseAddr: "127.0.0.1:0000"}
+	state := orchestrator.NewChangefeedReactorState(etcd.DefaultCDCClusterID, changefeedID)
+	tester := orchestrator.NewReactorStateTester(t, state, nil)
+
+	since := time.Now()
+	slowPartitions := []model.SlowPartition{{Topic: "topic", Partition: 1, Since: since}}
+	patchSlowPartitions(captureInfo, state, slowPartitions)
+	tester.MustApplyPatches()
+	require.Len(t, state.TaskPositions[captureInfo.ID].SlowPartitions, 1)
+	require.True(t, slowPartitions[0].Equal(state.TaskPositions[captureInfo.ID].SlowPartitions[0]))
+
+	// The task position is not changed if the slow partitions are the same.
+	kvEntries := maps.Clone(tester.KVEntries())
+	patchSlowPartitions(captureInfo, state, slowPartitions)
+	tester.MustApplyPatches()
+	require.Equal(t, kvEntries, tester.KVEntries())
+
+	patchSlowPartitions(captureInfo, state, nil)
+	tester.MustApplyPatches()
+	require.Empty(t, state.TaskPositions[captureInfo.ID].SlowPartitions)
+}
+
+func TestPatchConflictCount(t *testing.T) {
+	changefeedID := model.DefaultChangeFeedID("test-changefeed")
+	captureInfo := &model.CaptureInfo{ID: "capture-test", AdvertiseAddr: "127.0.0.1:0000"}
+	state := orchestrator.NewChangefeedReactorState(etcd.DefaultCDCClusterID, changefeedID)
+	tester := orchestrator.NewReactorStateTester(t, state, nil)
+
+	patchConflictCount(captureInfo, state, 3)
+	tester.MustApplyPatches()
+	require.Equal(t, uint64(3), state.TaskPositions[captureInfo.ID].ConflictCount)
+
+	// The task position is not changed if the count is the same.
+	kvEntries := maps.Clone(tester.KVEntries())
+	patchConflictCount(captureInfo, state, 3)
+	tester.MustApplyPatches()
+	require.Equal(t, kvEntries, tester.KVEntries())
+}
@@ -55,6 +55,10 @@ import (
 const (
 	backoffBaseDelayInMs = 5
 	maxTries             = 3
+
+	// conflictCountReportInterval limits how often the conflict count is reported
+	// to the owner, since the report is persisted in the meta storage.
+	conflictCountReportInterval = 10 * time.Second
 )
 
 // Processor is the processor of changefeed data.
@@ -115,6 +119,12 @@ type processor struct {
 	latestInfo   *model.ChangeFeedInfo
 	latestStatus *model.ChangeFeedStatus
 
+	// conflictCountBase is the conflict count persisted by the previous processors
+	// of this capture, so the reported count never goes backwards.
+	conflictCountBase       uint64
+	conflictCountReported   uint64
+	conflictCountReportedAt time.Time
+
 	ownerCaptureInfoClient etcd.OwnerCaptureInfoClient
 
 	metricSyncTableNumGauge      prometheus.Gauge
@@ -863,6 +873,34 @@ func (p *processor) refreshMetrics() {
 	p.metricSyncTableNumGauge.Set(float64(p.sinkManager.r.GetAllCurrentTableSpansCount()))
 }
 
+// slowPartitions returns the slow partitions of the sink of the processor.
+func (p *processor) slowPartitions() []model.SlowPartition {
+	if !p.initialized.Load() || p.sinkManager.r == nil {
+		return nil
+	}
+	return p.sinkManager.r.SlowPartitions()
+}
+
+// restoreConflictCount continues the conflict count from the persisted one.
+func (p *processor) restoreConflictCount(count uint64) {
+	p.conflictCountBase = count
+	p.conflictCountReported = count
+}
+
+// conflictCount returns the number of the conflicted rows written by this capture,
+// it's refreshed at most once per conflictCountReportInterval since the report is
+// persisted in the meta storage.
+func (p *processor) conflictCount() uint64 {
+	if !p.initialized.Load() || p.sinkManager.r == nil {
+		return p.conflictCountReported
+	}
+	if now := time.Now(); now.Sub(p.conflictCountReportedAt) >= conflictCountReportInterval {
+		p.conflictCountReported = p.conflictCountBase + p.sinkManager.r.ConflictCount()
+		p.conflictCountReportedAt = now
+	}
+	return p.conflictCountReported
+}
+
 // Close closes the processor. It must be called explicitly to stop all sub-components.
 func (p *processor) Close() error {
 	log.Info("processor closing ...",
@@ -803,3 +803,14 @@ func TestProcessorNotInitialized(t *testing.T) {
 	p, _, _ := initProcessor4Test(t, &liveness, false, globalVars, changefeedVars)
 	require.Nil(t, p.WriteDebugInfo(os.Stdout))
 }
+
+func TestProcessorRestoreConflictCount(t *testing.T) {
+	globalVars, changefeedVars := vars.NewGlobalVarsAndChangefeedInfo4Test()
+	liveness := model.LivenessCaptureAlive
+	p, _, _ := initProcessor4Test(t, &liveness, false, globalVars, changefeedVars)
+	// The persisted count is reported before the processor is initialized,
+	// so the count doesn't go backwards when the processor is recreated.
+	p.restoreConflictCount(7)
+	require.Equal(t, uint64(7), p.conflictCount())
+	require.Nil(t, p.slowPartitions())
+}
@@ -94,6 +94,9 @@ type SinkManager struct {
 		// sink factories in table sinks.
 		version uint64
 		errors  chan error
+		// closedConflictCount is the number of the conflicted rows written by the
+		// closed sink factories, so the conflict count isn't reset by rebuilding.
+		closedConflictCount uint64
 	}
 
 	// tableSinks is a map from tableID to tableSink.
@@ -207,33 +210,11 @@ func (m *SinkManager) Run(ctx context.Context, warnings ...chan<- error) (err er
 			zap.Error(err))
 	}()
 
-	splitTxn := util.GetOrZero(m.config.Sink.TxnAtomicity).ShouldSplitTxn()
-
 	gcErrors := make(chan error, 16)
 	sinkErrors := make(chan error, 16)
 	redoErrors := make(chan error, 16)
 
 	m.backgroundGC(gcErrors)
-	if m.sinkEg == nil {
-		var sinkCtx context.Context
-		m.sinkEg, sinkCtx = errgroup.WithContext(m.managerCtx)
-		m.startSinkWorkers(sinkCtx, m.sinkEg, splitTxn)
-		m.sinkEg.Go(func() error { return m.generateSinkTasks(sinkCtx) })
-		m.wg.Add(1)
-		go func() {
-			defer m.wg.Done()
-			if err := m.sinkEg.Wait(); err != nil && !cerror.Is(err, context.Canceled) {
-				log.Error("Worker handles or generates sink task failed",
-					zap.String("namespace", m.changefeedID.Namespace),
-					zap.String("changefeed", m.changefeedID.ID),
-					zap.Error(err))
-				select {
-				case sinkErrors <- err:
-				case <-m.managerCtx.Done():
-				}
-			}
-		}()
-	}
 	if m.redoDMLMgr != nil && m.redoEg == nil {
 		var redoCtx context.Context
 		m.redoEg, redoCtx = errgroup.WithContext(m.managerCtx)
@@ -264,6 +245,28 @@ func (m *SinkManager) Run(ctx context.Context, warnings ...chan<- error) (err er
 	// SinkManager will restart some internal modules if necessasry.
 	for {
 		sinkFactoryErrors, sinkFactoryVersion := m.initSinkFactory()
+		// The sink workers are started after the sink factory is created,
+		// because whether to split the transactions depends on the sink.
+		if splitTxn, ok := m.shouldSplitTxn(); ok && m.sinkEg == nil {
+			var sinkCtx context.Context
+			m.sinkEg, sinkCtx = errgroup.WithContext(m.managerCtx)
+			m.startSinkWorkers(sinkCtx, m.sinkEg, splitTxn)
+			m.sinkEg.Go(func() error { return m.generateSinkTasks(sinkCtx) })
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				if err := m.sinkEg.Wait(); err != nil && !cerror.Is(err, context.Canceled) {
+					log.Error("Worker handles or generates sink task failed",
+						zap.String("namespace", m.changefeedID.Namespace),
+						zap.String("changefeed", m.changefeedID.ID),
+						zap.Error(err))
+					select {
+					case sinkErrors <- err:
+					case <-m.managerCtx.Done():
+					}
+				}
+			}()
+		}
 
 		select {
 		case <-m.managerCtx.Done():
@@ -373,6 +376,18 @@ func (m *SinkManager) initSinkFactory() (chan error, uint64) {
 	return m.sinkFactory.errors, m.sinkFactory.version
 }
 
+// shouldSplitTxn returns whether the transactions can be split into multiple
+// batches, the second return value is false if the sink factory isn't created.
+func (m *SinkManager) shouldSplitTxn() (bool, bool) {
+	m.sinkFactory.Lock()
+	defer m.sinkFactory.Unlock()
+	if m.sinkFactory.f == nil {
+		return false, false
+	}
+	splitTxn := util.GetOrZero(m.config.Sink.TxnAtomicity).ShouldSplitTxn()
+	return splitTxn && !m.sinkFactory.f.RequireAtomicTxn(), true
+}
+
 func (m *SinkManager) clearSinkFactory() {
 	m.sinkFactory.Lock()
 	defer m.sinkFactory.Unlock()
@@ -381,6 +396,7 @@ func (m *SinkManager) clearSinkFactory() {
 			zap.String("namespace", m.changefeedID.Namespace),
 			zap.String("changefeed", m.changefeedID.ID),
 			zap.Uint64("factoryVersion", m.sinkFactory.version))
+		m.sinkFactory.closedConflictCount += m.sinkFactory.f.ConflictCount()
 		m.sinkFactory.f.Close()
 		m.sinkFactory.f = nil
 		log.Info("Sink manager has closed sink factory",
@@ -1028,6 +1044,29 @@ func (m *SinkManager) GetTableStats(span tablepb.Span) TableStats {
 	}
 }
 
+// SlowPartitions returns the slow partitions of the sink, it's nil if
+// the sink doesn't track the downstream partitions or is not created yet.
+func (m *SinkManager) SlowPartitions() []model.SlowPartition {
+	m.sinkFactory.Lock()
+	defer m.sinkFactory.Unlock()
+	if m.sinkFactory.f == nil {
+		return nil
+	}
+	return m.sinkFactory.f.SlowPartitions()
+}
+
+// ConflictCount returns the number of the conflicted rows written by the sink
+// since the manager is created, it never decreases when the sink is rebuilt.
+func (m *SinkManager) ConflictCount() uint64 {
+	m.sinkFactory.Lock()
+	defer m.sinkFactory.Unlock()
+	count := m.sinkFactory.closedConflictCount
+	if m.sinkFactory.f != nil {
+		count += m.sinkFactory.f.ConflictCount()
+	}
+	return count
+}
+
 // WaitForReady implements pkg/util.Runnable.
 func (m *SinkManager) WaitForReady(ctx context.Context) {
 	select {
@@ -0,0 +1,78 @@
+//  Copyright 2024 PingCAP, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package common
+
+import (
+	"encoding/json"
+	"sort"
+
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/redo"
+)
+
+// SegmentIndex is the index of a compacted redo log segment, in which the redo
+// logs are sorted by commit ts. It is stored beside the segment, and the name
+// of the index is the name of the segment with redo.IndexEXT appended.
+type SegmentIndex struct {
+	// Segment is the path of the compacted segment.
+	Segment string `json:"segment"`
+	// Sources are the paths of the segments merged into the compacted segment,
+	// they should be ignored if the compacted segment exists.
+	Sources     []string       `json:"sources"`
+	MinCommitTs uint64         `json:"min-commit-ts"`
+	MaxCommitTs uint64         `json:"max-commit-ts"`
+	Blocks      []SegmentBlock `json:"blocks"`
+}
+
+// SegmentBlock is a range of the compacted segment which starts at a frame.
+type SegmentBlock struct {
+	Offset      int64  `json:"offset"`
+	MinCommitTs uint64 `json:"min-commit-ts"`
+	MaxCommitTs uint64 `json:"max-commit-ts"`
+}
+
+// GetIndexFileName returns the name of the index of the compacted segment.
+func GetIndexFileName(segment string) string {
+	return segment + redo.IndexEXT
+}
+
+// Seek returns the offset of the first block which may contain redo logs whose
+// commit ts is greater than startTs, and false if there is no such block.
+func (idx *SegmentIndex) Seek(startTs uint64) (int64, bool) {
+	i := sort.Search(len(idx.Blocks), func(i int) bool {
+		return idx.Blocks[i].MaxCommitTs > startTs
+	})
+	if i == len(idx.Blocks) {
+		return 0, false
+	}
+	return idx.Blocks[i].Offset, true
+}
+
+// Marshal encodes the index.
+func (idx *SegmentIndex) Marshal() ([]byte, error) {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrMarshalFailed, err)
+	}
+	return data, nil
+}
+
+// UnmarshalSegmentIndex decodes the index.
+func UnmarshalSegmentIndex(data []byte) (*SegmentIndex, error) {
+	idx := &SegmentIndex{}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, cerror.WrapError(cerror.ErrUnmarshalFailed, err)
+	}
+	return idx, nil
+}
@@ -0,0 +1,64 @@
+//  Copyright 2024 PingCAP, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSegmentIndex(t *testing.T) {
+	t.Parallel()
+
+	idx := &SegmentIndex{
+		Segment:     "capture_test_row_30_uuid.log",
+		Sources:     []string{"capture_test_row_10_uuid1.log", "capture_test_row_30_uuid2.log"},
+		MinCommitTs: 1,
+		MaxCommitTs: 30,
+		Blocks: []SegmentBlock{
+			{Offset: 0, MinCommitTs: 1, MaxCommitTs: 10},
+			{Offset: 1024, MinCommitTs: 10, MaxCommitTs: 20},
+			{Offset: 2048, MinCommitTs: 21, MaxCommitTs: 30},
+		},
+	}
+	require.Equal(t, "capture_test_row_30_uuid.log.index", GetIndexFileName(idx.Segment))
+
+	cases := []struct {
+		startTs uint64
+		offset  int64
+		ok      bool
+	}{
+		{startTs: 0, offset: 0, ok: true},
+		{startTs: 9, offset: 0, ok: true},
+		// the redo logs with the same commit ts may span two blocks.
+		{startTs: 10, offset: 1024, ok: true},
+		{startTs: 25, offset: 2048, ok: true},
+		{startTs: 30, ok: false},
+	}
+	for _, c := range cases {
+		offset, ok := idx.Seek(c.startTs)
+		require.Equal(t, c.ok, ok, c.startTs)
+		require.Equal(t, c.offset, offset, c.startTs)
+	}
+
+	data, err := idx.Marshal()
+	require.NoError(t, err)
+	decoded, err := UnmarshalSegmentIndex(data)
+	require.NoError(t, err)
+	require.Equal(t, idx, decoded)
+
+	_, err = UnmarshalSegmentIndex([]byte("invalid"))
+	require.Error(t, err)
+}
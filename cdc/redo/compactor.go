@@ -0,0 +1,291 @@
+//  Copyright 2024 PingCAP, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package redo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/model/codec"
+	"github.com/pingcap/tiflow/cdc/redo/common"
+	"github.com/pingcap/tiflow/cdc/redo/reader"
+	"github.com/pingcap/tiflow/cdc/redo/writer"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/redo"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/pingcap/tiflow/pkg/uuid"
+	"go.uber.org/zap"
+)
+
+// compactor merges the closed redo log segments of a changefeed into larger
+// segments sorted by commit ts, and writes an index of the ts ranges beside each
+// compacted segment, so that the reader can seek to a start ts directly.
+//
+// A compaction writes the index first, then the compacted segment, and deletes
+// the merged segments at last. The reader ignores an index whose segment doesn't
+// exist, and skips the merged segments of an index whose segment exists, so an
+// interrupted compaction never makes the redo logs lost or duplicated. The
+// leftovers of an interrupted compaction are cleaned up in the next round.
+type compactor struct {
+	captureID     model.CaptureID
+	changeFeedID  model.ChangeFeedID
+	extStorage    storage.ExternalStorage
+	uuidGenerator uuid.Generator
+
+	maxSegmentSize int64
+	blockSize      int64
+}
+
+func newCompactor(
+	captureID model.CaptureID,
+	changeFeedID model.ChangeFeedID,
+	extStorage storage.ExternalStorage,
+	uuidGenerator uuid.Generator,
+	cfg *config.ConsistentCompaction,
+) *compactor {
+	return &compactor{
+		captureID:      captureID,
+		changeFeedID:   changeFeedID,
+		extStorage:     extStorage,
+		uuidGenerator:  uuidGenerator,
+		maxSegmentSize: cfg.MaxSegmentSize * redo.Megabyte,
+		blockSize:      redo.CompactionBlockSize,
+	}
+}
+
+type segmentFile struct {
+	path     string
+	size     int64
+	commitTs uint64
+	fileType string
+}
+
+// compact merges the segments whose commit ts in name is in (gcTs, resolvedTs].
+func (c *compactor) compact(ctx context.Context, gcTs, resolvedTs uint64) error {
+	changefeedMatcher := getChangefeedMatcher(c.changeFeedID)
+	existing := make(map[string]struct{})
+	indexFiles := make([]string, 0)
+	segments := make([]*segmentFile, 0)
+	err := c.extStorage.WalkDir(ctx, &storage.WalkOption{}, func(path string, size int64) error {
+		path = strings.TrimPrefix(path, "/")
+		if !strings.Contains(path, changefeedMatcher) {
+			return nil
+		}
+		existing[path] = struct{}{}
+		switch filepath.Ext(path) {
+		case redo.IndexEXT:
+			indexFiles = append(indexFiles, path)
+		case redo.LogEXT:
+			commitTs, fileType, err := redo.ParseLogFileName(path)
+			if err != nil {
+				log.Warn("redo compactor ignores the file with invalid name",
+					zap.String("namespace", c.changeFeedID.Namespace),
+					zap.String("changefeed", c.changeFeedID.ID),
+					zap.String("path", path), zap.Error(err))
+				return nil
+			}
+			segments = append(segments, &segmentFile{
+				path: path, size: size, commitTs: commitTs, fileType: fileType,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.WrapError(errors.ErrExternalStorageAPI, err)
+	}
+
+	// clean up the leftovers of the interrupted compactions.
+	compacted := make(map[string]struct{})
+	leftovers := make([]string, 0)
+	for _, indexFile := range indexFiles {
+		data, err := c.extStorage.ReadFile(ctx, indexFile)
+		if err != nil {
+			// the index may be removed by gc concurrently.
+			log.Warn("redo compactor fails to read the index",
+				zap.String("namespace", c.changeFeedID.Namespace),
+				zap.String("changefeed", c.changeFeedID.ID),
+				zap.String("path", indexFile), zap.Error(err))
+			continue
+		}
+		idx, err := common.UnmarshalSegmentIndex(data)
+		if err != nil {
+			return err
+		}
+		if _, ok := existing[idx.Segment]; !ok {
+			leftovers = append(leftovers, indexFile)
+			continue
+		}
+		compacted[idx.Segment] = struct{}{}
+		for _, source := range idx.Sources {
+			if _, ok := existing[source]; ok {
+				leftovers = append(leftovers, source)
+				compacted[source] = struct{}{}
+			}
+		}
+	}
+	if len(leftovers) > 0 {
+		log.Info("redo compactor cleans up the leftovers of interrupted compactions",
+			zap.String("namespace", c.changeFeedID.Namespace),
+			zap.String("changefeed", c.changeFeedID.ID),
+			zap.Strings("files", leftovers))
+		if err := util.DeleteFilesInExtStorage(ctx, c.extStorage, leftovers); err != nil {
+			return errors.WrapError(errors.ErrExternalStorageAPI, err)
+		}
+	}
+
+	candidates := make(map[string][]*segmentFile)
+	for _, seg := range segments {
+		if _, ok := compacted[seg.path]; ok {
+			continue
+		}
+		if seg.commitTs <= gcTs || seg.commitTs > resolvedTs || seg.size >= c.maxSegmentSize {
+			continue
+		}
+		candidates[seg.fileType] = append(candidates[seg.fileType], seg)
+	}
+	for _, fileType := range []string{redo.RedoRowLogFileType, redo.RedoDDLLogFileType} {
+		for _, batch := range c.splitBatches(candidates[fileType]) {
+			if err := c.merge(ctx, fileType, batch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitBatches splits the segments sorted by commit ts into batches whose
+// total size doesn't exceed maxSegmentSize, a batch with only one segment is
+// dropped since there is nothing to merge.
+func (c *compactor) splitBatches(segments []*segmentFile) [][]*segmentFile {
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].commitTs < segments[j].commitTs
+	})
+	var batches [][]*segmentFile
+	var batch []*segmentFile
+	var batchSize int64
+	for _, seg := range segments {
+		if len(batch) > 0 && batchSize+seg.size > c.maxSegmentSize {
+			if len(batch) > 1 {
+				batches = append(batches, batch)
+			}
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, seg)
+		batchSize += seg.size
+	}
+	if len(batch) > 1 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// merge merges the segments into a compacted segment.
+func (c *compactor) merge(ctx context.Context, fileType string, batch []*segmentFile) error {
+	start := time.Now()
+	sources := make([]string, 0, len(batch))
+	logs := make([]*model.RedoLog, 0)
+	for _, seg := range batch {
+		data, err := c.extStorage.ReadFile(ctx, seg.path)
+		if err != nil {
+			return errors.WrapError(errors.ErrExternalStorageAPI, err)
+		}
+		segLogs, err := reader.DecodeLogFile(seg.path, data)
+		if err != nil {
+			return err
+		}
+		logs = append(logs, segLogs...)
+		sources = append(sources, seg.path)
+	}
+	if len(logs) == 0 {
+		// all segments are empty, so they can be removed directly.
+		return errors.WrapError(errors.ErrExternalStorageAPI,
+			util.DeleteFilesInExtStorage(ctx, c.extStorage, sources))
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		return logs[i].GetCommitTs() < logs[j].GetCommitTs()
+	})
+
+	idx := &common.SegmentIndex{
+		Sources:     sources,
+		MinCommitTs: logs[0].GetCommitTs(),
+		MaxCommitTs: logs[len(logs)-1].GetCommitTs(),
+	}
+	// The compacted segment is not compressed, so the reader can read it
+	// from the offset of any block.
+	var buf bytes.Buffer
+	for _, rl := range logs {
+		data, err := codec.MarshalRedoLog(rl, nil)
+		if err != nil {
+			return errors.WrapError(errors.ErrMarshalFailed, err)
+		}
+		commitTs := rl.GetCommitTs()
+		offset := int64(buf.Len())
+		if len(idx.Blocks) == 0 || offset-idx.Blocks[len(idx.Blocks)-1].Offset >= c.blockSize {
+			idx.Blocks = append(idx.Blocks, common.SegmentBlock{Offset: offset, MinCommitTs: commitTs})
+		}
+		idx.Blocks[len(idx.Blocks)-1].MaxCommitTs = commitTs
+
+		lenField, padBytes := writer.EncodeFrameSize(len(data))
+		buf.Write(binary.LittleEndian.AppendUint64(nil, lenField))
+		buf.Write(data)
+		buf.Write(make([]byte, padBytes))
+	}
+	idx.Segment = c.getLogFileName(fileType, idx.MaxCommitTs)
+
+	indexData, err := idx.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := c.extStorage.WriteFile(ctx, common.GetIndexFileName(idx.Segment), indexData); err != nil {
+		return errors.WrapError(errors.ErrExternalStorageAPI, err)
+	}
+	if err := c.extStorage.WriteFile(ctx, idx.Segment, buf.Bytes()); err != nil {
+		return errors.WrapError(errors.ErrExternalStorageAPI, err)
+	}
+	if err := util.DeleteFilesInExtStorage(ctx, c.extStorage, sources); err != nil {
+		return errors.WrapError(errors.ErrExternalStorageAPI, err)
+	}
+
+	log.Info("redo compactor merges segments",
+		zap.String("namespace", c.changeFeedID.Namespace),
+		zap.String("changefeed", c.changeFeedID.ID),
+		zap.String("segment", idx.Segment),
+		zap.Int("sources", len(sources)),
+		zap.Int("events", len(logs)),
+		zap.Int("blocks", len(idx.Blocks)),
+		zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+func (c *compactor) getLogFileName(fileType string, maxCommitTs model.Ts) string {
+	uid := c.uuidGenerator.NewString()
+	if model.DefaultNamespace == c.changeFeedID.Namespace {
+		return fmt.Sprintf(redo.RedoLogFileFormatV1,
+			c.captureID, c.changeFeedID.ID, fileType,
+			maxCommitTs, uid, redo.LogEXT)
+	}
+	return fmt.Sprintf(redo.RedoLogFileFormatV2,
+		c.captureID, c.changeFeedID.Namespace, c.changeFeedID.ID,
+		fileType, maxCommitTs, uid, redo.LogEXT)
+}
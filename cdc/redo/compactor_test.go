@@ -0,0 +1,261 @@
+//  Copyright 2024 PingCAP, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package redo
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/model/codec"
+	"github.com/pingcap/tiflow/cdc/redo/common"
+	"github.com/pingcap/tiflow/cdc/redo/reader"
+	"github.com/pingcap/tiflow/cdc/redo/writer"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/redo"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/pingcap/tiflow/pkg/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+func writeTestSegment(
+	ctx context.Context, t *testing.T, extStorage storage.ExternalStorage,
+	changefeedID model.ChangeFeedID, logType string, commitTs ...uint64,
+) string {
+	var data []byte
+	var maxCommitTs uint64
+	for _, ts := range commitTs {
+		var rl *model.RedoLog
+		if logType == redo.RedoRowLogFileType {
+			rl = (&model.RowChangedEvent{
+				CommitTs:  ts,
+				TableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test", Table: "t"}},
+			}).ToRedoLog()
+		} else {
+			rl = (&model.DDLEvent{CommitTs: ts, TableInfo: &model.TableInfo{}}).ToRedoLog()
+		}
+		rawData, err := codec.MarshalRedoLog(rl, nil)
+		require.NoError(t, err)
+		lenField, padBytes := writer.EncodeFrameSize(len(rawData))
+		data = binary.LittleEndian.AppendUint64(data, lenField)
+		data = append(data, rawData...)
+		data = append(data, make([]byte, padBytes)...)
+		if ts > maxCommitTs {
+			maxCommitTs = ts
+		}
+	}
+	name := fmt.Sprintf(redo.RedoLogFileFormatV1, "capture", changefeedID.ID,
+		logType, maxCommitTs, uuid.NewGenerator().NewString(), redo.LogEXT)
+	require.NoError(t, extStorage.WriteFile(ctx, name, data))
+	return name
+}
+
+func listFiles(ctx context.Context, t *testing.T, extStorage storage.ExternalStorage) map[string]struct{} {
+	files := make(map[string]struct{})
+	err := extStorage.WalkDir(ctx, nil, func(path string, _ int64) error {
+		files[path] = struct{}{}
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestCompactorCompact(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	changefeedID := model.DefaultChangeFeedID("test-changefeed")
+	extStorage, _, err := util.GetTestExtStorage(ctx, t.TempDir())
+	require.NoError(t, err)
+
+	rows := []string{
+		writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoRowLogFileType, 12, 11, 10),
+		writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoRowLogFileType, 20, 13, 15),
+		writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoRowLogFileType, 14, 30, 16),
+	}
+	ddls := []string{
+		writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoDDLLogFileType, 15),
+		writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoDDLLogFileType, 25),
+	}
+	ignored := []string{
+		// before gc ts
+		writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoRowLogFileType, 5),
+		// after resolved ts
+		writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoRowLogFileType, 41, 50),
+		// another changefeed
+		writeTestSegment(ctx, t, extStorage, model.DefaultChangeFeedID("other"), redo.RedoRowLogFileType, 20),
+		writeTestSegment(ctx, t, extStorage, model.DefaultChangeFeedID("other"), redo.RedoRowLogFileType, 30),
+	}
+
+	c := newCompactor("capture", changefeedID, extStorage, uuid.NewGenerator(),
+		&config.ConsistentCompaction{MaxSegmentSize: 1})
+	c.blockSize = 256
+	require.NoError(t, c.compact(ctx, 5, 40))
+
+	files := listFiles(ctx, t, extStorage)
+	require.Len(t, files, len(ignored)+4)
+	for _, name := range ignored {
+		require.Contains(t, files, name)
+	}
+
+	check := func(logType string, sources []string, expected []uint64) {
+		var idx *common.SegmentIndex
+		for name := range files {
+			if filepath.Ext(name) != redo.IndexEXT {
+				continue
+			}
+			data, err := extStorage.ReadFile(ctx, name)
+			require.NoError(t, err)
+			i, err := common.UnmarshalSegmentIndex(data)
+			require.NoError(t, err)
+			if _, fileType, _ := redo.ParseLogFileName(i.Segment); fileType == logType {
+				idx = i
+			}
+		}
+		require.NotNil(t, idx)
+		require.Contains(t, files, idx.Segment)
+		commitTs, _, err := redo.ParseLogFileName(idx.Segment)
+		require.NoError(t, err)
+		require.Equal(t, expected[len(expected)-1], commitTs)
+		require.Equal(t, sources, idx.Sources)
+		require.Equal(t, expected[0], idx.MinCommitTs)
+		require.Equal(t, expected[len(expected)-1], idx.MaxCommitTs)
+
+		data, err := extStorage.ReadFile(ctx, idx.Segment)
+		require.NoError(t, err)
+		logs, err := reader.DecodeLogFile(idx.Segment, data)
+		require.NoError(t, err)
+		actual := make([]uint64, 0, len(logs))
+		for _, rl := range logs {
+			actual = append(actual, rl.GetCommitTs())
+		}
+		require.Equal(t, expected, actual)
+
+		// every block starts at a frame, and the blocks cover all the redo logs.
+		events := 0
+		for i, block := range idx.Blocks {
+			end := int64(len(data))
+			if i+1 < len(idx.Blocks) {
+				end = idx.Blocks[i+1].Offset
+			}
+			logs, err := reader.DecodeLogFile(idx.Segment, data[block.Offset:end])
+			require.NoError(t, err)
+			require.Equal(t, block.MinCommitTs, logs[0].GetCommitTs())
+			require.Equal(t, block.MaxCommitTs, logs[len(logs)-1].GetCommitTs())
+			events += len(logs)
+		}
+		require.Equal(t, len(expected), events)
+	}
+	check(redo.RedoRowLogFileType, rows, []uint64{10, 11, 12, 13, 14, 15, 16, 20, 30})
+	check(redo.RedoDDLLogFileType, ddls, []uint64{15, 25})
+
+	// the compacted segments are not compacted again.
+	require.NoError(t, c.compact(ctx, 5, 40))
+	require.Equal(t, files, listFiles(ctx, t, extStorage))
+}
+
+func TestCompactorSplitBatches(t *testing.T) {
+	t.Parallel()
+
+	c := &compactor{maxSegmentSize: 100}
+	segments := []*segmentFile{
+		{path: "d", size: 60, commitTs: 4},
+		{path: "a", size: 30, commitTs: 1},
+		{path: "b", size: 30, commitTs: 2},
+		{path: "c", size: 30, commitTs: 3},
+		{path: "e", size: 40, commitTs: 5},
+		{path: "f", size: 80, commitTs: 6},
+	}
+	var batches [][]string
+	for _, batch := range c.splitBatches(segments) {
+		var paths []string
+		for _, seg := range batch {
+			paths = append(paths, seg.path)
+		}
+		batches = append(batches, paths)
+	}
+	require.Equal(t, [][]string{{"a", "b", "c"}, {"d", "e"}}, batches)
+}
+
+func TestCompactorCleanupLeftovers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	changefeedID := model.DefaultChangeFeedID("test-changefeed")
+	extStorage, _, err := util.GetTestExtStorage(ctx, t.TempDir())
+	require.NoError(t, err)
+	writeIndex := func(idx *common.SegmentIndex) string {
+		data, err := idx.Marshal()
+		require.NoError(t, err)
+		name := common.GetIndexFileName(idx.Segment)
+		require.NoError(t, extStorage.WriteFile(ctx, name, data))
+		return name
+	}
+
+	// the compacted segment is written but the sources are not deleted.
+	source := writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoRowLogFileType, 10)
+	segment := writeTestSegment(ctx, t, extStorage, changefeedID, redo.RedoRowLogFileType, 10, 20)
+	index := writeIndex(&common.SegmentIndex{Segment: segment, Sources: []string{source}})
+	// the index is written but the compacted segment is not.
+	unfinished := writeIndex(&common.SegmentIndex{
+		Segment: fmt.Sprintf(redo.RedoLogFileFormatV1, "capture", changefeedID.ID,
+			redo.RedoRowLogFileType, 30, "missing", redo.LogEXT),
+	})
+
+	c := newCompactor("capture", changefeedID, extStorage, uuid.NewGenerator(),
+		&config.ConsistentCompaction{MaxSegmentSize: 1})
+	require.NoError(t, c.compact(ctx, 0, 100))
+
+	files := listFiles(ctx, t, extStorage)
+	require.Equal(t, map[string]struct{}{segment: {}, index: {}}, files)
+	require.NotContains(t, files, unfinished)
+}
+
+func TestMetaManagerGCTs(t *testing.T) {
+	t.Parallel()
+
+	changefeedID := model.DefaultChangeFeedID("test-changefeed")
+	m := &metaManager{changeFeedID: changefeedID, cfg: &config.ConsistentConfig{}}
+	checkpointTs := oracle.ComposeTS(5000, 1)
+	require.Equal(t, checkpointTs, m.getGCTs(checkpointTs))
+
+	m.cfg.RetentionInMs = 1000
+	require.Equal(t, oracle.ComposeTS(4000, 0), m.getGCTs(checkpointTs))
+	require.Equal(t, uint64(0), m.getGCTs(oracle.ComposeTS(500, 0)))
+
+	segment := fmt.Sprintf(redo.RedoLogFileFormatV1, "capture", changefeedID.ID,
+		redo.RedoRowLogFileType, 10, "uuid", redo.LogEXT)
+	other := fmt.Sprintf(redo.RedoLogFileFormatV1, "capture", "other",
+		redo.RedoRowLogFileType, 10, "uuid", redo.LogEXT)
+	cases := []struct {
+		path   string
+		gcTs   uint64
+		remove bool
+	}{
+		{path: segment, gcTs: 11, remove: true},
+		{path: segment, gcTs: 10, remove: false},
+		{path: common.GetIndexFileName(segment), gcTs: 11, remove: true},
+		{path: common.GetIndexFileName(segment), gcTs: 10, remove: false},
+		{path: common.GetIndexFileName(other), gcTs: 11, remove: false},
+		{path: getMetafileName("capture", changefeedID, uuid.NewGenerator()), gcTs: 11, remove: false},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.remove, m.shouldRemoved(c.path, c.gcTs), c.path)
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/util"
 	"github.com/pingcap/tiflow/pkg/uuid"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tikv/client-go/v2/oracle"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -68,6 +69,7 @@ type metaManager struct {
 	extStorage    storage.ExternalStorage
 	uuidGenerator uuid.Generator
 	preMetaFile   string
+	compactor     *compactor
 
 	startTs model.Ts
 
@@ -142,6 +144,10 @@ func (m *metaManager) preStart(ctx context.Context) error {
 		return err
 	}
 	m.extStorage = extStorage
+	if m.cfg.Compaction != nil {
+		m.compactor = newCompactor(m.captureID, m.changeFeedID,
+			m.extStorage, m.uuidGenerator, m.cfg.Compaction)
+	}
 
 	m.metricFlushLogDuration = common.RedoFlushLogDurationHistogram.
 		WithLabelValues(m.changeFeedID.Namespace, m.changeFeedID.ID)
@@ -165,7 +171,7 @@ func (m *metaManager) preStart(ctx context.Context) error {
 	return nil
 }
 
-// Run runs bgFlushMeta and bgGC.
+// Run runs bgFlushMeta, bgGC and bgCompact if compaction is enabled.
 func (m *metaManager) Run(ctx context.Context, _ ...chan<- error) error {
 	if err := m.preStart(ctx); err != nil {
 		return err
@@ -177,6 +183,11 @@ func (m *metaManager) Run(ctx context.Context, _ ...chan<- error) error {
 	eg.Go(func() error {
 		return m.bgGC(egCtx)
 	})
+	if m.compactor != nil {
+		eg.Go(func() error {
+			return m.bgCompact(egCtx)
+		})
+	}
 
 	m.running.Store(true)
 	return eg.Wait()
@@ -317,12 +328,14 @@ func (m *metaManager) preCleanupExtStorage(ctx context.Context) error {
 }
 
 // shouldRemoved remove the file which maxCommitTs in file name less than checkPointTs, since
-// all event ts < checkPointTs already sent to sink, the log is not needed any more for recovery
+// all event ts < checkPointTs already sent to sink, the log is not needed any more for recovery.
+// The index of a compacted segment is removed along with the segment.
 func (m *metaManager) shouldRemoved(path string, checkPointTs uint64) bool {
 	changefeedMatcher := getChangefeedMatcher(m.changeFeedID)
 	if !strings.Contains(path, changefeedMatcher) {
 		return false
 	}
+	path = strings.TrimSuffix(path, redo.IndexEXT)
 	if filepath.Ext(path) != redo.LogEXT {
 		return false
 	}
@@ -505,6 +518,19 @@ func (m *metaManager) bgFlushMeta(egCtx context.Context) (err error) {
 	}
 }
 
+// getGCTs returns the ts before which the redo logs can be removed, the redo
+// logs within the retention window before the checkpoint are retained.
+func (m *metaManager) getGCTs(checkpointTs uint64) uint64 {
+	if m.cfg.RetentionInMs <= 0 {
+		return checkpointTs
+	}
+	physical := oracle.ExtractPhysical(checkpointTs) - m.cfg.RetentionInMs
+	if physical <= 0 {
+		return 0
+	}
+	return oracle.ComposeTS(physical, 0)
+}
+
 // bgGC cleans stale files before the flushed checkpoint in background.
 func (m *metaManager) bgGC(egCtx context.Context) error {
 	ticker := time.NewTicker(time.Duration(redo.DefaultGCIntervalInMs) * time.Millisecond)
@@ -528,8 +554,9 @@ func (m *metaManager) bgGC(egCtx context.Context) error {
 				zap.Uint64("checkpointTs", ckpt),
 				zap.String("namespace", m.changeFeedID.Namespace),
 				zap.String("changefeed", m.changeFeedID.ID))
+			gcTs := m.getGCTs(ckpt)
 			err := util.RemoveFilesIf(egCtx, m.extStorage, func(path string) bool {
-				return m.shouldRemoved(path, ckpt)
+				return m.shouldRemoved(path, gcTs)
 			}, nil)
 			if err != nil {
 				log.Warn("redo manager log GC fail",
@@ -541,6 +568,36 @@ func (m *metaManager) bgGC(egCtx context.Context) error {
 	}
 }
 
+// bgCompact compacts the closed redo log segments in background.
+func (m *metaManager) bgCompact(egCtx context.Context) error {
+	ticker := time.NewTicker(time.Duration(m.cfg.Compaction.IntervalInMs) * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-egCtx.Done():
+			log.Info("redo manager compaction exits as context cancelled",
+				zap.String("namespace", m.changeFeedID.Namespace),
+				zap.String("changefeed", m.changeFeedID.ID))
+			return errors.Trace(egCtx.Err())
+		case <-ticker.C:
+			meta := m.GetFlushedMeta()
+			err := m.compactor.compact(egCtx, m.getGCTs(meta.CheckpointTs), meta.ResolvedTs)
+			if err != nil {
+				if egCtx.Err() != nil {
+					return errors.Trace(egCtx.Err())
+				}
+				// The compaction is retried in the next round, and the redo
+				// logs are still readable if it fails, so don't stop the
+				// changefeed here.
+				log.Warn("redo manager compaction fail",
+					zap.String("namespace", m.changeFeedID.Namespace),
+					zap.String("changefeed", m.changeFeedID.ID), zap.Error(err))
+			}
+		}
+	}
+}
+
 func getMetafileName(
 	captureID model.CaptureID,
 	changeFeedID model.ChangeFeedID,
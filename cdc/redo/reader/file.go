@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/cdc/model/codec"
+	"github.com/pingcap/tiflow/cdc/redo/common"
 	"github.com/pingcap/tiflow/cdc/redo/writer"
 	"github.com/pingcap/tiflow/cdc/redo/writer/file"
 	"github.com/pingcap/tiflow/pkg/compression"
@@ -53,8 +54,14 @@ const (
 	defaultWorkerNum = 16
 )
 
-// lz4MagicNumber is the magic number of lz4 compressed data
-var lz4MagicNumber = []byte{0x04, 0x22, 0x4D, 0x18}
+var (
+	// lz4MagicNumber is the magic number of lz4 compressed data
+	lz4MagicNumber = []byte{0x04, 0x22, 0x4D, 0x18}
+	// zstdMagicNumber is the magic number of zstd compressed data
+	zstdMagicNumber = []byte{0x28, 0xB5, 0x2F, 0xFD}
+	// gzipMagicNumber is the magic number of gzip compressed data
+	gzipMagicNumber = []byte{0x1F, 0x8B}
+)
 
 type fileReader interface {
 	io.Closer
@@ -130,7 +137,7 @@ func downLoadAndSortFiles(ctx context.Context, cfg *readerConfig) ([]io.ReadClos
 	if err != nil {
 		return nil, err
 	}
-	files, err := selectDownLoadFile(ctx, extStorage, cfg.fileType, cfg.startTs)
+	files, indexes, err := selectDownLoadFile(ctx, extStorage, cfg.fileType, cfg.startTs)
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +159,8 @@ func downLoadAndSortFiles(ctx context.Context, cfg *readerConfig) ([]io.ReadClos
 		sortedFileNames = append(sortedFileNames, getSortedFileName(fileName))
 		eg.Go(func() error {
 			defer func() { <-limit }()
-			return sortAndWriteFile(ctx, extStorage, fileName, cfg)
+			return sortAndWriteFile(ctx, extStorage, fileName, cfg,
+				indexes[strings.TrimPrefix(fileName, "/")])
 		})
 	}
 	if err := eg.Wait(); err != nil {
@@ -179,15 +187,25 @@ func getSortedFileName(name string) string {
 	return filepath.Base(name) + redo.SortLogEXT
 }
 
+// selectDownLoadFile returns the files need to be downloaded, and the indexes of
+// the compacted segments among them. The segments merged into a compacted segment
+// are skipped if the compacted segment exists.
 func selectDownLoadFile(
 	ctx context.Context, extStorage storage.ExternalStorage,
 	fixedType string, startTs uint64,
-) ([]string, error) {
+) ([]string, map[string]*common.SegmentIndex, error) {
 	files := []string{}
+	existing := make(map[string]struct{})
+	indexFiles := []string{}
 	// add changefeed filter and endTs filter
 	err := extStorage.WalkDir(ctx, &storage.WalkOption{},
 		func(path string, size int64) error {
+			existing[strings.TrimPrefix(path, "/")] = struct{}{}
 			fileName := filepath.Base(path)
+			if filepath.Ext(fileName) == redo.IndexEXT {
+				indexFiles = append(indexFiles, path)
+				return nil
+			}
 			ret, err := shouldOpen(startTs, fileName, fixedType)
 			if err != nil {
 				log.Warn("check selected log file fail",
@@ -201,17 +219,96 @@ func selectDownLoadFile(
 			return nil
 		})
 	if err != nil {
-		return nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+		return nil, nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+	}
+
+	indexes := make(map[string]*common.SegmentIndex)
+	skipped := make(map[string]struct{})
+	for _, indexFile := range indexFiles {
+		data, err := extStorage.ReadFile(ctx, indexFile)
+		if err != nil {
+			return nil, nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+		}
+		idx, err := common.UnmarshalSegmentIndex(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The index is written before the compacted segment, so the compaction
+		// is not finished if the segment doesn't exist.
+		if _, ok := existing[idx.Segment]; !ok {
+			continue
+		}
+		indexes[idx.Segment] = idx
+		for _, source := range idx.Sources {
+			skipped[source] = struct{}{}
+		}
+	}
+	if len(skipped) == 0 {
+		return files, indexes, nil
 	}
 
-	return files, nil
+	selected := make([]string, 0, len(files))
+	for _, file := range files {
+		if _, ok := skipped[strings.TrimPrefix(file, "/")]; ok {
+			log.Info("skip the log file merged into a compacted segment",
+				zap.String("logFile", file))
+			continue
+		}
+		selected = append(selected, file)
+	}
+	return selected, indexes, nil
+}
+
+// compressionOf returns the compression of the data by the magic number.
+func compressionOf(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, lz4MagicNumber):
+		return compression.LZ4
+	case bytes.HasPrefix(data, zstdMagicNumber):
+		return compression.Zstd
+	case bytes.HasPrefix(data, gzipMagicNumber):
+		return compression.Gzip
+	}
+	return compression.None
 }
 
-func isLZ4Compressed(data []byte) bool {
-	if len(data) < 4 {
-		return false
+// decompressLogFile decompresses the content of the log file if it's compressed.
+func decompressLogFile(fileName string, content []byte) ([]byte, error) {
+	cc := compressionOf(content)
+	if cc == compression.None {
+		return content, nil
+	}
+	decoded, err := compression.Decode(cc, content)
+	switch {
+	case err == nil:
+		return decoded, nil
+	case cc == compression.Gzip:
+		// the magic number of gzip is short, so an uncompressed log file
+		// may start with it, the gzip checksum makes sure it's not
+		// decoded by mistake.
+		log.Info("file is not gzip compressed", zap.String("file", fileName))
+		return content, nil
+	default:
+		return nil, err
+	}
+}
+
+// DecodeLogFile decodes the redo logs in the content of a log file, the content
+// is decompressed first if it's compressed.
+func DecodeLogFile(fileName string, content []byte) ([]*model.RedoLog, error) {
+	content, err := decompressLogFile(fileName, content)
+	if err != nil {
+		return nil, err
 	}
-	return bytes.Equal(data[:4], lz4MagicNumber)
+	h, err := readAllFromBuffer(content)
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]*model.RedoLog, 0, len(h))
+	for _, item := range h {
+		logs = append(logs, item.data)
+	}
+	return logs, nil
 }
 
 func readAllFromBuffer(buf []byte) (logHeap, error) {
@@ -236,11 +333,13 @@ func readAllFromBuffer(buf []byte) (logHeap, error) {
 }
 
 // sortAndWriteFile read file from external storage, then sort the file and write
-// to local storage.
+// to local storage. If the file is a compacted segment, only the blocks after
+// startTs are read with the help of the index.
 func sortAndWriteFile(
 	egCtx context.Context,
 	extStorage storage.ExternalStorage,
 	fileName string, cfg *readerConfig,
+	idx *common.SegmentIndex,
 ) error {
 	sortedName := getSortedFileName(fileName)
 	writerCfg := &writer.LogWriterConfig{
@@ -254,19 +353,17 @@ func sortAndWriteFile(
 		return err
 	}
 
-	fileContent, err := extStorage.ReadFile(egCtx, fileName)
+	fileContent, err := readLogFile(egCtx, extStorage, fileName, cfg.startTs, idx)
 	if err != nil {
-		return cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+		return err
 	}
 	if len(fileContent) == 0 {
 		log.Warn("download file is empty", zap.String("file", fileName))
 		return nil
 	}
-	// it's lz4 compressed, decompress it
-	if isLZ4Compressed(fileContent) {
-		if fileContent, err = compression.Decode(compression.LZ4, fileContent); err != nil {
-			return err
-		}
+	fileContent, err = decompressLogFile(fileName, fileContent)
+	if err != nil {
+		return err
 	}
 
 	// sort data
@@ -302,6 +399,44 @@ func sortAndWriteFile(
 	return w.Close()
 }
 
+// readLogFile reads the content of the log file. The compacted segment is
+// uncompressed and sorted, so it's read from the first block which may
+// contain redo logs after startTs.
+func readLogFile(
+	ctx context.Context, extStorage storage.ExternalStorage,
+	fileName string, startTs uint64, idx *common.SegmentIndex,
+) ([]byte, error) {
+	var offset int64
+	if idx != nil {
+		off, ok := idx.Seek(startTs)
+		if !ok {
+			return nil, nil
+		}
+		offset = off
+	}
+	if offset == 0 {
+		content, err := extStorage.ReadFile(ctx, fileName)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+		}
+		return content, nil
+	}
+
+	log.Info("seek compacted segment by index",
+		zap.String("file", fileName), zap.Uint64("startTs", startTs),
+		zap.Int64("offset", offset))
+	r, err := extStorage.Open(ctx, fileName, &storage.ReaderOption{StartOffset: &offset})
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+	}
+	return content, nil
+}
+
 func shouldOpen(startTs uint64, name, fixedType string) (bool, error) {
 	// .sort.tmp will return error
 	commitTs, fileType, err := redo.ParseLogFileName(name)
@@ -81,6 +81,11 @@ type LogReaderConfig struct {
 	// will load the file to memory first then write the sorted file to disk
 	// the memory used is WorkerNums * defaultMaxLogSize (64 * megabyte) total
 	WorkerNums int
+
+	// StartTs is the ts after which the redo logs are read, it can be less than
+	// the checkpoint ts in meta if the redo logs are retained by consistent.retention.
+	// Default is 0, it means reading from the checkpoint ts in meta.
+	StartTs uint64
 }
 
 // LogReader implement RedoLogReader interface
@@ -141,10 +146,18 @@ func (l *LogReader) Run(ctx context.Context) error {
 	return eg.Wait()
 }
 
+// getStartTs returns the ts after which the redo logs are read.
+func (l *LogReader) getStartTs() uint64 {
+	if l.cfg.StartTs != 0 {
+		return l.cfg.StartTs
+	}
+	return l.meta.CheckpointTs
+}
+
 func (l *LogReader) runRowReader(egCtx context.Context) error {
 	defer close(l.rowCh)
 	rowCfg := &readerConfig{
-		startTs:            l.meta.CheckpointTs,
+		startTs:            l.getStartTs(),
 		endTs:              l.meta.ResolvedTs,
 		dir:                l.cfg.Dir,
 		fileType:           redo.RedoRowLogFileType,
@@ -158,7 +171,7 @@ func (l *LogReader) runRowReader(egCtx context.Context) error {
 func (l *LogReader) runDDLReader(egCtx context.Context) error {
 	defer close(l.ddlCh)
 	ddlCfg := &readerConfig{
-		startTs:            l.meta.CheckpointTs - 1,
+		startTs:            l.getStartTs() - 1,
 		endTs:              l.meta.ResolvedTs,
 		dir:                l.cfg.Dir,
 		fileType:           redo.RedoDDLLogFileType,
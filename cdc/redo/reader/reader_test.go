@@ -15,6 +15,7 @@ package reader
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"net/url"
 	"os"
@@ -132,6 +133,94 @@ func TestReadLogs(t *testing.T) {
 	require.ErrorIs(t, eg.Wait(), nil)
 }
 
+func TestLogReaderWithCompactedSegment(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	frames := func(minCommitTs, maxCommitTs uint64) []byte {
+		var data []byte
+		for ts := minCommitTs; ts <= maxCommitTs; ts++ {
+			event := &model.RowChangedEvent{
+				CommitTs:  ts,
+				TableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test", Table: "t"}},
+			}
+			rawData, err := codec.MarshalRedoLog(event.ToRedoLog(), nil)
+			require.NoError(t, err)
+			lenField, padBytes := writer.EncodeFrameSize(len(rawData))
+			data = binary.LittleEndian.AppendUint64(data, lenField)
+			data = append(data, rawData...)
+			data = append(data, make([]byte, padBytes)...)
+		}
+		return data
+	}
+	writeFile := func(commitTs uint64, data []byte) string {
+		name := fmt.Sprintf(redo.RedoLogFileFormatV2, "capture", "default",
+			"changefeed", redo.RedoRowLogFileType, commitTs, uuid.NewString(), redo.LogEXT)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, redo.DefaultFileMode))
+		return name
+	}
+	writeIndex := func(idx *common.SegmentIndex) {
+		data, err := idx.Marshal()
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir,
+			common.GetIndexFileName(idx.Segment)), data, redo.DefaultFileMode))
+	}
+
+	// The first block of the compacted segment is not decodable, the reader
+	// must seek to the second block by the index.
+	merged := writeFile(30, frames(21, 30))
+	garbage := binary.LittleEndian.AppendUint64(nil, 0xffffffff)
+	segment := writeFile(40, append(garbage, frames(21, 40)...))
+	writeIndex(&common.SegmentIndex{
+		Segment:     segment,
+		Sources:     []string{merged},
+		MinCommitTs: 1,
+		MaxCommitTs: 40,
+		Blocks: []common.SegmentBlock{
+			{Offset: 0, MinCommitTs: 1, MaxCommitTs: 20},
+			{Offset: int64(len(garbage)), MinCommitTs: 21, MaxCommitTs: 40},
+		},
+	})
+	// The index of an unfinished compaction is ignored.
+	unmerged := writeFile(50, frames(41, 50))
+	writeIndex(&common.SegmentIndex{
+		Segment: "capture_default_changefeed_row_60_missing.log",
+		Sources: []string{unmerged},
+	})
+
+	uri, err := url.Parse(fmt.Sprintf("file://%s", dir))
+	require.NoError(t, err)
+	r := &LogReader{
+		cfg: &LogReaderConfig{
+			Dir:                t.TempDir(),
+			URI:                *uri,
+			UseExternalStorage: true,
+			StartTs:            25,
+		},
+		meta:  &common.LogMeta{CheckpointTs: 30, ResolvedTs: 100},
+		rowCh: make(chan *model.RowChangedEventInRedoLog, defaultReaderChanSize),
+		ddlCh: make(chan *model.DDLEvent, defaultReaderChanSize),
+	}
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return r.Run(egCtx)
+	})
+
+	for ts := uint64(26); ts <= 50; ts++ {
+		row, err := r.ReadNextRow(egCtx)
+		require.NoError(t, err)
+		require.Equal(t, ts, row.CommitTs)
+	}
+	row, err := r.ReadNextRow(egCtx)
+	require.NoError(t, err)
+	require.Nil(t, row)
+
+	cancel()
+	require.ErrorIs(t, eg.Wait(), nil)
+}
+
 func TestLogReaderClose(t *testing.T) {
 	t.Parallel()
 
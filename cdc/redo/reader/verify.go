@@ -0,0 +1,401 @@
+//  Copyright 2024 PingCAP, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package reader
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/model/codec"
+	"github.com/pingcap/tiflow/cdc/redo/common"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/redo"
+)
+
+const (
+	// ProblemCorrupted means the file can't be decoded.
+	ProblemCorrupted = "corrupted"
+	// ProblemMissing means some redo logs are lost, such as an unfinished
+	// segment or a segment whose tail is truncated.
+	ProblemMissing = "missing"
+	// ProblemInconsistent means the content doesn't match the meta or the
+	// segment name, so the reader may skip some redo logs.
+	ProblemInconsistent = "inconsistent"
+	// ProblemLeftover means the file is left by an interrupted compaction. It
+	// is ignored by the reader and removed by the next compaction.
+	ProblemLeftover = "leftover"
+)
+
+// VerifyProblem is a problem found by Verify.
+type VerifyProblem struct {
+	File    string `json:"file,omitempty"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// SegmentInfo is the summary of a redo log segment.
+type SegmentInfo struct {
+	File string `json:"file"`
+	Type string `json:"type"`
+	// CommitTs is the commit ts in the segment name, which is the max
+	// commit ts of the redo logs in a finished segment.
+	CommitTs    uint64 `json:"commit-ts"`
+	Size        int64  `json:"size"`
+	Events      int    `json:"events"`
+	MinCommitTs uint64 `json:"min-commit-ts"`
+	MaxCommitTs uint64 `json:"max-commit-ts"`
+	// Compacted is true if the segment is merged from other segments by the
+	// compaction, in which the redo logs are sorted by commit ts.
+	Compacted bool `json:"compacted,omitempty"`
+}
+
+// VerifyReport is the result of Verify.
+type VerifyReport struct {
+	CheckpointTs uint64           `json:"checkpoint-ts"`
+	ResolvedTs   uint64           `json:"resolved-ts"`
+	MetaFiles    int              `json:"meta-files"`
+	Segments     []*SegmentInfo   `json:"segments"`
+	Problems     []*VerifyProblem `json:"problems"`
+}
+
+func (r *VerifyReport) addProblem(file, kind, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, &VerifyProblem{
+		File:    file,
+		Kind:    kind,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Verify checks the integrity of the redo logs in the storage. It decodes every
+// frame of the segments, checks the commit ts of the redo logs against the
+// segment names which are used to select segments by the reader, checks the
+// order of the segments and the indexes of the compacted segments, and checks
+// the consistency of the meta files. The problems found are recorded in the
+// report, an error is returned only if the storage can't be accessed.
+//
+// Note that the frames of redo logs carry no checksum, so a corruption which
+// leaves the frame decodable can't be detected.
+func Verify(ctx context.Context, uri url.URL) (*VerifyReport, error) {
+	extStorage, err := redo.InitExternalStorage(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{}
+	metas := make([]*common.LogMeta, 0, 64)
+	segments := make([]*SegmentInfo, 0, 64)
+	indexFiles := make([]string, 0)
+	existing := make(map[string]struct{})
+	err = extStorage.WalkDir(ctx, nil, func(path string, size int64) error {
+		existing[strings.TrimPrefix(path, "/")] = struct{}{}
+		name := filepath.Base(path)
+		if strings.HasSuffix(name, redo.SortLogEXT) {
+			return nil
+		}
+		if filepath.Ext(name) == redo.IndexEXT {
+			indexFiles = append(indexFiles, path)
+			return nil
+		}
+		commitTs, fileType, err := redo.ParseLogFileName(name)
+		if err != nil {
+			report.addProblem(path, ProblemCorrupted, "unrecognized segment name: %s", err)
+			return nil
+		}
+		switch fileType {
+		case redo.RedoMetaFileType:
+			data, err := extStorage.ReadFile(ctx, path)
+			if err != nil {
+				return cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+			}
+			var meta common.LogMeta
+			if _, err := meta.UnmarshalMsg(data); err != nil {
+				report.addProblem(path, ProblemCorrupted, "undecodable meta: %s", err)
+				return nil
+			}
+			metas = append(metas, &meta)
+		case redo.RedoRowLogFileType, redo.RedoDDLLogFileType:
+			segments = append(segments,
+				&SegmentInfo{File: path, Type: fileType, CommitTs: commitTs, Size: size})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	indexes, leftovers, err := verifyIndexes(ctx, extStorage, report, indexFiles, existing)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make(map[*SegmentInfo]struct{}, len(segments))
+	for _, segment := range segments {
+		data, err := extStorage.ReadFile(ctx, segment.File)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+		}
+		path := strings.TrimPrefix(segment.File, "/")
+		idx := indexes[path]
+		segment.Compacted = idx != nil
+		unfinished := filepath.Ext(segment.File) == redo.TmpEXT
+		if verifySegment(report, segment, data, unfinished, idx) && idx == nil {
+			if _, ok := leftovers[path]; !ok {
+				ordered[segment] = struct{}{}
+			}
+		}
+	}
+	report.Segments = segments
+
+	report.MetaFiles = len(metas)
+	if len(metas) == 0 {
+		report.addProblem("", ProblemMissing, "no meta file found")
+	} else {
+		common.ParseMeta(metas, &report.CheckpointTs, &report.ResolvedTs)
+		if report.ResolvedTs < report.CheckpointTs {
+			report.addProblem("", ProblemInconsistent,
+				"resolved ts %d is less than checkpoint ts %d in meta",
+				report.ResolvedTs, report.CheckpointTs)
+		}
+	}
+
+	sort.Slice(report.Segments, func(i, j int) bool {
+		a, b := report.Segments[i], report.Segments[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.CommitTs != b.CommitTs {
+			return a.CommitTs < b.CommitTs
+		}
+		return a.File < b.File
+	})
+	verifyDDLOrder(report, ordered)
+	return report, nil
+}
+
+// verifyIndexes decodes the indexes of the compacted segments, and reports the
+// files left by the interrupted compactions, which are the indexes without the
+// compacted segment and the merged segments not removed yet. It returns the
+// indexes keyed by the compacted segment and the merged segments left.
+func verifyIndexes(
+	ctx context.Context, extStorage storage.ExternalStorage, report *VerifyReport,
+	indexFiles []string, existing map[string]struct{},
+) (map[string]*common.SegmentIndex, map[string]struct{}, error) {
+	indexes := make(map[string]*common.SegmentIndex, len(indexFiles))
+	leftovers := make(map[string]struct{})
+	for _, indexFile := range indexFiles {
+		data, err := extStorage.ReadFile(ctx, indexFile)
+		if err != nil {
+			return nil, nil, cerror.WrapError(cerror.ErrExternalStorageAPI, err)
+		}
+		idx, err := common.UnmarshalSegmentIndex(data)
+		if err != nil {
+			report.addProblem(indexFile, ProblemCorrupted, "undecodable index: %s", err)
+			continue
+		}
+		// The index is written before the compacted segment.
+		if _, ok := existing[idx.Segment]; !ok {
+			report.addProblem(indexFile, ProblemLeftover,
+				"compacted segment %s doesn't exist, the compaction is interrupted", idx.Segment)
+			continue
+		}
+		indexes[idx.Segment] = idx
+		// The merged segments are removed after the compacted segment is written.
+		for _, source := range idx.Sources {
+			if _, ok := existing[source]; ok {
+				leftovers[source] = struct{}{}
+				report.addProblem(source, ProblemLeftover,
+					"segment is merged into %s but not removed, the compaction is interrupted",
+					idx.Segment)
+			}
+		}
+	}
+	return indexes, leftovers, nil
+}
+
+// verifyDDLOrder checks the order of the DDL segments written by the same
+// writer. The owner writes DDLs in commit ts order, so a DDL segment containing
+// DDLs older than the previous one may be written out of order. The row segments
+// are not checked since the redo logs of different tables are not written in
+// commit ts order.
+func verifyDDLOrder(report *VerifyReport, ordered map[*SegmentInfo]struct{}) {
+	prevs := make(map[string]*SegmentInfo)
+	for _, segment := range report.Segments {
+		if segment.Type != redo.RedoDDLLogFileType {
+			continue
+		}
+		if _, ok := ordered[segment]; !ok {
+			continue
+		}
+		writer := getWriterOfSegment(segment.File)
+		prev, ok := prevs[writer]
+		prevs[writer] = segment
+		if ok && segment.MinCommitTs < prev.MaxCommitTs {
+			report.addProblem(segment.File, ProblemInconsistent,
+				"min commit ts %d is less than the max commit ts %d of the previous segment %s, "+
+					"the DDLs may be written out of order",
+				segment.MinCommitTs, prev.MaxCommitTs, prev.File)
+		}
+	}
+}
+
+// getWriterOfSegment returns the segment name without the commit ts and the
+// uuid, which identifies the writer of the segment.
+func getWriterOfSegment(path string) string {
+	fields := strings.Split(filepath.Base(path), "_")
+	return strings.Join(fields[:len(fields)-2], "_")
+}
+
+// verifySegment decodes the frames of the segment and checks the commit ts
+// of the redo logs against the segment name, and against the index if the
+// segment is compacted. It returns true if the commit ts range of the segment
+// is reliable, that is the segment is finished and fully decoded.
+func verifySegment(
+	report *VerifyReport, segment *SegmentInfo, data []byte,
+	unfinished bool, idx *common.SegmentIndex,
+) bool {
+	if unfinished {
+		report.addProblem(segment.File, ProblemMissing, "segment is not finished")
+	}
+	if len(data) == 0 {
+		report.addProblem(segment.File, ProblemMissing, "segment is empty")
+		return false
+	}
+	data, err := decompressLogFile(segment.File, data)
+	if err != nil {
+		report.addProblem(segment.File, ProblemCorrupted, "undecompressable segment: %s", err)
+		return false
+	}
+
+	expectedType := model.RedoLogTypeRow
+	if segment.Type == redo.RedoDDLLogFileType {
+		expectedType = model.RedoLogTypeDDL
+	}
+	corrupted := false
+	unsorted := false
+	blocks := 0
+	for off := int64(0); off < int64(len(data)); {
+		if int64(len(data))-off < frameSizeBytes {
+			report.addProblem(segment.File, ProblemCorrupted,
+				"truncated frame header at offset %d", off)
+			corrupted = true
+			break
+		}
+		recBytes, padBytes := decodeFrameSize(int64(binary.LittleEndian.Uint64(data[off:])))
+		end := off + frameSizeBytes + recBytes + padBytes
+		if end > int64(len(data)) {
+			report.addProblem(segment.File, ProblemCorrupted,
+				"truncated frame at offset %d, %d bytes expected but %d bytes left",
+				off, recBytes+padBytes, int64(len(data))-off-frameSizeBytes)
+			corrupted = true
+			break
+		}
+		frame := data[off+frameSizeBytes : end]
+		if !isZero(frame[recBytes:]) {
+			report.addProblem(segment.File, ProblemCorrupted,
+				"non-zero padding of frame at offset %d", off)
+			corrupted = true
+			break
+		}
+		redoLog, _, err := codec.UnmarshalRedoLog(frame[:recBytes])
+		if err != nil {
+			r := &reader{lastValidOff: off}
+			if r.isTornEntry(frame) {
+				report.addProblem(segment.File, ProblemCorrupted,
+					"torn write of frame at offset %d", off)
+			} else {
+				report.addProblem(segment.File, ProblemCorrupted,
+					"undecodable frame at offset %d: %s", off, err)
+			}
+			corrupted = true
+			break
+		}
+		if redoLog.Type != expectedType {
+			report.addProblem(segment.File, ProblemInconsistent,
+				"unexpected redo log type %d of frame at offset %d", redoLog.Type, off)
+		}
+
+		commitTs := redoLog.GetCommitTs()
+		if idx != nil {
+			// The redo logs are sorted by commit ts in the compacted segment,
+			// and each block of the index starts at a frame.
+			if !unsorted && segment.Events > 0 && commitTs < segment.MaxCommitTs {
+				report.addProblem(segment.File, ProblemInconsistent,
+					"redo logs of compacted segment are not sorted by commit ts at offset %d", off)
+				unsorted = true
+			}
+			if blocks < len(idx.Blocks) && idx.Blocks[blocks].Offset < off {
+				report.addProblem(segment.File, ProblemInconsistent,
+					"block at offset %d of the index doesn't start at a frame",
+					idx.Blocks[blocks].Offset)
+				blocks = len(idx.Blocks)
+			}
+			if blocks < len(idx.Blocks) && idx.Blocks[blocks].Offset == off {
+				blocks++
+			}
+		}
+		if segment.Events == 0 || commitTs < segment.MinCommitTs {
+			segment.MinCommitTs = commitTs
+		}
+		if commitTs > segment.MaxCommitTs {
+			segment.MaxCommitTs = commitTs
+		}
+		segment.Events++
+		off = end
+	}
+
+	// The commit ts in the name of an unfinished segment is not the max commit ts.
+	if unfinished || corrupted {
+		return false
+	}
+	if idx != nil && blocks < len(idx.Blocks) {
+		report.addProblem(segment.File, ProblemInconsistent,
+			"block at offset %d of the index is beyond the segment", idx.Blocks[blocks].Offset)
+	}
+	if idx != nil && segment.Events > 0 &&
+		(idx.MinCommitTs != segment.MinCommitTs || idx.MaxCommitTs != segment.MaxCommitTs) {
+		report.addProblem(segment.File, ProblemInconsistent,
+			"commit ts range [%d, %d] doesn't match the range [%d, %d] in the index",
+			segment.MinCommitTs, segment.MaxCommitTs, idx.MinCommitTs, idx.MaxCommitTs)
+	}
+	switch {
+	case segment.Events == 0:
+		report.addProblem(segment.File, ProblemMissing, "segment contains no redo logs")
+	case segment.MaxCommitTs > segment.CommitTs:
+		report.addProblem(segment.File, ProblemInconsistent,
+			"max commit ts %d exceeds the commit ts %d in segment name, "+
+				"the redo logs may be skipped by the reader",
+			segment.MaxCommitTs, segment.CommitTs)
+	case segment.MaxCommitTs < segment.CommitTs:
+		report.addProblem(segment.File, ProblemMissing,
+			"max commit ts %d is less than the commit ts %d in segment name, "+
+				"the tail of the segment may be lost",
+			segment.MaxCommitTs, segment.CommitTs)
+	}
+	return segment.Events > 0
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
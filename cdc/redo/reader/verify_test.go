@@ -0,0 +1,222 @@
+//  Copyright 2024 PingCAP, Inc.
+//
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the License.
+//  You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+//  Unless required by applicable law or agreed to in writing, software
+//  distributed under the License is distributed on an "AS IS" BASIS,
+//  See the License for the specific language governing permissions and
+//  limitations under the License.
+
+package reader
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/model/codec"
+	"github.com/pingcap/tiflow/cdc/redo/common"
+	"github.com/pingcap/tiflow/cdc/redo/writer"
+	"github.com/pingcap/tiflow/pkg/redo"
+	"github.com/stretchr/testify/require"
+)
+
+func genRowLog(commitTs uint64) *model.RedoLog {
+	event := &model.RowChangedEvent{
+		CommitTs:  commitTs,
+		TableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test", Table: "t"}},
+	}
+	return event.ToRedoLog()
+}
+
+func genDDLLog(commitTs uint64) *model.RedoLog {
+	event := &model.DDLEvent{CommitTs: commitTs, TableInfo: &model.TableInfo{}}
+	return event.ToRedoLog()
+}
+
+func genFrame(t *testing.T, redoLog *model.RedoLog) []byte {
+	rawData, err := codec.MarshalRedoLog(redoLog, nil)
+	require.NoError(t, err)
+	lenField, padBytes := writer.EncodeFrameSize(len(rawData))
+	data := binary.LittleEndian.AppendUint64(nil, lenField)
+	data = append(data, rawData...)
+	return append(data, make([]byte, padBytes)...)
+}
+
+func writeSegment(
+	t *testing.T, dir string, logType string, commitTs uint64, ext string, frames ...[]byte,
+) string {
+	name := fmt.Sprintf(redo.RedoLogFileFormatV2, "capture", "default",
+		"changefeed", logType, commitTs, uuid.NewString(), ext)
+	var data []byte
+	for _, f := range frames {
+		data = append(data, f...)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, redo.DefaultFileMode))
+	return name
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	uri, err := url.Parse(fmt.Sprintf("file://%s", dir))
+	require.NoError(t, err)
+
+	genMetaFile(t, dir, &common.LogMeta{CheckpointTs: 10, ResolvedTs: 100})
+	genLogFile(ctx, t, dir, redo.RedoRowLogFileType, 11, 20)
+	genLogFile(ctx, t, dir, redo.RedoDDLLogFileType, 30, 30)
+
+	report, err := Verify(ctx, *uri)
+	require.NoError(t, err)
+	require.Empty(t, report.Problems)
+	require.Equal(t, uint64(10), report.CheckpointTs)
+	require.Equal(t, uint64(100), report.ResolvedTs)
+	require.Equal(t, 1, report.MetaFiles)
+	require.Len(t, report.Segments, 2)
+	require.Equal(t, redo.RedoDDLLogFileType, report.Segments[0].Type)
+	require.Equal(t, 1, report.Segments[0].Events)
+	require.Equal(t, redo.RedoRowLogFileType, report.Segments[1].Type)
+	require.Equal(t, 10, report.Segments[1].Events)
+	require.Equal(t, uint64(11), report.Segments[1].MinCommitTs)
+	require.Equal(t, uint64(20), report.Segments[1].MaxCommitTs)
+}
+
+func TestVerifyProblems(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	uri, err := url.Parse(fmt.Sprintf("file://%s", dir))
+	require.NoError(t, err)
+
+	frame := func(commitTs uint64) []byte {
+		return genFrame(t, genRowLog(commitTs))
+	}
+	writeSegment := func(logType string, commitTs uint64, ext string, frames ...[]byte) string {
+		return writeSegment(t, dir, logType, commitTs, ext, frames...)
+	}
+
+	writeSegment(redo.RedoRowLogFileType, 40, redo.LogEXT, frame(31), frame(40))
+	lost := writeSegment(redo.RedoRowLogFileType, 60, redo.LogEXT, frame(50))
+	truncated := frame(70)
+	corrupted := writeSegment(redo.RedoRowLogFileType, 70, redo.LogEXT,
+		frame(69), truncated[:len(truncated)-3])
+	beyond := writeSegment(redo.RedoRowLogFileType, 65, redo.LogEXT, frame(80))
+	unfinished := writeSegment(redo.RedoRowLogFileType, 90, redo.LogEXT+redo.TmpEXT, frame(90))
+	empty := writeSegment(redo.RedoRowLogFileType, 95, redo.LogEXT)
+	wrongType := writeSegment(redo.RedoDDLLogFileType, 50, redo.LogEXT, frame(50))
+
+	report, err := Verify(ctx, *uri)
+	require.NoError(t, err)
+	require.Len(t, report.Segments, 7)
+	problems := make(map[string]string)
+	for _, p := range report.Problems {
+		problems[filepath.Base(p.File)] = p.Kind
+	}
+	require.Equal(t, map[string]string{
+		".":        ProblemMissing,
+		lost:       ProblemMissing,
+		corrupted:  ProblemCorrupted,
+		beyond:     ProblemInconsistent,
+		unfinished: ProblemMissing,
+		empty:      ProblemMissing,
+		wrongType:  ProblemInconsistent,
+	}, problems)
+}
+
+func TestVerifyCompaction(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ctx := context.Background()
+	uri, err := url.Parse(fmt.Sprintf("file://%s", dir))
+	require.NoError(t, err)
+	genMetaFile(t, dir, &common.LogMeta{CheckpointTs: 10, ResolvedTs: 100})
+
+	writeIndex := func(idx *common.SegmentIndex) string {
+		data, err := idx.Marshal()
+		require.NoError(t, err)
+		name := common.GetIndexFileName(idx.Segment)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, redo.DefaultFileMode))
+		return name
+	}
+	rowFrames := func(commitTs ...uint64) [][]byte {
+		frames := make([][]byte, 0, len(commitTs))
+		for _, ts := range commitTs {
+			frames = append(frames, genFrame(t, genRowLog(ts)))
+		}
+		return frames
+	}
+
+	// the compaction is finished, but a merged segment is not removed.
+	frames := rowFrames(11, 12, 20)
+	compacted := writeSegment(t, dir, redo.RedoRowLogFileType, 20, redo.LogEXT, frames...)
+	leftover := writeSegment(t, dir, redo.RedoRowLogFileType, 12, redo.LogEXT, frames[:2]...)
+	writeIndex(&common.SegmentIndex{
+		Segment:     compacted,
+		Sources:     []string{leftover, "removed.log"},
+		MinCommitTs: 11,
+		MaxCommitTs: 20,
+		Blocks: []common.SegmentBlock{
+			{Offset: 0, MinCommitTs: 11, MaxCommitTs: 12},
+			{Offset: int64(len(frames[0]) + len(frames[1])), MinCommitTs: 20, MaxCommitTs: 20},
+		},
+	})
+	// the compaction is interrupted before the compacted segment is written.
+	orphan := writeIndex(&common.SegmentIndex{Segment: "missing.log"})
+	// the redo logs of the compacted segment are not sorted.
+	unsorted := writeSegment(t, dir, redo.RedoRowLogFileType, 30, redo.LogEXT, rowFrames(30, 25)...)
+	writeIndex(&common.SegmentIndex{
+		Segment:     unsorted,
+		MinCommitTs: 25,
+		MaxCommitTs: 30,
+		Blocks:      []common.SegmentBlock{{Offset: 0, MinCommitTs: 25, MaxCommitTs: 30}},
+	})
+	// the block of the index doesn't start at a frame.
+	misaligned := writeSegment(t, dir, redo.RedoRowLogFileType, 40, redo.LogEXT, rowFrames(40)...)
+	writeIndex(&common.SegmentIndex{
+		Segment:     misaligned,
+		MinCommitTs: 40,
+		MaxCommitTs: 40,
+		Blocks:      []common.SegmentBlock{{Offset: 1, MinCommitTs: 40, MaxCommitTs: 40}},
+	})
+	// the DDLs of a writer are out of order.
+	writeSegment(t, dir, redo.RedoDDLLogFileType, 50, redo.LogEXT, genFrame(t, genDDLLog(50)))
+	outOfOrder := writeSegment(t, dir, redo.RedoDDLLogFileType, 60, redo.LogEXT,
+		genFrame(t, genDDLLog(45)), genFrame(t, genDDLLog(60)))
+	// the DDLs with the same commit ts may be written into different segments.
+	writeSegment(t, dir, redo.RedoDDLLogFileType, 70, redo.LogEXT,
+		genFrame(t, genDDLLog(60)), genFrame(t, genDDLLog(70)))
+
+	report, err := Verify(ctx, *uri)
+	require.NoError(t, err)
+	require.Len(t, report.Segments, 7)
+	for _, segment := range report.Segments {
+		name := filepath.Base(segment.File)
+		require.Equal(t, name == compacted || name == unsorted || name == misaligned,
+			segment.Compacted, name)
+	}
+	problems := make(map[string]string)
+	for _, p := range report.Problems {
+		problems[filepath.Base(p.File)] = p.Kind
+	}
+	require.Equal(t, map[string]string{
+		leftover:   ProblemLeftover,
+		orphan:     ProblemLeftover,
+		unsorted:   ProblemInconsistent,
+		misaligned: ProblemInconsistent,
+		outOfOrder: ProblemInconsistent,
+	}, problems)
+}
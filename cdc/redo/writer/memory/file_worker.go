@@ -21,7 +21,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/pierrec/lz4/v4"
 	"github.com/pingcap/log"
 	"github.com/pingcap/tidb/br/pkg/storage"
 	"github.com/pingcap/tiflow/cdc/model"
@@ -266,9 +265,13 @@ func (f *fileWorkerGroup) newFileCache(event *polymorphicRedoEvent) error {
 	)
 	bufferWriter := bytes.NewBuffer(buf)
 	wr = bufferWriter
-	if f.cfg.Compression == compression.LZ4 {
-		wr = lz4.NewWriter(bufferWriter)
-		closer = wr.(io.Closer)
+	if f.cfg.Compression != "" && f.cfg.Compression != compression.None {
+		compressor, err := compression.NewWriter(f.cfg.Compression, bufferWriter)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		wr = compressor
+		closer = compressor
 	}
 	_, err := wr.Write(event.data.Bytes())
 	if err != nil {
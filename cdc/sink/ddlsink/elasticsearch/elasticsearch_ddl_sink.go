@@ -0,0 +1,221 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/ddlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/elasticsearch"
+	"go.uber.org/zap"
+)
+
+// Assert Sink implementation
+var _ ddlsink.Sink = (*DDLSink)(nil)
+
+// DDLSink is the elasticsearch DDL sink.
+// The DDL events are mapped to the index mapping changes. The new columns are
+// added to the mapping, and the DDLs which can't be applied to the documents
+// safely, such as truncating the table or changing the type of a field,
+// return an error to stop the changefeed.
+type DDLSink struct {
+	// id indicates which changefeed this sink belongs to.
+	id     model.ChangeFeedID
+	cfg    *elasticsearch.Config
+	client *elasticsearch.Client
+	// statistic is used to record the DDL metrics
+	statistics *metrics.Statistics
+}
+
+// NewDDLSink creates a ddl sink for elasticsearch.
+func NewDDLSink(_ context.Context,
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	_ *config.ReplicaConfig,
+) (*DDLSink, error) {
+	cfg := elasticsearch.NewConfig()
+	if err := cfg.Apply(sinkURI); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &DDLSink{
+		id:         changefeedID,
+		cfg:        cfg,
+		client:     elasticsearch.NewClient(changefeedID, cfg),
+		statistics: metrics.NewStatistics(changefeedID, sink.TxnSink),
+	}, nil
+}
+
+// WriteDDLEvent applies the DDL event to the index of the table.
+func (d *DDLSink) WriteDDLEvent(ctx context.Context, ddl *model.DDLEvent) error {
+	tableInfo := ddl.TableInfo
+	if tableInfo == nil || tableInfo.TableName.Table == "" ||
+		tableInfo.IsView() || tableInfo.IsSequence() {
+		d.logSkippedDDL(ddl, "the ddl event has no index")
+		return nil
+	}
+	return d.statistics.RecordDDLExecution(func() error {
+		return d.applyDDLEvent(ctx, ddl)
+	})
+}
+
+func (d *DDLSink) applyDDLEvent(ctx context.Context, ddl *model.DDLEvent) error {
+	tableName := ddl.TableInfo.TableName
+	index, err := d.cfg.IndexName(tableName.Schema, tableName.Table)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch ddl.Type {
+	case timodel.ActionDropTable:
+		// The documents are kept, no more rows will be written to the index.
+		d.logSkippedDDL(ddl, "the index is kept after the table is dropped")
+		return nil
+	case timodel.ActionTruncateTable, timodel.ActionTruncateTablePartition,
+		timodel.ActionDropTablePartition, timodel.ActionExchangeTablePartition:
+		// The rows are removed without the row changed events,
+		// so the stale documents would be left in the index.
+		return cerror.ErrElasticsearchUnsupportedDDL.GenWithStackByArgs(index,
+			fmt.Sprintf("rows are removed without events by %s", ddl.Type))
+	case timodel.ActionRenameTable, timodel.ActionRenameTables:
+		if ddl.PreTableInfo != nil {
+			preTableName := ddl.PreTableInfo.TableName
+			preIndex, err := d.cfg.IndexName(preTableName.Schema, preTableName.Table)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if preIndex != index {
+				return cerror.ErrElasticsearchUnsupportedDDL.GenWithStackByArgs(index,
+					fmt.Sprintf("the documents are in index %s", preIndex))
+			}
+		}
+	}
+	return d.syncMapping(ctx, index, ddl)
+}
+
+// syncMapping creates the index or adds the new fields to its mapping.
+// The existing fields can't be renamed or changed to another type,
+// since the documents which have been indexed can't be updated.
+func (d *DDLSink) syncMapping(ctx context.Context, index string, ddl *model.DDLEvent) error {
+	if ddl.PreTableInfo != nil {
+		for _, col := range ddl.TableInfo.Columns {
+			preCol, ok := ddl.PreTableInfo.GetColumnInfo(col.ID)
+			if ok && preCol.Name.O != col.Name.O {
+				return cerror.ErrElasticsearchUnsupportedDDL.GenWithStackByArgs(index,
+					fmt.Sprintf("column %s is renamed to %s", preCol.Name.O, col.Name.O))
+			}
+		}
+	}
+
+	properties := elasticsearch.Mapping(ddl.TableInfo.Columns)
+	existing, err := d.client.GetMapping(ctx, index)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if existing == nil {
+		created, err := d.client.CreateIndex(ctx, index, properties)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if created {
+			log.Info("Create elasticsearch index",
+				zap.String("index", index),
+				zap.Uint64("commitTs", ddl.CommitTs),
+				zap.String("query", ddl.Query),
+				zap.String("namespace", d.id.Namespace),
+				zap.String("changefeed", d.id.ID))
+			return nil
+		}
+		// The index is created by others concurrently, sync with its mapping.
+		if existing, err = d.client.GetMapping(ctx, index); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	added := make(map[string]interface{})
+	for name, field := range properties {
+		old, ok := existing[name]
+		if !ok {
+			added[name] = field
+			continue
+		}
+		oldType := fieldType(old)
+		newType := fieldType(field)
+		if oldType != newType {
+			return cerror.ErrElasticsearchUnsupportedDDL.GenWithStackByArgs(index,
+				fmt.Sprintf("field %s is changed from %s to %s", name, oldType, newType))
+		}
+	}
+	if len(added) == 0 {
+		d.logSkippedDDL(ddl, "the mapping is not changed")
+		return nil
+	}
+	if err := d.client.PutMapping(ctx, index, added); err != nil {
+		return errors.Trace(err)
+	}
+	log.Info("Add fields to elasticsearch index",
+		zap.String("index", index),
+		zap.Int("fields", len(added)),
+		zap.Uint64("commitTs", ddl.CommitTs),
+		zap.String("query", ddl.Query),
+		zap.String("namespace", d.id.Namespace),
+		zap.String("changefeed", d.id.ID))
+	return nil
+}
+
+func (d *DDLSink) logSkippedDDL(ddl *model.DDLEvent, reason string) {
+	log.Info("Skip ddl event", zap.String("reason", reason),
+		zap.Uint64("commitTs", ddl.CommitTs),
+		zap.String("query", ddl.Query),
+		zap.String("namespace", d.id.Namespace),
+		zap.String("changefeed", d.id.ID))
+}
+
+// fieldType returns the type of the field mapping.
+func fieldType(field interface{}) string {
+	if m, ok := field.(map[string]interface{}); ok {
+		if t, ok := m["type"].(string); ok {
+			return t
+		}
+		// The object fields have no type.
+		return "object"
+	}
+	return ""
+}
+
+// WriteCheckpointTs does nothing, elasticsearch sink doesn't send checkpoint events.
+func (d *DDLSink) WriteCheckpointTs(_ context.Context,
+	_ uint64, _ []*model.TableInfo,
+) error {
+	return nil
+}
+
+// Close closes the sink.
+func (d *DDLSink) Close() {
+	if d.client != nil {
+		d.client.Close()
+	}
+	if d.statistics != nil {
+		d.statistics.Close()
+	}
+}
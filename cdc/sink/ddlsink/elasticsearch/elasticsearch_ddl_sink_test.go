@@ -0,0 +1,225 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCluster keeps the index mappings in memory.
+type fakeCluster struct {
+	mu       sync.Mutex
+	mappings map[string]map[string]interface{}
+	requests int
+}
+
+func (c *fakeCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests++
+
+	index, isMapping := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/_mapping")
+	mapping, exists := c.mappings[index]
+	switch {
+	case r.Method == http.MethodGet && isMapping:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			index: map[string]interface{}{"mappings": map[string]interface{}{"properties": mapping}},
+		})
+	case r.Method == http.MethodPut && !isMapping:
+		if exists {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"resource_already_exists_exception"}}`))
+			return
+		}
+		req := make(map[string]map[string]map[string]interface{})
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		c.mappings[index] = req["mappings"]["properties"]
+	case r.Method == http.MethodPut && isMapping:
+		req := make(map[string]map[string]interface{})
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		for name, field := range req["properties"] {
+			mapping[name] = field
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (c *fakeCluster) mapping(index string) map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mappings[index]
+}
+
+func newDDLSink(t *testing.T) (*DDLSink, *fakeCluster) {
+	cluster := &fakeCluster{mappings: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(cluster)
+	t.Cleanup(server.Close)
+	sinkURI, err := url.Parse(strings.Replace(server.URL, "http://", "opensearch://", 1) +
+		"?max-retries=0")
+	require.NoError(t, err)
+	s, err := NewDDLSink(context.Background(), model.DefaultChangeFeedID("test"),
+		sinkURI, config.GetDefaultReplicaConfig())
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+	return s, cluster
+}
+
+func newTableInfo(table string, columns ...*timodel.ColumnInfo) *model.TableInfo {
+	for i, col := range columns {
+		col.ID = int64(i + 1)
+		col.Offset = i
+		col.State = timodel.StatePublic
+	}
+	return model.WrapTableInfo(100, "test", 33, &timodel.TableInfo{
+		ID:      20,
+		Name:    pmodel.NewCIStr(table),
+		Columns: columns,
+	})
+}
+
+func newColumn(name string, tp byte) *timodel.ColumnInfo {
+	return &timodel.ColumnInfo{Name: pmodel.NewCIStr(name), FieldType: *types.NewFieldType(tp)}
+}
+
+func TestElasticsearchDDLSyncMapping(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, cluster := newDDLSink(t)
+
+	require.NoError(t, s.WriteDDLEvent(ctx, &model.DDLEvent{
+		CommitTs:  100,
+		Type:      timodel.ActionCreateTable,
+		Query:     "create table test.t(id int primary key)",
+		TableInfo: newTableInfo("t", newColumn("id", mysql.TypeLong)),
+	}))
+	require.Equal(t, map[string]interface{}{
+		"id": map[string]interface{}{"type": "long"},
+	}, cluster.mapping("test.t"))
+
+	preTableInfo := newTableInfo("t", newColumn("id", mysql.TypeLong))
+	require.NoError(t, s.WriteDDLEvent(ctx, &model.DDLEvent{
+		CommitTs:     101,
+		Type:         timodel.ActionAddColumn,
+		Query:        "alter table test.t add column name varchar(10)",
+		TableInfo:    newTableInfo("t", newColumn("id", mysql.TypeLong), newColumn("name", mysql.TypeVarchar)),
+		PreTableInfo: preTableInfo,
+	}))
+	require.Equal(t, map[string]interface{}{
+		"id":   map[string]interface{}{"type": "long"},
+		"name": map[string]interface{}{"type": "keyword"},
+	}, cluster.mapping("test.t"))
+
+	// the type is not changed in the index.
+	require.NoError(t, s.WriteDDLEvent(ctx, &model.DDLEvent{
+		CommitTs:  102,
+		Type:      timodel.ActionModifyColumn,
+		Query:     "alter table test.t modify column id bigint",
+		TableInfo: newTableInfo("t", newColumn("id", mysql.TypeLonglong), newColumn("name", mysql.TypeVarchar)),
+	}))
+	// the column is dropped, the field is kept.
+	require.NoError(t, s.WriteDDLEvent(ctx, &model.DDLEvent{
+		CommitTs:  103,
+		Type:      timodel.ActionDropColumn,
+		Query:     "alter table test.t drop column name",
+		TableInfo: newTableInfo("t", newColumn("id", mysql.TypeLonglong)),
+	}))
+	require.Len(t, cluster.mapping("test.t"), 2)
+}
+
+func TestElasticsearchDDLUnsupported(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, cluster := newDDLSink(t)
+	cluster.mappings["test.t"] = map[string]interface{}{
+		"id":   map[string]interface{}{"type": "long"},
+		"name": map[string]interface{}{"type": "keyword"},
+	}
+
+	for _, ddl := range []*model.DDLEvent{
+		{
+			Type:      timodel.ActionModifyColumn,
+			Query:     "alter table test.t modify column name blob",
+			TableInfo: newTableInfo("t", newColumn("id", mysql.TypeLong), newColumn("name", mysql.TypeBlob)),
+		},
+		{
+			Type:         timodel.ActionModifyColumn,
+			Query:        "alter table test.t change column name name2 varchar(10)",
+			TableInfo:    newTableInfo("t", newColumn("id", mysql.TypeLong), newColumn("name2", mysql.TypeVarchar)),
+			PreTableInfo: newTableInfo("t", newColumn("id", mysql.TypeLong), newColumn("name", mysql.TypeVarchar)),
+		},
+		{
+			Type:      timodel.ActionTruncateTable,
+			Query:     "truncate table test.t",
+			TableInfo: newTableInfo("t", newColumn("id", mysql.TypeLong), newColumn("name", mysql.TypeVarchar)),
+		},
+		{
+			Type:         timodel.ActionRenameTable,
+			Query:        "rename table test.t to test.t2",
+			TableInfo:    newTableInfo("t2", newColumn("id", mysql.TypeLong), newColumn("name", mysql.TypeVarchar)),
+			PreTableInfo: newTableInfo("t", newColumn("id", mysql.TypeLong), newColumn("name", mysql.TypeVarchar)),
+		},
+	} {
+		err := s.WriteDDLEvent(ctx, ddl)
+		require.ErrorIs(t, err, cerror.ErrElasticsearchUnsupportedDDL, ddl.Query)
+	}
+	require.Equal(t, map[string]interface{}{
+		"id":   map[string]interface{}{"type": "long"},
+		"name": map[string]interface{}{"type": "keyword"},
+	}, cluster.mapping("test.t"))
+}
+
+func TestElasticsearchDDLSkipped(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, cluster := newDDLSink(t)
+
+	require.NoError(t, s.WriteDDLEvent(ctx, &model.DDLEvent{
+		Type:      timodel.ActionCreateSchema,
+		Query:     "create database test",
+		TableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test"}},
+	}))
+	require.NoError(t, s.WriteDDLEvent(ctx, &model.DDLEvent{
+		Type:      timodel.ActionDropTable,
+		Query:     "drop table test.t",
+		TableInfo: newTableInfo("t", newColumn("id", mysql.TypeLong)),
+	}))
+	cluster.mu.Lock()
+	defer cluster.mu.Unlock()
+	require.Zero(t, cluster.requests)
+	require.NoError(t, s.WriteCheckpointTs(ctx, 100, nil))
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/ddlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/cloudstorage"
+	"github.com/pingcap/tiflow/pkg/sink/iceberg"
+	"github.com/pingcap/tiflow/pkg/util"
+	"go.uber.org/zap"
+)
+
+// Assert Sink implementation
+var _ ddlsink.Sink = (*DDLSink)(nil)
+
+// DDLSink is a sink that applies DDL events to the iceberg tables.
+// The table definition of each DDL is written to the `schema.json` file in
+// the same way as the cloud storage sink, and then the schema of the iceberg
+// table is evolved from it.
+type DDLSink struct {
+	// id indicates which changefeed this sink belongs to.
+	id model.ChangeFeedID
+	// statistic is used to record the DDL metrics
+	statistics *metrics.Statistics
+	storage    storage.ExternalStorage
+	cfg        *iceberg.Config
+
+	lastSendCheckpointTsTime time.Time
+}
+
+// NewDDLSink creates a ddl sink for iceberg.
+func NewDDLSink(ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+) (*DDLSink, error) {
+	// create iceberg config and then apply the params of sinkURI to it.
+	cfg := iceberg.NewConfig()
+	err := cfg.Apply(ctx, sinkURI, replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	storage, err := util.GetExternalStorageFromURI(ctx, cfg.StorageURI.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &DDLSink{
+		id:                       changefeedID,
+		storage:                  storage,
+		statistics:               metrics.NewStatistics(changefeedID, sink.TxnSink),
+		cfg:                      cfg,
+		lastSendCheckpointTsTime: time.Now(),
+	}, nil
+}
+
+// WriteDDLEvent writes the table definition to the external storage and
+// evolves the schema of the iceberg table.
+func (d *DDLSink) WriteDDLEvent(ctx context.Context, ddl *model.DDLEvent) error {
+	var def cloudstorage.TableDefinition
+	def.FromDDLEvent(ddl, d.cfg.OutputColumnID)
+	encodedDef, err := def.MarshalWithQuery()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	path, err := def.GenerateSchemaFilePath()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	log.Debug("write ddl event to external storage",
+		zap.String("path", path), zap.Any("ddl", ddl))
+
+	return d.statistics.RecordDDLExecution(func() error {
+		if err := d.storage.WriteFile(ctx, path, encodedDef); err != nil {
+			return err
+		}
+		if err := d.applyDDL(ctx, ddl, ddl.TableInfo); err != nil {
+			return err
+		}
+		if ddl.Type == timodel.ActionExchangeTablePartition {
+			// For exchange partition, the schema of the source table is changed too.
+			return d.applyDDL(ctx, ddl, ddl.PreTableInfo)
+		}
+		return nil
+	})
+}
+
+func (d *DDLSink) applyDDL(
+	ctx context.Context, ddl *model.DDLEvent, tableInfo *model.TableInfo,
+) error {
+	if tableInfo == nil || tableInfo.TableName.Table == "" {
+		// schema level DDLs do not change any iceberg table.
+		return nil
+	}
+	switch ddl.Type {
+	case timodel.ActionDropTable, timodel.ActionCreateView, timodel.ActionDropView:
+		// the iceberg table is kept after the upstream table is dropped.
+		return nil
+	}
+
+	table := iceberg.NewTable(d.id, d.storage, d.cfg.Location,
+		tableInfo.TableName.Schema, tableInfo.TableName.Table)
+	if ddl.Type == timodel.ActionTruncateTable {
+		return errors.Trace(table.Truncate(ctx, ddl.CommitTs))
+	}
+
+	if err := table.Load(ctx); err != nil {
+		return errors.Trace(err)
+	}
+	var (
+		prev         *iceberg.Schema
+		lastColumnID int
+	)
+	if metadata := table.Metadata(); metadata != nil {
+		prev, lastColumnID = metadata.CurrentSchema(), metadata.LastColumnID
+	}
+	schema, lastColumnID := iceberg.NewSchemaFromTableInfo(tableInfo, prev, lastColumnID)
+	return errors.Trace(table.UpdateSchema(ctx, schema, lastColumnID, tableInfo.Version))
+}
+
+// WriteCheckpointTs writes the checkpoint ts to the external storage.
+func (d *DDLSink) WriteCheckpointTs(ctx context.Context,
+	ts uint64, tables []*model.TableInfo,
+) error {
+	if time.Since(d.lastSendCheckpointTsTime) < 2*time.Second {
+		log.Debug("skip write checkpoint ts to external storage",
+			zap.Any("changefeedID", d.id),
+			zap.Uint64("ts", ts))
+		return nil
+	}
+
+	defer func() {
+		d.lastSendCheckpointTsTime = time.Now()
+	}()
+	ckpt, err := json.Marshal(map[string]uint64{"checkpoint-ts": ts})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = d.storage.WriteFile(ctx, "metadata", ckpt)
+	return errors.Trace(err)
+}
+
+// Close closes the sink.
+func (d *DDLSink) Close() {
+	if d.statistics != nil {
+		d.statistics.Close()
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/iceberg"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDDLEvent(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	parentDir := t.TempDir()
+	uri := fmt.Sprintf("iceberg+file://%s", parentDir)
+	sinkURI, err := url.Parse(uri)
+	require.Nil(t, err)
+	sink, err := NewDDLSink(ctx, model.DefaultChangeFeedID("test"),
+		sinkURI, config.GetDefaultReplicaConfig())
+	require.Nil(t, err)
+	defer sink.Close()
+
+	ft := types.NewFieldType(mysql.TypeLong)
+	ft.SetFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+	tidbTableInfo := &timodel.TableInfo{
+		ID:         20,
+		Name:       pmodel.NewCIStr("table1"),
+		PKIsHandle: true,
+		Columns: []*timodel.ColumnInfo{
+			{ID: 1, Name: pmodel.NewCIStr("col1"), FieldType: *ft},
+			{ID: 2, Name: pmodel.NewCIStr("col2"), FieldType: *types.NewFieldType(mysql.TypeVarchar)},
+		},
+	}
+	tableInfo := model.WrapTableInfo(100, "test", 100, tidbTableInfo)
+	ddlEvent := &model.DDLEvent{
+		CommitTs:  100,
+		Type:      timodel.ActionCreateTable,
+		Query:     "create table test.table1(col1 int primary key, col2 varchar(64))",
+		TableInfo: tableInfo,
+	}
+	err = sink.WriteDDLEvent(ctx, ddlEvent)
+	require.Nil(t, err)
+
+	// the table definition is written in the same way as the storage sink.
+	tableDir := path.Join(parentDir, "test/table1/meta/")
+	files, err := os.ReadDir(tableDir)
+	require.Nil(t, err)
+	require.Len(t, files, 1)
+
+	storage, err := util.GetExternalStorageFromURI(ctx, "file://"+parentDir)
+	require.Nil(t, err)
+	table := iceberg.NewTable(model.DefaultChangeFeedID("test"), storage,
+		"file://"+parentDir, "test", "table1")
+	require.Nil(t, table.Load(ctx))
+	schema := table.Metadata().CurrentSchema()
+	require.Equal(t, []iceberg.NestedField{
+		{ID: 1, Name: "col1", Required: true, Type: "int"},
+		{ID: 2, Name: "col2", Type: "string"},
+	}, schema.Fields)
+	require.Nil(t, table.Metadata().CurrentSnapshot())
+
+	// truncate table commits an empty snapshot.
+	err = sink.WriteDDLEvent(ctx, &model.DDLEvent{
+		CommitTs:  200,
+		Type:      timodel.ActionTruncateTable,
+		Query:     "truncate table test.table1",
+		TableInfo: model.WrapTableInfo(100, "test", 200, tidbTableInfo),
+	})
+	require.Nil(t, err)
+	require.Nil(t, table.Load(ctx))
+	require.NotNil(t, table.Metadata().CurrentSnapshot())
+}
+
+func TestWriteCheckpointTs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	parentDir := t.TempDir()
+	uri := fmt.Sprintf("iceberg+file://%s", parentDir)
+	sinkURI, err := url.Parse(uri)
+	require.Nil(t, err)
+	sink, err := NewDDLSink(ctx, model.DefaultChangeFeedID("test"),
+		sinkURI, config.GetDefaultReplicaConfig())
+	require.Nil(t, err)
+	defer sink.Close()
+
+	time.Sleep(3 * time.Second)
+	err = sink.WriteCheckpointTs(ctx, 100, nil)
+	require.Nil(t, err)
+	metadata, err := os.ReadFile(path.Join(parentDir, "metadata"))
+	require.Nil(t, err)
+	require.JSONEq(t, `{"checkpoint-ts":100}`, string(metadata))
+}
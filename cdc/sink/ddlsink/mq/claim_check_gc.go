@@ -0,0 +1,158 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/kafka"
+	"github.com/pingcap/tiflow/pkg/sink/kafka/claimcheck"
+	"github.com/robfig/cron"
+	"go.uber.org/zap"
+)
+
+// kafkaRetentionConfigName is the topic config of the retention time in milliseconds.
+const kafkaRetentionConfigName = "retention.ms"
+
+// claimCheckGC removes the expired claim-check files of the changefeed periodically.
+type claimCheckGC struct {
+	changefeedID model.ChangeFeedID
+	cleaner      *claimcheck.Cleaner
+	cron         *cron.Cron
+	cancel       context.CancelFunc
+
+	checkpointTs atomic.Uint64
+	running      atomic.Bool
+}
+
+// newClaimCheckGC creates and starts a claimCheckGC, it returns nil if the claim-check
+// is disabled, or the retention is not configured and unknown from the topic.
+func newClaimCheckGC(
+	ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	cfg *config.LargeMessageHandleConfig,
+	topicRetention time.Duration,
+) (*claimCheckGC, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	cleaner, err := claimcheck.NewCleaner(ctx, cfg, changefeedID)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if cleaner == nil {
+		return nil, nil
+	}
+	cleaner.SetRetention(topicRetention)
+	if cleaner.Retention() <= 0 {
+		log.Info("skip cleanup expired claim-check files, since the retention is unknown",
+			zap.String("namespace", changefeedID.Namespace),
+			zap.String("changefeed", changefeedID.ID))
+		cleaner.Close()
+		return nil, nil
+	}
+
+	spec := cfg.ClaimCheckCleanupCronSpec
+	if spec == "" {
+		spec = config.DefaultClaimCheckCleanupCronSpec
+	}
+	gcCtx, cancel := context.WithCancel(context.Background())
+	g := &claimCheckGC{
+		changefeedID: changefeedID,
+		cleaner:      cleaner,
+		cron:         cron.New(),
+		cancel:       cancel,
+	}
+	if err := g.cron.AddFunc(spec, func() { g.cleanup(gcCtx) }); err != nil {
+		cancel()
+		cleaner.Close()
+		return nil, errors.Trace(err)
+	}
+	g.cron.Start()
+	log.Info("start schedule cleanup expired claim-check files",
+		zap.String("namespace", changefeedID.Namespace),
+		zap.String("changefeed", changefeedID.ID),
+		zap.String("cronSpec", spec),
+		zap.Duration("retention", cleaner.Retention()))
+	return g, nil
+}
+
+// getKafkaTopicRetention returns the retention of the kafka topic,
+// it returns 0 if the retention is unknown or unlimited.
+func getKafkaTopicRetention(
+	ctx context.Context, admin kafka.ClusterAdminClient, topic string,
+) time.Duration {
+	value, err := admin.GetTopicConfig(ctx, topic, kafkaRetentionConfigName)
+	if err != nil {
+		log.Warn("get the retention of the topic failed",
+			zap.String("topic", topic), zap.Error(err))
+		return 0
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ms <= 0 {
+		return 0
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func (g *claimCheckGC) updateCheckpointTs(ts model.Ts) {
+	if g == nil {
+		return
+	}
+	g.checkpointTs.Store(ts)
+}
+
+func (g *claimCheckGC) cleanup(ctx context.Context) {
+	if !g.running.CompareAndSwap(false, true) {
+		log.Warn("cleanup expired claim-check files is already running, skip this round",
+			zap.String("namespace", g.changefeedID.Namespace),
+			zap.String("changefeed", g.changefeedID.ID))
+		return
+	}
+	defer g.running.Store(false)
+
+	start := time.Now()
+	checkpointTs := g.checkpointTs.Load()
+	count, err := g.cleaner.RemoveExpiredFiles(ctx, checkpointTs)
+	if err != nil {
+		log.Error("failed to cleanup expired claim-check files",
+			zap.String("namespace", g.changefeedID.Namespace),
+			zap.String("changefeed", g.changefeedID.ID),
+			zap.Uint64("checkpointTs", checkpointTs),
+			zap.Error(err))
+		return
+	}
+	log.Info("cleanup expired claim-check files",
+		zap.String("namespace", g.changefeedID.Namespace),
+		zap.String("changefeed", g.changefeedID.ID),
+		zap.Uint64("checkpointTs", checkpointTs),
+		zap.Int("count", count),
+		zap.Duration("cost", time.Since(start)))
+}
+
+func (g *claimCheckGC) close() {
+	if g == nil {
+		return
+	}
+	g.cron.Stop()
+	g.cancel()
+	g.cleaner.Close()
+}
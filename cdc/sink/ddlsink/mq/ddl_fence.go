@@ -0,0 +1,80 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+)
+
+// DDLFenceKeyPrefix is the key prefix of the DDL fence marker, the key is
+// followed by the schema and table name, the commit ts of the DDL and the data
+// topic, so that the fences are not compacted by the DDL topic. The fence is a
+// control message of the protocol, the consumers which don't consume the DDL
+// topic must skip the messages with this key prefix.
+const DDLFenceKeyPrefix = "ticdc-ddl-fence/"
+
+// DDLFence is the marker of a DDL sent to the DDL topic. It's sent to the DDL
+// topic after the DDL, and to all partitions of the data topic which the DDL
+// fences, that is the rows before it in the partitions are under the schema
+// before the DDL, and the rows after it are under the schema after the DDL.
+// A consumer should apply the DDL after all partitions reach the fence.
+type DDLFence struct {
+	Schema       string `json:"schema"`
+	Table        string `json:"table"`
+	CommitTs     uint64 `json:"commit-ts"`
+	DDLTopic     string `json:"ddl-topic"`
+	Topic        string `json:"topic"`
+	PartitionNum int32  `json:"partition-num"`
+}
+
+// Key returns the message key of the fence.
+func (f *DDLFence) Key() string {
+	tableName := model.TableName{Schema: f.Schema, Table: f.Table}
+	return fmt.Sprintf("%s%s/%d/%s", DDLFenceKeyPrefix, tableName.String(), f.CommitTs, f.Topic)
+}
+
+// checkDDLFenceEncoder checks the fences can be encoded if the event router
+// sends any DDL to the DDL topics.
+func checkDDLFenceEncoder(
+	eventRouter *dispatcher.EventRouter, builder codec.RowEventEncoderBuilder,
+) error {
+	if len(eventRouter.GetDDLTopics()) == 0 {
+		return nil
+	}
+	if _, ok := builder.Build().(codec.ControlMessageEncoder); !ok {
+		return cerror.ErrDispatcherFailed.GenWithStack(
+			"the ddl-topic is only supported by the json encoding format")
+	}
+	return nil
+}
+
+func newDDLFenceMessage(encoder codec.ControlMessageEncoder, fence *DDLFence) (*common.Message, error) {
+	value, err := json.Marshal(fence)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrMarshalFailed, err)
+	}
+	msg, err := encoder.EncodeControlMessage(fence.Key(), value, fence.CommitTs)
+	if err != nil {
+		return nil, cerror.Trace(err)
+	}
+	msg.Schema, msg.Table = &fence.Schema, &fence.Table
+	return msg, nil
+}
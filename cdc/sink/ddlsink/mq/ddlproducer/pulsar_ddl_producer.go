@@ -164,6 +164,14 @@ func newProducer(
 	if pConfig.SendTimeout != nil {
 		option.SendTimeout = pConfig.SendTimeout.Duration()
 	}
+	if pConfig.BatcherBuilder != nil {
+		option.BatcherBuilderType = pConfig.BatcherBuilder.Value()
+	}
+	// Chunking is not allowed when batching is enabled.
+	if pConfig.LargeMessageHandle.EnableChunking() {
+		option.EnableChunking = true
+		option.DisableBatching = true
+	}
 
 	producer, err := client.CreateProducer(option)
 	if err != nil {
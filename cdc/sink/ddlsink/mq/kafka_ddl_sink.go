@@ -92,6 +92,15 @@ func NewKafkaDDLSink(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if options.AutoCreate {
+		for _, ddlTopic := range eventRouter.GetDDLTopics() {
+			err = adminClient.CreateTopic(ctx,
+				kafka.CompactedTopicConfig(ddlTopic, options.ReplicationFactor), false)
+			if err != nil {
+				return nil, cerror.WrapError(cerror.ErrKafkaCreateTopic, err)
+			}
+		}
+	}
 
 	encoderConfig, err := util.GetEncoderConfig(changefeedID, sinkURI, protocol, replicaConfig, options.MaxMessageBytes)
 	if err != nil {
@@ -102,6 +111,25 @@ func NewKafkaDDLSink(
 	if err != nil {
 		return nil, cerror.WrapError(cerror.ErrKafkaInvalidConfig, err)
 	}
+	if err = checkDDLFenceEncoder(eventRouter, encoderBuilder); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// the claim-check files are expired after the messages referring to them
+	// are removed by the retention of the topic, if it's not configured.
+	var topicRetention time.Duration
+	if encoderConfig.LargeMessageHandle.EnableClaimCheck() {
+		topicRetention = getKafkaTopicRetention(ctx, adminClient, topic)
+	}
+	claimCheckGC, err := newClaimCheckGC(ctx, changefeedID, encoderConfig.LargeMessageHandle, topicRetention)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer func() {
+		if err != nil {
+			claimCheckGC.close()
+		}
+	}()
 
 	start := time.Now()
 	log.Info("Try to create a DDL sink producer",
@@ -113,6 +141,7 @@ func NewKafkaDDLSink(
 
 	ddlProducer := producerCreator(ctx, changefeedID, syncProducer)
 	s := newDDLSink(changefeedID, ddlProducer, adminClient, topicManager, eventRouter, encoderBuilder, protocol)
+	s.claimCheckGC = claimCheckGC
 	log.Info("DDL sink producer client created", zap.Duration("duration", time.Since(start)))
 	return s, nil
 }
@@ -15,6 +15,7 @@ package mq
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/log"
@@ -25,8 +26,10 @@ import (
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/manager"
 	"github.com/pingcap/tiflow/cdc/sink/metrics"
 	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/sink"
 	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"github.com/pingcap/tiflow/pkg/sink/kafka"
 	"go.uber.org/zap"
 )
@@ -74,6 +77,8 @@ type DDLSink struct {
 	statistics *metrics.Statistics
 	// admin is used to query kafka cluster information.
 	admin kafka.ClusterAdminClient
+	// claimCheckGC removes the expired claim-check files, it's nil if disabled.
+	claimCheckGC *claimCheckGC
 }
 
 func newDDLSink(
@@ -113,11 +118,27 @@ func (k *DDLSink) WriteDDLEvent(ctx context.Context, ddl *model.DDLEvent) error
 		return nil
 	}
 
-	topic := k.eventRouter.GetTopicForDDL(ddl)
+	// The DDL is sent to all topics of the table if the topic expression contains columns.
+	topics := k.eventRouter.GetTopicsForDDL(ddl)
+	if ddlTopic := k.eventRouter.GetDDLTopicForDDL(ddl); ddlTopic != "" {
+		return k.writeDDLEventToDDLTopic(ctx, ddl, msg, topics, ddlTopic)
+	}
+	for _, topic := range topics {
+		if err := k.writeDDLEventToTopic(ctx, ddl, msg, topic); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (k *DDLSink) writeDDLEventToTopic(
+	ctx context.Context, ddl *model.DDLEvent, msg *common.Message, topic string,
+) error {
 	partitionRule := getDDLDispatchRule(k.protocol)
 	log.Debug("Emit ddl event",
 		zap.Uint64("commitTs", ddl.CommitTs),
 		zap.String("query", ddl.Query),
+		zap.String("topic", topic),
 		zap.String("namespace", k.id.Namespace),
 		zap.String("changefeed", k.id.ID))
 	// Notice: We must call GetPartitionNum here,
@@ -141,10 +162,76 @@ func (k *DDLSink) WriteDDLEvent(ctx context.Context, ddl *model.DDLEvent) error
 	return errors.Trace(err)
 }
 
+// writeDDLEventToDDLTopic sends the DDL event to the first partition of the DDL
+// topic, followed by the fence markers, which are also broadcast to the data topics.
+func (k *DDLSink) writeDDLEventToDDLTopic(
+	ctx context.Context, ddl *model.DDLEvent, msg *common.Message, topics []string, ddlTopic string,
+) error {
+	tableName := ddl.TableInfo.TableName
+	if ddl.PreTableInfo != nil {
+		tableName = ddl.PreTableInfo.TableName
+	}
+	// The DDL topic is compacted, which rejects the messages without key, and
+	// keeps only the last message of a key, so each DDL is keyed uniquely.
+	if len(msg.Key) == 0 {
+		msg.Key = []byte(fmt.Sprintf("%s/%d", tableName.String(), ddl.CommitTs))
+	}
+	if _, err := k.topicManager.GetPartitionNum(ctx, ddlTopic); err != nil {
+		return errors.Trace(err)
+	}
+	log.Debug("Emit ddl event to the ddl topic",
+		zap.Uint64("commitTs", ddl.CommitTs),
+		zap.String("query", ddl.Query),
+		zap.String("ddlTopic", ddlTopic),
+		zap.String("namespace", k.id.Namespace),
+		zap.String("changefeed", k.id.ID))
+	err := k.statistics.RecordDDLExecution(func() error {
+		return k.producer.SyncSendMessage(ctx, ddlTopic, 0, msg)
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The bootstrap DDL does not change the schema, so nothing to fence.
+	if ddl.IsBootstrap {
+		return nil
+	}
+
+	encoder, ok := k.encoderBuilder.Build().(codec.ControlMessageEncoder)
+	if !ok {
+		return cerror.ErrDispatcherFailed.GenWithStack(
+			"the DDL fence is not supported by the %s protocol", k.protocol)
+	}
+	for _, topic := range topics {
+		partitionNum, err := k.topicManager.GetPartitionNum(ctx, topic)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		fence, err := newDDLFenceMessage(encoder, &DDLFence{
+			Schema:       tableName.Schema,
+			Table:        tableName.Table,
+			CommitTs:     ddl.CommitTs,
+			DDLTopic:     ddlTopic,
+			Topic:        topic,
+			PartitionNum: partitionNum,
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := k.producer.SyncSendMessage(ctx, ddlTopic, 0, fence); err != nil {
+			return errors.Trace(err)
+		}
+		if err := k.producer.SyncBroadcastMessage(ctx, topic, partitionNum, fence); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // WriteCheckpointTs sends the checkpoint ts to the MQ system.
 func (k *DDLSink) WriteCheckpointTs(ctx context.Context,
 	ts uint64, tables []*model.TableInfo,
 ) error {
+	k.claimCheckGC.updateCheckpointTs(ts)
 	encoder := k.encoderBuilder.Build()
 	msg, err := encoder.EncodeCheckpointEvent(ts)
 	if err != nil {
@@ -187,6 +274,7 @@ func (k *DDLSink) WriteCheckpointTs(ctx context.Context,
 
 // Close closes the sink.
 func (k *DDLSink) Close() {
+	k.claimCheckGC.close()
 	if k.producer != nil {
 		k.producer.Close()
 	}
@@ -15,14 +15,18 @@ package mq
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"testing"
 
 	mm "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/entry"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/cdc/sink/ddlsink/mq/ddlproducer"
 	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/codec/simple"
 	"github.com/pingcap/tiflow/pkg/sink/kafka"
 	"github.com/stretchr/testify/require"
 )
@@ -138,6 +142,81 @@ func TestWriteDDLEventToZeroPartition(t *testing.T) {
 	require.Len(t, s.producer.(*ddlproducer.MockDDLProducer).GetEvents("mock_topic", 2), 0)
 }
 
+func TestWriteDDLEventToDDLTopic(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uriTemplate := "kafka://%s/%s?kafka-version=0.9.0.0&max-batch-size=1" +
+		"&max-message-bytes=1048576&partition-num=2" +
+		"&kafka-client-id=unit-test&auto-create-topic=true&compression=gzip&protocol=simple"
+	uri := fmt.Sprintf(uriTemplate, "127.0.0.1:9092", kafka.DefaultMockTopicName)
+
+	sinkURI, err := url.Parse(uri)
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.DispatchRules = []*config.DispatchRule{
+		{Matcher: []string{"cdc.*"}, PartitionRule: "default", DDLTopic: "ddl_topic"},
+	}
+	require.NoError(t, replicaConfig.ValidateAndAdjust(sinkURI))
+
+	ctx = context.WithValue(ctx, "testing.T", t)
+	s, err := NewKafkaDDLSink(ctx, model.DefaultChangeFeedID("test"),
+		sinkURI, replicaConfig,
+		kafka.NewMockFactory,
+		ddlproducer.NewMockDDLProducer)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+	helper.DDL2Event("create database cdc")
+	ddl := helper.DDL2Event("create table cdc.person(id int, name varchar(32), primary key(id))")
+	ddl.CommitTs = 417318403368288260
+	err = s.WriteDDLEvent(ctx, ddl)
+	require.NoError(t, err)
+
+	producer := s.producer.(*ddlproducer.MockDDLProducer)
+	// the DDL and the fence are sent to the DDL topic.
+	ddlEvents := producer.GetEvents("ddl_topic", 0)
+	require.Len(t, ddlEvents, 2)
+	// the DDL and the fence are keyed uniquely, since the DDL topic is compacted.
+	require.Equal(t, "cdc.person/417318403368288260", string(ddlEvents[0].Key))
+	require.Equal(t, DDLFenceKeyPrefix+"cdc.person/417318403368288260/"+kafka.DefaultMockTopicName,
+		string(ddlEvents[1].Key))
+	// the fence is a control message of the protocol.
+	decoder, err := simple.NewDecoder(ctx, common.NewConfig(config.ProtocolSimple), nil)
+	require.NoError(t, err)
+	key, value, err := decoder.DecodeControlMessage(ddlEvents[1].Value)
+	require.NoError(t, err)
+	require.Equal(t, string(ddlEvents[1].Key), key)
+	var fence DDLFence
+	require.NoError(t, json.Unmarshal(value, &fence))
+	require.Equal(t, DDLFence{
+		Schema:       "cdc",
+		Table:        "person",
+		CommitTs:     ddl.CommitTs,
+		DDLTopic:     "ddl_topic",
+		Topic:        kafka.DefaultMockTopicName,
+		PartitionNum: 2,
+	}, fence)
+
+	// the fence is broadcast to all partitions of the data topic.
+	for partition := int32(0); partition < 2; partition++ {
+		events := producer.GetEvents(kafka.DefaultMockTopicName, partition)
+		require.Len(t, events, 1)
+		require.Equal(t, ddlEvents[1].Value, events[0].Value)
+	}
+
+	// the DDL of the table without DDL topic is sent to the data topic.
+	ddl = helper.DDL2Event("create table test.person(id int, name varchar(32), primary key(id))")
+	err = s.WriteDDLEvent(ctx, ddl)
+	require.NoError(t, err)
+	require.Len(t, producer.GetEvents("ddl_topic", 0), 2)
+	require.Len(t, producer.GetEvents(kafka.DefaultMockTopicName, 0), 2)
+}
+
 func TestWriteCheckpointTsToDefaultTopic(t *testing.T) {
 	t.Parallel()
 
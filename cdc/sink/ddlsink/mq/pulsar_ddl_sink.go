@@ -81,6 +81,20 @@ func NewPulsarDDLSink(
 	if err != nil {
 		return nil, cerror.WrapError(cerror.ErrKafkaInvalidConfig, err)
 	}
+	if err = checkDDLFenceEncoder(eventRouter, encoderBuilder); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// the retention of the pulsar topic is not queried, only the configured one is used.
+	claimCheckGC, err := newClaimCheckGC(ctx, changefeedID, encoderConfig.LargeMessageHandle, 0)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer func() {
+		if err != nil {
+			claimCheckGC.close()
+		}
+	}()
 
 	start := time.Now()
 	client, err := clientCreator(pConfig, changefeedID, replicaConfig.Sink)
@@ -101,6 +115,7 @@ func NewPulsarDDLSink(
 	}
 
 	s := newDDLSink(changefeedID, p, nil, topicManager, eventRouter, encoderBuilder, protocol)
+	s.claimCheckGC = claimCheckGC
 
 	return s, nil
 }
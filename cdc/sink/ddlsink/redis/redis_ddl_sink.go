@@ -0,0 +1,214 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/ddlsink"
+	dmlredis "github.com/pingcap/tiflow/cdc/sink/dmlsink/redis"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/builder"
+	predis "github.com/pingcap/tiflow/pkg/sink/redis"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// scanBatchSize is the number of keys scanned and removed in one round.
+const scanBatchSize = 512
+
+// Assert Sink implementation
+var _ ddlsink.Sink = (*DDLSink)(nil)
+
+// DDLSink is the redis DDL sink.
+// In the stream mode, the encoded DDL events are appended to the table streams.
+// In the kv mode, the hashes of the table are removed when the table is
+// dropped, truncated or renamed, so the stale rows are not left in the cache.
+type DDLSink struct {
+	// id indicates which changefeed this sink belongs to.
+	id  model.ChangeFeedID
+	cfg *predis.Config
+	// statistic is used to record the DDL metrics
+	statistics *metrics.Statistics
+	client     redis.UniversalClient
+	// encoderBuilder is only used by the stream mode.
+	encoderBuilder codec.RowEventEncoderBuilder
+}
+
+// NewDDLSink creates a ddl sink for redis.
+func NewDDLSink(ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+) (*DDLSink, error) {
+	cfg := predis.NewConfig()
+	if err := cfg.Apply(sinkURI); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var encoderBuilder codec.RowEventEncoderBuilder
+	if cfg.Mode == predis.ModeStream {
+		encoderConfig, err := dmlredis.NewEncoderConfig(changefeedID, sinkURI, replicaConfig)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		encoderBuilder, err = builder.NewRowEventEncoderBuilder(ctx, encoderConfig)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrRedisSinkInvalidConfig, err)
+		}
+	}
+
+	client := redis.NewClient(cfg.Options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+	}
+
+	return &DDLSink{
+		id:             changefeedID,
+		cfg:            cfg,
+		statistics:     metrics.NewStatistics(changefeedID, sink.RowSink),
+		client:         client,
+		encoderBuilder: encoderBuilder,
+	}, nil
+}
+
+// WriteDDLEvent writes the DDL event to redis.
+func (d *DDLSink) WriteDDLEvent(ctx context.Context, ddl *model.DDLEvent) error {
+	return d.statistics.RecordDDLExecution(func() error {
+		if d.cfg.Mode == predis.ModeKV {
+			return d.removeStaleKeys(ctx, ddl)
+		}
+		return d.appendDDLEvent(ctx, ddl)
+	})
+}
+
+func (d *DDLSink) appendDDLEvent(ctx context.Context, ddl *model.DDLEvent) error {
+	tableName := ddl.TableInfo.TableName
+	if tableName.Table == "" {
+		log.Info("Skip schema level ddl event, it has no table stream",
+			zap.Uint64("commitTs", ddl.CommitTs),
+			zap.String("query", ddl.Query),
+			zap.String("namespace", d.id.Namespace),
+			zap.String("changefeed", d.id.ID))
+		return nil
+	}
+	msg, err := d.encoderBuilder.Build().EncodeDDLEvent(ddl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if msg == nil {
+		log.Info("Skip ddl event", zap.Uint64("commitTs", ddl.CommitTs),
+			zap.String("query", ddl.Query),
+			zap.String("namespace", d.id.Namespace),
+			zap.String("changefeed", d.id.ID))
+		return nil
+	}
+
+	values := make([]interface{}, 0, 4)
+	if len(msg.Key) > 0 {
+		values = append(values, "key", msg.Key)
+	}
+	values = append(values, "value", msg.Value)
+	err = d.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: predis.StreamKey(d.cfg.KeyPrefix, tableName.Schema, tableName.Table),
+		MaxLen: d.cfg.StreamMaxLen,
+		Approx: d.cfg.StreamMaxLen > 0,
+		Values: values,
+	}).Err()
+	if err != nil {
+		return cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+	}
+	return nil
+}
+
+func (d *DDLSink) removeStaleKeys(ctx context.Context, ddl *model.DDLEvent) error {
+	var pattern string
+	switch ddl.Type {
+	case timodel.ActionDropSchema:
+		pattern = predis.SchemaKeyPattern(d.cfg.KeyPrefix, ddl.TableInfo.TableName.Schema)
+	case timodel.ActionDropTable, timodel.ActionTruncateTable:
+		tableName := ddl.TableInfo.TableName
+		pattern = predis.TableKeyPattern(d.cfg.KeyPrefix, tableName.Schema, tableName.Table)
+	case timodel.ActionRenameTable, timodel.ActionRenameTables:
+		if ddl.PreTableInfo == nil {
+			return nil
+		}
+		tableName := ddl.PreTableInfo.TableName
+		pattern = predis.TableKeyPattern(d.cfg.KeyPrefix, tableName.Schema, tableName.Table)
+	default:
+		return nil
+	}
+
+	removed := 0
+	iter := d.client.Scan(ctx, 0, pattern, scanBatchSize).Iterator()
+	keys := make([]string, 0, scanBatchSize)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == scanBatchSize {
+			if err := d.client.Unlink(ctx, keys...).Err(); err != nil {
+				return cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+			}
+			removed += len(keys)
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+	}
+	if len(keys) > 0 {
+		if err := d.client.Unlink(ctx, keys...).Err(); err != nil {
+			return cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+		}
+		removed += len(keys)
+	}
+	log.Info("Remove the stale keys of the ddl event",
+		zap.Uint64("commitTs", ddl.CommitTs),
+		zap.String("query", ddl.Query),
+		zap.String("pattern", pattern),
+		zap.Int("removed", removed),
+		zap.String("namespace", d.id.Namespace),
+		zap.String("changefeed", d.id.ID))
+	return nil
+}
+
+// WriteCheckpointTs does nothing, redis sink doesn't send checkpoint events.
+func (d *DDLSink) WriteCheckpointTs(_ context.Context,
+	_ uint64, _ []*model.TableInfo,
+) error {
+	return nil
+}
+
+// Close closes the sink.
+func (d *DDLSink) Close() {
+	if d.client != nil {
+		if err := d.client.Close(); err != nil {
+			log.Warn("close redis client failed",
+				zap.String("namespace", d.id.Namespace),
+				zap.String("changefeed", d.id.ID),
+				zap.Error(err))
+		}
+	}
+	if d.statistics != nil {
+		d.statistics.Close()
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newDDLSink(ctx context.Context, t *testing.T, addr, params string) *DDLSink {
+	sinkURI, err := url.Parse(fmt.Sprintf("redis://%s?%s", addr, params))
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	require.NoError(t, replicaConfig.ValidateAndAdjust(sinkURI))
+	sink, err := NewDDLSink(ctx, model.DefaultChangeFeedID("test"), sinkURI, replicaConfig)
+	require.NoError(t, err)
+	return sink
+}
+
+func newDDLEvent(typ timodel.ActionType, schema, table string) *model.DDLEvent {
+	return &model.DDLEvent{
+		CommitTs: 100,
+		Type:     typ,
+		TableInfo: &model.TableInfo{
+			TableName: model.TableName{Schema: schema, Table: table},
+			TableInfo: &timodel.TableInfo{},
+		},
+	}
+}
+
+func TestRemoveStaleKeys(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := newDDLSink(ctx, t, mr.Addr(), "mode=kv&key-prefix=cdc:")
+	defer sink.Close()
+
+	for _, key := range []string{
+		"cdc:test:t1:1", "cdc:test:t1:2", "cdc:test:t10:1",
+		"cdc:test:t2:1", "cdc:test:t3:1", "cdc:test2:t1:1", "other:test:t1:1",
+	} {
+		mr.HSet(key, "c1", "1")
+	}
+
+	// the ddl which doesn't change the table name keeps the keys.
+	require.NoError(t, sink.WriteDDLEvent(ctx, newDDLEvent(timodel.ActionAddColumn, "test", "t1")))
+	require.Len(t, mr.Keys(), 7)
+
+	require.NoError(t, sink.WriteDDLEvent(ctx, newDDLEvent(timodel.ActionTruncateTable, "test", "t1")))
+	require.ElementsMatch(t, []string{
+		"cdc:test:t10:1", "cdc:test:t2:1", "cdc:test:t3:1", "cdc:test2:t1:1", "other:test:t1:1",
+	}, mr.Keys())
+
+	rename := newDDLEvent(timodel.ActionRenameTable, "test", "t4")
+	rename.PreTableInfo = newDDLEvent(timodel.ActionRenameTable, "test", "t2").TableInfo
+	require.NoError(t, sink.WriteDDLEvent(ctx, rename))
+	require.ElementsMatch(t, []string{
+		"cdc:test:t10:1", "cdc:test:t3:1", "cdc:test2:t1:1", "other:test:t1:1",
+	}, mr.Keys())
+
+	require.NoError(t, sink.WriteDDLEvent(ctx, newDDLEvent(timodel.ActionDropSchema, "test", "")))
+	require.ElementsMatch(t, []string{"cdc:test2:t1:1", "other:test:t1:1"}, mr.Keys())
+
+	require.NoError(t, sink.WriteCheckpointTs(ctx, 200, nil))
+}
+
+func TestAppendDDLEvent(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sink := newDDLSink(ctx, t, mr.Addr(), "protocol=canal-json")
+	defer sink.Close()
+
+	ddl := newDDLEvent(timodel.ActionAddColumn, "test", "t1")
+	ddl.Query = "alter table test.t1 add column c2 int"
+	require.NoError(t, sink.WriteDDLEvent(ctx, ddl))
+	// the schema level ddl is skipped.
+	require.NoError(t, sink.WriteDDLEvent(ctx, newDDLEvent(timodel.ActionCreateSchema, "test", "")))
+
+	require.Equal(t, []string{"test:t1"}, mr.Keys())
+	entries, err := mr.Stream("test:t1")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Contains(t, entries[0].Values[1], `"sql":"alter table test.t1 add column c2 int"`)
+}
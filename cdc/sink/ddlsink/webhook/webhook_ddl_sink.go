@@ -0,0 +1,134 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/ddlsink"
+	dmlwebhook "github.com/pingcap/tiflow/cdc/sink/dmlsink/webhook"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/builder"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/webhook"
+	"go.uber.org/zap"
+)
+
+// checkpointInterval is the min interval of posting the checkpoint events.
+const checkpointInterval = 2 * time.Second
+
+// Assert Sink implementation
+var _ ddlsink.Sink = (*DDLSink)(nil)
+
+// DDLSink is a sink that posts DDL events and checkpoint events to the webhook.
+type DDLSink struct {
+	// id indicates which changefeed this sink belongs to.
+	id model.ChangeFeedID
+	// statistic is used to record the DDL metrics
+	statistics     *metrics.Statistics
+	client         *webhook.Client
+	encoderBuilder codec.RowEventEncoderBuilder
+
+	lastSendCheckpointTsTime time.Time
+}
+
+// NewDDLSink creates a ddl sink for webhook.
+func NewDDLSink(ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+) (*DDLSink, error) {
+	cfg := webhook.NewConfig()
+	if err := cfg.Apply(sinkURI); err != nil {
+		return nil, errors.Trace(err)
+	}
+	encoderConfig, err := dmlwebhook.NewEncoderConfig(changefeedID, sinkURI, replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	encoderBuilder, err := builder.NewRowEventEncoderBuilder(ctx, encoderConfig)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrWebhookSinkInvalidConfig, err)
+	}
+
+	return &DDLSink{
+		id:             changefeedID,
+		statistics:     metrics.NewStatistics(changefeedID, sink.TxnSink),
+		client:         webhook.NewClient(changefeedID, cfg),
+		encoderBuilder: encoderBuilder,
+	}, nil
+}
+
+// WriteDDLEvent encodes the DDL event and posts it to the webhook.
+func (d *DDLSink) WriteDDLEvent(ctx context.Context, ddl *model.DDLEvent) error {
+	msg, err := d.encoderBuilder.Build().EncodeDDLEvent(ddl)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if msg == nil {
+		log.Info("Skip ddl event", zap.Uint64("commitTs", ddl.CommitTs),
+			zap.String("query", ddl.Query),
+			zap.String("namespace", d.id.Namespace),
+			zap.String("changefeed", d.id.ID))
+		return nil
+	}
+
+	key := webhook.DDLIdempotencyKey(ddl.TableInfo.TableName, ddl.CommitTs)
+	err = d.statistics.RecordDDLExecution(func() error {
+		return d.client.Send(ctx, key, []*common.Message{msg})
+	})
+	return errors.Trace(err)
+}
+
+// WriteCheckpointTs posts the checkpoint event to the webhook if the protocol
+// supports it, the checkpoint events are posted at most once per checkpointInterval.
+func (d *DDLSink) WriteCheckpointTs(ctx context.Context,
+	ts uint64, tables []*model.TableInfo,
+) error {
+	if time.Since(d.lastSendCheckpointTsTime) < checkpointInterval {
+		return nil
+	}
+	msg, err := d.encoderBuilder.Build().EncodeCheckpointEvent(ts)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if msg == nil {
+		return nil
+	}
+	err = d.client.Send(ctx, webhook.CheckpointIdempotencyKey(ts), []*common.Message{msg})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	d.lastSendCheckpointTsTime = time.Now()
+	return nil
+}
+
+// Close closes the sink.
+func (d *DDLSink) Close() {
+	if d.client != nil {
+		d.client.Close()
+	}
+	if d.statistics != nil {
+		d.statistics.Close()
+	}
+}
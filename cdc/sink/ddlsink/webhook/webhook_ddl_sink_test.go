@@ -0,0 +1,98 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDDLEventAndCheckpointTs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu     sync.Mutex
+		keys   []string
+		bodies []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, r.Header.Get(webhook.IdempotencyKeyHeader))
+		bodies = append(bodies, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sinkURI, err := url.Parse(server.URL + "/events?protocol=canal-json&enable-tidb-extension=true")
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	require.NoError(t, replicaConfig.ValidateAndAdjust(sinkURI))
+	sink, err := NewDDLSink(ctx, model.DefaultChangeFeedID("test"), sinkURI, replicaConfig)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	ddlEvent := &model.DDLEvent{
+		CommitTs: 100,
+		Type:     timodel.ActionAddColumn,
+		Query:    "alter table test.table1 add col2 varchar(64)",
+		TableInfo: &model.TableInfo{
+			TableName: model.TableName{
+				Schema:  "test",
+				Table:   "table1",
+				TableID: 20,
+			},
+			TableInfo: &timodel.TableInfo{
+				Columns: []*timodel.ColumnInfo{
+					{
+						Name:      pmodel.NewCIStr("col1"),
+						FieldType: *types.NewFieldType(mysql.TypeLong),
+					},
+					{
+						Name:      pmodel.NewCIStr("col2"),
+						FieldType: *types.NewFieldType(mysql.TypeVarchar),
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, sink.WriteDDLEvent(ctx, ddlEvent))
+	require.NoError(t, sink.WriteCheckpointTs(ctx, 101, nil))
+	// the checkpoint events are throttled.
+	require.NoError(t, sink.WriteCheckpointTs(ctx, 102, nil))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"ddl-test.table1-100", "checkpoint-101"}, keys)
+	require.Contains(t, bodies[0], `"sql":"alter table test.table1 add col2 varchar(64)"`)
+	require.Contains(t, bodies[1], `"watermarkTs":101`)
+}
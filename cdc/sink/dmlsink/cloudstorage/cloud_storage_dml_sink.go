@@ -25,6 +25,8 @@ import (
 	"github.com/pingcap/log"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columntransformer"
 	"github.com/pingcap/tiflow/cdc/sink/metrics"
 	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
 	"github.com/pingcap/tiflow/cdc/sink/util"
@@ -84,6 +86,8 @@ type DMLSink struct {
 	defragmenter *defragmenter
 	// workers defines a group of workers for writing events to external storage.
 	workers []*dmlWorker
+	// transformer is used to transform the rows before encoding them.
+	transformer transformer.Transformer
 
 	alive struct {
 		sync.RWMutex
@@ -142,6 +146,10 @@ func NewDMLSink(ctx context.Context,
 	if err != nil {
 		return nil, cerror.WrapError(cerror.ErrStorageSinkInvalidConfig, err)
 	}
+	trans, err := columntransformer.New(replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
 	wgCtx, wgCancel := context.WithCancel(ctx)
 	s := &DMLSink{
@@ -150,6 +158,7 @@ func NewDMLSink(ctx context.Context,
 		outputRawChangeEvent: replicaConfig.Sink.CloudStorageConfig.GetOutputRawChangeEvent(),
 		encodingWorkers:      make([]*encodingWorker, defaultEncodingConcurrency),
 		workers:              make([]*dmlWorker, cfg.WorkerCount),
+		transformer:          trans,
 		statistics:           metrics.NewStatistics(changefeedID, sink.TxnSink),
 		cancel:               wgCancel,
 		dead:                 make(chan struct{}),
@@ -169,7 +178,7 @@ func NewDMLSink(ctx context.Context,
 	for i := 0; i < cfg.WorkerCount; i++ {
 		inputCh := chann.NewAutoDrainChann[eventFragment]()
 		s.workers[i] = newDMLWorker(i, s.changefeedID, storage, cfg, ext,
-			inputCh, pdClock, s.statistics)
+			encoderConfig, inputCh, pdClock, s.statistics)
 		workerChannels[i] = inputCh
 	}
 
@@ -248,6 +257,9 @@ func (s *DMLSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTa
 			txn.Callback()
 			continue
 		}
+		if err := transformer.ApplyToTxn(s.transformer, txn.Event); err != nil {
+			return errors.Trace(err)
+		}
 
 		tbl := cloudstorage.VersionedTableName{
 			TableNameWithPhysicTableID: model.TableName{
@@ -33,6 +33,8 @@ import (
 	"github.com/pingcap/tiflow/engine/pkg/clock"
 	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/pdutil"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/codec/parquet"
 	"github.com/pingcap/tiflow/pkg/util"
 	"github.com/stretchr/testify/require"
 )
@@ -187,6 +189,69 @@ func TestCloudStorageWriteEventsWithoutDateSeparator(t *testing.T) {
 	s.Close()
 }
 
+func TestCloudStorageWriteEventsWithParquet(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	parentDir := t.TempDir()
+	uri := fmt.Sprintf("file:///%s?flush-interval=2s", parentDir)
+	sinkURI, err := url.Parse(uri)
+	require.Nil(t, err)
+
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.DateSeparator = util.AddressOf(config.DateSeparatorNone.String())
+	replicaConfig.Sink.Protocol = util.AddressOf(config.ProtocolParquet.String())
+	replicaConfig.Sink.FileIndexWidth = util.AddressOf(6)
+	errCh := make(chan error, 5)
+	s, err := NewDMLSink(ctx,
+		model.DefaultChangeFeedID("test"),
+		pdutil.NewMonotonicClock(clock.New()),
+		sinkURI, replicaConfig, errCh)
+	require.Nil(t, err)
+	var cnt uint64 = 0
+	batch := 100
+	tableStatus := state.TableSinkSinking
+
+	// all the rows are encoded into one parquet file.
+	txns := generateTxnEvents(&cnt, batch, &tableStatus)
+	err = s.WriteEvents(txns...)
+	require.Nil(t, err)
+	time.Sleep(3 * time.Second)
+
+	tableDir := path.Join(parentDir, "test/table1/33")
+	fileNames := getTableFiles(t, tableDir)
+	require.ElementsMatch(t, []string{"CDC000001.parquet", "CDC.index"}, fileNames)
+	require.Equal(t, uint64(1000), atomic.LoadUint64(&cnt))
+
+	content, err := os.ReadFile(path.Join(tableDir, "CDC000001.parquet"))
+	require.Nil(t, err)
+	tableInfo := txns[0].Event.Rows[0].TableInfo
+	decoder, err := parquet.NewBatchDecoder(ctx, common.NewConfig(config.ProtocolParquet),
+		tableInfo, content)
+	require.Nil(t, err)
+	rows := 0
+	for {
+		tp, hasNext, err := decoder.HasNext()
+		require.Nil(t, err)
+		if !hasNext {
+			break
+		}
+		require.Equal(t, model.MessageTypeRow, tp)
+		row, err := decoder.NextRowChangedEvent()
+		require.Nil(t, err)
+		require.Equal(t, uint64(100), row.CommitTs)
+		require.Equal(t, []*model.ColumnData{
+			{ColumnID: 1, Value: int64(rows)},
+			{ColumnID: 2, Value: []byte("hello world")},
+		}, row.Columns)
+		rows++
+	}
+	require.Equal(t, 1000, rows)
+
+	cancel()
+	s.Close()
+}
+
 func TestCloudStorageWriteEventsWithDateSeparator(t *testing.T) {
 	t.Parallel()
 
@@ -27,10 +27,12 @@ import (
 	"github.com/pingcap/tiflow/cdc/sink/metrics"
 	mcloudstorage "github.com/pingcap/tiflow/cdc/sink/metrics/cloudstorage"
 	"github.com/pingcap/tiflow/pkg/chann"
+	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/pdutil"
 	"github.com/pingcap/tiflow/pkg/sink/cloudstorage"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/codec/parquet"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
@@ -43,6 +45,9 @@ type dmlWorker struct {
 	changeFeedID model.ChangeFeedID
 	storage      storage.ExternalStorage
 	config       *cloudstorage.Config
+	// encoderConfig is used to encode the data file when it is flushed if
+	// the protocol can not concatenate the encoded messages, such as parquet.
+	encoderConfig *common.Config
 	// toBeFlushedCh contains a set of batchedTask waiting to be flushed to cloud storage.
 	toBeFlushedCh          chan batchedTask
 	inputCh                *chann.DrainableChann[eventFragment]
@@ -67,6 +72,8 @@ type singleTableTask struct {
 	size      uint64
 	tableInfo *model.TableInfo
 	msgs      []*common.Message
+	// txns are kept to be encoded when the data file is flushed.
+	txns []*model.SingleTableTxn
 }
 
 func newBatchedTask() batchedTask {
@@ -75,7 +82,7 @@ func newBatchedTask() batchedTask {
 	}
 }
 
-func (t *batchedTask) handleSingleTableEvent(event eventFragment) {
+func (t *batchedTask) handleSingleTableEvent(event eventFragment, encodeOnFlush bool) {
 	table := event.versionedTable
 	if _, ok := t.batch[table]; !ok {
 		t.batch[table] = &singleTableTask{
@@ -89,6 +96,13 @@ func (t *batchedTask) handleSingleTableEvent(event eventFragment) {
 		v.size += uint64(len(msg.Value))
 	}
 	v.msgs = append(v.msgs, event.encodedMsgs...)
+	if encodeOnFlush {
+		// The events are not encoded yet, so the size is estimated.
+		for _, row := range event.event.Event.Rows {
+			v.size += uint64(row.ApproximateBytes())
+		}
+		v.txns = append(v.txns, event.event.Event)
+	}
 }
 
 func (t *batchedTask) generateTaskByTable(table cloudstorage.VersionedTableName) batchedTask {
@@ -109,6 +123,7 @@ func newDMLWorker(
 	storage storage.ExternalStorage,
 	config *cloudstorage.Config,
 	extension string,
+	encoderConfig *common.Config,
 	inputCh *chann.DrainableChann[eventFragment],
 	pdClock pdutil.Clock,
 	statistics *metrics.Statistics,
@@ -118,6 +133,7 @@ func newDMLWorker(
 		changeFeedID:      changefeedID,
 		storage:           storage,
 		config:            config,
+		encoderConfig:     encoderConfig,
 		inputCh:           inputCh,
 		toBeFlushedCh:     make(chan batchedTask, 64),
 		statistics:        statistics,
@@ -253,6 +269,11 @@ func (d *dmlWorker) writeIndexFile(ctx context.Context, path, content string) er
 	return err
 }
 
+// encodeOnFlush returns true if the data file must be encoded as a whole.
+func (d *dmlWorker) encodeOnFlush() bool {
+	return d.encoderConfig != nil && d.encoderConfig.Protocol == config.ProtocolParquet
+}
+
 func (d *dmlWorker) writeDataFile(ctx context.Context, path string, task *singleTableTask) error {
 	var callbacks []func()
 	buf := bytes.NewBuffer(make([]byte, 0, task.size))
@@ -264,6 +285,20 @@ func (d *dmlWorker) writeDataFile(ctx context.Context, path string, task *single
 		buf.Write(msg.Value)
 		callbacks = append(callbacks, msg.Callback)
 	}
+	if d.encodeOnFlush() {
+		encoder := parquet.NewEncoder(d.encoderConfig, task.tableInfo)
+		for _, txn := range task.txns {
+			if err := encoder.AppendTxnEvent(txn); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		data, err := encoder.Encode()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		buf = bytes.NewBuffer(data)
+		bytesCnt = int64(len(data))
+	}
 
 	if err := d.statistics.RecordBatchExecution(func() (int, int64, error) {
 		start := time.Now()
@@ -348,7 +383,7 @@ func (d *dmlWorker) genAndDispatchTask(ctx context.Context,
 			if !ok || atomic.LoadUint64(&d.isClosed) == 1 {
 				return nil
 			}
-			batchedTask.handleSingleTableEvent(frag)
+			batchedTask.handleSingleTableEvent(frag, d.encodeOnFlush())
 			// if the file size exceeds the upper limit, emit the flush task containing the table
 			// as soon as possible.
 			table := frag.versionedTable
@@ -55,7 +55,8 @@ func testDMLWorker(ctx context.Context, t *testing.T, dir string) *dmlWorker {
 	statistics := metrics.NewStatistics(model.DefaultChangeFeedID("dml-worker-test"), sink.TxnSink)
 	pdlock := pdutil.NewMonotonicClock(clock.New())
 	d := newDMLWorker(1, model.DefaultChangeFeedID("dml-worker-test"), storage,
-		cfg, ".json", chann.NewAutoDrainChann[eventFragment](), pdlock, statistics)
+		cfg, ".json", common.NewConfig(config.ProtocolCanalJSON),
+		chann.NewAutoDrainChann[eventFragment](), pdlock, statistics)
 	return d
 }
 
@@ -0,0 +1,172 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"context"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/chann"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/elasticsearch"
+	"go.uber.org/zap"
+)
+
+// dmlWorker denotes a worker responsible for writing transactions to the
+// elasticsearch cluster by the bulk API.
+type dmlWorker struct {
+	// worker id
+	id           int
+	changeFeedID model.ChangeFeedID
+	config       *elasticsearch.Config
+	client       *elasticsearch.Client
+	inputCh      *chann.DrainableChann[*dmlsink.TxnCallbackableEvent]
+	statistics   *metrics.Statistics
+}
+
+func newDMLWorker(
+	id int,
+	changefeedID model.ChangeFeedID,
+	config *elasticsearch.Config,
+	client *elasticsearch.Client,
+	statistics *metrics.Statistics,
+) *dmlWorker {
+	return &dmlWorker{
+		id:           id,
+		changeFeedID: changefeedID,
+		config:       config,
+		client:       client,
+		inputCh:      chann.NewAutoDrainChann[*dmlsink.TxnCallbackableEvent](),
+		statistics:   statistics,
+	}
+}
+
+// run batches the buffered transactions into bulk requests.
+func (d *dmlWorker) run(ctx context.Context) error {
+	log.Debug("elasticsearch dml worker started", zap.Int("workerID", d.id),
+		zap.String("namespace", d.changeFeedID.Namespace),
+		zap.String("changefeed", d.changeFeedID.ID))
+
+	txns := make([]*dmlsink.TxnCallbackableEvent, 0, 16)
+	for {
+		rows := 0
+		select {
+		case <-ctx.Done():
+			return errors.Trace(ctx.Err())
+		case txn, ok := <-d.inputCh.Out():
+			if !ok {
+				return nil
+			}
+			txns = append(txns, txn)
+			rows += len(txn.Event.Rows)
+		}
+	drain:
+		for rows < d.config.MaxBatchSize {
+			select {
+			case txn, ok := <-d.inputCh.Out():
+				if !ok {
+					break drain
+				}
+				txns = append(txns, txn)
+				rows += len(txn.Event.Rows)
+			default:
+				break drain
+			}
+		}
+
+		if err := d.writeTxns(ctx, txns); err != nil {
+			return errors.Trace(err)
+		}
+		for i := range txns {
+			txns[i] = nil
+		}
+		txns = txns[:0]
+	}
+}
+
+// writeTxns writes the transactions in one bulk request, the callbacks are
+// called only after all the actions are accepted.
+func (d *dmlWorker) writeTxns(ctx context.Context, txns []*dmlsink.TxnCallbackableEvent) error {
+	req := &elasticsearch.BulkRequest{}
+	callbacks := make([]func(), 0, len(txns))
+	var size int64
+	for _, txn := range txns {
+		callbacks = append(callbacks, txn.Callback)
+		if txn.GetTableSinkState() != state.TableSinkSinking {
+			continue
+		}
+		for _, row := range txn.Event.Rows {
+			if err := d.appendRow(req, row); err != nil {
+				return errors.Trace(err)
+			}
+			size += int64(row.ApproximateBytes())
+		}
+	}
+
+	if req.Len() > 0 {
+		err := d.statistics.RecordBatchExecution(func() (int, int64, error) {
+			if err := d.client.Bulk(ctx, req); err != nil {
+				return 0, 0, err
+			}
+			return req.Len(), size, nil
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	for _, callback := range callbacks {
+		callback()
+	}
+	return nil
+}
+
+// appendRow converts the row to the bulk actions. The commit ts is used as
+// the external version of the document, so an older row can't overwrite a
+// newer one. An update which changes the handle key deletes the old document.
+func (d *dmlWorker) appendRow(req *elasticsearch.BulkRequest, row *model.RowChangedEvent) error {
+	index, err := d.config.IndexName(row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName())
+	if err != nil {
+		return err
+	}
+
+	var preID string
+	if len(row.PreColumns) > 0 {
+		preID, err = elasticsearch.DocumentID(row.TableInfo, row.PreColumns)
+		if err != nil {
+			return err
+		}
+	}
+	if len(row.Columns) == 0 {
+		return req.Delete(index, preID, row.CommitTs)
+	}
+
+	id, err := elasticsearch.DocumentID(row.TableInfo, row.Columns)
+	if err != nil {
+		return err
+	}
+	if preID != "" && preID != id {
+		if err := req.Delete(index, preID, row.CommitTs); err != nil {
+			return err
+		}
+	}
+	doc, err := elasticsearch.Document(row.TableInfo, row.Columns)
+	if err != nil {
+		return err
+	}
+	return req.Index(index, id, row.CommitTs, doc)
+}
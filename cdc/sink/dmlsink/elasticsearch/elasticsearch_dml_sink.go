@@ -0,0 +1,191 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columntransformer"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/elasticsearch"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Assert EventSink[E event.TableEvent] implementation
+var _ dmlsink.EventSink[*model.SingleTableTxn] = (*DMLSink)(nil)
+
+// DMLSink is the elasticsearch sink.
+// It maps each table to an index and each row to a document whose id comes
+// from the handle key, the documents are written by the bulk API.
+// The data flow is as follows: **data** -> dmlWorkers -> elasticsearch
+// Events of the same table are sent to the same dmlWorker, and the commit ts
+// is used as the external version of the documents, so the stale updates
+// can't overwrite the newer ones even if they are retried.
+type DMLSink struct {
+	changefeedID model.ChangeFeedID
+	scheme       string
+	client       *elasticsearch.Client
+	// workers defines a group of workers for writing events to elasticsearch.
+	workers []*dmlWorker
+	// transformer is used to transform the rows before converting them to documents.
+	transformer transformer.Transformer
+
+	alive struct {
+		sync.RWMutex
+		isDead bool
+	}
+
+	statistics *metrics.Statistics
+
+	cancel func()
+	wg     sync.WaitGroup
+	dead   chan struct{}
+}
+
+// NewDMLSink creates an elasticsearch sink.
+func NewDMLSink(ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+	errCh chan error,
+) (*DMLSink, error) {
+	cfg := elasticsearch.NewConfig()
+	if err := cfg.Apply(sinkURI); err != nil {
+		return nil, err
+	}
+	trans, err := columntransformer.New(replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	wgCtx, wgCancel := context.WithCancel(ctx)
+	s := &DMLSink{
+		changefeedID: changefeedID,
+		scheme:       strings.ToLower(sinkURI.Scheme),
+		client:       elasticsearch.NewClient(changefeedID, cfg),
+		workers:      make([]*dmlWorker, cfg.WorkerCount),
+		transformer:  trans,
+		statistics:   metrics.NewStatistics(changefeedID, sink.TxnSink),
+		cancel:       wgCancel,
+		dead:         make(chan struct{}),
+	}
+	for i := 0; i < cfg.WorkerCount; i++ {
+		s.workers[i] = newDMLWorker(i, changefeedID, cfg, s.client, s.statistics)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.run(wgCtx)
+
+		s.alive.Lock()
+		s.alive.isDead = true
+		for _, w := range s.workers {
+			w.inputCh.CloseAndDrain()
+		}
+		s.alive.Unlock()
+		close(s.dead)
+
+		if err != nil && errors.Cause(err) != context.Canceled {
+			select {
+			case <-wgCtx.Done():
+			case errCh <- err:
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+func (s *DMLSink) run(ctx context.Context) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < len(s.workers); i++ {
+		worker := s.workers[i]
+		eg.Go(func() error {
+			return worker.run(ctx)
+		})
+	}
+
+	log.Info("elasticsearch dml worker started", zap.String("namespace", s.changefeedID.Namespace),
+		zap.String("changefeed", s.changefeedID.ID),
+		zap.Int("workerCount", len(s.workers)))
+
+	return eg.Wait()
+}
+
+// WriteEvents write events to elasticsearch sink.
+func (s *DMLSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTableTxn]) error {
+	s.alive.RLock()
+	defer s.alive.RUnlock()
+	if s.alive.isDead {
+		return errors.Trace(errors.New("dead dmlSink"))
+	}
+
+	for _, txn := range txns {
+		if txn.GetTableSinkState() != state.TableSinkSinking {
+			// The table where the event comes from is in stopping, so it's safe
+			// to drop the event directly.
+			txn.Callback()
+			continue
+		}
+		if err := transformer.ApplyToTxn(s.transformer, txn.Event); err != nil {
+			return errors.Trace(err)
+		}
+
+		s.statistics.ObserveRows(txn.Event.Rows...)
+		tableID := uint64(txn.Event.GetPhysicalTableID())
+		s.workers[tableID%uint64(len(s.workers))].inputCh.In() <- txn
+	}
+
+	return nil
+}
+
+// Close closes the elasticsearch sink.
+func (s *DMLSink) Close() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+
+	if s.client != nil {
+		s.client.Close()
+	}
+	if s.statistics != nil {
+		s.statistics.Close()
+	}
+}
+
+// Dead checks whether it's dead or not.
+func (s *DMLSink) Dead() <-chan struct{} {
+	return s.dead
+}
+
+// SchemeOption returns the scheme and the option.
+// The update events which change the unique key are split, so the old
+// document is deleted before the new one is indexed.
+func (s *DMLSink) SchemeOption() (string, bool) {
+	return s.scheme, false
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTableInfo() *model.TableInfo {
+	pk := types.NewFieldType(mysql.TypeLong)
+	pk.SetFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+	tidbTableInfo := &timodel.TableInfo{
+		ID:         20,
+		Name:       pmodel.NewCIStr("Table1"),
+		PKIsHandle: true,
+		Columns: []*timodel.ColumnInfo{
+			{ID: 1, Name: pmodel.NewCIStr("c1"), FieldType: *pk},
+			{ID: 2, Name: pmodel.NewCIStr("c2"), FieldType: *types.NewFieldType(mysql.TypeVarchar)},
+		},
+	}
+	return model.WrapTableInfo(100, "test", 33, tidbTableInfo)
+}
+
+func newTxn(
+	tableInfo *model.TableInfo, commitTs uint64, cnt *uint64,
+	tableStatus *state.TableSinkState, rows ...*model.RowChangedEvent,
+) *dmlsink.TxnCallbackableEvent {
+	for _, row := range rows {
+		row.CommitTs = commitTs
+		row.PhysicalTableID = 20
+		row.TableInfo = tableInfo
+	}
+	return &dmlsink.TxnCallbackableEvent{
+		Event: &model.SingleTableTxn{
+			PhysicalTableID: 20,
+			CommitTs:        commitTs,
+			TableInfo:       tableInfo,
+			Rows:            rows,
+		},
+		Callback: func() {
+			atomic.AddUint64(cnt, 1)
+		},
+		SinkState: tableStatus,
+	}
+}
+
+func newColumns(id int64, value string) []*model.ColumnData {
+	return []*model.ColumnData{
+		{ColumnID: 1, Value: id},
+		{ColumnID: 2, Value: []byte(value)},
+	}
+}
+
+func newServer(t *testing.T, response string) (*httptest.Server, func() []string) {
+	var (
+		mu    sync.Mutex
+		lines []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/_bulk", r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		lines = append(lines, strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")...)
+		mu.Unlock()
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), lines...)
+	}
+}
+
+func newSink(ctx context.Context, t *testing.T, serverURL string) (*DMLSink, chan error) {
+	sinkURI, err := url.Parse(strings.Replace(serverURL, "http://", "elasticsearch://", 1) +
+		"?worker-count=2&max-retries=0")
+	require.NoError(t, err)
+	errCh := make(chan error, 1)
+	s, err := NewDMLSink(ctx, model.DefaultChangeFeedID("test"), sinkURI,
+		config.GetDefaultReplicaConfig(), errCh)
+	require.NoError(t, err)
+	return s, errCh
+}
+
+func TestElasticsearchWriteEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, requests := newServer(t, `{"errors":false,"items":[]}`)
+	s, _ := newSink(ctx, t, server.URL)
+	defer s.Close()
+
+	var cnt uint64
+	tableStatus := state.TableSinkSinking
+	tableInfo := newTableInfo()
+	require.NoError(t, s.WriteEvents(
+		newTxn(tableInfo, 100, &cnt, &tableStatus,
+			&model.RowChangedEvent{Columns: newColumns(1, "a")},
+			&model.RowChangedEvent{Columns: newColumns(2, "b")}),
+		// the update changes the handle key.
+		newTxn(tableInfo, 101, &cnt, &tableStatus,
+			&model.RowChangedEvent{PreColumns: newColumns(1, "a"), Columns: newColumns(3, "a")}),
+		newTxn(tableInfo, 102, &cnt, &tableStatus,
+			&model.RowChangedEvent{PreColumns: newColumns(2, "b")}),
+	))
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 3
+	}, 10*time.Second, 100*time.Millisecond)
+
+	require.Equal(t, []string{
+		`{"index":{"_index":"test.table1","_id":"1","version":100,"version_type":"external_gte"}}`,
+		`{"c1":1,"c2":"a"}`,
+		`{"index":{"_index":"test.table1","_id":"2","version":100,"version_type":"external_gte"}}`,
+		`{"c1":2,"c2":"b"}`,
+		`{"delete":{"_index":"test.table1","_id":"1","version":101,"version_type":"external_gte"}}`,
+		`{"index":{"_index":"test.table1","_id":"3","version":101,"version_type":"external_gte"}}`,
+		`{"c1":3,"c2":"a"}`,
+		`{"delete":{"_index":"test.table1","_id":"2","version":102,"version_type":"external_gte"}}`,
+	}, requests())
+}
+
+func TestElasticsearchStoppingTable(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, requests := newServer(t, `{"errors":false,"items":[]}`)
+	s, _ := newSink(ctx, t, server.URL)
+	defer s.Close()
+
+	var cnt uint64
+	tableStatus := state.TableSinkStopping
+	require.NoError(t, s.WriteEvents(newTxn(newTableInfo(), 100, &cnt, &tableStatus,
+		&model.RowChangedEvent{Columns: newColumns(1, "a")})))
+	// the events of the stopping table are dropped.
+	require.Equal(t, uint64(1), atomic.LoadUint64(&cnt))
+	require.Empty(t, requests())
+}
+
+func TestElasticsearchRejected(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, _ := newServer(t, `{"errors":true,"items":[{"index":`+
+		`{"_index":"test.table1","_id":"1","status":400,"error":{"type":"mapper_parsing_exception"}}}]}`)
+	s, errCh := newSink(ctx, t, server.URL)
+	defer s.Close()
+
+	var cnt uint64
+	tableStatus := state.TableSinkSinking
+	require.NoError(t, s.WriteEvents(newTxn(newTableInfo(), 100, &cnt, &tableStatus,
+		&model.RowChangedEvent{Columns: newColumns(1, "a")})))
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, cerror.ErrElasticsearchRequestFailed)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the error is not reported")
+	}
+	// the checkpoint is not advanced.
+	require.Equal(t, uint64(0), atomic.LoadUint64(&cnt))
+	require.Eventually(t, func() bool {
+		select {
+		case <-s.Dead():
+			return true
+		default:
+			return false
+		}
+	}, 10*time.Second, 100*time.Millisecond)
+}
@@ -13,6 +13,8 @@
 
 package dmlsink
 
+import "github.com/pingcap/tiflow/cdc/model"
+
 // EventSink is the interface for event sink.
 type EventSink[E TableEvent] interface {
 	// WriteEvents writes events to the sink.
@@ -25,3 +27,24 @@ type EventSink[E TableEvent] interface {
 	// The EventSink meets internal errors and has been dead already.
 	Dead() <-chan struct{}
 }
+
+// SlowPartitionReporter is implemented by the sinks which track the in-flight
+// messages of the downstream partitions, such as the MQ sinks.
+type SlowPartitionReporter interface {
+	// SlowPartitions returns the partitions whose messages are not acknowledged in time.
+	SlowPartitions() []model.SlowPartition
+}
+
+// ConflictCounter is implemented by the sinks which detect the rows conflicted
+// with the downstream rows, such as the MySQL sink in the BDR mode.
+type ConflictCounter interface {
+	// ConflictCount returns the number of the conflicted rows written by the sink.
+	ConflictCount() uint64
+}
+
+// AtomicTxnSink is implemented by the sinks which may require the transactions
+// to be written as a whole, regardless of the transaction atomicity config.
+type AtomicTxnSink interface {
+	// RequireAtomicTxn returns true if the transactions can't be split.
+	RequireAtomicTxn() bool
+}
@@ -23,10 +23,14 @@ import (
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/blackhole"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/cloudstorage"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/elasticsearch"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/iceberg"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dmlproducer"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/manager"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/redis"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/txn"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/webhook"
 	"github.com/pingcap/tiflow/cdc/sink/tablesink"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
@@ -51,6 +55,14 @@ const (
 	CategoryCloudStorage = 3
 	// CategoryBlackhole is for Blackhole sink.
 	CategoryBlackhole = 4
+	// CategoryIceberg is for Iceberg sink.
+	CategoryIceberg = 5
+	// CategoryWebhook is for Webhook sink.
+	CategoryWebhook = 6
+	// CategoryRedis is for Redis sink.
+	CategoryRedis = 7
+	// CategoryElasticsearch is for Elasticsearch sink.
+	CategoryElasticsearch = 8
 )
 
 // SinkFactory is the factory of sink.
@@ -108,6 +120,35 @@ func New(
 		}
 		s.txnSink = storageSink
 		s.category = CategoryCloudStorage
+	case sink.IcebergS3Scheme, sink.IcebergFileScheme, sink.IcebergGCSScheme, sink.IcebergAzblobScheme:
+		icebergSink, err := iceberg.NewDMLSink(ctx, changefeedID, sinkURI, cfg, errCh)
+		if err != nil {
+			return nil, err
+		}
+		s.txnSink = icebergSink
+		s.category = CategoryIceberg
+	case sink.WebhookScheme, sink.WebhookSSLScheme:
+		webhookSink, err := webhook.NewDMLSink(ctx, changefeedID, sinkURI, cfg, errCh)
+		if err != nil {
+			return nil, err
+		}
+		s.txnSink = webhookSink
+		s.category = CategoryWebhook
+	case sink.RedisScheme, sink.RedisSSLScheme:
+		redisSink, err := redis.NewDMLSink(ctx, changefeedID, sinkURI, cfg, errCh)
+		if err != nil {
+			return nil, err
+		}
+		s.txnSink = redisSink
+		s.category = CategoryRedis
+	case sink.ElasticsearchScheme, sink.ElasticsearchSSLScheme,
+		sink.OpenSearchScheme, sink.OpenSearchSSLScheme:
+		esSink, err := elasticsearch.NewDMLSink(ctx, changefeedID, sinkURI, cfg, errCh)
+		if err != nil {
+			return nil, err
+		}
+		s.txnSink = esSink
+		s.category = CategoryElasticsearch
 	case sink.BlackHoleScheme:
 		bs := blackhole.NewDMLSink()
 		s.rowSink = bs
@@ -184,6 +225,32 @@ func (s *SinkFactory) Close() {
 	}
 }
 
+// SlowPartitions returns the slow partitions of the sink, it's nil
+// if the sink doesn't track the downstream partitions.
+func (s *SinkFactory) SlowPartitions() []model.SlowPartition {
+	if reporter, ok := s.txnSink.(dmlsink.SlowPartitionReporter); ok {
+		return reporter.SlowPartitions()
+	}
+	return nil
+}
+
+// ConflictCount returns the number of the conflicted rows written by the sink,
+// it's 0 if the sink doesn't detect the conflicts.
+func (s *SinkFactory) ConflictCount() uint64 {
+	if counter, ok := s.txnSink.(dmlsink.ConflictCounter); ok {
+		return counter.ConflictCount()
+	}
+	return 0
+}
+
+// RequireAtomicTxn returns true if the sink can't write the split transactions.
+func (s *SinkFactory) RequireAtomicTxn() bool {
+	if sink, ok := s.txnSink.(dmlsink.AtomicTxnSink); ok {
+		return sink.RequireAtomicTxn()
+	}
+	return false
+}
+
 // Category returns category of s.
 func (s *SinkFactory) Category() Category {
 	if s.category == 0 {
@@ -0,0 +1,225 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/pkg/chann"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/iceberg"
+	"go.uber.org/zap"
+)
+
+// dmlWorker denotes a worker responsible for writing rows to iceberg tables.
+type dmlWorker struct {
+	// worker id
+	id           int
+	changeFeedID model.ChangeFeedID
+	storage      storage.ExternalStorage
+	config       *iceberg.Config
+	// inputCh receives the transactions of a table sink whose commit ts are
+	// not greater than the resolved ts, see DMLSink.WriteEvents.
+	inputCh    *chann.DrainableChann[[]*dmlsink.TxnCallbackableEvent]
+	statistics *metrics.Statistics
+
+	tables map[model.TableName]*tableWriter
+}
+
+// tableWriter holds the rows of an iceberg table which have not been committed.
+type tableWriter struct {
+	table *iceberg.Table
+	// tableInfoVersion is the version of the table info which the schema of
+	// the iceberg table is built from.
+	tableInfoVersion uint64
+	batch            *iceberg.Batch
+	callbacks        []func()
+}
+
+func newDMLWorker(
+	id int,
+	changefeedID model.ChangeFeedID,
+	storage storage.ExternalStorage,
+	config *iceberg.Config,
+	inputCh *chann.DrainableChann[[]*dmlsink.TxnCallbackableEvent],
+	statistics *metrics.Statistics,
+) *dmlWorker {
+	return &dmlWorker{
+		id:           id,
+		changeFeedID: changefeedID,
+		storage:      storage,
+		config:       config,
+		inputCh:      inputCh,
+		statistics:   statistics,
+		tables:       make(map[model.TableName]*tableWriter),
+	}
+}
+
+// run writes the transactions received from the input channel, and commits
+// a snapshot for each table after all the transactions resolved by the same
+// resolved ts are written, so that a snapshot never contains a part of the
+// rows of a transaction. The rows of a table are flushed to data files
+// without a commit if the size of them exceeds the file size limit.
+func (d *dmlWorker) run(ctx context.Context) error {
+	log.Debug("iceberg dml worker started", zap.Int("workerID", d.id),
+		zap.String("namespace", d.changeFeedID.Namespace),
+		zap.String("changefeed", d.changeFeedID.ID))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Trace(ctx.Err())
+		case txns, ok := <-d.inputCh.Out():
+			if !ok {
+				return nil
+			}
+			var writers []*tableWriter
+			for _, txn := range txns {
+				w, err := d.writeTxn(ctx, txn)
+				if err != nil {
+					return errors.Trace(err)
+				}
+				if len(writers) == 0 || writers[len(writers)-1] != w {
+					writers = append(writers, w)
+				}
+			}
+			for _, w := range writers {
+				if err := d.commit(ctx, w); err != nil {
+					return errors.Trace(err)
+				}
+			}
+		}
+	}
+}
+
+func (d *dmlWorker) writeTxn(
+	ctx context.Context, txn *dmlsink.TxnCallbackableEvent,
+) (*tableWriter, error) {
+	tableInfo := txn.Event.TableInfo
+	w, err := d.getTableWriter(ctx, tableInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	for _, row := range txn.Event.Rows {
+		if err := w.batch.AppendRow(row); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	w.callbacks = append(w.callbacks, txn.Callback)
+	if w.batch.Size() >= d.config.FileSize {
+		if err := w.table.FlushBatch(ctx, w.batch); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return w, nil
+}
+
+// getTableWriter returns the writer of the table, the schema of the iceberg
+// table is updated if the table info has been changed.
+func (d *dmlWorker) getTableWriter(
+	ctx context.Context, tableInfo *model.TableInfo,
+) (*tableWriter, error) {
+	name := model.TableName{
+		Schema: tableInfo.GetSchemaName(),
+		Table:  tableInfo.GetTableName(),
+	}
+	w, ok := d.tables[name]
+	if ok && w.tableInfoVersion == tableInfo.Version {
+		return w, nil
+	}
+	if !ok {
+		w = &tableWriter{
+			table: iceberg.NewTable(d.changeFeedID, d.storage, d.config.Location,
+				name.Schema, name.Table),
+		}
+		d.tables[name] = w
+	}
+	// the rows of the previous table info must be committed before the
+	// schema is changed.
+	if err := d.commit(ctx, w); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := w.table.Load(ctx); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	var (
+		prev         *iceberg.Schema
+		lastColumnID int
+	)
+	if metadata := w.table.Metadata(); metadata != nil {
+		prev, lastColumnID = metadata.CurrentSchema(), metadata.LastColumnID
+	}
+	schema, lastColumnID := iceberg.NewSchemaFromTableInfo(tableInfo, prev, lastColumnID)
+	if err := w.table.UpdateSchema(ctx, schema, lastColumnID, tableInfo.Version); err != nil {
+		return nil, errors.Trace(err)
+	}
+	w.tableInfoVersion = tableInfo.Version
+	w.batch = iceberg.NewBatch(w.table.Metadata().CurrentSchema(), d.config.TimeZone)
+	log.Info("iceberg table schema updated", zap.Int("workerID", d.id),
+		zap.String("namespace", d.changeFeedID.Namespace),
+		zap.String("changefeed", d.changeFeedID.ID),
+		zap.String("table", name.String()),
+		zap.Uint64("tableInfoVersion", tableInfo.Version),
+		zap.Any("schema", w.batch.Schema()))
+	return w, nil
+}
+
+// commit commits the rows of the table as a new snapshot, and then calls the
+// callbacks of the transactions.
+func (d *dmlWorker) commit(ctx context.Context, w *tableWriter) error {
+	if w.batch == nil || w.batch.Len() == 0 {
+		w.callback()
+		return nil
+	}
+
+	batch := w.batch
+	if err := d.statistics.RecordBatchExecution(func() (int, int64, error) {
+		if err := w.table.WriteBatch(ctx, batch); err != nil {
+			return 0, 0, err
+		}
+		return batch.Len(), batch.Bytes(), nil
+	}); err != nil {
+		log.Error("failed to write rows to iceberg table",
+			zap.Int("workerID", d.id),
+			zap.String("namespace", d.changeFeedID.Namespace),
+			zap.String("changefeed", d.changeFeedID.ID),
+			zap.Error(err))
+		return errors.Trace(err)
+	}
+	log.Debug("commit iceberg snapshot success", zap.Int("workerID", d.id),
+		zap.String("namespace", d.changeFeedID.Namespace),
+		zap.String("changefeed", d.changeFeedID.ID),
+		zap.Int("rows", batch.Len()),
+		zap.Uint64("commitTs", batch.CommitTs()))
+
+	w.callback()
+	w.batch = iceberg.NewBatch(batch.Schema(), d.config.TimeZone)
+	return nil
+}
+
+func (w *tableWriter) callback() {
+	for _, cb := range w.callbacks {
+		if cb != nil {
+			cb()
+		}
+	}
+	w.callbacks = nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+	"hash/fnv"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/chann"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/iceberg"
+	putil "github.com/pingcap/tiflow/pkg/util"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Assert EventSink[E event.TableEvent] implementation
+var _ dmlsink.EventSink[*model.SingleTableTxn] = (*DMLSink)(nil)
+
+// DMLSink is the iceberg sink.
+// It writes the row changes to iceberg tables in the external storage.
+// The data flow is as follows: **data** -> dmlWorkers -> iceberg tables
+// Events of the same table, including all the partitions of a partitioned
+// table, are sent to the same dmlWorker. The events of a WriteEvents call,
+// which are resolved by the same resolved ts, are committed to the table as a
+// snapshot, so the snapshots of a table follow the checkpoint of the table.
+type DMLSink struct {
+	changefeedID model.ChangeFeedID
+	scheme       string
+	// workers defines a group of workers for writing events to iceberg tables.
+	workers []*dmlWorker
+
+	alive struct {
+		sync.RWMutex
+		isDead bool
+	}
+
+	statistics *metrics.Statistics
+
+	cancel func()
+	wg     sync.WaitGroup
+	dead   chan struct{}
+}
+
+// NewDMLSink creates an iceberg sink.
+func NewDMLSink(ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+	errCh chan error,
+) (*DMLSink, error) {
+	// create iceberg config and then apply the params of sinkURI to it.
+	cfg := iceberg.NewConfig()
+	err := cfg.Apply(ctx, sinkURI, replicaConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// create an external storage which holds all the iceberg tables.
+	storage, err := putil.GetExternalStorageFromURI(ctx, cfg.StorageURI.String())
+	if err != nil {
+		return nil, err
+	}
+
+	wgCtx, wgCancel := context.WithCancel(ctx)
+	s := &DMLSink{
+		changefeedID: changefeedID,
+		scheme:       strings.ToLower(sinkURI.Scheme),
+		workers:      make([]*dmlWorker, cfg.WorkerCount),
+		statistics:   metrics.NewStatistics(changefeedID, sink.TxnSink),
+		cancel:       wgCancel,
+		dead:         make(chan struct{}),
+	}
+	for i := 0; i < cfg.WorkerCount; i++ {
+		s.workers[i] = newDMLWorker(i, changefeedID, storage, cfg,
+			chann.NewAutoDrainChann[[]*dmlsink.TxnCallbackableEvent](), s.statistics)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.run(wgCtx)
+
+		s.alive.Lock()
+		s.alive.isDead = true
+		for _, w := range s.workers {
+			w.inputCh.CloseAndDrain()
+		}
+		s.alive.Unlock()
+		close(s.dead)
+
+		if err != nil && errors.Cause(err) != context.Canceled {
+			select {
+			case <-wgCtx.Done():
+			case errCh <- err:
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+func (s *DMLSink) run(ctx context.Context) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < len(s.workers); i++ {
+		worker := s.workers[i]
+		eg.Go(func() error {
+			return worker.run(ctx)
+		})
+	}
+
+	log.Info("iceberg dml worker started", zap.String("namespace", s.changefeedID.Namespace),
+		zap.String("changefeed", s.changefeedID.ID),
+		zap.Int("workerCount", len(s.workers)),
+		zap.Any("config", s.workers[0].config))
+
+	return eg.Wait()
+}
+
+// WriteEvents write events to iceberg sink.
+func (s *DMLSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTableTxn]) error {
+	s.alive.RLock()
+	defer s.alive.RUnlock()
+	if s.alive.isDead {
+		return errors.Trace(errors.New("dead dmlSink"))
+	}
+
+	batches := make([][]*dmlsink.TxnCallbackableEvent, len(s.workers))
+	for _, txn := range txns {
+		if txn.GetTableSinkState() != state.TableSinkSinking {
+			// The table where the event comes from is in stopping, so it's safe
+			// to drop the event directly.
+			txn.Callback()
+			continue
+		}
+
+		s.statistics.ObserveRows(txn.Event.Rows...)
+		// the partitions of a table are written to the same iceberg table,
+		// so the worker is chosen by the table name instead of the table id.
+		h := fnv.New32a()
+		h.Write([]byte(txn.Event.TableInfo.GetSchemaName()))
+		h.Write([]byte(txn.Event.TableInfo.GetTableName()))
+		idx := h.Sum32() % uint32(len(s.workers))
+		batches[idx] = append(batches[idx], txn)
+	}
+	// the transactions are sent in batches, so that the worker commits them
+	// in one snapshot.
+	for i, batch := range batches {
+		if len(batch) > 0 {
+			s.workers[i].inputCh.In() <- batch
+		}
+	}
+
+	return nil
+}
+
+// Close closes the iceberg sink.
+func (s *DMLSink) Close() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+
+	if s.statistics != nil {
+		s.statistics.Close()
+	}
+}
+
+// Dead checks whether it's dead or not.
+func (s *DMLSink) Dead() <-chan struct{} {
+	return s.dead
+}
+
+// SchemeOption returns the scheme and the option.
+func (s *DMLSink) SchemeOption() (string, bool) {
+	return s.scheme, false
+}
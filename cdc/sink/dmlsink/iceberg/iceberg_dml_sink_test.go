@@ -0,0 +1,197 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/iceberg"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTxnEvents(
+	cnt *uint64,
+	batch int,
+	tableStatus *state.TableSinkState,
+) []*dmlsink.TxnCallbackableEvent {
+	pk := types.NewFieldType(mysql.TypeLong)
+	pk.SetFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+	tidbTableInfo := &timodel.TableInfo{
+		Name:       pmodel.NewCIStr("table1"),
+		PKIsHandle: true,
+		Columns: []*timodel.ColumnInfo{
+			{ID: 1, Name: pmodel.NewCIStr("c1"), FieldType: *pk},
+			{ID: 2, Name: pmodel.NewCIStr("c2"), FieldType: *types.NewFieldType(mysql.TypeVarchar)},
+		},
+	}
+	tableInfo := model.WrapTableInfo(100, "test", 33, tidbTableInfo)
+
+	txns := make([]*dmlsink.TxnCallbackableEvent, 0, 10)
+	for i := 0; i < 10; i++ {
+		txn := &dmlsink.TxnCallbackableEvent{
+			Event: &model.SingleTableTxn{
+				CommitTs:         uint64(100 + i),
+				TableInfoVersion: 33,
+				TableInfo:        tableInfo,
+			},
+			Callback: func() {
+				atomic.AddUint64(cnt, uint64(batch))
+			},
+			SinkState: tableStatus,
+		}
+		for j := 0; j < batch; j++ {
+			row := &model.RowChangedEvent{
+				CommitTs:  uint64(100 + i),
+				TableInfo: tableInfo,
+				Columns: []*model.ColumnData{
+					{ColumnID: 1, Value: int64(i*batch + j)},
+					{ColumnID: 2, Value: []byte("hello world")},
+				},
+			}
+			txn.Event.Rows = append(txn.Event.Rows, row)
+		}
+		txns = append(txns, txn)
+	}
+	return txns
+}
+
+func TestIcebergWriteEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	parentDir := t.TempDir()
+	uri := fmt.Sprintf("iceberg+file://%s?flush-interval=2s", parentDir)
+	sinkURI, err := url.Parse(uri)
+	require.Nil(t, err)
+
+	errCh := make(chan error, 5)
+	s, err := NewDMLSink(ctx, model.DefaultChangeFeedID("test"),
+		sinkURI, config.GetDefaultReplicaConfig(), errCh)
+	require.Nil(t, err)
+
+	var cnt uint64 = 0
+	batch := 100
+	tableStatus := state.TableSinkSinking
+	txns := generateTxnEvents(&cnt, batch, &tableStatus)
+	err = s.WriteEvents(txns...)
+	require.Nil(t, err)
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 1000
+	}, 10*time.Second, 100*time.Millisecond)
+
+	storage, err := util.GetExternalStorageFromURI(ctx, "file://"+parentDir)
+	require.Nil(t, err)
+	table := iceberg.NewTable(model.DefaultChangeFeedID("test"), storage,
+		"file://"+parentDir, "test", "table1")
+	require.Nil(t, table.Load(ctx))
+	metadata := table.Metadata()
+	require.NotNil(t, metadata)
+	require.Equal(t, []int{1}, metadata.CurrentSchema().IdentifierFieldIDs)
+	snapshot := metadata.CurrentSnapshot()
+	require.NotNil(t, snapshot)
+	require.Equal(t, "1000", snapshot.Summary["added-records"])
+	require.Equal(t, "109", snapshot.Summary["tidb-commit-ts"])
+
+	cancel()
+	s.Close()
+}
+
+func TestIcebergCommitByResolvedTs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	parentDir := t.TempDir()
+	uri := fmt.Sprintf("iceberg+file://%s", parentDir)
+	sinkURI, err := url.Parse(uri)
+	require.Nil(t, err)
+
+	errCh := make(chan error, 5)
+	s, err := NewDMLSink(ctx, model.DefaultChangeFeedID("test"),
+		sinkURI, config.GetDefaultReplicaConfig(), errCh)
+	require.Nil(t, err)
+
+	// the rows are flushed to data files in every transaction.
+	s.workers[0].config.FileSize = 1024
+
+	var cnt uint64 = 0
+	tableStatus := state.TableSinkSinking
+	txns := generateTxnEvents(&cnt, 100, &tableStatus)
+	// each call of WriteEvents contains the transactions resolved by a
+	// resolved ts, which are committed in one snapshot.
+	require.Nil(t, s.WriteEvents(txns[:4]...))
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 400
+	}, 10*time.Second, 100*time.Millisecond)
+	require.Nil(t, s.WriteEvents(txns[4:]...))
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 1000
+	}, 10*time.Second, 100*time.Millisecond)
+
+	storage, err := util.GetExternalStorageFromURI(ctx, "file://"+parentDir)
+	require.Nil(t, err)
+	table := iceberg.NewTable(model.DefaultChangeFeedID("test"), storage,
+		"file://"+parentDir, "test", "table1")
+	require.Nil(t, table.Load(ctx))
+	snapshots := table.Metadata().Snapshots
+	require.Len(t, snapshots, 2)
+	require.Equal(t, "103", snapshots[0].Summary["tidb-commit-ts"])
+	require.Equal(t, "400", snapshots[0].Summary["added-records"])
+	require.Equal(t, "4", snapshots[0].Summary["added-data-files"])
+	require.Equal(t, "109", snapshots[1].Summary["tidb-commit-ts"])
+	require.Equal(t, "600", snapshots[1].Summary["added-records"])
+	require.Equal(t, "6", snapshots[1].Summary["added-data-files"])
+
+	cancel()
+	s.Close()
+}
+
+func TestIcebergWriteEventsWhenTableStopping(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	parentDir := t.TempDir()
+	uri := fmt.Sprintf("iceberg+file://%s?flush-interval=2s", parentDir)
+	sinkURI, err := url.Parse(uri)
+	require.Nil(t, err)
+
+	errCh := make(chan error, 5)
+	s, err := NewDMLSink(ctx, model.DefaultChangeFeedID("test"),
+		sinkURI, config.GetDefaultReplicaConfig(), errCh)
+	require.Nil(t, err)
+
+	var cnt uint64 = 0
+	tableStatus := state.TableSinkStopping
+	txns := generateTxnEvents(&cnt, 10, &tableStatus)
+	err = s.WriteEvents(txns...)
+	require.Nil(t, err)
+	// the events are dropped and the callbacks are called directly.
+	require.Equal(t, uint64(100), atomic.LoadUint64(&cnt))
+
+	cancel()
+	s.Close()
+}
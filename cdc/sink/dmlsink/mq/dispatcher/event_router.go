@@ -14,6 +14,7 @@
 package dispatcher
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/pingcap/log"
@@ -35,6 +36,9 @@ type EventRouter struct {
 	rules []struct {
 		partitionDispatcher partition.Dispatcher
 		topicDispatcher     topic.Dispatcher
+		// ddlTopic is the topic of the DDL events, it's empty if the DDL
+		// events are sent to the topic of the table.
+		ddlTopic string
 		filter.Filter
 	}
 }
@@ -55,6 +59,7 @@ func NewEventRouter(
 	rules := make([]struct {
 		partitionDispatcher partition.Dispatcher
 		topicDispatcher     topic.Dispatcher
+		ddlTopic            string
 		filter.Filter
 	}, 0, len(ruleConfigs))
 
@@ -67,18 +72,34 @@ func NewEventRouter(
 			f = filter.CaseInsensitive(f)
 		}
 
-		d := getPartitionDispatcher(
-			ruleConfig.PartitionRule, scheme, ruleConfig.IndexName, ruleConfig.Columns,
-		)
-		t, err := getTopicDispatcher(ruleConfig.TopicRule, defaultTopic, protocol, scheme)
+		t, err := getTopicDispatcher(ruleConfig, defaultTopic, protocol, scheme)
 		if err != nil {
 			return nil, err
 		}
+		d := getPartitionDispatcher(
+			ruleConfig.PartitionRule, scheme, ruleConfig.IndexName, ruleConfig.Columns, t,
+		)
+		// the mapping change markers of the adaptive dispatcher are control messages,
+		// the consumers can't tell them from the events of the other protocols.
+		if _, ok := d.(*partition.AdaptiveDispatcher); ok && !protocol.SupportControlMessage() {
+			return nil, cerror.ErrDispatcherFailed.GenWithStack(
+				"the adaptive partition dispatcher is not supported by the %s protocol", protocol)
+		}
+		if ruleConfig.DDLTopic != "" && !topic.IsHardCode(ruleConfig.DDLTopic) {
+			return nil, cerror.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(
+				ruleConfig.DDLTopic, "ddl-topic must be a fixed topic name")
+		}
+		// the DDL fences are control messages, like the mapping change markers.
+		if ruleConfig.DDLTopic != "" && !protocol.SupportControlMessage() {
+			return nil, cerror.ErrDispatcherFailed.GenWithStack(
+				"the ddl-topic is not supported by the %s protocol", protocol)
+		}
 		rules = append(rules, struct {
 			partitionDispatcher partition.Dispatcher
 			topicDispatcher     topic.Dispatcher
+			ddlTopic            string
 			filter.Filter
-		}{partitionDispatcher: d, topicDispatcher: t, Filter: f})
+		}{partitionDispatcher: d, topicDispatcher: t, ddlTopic: ruleConfig.DDLTopic, Filter: f})
 	}
 
 	return &EventRouter{
@@ -90,9 +111,42 @@ func NewEventRouter(
 // GetTopicForRowChange returns the target topic for row changes.
 func (s *EventRouter) GetTopicForRowChange(row *model.RowChangedEvent) string {
 	topicDispatcher, _ := s.matchDispatcher(row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName())
+	if d, ok := topicDispatcher.(*topic.ColumnTopicDispatcher); ok {
+		return d.DispatchRowChangedEvent(row)
+	}
 	return topicDispatcher.Substitute(row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName())
 }
 
+// SplitRowChangesByTopic splits the updates whose pre-image and post-image are
+// dispatched to different topics into a delete and an insert, so the delete is
+// sent to the old topic and the insert is sent to the new one.
+func (s *EventRouter) SplitRowChangesByTopic(rows []*model.RowChangedEvent) ([]*model.RowChangedEvent, error) {
+	var result []*model.RowChangedEvent
+	for i, row := range rows {
+		topicDispatcher, _ := s.matchDispatcher(row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName())
+		d, ok := topicDispatcher.(*topic.ColumnTopicDispatcher)
+		if !ok || !d.IsTopicChanged(row) {
+			if result != nil {
+				result = append(result, row)
+			}
+			continue
+		}
+		if result == nil {
+			result = make([]*model.RowChangedEvent, 0, len(rows)+1)
+			result = append(result, rows[:i]...)
+		}
+		deleteEvent, insertEvent, err := model.SplitUpdateEvent(row)
+		if err != nil {
+			return nil, cerror.Trace(err)
+		}
+		result = append(result, deleteEvent, insertEvent)
+	}
+	if result == nil {
+		return rows, nil
+	}
+	return result, nil
+}
+
 // GetTopicForDDL returns the target topic for DDL.
 func (s *EventRouter) GetTopicForDDL(ddl *model.DDLEvent) string {
 	var schema, table string
@@ -114,6 +168,56 @@ func (s *EventRouter) GetTopicForDDL(ddl *model.DDLEvent) string {
 	return topicDispatcher.Substitute(schema, table)
 }
 
+// GetTopicsForDDL returns all target topics for DDL, the DDL is sent to all topics
+// the rows of the table may be dispatched to if the topic expression contains columns.
+func (s *EventRouter) GetTopicsForDDL(ddl *model.DDLEvent) []string {
+	topicName := s.GetTopicForDDL(ddl)
+	tableName := ddl.TableInfo.TableName
+	if ddl.PreTableInfo != nil {
+		tableName = ddl.PreTableInfo.TableName
+	}
+	if tableName.Table == "" {
+		return []string{topicName}
+	}
+	topicDispatcher, _ := s.matchDispatcher(tableName.Schema, tableName.Table)
+	if d, ok := topicDispatcher.(*topic.ColumnTopicDispatcher); ok {
+		return d.Topics(tableName.Schema, tableName.Table)
+	}
+	return []string{topicName}
+}
+
+// GetDDLTopicForDDL returns the DDL topic of the DDL, it's empty if the DDL
+// should be sent to the topic returned by GetTopicForDDL.
+func (s *EventRouter) GetDDLTopicForDDL(ddl *model.DDLEvent) string {
+	tableName := ddl.TableInfo.TableName
+	if ddl.PreTableInfo != nil {
+		tableName = ddl.PreTableInfo.TableName
+	}
+	return s.GetDDLTopic(tableName.Schema, tableName.Table)
+}
+
+// GetDDLTopic returns the DDL topic of the table, the table is empty for
+// the schema level DDLs. It's empty if no DDL topic is set for the table.
+func (s *EventRouter) GetDDLTopic(schema, table string) string {
+	for _, rule := range s.rules {
+		if rule.MatchTable(schema, table) {
+			return rule.ddlTopic
+		}
+	}
+	return ""
+}
+
+// GetDDLTopics returns all DDL topics in the rules.
+func (s *EventRouter) GetDDLTopics() []string {
+	var topics []string
+	for _, rule := range s.rules {
+		if rule.ddlTopic != "" && !slices.Contains(topics, rule.ddlTopic) {
+			topics = append(topics, rule.ddlTopic)
+		}
+	}
+	return topics
+}
+
 // GetPartitionForRowChange returns the target partition for row changes.
 func (s *EventRouter) GetPartitionForRowChange(
 	row *model.RowChangedEvent,
@@ -123,6 +227,41 @@ func (s *EventRouter) GetPartitionForRowChange(
 		DispatchRowChangedEvent(row, partitionNum)
 }
 
+// GetPartitionForRowChangeWithMapping returns the target partition for row changes,
+// and the mapping change of the table if it's dispatched by the adaptive dispatcher
+// and the row is the first one dispatched under a new mapping.
+func (s *EventRouter) GetPartitionForRowChangeWithMapping(
+	row *model.RowChangedEvent,
+	partitionNum int32,
+) (int32, string, *partition.MappingChange, error) {
+	d := s.GetPartitionDispatcher(row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName())
+	if adaptive, ok := d.(*partition.AdaptiveDispatcher); ok {
+		index, key, change := adaptive.DispatchRowChangedEventWithMapping(row, partitionNum)
+		return index, key, change, nil
+	}
+	index, key, err := d.DispatchRowChangedEvent(row, partitionNum)
+	return index, key, nil, err
+}
+
+// HasAdaptiveDispatcher returns whether any table is dispatched by the adaptive dispatcher.
+func (s *EventRouter) HasAdaptiveDispatcher() bool {
+	for _, rule := range s.rules {
+		if _, ok := rule.partitionDispatcher.(*partition.AdaptiveDispatcher); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SetPartitionLoad sets the source of the partition load for the adaptive dispatchers.
+func (s *EventRouter) SetPartitionLoad(load partition.PartitionLoad) {
+	for _, rule := range s.rules {
+		if adaptive, ok := rule.partitionDispatcher.(*partition.AdaptiveDispatcher); ok {
+			adaptive.SetPartitionLoad(load)
+		}
+	}
+}
+
 // GetPartitionDispatcher returns the partition dispatcher for a specific table.
 func (s *EventRouter) GetPartitionDispatcher(schema, table string) partition.Dispatcher {
 	_, partitionDispatcher := s.matchDispatcher(schema, table)
@@ -132,7 +271,13 @@ func (s *EventRouter) GetPartitionDispatcher(schema, table string) partition.Dis
 // VerifyTables return error if any one table route rule is invalid.
 func (s *EventRouter) VerifyTables(infos []*model.TableInfo) error {
 	for _, table := range infos {
-		_, partitionDispatcher := s.matchDispatcher(table.TableName.Schema, table.TableName.Table)
+		topicDispatcher, partitionDispatcher := s.matchDispatcher(table.TableName.Schema, table.TableName.Table)
+		if d, ok := topicDispatcher.(*topic.ColumnTopicDispatcher); ok {
+			if _, ok := table.OffsetsByNames(d.Columns()); !ok {
+				return cerror.ErrDispatcherFailed.GenWithStack(
+					"columns not found when verify the table, table: %v, topic: %s", table.TableName, d)
+			}
+		}
 		switch v := partitionDispatcher.(type) {
 		case *partition.IndexValueDispatcher:
 			if v.IndexName != "" {
@@ -168,6 +313,15 @@ func (s *EventRouter) GetActiveTopics(activeTables []model.TableName) []string {
 	topicsMap := make(map[string]bool, len(activeTables))
 	for _, table := range activeTables {
 		topicDispatcher, _ := s.matchDispatcher(table.Schema, table.Table)
+		if d, ok := topicDispatcher.(*topic.ColumnTopicDispatcher); ok {
+			for _, topicName := range d.Topics(table.Schema, table.Table) {
+				if !topicsMap[topicName] {
+					topicsMap[topicName] = true
+					topics = append(topics, topicName)
+				}
+			}
+			continue
+		}
 		topicName := topicDispatcher.Substitute(table.Schema, table.Table)
 		if topicName == s.defaultTopic {
 			log.Debug("topic name corresponding to the table is the same as the default topic name",
@@ -213,6 +367,7 @@ func (s *EventRouter) matchDispatcher(
 // getPartitionDispatcher returns the partition dispatcher for a specific partition rule.
 func getPartitionDispatcher(
 	rule string, scheme string, indexName string, columns []string,
+	topicDispatcher topic.Dispatcher,
 ) partition.Dispatcher {
 	switch strings.ToLower(rule) {
 	case "default":
@@ -228,6 +383,8 @@ func getPartitionDispatcher(
 		return partition.NewIndexValueDispatcher(indexName)
 	case "columns":
 		return partition.NewColumnsDispatcher(columns)
+	case "adaptive":
+		return partition.NewAdaptiveDispatcher(topicDispatcher.Substitute)
 	default:
 	}
 
@@ -235,15 +392,16 @@ func getPartitionDispatcher(
 		return partition.NewKeyDispatcher(rule)
 	}
 
-	log.Warn("the partition dispatch rule is not default/ts/table/index-value/columns," +
+	log.Warn("the partition dispatch rule is not default/ts/table/index-value/columns/adaptive," +
 		" use the default rule instead.")
 	return partition.NewDefaultDispatcher()
 }
 
 // getTopicDispatcher returns the topic dispatcher for a specific topic rule (aka topic expression).
 func getTopicDispatcher(
-	rule string, defaultTopic string, protocol config.Protocol, schema string,
+	ruleConfig *config.DispatchRule, defaultTopic string, protocol config.Protocol, schema string,
 ) (topic.Dispatcher, error) {
+	rule := ruleConfig.TopicRule
 	if rule == "" {
 		return topic.NewStaticTopicDispatcher(defaultTopic), nil
 	}
@@ -254,6 +412,9 @@ func getTopicDispatcher(
 
 	// check if this rule is a valid topic expression
 	topicExpr := topic.Expression(rule)
+	if topicExpr.IsColumnExpression() {
+		return getColumnTopicDispatcher(ruleConfig, topicExpr, defaultTopic, protocol, schema)
+	}
 	err := validateTopicExpression(topicExpr, schema, protocol)
 	if err != nil {
 		return nil, err
@@ -261,6 +422,27 @@ func getTopicDispatcher(
 	return topic.NewDynamicTopicDispatcher(topicExpr), nil
 }
 
+// getColumnTopicDispatcher returns the topic dispatcher for the topic expression
+// with the column placeholders or functions, it's only supported by kafka.
+func getColumnTopicDispatcher(
+	ruleConfig *config.DispatchRule, topicExpr topic.Expression,
+	defaultTopic string, protocol config.Protocol, scheme string,
+) (topic.Dispatcher, error) {
+	if sink.IsPulsarScheme(scheme) || protocol == config.ProtocolAvro {
+		return nil, cerror.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(topicExpr,
+			"the column placeholders and functions are only supported by kafka, except the avro protocol")
+	}
+	overflowTopic := ruleConfig.OverflowTopic
+	if overflowTopic == "" {
+		overflowTopic = defaultTopic
+	}
+	maxTopics := ruleConfig.MaxTopics
+	if maxTopics <= 0 {
+		maxTopics = config.DefaultMaxTopicsPerDispatchRule
+	}
+	return topic.NewColumnTopicDispatcher(topicExpr, ruleConfig.AllowedTopics, overflowTopic, maxTopics)
+}
+
 func validateTopicExpression(expr topic.Expression, scheme string, protocol config.Protocol) error {
 	if sink.IsPulsarScheme(scheme) {
 		return expr.PulsarValidate()
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher/partition"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher/topic"
 	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/sink"
 	"github.com/stretchr/testify/require"
 )
@@ -270,6 +271,71 @@ func TestGetPartitionForRowChange(t *testing.T) {
 	require.Equal(t, int32(1), p)
 }
 
+func TestGetPartitionForRowChangeWithMapping(t *testing.T) {
+	t.Parallel()
+
+	replicaConfig := &config.ReplicaConfig{
+		Sink: &config.SinkConfig{
+			DispatchRules: []*config.DispatchRule{
+				{
+					Matcher:       []string{"test_adaptive.*"},
+					PartitionRule: "adaptive",
+					TopicRule:     "{schema}_{table}",
+				},
+			},
+		},
+	}
+	// the mapping change markers can't be carried by the protocol.
+	_, err := NewEventRouter(replicaConfig, config.ProtocolCanalJSON, "test", sink.KafkaScheme)
+	require.ErrorIs(t, err, cerror.ErrDispatcherFailed)
+
+	d, err := NewEventRouter(replicaConfig, config.ProtocolSimple, "test", sink.KafkaScheme)
+	require.NoError(t, err)
+	require.True(t, d.HasAdaptiveDispatcher())
+	require.IsType(t, &partition.AdaptiveDispatcher{},
+		d.GetPartitionDispatcher("test_adaptive", "table"))
+
+	cols := []*model.Column{
+		{
+			Name:  "id",
+			Value: 1,
+			Flag:  model.HandleKeyFlag | model.PrimaryKeyFlag,
+		},
+	}
+	tableInfo := model.BuildTableInfo("test_adaptive", "table", cols, [][]int{{0}})
+	row := &model.RowChangedEvent{
+		TableInfo: tableInfo,
+		Columns:   model.Columns2ColumnDatas(cols, tableInfo),
+		CommitTs:  1,
+	}
+	// the adaptive dispatcher dispatches the table like the table dispatcher without load.
+	expected, _, err := partition.NewTableDispatcher().DispatchRowChangedEvent(row, 16)
+	require.NoError(t, err)
+	p, _, change, err := d.GetPartitionForRowChangeWithMapping(row, 16)
+	require.NoError(t, err)
+	require.Equal(t, expected, p)
+	require.Nil(t, change)
+
+	// the partition number is changed, so is the mapping.
+	row.CommitTs = 2
+	p, _, change, err = d.GetPartitionForRowChangeWithMapping(row, 8)
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	require.Equal(t, []int32{expected}, change.Previous)
+	require.Equal(t, []int32{p}, change.Current)
+
+	// the other dispatchers never change the mapping.
+	p, _, change, err = d.GetPartitionForRowChangeWithMapping(&model.RowChangedEvent{
+		TableInfo: &model.TableInfo{
+			TableName: model.TableName{Schema: "a", Table: "table"},
+		},
+		CommitTs: 1,
+	}, 2)
+	require.NoError(t, err)
+	require.Nil(t, change)
+	require.Equal(t, int32(1), p)
+}
+
 func TestGetTopicForDDL(t *testing.T) {
 	t.Parallel()
 
@@ -404,6 +470,54 @@ func TestGetTopicForDDL(t *testing.T) {
 	}
 }
 
+func TestGetDDLTopic(t *testing.T) {
+	t.Parallel()
+
+	replicaConfig := &config.ReplicaConfig{
+		Sink: &config.SinkConfig{
+			DispatchRules: []*config.DispatchRule{
+				{
+					Matcher:   []string{"test.*"},
+					TopicRule: "hello_{schema}",
+					DDLTopic:  "test_ddl",
+				},
+				{
+					Matcher:   []string{"sbs.*"},
+					TopicRule: "{schema}_{table}",
+					DDLTopic:  "test_ddl",
+				},
+				{
+					Matcher:   []string{"*.*"},
+					TopicRule: "{schema}_{table}",
+				},
+			},
+		},
+	}
+
+	// the DDL fences can't be carried by the protocol.
+	_, err := NewEventRouter(replicaConfig, config.ProtocolCanalJSON, "test", sink.KafkaScheme)
+	require.ErrorIs(t, err, cerror.ErrDispatcherFailed)
+
+	d, err := NewEventRouter(replicaConfig, config.ProtocolSimple, "test", sink.KafkaScheme)
+	require.NoError(t, err)
+	require.Equal(t, []string{"test_ddl"}, d.GetDDLTopics())
+	require.Equal(t, "test_ddl", d.GetDDLTopic("test", "t1"))
+	require.Equal(t, "test_ddl", d.GetDDLTopic("sbs", ""))
+	require.Equal(t, "", d.GetDDLTopic("other", "t1"))
+
+	// the DDL topic of the renamed table is decided by the table before the DDL.
+	require.Equal(t, "test_ddl", d.GetDDLTopicForDDL(&model.DDLEvent{
+		PreTableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test", Table: "t1"}},
+		TableInfo:    &model.TableInfo{TableName: model.TableName{Schema: "other", Table: "t1"}},
+		Type:         timodel.ActionRenameTable,
+	}))
+
+	// the DDL topic must be a fixed topic name.
+	replicaConfig.Sink.DispatchRules[0].DDLTopic = "{schema}_ddl"
+	_, err = NewEventRouter(replicaConfig, config.ProtocolSimple, "test", sink.KafkaScheme)
+	require.ErrorContains(t, err, "ddl-topic must be a fixed topic name")
+}
+
 func TestVerifyTables(t *testing.T) {
 	t.Parallel()
 
@@ -422,3 +536,98 @@ func TestVerifyTables(t *testing.T) {
 	err = d.VerifyTables([]*model.TableInfo{tableInfo})
 	require.NoError(t, err)
 }
+
+func TestColumnTopicExpression(t *testing.T) {
+	t.Parallel()
+
+	replicaConfig := &config.ReplicaConfig{
+		Sink: &config.SinkConfig{
+			DispatchRules: []*config.DispatchRule{
+				{
+					Matcher:       []string{"test.orders"},
+					TopicRule:     "orders_{lower(column:tenant)}",
+					AllowedTopics: []string{"orders_a", "orders_b"},
+					OverflowTopic: "orders_overflow",
+				},
+				{
+					Matcher:   []string{"test.*"},
+					TopicRule: "{table}_{hash-mod(column:tenant,2)}",
+				},
+			},
+		},
+	}
+	d, err := NewEventRouter(replicaConfig, config.ProtocolCanalJSON, "test", sink.KafkaScheme)
+	require.NoError(t, err)
+
+	newRow := func(table string, tenant any) *model.RowChangedEvent {
+		cols := []*model.Column{
+			{Name: "id", Value: 1, Flag: model.HandleKeyFlag | model.PrimaryKeyFlag},
+			{Name: "tenant", Value: tenant},
+		}
+		tableInfo := model.BuildTableInfo("test", table, cols, [][]int{{0}})
+		return &model.RowChangedEvent{
+			TableInfo: tableInfo,
+			Columns:   model.Columns2ColumnDatas(cols, tableInfo),
+		}
+	}
+	// the rows are dispatched to the overflow topic if the topic is not allowed.
+	require.Equal(t, "orders_a", d.GetTopicForRowChange(newRow("orders", []byte("A"))))
+	require.Equal(t, "orders_b", d.GetTopicForRowChange(newRow("orders", "b")))
+	require.Equal(t, "orders_overflow", d.GetTopicForRowChange(newRow("orders", "c")))
+	require.Equal(t, "orders_overflow", d.GetTopicForRowChange(newRow("orders", nil)))
+	require.Equal(t, "users_1", d.GetTopicForRowChange(newRow("users", 1)))
+	require.Equal(t, "users_0", d.GetTopicForRowChange(newRow("users", 4)))
+	// the default topic is the overflow topic if it's not set.
+	require.Equal(t, "test", d.GetTopicForRowChange(newRow("users", nil)))
+
+	// the DDLs and the checkpoints are sent to all topics of the table.
+	require.Equal(t, []string{"orders_a", "orders_b", "orders_overflow"}, d.GetTopicsForDDL(&model.DDLEvent{
+		TableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test", Table: "orders"}},
+	}))
+	require.Equal(t, []string{"users_0", "users_1", "test"}, d.GetTopicsForDDL(&model.DDLEvent{
+		TableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test", Table: "users"}},
+	}))
+	require.Equal(t, []string{"test"}, d.GetTopicsForDDL(&model.DDLEvent{
+		TableInfo: &model.TableInfo{TableName: model.TableName{Schema: "test"}},
+	}))
+	require.Equal(t, []string{"orders_a", "orders_b", "orders_overflow", "users_0", "users_1", "test"},
+		d.GetActiveTopics([]model.TableName{{Schema: "test", Table: "orders"}, {Schema: "test", Table: "users"}}))
+
+	// the update moving the row to another topic is split into a delete to the old
+	// topic and an insert to the new one.
+	moved := newRow("orders", "b")
+	moved.PreColumns = newRow("orders", "a").Columns
+	kept := newRow("orders", "a")
+	kept.PreColumns = kept.Columns
+	rows, err := d.SplitRowChangesByTopic([]*model.RowChangedEvent{kept, moved})
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	require.Same(t, kept, rows[0])
+	require.True(t, rows[1].IsDelete())
+	require.Equal(t, "orders_a", d.GetTopicForRowChange(rows[1]))
+	require.True(t, rows[2].IsInsert())
+	require.Equal(t, "orders_b", d.GetTopicForRowChange(rows[2]))
+	unchanged := []*model.RowChangedEvent{kept}
+	rows, err = d.SplitRowChangesByTopic(unchanged)
+	require.NoError(t, err)
+	require.Equal(t, unchanged, rows)
+
+	// the column referred by the topic expression must exist.
+	require.NoError(t, d.VerifyTables([]*model.TableInfo{newRow("orders", "a").TableInfo}))
+	tableInfo := model.BuildTableInfo("test", "users", []*model.Column{{Name: "id"}}, nil)
+	require.ErrorContains(t, d.VerifyTables([]*model.TableInfo{tableInfo}), "columns not found")
+
+	// the topics of the column expression are bounded.
+	replicaConfig.Sink.DispatchRules[0].AllowedTopics = nil
+	_, err = NewEventRouter(replicaConfig, config.ProtocolCanalJSON, "test", sink.KafkaScheme)
+	require.ErrorContains(t, err, "allowed-topics must be set")
+	replicaConfig.Sink.DispatchRules[0].AllowedTopics = []string{"orders_a", "orders_b"}
+	replicaConfig.Sink.DispatchRules[1].MaxTopics = 1
+	_, err = NewEventRouter(replicaConfig, config.ProtocolCanalJSON, "test", sink.KafkaScheme)
+	require.ErrorContains(t, err, "exceeds max-topics")
+
+	// the column expression is not supported by pulsar.
+	replicaConfig.Sink.DispatchRules[1].MaxTopics = 0
+	_, err = NewEventRouter(replicaConfig, config.ProtocolCanalJSON, "test", sink.PulsarScheme)
+	require.ErrorContains(t, err, "only supported by kafka")
+}
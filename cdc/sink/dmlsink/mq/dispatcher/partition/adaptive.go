@@ -0,0 +1,303 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partition
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/hash"
+	"go.uber.org/zap"
+)
+
+const (
+	// adaptiveCheckInterval is the interval to check the throughput of the partitions.
+	adaptiveCheckInterval = 10 * time.Second
+	// adaptiveHotRatio is the ratio of a hot partition's throughput to the average.
+	adaptiveHotRatio = 2.0
+	// adaptiveHotShare is the minimum share of a hot partition's throughput which
+	// comes from a table to spread the table. The share is estimated by the approximate
+	// data size of the rows, which is smaller than the encoded size, so the table is
+	// spread only if it's obviously the cause of the hot partition.
+	adaptiveHotShare = 0.5
+)
+
+// PartitionLoad reports the load of the partitions.
+type PartitionLoad interface {
+	// PartitionWriteBytes returns the accumulated written bytes of each partition of the topic.
+	PartitionWriteBytes(topic string, partitionNum int32) []float64
+}
+
+// MappingChange is the change of the partitions which a table is dispatched to.
+// Under a mapping, the rows with the same handle key are always dispatched to the
+// same partition. To keep the order of the rows with the same key, consumers should
+// consume the rows before CommitTs in the Previous partitions before the rows since
+// CommitTs in the Current partitions.
+type MappingChange struct {
+	Schema       string  `json:"schema"`
+	Table        string  `json:"table"`
+	TableID      int64   `json:"table-id"`
+	CommitTs     uint64  `json:"commit-ts"`
+	PartitionNum int32   `json:"partition-num"`
+	Previous     []int32 `json:"previous"`
+	Current      []int32 `json:"current"`
+}
+
+// Partitions returns all partitions involved in the change in ascending order.
+func (c *MappingChange) Partitions() []int32 {
+	partitions := make(map[int32]struct{}, len(c.Previous)+len(c.Current))
+	for _, p := range c.Previous {
+		partitions[p] = struct{}{}
+	}
+	for _, p := range c.Current {
+		partitions[p] = struct{}{}
+	}
+	result := make([]int32, 0, len(partitions))
+	for p := range partitions {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// AdaptiveDispatcher is a partition dispatcher which dispatches events in the same
+// way as the TableDispatcher, but spreads a hot table with the handle key across
+// more partitions by the handle key value, when the partitions of the table are
+// much busier than the average. The mapping of a table is changed only between
+// transactions, and each change is reported to emit a marker for consumers.
+type AdaptiveDispatcher struct {
+	hasher *hash.PositionInertia
+	lock   sync.Mutex
+
+	// topic returns the topic of the table, the load of which is checked.
+	topic  func(schema, table string) string
+	load   PartitionLoad
+	now    func() time.Time
+	tables map[model.TableID]*adaptiveTable
+	topics map[string]*adaptiveTopic
+}
+
+// adaptiveTable is the partition mapping of a table, the table is dispatched to
+// `spread` partitions starting from `base`.
+type adaptiveTable struct {
+	topic        string
+	partitionNum int32
+	base         int32
+	spread       int32
+	// target is the spread which is applied at the next transaction.
+	target    int32
+	hasHandle bool
+	commitTs  uint64
+	// bytes is the approximate data size of the rows dispatched since the last check.
+	bytes float64
+}
+
+func (t *adaptiveTable) partitions() []int32 {
+	result := make([]int32, 0, t.spread)
+	for i := int32(0); i < t.spread; i++ {
+		result = append(result, (t.base+i)%t.partitionNum)
+	}
+	return result
+}
+
+type adaptiveTopic struct {
+	lastCheck time.Time
+	lastBytes []float64
+}
+
+// NewAdaptiveDispatcher creates an AdaptiveDispatcher.
+func NewAdaptiveDispatcher(topic func(schema, table string) string) *AdaptiveDispatcher {
+	return &AdaptiveDispatcher{
+		hasher: hash.NewPositionInertia(),
+		topic:  topic,
+		now:    time.Now,
+		tables: make(map[model.TableID]*adaptiveTable),
+		topics: make(map[string]*adaptiveTopic),
+	}
+}
+
+// SetPartitionLoad sets the source of the partition load, the tables are never
+// spread without it. It should be called before dispatching any event.
+func (d *AdaptiveDispatcher) SetPartitionLoad(load PartitionLoad) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.load = load
+}
+
+// DispatchRowChangedEvent returns the target partition to which
+// a row changed event should be dispatched.
+func (d *AdaptiveDispatcher) DispatchRowChangedEvent(row *model.RowChangedEvent, partitionNum int32) (int32, string, error) {
+	partition, key, _ := d.DispatchRowChangedEventWithMapping(row, partitionNum)
+	return partition, key, nil
+}
+
+// DispatchRowChangedEventWithMapping returns the target partition to which a row
+// changed event should be dispatched, and the mapping change of the table if the
+// row is the first one dispatched under a new mapping.
+// The rows of a table should be dispatched in the order of the commit ts.
+func (d *AdaptiveDispatcher) DispatchRowChangedEventWithMapping(
+	row *model.RowChangedEvent, partitionNum int32,
+) (int32, string, *MappingChange) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	schema, table := row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName()
+	d.hasher.Reset()
+	d.hasher.Write([]byte(schema), []byte(table))
+	base := int32(d.hasher.Sum32() % uint32(partitionNum))
+
+	dispatchCols := row.Columns
+	if len(row.Columns) == 0 {
+		dispatchCols = row.PreColumns
+	}
+	hasHandle := false
+	for _, col := range dispatchCols {
+		if col == nil {
+			continue
+		}
+		if row.TableInfo.ForceGetColumnFlagType(col.ColumnID).IsHandleKey() {
+			d.hasher.Write([]byte(row.TableInfo.ForceGetColumnName(col.ColumnID)),
+				[]byte(model.ColumnValueString(col.Value)))
+			hasHandle = true
+		}
+	}
+
+	tableID := row.GetTableID()
+	t, ok := d.tables[tableID]
+	if !ok {
+		t = &adaptiveTable{
+			topic:        d.topic(schema, table),
+			partitionNum: partitionNum,
+			base:         base,
+			spread:       1,
+			target:       1,
+			hasHandle:    hasHandle,
+			commitTs:     row.CommitTs,
+		}
+		d.tables[tableID] = t
+	}
+	t.hasHandle = hasHandle
+	if !hasHandle {
+		t.target = 1
+	}
+
+	var change *MappingChange
+	// The mapping is changed only between transactions, so all rows of a
+	// transaction are dispatched under the same mapping.
+	if row.CommitTs != t.commitTs {
+		d.checkLoad(t.topic, partitionNum)
+		if t.partitionNum != partitionNum || t.base != base || t.target != t.spread {
+			change = &MappingChange{
+				Schema:       schema,
+				Table:        table,
+				TableID:      tableID,
+				CommitTs:     row.CommitTs,
+				PartitionNum: partitionNum,
+				Previous:     t.partitions(),
+			}
+			t.partitionNum = partitionNum
+			t.base = base
+			t.spread = t.target
+			if t.spread > partitionNum {
+				t.spread = partitionNum
+				t.target = partitionNum
+			}
+			change.Current = t.partitions()
+			log.Info("adaptive dispatcher changes the partitions of the table",
+				zap.String("schema", schema),
+				zap.String("table", table),
+				zap.Int64("tableID", tableID),
+				zap.Uint64("commitTs", row.CommitTs),
+				zap.Int32s("previous", change.Previous),
+				zap.Int32s("current", change.Current))
+		}
+		t.commitTs = row.CommitTs
+	}
+	t.bytes += float64(row.ApproximateDataSize)
+
+	if t.spread <= 1 {
+		return t.base, row.TableInfo.TableName.String(), change
+	}
+	sum32 := d.hasher.Sum32()
+	return (t.base + int32(sum32%uint32(t.spread))) % partitionNum,
+		strconv.FormatInt(int64(sum32), 10), change
+}
+
+// checkLoad updates the target spread of the tables of the topic according to
+// the throughput of the partitions since the last check.
+func (d *AdaptiveDispatcher) checkLoad(topic string, partitionNum int32) {
+	if d.load == nil {
+		return
+	}
+	now := d.now()
+	state, ok := d.topics[topic]
+	if !ok {
+		state = &adaptiveTopic{}
+		d.topics[topic] = state
+	}
+	if now.Sub(state.lastCheck) < adaptiveCheckInterval {
+		return
+	}
+	written := d.load.PartitionWriteBytes(topic, partitionNum)
+	lastBytes := state.lastBytes
+	state.lastCheck = now
+	state.lastBytes = written
+
+	var average float64
+	throughput := make([]float64, len(written))
+	if len(lastBytes) == len(written) && len(written) != 0 {
+		var total float64
+		for i := range written {
+			throughput[i] = written[i] - lastBytes[i]
+			total += throughput[i]
+		}
+		average = total / float64(len(throughput))
+	}
+
+	for _, t := range d.tables {
+		if t.topic != topic || t.partitionNum != partitionNum {
+			continue
+		}
+		// the throughput of the first check is unknown.
+		if average <= 0 {
+			t.bytes = 0
+			continue
+		}
+		var busiest float64
+		for _, p := range t.partitions() {
+			if throughput[p] > busiest {
+				busiest = throughput[p]
+			}
+		}
+		switch {
+		case !t.hasHandle:
+			t.target = 1
+		case busiest > adaptiveHotRatio*average &&
+			t.bytes/float64(t.spread) >= adaptiveHotShare*busiest:
+			t.target = t.spread * 2
+			if t.target > partitionNum {
+				t.target = partitionNum
+			}
+		case t.spread > 1 && busiest < average:
+			t.target = t.spread / 2
+		default:
+			t.target = t.spread
+		}
+		t.bytes = 0
+	}
+}
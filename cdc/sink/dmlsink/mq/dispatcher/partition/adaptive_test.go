@@ -0,0 +1,146 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPartitionLoad struct {
+	written []float64
+}
+
+func (l *mockPartitionLoad) PartitionWriteBytes(_ string, _ int32) []float64 {
+	result := make([]float64, len(l.written))
+	copy(result, l.written)
+	return result
+}
+
+func newAdaptiveTestRow(
+	tableInfo *model.TableInfo, commitTs uint64, value int,
+) *model.RowChangedEvent {
+	return &model.RowChangedEvent{
+		TableInfo:           tableInfo,
+		PhysicalTableID:     tableInfo.ID,
+		CommitTs:            commitTs,
+		ApproximateDataSize: 3000,
+		Columns: model.Columns2ColumnDatas([]*model.Column{
+			{Name: "a", Value: value},
+			{Name: "b", Value: value},
+		}, tableInfo),
+	}
+}
+
+func TestAdaptiveDispatcher(t *testing.T) {
+	t.Parallel()
+
+	tableInfo := model.BuildTableInfo("test", "t1", []*model.Column{
+		{Name: "a", Flag: model.HandleKeyFlag | model.PrimaryKeyFlag},
+		{Name: "b"},
+	}, [][]int{{0}})
+	tableInfo.ID = 100
+
+	const partitionNum = 4
+	now := time.Now()
+	load := &mockPartitionLoad{written: make([]float64, partitionNum)}
+	d := NewAdaptiveDispatcher(func(_, _ string) string { return "topic" })
+	d.SetPartitionLoad(load)
+	d.now = func() time.Time { return now }
+
+	expected, _, err := NewTableDispatcher().DispatchRowChangedEvent(
+		newAdaptiveTestRow(tableInfo, 1, 1), partitionNum)
+	require.NoError(t, err)
+
+	// the first check records the written bytes only.
+	for commitTs := uint64(1); commitTs <= 2; commitTs++ {
+		partition, key, change := d.DispatchRowChangedEventWithMapping(
+			newAdaptiveTestRow(tableInfo, commitTs, 1), partitionNum)
+		require.Equal(t, expected, partition)
+		require.Equal(t, "test.t1", key)
+		require.Nil(t, change)
+	}
+
+	// the partition of the table is hot, the table is spread at the next transaction.
+	now = now.Add(adaptiveCheckInterval)
+	load.written[expected] = 4000
+	partition, _, change := d.DispatchRowChangedEventWithMapping(
+		newAdaptiveTestRow(tableInfo, 3, 1), partitionNum)
+	require.NotNil(t, change)
+	second := (expected + 1) % partitionNum
+	require.Equal(t, uint64(3), change.CommitTs)
+	require.Equal(t, []int32{expected}, change.Previous)
+	require.Equal(t, []int32{expected, second}, change.Current)
+	require.Contains(t, change.Current, partition)
+
+	// the rows with the same key are dispatched to the same partition.
+	dispatched := make(map[int32]struct{})
+	for value := 0; value < 100; value++ {
+		first, _, change := d.DispatchRowChangedEventWithMapping(
+			newAdaptiveTestRow(tableInfo, 3, value), partitionNum)
+		require.Nil(t, change)
+		again, _, err := d.DispatchRowChangedEvent(
+			newAdaptiveTestRow(tableInfo, 3, value), partitionNum)
+		require.NoError(t, err)
+		require.Equal(t, first, again)
+		dispatched[first] = struct{}{}
+	}
+	require.Equal(t, map[int32]struct{}{expected: {}, second: {}}, dispatched)
+
+	// the partitions of the table are cold, the table is shrunk.
+	now = now.Add(adaptiveCheckInterval)
+	load.written[(expected+2)%partitionNum] = 4000
+	partition, key, change := d.DispatchRowChangedEventWithMapping(
+		newAdaptiveTestRow(tableInfo, 4, 1), partitionNum)
+	require.Equal(t, expected, partition)
+	require.Equal(t, "test.t1", key)
+	require.NotNil(t, change)
+	require.Equal(t, []int32{expected, second}, change.Previous)
+	require.Equal(t, []int32{expected}, change.Current)
+	require.ElementsMatch(t, []int32{expected, second}, change.Partitions())
+}
+
+func TestAdaptiveDispatcherWithoutHandleKey(t *testing.T) {
+	t.Parallel()
+
+	tableInfo := model.BuildTableInfo("test", "t2", []*model.Column{
+		{Name: "a"},
+		{Name: "b"},
+	}, nil)
+	tableInfo.ID = 101
+
+	const partitionNum = 4
+	now := time.Now()
+	load := &mockPartitionLoad{written: make([]float64, partitionNum)}
+	d := NewAdaptiveDispatcher(func(_, _ string) string { return "topic" })
+	d.SetPartitionLoad(load)
+	d.now = func() time.Time { return now }
+
+	expected, _, err := NewTableDispatcher().DispatchRowChangedEvent(
+		newAdaptiveTestRow(tableInfo, 1, 1), partitionNum)
+	require.NoError(t, err)
+
+	// the table without the handle key is never spread to keep the order.
+	for commitTs := uint64(1); commitTs <= 5; commitTs++ {
+		now = now.Add(adaptiveCheckInterval)
+		load.written[expected] += 4000
+		partition, _, change := d.DispatchRowChangedEventWithMapping(
+			newAdaptiveTestRow(tableInfo, commitTs, int(commitTs)), partitionNum)
+		require.Equal(t, expected, partition)
+		require.Nil(t, change)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topic
+
+import (
+	"hash/crc32"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/errors"
+)
+
+const (
+	termSchema = "schema"
+	termTable  = "table"
+	// termColumnPrefix is the prefix of the column placeholder, such as `{column:tenant_id}`.
+	termColumnPrefix = "column:"
+	// termLower converts the value to lower case, such as `{lower(column:region)}`.
+	termLower = "lower"
+	// termHashMod hashes the value into [0, N), such as `{hash-mod(column:tenant_id,8)}`.
+	termHashMod = "hash-mod"
+
+	// maxHashModulus limits the topics generated by a single hash-mod function.
+	maxHashModulus = 1024
+)
+
+var (
+	// placeholderRE is used to match the placeholders in the topic expression.
+	placeholderRE = regexp.MustCompile(`\{([^{}]*)\}`)
+	// literalRE is used to match the literal parts of the topic expression.
+	literalRE = regexp.MustCompile(`^[A-Za-z0-9\._\-]*$`)
+	// columnNameRE is used to match the column name in the column placeholder.
+	columnNameRE = regexp.MustCompile(`^[A-Za-z0-9_\$]+$`)
+)
+
+// term is a placeholder in the topic expression.
+type term struct {
+	name string
+	// column is the column name if the term is a column placeholder.
+	column string
+	// arg is the argument of the function.
+	arg *term
+	// modulus is the modulus of the hash-mod function.
+	modulus uint32
+}
+
+func parseTerm(s string) (*term, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == termSchema || s == termTable:
+		return &term{name: s}, nil
+	case strings.HasPrefix(s, termColumnPrefix):
+		column := strings.TrimPrefix(s, termColumnPrefix)
+		if !columnNameRE.MatchString(column) {
+			return nil, errors.Errorf("invalid column name %s", column)
+		}
+		return &term{name: termColumnPrefix, column: column}, nil
+	case strings.HasPrefix(s, termLower+"(") && strings.HasSuffix(s, ")"):
+		arg, err := parseTerm(s[len(termLower)+1 : len(s)-1])
+		if err != nil {
+			return nil, err
+		}
+		return &term{name: termLower, arg: arg}, nil
+	case strings.HasPrefix(s, termHashMod+"(") && strings.HasSuffix(s, ")"):
+		args := s[len(termHashMod)+1 : len(s)-1]
+		idx := strings.LastIndex(args, ",")
+		if idx < 0 {
+			return nil, errors.Errorf("the modulus of %s is missing", termHashMod)
+		}
+		modulus, err := strconv.ParseUint(strings.TrimSpace(args[idx+1:]), 10, 32)
+		if err != nil || modulus == 0 || modulus > maxHashModulus {
+			return nil, errors.Errorf("the modulus of %s should be in [1, %d]", termHashMod, maxHashModulus)
+		}
+		arg, err := parseTerm(args[:idx])
+		if err != nil {
+			return nil, err
+		}
+		return &term{name: termHashMod, arg: arg, modulus: uint32(modulus)}, nil
+	default:
+		return nil, errors.Errorf("unknown placeholder {%s}", s)
+	}
+}
+
+// eval returns the value of the term, it returns false if the column is not found.
+func (t *term) eval(schema, table string, row *model.RowChangedEvent) (string, bool) {
+	switch t.name {
+	case termSchema:
+		return schema, true
+	case termTable:
+		return table, true
+	case termColumnPrefix:
+		return columnValue(row, t.column)
+	case termLower:
+		value, ok := t.arg.eval(schema, table, row)
+		return strings.ToLower(value), ok
+	default:
+		value, ok := t.arg.eval(schema, table, row)
+		if !ok {
+			return "", false
+		}
+		return strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(value))%t.modulus), 10), true
+	}
+}
+
+// values returns all possible values of the term, it returns false if the values
+// are unbounded, which means the term refers to a column without hash-mod.
+func (t *term) values(schema, table string) ([]string, bool) {
+	switch t.name {
+	case termSchema:
+		return []string{schema}, true
+	case termTable:
+		return []string{table}, true
+	case termColumnPrefix:
+		return nil, false
+	case termLower:
+		values, ok := t.arg.values(schema, table)
+		for i := range values {
+			values[i] = strings.ToLower(values[i])
+		}
+		return values, ok
+	default:
+		values := make([]string, 0, t.modulus)
+		for i := uint32(0); i < t.modulus; i++ {
+			values = append(values, strconv.FormatUint(uint64(i), 10))
+		}
+		return values, true
+	}
+}
+
+// columns returns the columns referred by the term.
+func (t *term) columns() []string {
+	if t.name == termColumnPrefix {
+		return []string{t.column}
+	}
+	if t.arg != nil {
+		return t.arg.columns()
+	}
+	return nil
+}
+
+// columnValue returns the string value of the column of the row, the pre-value
+// is used for the delete event. It returns false if the column is not found.
+func columnValue(row *model.RowChangedEvent, name string) (string, bool) {
+	if row == nil {
+		return "", false
+	}
+	columns := row.Columns
+	if len(columns) == 0 {
+		columns = row.PreColumns
+	}
+	offsets, ok := row.TableInfo.OffsetsByNames([]string{name})
+	if !ok || offsets[0] >= len(columns) || columns[offsets[0]] == nil {
+		return "", false
+	}
+	value := columns[offsets[0]].Value
+	if value == nil {
+		return "", false
+	}
+	return model.ColumnValueString(value), true
+}
+
+// compiledExpression is a topic expression split into the literals and the placeholders,
+// the literals[i] is ahead of the terms[i], and the last literal is the suffix.
+type compiledExpression struct {
+	literals []string
+	terms    []*term
+}
+
+func compileExpression(e Expression) (*compiledExpression, error) {
+	expr := string(e)
+	result := &compiledExpression{}
+	last := 0
+	for _, loc := range placeholderRE.FindAllStringSubmatchIndex(expr, -1) {
+		result.literals = append(result.literals, expr[last:loc[0]])
+		t, err := parseTerm(expr[loc[2]:loc[3]])
+		if err != nil {
+			return nil, errors.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(e, err.Error())
+		}
+		result.terms = append(result.terms, t)
+		last = loc[1]
+	}
+	result.literals = append(result.literals, expr[last:])
+	for _, literal := range result.literals {
+		if !literalRE.MatchString(literal) {
+			return nil, errors.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(e,
+				"the literal parts should match [A-Za-z0-9._-]")
+		}
+	}
+	return result, nil
+}
+
+// eval returns the topic of the row, it returns false if any column is not found.
+func (c *compiledExpression) eval(schema, table string, row *model.RowChangedEvent) (string, bool) {
+	var b strings.Builder
+	for i, t := range c.terms {
+		b.WriteString(c.literals[i])
+		value, ok := t.eval(schema, table, row)
+		if !ok {
+			return "", false
+		}
+		b.WriteString(kafkaForbidRE.ReplaceAllString(value, "_"))
+	}
+	b.WriteString(c.literals[len(c.literals)-1])
+	return normalizeTopicName(b.String()), true
+}
+
+// count returns the number of the possible topics of a table, it returns false if
+// the topics are unbounded, or the number exceeds the limit.
+func (c *compiledExpression) count(limit int) (int, bool) {
+	count := 1
+	for _, t := range c.terms {
+		values, ok := t.values("", "")
+		if !ok {
+			return 0, false
+		}
+		count *= len(values)
+		if count > limit {
+			return count, false
+		}
+	}
+	return count, true
+}
+
+// topics returns all possible topics of the table, it returns false if the
+// topics are unbounded.
+func (c *compiledExpression) topics(schema, table string) ([]string, bool) {
+	topics := []string{""}
+	for i, t := range c.terms {
+		values, ok := t.values(schema, table)
+		if !ok {
+			return nil, false
+		}
+		next := make([]string, 0, len(topics)*len(values))
+		for _, prefix := range topics {
+			for _, value := range values {
+				next = append(next, prefix+c.literals[i]+kafkaForbidRE.ReplaceAllString(value, "_"))
+			}
+		}
+		topics = next
+	}
+	for i := range topics {
+		topics[i] = normalizeTopicName(topics[i] + c.literals[len(c.literals)-1])
+	}
+	return topics, true
+}
+
+// ColumnTopicDispatcher is a topic dispatcher which dispatches rows to the topics
+// generated from the column values. The topics are bounded by the allowed topics,
+// or the modulus of the hash-mod functions, the rows are dispatched to the overflow
+// topic if the generated topic is not allowed.
+type ColumnTopicDispatcher struct {
+	expression    Expression
+	compiled      *compiledExpression
+	allowedTopics []string
+	overflowTopic string
+}
+
+// NewColumnTopicDispatcher creates a ColumnTopicDispatcher, the number of the topics
+// generated by the expression for a table should not exceed the maxTopics.
+func NewColumnTopicDispatcher(
+	topicExpr Expression, allowedTopics []string, overflowTopic string, maxTopics int,
+) (*ColumnTopicDispatcher, error) {
+	compiled, err := compileExpression(topicExpr)
+	if err != nil {
+		return nil, err
+	}
+	if !IsHardCode(overflowTopic) {
+		return nil, errors.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(overflowTopic,
+			"overflow-topic must be a fixed topic name")
+	}
+	d := &ColumnTopicDispatcher{
+		expression:    topicExpr,
+		compiled:      compiled,
+		overflowTopic: overflowTopic,
+	}
+	if len(allowedTopics) > 0 {
+		if len(allowedTopics) > maxTopics {
+			return nil, errors.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(topicExpr,
+				"the number of the allowed-topics exceeds max-topics "+strconv.Itoa(maxTopics))
+		}
+		for _, topic := range allowedTopics {
+			if !IsHardCode(topic) {
+				return nil, errors.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(topic,
+					"allowed-topics must be fixed topic names")
+			}
+		}
+		d.allowedTopics = allowedTopics
+		return d, nil
+	}
+	// The schema and table names do not affect the number of the topics.
+	count, ok := compiled.count(maxTopics)
+	if count == 0 && !ok {
+		return nil, errors.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(topicExpr,
+			"allowed-topics must be set if a column is not wrapped by hash-mod")
+	}
+	if !ok {
+		return nil, errors.ErrKafkaInvalidTopicExpression.GenWithStackByArgs(topicExpr,
+			"the number of the topics exceeds max-topics "+strconv.Itoa(maxTopics))
+	}
+	return d, nil
+}
+
+// Substitute returns the overflow topic, since no row is provided.
+func (d *ColumnTopicDispatcher) Substitute(schema, table string) string {
+	return d.overflowTopic
+}
+
+// DispatchRowChangedEvent returns the target topic of the row.
+func (d *ColumnTopicDispatcher) DispatchRowChangedEvent(row *model.RowChangedEvent) string {
+	topic, ok := d.compiled.eval(row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName(), row)
+	if !ok {
+		return d.overflowTopic
+	}
+	if d.allowedTopics != nil && !slices.Contains(d.allowedTopics, topic) {
+		return d.overflowTopic
+	}
+	return topic
+}
+
+// IsTopicChanged returns true if the row is an update whose pre-image
+// and post-image are dispatched to different topics.
+func (d *ColumnTopicDispatcher) IsTopicChanged(row *model.RowChangedEvent) bool {
+	if !row.IsUpdate() {
+		return false
+	}
+	preImage := *row
+	preImage.Columns = nil
+	return d.DispatchRowChangedEvent(&preImage) != d.DispatchRowChangedEvent(row)
+}
+
+// Topics returns all topics the rows of the table may be dispatched to,
+// including the overflow topic.
+func (d *ColumnTopicDispatcher) Topics(schema, table string) []string {
+	topics := slices.Clone(d.allowedTopics)
+	if topics == nil {
+		topics, _ = d.compiled.topics(schema, table)
+	}
+	if !slices.Contains(topics, d.overflowTopic) {
+		topics = append(topics, d.overflowTopic)
+	}
+	return topics
+}
+
+// Columns returns the columns referred by the expression.
+func (d *ColumnTopicDispatcher) Columns() []string {
+	var columns []string
+	for _, t := range d.compiled.terms {
+		columns = append(columns, t.columns()...)
+	}
+	return columns
+}
+
+func (d *ColumnTopicDispatcher) String() string {
+	return string(d.expression)
+}
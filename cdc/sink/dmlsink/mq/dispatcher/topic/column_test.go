@@ -0,0 +1,108 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topic
+
+import (
+	"testing"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsColumnExpression(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, Expression("hello").IsColumnExpression())
+	require.False(t, Expression("{schema}_{table}").IsColumnExpression())
+	require.True(t, Expression("{schema}_{column:a}").IsColumnExpression())
+	require.True(t, Expression("{lower(table)}").IsColumnExpression())
+	require.True(t, Expression("t_{hash-mod(column:a,4)}").IsColumnExpression())
+}
+
+func TestCompileExpression(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		expression string
+		wantErr    string
+	}{
+		{expression: "{schema}_{column:tenant_id}"},
+		{expression: "{lower(column:Region)}-{hash-mod(lower(column:a), 16)}"},
+		{expression: "t_{hash-mod(column:a)}", wantErr: "the modulus of hash-mod is missing"},
+		{expression: "t_{hash-mod(column:a,0)}", wantErr: "should be in [1, 1024]"},
+		{expression: "t_{hash-mod(column:a,1025)}", wantErr: "should be in [1, 1024]"},
+		{expression: "t_{column:a b}", wantErr: "invalid column name"},
+		{expression: "t_{upper(column:a)}", wantErr: "unknown placeholder"},
+		{expression: "t/{column:a}", wantErr: "the literal parts should match"},
+	}
+	for _, c := range cases {
+		_, err := compileExpression(Expression(c.expression))
+		if c.wantErr == "" {
+			require.NoError(t, err, c.expression)
+		} else {
+			require.ErrorContains(t, err, c.wantErr, c.expression)
+		}
+	}
+}
+
+func TestColumnTopicDispatcher(t *testing.T) {
+	t.Parallel()
+
+	cols := []*model.Column{
+		{Name: "id", Value: 1, Flag: model.HandleKeyFlag | model.PrimaryKeyFlag},
+		{Name: "region", Value: []byte("US East")},
+	}
+	tableInfo := model.BuildTableInfo("db", "t", cols, [][]int{{0}})
+	row := &model.RowChangedEvent{
+		TableInfo: tableInfo,
+		Columns:   model.Columns2ColumnDatas(cols, tableInfo),
+	}
+
+	// the forbidden characters in the column value are replaced by '_'.
+	d, err := NewColumnTopicDispatcher("{schema}_{lower(column:region)}", []string{"db_us_east"}, "overflow", 8)
+	require.NoError(t, err)
+	require.Equal(t, "db_us_east", d.DispatchRowChangedEvent(row))
+	require.Equal(t, "overflow", d.Substitute("db", "t"))
+	require.Equal(t, []string{"db_us_east", "overflow"}, d.Topics("db", "t"))
+	require.Equal(t, []string{"region"}, d.Columns())
+
+	// the pre-columns are used for the delete event.
+	deleteRow := &model.RowChangedEvent{TableInfo: tableInfo, PreColumns: row.Columns}
+	require.Equal(t, "db_us_east", d.DispatchRowChangedEvent(deleteRow))
+	require.False(t, d.IsTopicChanged(deleteRow))
+
+	// the topic of an update is changed if the routing column is changed.
+	movedCols := []*model.Column{cols[0], {Name: "region", Value: []byte("EU West")}}
+	updateRow := &model.RowChangedEvent{
+		TableInfo:  tableInfo,
+		PreColumns: row.Columns,
+		Columns:    model.Columns2ColumnDatas(movedCols, tableInfo),
+	}
+	require.Equal(t, "overflow", d.DispatchRowChangedEvent(updateRow))
+	require.True(t, d.IsTopicChanged(updateRow))
+	updateRow.Columns = row.Columns
+	require.False(t, d.IsTopicChanged(updateRow))
+
+	d, err = NewColumnTopicDispatcher("{table}_{hash-mod(column:region,4)}", nil, "overflow", 8)
+	require.NoError(t, err)
+	require.Equal(t, []string{"t_0", "t_1", "t_2", "t_3", "overflow"}, d.Topics("db", "t"))
+	require.Contains(t, d.Topics("db", "t"), d.DispatchRowChangedEvent(row))
+
+	_, err = NewColumnTopicDispatcher("{hash-mod(column:a,4)}_{hash-mod(column:b,4)}", nil, "overflow", 8)
+	require.ErrorContains(t, err, "exceeds max-topics 8")
+	_, err = NewColumnTopicDispatcher("{column:a}", []string{"a", "{schema}"}, "overflow", 8)
+	require.ErrorContains(t, err, "allowed-topics must be fixed topic names")
+	_, err = NewColumnTopicDispatcher("{column:a}", []string{"a"}, "{schema}", 8)
+	require.ErrorContains(t, err, "overflow-topic must be a fixed topic name")
+}
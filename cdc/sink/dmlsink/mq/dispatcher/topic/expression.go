@@ -94,7 +94,23 @@ func (e Expression) Substitute(schema, table string) string {
 	// doing the real conversion things
 	topicName := schemaRE.ReplaceAllString(topicExpr, replacedSchema)
 	topicName = tableRE.ReplaceAllString(topicName, replacedTable)
+	return normalizeTopicName(topicName)
+}
+
+// IsColumnExpression checks whether the expression contains a column placeholder
+// or a function, such as `{column:tenant_id}` or `{hash-mod(column:tenant_id,8)}`,
+// which is evaluated for each row.
+func (e Expression) IsColumnExpression() bool {
+	for _, match := range placeholderRE.FindAllStringSubmatch(string(e), -1) {
+		if match[1] != termSchema && match[1] != termTable {
+			return true
+		}
+	}
+	return false
+}
 
+// normalizeTopicName makes the topic name valid for kafka.
+func normalizeTopicName(topicName string) string {
 	// topicName will be truncated if it exceed the limit.
 	// And topicName '.' and '..' are also invalid, replace them with '_'.
 	//    See https://github.com/apache/kafka/blob/trunk/clients/src/main/java/org/apache/kafka/common/internals/Topic.java#L46
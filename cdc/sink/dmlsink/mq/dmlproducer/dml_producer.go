@@ -34,6 +34,19 @@ type DMLProducer interface {
 	Close()
 }
 
+// TransactionalDMLProducer is the DMLProducer which sends messages in transactions.
+// The callbacks of the messages are not called until the transaction is committed.
+type TransactionalDMLProducer interface {
+	DMLProducer
+
+	// BeginTxn begins a new transaction.
+	BeginTxn() error
+	// CommitTxn flushes all messages sent in the transaction and commits it.
+	CommitTxn() error
+	// AbortTxn aborts the transaction.
+	AbortTxn() error
+}
+
 // Factory is a function to create a producer.
 // errCh is used to report error to the caller(i.e. processor,owner).
 // Because the caller passes errCh to many goroutines,
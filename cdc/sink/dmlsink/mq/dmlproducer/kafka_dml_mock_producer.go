@@ -18,17 +18,24 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/pingcap/errors"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"github.com/pingcap/tiflow/pkg/sink/kafka"
 )
 
-var _ DMLProducer = (*MockDMLProducer)(nil)
+var _ TransactionalDMLProducer = (*MockDMLProducer)(nil)
 
 // MockDMLProducer is a mock producer for test.
 type MockDMLProducer struct {
 	mu     sync.Mutex
 	events map[string][]*common.Message
+	// inTxn indicates whether a transaction is began, the events sent in the
+	// transaction are kept in txnEvents until the transaction is committed.
+	inTxn     bool
+	txnEvents map[string][]*common.Message
+	// Commits is the number of committed transactions.
+	Commits int
 
 	asyncProducer kafka.AsyncProducer
 }
@@ -53,13 +60,65 @@ func (m *MockDMLProducer) AsyncSendMessage(_ context.Context, topic string,
 	defer m.mu.Unlock()
 
 	key := fmt.Sprintf("%s-%d", topic, partition)
+	if m.inTxn {
+		m.txnEvents[key] = append(m.txnEvents[key], message)
+		return nil
+	}
 	if _, ok := m.events[key]; !ok {
 		m.events[key] = make([]*common.Message, 0)
 	}
 	m.events[key] = append(m.events[key], message)
 
-	message.Callback()
+	if message.Callback != nil {
+		message.Callback()
+	}
+
+	return nil
+}
+
+// BeginTxn begins a transaction.
+func (m *MockDMLProducer) BeginTxn() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inTxn {
+		return errors.New("transaction already began")
+	}
+	m.inTxn = true
+	m.txnEvents = make(map[string][]*common.Message)
+	return nil
+}
 
+// CommitTxn makes the events sent in the transaction visible, and calls their callbacks.
+func (m *MockDMLProducer) CommitTxn() error {
+	m.mu.Lock()
+	if !m.inTxn {
+		m.mu.Unlock()
+		return errors.New("transaction not began")
+	}
+	var committed []*common.Message
+	for key, events := range m.txnEvents {
+		m.events[key] = append(m.events[key], events...)
+		committed = append(committed, events...)
+	}
+	m.inTxn = false
+	m.txnEvents = nil
+	m.Commits++
+	m.mu.Unlock()
+
+	for _, message := range committed {
+		if message.Callback != nil {
+			message.Callback()
+		}
+	}
+	return nil
+}
+
+// AbortTxn drops the events sent in the transaction.
+func (m *MockDMLProducer) AbortTxn() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inTxn = false
+	m.txnEvents = nil
 	return nil
 }
 
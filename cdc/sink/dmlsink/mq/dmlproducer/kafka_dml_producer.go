@@ -27,7 +27,7 @@ import (
 	"go.uber.org/zap"
 )
 
-var _ DMLProducer = (*kafkaDMLProducer)(nil)
+var _ TransactionalDMLProducer = (*kafkaDMLProducer)(nil)
 
 // kafkaDMLProducer is used to send messages to kafka.
 type kafkaDMLProducer struct {
@@ -122,6 +122,47 @@ func (k *kafkaDMLProducer) AsyncSendMessage(
 	return k.asyncProducer.AsyncSend(ctx, topic, partition, message)
 }
 
+// BeginTxn begins a new transaction.
+func (k *kafkaDMLProducer) BeginTxn() error {
+	p, err := k.transactionalProducer()
+	if err != nil {
+		return err
+	}
+	return p.BeginTxn()
+}
+
+// CommitTxn flushes all messages sent in the transaction and commits it.
+func (k *kafkaDMLProducer) CommitTxn() error {
+	p, err := k.transactionalProducer()
+	if err != nil {
+		return err
+	}
+	return p.CommitTxn()
+}
+
+// AbortTxn aborts the transaction.
+func (k *kafkaDMLProducer) AbortTxn() error {
+	p, err := k.transactionalProducer()
+	if err != nil {
+		return err
+	}
+	return p.AbortTxn()
+}
+
+func (k *kafkaDMLProducer) transactionalProducer() (kafka.TransactionalProducer, error) {
+	k.closedMu.RLock()
+	defer k.closedMu.RUnlock()
+	if k.closed {
+		return nil, cerror.ErrKafkaProducerClosed.GenWithStackByArgs()
+	}
+	p, ok := k.asyncProducer.(kafka.TransactionalProducer)
+	if !ok {
+		return nil, cerror.ErrKafkaTransaction.GenWithStack(
+			"the kafka async producer does not support transactions")
+	}
+	return p, nil
+}
+
 func (k *kafkaDMLProducer) Close() {
 	// We have to hold the lock to synchronize closing with writing.
 	k.closedMu.Lock()
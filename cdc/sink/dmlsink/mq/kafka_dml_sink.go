@@ -22,7 +22,10 @@ import (
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dmlproducer"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columnselector"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columntransformer"
+	"github.com/pingcap/tiflow/cdc/sink/metrics/mq"
 	"github.com/pingcap/tiflow/cdc/sink/util"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
@@ -53,6 +56,9 @@ func NewKafkaDMLSink(
 	if err := options.Apply(changefeedID, sinkURI, replicaConfig); err != nil {
 		return nil, cerror.WrapError(cerror.ErrKafkaInvalidConfig, err)
 	}
+	if options.ExactlyOnce && options.TransactionMarkerTopic == "" {
+		options.TransactionMarkerTopic = kafka.DefaultTxnMarkerTopic(topic)
+	}
 
 	factory, err := factoryCreator(options, changefeedID)
 	if err != nil {
@@ -97,11 +103,19 @@ func NewKafkaDMLSink(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	eventRouter.SetPartitionLoad(mq.NewPartitionLoad(changefeedID))
 
-	trans, err := columnselector.New(replicaConfig)
+	selector, err := columnselector.New(replicaConfig)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	columnTransformer, err := columntransformer.New(replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The column selector is applied first, so the filtered out columns
+	// are not transformed.
+	trans := transformer.Chain{selector, columnTransformer}
 
 	encoderConfig, err := util.GetEncoderConfig(changefeedID, sinkURI, protocol, replicaConfig, options.MaxMessageBytes)
 	if err != nil {
@@ -112,18 +126,68 @@ func NewKafkaDMLSink(
 	if err != nil {
 		return nil, cerror.WrapError(cerror.ErrKafkaNewProducer, err)
 	}
+	controlEncoder, err := newControlEncoder(eventRouter, encoderBuilder)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	// The bootstrap messages may be sent to the DDL topics.
+	if options.AutoCreate {
+		for _, ddlTopic := range eventRouter.GetDDLTopics() {
+			err = adminClient.CreateTopic(ctx,
+				kafka.CompactedTopicConfig(ddlTopic, options.ReplicationFactor), false)
+			if err != nil {
+				return nil, cerror.WrapError(cerror.ErrKafkaCreateTopic, err)
+			}
+		}
+	}
+
+	var markerReader kafka.TxnMarkerReader
+	if options.ExactlyOnce {
+		if options.AutoCreate {
+			err = adminClient.CreateTopic(ctx,
+				kafka.TxnMarkerTopicConfig(options.TransactionMarkerTopic, options.ReplicationFactor), false)
+			if err != nil {
+				return nil, cerror.WrapError(cerror.ErrKafkaCreateTopic, err)
+			}
+		}
+		markerReader, err = factory.TxnMarkerReader(ctx)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrKafkaNewProducer, err)
+		}
+		log.Info("Kafka DML sink exactly-once enabled",
+			zap.String("namespace", changefeedID.Namespace),
+			zap.String("changefeedID", changefeedID.ID),
+			zap.String("transactionalID", options.TransactionalID),
+			zap.String("markerTopic", options.TransactionMarkerTopic))
+	}
 
 	failpointCh := make(chan error, 1)
 	asyncProducer, err := factory.AsyncProducer(ctx, failpointCh)
 	if err != nil {
+		if markerReader != nil {
+			markerReader.Close()
+		}
 		return nil, cerror.WrapError(cerror.ErrKafkaNewProducer, err)
 	}
 
 	metricsCollector := factory.MetricsCollector(tiflowutil.RoleProcessor, adminClient)
 	dmlProducer := producerCreator(ctx, changefeedID, asyncProducer, metricsCollector, errCh, failpointCh)
-	encoderGroup := codec.NewEncoderGroup(replicaConfig.Sink, encoderBuilder, changefeedID)
+	var txn *txnCoordinator
+	if markerReader != nil {
+		txnProducer, ok := dmlProducer.(dmlproducer.TransactionalDMLProducer)
+		if !ok {
+			markerReader.Close()
+			dmlProducer.Close()
+			return nil, cerror.ErrKafkaTransaction.GenWithStack(
+				"the kafka DML producer does not support transactions")
+		}
+		txn = newTxnCoordinator(changefeedID, txnProducer, markerReader, options.TransactionMarkerTopic)
+	}
+	encoderGroup := codec.NewEncoderGroup(replicaConfig.Sink, encoderBuilder, changefeedID).
+		WithDDLTopic(eventRouter.GetDDLTopic)
 	s := newDMLSink(ctx, changefeedID, dmlProducer, adminClient, topicManager, eventRouter, trans, encoderGroup,
-		protocol, scheme, replicaConfig.Sink.KafkaConfig.GetOutputRawChangeEvent(), errCh)
+		controlEncoder, txn, protocol, scheme, replicaConfig.Sink.KafkaConfig.GetOutputRawChangeEvent(), errCh)
 	log.Info("DML sink producer created",
 		zap.String("namespace", changefeedID.Namespace),
 		zap.String("changefeedID", changefeedID.ID))
@@ -0,0 +1,77 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"encoding/json"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher/partition"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+)
+
+// PartitionMappingMarkerKey is the key of the partition mapping change marker,
+// the marker is a control message of the protocol whose value is the JSON
+// encoded partition.MappingChange. Consumers which don't rebuild the order of
+// the rows by the markers must skip the messages with this key.
+const PartitionMappingMarkerKey = "ticdc-partition-mapping"
+
+// newControlEncoder returns the encoder of the control messages if the event
+// router sends any, it fails if the encoder can't carry them.
+func newControlEncoder(
+	eventRouter *dispatcher.EventRouter, builder codec.RowEventEncoderBuilder,
+) (codec.ControlMessageEncoder, error) {
+	if !eventRouter.HasAdaptiveDispatcher() {
+		return nil, nil
+	}
+	encoder, ok := builder.Build().(codec.ControlMessageEncoder)
+	if !ok {
+		return nil, cerror.ErrDispatcherFailed.GenWithStack(
+			"the adaptive partition dispatcher is only supported by the json encoding format")
+	}
+	return encoder, nil
+}
+
+// newPartitionMappingMarkers returns the markers of the partition mapping change,
+// one for each partition involved in the change. They should be sent before the
+// first row dispatched under the new mapping.
+func newPartitionMappingMarkers(
+	encoder codec.ControlMessageEncoder, topic string, change *partition.MappingChange,
+) ([]mqEvent, error) {
+	value, err := json.Marshal(change)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrMarshalFailed, err)
+	}
+	partitions := change.Partitions()
+	events := make([]mqEvent, 0, len(partitions))
+	for _, p := range partitions {
+		marker, err := encoder.EncodeControlMessage(PartitionMappingMarkerKey, value, change.CommitTs)
+		if err != nil {
+			return nil, cerror.Trace(err)
+		}
+		marker.Schema, marker.Table = &change.Schema, &change.Table
+		events = append(events, mqEvent{
+			key: model.TopicPartitionKey{
+				Topic:          topic,
+				Partition:      p,
+				PartitionKey:   PartitionMappingMarkerKey,
+				TotalPartition: change.PartitionNum,
+			},
+			marker: marker,
+		})
+	}
+	return events, nil
+}
@@ -15,6 +15,7 @@ package mq
 
 import (
 	"context"
+	"slices"
 	"sync"
 
 	"github.com/pingcap/errors"
@@ -70,6 +71,11 @@ type dmlSink struct {
 
 	scheme               string
 	outputRawChangeEvent bool
+	// exactlyOnce indicates the rows are produced in kafka transactions.
+	exactlyOnce bool
+	// controlEncoder encodes the partition mapping change markers,
+	// it's nil if no table is dispatched by the adaptive dispatcher.
+	controlEncoder codec.ControlMessageEncoder
 }
 
 func newDMLSink(
@@ -81,13 +87,15 @@ func newDMLSink(
 	eventRouter *dispatcher.EventRouter,
 	transformer transformer.Transformer,
 	encoderGroup codec.EncoderGroup,
+	controlEncoder codec.ControlMessageEncoder,
+	txn *txnCoordinator,
 	protocol config.Protocol,
 	scheme string,
 	outputRawChangeEvent bool,
 	errCh chan error,
 ) *dmlSink {
 	ctx, cancel := context.WithCancelCause(ctx)
-	worker := newWorker(changefeedID, protocol, producer, encoderGroup)
+	worker := newWorker(changefeedID, protocol, producer, encoderGroup, txn)
 
 	s := &dmlSink{
 		id:                   changefeedID,
@@ -98,6 +106,8 @@ func newDMLSink(
 		dead:                 make(chan struct{}),
 		scheme:               scheme,
 		outputRawChangeEvent: outputRawChangeEvent,
+		exactlyOnce:          txn != nil,
+		controlEncoder:       controlEncoder,
 	}
 	s.alive.transformer = transformer
 	s.alive.eventRouter = eventRouter
@@ -158,6 +168,9 @@ func (s *dmlSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTa
 		}
 	}
 
+	// The rows of the tables are resolved once they are all written, the worker
+	// commits the rows of a table in a kafka transaction only if they are resolved.
+	var resolvedTables []model.TableID
 	for _, txn := range txns {
 		if txn.GetTableSinkState() != state.TableSinkSinking {
 			// The table where the event comes from is in stopping, so it's safe
@@ -165,8 +178,15 @@ func (s *dmlSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTa
 			txn.Callback()
 			continue
 		}
-		rowCallback := toRowCallback(txn.Callback, uint64(len(txn.Event.Rows)))
-		for _, row := range txn.Event.Rows {
+		// The updates which move the rows to another topic are split, otherwise
+		// the consumers of the old topic never see the rows are moved out.
+		rows, err := s.alive.eventRouter.SplitRowChangesByTopic(txn.Event.Rows)
+		if err != nil {
+			s.cancel(err)
+			return errors.Trace(err)
+		}
+		rowCallback := toRowCallback(txn.Callback, uint64(len(rows)))
+		for _, row := range rows {
 			topic := s.alive.eventRouter.GetTopicForRowChange(row)
 			partitionNum, err := s.alive.topicManager.GetPartitionNum(s.ctx, topic)
 			failpoint.Inject("MQSinkGetPartitionError", func() {
@@ -185,11 +205,30 @@ func (s *dmlSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTa
 			}
 			// Note: Calculate the partition index after the transformer is applied.
 			// Because the transformer may change the row of the event.
-			index, key, err := s.alive.eventRouter.GetPartitionForRowChange(row, partitionNum)
+			index, key, change, err := s.alive.eventRouter.GetPartitionForRowChangeWithMapping(row, partitionNum)
 			if err != nil {
 				s.cancel(err)
 				return errors.Trace(err)
 			}
+			// The markers of the mapping change are sent before the row,
+			// so consumers can rebuild the order of the rows.
+			if change != nil {
+				markers, err := newPartitionMappingMarkers(s.controlEncoder, topic, change)
+				if err != nil {
+					s.cancel(err)
+					return errors.Trace(err)
+				}
+				for _, marker := range markers {
+					s.alive.worker.msgChan.In() <- marker
+				}
+			}
+
+			if s.alive.worker.txn != nil {
+				tableID := row.GetTableID()
+				if !slices.Contains(resolvedTables, tableID) {
+					resolvedTables = append(resolvedTables, tableID)
+				}
+			}
 
 			// This never be blocked because this is an unbounded channel.
 			// We already limit the memory usage by MemoryQuota at SinkManager level.
@@ -209,6 +248,9 @@ func (s *dmlSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTa
 			}
 		}
 	}
+	for i := range resolvedTables {
+		s.alive.worker.msgChan.In() <- mqEvent{resolvedTable: &resolvedTables[i]}
+	}
 	return nil
 }
 
@@ -235,6 +277,23 @@ func (s *dmlSink) Dead() <-chan struct{} {
 	return s.dead
 }
 
+// SlowPartitions implements dmlsink.SlowPartitionReporter.
+func (s *dmlSink) SlowPartitions() []model.SlowPartition {
+	s.alive.RLock()
+	defer s.alive.RUnlock()
+	if s.alive.isDead {
+		return nil
+	}
+	return s.alive.worker.tracker.slowPartitions()
+}
+
+// RequireAtomicTxn implements dmlsink.AtomicTxnSink. The exactly-once sink
+// commits the rows of a table with the same commit ts in one kafka transaction,
+// so the transactions can't be split.
+func (s *dmlSink) RequireAtomicTxn() bool {
+	return s.exactlyOnce
+}
+
 // Scheme returns the scheme of this sink.
 func (s *dmlSink) SchemeOption() (string, bool) {
 	return s.scheme, s.outputRawChangeEvent
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/sink/kafka"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 )
 
 func TestNewKafkaDMLSinkFailed(t *testing.T) {
@@ -112,3 +113,62 @@ func TestWriteEvents(t *testing.T) {
 	require.Len(t, errCh, 0)
 	require.Len(t, s.alive.worker.producer.(*dmlproducer.MockDMLProducer).GetAllEvents(), 3000)
 }
+
+func TestWriteEventsExactlyOnce(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uriTemplate := "kafka://%s/%s?kafka-version=2.4.0&max-message-bytes=1048576&partition-num=1" +
+		"&kafka-client-id=unit-test&auto-create-topic=false&protocol=canal-json&exactly-once=true"
+	uri := fmt.Sprintf(uriTemplate, "127.0.0.1:9092", kafka.DefaultMockTopicName)
+
+	sinkURI, err := url.Parse(uri)
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	require.NoError(t, replicaConfig.ValidateAndAdjust(sinkURI))
+	errCh := make(chan error, 1)
+
+	ctx = context.WithValue(ctx, "testing.T", t)
+	changefeedID := model.DefaultChangeFeedID("test")
+	s, err := NewKafkaDMLSink(ctx, changefeedID, sinkURI, replicaConfig, errCh,
+		kafka.NewMockFactory, dmlproducer.NewDMLMockProducer)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	defer s.Close()
+	require.NotNil(t, s.alive.worker.txn)
+	require.Equal(t, kafka.DefaultTxnMarkerTopic(kafka.DefaultMockTopicName), s.alive.worker.txn.markerTopic)
+
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	job := helper.DDL2Job(`create table test.t(a varchar(255) primary key)`)
+	tableInfo := model.WrapTableInfo(0, "test", 1, job.BinlogInfo.TableInfo)
+
+	tableStatus := state.TableSinkSinking
+	var called atomic.Int64
+	events := make([]*dmlsink.CallbackableEvent[*model.SingleTableTxn], 0, 100)
+	for i := 0; i < 100; i++ {
+		events = append(events, &dmlsink.TxnCallbackableEvent{
+			Event: &model.SingleTableTxn{
+				Rows: []*model.RowChangedEvent{{
+					CommitTs:        uint64(i + 1),
+					PhysicalTableID: tableInfo.ID,
+					TableInfo:       tableInfo,
+					Columns: model.Columns2ColumnDatas(
+						[]*model.Column{{Name: "a", Value: "aa"}}, tableInfo),
+				}},
+			},
+			Callback:  func() { called.Add(1) },
+			SinkState: &tableStatus,
+		})
+	}
+	require.NoError(t, s.WriteEvents(events...))
+
+	producer := s.alive.worker.producer.(*dmlproducer.MockDMLProducer)
+	require.Eventually(t, func() bool {
+		return called.Load() == 100
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, errCh, 0)
+	require.Len(t, producer.GetEvents(kafka.DefaultMockTopicName, 0), 100)
+	require.NotEmpty(t, producer.GetEvents(kafka.DefaultTxnMarkerTopic(kafka.DefaultMockTopicName), 0))
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/metrics/mq"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// defaultSlowPartitionThreshold is the duration after which a partition is regarded
+// as slow, if its oldest in-flight message is still not acknowledged.
+const defaultSlowPartitionThreshold = 30 * time.Second
+
+// partitionInflight is the in-flight messages of a partition.
+type partitionInflight struct {
+	// sendTimes is the send time of the in-flight messages in order, the messages
+	// of a partition are acknowledged in the order they are sent.
+	sendTimes []time.Time
+	bytes     int
+	// slowSince is the send time of the oldest in-flight message when the
+	// partition was found slow, it's zero if the partition is not slow.
+	slowSince time.Time
+
+	metricInflightMessages prometheus.Gauge
+	metricInflightBytes    prometheus.Gauge
+	metricAckDuration      prometheus.Observer
+}
+
+// partitionTracker tracks the in-flight messages of each topic partition,
+// records the acknowledge latency and detects the slow partitions.
+type partitionTracker struct {
+	changefeedID model.ChangeFeedID
+	threshold    time.Duration
+	now          func() time.Time
+
+	mu         sync.Mutex
+	partitions map[model.TopicPartitionKey]*partitionInflight
+
+	metricSlowPartitionCount prometheus.Gauge
+}
+
+func newPartitionTracker(changefeedID model.ChangeFeedID, threshold time.Duration) *partitionTracker {
+	t := &partitionTracker{
+		changefeedID: changefeedID,
+		threshold:    threshold,
+		now:          time.Now,
+		partitions:   make(map[model.TopicPartitionKey]*partitionInflight),
+		metricSlowPartitionCount: mq.WorkerSlowPartitionCount.
+			WithLabelValues(changefeedID.Namespace, changefeedID.ID),
+	}
+	return t
+}
+
+// onSend records a message sent to the partition, the returned function
+// should be called once the message is acknowledged.
+func (t *partitionTracker) onSend(topic string, partition int32, bytes int) func() {
+	key := model.TopicPartitionKey{Topic: topic, Partition: partition}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.partitions[key]
+	if !ok {
+		labels := []string{t.changefeedID.Namespace, t.changefeedID.ID, topic, strconv.Itoa(int(partition))}
+		p = &partitionInflight{
+			metricInflightMessages: mq.WorkerPartitionInflightMessages.WithLabelValues(labels...),
+			metricInflightBytes:    mq.WorkerPartitionInflightBytes.WithLabelValues(labels...),
+			metricAckDuration:      mq.WorkerPartitionAckDuration.WithLabelValues(labels...),
+		}
+		t.partitions[key] = p
+	}
+	p.sendTimes = append(p.sendTimes, t.now())
+	p.bytes += bytes
+	p.metricInflightMessages.Inc()
+	p.metricInflightBytes.Add(float64(bytes))
+
+	return func() {
+		t.onAck(p, bytes)
+	}
+}
+
+func (t *partitionTracker) onAck(p *partitionInflight, bytes int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(p.sendTimes) == 0 {
+		return
+	}
+	p.metricAckDuration.Observe(t.now().Sub(p.sendTimes[0]).Seconds())
+	p.sendTimes[0] = time.Time{}
+	p.sendTimes = p.sendTimes[1:]
+	p.bytes -= bytes
+	p.metricInflightMessages.Dec()
+	p.metricInflightBytes.Sub(float64(bytes))
+}
+
+// slowPartitions returns the partitions whose oldest in-flight message
+// is not acknowledged for longer than the threshold.
+func (t *partitionTracker) slowPartitions() []model.SlowPartition {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var result []model.SlowPartition
+	for key, p := range t.partitions {
+		slow := len(p.sendTimes) > 0 && now.Sub(p.sendTimes[0]) >= t.threshold
+		switch {
+		case slow && p.slowSince.IsZero():
+			p.slowSince = p.sendTimes[0]
+			log.Warn("MQ sink partition is slow",
+				zap.String("namespace", t.changefeedID.Namespace),
+				zap.String("changefeed", t.changefeedID.ID),
+				zap.String("topic", key.Topic),
+				zap.Int32("partition", key.Partition),
+				zap.Int("inflightMessages", len(p.sendTimes)),
+				zap.Int("inflightBytes", p.bytes),
+				zap.Duration("oldestInflight", now.Sub(p.sendTimes[0])))
+		case !slow && !p.slowSince.IsZero():
+			p.slowSince = time.Time{}
+			log.Info("MQ sink partition recovers from slow",
+				zap.String("namespace", t.changefeedID.Namespace),
+				zap.String("changefeed", t.changefeedID.ID),
+				zap.String("topic", key.Topic),
+				zap.Int32("partition", key.Partition))
+		}
+		if slow {
+			result = append(result, model.SlowPartition{
+				Topic:     key.Topic,
+				Partition: key.Partition,
+				Since:     p.slowSince,
+			})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Topic != result[j].Topic {
+			return result[i].Topic < result[j].Topic
+		}
+		return result[i].Partition < result[j].Partition
+	})
+	t.metricSlowPartitionCount.Set(float64(len(result)))
+	return result
+}
+
+func (t *partitionTracker) close() {
+	labels := map[string]string{
+		"namespace":  t.changefeedID.Namespace,
+		"changefeed": t.changefeedID.ID,
+	}
+	mq.WorkerPartitionInflightMessages.DeletePartialMatch(labels)
+	mq.WorkerPartitionInflightBytes.DeletePartialMatch(labels)
+	mq.WorkerPartitionAckDuration.DeletePartialMatch(labels)
+	mq.WorkerSlowPartitionCount.DeletePartialMatch(labels)
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/metrics/mq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionTracker(t *testing.T) {
+	changefeedID := model.DefaultChangeFeedID("test-partition-tracker")
+	tracker := newPartitionTracker(changefeedID, 10*time.Second)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	ack1 := tracker.onSend("topic", 0, 100)
+	ack2 := tracker.onSend("topic", 0, 200)
+	ack3 := tracker.onSend("topic", 1, 300)
+	require.Equal(t, float64(2), testutil.ToFloat64(mq.WorkerPartitionInflightMessages.
+		WithLabelValues(changefeedID.Namespace, changefeedID.ID, "topic", "0")))
+	require.Equal(t, float64(300), testutil.ToFloat64(mq.WorkerPartitionInflightBytes.
+		WithLabelValues(changefeedID.Namespace, changefeedID.ID, "topic", "0")))
+	require.Empty(t, tracker.slowPartitions())
+
+	// The partition 1 is acknowledged in time, but the partition 0 is not.
+	now = now.Add(5 * time.Second)
+	ack3()
+	now = now.Add(5 * time.Second)
+	expected := []model.SlowPartition{{Topic: "topic", Partition: 0, Since: now.Add(-10 * time.Second)}}
+	require.Equal(t, expected, tracker.slowPartitions())
+	require.Equal(t, float64(1), testutil.ToFloat64(mq.WorkerSlowPartitionCount.
+		WithLabelValues(changefeedID.Namespace, changefeedID.ID)))
+
+	// The partition is still slow after the oldest message is acknowledged,
+	// and the time it becomes slow is not changed.
+	ack1()
+	now = now.Add(10 * time.Second)
+	require.Equal(t, expected, tracker.slowPartitions())
+	require.Equal(t, float64(1), testutil.ToFloat64(mq.WorkerPartitionInflightMessages.
+		WithLabelValues(changefeedID.Namespace, changefeedID.ID, "topic", "0")))
+
+	ack2()
+	require.Empty(t, tracker.slowPartitions())
+	require.Equal(t, float64(0), testutil.ToFloat64(mq.WorkerPartitionInflightBytes.
+		WithLabelValues(changefeedID.Namespace, changefeedID.ID, "topic", "0")))
+
+	tracker.close()
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dmlproducer"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/manager"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columnselector"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columntransformer"
 	"github.com/pingcap/tiflow/cdc/sink/util"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
@@ -63,7 +65,7 @@ func NewPulsarDMLSink(
 	if !util.IsPulsarSupportedProtocols(protocol) {
 		return nil, cerror.ErrSinkURIInvalid.
 			GenWithStackByArgs("unsupported protocol, " +
-				"pulsar sink currently only support these protocols: [canal-json, canal, maxwell]")
+				"pulsar sink currently only support these protocols: [canal-json, debezium]")
 	}
 
 	pConfig, err := pulsarConfig.NewPulsarConfig(sinkURI, replicaConfig.Sink.PulsarConfig)
@@ -105,10 +107,17 @@ func NewPulsarDMLSink(
 		return nil, errors.Trace(err)
 	}
 
-	trans, err := columnselector.New(replicaConfig)
+	selector, err := columnselector.New(replicaConfig)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	columnTransformer, err := columntransformer.New(replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The column selector is applied first, so the filtered out columns
+	// are not transformed.
+	trans := transformer.Chain{selector, columnTransformer}
 
 	encoderConfig, err := util.GetEncoderConfig(changefeedID, sinkURI, protocol, replicaConfig,
 		config.DefaultMaxMessageBytes)
@@ -120,10 +129,15 @@ func NewPulsarDMLSink(
 	if err != nil {
 		return nil, cerror.WrapError(cerror.ErrPulsarInvalidConfig, err)
 	}
+	controlEncoder, err := newControlEncoder(eventRouter, encoderBuilder)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 
-	encoderGroup := codec.NewEncoderGroup(replicaConfig.Sink, encoderBuilder, changefeedID)
+	encoderGroup := codec.NewEncoderGroup(replicaConfig.Sink, encoderBuilder, changefeedID).
+		WithDDLTopic(eventRouter.GetDDLTopic)
 
-	s := newDMLSink(ctx, changefeedID, p, nil, topicManager, eventRouter, trans, encoderGroup,
+	s := newDMLSink(ctx, changefeedID, p, nil, topicManager, eventRouter, trans, encoderGroup, controlEncoder, nil,
 		protocol, scheme, pConfig.GetOutputRawChangeEvent(), errCh)
 
 	return s, nil
@@ -0,0 +1,175 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dmlproducer"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/kafka"
+	"go.uber.org/zap"
+)
+
+// txnCoordinator is used by the worker to send the rows in kafka transactions.
+// The progress of each table is committed to the transaction marker topic in the
+// same transaction as the rows. When a table sink is created, e.g. the sink restarts
+// or the table is moved from another capture, the rows which have been committed by
+// the previous table sink are dropped according to the committed progress.
+//
+// All the rows of a table with the same commit ts are committed in the same kafka
+// transaction, so the progress is the commit ts of the last committed rows, and the
+// rows are deduplicated by the whole commit ts.
+type txnCoordinator struct {
+	changefeedID model.ChangeFeedID
+	producer     dmlproducer.TransactionalDMLProducer
+	markerReader kafka.TxnMarkerReader
+	markerTopic  string
+
+	tables map[model.TableID]*tableTxnProgress
+}
+
+// tableTxnProgress is the progress of a table sink in kafka transactions.
+type tableTxnProgress struct {
+	// sinkState identifies the table sink which the progress belongs to.
+	sinkState *state.TableSinkState
+	// committed is the commit ts of the last committed rows, including the
+	// rows committed by the previous table sinks, the rows up to it are dropped.
+	committed uint64
+}
+
+func newTxnCoordinator(
+	changefeedID model.ChangeFeedID,
+	producer dmlproducer.TransactionalDMLProducer,
+	markerReader kafka.TxnMarkerReader,
+	markerTopic string,
+) *txnCoordinator {
+	return &txnCoordinator{
+		changefeedID: changefeedID,
+		producer:     producer,
+		markerReader: markerReader,
+		markerTopic:  markerTopic,
+		tables:       make(map[model.TableID]*tableTxnProgress),
+	}
+}
+
+// filter drops the rows of the stopped tables and the rows which have been
+// committed, the callbacks of the dropped rows are called immediately.
+// It reuses the buffer of msgs to hold the rows to send.
+func (c *txnCoordinator) filter(ctx context.Context, msgs []mqEvent) ([]mqEvent, error) {
+	if err := c.loadNewTables(ctx, msgs); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	result := msgs[:0]
+	for _, msg := range msgs {
+		if msg.rowEvent.GetTableSinkState() != state.TableSinkSinking {
+			msg.rowEvent.Callback()
+			log.Debug("Skip event of stopped table", zap.Any("event", msg.rowEvent))
+			continue
+		}
+		progress := c.tables[msg.rowEvent.Event.GetTableID()]
+		if msg.rowEvent.Event.CommitTs <= progress.committed {
+			msg.rowEvent.Callback()
+			continue
+		}
+		result = append(result, msg)
+	}
+	return result, nil
+}
+
+// loadNewTables loads the committed progress of the new table sinks,
+// the marker topic is read at most once for all new table sinks in msgs.
+func (c *txnCoordinator) loadNewTables(ctx context.Context, msgs []mqEvent) error {
+	var markers map[int64]kafka.TxnMarker
+	for _, msg := range msgs {
+		tableID := msg.rowEvent.Event.GetTableID()
+		progress, ok := c.tables[tableID]
+		if ok && progress.sinkState == msg.rowEvent.SinkState {
+			continue
+		}
+		if markers == nil {
+			var err error
+			markers, err = c.markerReader.ReadMarkers(ctx)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		committed := markers[tableID]
+		c.tables[tableID] = &tableTxnProgress{
+			sinkState: msg.rowEvent.SinkState,
+			committed: committed.CommitTs,
+		}
+		log.Info("MQ sink table transaction progress loaded",
+			zap.String("namespace", c.changefeedID.Namespace),
+			zap.String("changefeed", c.changefeedID.ID),
+			zap.Int64("tableID", tableID),
+			zap.Uint64("commitTs", committed.CommitTs))
+	}
+	return nil
+}
+
+// markers returns the markers which should be committed with the rows.
+func (c *txnCoordinator) markers(msgs []mqEvent) map[model.TableID]kafka.TxnMarker {
+	markers := make(map[model.TableID]kafka.TxnMarker)
+	for _, msg := range msgs {
+		tableID := msg.rowEvent.Event.GetTableID()
+		if commitTs := msg.rowEvent.Event.CommitTs; commitTs > markers[tableID].CommitTs {
+			markers[tableID] = kafka.TxnMarker{CommitTs: commitTs}
+		}
+	}
+	return markers
+}
+
+// hasStoppedTable returns true if any table of the rows is not sinking.
+func (c *txnCoordinator) hasStoppedTable(msgs []mqEvent) bool {
+	for _, msg := range msgs {
+		if msg.rowEvent.GetTableSinkState() != state.TableSinkSinking {
+			return true
+		}
+	}
+	return false
+}
+
+// commit advances the progress of the tables after the markers are committed.
+func (c *txnCoordinator) commit(markers map[model.TableID]kafka.TxnMarker) {
+	for tableID, marker := range markers {
+		c.tables[tableID].committed = marker.CommitTs
+	}
+}
+
+// sendMarkers sends the markers to the transaction marker topic.
+func (c *txnCoordinator) sendMarkers(
+	ctx context.Context, markers map[model.TableID]kafka.TxnMarker,
+) error {
+	for tableID, marker := range markers {
+		partition, key, value, err := kafka.NewTxnMarkerMessage(c.changefeedID, tableID, marker)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		message := &common.Message{Key: key, Value: value}
+		if err := c.producer.AsyncSendMessage(ctx, c.markerTopic, partition, message); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (c *txnCoordinator) close() {
+	c.markerReader.Close()
+}
@@ -0,0 +1,304 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiflow/cdc/entry"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dmlproducer"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/builder"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/kafka"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func newTransactionalWorker(
+	t *testing.T, producer dmlproducer.TransactionalDMLProducer, reader kafka.TxnMarkerReader,
+) *worker {
+	id := model.DefaultChangeFeedID("test")
+	encoderConfig := common.NewConfig(config.ProtocolCanalJSON).WithMaxMessageBytes(1024).WithChangefeedID(id)
+	encoderBuilder, err := builder.NewRowEventEncoderBuilder(context.Background(), encoderConfig)
+	require.NoError(t, err)
+	encoderGroup := codec.NewEncoderGroup(config.GetDefaultReplicaConfig().Sink, encoderBuilder, id)
+	txn := newTxnCoordinator(id, producer, reader, "marker")
+	return newWorker(id, config.ProtocolCanalJSON, producer, encoderGroup, txn)
+}
+
+func newMockTransactionalProducer() *dmlproducer.MockDMLProducer {
+	p := dmlproducer.NewDMLMockProducer(context.Background(), model.DefaultChangeFeedID("test"), nil, nil, nil, nil)
+	return p.(*dmlproducer.MockDMLProducer)
+}
+
+// sendRow sends a row of the table to the worker, called is increased when its callback is called.
+func sendRow(
+	w *worker, tableInfo *model.TableInfo, sinkState *state.TableSinkState,
+	commitTs uint64, value string, called *atomic.Int64,
+) {
+	w.msgChan.In() <- mqEvent{
+		key: model.TopicPartitionKey{Topic: "test", Partition: 0},
+		rowEvent: &dmlsink.RowChangeCallbackableEvent{
+			Event: &model.RowChangedEvent{
+				CommitTs:        commitTs,
+				PhysicalTableID: tableInfo.ID,
+				TableInfo:       tableInfo,
+				Columns: model.Columns2ColumnDatas(
+					[]*model.Column{{Name: "a", Value: value}}, tableInfo),
+			},
+			Callback:  func() { called.Inc() },
+			SinkState: sinkState,
+		},
+	}
+}
+
+// resolveTable marks the rows of the table sent before as a resolved batch.
+func resolveTable(w *worker, tableID model.TableID) {
+	w.msgChan.In() <- mqEvent{resolvedTable: &tableID}
+}
+
+// committedMarkers returns the last committed markers of the tables.
+func committedMarkers(t *testing.T, p *dmlproducer.MockDMLProducer) map[int64]kafka.TxnMarker {
+	markers := make(map[int64]kafka.TxnMarker)
+	prefix := "default/test/"
+	for _, message := range p.GetEvents("marker", 0) {
+		tableID, err := strconv.ParseInt(strings.TrimPrefix(string(message.Key), prefix), 10, 64)
+		require.NoError(t, err)
+		var marker kafka.TxnMarker
+		require.NoError(t, json.Unmarshal(message.Value, &marker))
+		markers[tableID] = marker
+	}
+	return markers
+}
+
+func TestTransactionalWorker(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	job := helper.DDL2Job(`create table test.t(a varchar(255) primary key)`)
+	tableInfo := model.WrapTableInfo(0, "test", 1, job.BinlogInfo.TableInfo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the rows at commit ts 2 have been committed by the previous table sink.
+	p := newMockTransactionalProducer()
+	reader := kafka.NewMockTxnMarkerReader(map[int64]kafka.TxnMarker{
+		tableInfo.ID: {CommitTs: 2},
+	})
+	worker := newTransactionalWorker(t, p, reader)
+	defer worker.close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = worker.run(ctx)
+	}()
+
+	var called atomic.Int64
+	sinking := state.TableSinkSinking
+	sendRow(worker, tableInfo, &sinking, 1, "a", &called)
+	sendRow(worker, tableInfo, &sinking, 2, "b", &called)
+	sendRow(worker, tableInfo, &sinking, 2, "c", &called)
+	sendRow(worker, tableInfo, &sinking, 3, "d", &called)
+	resolveTable(worker, tableInfo.ID)
+
+	// only the rows after the committed commit ts are sent.
+	require.Eventually(t, func() bool {
+		return called.Load() == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, p.GetEvents("test", 0), 1)
+	require.Equal(t, 1, reader.Reads)
+	require.Equal(t, kafka.TxnMarker{CommitTs: 3}, committedMarkers(t, p)[tableInfo.ID])
+
+	// the rows are not committed until they are resolved.
+	sendRow(worker, tableInfo, &sinking, 4, "e", &called)
+	require.Never(t, func() bool {
+		return called.Load() == 5
+	}, 200*time.Millisecond, 10*time.Millisecond)
+	resolveTable(worker, tableInfo.ID)
+	require.Eventually(t, func() bool {
+		return called.Load() == 5
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, p.GetEvents("test", 0), 2)
+
+	// the table is moved back, the committed progress is loaded again.
+	reader.Markers = committedMarkers(t, p)
+	restarted := state.TableSinkSinking
+	sendRow(worker, tableInfo, &restarted, 4, "e", &called)
+	sendRow(worker, tableInfo, &restarted, 5, "f", &called)
+	resolveTable(worker, tableInfo.ID)
+	require.Eventually(t, func() bool {
+		return called.Load() == 7
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, p.GetEvents("test", 0), 3)
+	require.Equal(t, 2, reader.Reads)
+
+	cancel()
+	wg.Wait()
+}
+
+// stoppingProducer calls onMarker before the first transaction marker is sent.
+type stoppingProducer struct {
+	*dmlproducer.MockDMLProducer
+	once     sync.Once
+	onMarker func()
+}
+
+func (p *stoppingProducer) AsyncSendMessage(
+	ctx context.Context, topic string, partition int32, message *common.Message,
+) error {
+	if topic == "marker" {
+		p.once.Do(p.onMarker)
+	}
+	return p.MockDMLProducer.AsyncSendMessage(ctx, topic, partition, message)
+}
+
+func TestTransactionalWorkerTableMoved(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	job := helper.DDL2Job(`create table test.t1(a varchar(255) primary key)`)
+	moved := model.WrapTableInfo(0, "test", 1, job.BinlogInfo.TableInfo)
+	job = helper.DDL2Job(`create table test.t2(a varchar(255) primary key)`)
+	kept := model.WrapTableInfo(0, "test", 1, job.BinlogInfo.TableInfo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The moved table is stopped on capture A in the middle of the transaction.
+	stateA := state.TableSinkSinking
+	keptState := state.TableSinkSinking
+	producerA := &stoppingProducer{
+		MockDMLProducer: newMockTransactionalProducer(),
+		onMarker:        func() { stateA.Store(state.TableSinkStopping) },
+	}
+	workerA := newTransactionalWorker(t, producerA, kafka.NewMockTxnMarkerReader(nil))
+	defer workerA.close()
+
+	var calledA atomic.Int64
+	sendRow(workerA, moved, &stateA, 1, "a", &calledA)
+	sendRow(workerA, kept, &keptState, 1, "x", &calledA)
+	sendRow(workerA, moved, &stateA, 2, "b", &calledA)
+	resolveTable(workerA, moved.ID)
+	resolveTable(workerA, kept.ID)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = workerA.run(ctx)
+	}()
+
+	// The transaction is aborted, and only the rows of the kept table are committed.
+	require.Eventually(t, func() bool {
+		return calledA.Load() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, producerA.Commits)
+	require.Len(t, producerA.GetEvents("test", 0), 1)
+	markersA := committedMarkers(t, producerA.MockDMLProducer)
+	require.Equal(t, map[int64]kafka.TxnMarker{kept.ID: {CommitTs: 1}}, markersA)
+
+	// The moved table is started on capture B, all its rows are committed by B.
+	producerB := newMockTransactionalProducer()
+	readerB := kafka.NewMockTxnMarkerReader(markersA)
+	workerB := newTransactionalWorker(t, producerB, readerB)
+	defer workerB.close()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = workerB.run(ctx)
+	}()
+
+	stateB := state.TableSinkSinking
+	var calledB atomic.Int64
+	sendRow(workerB, moved, &stateB, 1, "a", &calledB)
+	sendRow(workerB, moved, &stateB, 2, "b", &calledB)
+	resolveTable(workerB, moved.ID)
+	require.Eventually(t, func() bool {
+		return calledB.Load() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, producerB.GetEvents("test", 0), 2)
+	require.Equal(t, kafka.TxnMarker{CommitTs: 2}, committedMarkers(t, producerB)[moved.ID])
+
+	cancel()
+	wg.Wait()
+}
+
+// lateBootstrapEncoderGroup queues a bootstrap message after the rows, just like
+// the bootstrap worker sending it periodically in the middle of a batch.
+type lateBootstrapEncoderGroup struct {
+	codec.EncoderGroup
+}
+
+func (g *lateBootstrapEncoderGroup) AddEvents(
+	ctx context.Context, key model.TopicPartitionKey, events ...*dmlsink.RowChangeCallbackableEvent,
+) error {
+	if err := g.EncoderGroup.AddEvents(ctx, key, events...); err != nil {
+		return err
+	}
+	return g.EncoderGroup.AddMessages(ctx, key, &common.Message{Value: []byte("bootstrap")})
+}
+
+func TestTransactionalWorkerLateBootstrap(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	job := helper.DDL2Job(`create table test.t(a varchar(255) primary key)`)
+	tableInfo := model.WrapTableInfo(0, "test", 1, job.BinlogInfo.TableInfo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := newMockTransactionalProducer()
+	worker := newTransactionalWorker(t, p, kafka.NewMockTxnMarkerReader(nil))
+	worker.encoderGroup = &lateBootstrapEncoderGroup{EncoderGroup: worker.encoderGroup}
+	defer worker.close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = worker.run(ctx)
+	}()
+
+	var called atomic.Int64
+	sinking := state.TableSinkSinking
+	sendRow(worker, tableInfo, &sinking, 1, "a", &called)
+	resolveTable(worker, tableInfo.ID)
+
+	// The bootstrap message queued after the row is committed in the same transaction.
+	require.Eventually(t, func() bool {
+		return called.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, p.Commits)
+	events := p.GetEvents("test", 0)
+	require.Len(t, events, 2)
+	require.Equal(t, []byte("bootstrap"), events[1].Value)
+
+	cancel()
+	wg.Wait()
+}
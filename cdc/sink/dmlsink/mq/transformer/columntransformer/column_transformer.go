@@ -0,0 +1,365 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntransformer
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	filter "github.com/pingcap/tidb/pkg/util/table-filter"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher/partition"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/errors"
+)
+
+const (
+	maskChar    = '*'
+	tokenPrefix = "tok_"
+	// tokenSize is the number of bytes of the HMAC retained in the token.
+	tokenSize = 16
+)
+
+type rule struct {
+	tableF  filter.Filter
+	columnM filter.ColumnFilter
+	config  *config.ColumnTransformer
+}
+
+func newRule(cfg *config.ColumnTransformer, caseSensitive bool) (*rule, error) {
+	tableF, err := filter.Parse(cfg.Matcher)
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrFilterRuleInvalid, err, cfg.Matcher)
+	}
+	if !caseSensitive {
+		tableF = filter.CaseInsensitive(tableF)
+	}
+	columnM, err := filter.ParseColumnFilter(cfg.Columns)
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrFilterRuleInvalid, err, cfg.Columns)
+	}
+	return &rule{
+		tableF:  tableF,
+		columnM: columnM,
+		config:  cfg,
+	}, nil
+}
+
+func (r *rule) match(schema, table string) bool {
+	return r.tableF.MatchTable(schema, table)
+}
+
+// verifyColumn returns error if the column cannot be transformed by the rule.
+// Handle key columns identify the row in the downstream and are used to
+// dispatch the row to partitions, so only the deterministic and collision
+// resistant actions are allowed for them.
+func (r *rule) verifyColumn(table *model.TableInfo, column *timodel.ColumnInfo) error {
+	flag := table.ForceGetColumnFlagType(column.ID)
+	switch r.config.Action {
+	case config.ColumnTransformRename:
+		if flag.IsHandleKey() {
+			return errors.ErrColumnTransformerFailed.GenWithStack(
+				"the handle key column cannot be renamed, table: %v, column: %s",
+				table.TableName, column.Name)
+		}
+		return nil
+	case config.ColumnTransformMask, config.ColumnTransformTruncate:
+		if flag.IsHandleKey() {
+			return errors.ErrColumnTransformerFailed.GenWithStack(
+				"the handle key column cannot be transformed by %s, table: %v, column: %s",
+				r.config.Action, table.TableName, column.Name)
+		}
+	}
+	if !isStringType(column.GetType()) {
+		return errors.ErrColumnTransformerFailed.GenWithStack(
+			"the column is not a string column, table: %v, column: %s, action: %s",
+			table.TableName, column.Name, r.config.Action)
+	}
+	return nil
+}
+
+func (r *rule) apply(table *model.TableInfo, columns []*model.ColumnData) error {
+	for _, column := range columns {
+		// the column may be filtered out by the column selector.
+		if column == nil {
+			continue
+		}
+		info := table.ForceGetColumnInfo(column.ColumnID)
+		if !r.columnM.MatchColumn(info.Name.O) {
+			continue
+		}
+		if err := r.verifyColumn(table, info); err != nil {
+			return err
+		}
+		if column.Value == nil {
+			continue
+		}
+		value, err := r.transform(column.Value)
+		if err != nil {
+			return errors.WrapError(errors.ErrColumnTransformerFailed, err)
+		}
+		column.Value = value
+	}
+	return nil
+}
+
+func (r *rule) transform(value interface{}) (interface{}, error) {
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return nil, errors.ErrColumnTransformerFailed.GenWithStack(
+			"unexpected column value type %T", value)
+	}
+
+	var result []byte
+	switch r.config.Action {
+	case config.ColumnTransformMask:
+		result = mask(data, r.config.Length)
+	case config.ColumnTransformTruncate:
+		result = truncate(data, r.config.Length)
+	case config.ColumnTransformHash:
+		h := sha256.New()
+		h.Write([]byte(r.config.Salt))
+		h.Write(data)
+		result = []byte(hex.EncodeToString(h.Sum(nil)))
+	case config.ColumnTransformTokenize:
+		mac := hmac.New(sha256.New, []byte(r.config.Salt))
+		mac.Write(data)
+		result = []byte(tokenPrefix + hex.EncodeToString(mac.Sum(nil)[:tokenSize]))
+	default:
+		return nil, errors.ErrColumnTransformerFailed.GenWithStack(
+			"unexpected column transformer action %s", r.config.Action)
+	}
+
+	if _, ok := value.(string); ok {
+		return string(result), nil
+	}
+	return result, nil
+}
+
+// mask replaces all but the last `keep` characters with the mask character.
+// The value is treated as bytes if it is not a valid UTF-8 string.
+func mask(data []byte, keep int) []byte {
+	if !utf8.Valid(data) {
+		result := make([]byte, len(data))
+		for i := range result {
+			if i < len(data)-keep {
+				result[i] = maskChar
+			} else {
+				result[i] = data[i]
+			}
+		}
+		return result
+	}
+	runes := []rune(string(data))
+	for i := 0; i < len(runes)-keep; i++ {
+		runes[i] = maskChar
+	}
+	return []byte(string(runes))
+}
+
+// truncate retains the first `length` characters.
+// The value is treated as bytes if it is not a valid UTF-8 string.
+func truncate(data []byte, length int) []byte {
+	if !utf8.Valid(data) {
+		if len(data) > length {
+			return data[:length]
+		}
+		return data
+	}
+	runes := []rune(string(data))
+	if len(runes) > length {
+		return []byte(string(runes[:length]))
+	}
+	return data
+}
+
+func isStringType(tp byte) bool {
+	switch tp {
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		return true
+	default:
+		return false
+	}
+}
+
+type renamedTable struct {
+	origin  *model.TableInfo
+	renamed *model.TableInfo
+}
+
+// ColumnTransformer transforms the column values and names of the row changed
+// events. All the rules matching the table are applied in order, and the column
+// names in the rules always refer to the upstream column names.
+type ColumnTransformer struct {
+	rules []*rule
+
+	mu sync.Mutex
+	// renamed caches the renamed table info of the latest table info by table ID.
+	renamed map[int64]renamedTable
+}
+
+// New return a column transformer
+func New(cfg *config.ReplicaConfig) (*ColumnTransformer, error) {
+	rules := make([]*rule, 0, len(cfg.Sink.ColumnTransformers))
+	for _, r := range cfg.Sink.ColumnTransformers {
+		rule, err := newRule(r, cfg.CaseSensitive)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return &ColumnTransformer{
+		rules:   rules,
+		renamed: make(map[int64]renamedTable),
+	}, nil
+}
+
+// Apply the column transformer to the given event.
+func (c *ColumnTransformer) Apply(event *model.RowChangedEvent) error {
+	if len(c.rules) == 0 {
+		return nil
+	}
+
+	var renames []*rule
+	for _, r := range c.rules {
+		if !r.match(event.TableInfo.GetSchemaName(), event.TableInfo.GetTableName()) {
+			continue
+		}
+		if r.config.Action == config.ColumnTransformRename {
+			renames = append(renames, r)
+			continue
+		}
+		if err := r.apply(event.TableInfo, event.Columns); err != nil {
+			return err
+		}
+		if err := r.apply(event.TableInfo, event.PreColumns); err != nil {
+			return err
+		}
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+
+	tableInfo, err := c.getRenamedTableInfo(event.TableInfo, renames)
+	if err != nil {
+		return err
+	}
+	event.TableInfo = tableInfo
+	return nil
+}
+
+func (c *ColumnTransformer) getRenamedTableInfo(
+	table *model.TableInfo, renames []*rule,
+) (*model.TableInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.renamed[table.ID]; ok && cached.origin == table {
+		return cached.renamed, nil
+	}
+
+	info := table.TableInfo.Clone()
+	for _, r := range renames {
+		for _, column := range info.Columns {
+			if !r.columnM.MatchColumn(column.Name.O) {
+				continue
+			}
+			if err := r.verifyColumn(table, table.ForceGetColumnInfo(column.ID)); err != nil {
+				return nil, err
+			}
+			if column.Name.L != strings.ToLower(r.config.NewName) &&
+				info.FindPublicColumnByName(r.config.NewName) != nil {
+				return nil, errors.ErrColumnTransformerFailed.GenWithStack(
+					"the new column name is already used, table: %v, column: %s, new name: %s",
+					table.TableName, column.Name, r.config.NewName)
+			}
+			oldName := column.Name.L
+			column.Name = pmodel.NewCIStr(r.config.NewName)
+			for _, index := range info.Indices {
+				for _, indexColumn := range index.Columns {
+					if indexColumn.Name.L == oldName {
+						indexColumn.Name = column.Name
+					}
+				}
+			}
+		}
+	}
+
+	renamed := model.WrapTableInfo(table.SchemaID, table.TableName.Schema, table.Version, info)
+	renamed.TableName = table.TableName
+	c.renamed[table.ID] = renamedTable{origin: table, renamed: renamed}
+	return renamed, nil
+}
+
+// VerifyTables return the error if any given table cannot satisfy the column transformer constraints.
+// 1. the handle key columns can only be hashed or tokenized.
+// 2. the column to be masked, hashed, truncated or tokenized must be a string column.
+// 3. the renamed column must not be used in the column dispatcher.
+// The event router can be nil if the downstream is not MQ.
+func (c *ColumnTransformer) VerifyTables(
+	infos []*model.TableInfo, eventRouter *dispatcher.EventRouter,
+) error {
+	for _, table := range infos {
+		var renames []*rule
+		for _, r := range c.rules {
+			if !r.match(table.TableName.Schema, table.TableName.Table) {
+				continue
+			}
+			for _, column := range table.Columns {
+				if !model.IsColCDCVisible(column) || !r.columnM.MatchColumn(column.Name.O) {
+					continue
+				}
+				if err := r.verifyColumn(table, column); err != nil {
+					return err
+				}
+				if r.config.Action != config.ColumnTransformRename || eventRouter == nil {
+					continue
+				}
+				partitionDispatcher := eventRouter.GetPartitionDispatcher(
+					table.TableName.Schema, table.TableName.Table)
+				if v, ok := partitionDispatcher.(*partition.ColumnsDispatcher); ok {
+					for _, col := range v.Columns {
+						if strings.EqualFold(col, column.Name.O) {
+							return errors.ErrColumnTransformerFailed.GenWithStack(
+								"the renamed column is used in the column dispatcher, "+
+									"table: %v, column: %s", table.TableName, column.Name)
+						}
+					}
+				}
+			}
+			if r.config.Action == config.ColumnTransformRename {
+				renames = append(renames, r)
+			}
+		}
+		if len(renames) > 0 {
+			if _, err := c.getRenamedTableInfo(table, renames); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
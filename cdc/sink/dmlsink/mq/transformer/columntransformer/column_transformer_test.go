@@ -0,0 +1,186 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package columntransformer
+
+import (
+	"testing"
+
+	"github.com/pingcap/tiflow/cdc/entry"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTableInfo(helper *entry.SchemaTestHelper) *model.TableInfo {
+	job := helper.DDL2Job(`create table test.t1(
+		id varchar(64) primary key,
+		phone varchar(32),
+		name varchar(64),
+		email varchar(64),
+		age int,
+		unique key uk_email(email))`)
+	return model.WrapTableInfo(0, "test", 0, job.BinlogInfo.TableInfo)
+}
+
+func columnValues(tableInfo *model.TableInfo, cols []*model.ColumnData) map[string]interface{} {
+	values := make(map[string]interface{}, len(cols))
+	for _, col := range cols {
+		values[tableInfo.ForceGetColumnName(col.ColumnID)] = col.Value
+	}
+	return values
+}
+
+func TestApply(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+	tableInfo := newTableInfo(helper)
+
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.ColumnTransformers = []*config.ColumnTransformer{
+		{Matcher: []string{"test.*"}, Columns: []string{"id"}, Action: config.ColumnTransformHash, Salt: "s"},
+		{Matcher: []string{"test.*"}, Columns: []string{"phone"}, Action: config.ColumnTransformMask, Length: 4},
+		{Matcher: []string{"test.*"}, Columns: []string{"name"}, Action: config.ColumnTransformTruncate, Length: 2},
+		{Matcher: []string{"test.*"}, Columns: []string{"email"}, Action: config.ColumnTransformTokenize, Salt: "s"},
+		{Matcher: []string{"test.*"}, Columns: []string{"email"}, Action: config.ColumnTransformRename, NewName: "mail"},
+		{Matcher: []string{"other.*"}, Columns: []string{"*"}, Action: config.ColumnTransformMask},
+	}
+	transformer, err := New(replicaConfig)
+	require.NoError(t, err)
+
+	newEvent := func() *model.RowChangedEvent {
+		cols := model.Columns2ColumnDatas([]*model.Column{
+			{Name: "id", Value: []byte("k1")},
+			{Name: "phone", Value: []byte("13812345678")},
+			{Name: "name", Value: "张三丰"},
+			{Name: "email", Value: []byte("a@b.c")},
+			{Name: "age", Value: int64(18)},
+		}, tableInfo)
+		preCols := model.Columns2ColumnDatas([]*model.Column{
+			{Name: "id", Value: []byte("k1")},
+			{Name: "phone", Value: nil},
+			{Name: "name", Value: "李四"},
+			{Name: "email", Value: []byte("a@b.c")},
+			{Name: "age", Value: int64(17)},
+		}, tableInfo)
+		return &model.RowChangedEvent{TableInfo: tableInfo, Columns: cols, PreColumns: preCols}
+	}
+
+	event := newEvent()
+	require.NoError(t, transformer.Apply(event))
+	values := columnValues(event.TableInfo, event.Columns)
+	require.Equal(t, map[string]interface{}{
+		"id":    []byte("2bf09322c2c60ef426f5eb146dc28710d077cf2016d162f61f65835d8c365663"),
+		"phone": []byte("*******5678"),
+		"name":  "张三",
+		"mail":  []byte("tok_a6ebc14b3086e660195e044875cc1bbe"),
+		"age":   int64(18),
+	}, values)
+	preValues := columnValues(event.TableInfo, event.PreColumns)
+	// the handle key and the unique key are transformed consistently.
+	require.Equal(t, values["id"], preValues["id"])
+	require.Equal(t, values["mail"], preValues["mail"])
+	require.Nil(t, preValues["phone"])
+	require.Equal(t, "李四", preValues["name"])
+
+	// the renamed table info is reused.
+	renamed := event.TableInfo
+	require.NotSame(t, tableInfo, renamed)
+	require.Equal(t, tableInfo.TableName, renamed.TableName)
+	require.True(t, renamed.ForceGetColumnFlagType(
+		renamed.ForceGetColumnIDByName("mail")).IsUniqueKey())
+	event = newEvent()
+	require.NoError(t, transformer.Apply(event))
+	require.Same(t, renamed, event.TableInfo)
+
+	// the string value is hashed with the salt.
+	replicaConfig.Sink.ColumnTransformers[0].Salt = "t"
+	transformer, err = New(replicaConfig)
+	require.NoError(t, err)
+	event = newEvent()
+	require.NoError(t, transformer.Apply(event))
+	require.NotEqual(t, values["id"], columnValues(event.TableInfo, event.Columns)["id"])
+
+	// the non-string column cannot be transformed.
+	replicaConfig.Sink.ColumnTransformers = []*config.ColumnTransformer{
+		{Matcher: []string{"test.*"}, Columns: []string{"age"}, Action: config.ColumnTransformHash},
+	}
+	transformer, err = New(replicaConfig)
+	require.NoError(t, err)
+	err = transformer.Apply(newEvent())
+	require.ErrorIs(t, err, errors.ErrColumnTransformerFailed)
+}
+
+func TestMaskAndTruncate(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte("****"), mask([]byte("abcd"), 0))
+	require.Equal(t, []byte("**cd"), mask([]byte("abcd"), 2))
+	require.Equal(t, []byte("abcd"), mask([]byte("abcd"), 8))
+	require.Equal(t, []byte("*三"), mask([]byte("张三"), 1))
+	require.Equal(t, []byte{'*', 0xff}, mask([]byte{0xfe, 0xff}, 1))
+
+	require.Equal(t, []byte("ab"), truncate([]byte("abcd"), 2))
+	require.Equal(t, []byte("abcd"), truncate([]byte("abcd"), 8))
+	require.Equal(t, []byte("张"), truncate([]byte("张三"), 1))
+	require.Equal(t, []byte{0xfe}, truncate([]byte{0xfe, 0xff}, 1))
+}
+
+func TestVerifyTables(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+	infos := []*model.TableInfo{newTableInfo(helper)}
+
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.ColumnTransformers = []*config.ColumnTransformer{
+		{Matcher: []string{"test.t1"}, Columns: []string{"id"}, Action: config.ColumnTransformTokenize, Salt: "s"},
+		{Matcher: []string{"test.t1"}, Columns: []string{"phone", "name"}, Action: config.ColumnTransformMask},
+		{Matcher: []string{"test.t1"}, Columns: []string{"email"}, Action: config.ColumnTransformRename, NewName: "mail"},
+	}
+	transformer, err := New(replicaConfig)
+	require.NoError(t, err)
+	require.NoError(t, transformer.VerifyTables(infos, nil))
+
+	for _, rule := range []*config.ColumnTransformer{
+		// the handle key can only be hashed or tokenized.
+		{Matcher: []string{"test.t1"}, Columns: []string{"id"}, Action: config.ColumnTransformMask},
+		{Matcher: []string{"test.t1"}, Columns: []string{"i*"}, Action: config.ColumnTransformTruncate, Length: 1},
+		{Matcher: []string{"test.t1"}, Columns: []string{"id"}, Action: config.ColumnTransformRename, NewName: "k"},
+		// the non-string column cannot be transformed.
+		{Matcher: []string{"test.t1"}, Columns: []string{"age"}, Action: config.ColumnTransformHash},
+		// the new name conflicts with another column.
+		{Matcher: []string{"test.t1"}, Columns: []string{"email"}, Action: config.ColumnTransformRename, NewName: "name"},
+	} {
+		replicaConfig.Sink.ColumnTransformers = []*config.ColumnTransformer{rule}
+		transformer, err = New(replicaConfig)
+		require.NoError(t, err)
+		err = transformer.VerifyTables(infos, nil)
+		require.ErrorIs(t, err, errors.ErrColumnTransformerFailed, rule)
+	}
+
+	// the renamed column cannot be used in the column dispatcher.
+	replicaConfig.Sink.ColumnTransformers = []*config.ColumnTransformer{
+		{Matcher: []string{"test.t1"}, Columns: []string{"email"}, Action: config.ColumnTransformRename, NewName: "mail"},
+	}
+	replicaConfig.Sink.DispatchRules = []*config.DispatchRule{
+		{Matcher: []string{"test.t1"}, PartitionRule: "columns", Columns: []string{"email"}},
+	}
+	eventRouter, err := dispatcher.NewEventRouter(replicaConfig, config.ProtocolOpen, "default", "kafka")
+	require.NoError(t, err)
+	transformer, err = New(replicaConfig)
+	require.NoError(t, err)
+	err = transformer.VerifyTables(infos, eventRouter)
+	require.ErrorIs(t, err, errors.ErrColumnTransformerFailed)
+}
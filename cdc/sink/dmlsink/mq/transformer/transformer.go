@@ -19,3 +19,31 @@ import "github.com/pingcap/tiflow/cdc/model"
 type Transformer interface {
 	Apply(event *model.RowChangedEvent) error
 }
+
+// Chain applies the transformers one by one in order.
+type Chain []Transformer
+
+// Apply implements Transformer interface.
+func (c Chain) Apply(event *model.RowChangedEvent) error {
+	for _, t := range c {
+		if err := t.Apply(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyToTxn applies the transformer to all rows of the given transaction.
+// The table info of the transaction is kept the same as the one of its rows,
+// since the transformer may replace it, e.g. rename columns.
+func ApplyToTxn(t Transformer, txn *model.SingleTableTxn) error {
+	for _, row := range txn.Rows {
+		if err := t.Apply(row); err != nil {
+			return err
+		}
+	}
+	if len(txn.Rows) > 0 {
+		txn.TableInfo = txn.Rows[0].TableInfo
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/sink"
 	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
@@ -46,6 +47,12 @@ const (
 type mqEvent struct {
 	key      model.TopicPartitionKey
 	rowEvent *dmlsink.RowChangeCallbackableEvent
+	// marker is the message sent to the partition in order with the rows,
+	// such as the partition mapping change marker. The rowEvent is nil for it.
+	marker *common.Message
+	// resolvedTable is the table whose rows sent before it are a resolved batch,
+	// it's only sent in exactly-once mode. The rowEvent and marker are nil for it.
+	resolvedTable *model.TableID
 }
 
 // worker will send messages to the DML producer on a batch basis.
@@ -64,8 +71,13 @@ type worker struct {
 
 	// producer is used to send the messages to the Kafka broker.
 	producer dmlproducer.DMLProducer
+	// txn is used to send the messages in kafka transactions,
+	// it's nil if the exactly-once is not enabled.
+	txn *txnCoordinator
 	// statistics is used to record DML metrics.
 	statistics *metrics.Statistics
+	// tracker is used to account the in-flight messages of each partition.
+	tracker *partitionTracker
 }
 
 // newWorker creates a new flush worker.
@@ -74,6 +86,7 @@ func newWorker(
 	protocol config.Protocol,
 	producer dmlproducer.DMLProducer,
 	encoderGroup codec.EncoderGroup,
+	txn *txnCoordinator,
 ) *worker {
 	w := &worker{
 		changeFeedID: id,
@@ -82,7 +95,9 @@ func newWorker(
 		ticker:       time.NewTicker(batchInterval),
 		encoderGroup: encoderGroup,
 		producer:     producer,
+		txn:          txn,
 		statistics:   metrics.NewStatistics(id, sink.RowSink),
+		tracker:      newPartitionTracker(id, defaultSlowPartitionThreshold),
 	}
 	return w
 }
@@ -103,6 +118,12 @@ func (w *worker) run(ctx context.Context) (retErr error) {
 	g.Go(func() error {
 		return w.encoderGroup.Run(ctx)
 	})
+	if w.txn != nil {
+		g.Go(func() error {
+			return w.transactionalRun(ctx)
+		})
+		return g.Wait()
+	}
 	g.Go(func() error {
 		if w.protocol.IsBatchEncode() {
 			return w.batchEncodeRun(ctx)
@@ -133,6 +154,12 @@ func (w *worker) nonBatchEncodeRun(ctx context.Context) error {
 					zap.String("changefeed", w.changeFeedID.ID))
 				return nil
 			}
+			if event.marker != nil {
+				if err := w.encoderGroup.AddMessages(ctx, event.key, event.marker); err != nil {
+					return errors.Trace(err)
+				}
+				continue
+			}
 			if event.rowEvent.GetTableSinkState() != state.TableSinkSinking {
 				event.rowEvent.Callback()
 				log.Debug("Skip event of stopped table",
@@ -180,16 +207,226 @@ func (w *worker) batchEncodeRun(ctx context.Context) (retErr error) {
 		metricBatchDuration.Observe(time.Since(start).Seconds())
 
 		msgs := msgsBuf[:msgCount]
-		// Group messages by its TopicPartitionKey before adding them to the encoder group.
-		groupedMsgs := w.group(msgs)
-		for key, msg := range groupedMsgs {
-			if err := w.encoderGroup.AddEvents(ctx, key, msg...); err != nil {
+		// The markers split the messages, so they are kept in order with the rows.
+		for len(msgs) != 0 {
+			end := 0
+			for end < len(msgs) && msgs[end].marker == nil {
+				end++
+			}
+			// Group messages by its TopicPartitionKey before adding them to the encoder group.
+			groupedMsgs := w.group(msgs[:end])
+			for key, msg := range groupedMsgs {
+				if err := w.encoderGroup.AddEvents(ctx, key, msg...); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			if end < len(msgs) {
+				if err := w.encoderGroup.AddMessages(ctx, msgs[end].key, msgs[end].marker); err != nil {
+					return errors.Trace(err)
+				}
+				end++
+			}
+			msgs = msgs[end:]
+		}
+	}
+}
+
+// transactionalRun collects messages into batch, and sends the rows of the resolved
+// batches of the tables in a kafka transaction. The rows of a table which are not
+// resolved yet are kept to the next transaction, so all the rows of a table with
+// the same commit ts are always committed together.
+func (w *worker) transactionalRun(ctx context.Context) error {
+	log.Info("MQ sink transactional worker started",
+		zap.String("namespace", w.changeFeedID.Namespace),
+		zap.String("changefeed", w.changeFeedID.ID),
+		zap.String("protocol", w.protocol.String()),
+	)
+
+	metricBatchDuration := mq.WorkerBatchDuration.WithLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
+	metricBatchSize := mq.WorkerBatchSize.WithLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
+	defer func() {
+		mq.WorkerBatchDuration.DeleteLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
+		mq.WorkerBatchSize.DeleteLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
+	}()
+
+	var pending []mqEvent
+	msgsBuf := make([]mqEvent, batchSize)
+	for {
+		start := time.Now()
+		msgCount, err := w.batch(ctx, msgsBuf, batchInterval)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if msgCount == 0 {
+			continue
+		}
+		metricBatchSize.Observe(float64(msgCount))
+		metricBatchDuration.Observe(time.Since(start).Seconds())
+
+		var resolved []mqEvent
+		resolved, pending = splitResolved(append(pending, msgsBuf[:msgCount]...))
+		if len(resolved) == 0 {
+			continue
+		}
+		if err := w.sendTransaction(ctx, resolved); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// splitResolved splits the rows into the rows which are sent before the last
+// resolved boundary of their tables, and the rest which are not resolved yet.
+// The resolved boundaries are dropped.
+func splitResolved(msgs []mqEvent) (resolved []mqEvent, rest []mqEvent) {
+	boundaries := make(map[model.TableID]int)
+	for i, msg := range msgs {
+		if msg.resolvedTable != nil {
+			boundaries[*msg.resolvedTable] = i
+		}
+	}
+	for i, msg := range msgs {
+		if msg.resolvedTable != nil {
+			continue
+		}
+		if boundary, ok := boundaries[msg.rowEvent.Event.GetTableID()]; ok && i < boundary {
+			resolved = append(resolved, msg)
+		} else {
+			rest = append(rest, msg)
+		}
+	}
+	return resolved, rest
+}
+
+// sendTransaction sends the messages along with the progress of their tables in a
+// kafka transaction, the callbacks of the messages are called after it's committed.
+// If it fails, the transaction is left to be aborted by the producer of the restarted
+// sink, which has the same transactional id.
+//
+// The transactional id can't fence the producer of the previous capture when a table
+// is moved, so the rows of a stopped table sink are never committed: if any table is
+// stopped before the commit, the transaction is aborted, and the rest rows are sent
+// again in a new transaction. A table sink waits for the callbacks of its rows when
+// it's closed, and the table is started on another capture only after that, so a
+// table which is stopped after the check can't be read by another capture before
+// the transaction is committed.
+func (w *worker) sendTransaction(ctx context.Context, msgs []mqEvent) error {
+	for {
+		var err error
+		msgs, err = w.txn.filter(ctx, msgs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+		markers := w.txn.markers(msgs)
+
+		if err := w.txn.producer.BeginTxn(); err != nil {
+			return errors.Trace(err)
+		}
+		callbacks, err := w.sendTxnMessages(ctx, msgs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := w.txn.sendMarkers(ctx, markers); err != nil {
+			return errors.Trace(err)
+		}
+		if w.txn.hasStoppedTable(msgs) {
+			if err := w.txn.producer.AbortTxn(); err != nil {
 				return errors.Trace(err)
 			}
+			log.Info("MQ sink transaction aborted since some tables are stopped",
+				zap.String("namespace", w.changeFeedID.Namespace),
+				zap.String("changefeed", w.changeFeedID.ID),
+				zap.Int("rows", len(msgs)))
+			continue
+		}
+		if err := w.txn.producer.CommitTxn(); err != nil {
+			return errors.Trace(err)
+		}
+		w.txn.commit(markers)
+		for _, callback := range callbacks {
+			callback()
 		}
+		return nil
 	}
 }
 
+// sendTxnMessages encodes the rows and sends them in the current transaction,
+// it returns the callbacks of the messages which should be called after commit.
+func (w *worker) sendTxnMessages(ctx context.Context, msgs []mqEvent) ([]func(), error) {
+	groupedMsgs := make(map[model.TopicPartitionKey][]*dmlsink.RowChangeCallbackableEvent)
+	for _, msg := range msgs {
+		groupedMsgs[msg.key] = append(groupedMsgs[msg.key], msg.rowEvent)
+	}
+	for key, events := range groupedMsgs {
+		if err := w.encoderGroup.AddEvents(ctx, key, events...); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	var callbacks []func()
+	outCh := w.encoderGroup.Output()
+	// The bootstrap messages may be sent in the transaction too.
+	for encoded := 0; encoded < len(msgs); {
+		select {
+		case <-ctx.Done():
+			return nil, errors.Trace(ctx.Err())
+		case future := <-outCh:
+			if err := future.Ready(ctx); err != nil {
+				return nil, errors.Trace(err)
+			}
+			encoded += future.EventCount()
+			if err := w.sendTxnFuture(ctx, future.Key, future.Messages, &callbacks); err != nil {
+				return nil, err
+			}
+		}
+	}
+	// The bootstrap messages queued after the rows are sent in the transaction
+	// too, rather than left out of it until the next batch.
+	for {
+		select {
+		case future := <-outCh:
+			if err := future.Ready(ctx); err != nil {
+				return nil, errors.Trace(err)
+			}
+			if err := w.sendTxnFuture(ctx, future.Key, future.Messages, &callbacks); err != nil {
+				return nil, err
+			}
+		default:
+			return callbacks, nil
+		}
+	}
+}
+
+// sendTxnFuture sends the encoded messages of a future in the current transaction,
+// their callbacks are collected into callbacks.
+func (w *worker) sendTxnFuture(
+	ctx context.Context, key model.TopicPartitionKey, messages []*common.Message, callbacks *[]func(),
+) error {
+	metricSendMessageDuration := mq.WorkerSendMessageDuration.WithLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
+	for _, message := range messages {
+		if message.Callback != nil {
+			*callbacks = append(*callbacks, message.Callback)
+			message.Callback = nil
+		}
+		start := time.Now()
+		if err := w.statistics.RecordBatchExecution(func() (int, int64, error) {
+			message.SetPartitionKey(key.PartitionKey)
+			w.trackMessage(key.Topic, key.Partition, message)
+			if err := w.producer.AsyncSendMessage(ctx, key.Topic, key.Partition, message); err != nil {
+				return 0, 0, err
+			}
+			mq.RecordPartitionWriteBytes(w.changeFeedID, key.Topic, key.Partition, message.Length())
+			return message.GetRowsCount(), int64(message.Length()), nil
+		}); err != nil {
+			return err
+		}
+		metricSendMessageDuration.Observe(time.Since(start).Seconds())
+	}
+	return nil
+}
+
 // batch collects a batch of messages from w.msgChan into buffer.
 // It returns the number of messages collected.
 // Note: It will block until at least one message is received.
@@ -212,6 +449,9 @@ func (w *worker) batch(
 			w.statistics.ObserveRows(msg.rowEvent.Event)
 			buffer[msgCount] = msg
 			msgCount++
+		} else if msg.marker != nil || msg.resolvedTable != nil {
+			buffer[msgCount] = msg
+			msgCount++
 		}
 	}
 
@@ -232,6 +472,9 @@ func (w *worker) batch(
 				w.statistics.ObserveRows(msg.rowEvent.Event)
 				buffer[msgCount] = msg
 				msgCount++
+			} else if msg.marker != nil || msg.resolvedTable != nil {
+				buffer[msgCount] = msg
+				msgCount++
 			}
 
 			if msgCount >= maxBatchSize {
@@ -287,6 +530,7 @@ func (w *worker) sendMessages(ctx context.Context) error {
 				start := time.Now()
 				if err = w.statistics.RecordBatchExecution(func() (int, int64, error) {
 					message.SetPartitionKey(future.Key.PartitionKey)
+					w.trackMessage(future.Key.Topic, future.Key.Partition, message)
 					if err := w.producer.AsyncSendMessage(
 						ctx,
 						future.Key.Topic,
@@ -294,6 +538,8 @@ func (w *worker) sendMessages(ctx context.Context) error {
 						message); err != nil {
 						return 0, 0, err
 					}
+					mq.RecordPartitionWriteBytes(w.changeFeedID,
+						future.Key.Topic, future.Key.Partition, message.Length())
 					return message.GetRowsCount(), int64(message.Length()), nil
 				}); err != nil {
 					return err
@@ -304,11 +550,29 @@ func (w *worker) sendMessages(ctx context.Context) error {
 	}
 }
 
+// trackMessage accounts the message as in-flight until it's acknowledged,
+// which is notified by the callback of the message.
+func (w *worker) trackMessage(topic string, partition int32, message *common.Message) {
+	ack := w.tracker.onSend(topic, partition, message.Length())
+	callback := message.Callback
+	message.Callback = func() {
+		ack()
+		if callback != nil {
+			callback()
+		}
+	}
+}
+
 func (w *worker) close() {
 	w.msgChan.CloseAndDrain()
 	w.producer.Close()
+	if w.txn != nil {
+		w.txn.close()
+	}
 	w.statistics.Close()
+	w.tracker.close()
 	mq.WorkerSendMessageDuration.DeleteLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
 	mq.WorkerBatchSize.DeleteLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
 	mq.WorkerBatchDuration.DeleteLabelValues(w.changeFeedID.Namespace, w.changeFeedID.ID)
+	mq.CleanPartitionWriteBytes(w.changeFeedID)
 }
@@ -15,6 +15,7 @@ package mq
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 	"testing"
 	"time"
@@ -23,12 +24,14 @@ import (
 	"github.com/pingcap/tiflow/cdc/entry"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher/partition"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dmlproducer"
 	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
 	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/sink/codec"
 	"github.com/pingcap/tiflow/pkg/sink/codec/builder"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/codec/simple"
 	"github.com/stretchr/testify/require"
 )
 
@@ -44,7 +47,7 @@ func newBatchEncodeWorker(t *testing.T) (*worker, dmlproducer.DMLProducer) {
 	cfg := config.GetDefaultReplicaConfig()
 	cfg.Sink.EncoderConcurrency = &encoderConcurrency
 	encoderGroup := codec.NewEncoderGroup(cfg.Sink, encoderBuilder, id)
-	return newWorker(id, config.ProtocolOpen, p, encoderGroup), p
+	return newWorker(id, config.ProtocolOpen, p, encoderGroup, nil), p
 }
 
 func newNonBatchEncodeWorker(t *testing.T) (*worker, dmlproducer.DMLProducer) {
@@ -59,7 +62,7 @@ func newNonBatchEncodeWorker(t *testing.T) (*worker, dmlproducer.DMLProducer) {
 	cfg := config.GetDefaultReplicaConfig()
 	cfg.Sink.EncoderConcurrency = &encoderConcurrency
 	encoderGroup := codec.NewEncoderGroup(cfg.Sink, encoderBuilder, id)
-	return newWorker(id, config.ProtocolOpen, p, encoderGroup), p
+	return newWorker(id, config.ProtocolOpen, p, encoderGroup, nil), p
 }
 
 func TestNonBatchEncode_SendMessages(t *testing.T) {
@@ -465,6 +468,91 @@ func TestBatchEncode_SendMessages(t *testing.T) {
 	wg.Wait()
 }
 
+func TestBatchEncode_SendMappingMarkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	worker, p := newBatchEncodeWorker(t)
+	defer worker.close()
+
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	job := helper.DDL2Job(`create table test.t(a varchar(255) primary key)`)
+	tableInfo := model.WrapTableInfo(0, "test", 1, job.BinlogInfo.TableInfo)
+
+	tableStatus := state.TableSinkSinking
+	newEvent := func(partition int32, commitTs uint64, value string) mqEvent {
+		return mqEvent{
+			key: model.TopicPartitionKey{Topic: "test", Partition: partition},
+			rowEvent: &dmlsink.RowChangeCallbackableEvent{
+				Event: &model.RowChangedEvent{
+					CommitTs:  commitTs,
+					TableInfo: tableInfo,
+					Columns:   model.Columns2ColumnDatas([]*model.Column{{Name: "a", Value: value}}, tableInfo),
+				},
+				Callback:  func() {},
+				SinkState: &tableStatus,
+			},
+		}
+	}
+	// the markers are control messages of the simple protocol.
+	controlConfig := common.NewConfig(config.ProtocolSimple)
+	controlBuilder, err := simple.NewBuilder(ctx, controlConfig)
+	require.NoError(t, err)
+	markers, err := newPartitionMappingMarkers(controlBuilder.Build().(codec.ControlMessageEncoder), "test", &partition.MappingChange{
+		Schema:       "test",
+		Table:        "t",
+		CommitTs:     2,
+		PartitionNum: 2,
+		Previous:     []int32{0},
+		Current:      []int32{0, 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, markers, 2)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = worker.run(ctx)
+	}()
+
+	worker.msgChan.In() <- newEvent(0, 1, "aa")
+	for _, marker := range markers {
+		worker.msgChan.In() <- marker
+	}
+	worker.msgChan.In() <- newEvent(0, 2, "bb")
+	worker.msgChan.In() <- newEvent(1, 2, "cc")
+
+	mp := p.(*dmlproducer.MockDMLProducer)
+	require.Eventually(t, func() bool {
+		return len(mp.GetAllEvents()) == 5
+	}, 3*time.Second, 100*time.Millisecond)
+
+	// the markers are kept in order with the rows of the same partition.
+	events := mp.GetEvents("test", 0)
+	require.Len(t, events, 3)
+	require.Equal(t, model.MessageTypeRow, events[0].Type)
+	require.Equal(t, PartitionMappingMarkerKey, string(events[1].Key))
+	require.Equal(t, model.MessageTypeRow, events[2].Type)
+	events = mp.GetEvents("test", 1)
+	require.Len(t, events, 2)
+	require.Equal(t, PartitionMappingMarkerKey, string(events[0].Key))
+	require.Equal(t, model.MessageTypeRow, events[1].Type)
+
+	decoder, err := simple.NewDecoder(ctx, controlConfig, nil)
+	require.NoError(t, err)
+	key, value, err := decoder.DecodeControlMessage(events[0].Value)
+	require.NoError(t, err)
+	require.Equal(t, PartitionMappingMarkerKey, key)
+	var change partition.MappingChange
+	require.NoError(t, json.Unmarshal(value, &change))
+	require.Equal(t, []int32{0, 1}, change.Current)
+
+	cancel()
+	wg.Wait()
+}
+
 func TestBatchEncodeWorker_Abort(t *testing.T) {
 	t.Parallel()
 
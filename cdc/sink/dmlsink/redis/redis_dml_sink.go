@@ -0,0 +1,306 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columnselector"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columntransformer"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/cdc/sink/util"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/builder"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	predis "github.com/pingcap/tiflow/pkg/sink/redis"
+	putil "github.com/pingcap/tiflow/pkg/util"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Assert EventSink[E event.TableEvent] implementation
+var _ dmlsink.EventSink[*model.SingleTableTxn] = (*DMLSink)(nil)
+
+// DMLSink is the redis sink.
+// It either appends the encoded row changes to a stream per table,
+// or applies the row changes to the hashes keyed by the handle key.
+// The data flow is as follows: **data** -> workers -> redis
+// The rows are dispatched to the workers by the partition dispatchers,
+// so the rows of the same key are always written by the same worker in order.
+type DMLSink struct {
+	changefeedID model.ChangeFeedID
+	scheme       string
+	client       redis.UniversalClient
+	workers      []*worker
+
+	alive struct {
+		sync.RWMutex
+
+		transformer transformer.Transformer
+		// eventRouter is used to dispatch the rows to the workers.
+		eventRouter *dispatcher.EventRouter
+		isDead      bool
+	}
+
+	statistics *metrics.Statistics
+
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	dead   chan struct{}
+}
+
+// NewDMLSink creates a redis sink.
+func NewDMLSink(ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+	errCh chan error,
+) (*DMLSink, error) {
+	cfg := predis.NewConfig()
+	if err := cfg.Apply(sinkURI); err != nil {
+		return nil, err
+	}
+	var (
+		protocol       config.Protocol
+		encoderBuilder codec.RowEventEncoderBuilder
+	)
+	if cfg.Mode == predis.ModeStream {
+		encoderConfig, err := NewEncoderConfig(changefeedID, sinkURI, replicaConfig)
+		if err != nil {
+			return nil, err
+		}
+		protocol = encoderConfig.Protocol
+		encoderBuilder, err = builder.NewRowEventEncoderBuilder(ctx, encoderConfig)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrRedisSinkInvalidConfig, err)
+		}
+	}
+	scheme := strings.ToLower(sinkURI.Scheme)
+	eventRouter, err := dispatcher.NewEventRouter(replicaConfig, protocol, "", scheme)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	selector, err := columnselector.New(replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	columnTransformer, err := columntransformer.New(replicaConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	client := redis.NewClient(cfg.Options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	s := &DMLSink{
+		changefeedID: changefeedID,
+		scheme:       scheme,
+		client:       client,
+		workers:      make([]*worker, cfg.WorkerCount),
+		statistics:   metrics.NewStatistics(changefeedID, sink.RowSink),
+		cancel:       cancel,
+		dead:         make(chan struct{}),
+	}
+	s.alive.transformer = transformer.Chain{selector, columnTransformer}
+	s.alive.eventRouter = eventRouter
+	for i := 0; i < cfg.WorkerCount; i++ {
+		s.workers[i] = newWorker(i, changefeedID, cfg, client, encoderBuilder, s.statistics)
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		err := s.run(ctx)
+
+		s.alive.Lock()
+		s.alive.isDead = true
+		for _, w := range s.workers {
+			w.inputCh.CloseAndDrain()
+		}
+		s.alive.Unlock()
+		close(s.dead)
+
+		if err != nil {
+			if errors.Cause(err) == context.Canceled {
+				err = context.Cause(ctx)
+			}
+			if err == nil {
+				return
+			}
+			select {
+			case errCh <- err:
+				log.Warn("redis dml sink meet error",
+					zap.String("namespace", s.changefeedID.Namespace),
+					zap.String("changefeed", s.changefeedID.ID),
+					zap.Error(err))
+			default:
+				log.Info("redis dml sink meet error, ignored",
+					zap.String("namespace", s.changefeedID.Namespace),
+					zap.String("changefeed", s.changefeedID.ID),
+					zap.Error(err))
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// NewEncoderConfig returns the encoder config of the stream mode,
+// only the protocols which encode events as JSON objects are supported.
+func NewEncoderConfig(
+	changefeedID model.ChangeFeedID,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+) (*common.Config, error) {
+	protocol, err := util.GetProtocol(putil.GetOrZero(replicaConfig.Sink.Protocol))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if !predis.IsSupportedProtocol(protocol) {
+		return nil, cerror.ErrRedisSinkInvalidConfig.GenWithStack(
+			"unsupported protocol %s, redis sink only supports "+
+				"[canal-json, open-protocol, simple, debezium, maxwell]", protocol)
+	}
+	encoderConfig, err := util.GetEncoderConfig(
+		changefeedID, sinkURI, protocol, replicaConfig, config.DefaultMaxMessageBytes)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if encoderConfig.EncodingFormat != common.EncodingFormatJSON {
+		return nil, cerror.ErrRedisSinkInvalidConfig.GenWithStack(
+			"unsupported encoding format %s, redis sink only supports json",
+			encoderConfig.EncodingFormat)
+	}
+	return encoderConfig, nil
+}
+
+func (s *DMLSink) run(ctx context.Context) error {
+	eg, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < len(s.workers); i++ {
+		worker := s.workers[i]
+		eg.Go(func() error {
+			return worker.run(ctx)
+		})
+	}
+
+	log.Info("redis dml worker started", zap.String("namespace", s.changefeedID.Namespace),
+		zap.String("changefeed", s.changefeedID.ID),
+		zap.Int("workerCount", len(s.workers)))
+
+	return eg.Wait()
+}
+
+// WriteEvents writes events to the sink.
+// This is an asynchronously and thread-safe method.
+func (s *DMLSink) WriteEvents(txns ...*dmlsink.CallbackableEvent[*model.SingleTableTxn]) error {
+	s.alive.RLock()
+	defer s.alive.RUnlock()
+	if s.alive.isDead {
+		return errors.Trace(errors.New("dead dmlSink"))
+	}
+	// Because we split txn to rows when dispatching them to the workers.
+	// So we need to convert the txn level callback to row level callback.
+	toRowCallback := func(txnCallback func(), totalCount uint64) func() {
+		var calledCount atomic.Uint64
+		// The callback of the last row will trigger the callback of the txn.
+		return func() {
+			if calledCount.Inc() == totalCount {
+				txnCallback()
+			}
+		}
+	}
+
+	partitionNum := int32(len(s.workers))
+	for _, txn := range txns {
+		if txn.GetTableSinkState() != state.TableSinkSinking {
+			// The table where the event comes from is in stopping, so it's safe
+			// to drop the event directly.
+			txn.Callback()
+			continue
+		}
+		s.statistics.ObserveRows(txn.Event.Rows...)
+		rowCallback := toRowCallback(txn.Callback, uint64(len(txn.Event.Rows)))
+		for _, row := range txn.Event.Rows {
+			err := s.alive.transformer.Apply(row)
+			if err != nil {
+				s.cancel(err)
+				return errors.Trace(err)
+			}
+			// Note: Calculate the partition index after the transformer is applied.
+			// Because the transformer may change the row of the event.
+			index, _, err := s.alive.eventRouter.GetPartitionForRowChange(row, partitionNum)
+			if err != nil {
+				s.cancel(err)
+				return errors.Trace(err)
+			}
+			s.workers[index].inputCh.In() <- &dmlsink.RowChangeCallbackableEvent{
+				Event:     row,
+				Callback:  rowCallback,
+				SinkState: txn.SinkState,
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes the sink.
+func (s *DMLSink) Close() {
+	if s.cancel != nil {
+		s.cancel(nil)
+	}
+	s.wg.Wait()
+
+	if s.client != nil {
+		if err := s.client.Close(); err != nil {
+			log.Warn("close redis client failed",
+				zap.String("namespace", s.changefeedID.Namespace),
+				zap.String("changefeed", s.changefeedID.ID),
+				zap.Error(err))
+		}
+	}
+	if s.statistics != nil {
+		s.statistics.Close()
+	}
+}
+
+// Dead checks whether it's dead or not.
+func (s *DMLSink) Dead() <-chan struct{} {
+	return s.dead
+}
+
+// SchemeOption returns the scheme and the option.
+// The update events which change the unique key are always split, so the
+// delete and the insert are dispatched by the old and the new key respectively.
+func (s *DMLSink) SchemeOption() (string, bool) {
+	return s.scheme, false
+}
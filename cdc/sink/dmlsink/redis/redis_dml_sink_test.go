@@ -0,0 +1,220 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTableInfo() *model.TableInfo {
+	pk := types.NewFieldType(mysql.TypeLong)
+	pk.SetFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+	return model.WrapTableInfo(100, "test", 33, &timodel.TableInfo{
+		ID:         20,
+		Name:       pmodel.NewCIStr("table1"),
+		PKIsHandle: true,
+		Columns: []*timodel.ColumnInfo{
+			{ID: 1, Name: pmodel.NewCIStr("c1"), FieldType: *pk},
+			{ID: 2, Name: pmodel.NewCIStr("c2"), FieldType: *types.NewFieldType(mysql.TypeVarchar)},
+		},
+	})
+}
+
+func newRow(tableInfo *model.TableInfo, commitTs uint64, pre, post []interface{}) *model.RowChangedEvent {
+	toColumns := func(values []interface{}) []*model.ColumnData {
+		if values == nil {
+			return nil
+		}
+		return []*model.ColumnData{
+			{ColumnID: 1, Value: values[0]},
+			{ColumnID: 2, Value: values[1]},
+		}
+	}
+	return &model.RowChangedEvent{
+		CommitTs:        commitTs,
+		PhysicalTableID: 20,
+		TableInfo:       tableInfo,
+		PreColumns:      toColumns(pre),
+		Columns:         toColumns(post),
+	}
+}
+
+func newTxn(
+	cnt *uint64, tableStatus *state.TableSinkState, rows ...*model.RowChangedEvent,
+) *dmlsink.TxnCallbackableEvent {
+	return &dmlsink.TxnCallbackableEvent{
+		Event: &model.SingleTableTxn{
+			PhysicalTableID: 20,
+			CommitTs:        rows[0].CommitTs,
+			TableInfo:       rows[0].TableInfo,
+			Rows:            rows,
+		},
+		Callback: func() {
+			atomic.AddUint64(cnt, 1)
+		},
+		SinkState: tableStatus,
+	}
+}
+
+func newSink(
+	ctx context.Context, t *testing.T, addr string, params string,
+) (*DMLSink, chan error) {
+	sinkURI, err := url.Parse(fmt.Sprintf("redis://%s/0?%s", addr, params))
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.Protocol = util.AddressOf(config.ProtocolCanalJSON.String())
+	replicaConfig.Sink.DispatchRules = []*config.DispatchRule{
+		{Matcher: []string{"test.*"}, PartitionRule: "index-value"},
+	}
+	require.NoError(t, replicaConfig.ValidateAndAdjust(sinkURI))
+	errCh := make(chan error, 1)
+	s, err := NewDMLSink(ctx, model.DefaultChangeFeedID("test"), sinkURI, replicaConfig, errCh)
+	require.NoError(t, err)
+	return s, errCh
+}
+
+func TestRedisStreamMode(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, _ := newSink(ctx, t, mr.Addr(), "protocol=canal-json&key-prefix=cdc:&worker-count=1")
+	defer s.Close()
+
+	var cnt uint64
+	tableStatus := state.TableSinkSinking
+	tableInfo := newTableInfo()
+	require.NoError(t, s.WriteEvents(
+		newTxn(&cnt, &tableStatus,
+			newRow(tableInfo, 100, nil, []interface{}{int64(1), []byte("a")}),
+			newRow(tableInfo, 100, nil, []interface{}{int64(2), []byte("b")})),
+		newTxn(&cnt, &tableStatus,
+			newRow(tableInfo, 101, []interface{}{int64(1), []byte("a")}, nil)),
+	))
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 2
+	}, 10*time.Second, 100*time.Millisecond)
+
+	entries, err := mr.Stream("cdc:test:table1")
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for i, typ := range []string{"INSERT", "INSERT", "DELETE"} {
+		require.Equal(t, "value", entries[i].Values[0])
+		require.Contains(t, entries[i].Values[1], fmt.Sprintf(`"type":"%s"`, typ))
+	}
+}
+
+func TestRedisKVMode(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, _ := newSink(ctx, t, mr.Addr(), "mode=kv&worker-count=4&max-batch-size=2")
+	defer s.Close()
+
+	var cnt uint64
+	tableStatus := state.TableSinkSinking
+	tableInfo := newTableInfo()
+	require.NoError(t, s.WriteEvents(
+		newTxn(&cnt, &tableStatus,
+			newRow(tableInfo, 100, nil, []interface{}{int64(1), []byte("a")}),
+			newRow(tableInfo, 100, nil, []interface{}{int64(2), []byte("b")}),
+			newRow(tableInfo, 100, nil, []interface{}{int64(3), []byte("c")})),
+		newTxn(&cnt, &tableStatus,
+			// update the non-key column.
+			newRow(tableInfo, 101, []interface{}{int64(1), []byte("a")}, []interface{}{int64(1), nil})),
+		newTxn(&cnt, &tableStatus,
+			// update the key, which is split into a delete and an insert.
+			newRow(tableInfo, 102, []interface{}{int64(2), []byte("b")}, nil),
+			newRow(tableInfo, 102, nil, []interface{}{int64(4), []byte("b")})),
+		newTxn(&cnt, &tableStatus,
+			newRow(tableInfo, 103, []interface{}{int64(3), []byte("c")}, nil)),
+	))
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 4
+	}, 10*time.Second, 100*time.Millisecond)
+
+	require.ElementsMatch(t, []string{"test:table1:1", "test:table1:4"}, mr.Keys())
+	require.Equal(t, map[string]string{"c1": "1"}, hgetall(t, mr, "test:table1:1"))
+	require.Equal(t, map[string]string{"c1": "4", "c2": "b"}, hgetall(t, mr, "test:table1:4"))
+
+	// the update which changes the key is handled even if it is not split.
+	require.NoError(t, s.WriteEvents(newTxn(&cnt, &tableStatus,
+		newRow(tableInfo, 104, []interface{}{int64(4), []byte("b")}, []interface{}{int64(5), []byte("b")}))))
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 5
+	}, 10*time.Second, 100*time.Millisecond)
+	require.ElementsMatch(t, []string{"test:table1:1", "test:table1:5"}, mr.Keys())
+}
+
+func TestRedisKVModeWithoutHandleKey(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, errCh := newSink(ctx, t, mr.Addr(), "mode=kv")
+	defer s.Close()
+
+	tableInfo := model.WrapTableInfo(100, "test", 33, &timodel.TableInfo{
+		ID:   20,
+		Name: pmodel.NewCIStr("table1"),
+		Columns: []*timodel.ColumnInfo{
+			{ID: 1, Name: pmodel.NewCIStr("c1"), FieldType: *types.NewFieldType(mysql.TypeLong)},
+			{ID: 2, Name: pmodel.NewCIStr("c2"), FieldType: *types.NewFieldType(mysql.TypeVarchar)},
+		},
+	})
+	var cnt uint64
+	tableStatus := state.TableSinkSinking
+	require.NoError(t, s.WriteEvents(newTxn(&cnt, &tableStatus,
+		newRow(tableInfo, 100, nil, []interface{}{int64(1), []byte("a")}))))
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, cerror.ErrRedisSinkInvalidConfig)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the error is not reported")
+	}
+	require.Equal(t, uint64(0), atomic.LoadUint64(&cnt))
+	require.Empty(t, mr.Keys())
+}
+
+func hgetall(t *testing.T, mr *miniredis.Miniredis, key string) map[string]string {
+	fields, err := mr.HKeys(key)
+	require.NoError(t, err)
+	result := make(map[string]string, len(fields))
+	for _, field := range fields {
+		result[field] = mr.HGet(key, field)
+	}
+	return result
+}
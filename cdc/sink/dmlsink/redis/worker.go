@@ -0,0 +1,265 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/chann"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	predis "github.com/pingcap/tiflow/pkg/sink/redis"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// worker writes the row changes dispatched to it in batches, each batch is
+// written in one pipeline and the callbacks are called after the pipeline
+// is executed successfully.
+type worker struct {
+	id           int
+	changeFeedID model.ChangeFeedID
+	cfg          *predis.Config
+	client       redis.Cmdable
+	// encoderBuilder is only used by the stream mode.
+	encoderBuilder codec.RowEventEncoderBuilder
+	inputCh        *chann.DrainableChann[*dmlsink.RowChangeCallbackableEvent]
+	statistics     *metrics.Statistics
+}
+
+func newWorker(
+	id int,
+	changefeedID model.ChangeFeedID,
+	cfg *predis.Config,
+	client redis.Cmdable,
+	encoderBuilder codec.RowEventEncoderBuilder,
+	statistics *metrics.Statistics,
+) *worker {
+	return &worker{
+		id:             id,
+		changeFeedID:   changefeedID,
+		cfg:            cfg,
+		client:         client,
+		encoderBuilder: encoderBuilder,
+		inputCh:        chann.NewAutoDrainChann[*dmlsink.RowChangeCallbackableEvent](),
+		statistics:     statistics,
+	}
+}
+
+func (w *worker) run(ctx context.Context) error {
+	log.Debug("redis worker started", zap.Int("workerID", w.id),
+		zap.String("namespace", w.changeFeedID.Namespace),
+		zap.String("changefeed", w.changeFeedID.ID))
+
+	events := make([]*dmlsink.RowChangeCallbackableEvent, 0, w.cfg.MaxBatchSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Trace(ctx.Err())
+		case event, ok := <-w.inputCh.Out():
+			if !ok {
+				log.Info("redis worker exited", zap.Int("workerID", w.id),
+					zap.String("namespace", w.changeFeedID.Namespace),
+					zap.String("changefeed", w.changeFeedID.ID))
+				return nil
+			}
+			events = append(events, event)
+		}
+		// Batch the events which are already buffered.
+	drain:
+		for len(events) < w.cfg.MaxBatchSize {
+			select {
+			case event, ok := <-w.inputCh.Out():
+				if !ok {
+					break drain
+				}
+				events = append(events, event)
+			default:
+				break drain
+			}
+		}
+
+		if err := w.writeEvents(ctx, events); err != nil {
+			return errors.Trace(err)
+		}
+		for i := range events {
+			events[i] = nil
+		}
+		events = events[:0]
+	}
+}
+
+func (w *worker) writeEvents(ctx context.Context, events []*dmlsink.RowChangeCallbackableEvent) error {
+	rows := make([]*dmlsink.RowChangeCallbackableEvent, 0, len(events))
+	for _, event := range events {
+		// Skip this event when the table is stopping.
+		if event.GetTableSinkState() != state.TableSinkSinking {
+			event.Callback()
+			log.Debug("Skip event of stopped table", zap.Any("event", event))
+			continue
+		}
+		rows = append(rows, event)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	var callbacks []func()
+	err := w.statistics.RecordBatchExecution(func() (int, int64, error) {
+		var (
+			size int64
+			err  error
+		)
+		if w.cfg.Mode == predis.ModeKV {
+			callbacks, size, err = w.applyRows(ctx, rows)
+		} else {
+			callbacks, size, err = w.appendRows(ctx, rows)
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		return len(rows), size, nil
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, callback := range callbacks {
+		callback()
+	}
+	return nil
+}
+
+// appendRows encodes the rows and appends the messages to the table streams.
+func (w *worker) appendRows(
+	ctx context.Context, rows []*dmlsink.RowChangeCallbackableEvent,
+) ([]func(), int64, error) {
+	// Group the rows by stream, the order of the rows in each stream is kept.
+	var streams []string
+	groups := make(map[string][]*dmlsink.RowChangeCallbackableEvent)
+	for _, row := range rows {
+		stream := predis.StreamKey(w.cfg.KeyPrefix,
+			row.Event.TableInfo.GetSchemaName(), row.Event.TableInfo.GetTableName())
+		if _, ok := groups[stream]; !ok {
+			streams = append(streams, stream)
+		}
+		groups[stream] = append(groups[stream], row)
+	}
+
+	var (
+		size      int64
+		callbacks []func()
+	)
+	pipe := w.client.Pipeline()
+	for _, stream := range streams {
+		encoder := w.encoderBuilder.Build()
+		for _, row := range groups[stream] {
+			err := encoder.AppendRowChangedEvent(ctx, stream, row.Event, row.Callback)
+			if err != nil {
+				return nil, 0, errors.Trace(err)
+			}
+		}
+		for _, msg := range encoder.Build() {
+			values := make([]interface{}, 0, 4)
+			if len(msg.Key) > 0 {
+				values = append(values, "key", msg.Key)
+			}
+			values = append(values, "value", msg.Value)
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: stream,
+				MaxLen: w.cfg.StreamMaxLen,
+				Approx: w.cfg.StreamMaxLen > 0,
+				Values: values,
+			})
+			size += int64(msg.Length())
+			if msg.Callback != nil {
+				callbacks = append(callbacks, msg.Callback)
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+	}
+	return callbacks, size, nil
+}
+
+// applyRows applies the rows to the hashes in one transaction.
+// Each row is stored as a hash keyed by its handle key, so
+//   - DELETE removes the key,
+//   - INSERT and UPDATE replace the whole hash, the NULL columns are omitted,
+//   - UPDATE which changes the handle key removes the old key as well.
+func (w *worker) applyRows(
+	ctx context.Context, rows []*dmlsink.RowChangeCallbackableEvent,
+) ([]func(), int64, error) {
+	var size int64
+	callbacks := make([]func(), 0, len(rows))
+	pipe := w.client.TxPipeline()
+	for _, row := range rows {
+		event := row.Event
+		schema, table := event.TableInfo.GetSchemaName(), event.TableInfo.GetTableName()
+		if len(event.PreColumns) > 0 {
+			handleKeyValues, err := getHandleKeyValues(event.TableInfo, event.PreColumns)
+			if err != nil {
+				return nil, 0, errors.Trace(err)
+			}
+			pipe.Del(ctx, predis.RowKey(w.cfg.KeyPrefix, schema, table, handleKeyValues))
+		}
+		if len(event.Columns) > 0 {
+			handleKeyValues, err := getHandleKeyValues(event.TableInfo, event.Columns)
+			if err != nil {
+				return nil, 0, errors.Trace(err)
+			}
+			key := predis.RowKey(w.cfg.KeyPrefix, schema, table, handleKeyValues)
+			fields := make([]interface{}, 0, 2*len(event.Columns))
+			for _, col := range event.Columns {
+				if col == nil || col.Value == nil {
+					continue
+				}
+				fields = append(fields,
+					event.TableInfo.ForceGetColumnName(col.ColumnID), model.ColumnValueString(col.Value))
+			}
+			if len(event.PreColumns) == 0 {
+				pipe.Del(ctx, key)
+			}
+			pipe.HSet(ctx, key, fields...)
+		}
+		size += int64(event.ApproximateBytes())
+		callbacks = append(callbacks, row.Callback)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, 0, cerror.WrapError(cerror.ErrRedisSinkWriteFailed, err)
+	}
+	return callbacks, size, nil
+}
+
+// getHandleKeyValues returns the values of the handle key columns in the row.
+func getHandleKeyValues(tableInfo *model.TableInfo, cols []*model.ColumnData) ([]string, error) {
+	values := make([]string, 0, 1)
+	for _, col := range cols {
+		if col != nil && tableInfo.ForceGetColumnFlagType(col.ColumnID).IsHandleKey() {
+			values = append(values, model.ColumnValueString(col.Value))
+		}
+	}
+	if len(values) == 0 {
+		return nil, cerror.ErrRedisSinkInvalidConfig.GenWithStack(
+			"table %s has no handle key, it can't be written in %s mode",
+			tableInfo.TableName.String(), predis.ModeKV)
+	}
+	return values, nil
+}
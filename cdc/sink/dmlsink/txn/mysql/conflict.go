@@ -0,0 +1,489 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/metrics/txn"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/quotes"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	conflictResolutionApplied = "applied"
+	conflictResolutionSkipped = "skipped"
+)
+
+// conflictRecord is a row conflicted with the downstream row, it's recorded
+// in the conflict table or the conflict file for audit.
+type conflictRecord struct {
+	Changefeed string                 `json:"changefeed"`
+	Schema     string                 `json:"schema"`
+	Table      string                 `json:"table"`
+	Type       string                 `json:"type"`
+	CommitTs   uint64                 `json:"commit-ts"`
+	Key        map[string]interface{} `json:"key"`
+	Row        map[string]interface{} `json:"row"`
+	Policy     string                 `json:"policy"`
+	Resolution string                 `json:"resolution"`
+	DetectedAt time.Time              `json:"detected-at"`
+}
+
+// conflictResolver detects the rows conflicted with the downstream rows, which
+// are written by the downstream cluster concurrently in the BDR mode.
+//
+// Before a row is written, the downstream row with the same handle key is locked
+// and its version column is compared with the version of the pre-image. A row is
+// conflicted if the downstream row is changed after the pre-image, that is:
+//   - for an insert, the downstream row exists with a different version;
+//   - for an update, the downstream row is missing or its version is neither the
+//     pre-image's nor the post-image's;
+//   - for a delete, the downstream row exists with a version different from the pre-image's.
+//
+// A conflicted row is either applied as a whole or skipped by the policy.
+type conflictResolver struct {
+	changefeedID model.ChangeFeedID
+	cfg          *config.ConflictResolutionConfig
+	// conflictTable is the quoted conflict table, it's empty if not set.
+	conflictTable string
+	now           func() time.Time
+
+	fileMu sync.Mutex
+	file   *os.File
+
+	// undetectedTables records the tables whose rows are replicated without
+	// the conflict detection, they are logged once.
+	undetectedTables sync.Map // map[model.TableName]struct{}
+
+	// count is the number of the conflicted rows committed by the resolver.
+	count atomic.Uint64
+	// refs is the number of the backends sharing the resolver,
+	// it's closed once all of them are closed.
+	refs atomic.Int32
+
+	metricApplied prometheus.Counter
+	metricSkipped prometheus.Counter
+}
+
+func newConflictResolver(
+	ctx context.Context,
+	changefeedID model.ChangeFeedID,
+	cfg *config.ConflictResolutionConfig,
+	db *sql.DB,
+) (*conflictResolver, error) {
+	r := &conflictResolver{
+		changefeedID: changefeedID,
+		cfg:          cfg,
+		now:          time.Now,
+		metricApplied: txn.RowConflicts.WithLabelValues(
+			changefeedID.Namespace, changefeedID.ID, conflictResolutionApplied),
+		metricSkipped: txn.RowConflicts.WithLabelValues(
+			changefeedID.Namespace, changefeedID.ID, conflictResolutionSkipped),
+	}
+	if schema, table := cfg.ConflictTableName(); schema != "" {
+		r.conflictTable = quotes.QuoteSchema(schema, table)
+		if err := createConflictTable(ctx, db, schema, r.conflictTable); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.ConflictFile != "" {
+		file, err := os.OpenFile(cfg.ConflictFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrMySQLInvalidConfig, err)
+		}
+		r.file = file
+	}
+	log.Info("conflict resolution is enabled",
+		zap.String("namespace", changefeedID.Namespace),
+		zap.String("changefeed", changefeedID.ID),
+		zap.String("policy", cfg.Policy),
+		zap.String("versionColumn", cfg.VersionColumn),
+		zap.String("priority", cfg.Priority),
+		zap.String("conflictTable", cfg.ConflictTable),
+		zap.String("conflictFile", cfg.ConflictFile))
+	return r, nil
+}
+
+func createConflictTable(ctx context.Context, db *sql.DB, schema, quoteTable string) error {
+	queries := []string{
+		"CREATE DATABASE IF NOT EXISTS " + quotes.QuoteName(schema),
+		"CREATE TABLE IF NOT EXISTS " + quoteTable + ` (
+	id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+	changefeed VARCHAR(255) NOT NULL,
+	source_schema VARCHAR(255) NOT NULL,
+	source_table VARCHAR(255) NOT NULL,
+	row_type VARCHAR(16) NOT NULL,
+	commit_ts BIGINT UNSIGNED NOT NULL,
+	row_key TEXT,
+	incoming_row LONGTEXT,
+	policy VARCHAR(32) NOT NULL,
+	resolution VARCHAR(16) NOT NULL,
+	detected_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+)`,
+	}
+	for _, query := range queries {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return cerror.WrapError(cerror.ErrMySQLTxnError,
+				errors.WithMessage(err, "fail to create the conflict table"))
+		}
+	}
+	return nil
+}
+
+// resolveRow returns the statements to write the row, and the conflict record if
+// the row is conflicted. The downstream row is locked until the transaction ends.
+func (r *conflictResolver) resolveRow(
+	ctx context.Context, tx *sql.Tx, row *model.RowChangedEvent, forceReplicate bool,
+) (sqls []string, values [][]interface{}, record *conflictRecord, err error) {
+	quoteTable := row.TableInfo.TableName.QuoteString()
+	preCols, cols := row.GetPreColumns(), row.GetColumns()
+	rowType, keyCols := "update", preCols
+	switch {
+	case len(preCols) == 0:
+		rowType, keyCols = "insert", cols
+	case len(cols) == 0:
+		rowType = "delete"
+	}
+
+	keyNames, keyArgs := whereSlice(keyCols, false)
+	preVersion, hasPreVersion := findColumn(preCols, r.cfg.VersionColumn)
+	version, hasVersion := findColumn(cols, r.cfg.VersionColumn)
+	switch rowType {
+	case "insert":
+		preVersion, hasPreVersion = version, hasVersion
+	case "delete":
+		version, hasVersion = preVersion, hasPreVersion
+	}
+	// The rows can't be located, or the version is unknown, write them directly.
+	if len(keyNames) == 0 || !hasPreVersion || !hasVersion {
+		r.logUndetectedTable(row.TableInfo.TableName, len(keyNames) == 0)
+		sqls, values = prepareRowDMLs(quoteTable, preCols, cols, forceReplicate)
+		return sqls, values, nil, nil
+	}
+
+	matched, same, older, exists, err := r.queryVersion(
+		ctx, tx, quoteTable, keyNames, keyArgs, preVersion, version)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var conflicted bool
+	switch rowType {
+	case "insert", "delete":
+		conflicted = exists && !matched
+	default:
+		conflicted = !exists || !(matched || same)
+	}
+	if !conflicted {
+		sqls, values = prepareRowDMLs(quoteTable, preCols, cols, forceReplicate)
+		return sqls, values, nil, nil
+	}
+
+	record = &conflictRecord{
+		Changefeed: r.changefeedID.ID,
+		Schema:     row.TableInfo.GetSchemaName(),
+		Table:      row.TableInfo.GetTableName(),
+		Type:       rowType,
+		CommitTs:   row.CommitTs,
+		Key:        columnsToMap(keyCols, true),
+		Row:        columnsToMap(keyCols, false),
+		Policy:     r.cfg.Policy,
+		Resolution: conflictResolutionSkipped,
+		DetectedAt: r.now(),
+	}
+	if rowType != "delete" {
+		record.Row = columnsToMap(cols, false)
+	}
+	if !r.shouldApply(!exists || older) {
+		return nil, nil, record, nil
+	}
+	record.Resolution = conflictResolutionApplied
+	// The conflicted row is applied as a whole, regardless of the downstream row.
+	if rowType != "insert" {
+		sql, args := prepareDelete(quoteTable, preCols, false)
+		sqls, values = append(sqls, sql), append(values, args)
+	}
+	if rowType != "delete" {
+		sql, args := prepareReplace(quoteTable, cols, true, false)
+		sqls, values = append(sqls, sql), append(values, args)
+	}
+	return sqls, values, record, nil
+}
+
+// logUndetectedTable logs the table whose rows are replicated without the
+// conflict detection, each table is logged only once.
+func (r *conflictResolver) logUndetectedTable(name model.TableName, noHandleKey bool) {
+	if _, loaded := r.undetectedTables.LoadOrStore(name, struct{}{}); loaded {
+		return
+	}
+	log.Warn("the rows of the table are replicated without the conflict detection",
+		zap.String("namespace", r.changefeedID.Namespace),
+		zap.String("changefeed", r.changefeedID.ID),
+		zap.String("schema", name.Schema),
+		zap.String("table", name.Table),
+		zap.Bool("noHandleKey", noHandleKey),
+		zap.String("versionColumn", r.cfg.VersionColumn))
+}
+
+// queryVersion locks the downstream row and compares its version with the versions
+// of the pre-image and the post-image.
+func (r *conflictResolver) queryVersion(
+	ctx context.Context, tx *sql.Tx,
+	quoteTable string, keyNames []string, keyArgs []interface{},
+	preVersion, version interface{},
+) (matched, same, older, exists bool, err error) {
+	quoteColumn := quotes.QuoteName(r.cfg.VersionColumn)
+	var builder strings.Builder
+	builder.WriteString("SELECT " + quoteColumn + " <=> ?, " + quoteColumn + " <=> ?, ")
+	builder.WriteString(quoteColumn + " IS NULL OR " + quoteColumn + " <= ? FROM " + quoteTable + " WHERE ")
+	args := []interface{}{preVersion, version, version}
+	for i, name := range keyNames {
+		if i > 0 {
+			builder.WriteString(" AND ")
+		}
+		if keyArgs[i] == nil {
+			builder.WriteString(quotes.QuoteName(name) + " IS NULL")
+		} else {
+			builder.WriteString(quotes.QuoteName(name) + " = ?")
+			args = append(args, keyArgs[i])
+		}
+	}
+	builder.WriteString(" LIMIT 1 FOR UPDATE")
+
+	var isOlder sql.NullBool
+	err = tx.QueryRowContext(ctx, builder.String(), args...).Scan(&matched, &same, &isOlder)
+	if err == sql.ErrNoRows {
+		return false, false, false, false, nil
+	}
+	if err != nil {
+		return false, false, false, false, errors.Trace(err)
+	}
+	return matched, same, isOlder.Valid && isOlder.Bool, true, nil
+}
+
+// shouldApply returns true if the incoming row wins the conflict.
+func (r *conflictResolver) shouldApply(newer bool) bool {
+	switch r.cfg.Policy {
+	case config.ConflictPolicyNewerVersionWins:
+		return newer
+	case config.ConflictPolicySourcePriority:
+		return r.cfg.Priority == config.ConflictPriorityUpstream
+	default:
+		return false
+	}
+}
+
+// prepareRecords returns the statements to insert the records into the conflict table.
+func (r *conflictResolver) prepareRecords(records []*conflictRecord) ([]string, [][]interface{}) {
+	if r.conflictTable == "" || len(records) == 0 {
+		return nil, nil
+	}
+	query := "INSERT INTO " + r.conflictTable + " (" + buildColumnList([]string{
+		"changefeed", "source_schema", "source_table", "row_type", "commit_ts",
+		"row_key", "incoming_row", "policy", "resolution",
+	}) + ") VALUES (" + placeHolder(9) + ")"
+	sqls := make([]string, 0, len(records))
+	values := make([][]interface{}, 0, len(records))
+	for _, record := range records {
+		key, _ := json.Marshal(record.Key)
+		row, _ := json.Marshal(record.Row)
+		sqls = append(sqls, query)
+		values = append(values, []interface{}{
+			record.Changefeed, record.Schema, record.Table, record.Type, record.CommitTs,
+			string(key), string(row), record.Policy, record.Resolution,
+		})
+	}
+	return sqls, values
+}
+
+// onCommitted is called after the transaction containing the records is committed.
+func (r *conflictResolver) onCommitted(records []*conflictRecord) {
+	if len(records) == 0 {
+		return
+	}
+	for _, record := range records {
+		if record.Resolution == conflictResolutionApplied {
+			r.metricApplied.Inc()
+		} else {
+			r.metricSkipped.Inc()
+		}
+		log.Warn("row conflicted with the downstream",
+			zap.String("namespace", r.changefeedID.Namespace),
+			zap.String("changefeed", r.changefeedID.ID),
+			zap.String("schema", record.Schema),
+			zap.String("table", record.Table),
+			zap.String("type", record.Type),
+			zap.Uint64("commitTs", record.CommitTs),
+			zap.Any("key", record.Key),
+			zap.String("resolution", record.Resolution))
+	}
+	r.count.Add(uint64(len(records)))
+
+	if r.file == nil {
+		return
+	}
+	var buf []byte
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			log.Warn("fail to marshal the conflict record", zap.Error(err))
+			continue
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+	r.fileMu.Lock()
+	defer r.fileMu.Unlock()
+	if _, err := r.file.Write(buf); err != nil {
+		log.Warn("fail to write the conflict file",
+			zap.String("namespace", r.changefeedID.Namespace),
+			zap.String("changefeed", r.changefeedID.ID),
+			zap.String("file", r.cfg.ConflictFile),
+			zap.Error(err))
+	}
+}
+
+// acquire is called by each backend sharing the resolver.
+func (r *conflictResolver) acquire() *conflictResolver {
+	r.refs.Add(1)
+	return r
+}
+
+// release is called once the backend is closed, the resolver is closed
+// after the last backend sharing it is closed.
+func (r *conflictResolver) release() {
+	if r.refs.Add(-1) > 0 {
+		return
+	}
+	txn.RowConflicts.DeletePartialMatch(map[string]string{
+		"namespace":  r.changefeedID.Namespace,
+		"changefeed": r.changefeedID.ID,
+	})
+	if r.file != nil {
+		r.fileMu.Lock()
+		defer r.fileMu.Unlock()
+		if err := r.file.Close(); err != nil {
+			log.Warn("fail to close the conflict file", zap.Error(err))
+		}
+	}
+}
+
+// conflictExecute executes the rows of the buffered events one by one in the
+// transaction, the rows conflicted with the downstream are resolved by the policy
+// and recorded in the conflict table in the same transaction.
+func (s *mysqlBackend) conflictExecute(
+	ctx context.Context, tx *sql.Tx, writeTimeout time.Duration,
+) ([]*conflictRecord, error) {
+	start := time.Now()
+	rollback := func(err error, query string) error {
+		err = logDMLTxnErr(wrapMysqlTxnError(err), start, s.changefeed, query, s.rows, nil)
+		if rbErr := tx.Rollback(); rbErr != nil {
+			if errors.Cause(rbErr) != context.Canceled {
+				log.Warn("failed to rollback txn", zap.String("changefeed", s.changefeed), zap.Error(rbErr))
+			}
+		}
+		return err
+	}
+	exec := func(query string, args []interface{}) error {
+		log.Debug("exec row", zap.String("changefeed", s.changefeed), zap.Int("workerID", s.workerID),
+			zap.String("sql", query), zap.Any("args", args))
+		ctx, cancel := context.WithTimeout(ctx, writeTimeout)
+		defer cancel()
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	var records []*conflictRecord
+	for _, event := range s.events {
+		for _, row := range event.Event.Rows {
+			queryCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+			sqls, values, record, err := s.conflictResolver.resolveRow(queryCtx, tx, row, s.cfg.ForceReplicate)
+			cancel()
+			if err != nil {
+				return nil, rollback(err, "SELECT ... FOR UPDATE")
+			}
+			if record != nil {
+				records = append(records, record)
+			}
+			for i, query := range sqls {
+				if err := exec(query, values[i]); err != nil {
+					return nil, rollback(err, query)
+				}
+			}
+		}
+	}
+	sqls, values := s.conflictResolver.prepareRecords(records)
+	for i, query := range sqls {
+		if err := exec(query, values[i]); err != nil {
+			return nil, rollback(err, query)
+		}
+	}
+	return records, nil
+}
+
+// prepareRowDMLs returns the statements to write a row without the conflict detection,
+// the insert is translated to REPLACE since the row may be written by the downstream.
+func prepareRowDMLs(
+	quoteTable string, preCols, cols []*model.Column, forceReplicate bool,
+) (sqls []string, values [][]interface{}) {
+	var query string
+	var args []interface{}
+	switch {
+	case len(preCols) != 0 && len(cols) != 0:
+		query, args = prepareUpdate(quoteTable, preCols, cols, forceReplicate)
+	case len(preCols) != 0:
+		query, args = prepareDelete(quoteTable, preCols, forceReplicate)
+	default:
+		query, args = prepareReplace(quoteTable, cols, true, false)
+	}
+	if query == "" {
+		return nil, nil
+	}
+	return []string{query}, [][]interface{}{args}
+}
+
+// findColumn returns the query argument of the column with the given name.
+func findColumn(cols []*model.Column, name string) (interface{}, bool) {
+	for _, col := range cols {
+		if col != nil && strings.EqualFold(col.Name, name) {
+			return appendQueryArgs(nil, col)[0], true
+		}
+	}
+	return nil, false
+}
+
+// columnsToMap returns the values of the columns, only the handle key
+// columns are returned if handleKeyOnly is true.
+func columnsToMap(cols []*model.Column, handleKeyOnly bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(cols))
+	for _, col := range cols {
+		if col == nil || (handleKeyOnly && !col.Flag.IsHandleKey()) {
+			continue
+		}
+		result[col.Name] = appendQueryArgs(nil, col)[0]
+	}
+	return result
+}
@@ -0,0 +1,173 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics/txn"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func newConflictTestRows() []*model.RowChangedEvent {
+	tableInfo := model.BuildTableInfo("s1", "t1", []*model.Column{
+		{Name: "a", Type: mysql.TypeLong, Flag: model.HandleKeyFlag | model.PrimaryKeyFlag},
+		{Name: "b", Type: mysql.TypeVarchar},
+		{Name: "v", Type: mysql.TypeLonglong},
+	}, [][]int{{0}})
+	row := func(a int, b string, v int) []*model.ColumnData {
+		return model.Columns2ColumnDatas([]*model.Column{
+			{Name: "a", Value: a}, {Name: "b", Value: b}, {Name: "v", Value: v},
+		}, tableInfo)
+	}
+	return []*model.RowChangedEvent{
+		// An update without conflict.
+		{CommitTs: 10, TableInfo: tableInfo, PreColumns: row(1, "x", 1), Columns: row(1, "y", 2)},
+		// An update conflicted with a newer downstream row.
+		{CommitTs: 11, TableInfo: tableInfo, PreColumns: row(2, "x", 1), Columns: row(2, "y", 2)},
+		// An insert conflicted with an older downstream row.
+		{CommitTs: 12, TableInfo: tableInfo, Columns: row(3, "z", 5)},
+		// A delete of a missing row.
+		{CommitTs: 13, TableInfo: tableInfo, PreColumns: row(4, "w", 3)},
+	}
+}
+
+func TestConflictResolutionNewerVersionWins(t *testing.T) {
+	conflictFile := filepath.Join(t.TempDir(), "conflicts.log")
+	cfg := &config.ConflictResolutionConfig{
+		Policy:        config.ConflictPolicyNewerVersionWins,
+		VersionColumn: "v",
+		ConflictTable: "audit.conflicts",
+		ConflictFile:  conflictFile,
+	}
+	require.NoError(t, cfg.ValidateAndAdjust())
+
+	changefeedID := model.DefaultChangeFeedID("test-conflict")
+	ctx := context.Background()
+	// The CREATE TABLE statement is long, so it's matched by the prefix.
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherFunc(
+		func(expected, actual string) error {
+			if strings.HasPrefix(actual, expected) {
+				return nil
+			}
+			return sqlmock.QueryMatcherEqual.Match(expected, actual)
+		})))
+	require.NoError(t, err)
+	defer db.Close()
+	mock.ExpectExec("CREATE DATABASE IF NOT EXISTS `audit`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS `audit`.`conflicts`").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	resolver, err := newConflictResolver(ctx, changefeedID, cfg, db)
+	require.NoError(t, err)
+	now := time.Now()
+	resolver.now = func() time.Time { return now }
+
+	selectSQL := "SELECT `v` <=> ?, `v` <=> ?, `v` IS NULL OR `v` <= ? FROM `s1`.`t1` WHERE `a` = ? LIMIT 1 FOR UPDATE"
+	columns := []string{"matched", "same", "older"}
+	mock.ExpectBegin()
+	mock.ExpectQuery(selectSQL).WithArgs(1, 2, 2, 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, false, true))
+	mock.ExpectExec("UPDATE `s1`.`t1` SET `a` = ?, `b` = ?, `v` = ? WHERE `a` = ? LIMIT 1").
+		WithArgs(1, "y", 2, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(selectSQL).WithArgs(1, 2, 2, 2).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(false, false, false))
+	mock.ExpectQuery(selectSQL).WithArgs(5, 5, 5, 3).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(false, false, true))
+	mock.ExpectExec("REPLACE INTO `s1`.`t1` (`a`,`b`,`v`) VALUES (?,?,?)").
+		WithArgs(3, "z", 5).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(selectSQL).WithArgs(3, 3, 3, 4).
+		WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectExec("DELETE FROM `s1`.`t1` WHERE `a` = ? LIMIT 1").
+		WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 0))
+	insertRecord := "INSERT INTO `audit`.`conflicts` (`changefeed`,`source_schema`,`source_table`," +
+		"`row_type`,`commit_ts`,`row_key`,`incoming_row`,`policy`,`resolution`) VALUES (?,?,?,?,?,?,?,?,?)"
+	mock.ExpectExec(insertRecord).
+		WithArgs("test-conflict", "s1", "t1", "update", uint64(11), `{"a":2}`,
+			`{"a":2,"b":"y","v":2}`, "newer-version-wins", "skipped").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(insertRecord).
+		WithArgs("test-conflict", "s1", "t1", "insert", uint64(12), `{"a":3}`,
+			`{"a":3,"b":"z","v":5}`, "newer-version-wins", "applied").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	backend := newMySQLBackendWithoutDB()
+	backend.db = db
+	backend.conflictResolver = resolver.acquire()
+	backend.conflictCount = &resolver.count
+	backend.dmlMaxRetry = 1
+	backend.metricTxnSinkDMLBatchCommit = txn.SinkDMLBatchCommit.WithLabelValues("default", "test-conflict")
+	backend.metricTxnSinkDMLBatchCallback = txn.SinkDMLBatchCallback.WithLabelValues("default", "test-conflict")
+	var flushed bool
+	backend.OnTxnEvent(&dmlsink.TxnCallbackableEvent{
+		Event:    &model.SingleTableTxn{Rows: newConflictTestRows()},
+		Callback: func() { flushed = true },
+	})
+	require.NoError(t, backend.Flush(ctx))
+	require.True(t, flushed)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.Equal(t, uint64(2), backend.ConflictCount())
+
+	data, err := os.ReadFile(conflictFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var record conflictRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	require.Equal(t, "insert", record.Type)
+	require.Equal(t, conflictResolutionApplied, record.Resolution)
+
+	// The resolver is shared by the backends, it's closed by the last one.
+	other := resolver.acquire()
+	mock.ExpectClose()
+	require.NoError(t, backend.Close())
+	require.Equal(t, uint64(2), backend.ConflictCount())
+	require.Equal(t, int32(1), resolver.refs.Load())
+	other.release()
+	require.Equal(t, int32(0), resolver.refs.Load())
+}
+
+func TestConflictResolverShouldApply(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		policy   string
+		priority string
+		newer    bool
+		apply    bool
+	}{
+		{config.ConflictPolicyNewerVersionWins, "", true, true},
+		{config.ConflictPolicyNewerVersionWins, "", false, false},
+		{config.ConflictPolicySourcePriority, config.ConflictPriorityUpstream, false, true},
+		{config.ConflictPolicySourcePriority, config.ConflictPriorityDownstream, true, false},
+		{config.ConflictPolicyLogAndSkip, "", true, false},
+	}
+	for _, c := range cases {
+		r := &conflictResolver{cfg: &config.ConflictResolutionConfig{Policy: c.policy, Priority: c.priority}}
+		require.Equal(t, c.apply, r.shouldApply(c.newer), "%+v", c)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"math"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	dmysql "github.com/go-sql-driver/mysql"
@@ -78,6 +79,12 @@ type mysqlBackend struct {
 	// Indicate if the CachePrepStmts should be enabled or not
 	cachePrepStmts   bool
 	maxAllowedPacket int64
+
+	// conflictResolver is nil if the conflicts are not detected, it's shared by all backends.
+	conflictResolver *conflictResolver
+	// conflictCount is the count of the shared conflict resolver, it's kept
+	// after the backend is closed, so the count is still readable.
+	conflictCount *atomic.Uint64
 }
 
 // NewMySQLBackends creates a new MySQL sink using schema storage
@@ -172,9 +179,17 @@ func NewMySQLBackends(
 		maxAllowedPacket = int64(variable.DefMaxAllowedPacket)
 	}
 
+	var resolver *conflictResolver
+	if cfg.ConflictResolution != nil {
+		resolver, err = newConflictResolver(ctx, changefeedID, cfg.ConflictResolution, db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	backends := make([]*mysqlBackend, 0, cfg.WorkerCount)
 	for i := 0; i < cfg.WorkerCount; i++ {
-		backends = append(backends, &mysqlBackend{
+		backend := &mysqlBackend{
 			workerID:    i,
 			changefeed:  changefeed,
 			db:          db,
@@ -188,7 +203,12 @@ func NewMySQLBackends(
 			stmtCache:                       stmtCache,
 			cachePrepStmts:                  cachePrepStmts,
 			maxAllowedPacket:                maxAllowedPacket,
-		})
+		}
+		if resolver != nil {
+			backend.conflictResolver = resolver.acquire()
+			backend.conflictCount = &resolver.count
+		}
+		backends = append(backends, backend)
 	}
 
 	log.Info("MySQL backends is created",
@@ -253,11 +273,24 @@ func (s *mysqlBackend) Flush(ctx context.Context) (err error) {
 	return
 }
 
+// ConflictCount implements dmlsink.ConflictCounter. The backends share the
+// conflict resolver, so each of them returns the count of the whole sink.
+func (s *mysqlBackend) ConflictCount() uint64 {
+	if s.conflictCount == nil {
+		return 0
+	}
+	return s.conflictCount.Load()
+}
+
 // Close implements interface backend.
 func (s *mysqlBackend) Close() (err error) {
 	if s.stmtCache != nil {
 		s.stmtCache.Purge()
 	}
+	if s.conflictResolver != nil {
+		s.conflictResolver.release()
+		s.conflictResolver = nil
+	}
 	if s.db != nil {
 		err = s.db.Close()
 		s.db = nil
@@ -539,6 +572,15 @@ func (s *mysqlBackend) prepareDMLs() *preparedDMLs {
 			callbacks = append(callbacks, event.Callback)
 		}
 
+		// The rows are resolved with the downstream rows one by one
+		// in the transaction, see conflictExecute.
+		if s.conflictResolver != nil {
+			for _, row := range event.Event.Rows {
+				approximateSize += row.ApproximateDataSize
+			}
+			continue
+		}
+
 		// TODO: find a better threshold
 		enableBatchModeThreshold := 1
 		// Determine whether to use batch dml feature here.
@@ -768,7 +810,13 @@ func (s *mysqlBackend) execDMLWithMaxRetries(pctx context.Context, dmls *prepare
 			// error can be ErrPrepareMulti, ErrBadConn etc.
 			// TODO: add a quick path to check whether we should fallback to
 			// the sequence way.
-			if s.cfg.MultiStmtEnable && !fallbackToSeqWay {
+			var records []*conflictRecord
+			if s.conflictResolver != nil {
+				records, err = s.conflictExecute(pctx, tx, writeTimeout)
+				if err != nil {
+					return 0, 0, err
+				}
+			} else if s.cfg.MultiStmtEnable && !fallbackToSeqWay {
 				err = s.multiStmtExecute(pctx, dmls, tx, writeTimeout)
 				if err != nil {
 					fallbackToSeqWay = true
@@ -786,6 +834,9 @@ func (s *mysqlBackend) execDMLWithMaxRetries(pctx context.Context, dmls *prepare
 					wrapMysqlTxnError(err),
 					start, s.changefeed, "COMMIT", dmls.rowCount, dmls.startTs)
 			}
+			if s.conflictResolver != nil {
+				s.conflictResolver.onCommitted(records)
+			}
 			return dmls.rowCount, dmls.approximateSize, nil
 		})
 		if err != nil {
@@ -21,6 +21,8 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/transformer/columntransformer"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/txn/mysql"
 	"github.com/pingcap/tiflow/cdc/sink/metrics"
 	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
@@ -54,6 +56,8 @@ type dmlSink struct {
 	dead chan struct{}
 
 	statistics *metrics.Statistics
+	// transformer is used to transform the rows before writing them to the downstream.
+	transformer transformer.Transformer
 
 	scheme string
 }
@@ -74,6 +78,12 @@ func NewMySQLSink(
 	ctx, cancel := context.WithCancel(ctx)
 	statistics := metrics.NewStatistics(changefeedID, sink.TxnSink)
 
+	trans, err := columntransformer.New(replicaConfig)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
 	backendImpls, err := mysql.NewMySQLBackends(ctx, changefeedID, sinkURI, replicaConfig, GetDBConnImpl, statistics)
 	if err != nil {
 		cancel()
@@ -87,6 +97,7 @@ func NewMySQLSink(
 
 	s := newSink(ctx, changefeedID, backends, errCh, conflictDetectorSlots)
 	s.statistics = statistics
+	s.transformer = trans
 	s.cancel = cancel
 	s.scheme = sink.GetScheme(sinkURI)
 
@@ -156,6 +167,13 @@ func (s *dmlSink) WriteEvents(txnEvents ...*dmlsink.TxnCallbackableEvent) error
 			txn.Callback()
 			continue
 		}
+		// Transform the rows before calculating the conflict keys,
+		// the handle key columns are kept consistent by the transformer.
+		if s.transformer != nil {
+			if err := transformer.ApplyToTxn(s.transformer, txn.Event); err != nil {
+				return errors.Trace(err)
+			}
+		}
 		s.alive.conflictDetector.Add(newTxnEvent(txn))
 	}
 	return nil
@@ -178,6 +196,17 @@ func (s *dmlSink) Dead() <-chan struct{} {
 	return s.dead
 }
 
+// ConflictCount implements dmlsink.ConflictCounter.
+func (s *dmlSink) ConflictCount() uint64 {
+	// The backends share the conflict resolver, so the first one is enough.
+	for _, w := range s.workers {
+		if counter, ok := w.backend.(dmlsink.ConflictCounter); ok {
+			return counter.ConflictCount()
+		}
+	}
+	return 0
+}
+
 func (s *dmlSink) SchemeOption() (string, bool) {
 	return s.scheme, false
 }
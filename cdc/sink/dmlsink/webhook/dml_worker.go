@@ -0,0 +1,139 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/metrics"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/chann"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/webhook"
+	"go.uber.org/zap"
+)
+
+// dmlWorker denotes a worker responsible for posting transactions to the webhook.
+type dmlWorker struct {
+	// worker id
+	id           int
+	changeFeedID model.ChangeFeedID
+	client       *webhook.Client
+	encoder      codec.RowEventEncoder
+	inputCh      *chann.DrainableChann[*dmlsink.TxnCallbackableEvent]
+	statistics   *metrics.Statistics
+
+	// lastTxns records the commit ts and the sequence of the last transaction
+	// of each physical table, which are used to build the idempotency key.
+	lastTxns map[int64]txnSeq
+}
+
+type txnSeq struct {
+	commitTs uint64
+	seq      int
+}
+
+func newDMLWorker(
+	id int,
+	changefeedID model.ChangeFeedID,
+	client *webhook.Client,
+	encoder codec.RowEventEncoder,
+	inputCh *chann.DrainableChann[*dmlsink.TxnCallbackableEvent],
+	statistics *metrics.Statistics,
+) *dmlWorker {
+	return &dmlWorker{
+		id:           id,
+		changeFeedID: changefeedID,
+		client:       client,
+		encoder:      encoder,
+		inputCh:      inputCh,
+		statistics:   statistics,
+		lastTxns:     make(map[int64]txnSeq),
+	}
+}
+
+// run posts the transactions one by one, so the transactions of the same
+// table are received by the webhook in order.
+func (d *dmlWorker) run(ctx context.Context) error {
+	log.Debug("webhook dml worker started", zap.Int("workerID", d.id),
+		zap.String("namespace", d.changeFeedID.Namespace),
+		zap.String("changefeed", d.changeFeedID.ID))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return errors.Trace(ctx.Err())
+		case txn, ok := <-d.inputCh.Out():
+			if !ok {
+				return nil
+			}
+			if err := d.writeTxn(ctx, txn); err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+}
+
+// writeTxn posts the transaction to the webhook, the callback is called only
+// after the webhook responds with a 2xx status code, so the checkpoint of the
+// table sink is not advanced until the transaction is accepted.
+func (d *dmlWorker) writeTxn(ctx context.Context, txn *dmlsink.TxnCallbackableEvent) error {
+	if txn.GetTableSinkState() != state.TableSinkSinking {
+		txn.Callback()
+		return nil
+	}
+
+	for _, row := range txn.Event.Rows {
+		if err := d.encoder.AppendRowChangedEvent(ctx, "", row, nil); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	msgs := d.encoder.Build()
+	if len(msgs) == 0 {
+		txn.Callback()
+		return nil
+	}
+
+	tableID := txn.Event.GetPhysicalTableID()
+	last := d.lastTxns[tableID]
+	if last.commitTs == txn.Event.GetCommitTs() {
+		last.seq++
+	} else {
+		last = txnSeq{commitTs: txn.Event.GetCommitTs()}
+	}
+	d.lastTxns[tableID] = last
+	table := txn.Event.TableInfo.TableName
+	table.TableID = tableID
+	key := webhook.IdempotencyKey(table, last.commitTs, last.seq)
+
+	err := d.statistics.RecordBatchExecution(func() (int, int64, error) {
+		var size int64
+		for _, msg := range msgs {
+			size += int64(len(msg.Value))
+		}
+		if err := d.client.Send(ctx, key, msgs); err != nil {
+			return 0, 0, err
+		}
+		return len(txn.Event.Rows), size, nil
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	txn.Callback()
+	return nil
+}
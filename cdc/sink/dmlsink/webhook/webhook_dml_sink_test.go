@@ -0,0 +1,201 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/cdc/sink/tablesink/state"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/webhook"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func generateTxnEvents(
+	cnt *uint64,
+	batch int,
+	tableStatus *state.TableSinkState,
+) []*dmlsink.TxnCallbackableEvent {
+	pk := types.NewFieldType(mysql.TypeLong)
+	pk.SetFlag(mysql.PriKeyFlag | mysql.NotNullFlag)
+	tidbTableInfo := &timodel.TableInfo{
+		ID:         20,
+		Name:       pmodel.NewCIStr("table1"),
+		PKIsHandle: true,
+		Columns: []*timodel.ColumnInfo{
+			{ID: 1, Name: pmodel.NewCIStr("c1"), FieldType: *pk},
+			{ID: 2, Name: pmodel.NewCIStr("c2"), FieldType: *types.NewFieldType(mysql.TypeVarchar)},
+		},
+	}
+	tableInfo := model.WrapTableInfo(100, "test", 33, tidbTableInfo)
+
+	txns := make([]*dmlsink.TxnCallbackableEvent, 0, 3)
+	for i := 0; i < 3; i++ {
+		txn := &dmlsink.TxnCallbackableEvent{
+			Event: &model.SingleTableTxn{
+				PhysicalTableID: 20,
+				// the last two transactions are split from the same upstream transaction.
+				CommitTs:  uint64(100 + (i+1)/2),
+				TableInfo: tableInfo,
+			},
+			Callback: func() {
+				atomic.AddUint64(cnt, uint64(batch))
+			},
+			SinkState: tableStatus,
+		}
+		for j := 0; j < batch; j++ {
+			txn.Event.Rows = append(txn.Event.Rows, &model.RowChangedEvent{
+				CommitTs:        txn.Event.CommitTs,
+				PhysicalTableID: 20,
+				TableInfo:       tableInfo,
+				Columns: []*model.ColumnData{
+					{ColumnID: 1, Value: int64(i*batch + j)},
+					{ColumnID: 2, Value: []byte("hello world")},
+				},
+			})
+		}
+		txns = append(txns, txn)
+	}
+	return txns
+}
+
+type request struct {
+	key  string
+	body []byte
+}
+
+func newServer(t *testing.T, failures int, code int) (*httptest.Server, func() []request) {
+	var (
+		mu       sync.Mutex
+		requests []request
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, webhook.ContentType, r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if failures > 0 {
+			failures--
+			w.WriteHeader(code)
+			return
+		}
+		requests = append(requests, request{key: r.Header.Get(webhook.IdempotencyKeyHeader), body: body})
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []request {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]request(nil), requests...)
+	}
+}
+
+func newSink(
+	ctx context.Context, t *testing.T, serverURL string, params string,
+) (*DMLSink, chan error) {
+	sinkURI, err := url.Parse(serverURL + "/events?protocol=canal-json&" + params)
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.Protocol = util.AddressOf(config.ProtocolCanalJSON.String())
+	errCh := make(chan error, 1)
+	s, err := NewDMLSink(ctx, model.DefaultChangeFeedID("test"), sinkURI, replicaConfig, errCh)
+	require.NoError(t, err)
+	return s, errCh
+}
+
+func TestWebhookWriteEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// the first request fails and it is retried.
+	server, requests := newServer(t, 1, http.StatusServiceUnavailable)
+	s, _ := newSink(ctx, t, server.URL, "worker-count=2&retry-backoff-base-in-ms=10")
+	defer s.Close()
+
+	var cnt uint64
+	tableStatus := state.TableSinkSinking
+	require.NoError(t, s.WriteEvents(generateTxnEvents(&cnt, 10, &tableStatus)...))
+	require.Eventually(t, func() bool {
+		return atomic.LoadUint64(&cnt) == 30
+	}, 10*time.Second, 100*time.Millisecond)
+
+	reqs := requests()
+	require.Len(t, reqs, 3)
+	// the transactions of the same table are posted in order.
+	require.Equal(t, "dml-test.table1-20-100-0", reqs[0].key)
+	require.Equal(t, "dml-test.table1-20-101-0", reqs[1].key)
+	require.Equal(t, "dml-test.table1-20-101-1", reqs[2].key)
+	for _, req := range reqs {
+		lines := bytes.Split(bytes.TrimSuffix(req.body, []byte("\n")), []byte("\n"))
+		require.Len(t, lines, 10)
+		require.Contains(t, string(lines[0]), `"table":"table1"`)
+	}
+}
+
+func TestWebhookRejected(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// the client errors are not retried.
+	server, requests := newServer(t, 1, http.StatusBadRequest)
+	s, errCh := newSink(ctx, t, server.URL, "worker-count=1")
+	defer s.Close()
+
+	var cnt uint64
+	tableStatus := state.TableSinkSinking
+	require.NoError(t, s.WriteEvents(generateTxnEvents(&cnt, 10, &tableStatus)[0]))
+	select {
+	case err := <-errCh:
+		require.ErrorIs(t, err, cerror.ErrWebhookSinkRequestFailed)
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "the error is not reported")
+	}
+	// the checkpoint is not advanced.
+	require.Equal(t, uint64(0), atomic.LoadUint64(&cnt))
+	require.Empty(t, requests())
+}
+
+func TestWebhookUnsupportedProtocol(t *testing.T) {
+	t.Parallel()
+
+	sinkURI, err := url.Parse("http://127.0.0.1:8080/events?protocol=avro")
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.Protocol = util.AddressOf(config.ProtocolAvro.String())
+	_, err = NewDMLSink(context.Background(), model.DefaultChangeFeedID("test"),
+		sinkURI, replicaConfig, make(chan error, 1))
+	require.ErrorIs(t, err, cerror.ErrWebhookSinkInvalidConfig)
+}
@@ -48,6 +48,50 @@ var (
 			Help:      "Batch duration for MQ worker.",
 			Buckets:   prometheus.ExponentialBuckets(0.004, 2, 10), // 4ms ~ 2s
 		}, []string{"namespace", "changefeed"})
+	// WorkerPartitionWriteBytes records the bytes of the messages sent to each partition.
+	WorkerPartitionWriteBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ticdc",
+			Subsystem: "sink",
+			Name:      "mq_worker_partition_write_bytes",
+			Help:      "Total bytes of the messages sent to each partition by MQ worker.",
+		}, []string{"namespace", "changefeed", "topic", "partition"})
+	// WorkerPartitionInflightMessages records the number of the messages sent to
+	// each partition but not acknowledged yet.
+	WorkerPartitionInflightMessages = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "ticdc",
+			Subsystem: "sink",
+			Name:      "mq_worker_partition_inflight_messages",
+			Help:      "The number of the in-flight messages of each partition for MQ worker.",
+		}, []string{"namespace", "changefeed", "topic", "partition"})
+	// WorkerPartitionInflightBytes records the bytes of the messages sent to
+	// each partition but not acknowledged yet.
+	WorkerPartitionInflightBytes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "ticdc",
+			Subsystem: "sink",
+			Name:      "mq_worker_partition_inflight_bytes",
+			Help:      "The bytes of the in-flight messages of each partition for MQ worker.",
+		}, []string{"namespace", "changefeed", "topic", "partition"})
+	// WorkerPartitionAckDuration records the duration from sending a message
+	// to the partition until it's acknowledged.
+	WorkerPartitionAckDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "ticdc",
+			Subsystem: "sink",
+			Name:      "mq_worker_partition_ack_duration",
+			Help:      "Acknowledge duration(s) of the messages of each partition for MQ worker.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 20), // 1ms~524s
+		}, []string{"namespace", "changefeed", "topic", "partition"})
+	// WorkerSlowPartitionCount records the number of the slow partitions.
+	WorkerSlowPartitionCount = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "ticdc",
+			Subsystem: "sink",
+			Name:      "mq_worker_slow_partition_count",
+			Help:      "The number of the partitions whose messages are not acknowledged in time.",
+		}, []string{"namespace", "changefeed"})
 )
 
 // InitMetrics registers all metrics in this file.
@@ -57,6 +101,11 @@ func InitMetrics(registry *prometheus.Registry) {
 	registry.MustRegister(WorkerSendMessageDuration)
 	registry.MustRegister(WorkerBatchSize)
 	registry.MustRegister(WorkerBatchDuration)
+	registry.MustRegister(WorkerPartitionWriteBytes)
+	registry.MustRegister(WorkerPartitionInflightMessages)
+	registry.MustRegister(WorkerPartitionInflightBytes)
+	registry.MustRegister(WorkerPartitionAckDuration)
+	registry.MustRegister(WorkerSlowPartitionCount)
 	claimcheck.InitMetrics(registry)
 	codec.InitMetrics(registry)
 	kafka.InitMetrics(registry)
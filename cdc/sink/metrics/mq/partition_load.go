@@ -0,0 +1,66 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mq
+
+import (
+	"strconv"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// PartitionLoad reads the load of the partitions from the metrics of the changefeed.
+type PartitionLoad struct {
+	changefeedID model.ChangeFeedID
+}
+
+// NewPartitionLoad creates a PartitionLoad.
+func NewPartitionLoad(changefeedID model.ChangeFeedID) *PartitionLoad {
+	return &PartitionLoad{changefeedID: changefeedID}
+}
+
+// PartitionWriteBytes returns the accumulated written bytes of each partition of the topic.
+func (l *PartitionLoad) PartitionWriteBytes(topic string, partitionNum int32) []float64 {
+	result := make([]float64, partitionNum)
+	for i := int32(0); i < partitionNum; i++ {
+		counter, err := WorkerPartitionWriteBytes.GetMetricWithLabelValues(
+			l.changefeedID.Namespace, l.changefeedID.ID, topic, strconv.Itoa(int(i)))
+		if err != nil {
+			continue
+		}
+		metric := &dto.Metric{}
+		if err := counter.Write(metric); err != nil {
+			continue
+		}
+		result[i] = metric.GetCounter().GetValue()
+	}
+	return result
+}
+
+// RecordPartitionWriteBytes records the bytes of the message sent to the partition.
+func RecordPartitionWriteBytes(
+	changefeedID model.ChangeFeedID, topic string, partition int32, bytes int,
+) {
+	WorkerPartitionWriteBytes.WithLabelValues(
+		changefeedID.Namespace, changefeedID.ID, topic, strconv.Itoa(int(partition)),
+	).Add(float64(bytes))
+}
+
+// CleanPartitionWriteBytes removes the partition metrics of the changefeed.
+func CleanPartitionWriteBytes(changefeedID model.ChangeFeedID) {
+	WorkerPartitionWriteBytes.DeletePartialMatch(map[string]string{
+		"namespace":  changefeedID.Namespace,
+		"changefeed": changefeedID.ID,
+	})
+}
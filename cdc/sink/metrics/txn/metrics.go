@@ -88,6 +88,16 @@ var (
 			Name:      "txn_prepare_statement_errors",
 			Help:      "Prepare statement errors",
 		}, []string{"namespace", "changefeed"})
+
+	// RowConflicts records the rows conflicted with the downstream in the BDR mode,
+	// the resolution is either applied or skipped.
+	RowConflicts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "ticdc",
+			Subsystem: "sink",
+			Name:      "txn_row_conflicts",
+			Help:      "The number of rows conflicted with the downstream rows",
+		}, []string{"namespace", "changefeed", "resolution"})
 )
 
 // InitMetrics registers all metrics in this file.
@@ -100,4 +110,5 @@ func InitMetrics(registry *prometheus.Registry) {
 	registry.MustRegister(SinkDMLBatchCommit)
 	registry.MustRegister(SinkDMLBatchCallback)
 	registry.MustRegister(PrepareStatementErrors)
+	registry.MustRegister(RowConflicts)
 }
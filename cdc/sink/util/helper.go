@@ -60,6 +60,8 @@ func GetFileExtension(protocol config.Protocol) string {
 		return ".canal"
 	case config.ProtocolCsv:
 		return ".csv"
+	case config.ProtocolParquet:
+		return ".parquet"
 	default:
 		return ".unknown"
 	}
@@ -118,5 +120,5 @@ func GetTopicManagerAndTryCreateTopic(
 
 // IsPulsarSupportedProtocols returns whether the protocol is supported by pulsar.
 func IsPulsarSupportedProtocols(p config.Protocol) bool {
-	return p == config.ProtocolCanalJSON
+	return p == config.ProtocolCanalJSON || p == config.ProtocolDebezium
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/sink/kafka"
 	"github.com/stretchr/testify/require"
 )
@@ -120,3 +121,12 @@ func TestGetTopic(t *testing.T) {
 		})
 	}
 }
+
+func TestIsPulsarSupportedProtocols(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, IsPulsarSupportedProtocols(config.ProtocolCanalJSON))
+	require.True(t, IsPulsarSupportedProtocols(config.ProtocolDebezium))
+	require.False(t, IsPulsarSupportedProtocols(config.ProtocolOpen))
+	require.False(t, IsPulsarSupportedProtocols(config.ProtocolAvro))
+}
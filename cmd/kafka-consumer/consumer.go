@@ -82,6 +82,9 @@ func newConsumer(ctx context.Context, o *option) *consumer {
 	if len(topics) == 0 {
 		log.Panic("no topic provided for the consumer")
 	}
+	w := newWriter(ctx, o)
+	// the DDLs of the tables are consumed from the DDL topics if set.
+	topics = append(topics, w.ddlTopics...)
 	configMap := &kafka.ConfigMap{
 		"bootstrap.servers": strings.Join(o.address, ","),
 		"group.id":          o.groupID,
@@ -108,7 +111,7 @@ func newConsumer(ctx context.Context, o *option) *consumer {
 		log.Panic("subscribe topics failed", zap.Error(err))
 	}
 	return &consumer{
-		writer: newWriter(ctx, o),
+		writer: w,
 		client: client,
 	}
 }
@@ -119,6 +122,7 @@ func (c *consumer) Consume(ctx context.Context) {
 		if err := c.client.Close(); err != nil {
 			log.Panic("close kafka consumer failed", zap.Error(err))
 		}
+		c.writer.close()
 	}()
 	for {
 		msg, err := c.client.ReadMessage(-1)
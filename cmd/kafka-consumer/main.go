@@ -44,9 +44,10 @@ func main() {
 
 	groupID := fmt.Sprintf("ticdc_kafka_consumer_%s", uuid.New().String())
 	consumerOption := newOption()
-	flag.StringVar(&configFile, "config", "", "config file for changefeed")
+	flag.StringVar(&configFile, "config", "", "config file for changefeed, the DDL topics of its dispatch rules are consumed as well")
 	flag.StringVar(&upstreamURIStr, "upstream-uri", "", "Kafka uri")
 	flag.StringVar(&consumerOption.downstreamURI, "downstream-uri", "", "downstream sink uri")
+	flag.StringVar(&consumerOption.downstreamConfigFile, "downstream-config", "", "config file for downstream sink")
 	flag.StringVar(&consumerOption.schemaRegistryURI, "schema-registry-uri", "", "schema registry uri")
 	flag.StringVar(&consumerOption.upstreamTiDBDSN, "upstream-tidb-dsn", "", "upstream TiDB DSN")
 	flag.StringVar(&consumerOption.groupID, "consumer-group-id", groupID, "consumer group id")
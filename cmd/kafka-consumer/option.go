@@ -26,6 +26,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/config"
 	cerrors "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/filter"
+	"github.com/pingcap/tiflow/pkg/sink"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"github.com/pingcap/tiflow/pkg/util"
 	"go.uber.org/zap"
@@ -59,6 +60,10 @@ type option struct {
 	ca, cert, key string
 
 	downstreamURI string
+	// downstreamConfigFile is the replica config file of the downstream sink
+	downstreamConfigFile string
+	// the replicaConfig of the downstream sink, which the decoded events are written to
+	sinkReplicaConfig *config.ReplicaConfig
 
 	// avro schema registry uri should be set if the encoding protocol is avro
 	schemaRegistryURI string
@@ -147,6 +152,11 @@ func (o *option) Adjust(upstreamURI *url.URL, configFile string) error {
 	}
 	o.replicaConfig = replicaConfig
 
+	o.sinkReplicaConfig, err = o.newSinkReplicaConfig()
+	if err != nil {
+		return cerror.Trace(err)
+	}
+
 	o.codecConfig = common.NewConfig(protocol)
 	if err = o.codecConfig.Apply(upstreamURI, o.replicaConfig); err != nil {
 		return cerror.Trace(err)
@@ -173,3 +183,35 @@ func (o *option) Adjust(upstreamURI *url.URL, configFile string) error {
 		zap.String("upstreamURI", upstreamURI.String()))
 	return nil
 }
+
+// newSinkReplicaConfig returns the replica config of the downstream sink.
+// The MySQL compatible downstream uses the config of the changefeed by default,
+// the other downstreams are configured by the downstream config file and uri,
+// so the decoded events can be re-encoded by another protocol.
+func (o *option) newSinkReplicaConfig() (*config.ReplicaConfig, error) {
+	sinkURI, err := url.Parse(o.downstreamURI)
+	if err != nil {
+		return nil, cerrors.WrapError(cerrors.ErrSinkURIInvalid, err)
+	}
+	if o.downstreamConfigFile == "" && sink.IsMySQLCompatibleScheme(sink.GetScheme(sinkURI)) {
+		return o.replicaConfig, nil
+	}
+
+	replicaConfig := config.GetDefaultReplicaConfig()
+	// the TiDB source ID should never be set to 0
+	replicaConfig.Sink.TiDBSourceID = 1
+	if o.downstreamConfigFile != "" {
+		err = cmdUtil.StrictDecodeFile(o.downstreamConfigFile, "kafka consumer downstream", replicaConfig)
+		if err != nil {
+			return nil, cerror.Trace(err)
+		}
+	}
+	if err = replicaConfig.ValidateAndAdjust(sinkURI); err != nil {
+		return nil, cerror.Trace(err)
+	}
+	log.Info("downstream sink config adjusted",
+		zap.String("downstreamConfigFile", o.downstreamConfigFile),
+		zap.String("downstreamURI", util.MaskSensitiveDataInURI(o.downstreamURI)),
+		zap.String("protocol", util.GetOrZero(replicaConfig.Sink.Protocol)))
+	return replicaConfig, nil
+}
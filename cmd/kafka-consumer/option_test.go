@@ -0,0 +1,108 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSinkReplicaConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	validConfig := writeConfig("valid.toml", "[sink]\nprotocol = \"open-protocol\"\n")
+	mysqlConfig := writeConfig("mysql.toml", "[filter]\nrules = ['test.*']\n")
+	invalidConfig := writeConfig("invalid.toml", "[sink]\nunknown-field = true\n")
+
+	changefeedConfig := config.GetDefaultReplicaConfig()
+	changefeedConfig.Sink.Protocol = util.AddressOf(config.ProtocolCanalJSON.String())
+
+	cases := []struct {
+		name          string
+		uri           string
+		configFile    string
+		reused        bool
+		protocol      string
+		expectedError bool
+	}{
+		{
+			name:   "mysql without downstream config",
+			uri:    "mysql://root@127.0.0.1:3306/",
+			reused: true,
+		},
+		{
+			name:       "mysql with downstream config",
+			uri:        "mysql://root@127.0.0.1:3306/",
+			configFile: mysqlConfig,
+		},
+		{
+			name:     "kafka",
+			uri:      "kafka://127.0.0.1:9092/test?protocol=avro",
+			protocol: config.ProtocolAvro.String(),
+		},
+		{
+			name:       "kafka with downstream config",
+			uri:        "kafka://127.0.0.1:9092/test",
+			configFile: validConfig,
+			protocol:   config.ProtocolOpen.String(),
+		},
+		{
+			name:     "storage",
+			uri:      "file://" + dir + "/storage?protocol=csv",
+			protocol: config.ProtocolCsv.String(),
+		},
+		{
+			name:          "invalid downstream config",
+			uri:           "kafka://127.0.0.1:9092/test?protocol=canal-json",
+			configFile:    invalidConfig,
+			expectedError: true,
+		},
+		{
+			name:          "kafka without protocol",
+			uri:           "kafka://127.0.0.1:9092/test",
+			expectedError: true,
+		},
+	}
+	for _, c := range cases {
+		o := &option{
+			replicaConfig:        changefeedConfig,
+			downstreamURI:        c.uri,
+			downstreamConfigFile: c.configFile,
+		}
+		replicaConfig, err := o.newSinkReplicaConfig()
+		if c.expectedError {
+			require.Error(t, err, c.name)
+			continue
+		}
+		require.NoError(t, err, c.name)
+		if c.reused {
+			require.Same(t, changefeedConfig, replicaConfig, c.name)
+			continue
+		}
+		require.NotSame(t, changefeedConfig, replicaConfig, c.name)
+		require.Equal(t, uint64(1), replicaConfig.Sink.TiDBSourceID, c.name)
+		require.Equal(t, c.protocol, util.GetOrZero(replicaConfig.Sink.Protocol), c.name)
+	}
+}
@@ -16,9 +16,12 @@ package main
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -28,8 +31,11 @@ import (
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/cdc/sink/ddlsink"
 	ddlsinkfactory "github.com/pingcap/tiflow/cdc/sink/ddlsink/factory"
+	ddlmq "github.com/pingcap/tiflow/cdc/sink/ddlsink/mq"
 	eventsinkfactory "github.com/pingcap/tiflow/cdc/sink/dmlsink/factory"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher/partition"
 	"github.com/pingcap/tiflow/cdc/sink/tablesink"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
@@ -37,6 +43,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/sink/codec"
 	"github.com/pingcap/tiflow/pkg/sink/codec/avro"
 	"github.com/pingcap/tiflow/pkg/sink/codec/canal"
+	"github.com/pingcap/tiflow/pkg/sink/codec/debezium"
 	"github.com/pingcap/tiflow/pkg/sink/codec/open"
 	"github.com/pingcap/tiflow/pkg/sink/codec/simple"
 	"github.com/pingcap/tiflow/pkg/spanz"
@@ -62,6 +69,8 @@ func NewDecoder(ctx context.Context, option *option, upstreamTiDB *sql.DB) (code
 		decoder = avro.NewDecoder(option.codecConfig, schemaM, option.topic, upstreamTiDB)
 	case config.ProtocolSimple:
 		decoder, err = simple.NewDecoder(ctx, option.codecConfig, upstreamTiDB)
+	case config.ProtocolDebezium:
+		decoder = debezium.NewDecoder(option.codecConfig, upstreamTiDB)
 	default:
 		log.Panic("Protocol not supported", zap.Any("Protocol", option.protocol))
 	}
@@ -79,6 +88,38 @@ type partitionProgress struct {
 
 	eventGroups map[int64]*eventsGroup
 	decoder     codec.RowEventDecoder
+	// schemaVersions are the latest schema versions of the tables announced by
+	// the DDL and bootstrap messages, keyed by the quoted table name.
+	schemaVersions map[string]uint64
+}
+
+// announceSchemaVersion records the schema version of the DDL or bootstrap message.
+func (p *partitionProgress) announceSchemaVersion(tableInfo *model.TableInfo) {
+	if tableInfo.TableInfo == nil || tableInfo.UpdateTS == 0 {
+		return
+	}
+	name := tableInfo.TableName.QuoteString()
+	if tableInfo.UpdateTS > p.schemaVersions[name] {
+		p.schemaVersions[name] = tableInfo.UpdateTS
+	}
+}
+
+// checkSchemaVersion returns false if the row is encoded with a schema version
+// newer than the one announced in the partition, which means the DDL or the
+// bootstrap message of the new schema is lost.
+func (p *partitionProgress) checkSchemaVersion(row *model.RowChangedEvent) bool {
+	version := row.TableInfo.UpdateTS
+	if version == 0 {
+		return true
+	}
+	name := row.TableInfo.TableName.QuoteString()
+	announced, ok := p.schemaVersions[name]
+	if !ok {
+		// the consumer may start after the schema is announced.
+		p.schemaVersions[name] = version
+		return true
+	}
+	return version <= announced
 }
 
 type writer struct {
@@ -89,11 +130,37 @@ type writer struct {
 	ddlSink              ddlsink.Sink
 	fakeTableIDGenerator *fakeTableIDGenerator
 
+	// tableInfos are the tables of the received row changed events,
+	// the checkpoint is sent to the topics of them if the downstream is MQ.
+	tableInfos   map[model.TableID]*model.TableInfo
+	checkpointTs uint64
+
 	// sinkFactory is used to create table sink for each table.
 	sinkFactory *eventsinkfactory.SinkFactory
 	progresses  []*partitionProgress
 
 	eventRouter *dispatcher.EventRouter
+	// partitionMappings are the partition mapping changes of the tables
+	// dispatched by the adaptive dispatcher, in the order of the commit ts.
+	partitionMappings map[string][]*partition.MappingChange
+
+	// ddlTopics are the DDL topics of the dispatch rules, the DDLs of the tables
+	// are consumed from them instead of the data topic.
+	ddlTopics []string
+	// lastDDLs are the last DDL received from each DDL topic,
+	// the fences of the DDL follow it in the DDL topic.
+	lastDDLs map[string]*model.DDLEvent
+	// fences are the DDL fences which not all partitions have reached yet.
+	fences map[string]*fenceProgress
+}
+
+// fenceProgress is the progress of a DDL fence of the data topic.
+type fenceProgress struct {
+	fence *ddlmq.DDLFence
+	// ddl is set once the fence is received from the DDL topic.
+	ddl *model.DDLEvent
+	// reached are the partitions which have received the fence.
+	reached map[int32]struct{}
 }
 
 func newWriter(ctx context.Context, o *option) *writer {
@@ -102,7 +169,11 @@ func newWriter(ctx context.Context, o *option) *writer {
 		fakeTableIDGenerator: &fakeTableIDGenerator{
 			tableIDs: make(map[string]int64),
 		},
-		progresses: make([]*partitionProgress, o.partitionNum),
+		tableInfos:        make(map[model.TableID]*model.TableInfo),
+		progresses:        make([]*partitionProgress, o.partitionNum),
+		partitionMappings: make(map[string][]*partition.MappingChange),
+		lastDDLs:          make(map[string]*model.DDLEvent),
+		fences:            make(map[string]*fenceProgress),
 	}
 
 	eventRouter, err := dispatcher.NewEventRouter(o.replicaConfig, o.protocol, o.topic, "kafka")
@@ -112,8 +183,10 @@ func newWriter(ctx context.Context, o *option) *writer {
 			zap.Any("dispatcherRules", o.replicaConfig.Sink.DispatchRules), zap.Error(err))
 	}
 	w.eventRouter = eventRouter
+	w.ddlTopics = eventRouter.GetDDLTopics()
 	log.Info("event router created", zap.Any("protocol", o.protocol),
-		zap.Any("topic", o.topic), zap.Any("dispatcherRules", o.replicaConfig.Sink.DispatchRules))
+		zap.Any("topic", o.topic), zap.Strings("ddlTopics", w.ddlTopics),
+		zap.Any("dispatcherRules", o.replicaConfig.Sink.DispatchRules))
 
 	var db *sql.DB
 
@@ -131,15 +204,16 @@ func newWriter(ctx context.Context, o *option) *writer {
 			log.Panic("cannot create the decoder", zap.Error(err))
 		}
 		w.progresses[i] = &partitionProgress{
-			eventGroups: make(map[int64]*eventsGroup),
-			decoder:     decoder,
+			eventGroups:    make(map[int64]*eventsGroup),
+			decoder:        decoder,
+			schemaVersions: make(map[string]uint64),
 		}
 	}
 
 	config.GetGlobalServerConfig().TZ = o.timezone
 	errChan := make(chan error, 1)
 	changefeed := model.DefaultChangeFeedID("kafka-consumer")
-	f, err := eventsinkfactory.New(ctx, changefeed, o.downstreamURI, o.replicaConfig, errChan, nil)
+	f, err := eventsinkfactory.New(ctx, changefeed, o.downstreamURI, o.sinkReplicaConfig, errChan, nil)
 	if err != nil {
 		log.Panic("cannot create the event sink factory", zap.Error(err))
 	}
@@ -154,7 +228,7 @@ func newWriter(ctx context.Context, o *option) *writer {
 		}
 	}()
 
-	ddlSink, err := ddlsinkfactory.New(ctx, changefeed, o.downstreamURI, o.replicaConfig)
+	ddlSink, err := ddlsinkfactory.New(ctx, changefeed, o.downstreamURI, o.sinkReplicaConfig)
 	if err != nil {
 		log.Panic("cannot create the ddl sink factory", zap.Error(err))
 	}
@@ -253,6 +327,7 @@ func (w *writer) Write(ctx context.Context, messageType model.MessageType) bool
 		w.forEachPartition(func(sink *partitionProgress) {
 			syncFlushRowChangedEvents(ctx, sink, watermark)
 		})
+		w.writeCheckpointTs(ctx, watermark)
 	}
 
 	// The DDL events will only execute in partition0
@@ -266,6 +341,193 @@ func (w *writer) Write(ctx context.Context, messageType model.MessageType) bool
 	return true
 }
 
+// writeCheckpointTs sends the checkpoint to the downstream after all events
+// before it are flushed, so the consumers of a MQ downstream can make progress.
+func (w *writer) writeCheckpointTs(ctx context.Context, watermark uint64) {
+	if watermark <= w.checkpointTs || watermark == math.MaxUint64 {
+		return
+	}
+	tables := make([]*model.TableInfo, 0, len(w.tableInfos))
+	for _, tableInfo := range w.tableInfos {
+		tables = append(tables, tableInfo)
+	}
+	if err := w.ddlSink.WriteCheckpointTs(ctx, watermark, tables); err != nil {
+		log.Panic("write checkpoint ts failed", zap.Uint64("checkpointTs", watermark), zap.Error(err))
+	}
+	w.checkpointTs = watermark
+}
+
+// close closes the downstream sinks.
+func (w *writer) close() {
+	w.sinkFactory.Close()
+	w.ddlSink.Close()
+}
+
+// decodeControlMessage returns the JSON value of the control message.
+func (w *writer) decodeControlMessage(partition int32, value []byte) []byte {
+	decoder, ok := w.progresses[partition].decoder.(codec.ControlMessageDecoder)
+	if !ok {
+		log.Panic("control message is not supported by the protocol",
+			zap.Int32("partition", partition), zap.String("protocol", w.option.protocol.String()))
+	}
+	_, result, err := decoder.DecodeControlMessage(value)
+	if err != nil {
+		log.Panic("decode control message failed",
+			zap.Int32("partition", partition), zap.Error(err))
+	}
+	return result
+}
+
+// decodeDDLFence returns the DDL fence of the control message.
+func (w *writer) decodeDDLFence(partition int32, value []byte) *ddlmq.DDLFence {
+	value = w.decodeControlMessage(partition, value)
+	fence := &ddlmq.DDLFence{}
+	if err := json.Unmarshal(value, fence); err != nil {
+		log.Panic("decode DDL fence failed",
+			zap.Int32("partition", partition), zap.ByteString("value", value), zap.Error(err))
+	}
+	return fence
+}
+
+func (w *writer) getFenceProgress(fence *ddlmq.DDLFence) *fenceProgress {
+	key := fence.Key()
+	progress, ok := w.fences[key]
+	if !ok {
+		progress = &fenceProgress{
+			fence:   fence,
+			reached: make(map[int32]struct{}),
+		}
+		w.fences[key] = progress
+	}
+	return progress
+}
+
+// tryApplyFence appends the DDL of the fence once all partitions reach the fence,
+// the rows before the fence in all partitions are under the schema before the DDL.
+func (w *writer) tryApplyFence(ctx context.Context, progress *fenceProgress) bool {
+	if progress.ddl == nil || len(progress.reached) < int(progress.fence.PartitionNum) {
+		return false
+	}
+	delete(w.fences, progress.fence.Key())
+	log.Info("all partitions reached the DDL fence",
+		zap.String("ddlTopic", progress.fence.DDLTopic),
+		zap.Uint64("commitTs", progress.ddl.CommitTs),
+		zap.String("DDL", progress.ddl.Query))
+	w.appendDDL(progress.ddl)
+	return w.Write(ctx, model.MessageTypeDDL)
+}
+
+// writeDDLTopicMessage handles the message of the DDL topic. The DDL and bootstrap
+// messages carry the table schemas which all partitions need to decode the rows,
+// and the DDLs are applied once all partitions reach their fences.
+func (w *writer) writeDDLTopicMessage(ctx context.Context, message *kafka.Message) bool {
+	ddlTopic := *message.TopicPartition.Topic
+	if strings.HasPrefix(string(message.Key), ddlmq.DDLFenceKeyPrefix) {
+		fence := w.decodeDDLFence(0, message.Value)
+		// the fences of the other data topics are not consumed.
+		if fence.Topic != w.option.topic {
+			return false
+		}
+		ddl := w.lastDDLs[ddlTopic]
+		if ddl == nil || ddl.CommitTs != fence.CommitTs {
+			log.Panic("DDL fence received before the DDL",
+				zap.String("ddlTopic", ddlTopic), zap.Any("offset", message.TopicPartition.Offset),
+				zap.Any("fence", fence))
+		}
+		progress := w.getFenceProgress(fence)
+		progress.ddl = ddl
+		return w.tryApplyFence(ctx, progress)
+	}
+
+	var ddl *model.DDLEvent
+	for i, progress := range w.progresses {
+		if err := progress.decoder.AddKeyValue(message.Key, message.Value); err != nil {
+			log.Panic("add key value to the decoder failed",
+				zap.String("ddlTopic", ddlTopic), zap.Any("offset", message.TopicPartition.Offset),
+				zap.Error(err))
+		}
+		ty, hasNext, err := progress.decoder.HasNext()
+		if err != nil || !hasNext || ty != model.MessageTypeDDL {
+			log.Panic("decode DDL topic message failed",
+				zap.String("ddlTopic", ddlTopic), zap.Any("offset", message.TopicPartition.Offset),
+				zap.Any("messageType", ty), zap.Bool("hasNext", hasNext), zap.Error(err))
+		}
+		result, err := progress.decoder.NextDDLEvent()
+		if err != nil {
+			log.Panic("decode message value failed",
+				zap.String("ddlTopic", ddlTopic), zap.Any("offset", message.TopicPartition.Offset),
+				zap.Error(err))
+		}
+		w.appendCachedEvents(progress)
+		progress.announceSchemaVersion(result.TableInfo)
+		if i == 0 {
+			ddl = result
+		}
+	}
+	// the Query is empty if it's a bootstrap message.
+	if ddl != nil && ddl.Query != "" {
+		w.lastDDLs[ddlTopic] = ddl
+		log.Info("DDL message received from the DDL topic",
+			zap.String("ddlTopic", ddlTopic),
+			zap.Any("offset", message.TopicPartition.Offset),
+			zap.Uint64("commitTs", ddl.CommitTs),
+			zap.String("DDL", ddl.Query))
+	}
+	return false
+}
+
+// appendCachedEvents appends the rows cached by the simple decoder until the
+// schemas of the tables are received.
+func (w *writer) appendCachedEvents(progress *partitionProgress) {
+	decoder, ok := progress.decoder.(*simple.Decoder)
+	if !ok {
+		return
+	}
+	for _, row := range decoder.GetCachedEvents() {
+		row.TableInfo.TableName.TableID = row.PhysicalTableID
+		group, ok := progress.eventGroups[row.PhysicalTableID]
+		if !ok {
+			group = NewEventsGroup()
+			progress.eventGroups[row.PhysicalTableID] = group
+		}
+		group.Append(row)
+		w.tableInfos[row.PhysicalTableID] = row.TableInfo
+	}
+}
+
+// appendPartitionMapping records the partition mapping change of the marker,
+// the duplicated markers from the other partitions are ignored.
+func (w *writer) appendPartitionMapping(partitionID int32, value []byte) {
+	change := &partition.MappingChange{}
+	if err := json.Unmarshal(value, change); err != nil {
+		log.Panic("decode partition mapping marker failed",
+			zap.Int32("partition", partitionID), zap.ByteString("value", value), zap.Error(err))
+	}
+	name := model.TableName{Schema: change.Schema, Table: change.Table}.String()
+	changes := w.partitionMappings[name]
+	if len(changes) != 0 && changes[len(changes)-1].CommitTs >= change.CommitTs {
+		return
+	}
+	w.partitionMappings[name] = append(changes, change)
+	log.Info("partition mapping changed",
+		zap.Int32("partition", partitionID), zap.String("table", name),
+		zap.Uint64("commitTs", change.CommitTs),
+		zap.Int32s("previous", change.Previous), zap.Int32s("current", change.Current))
+}
+
+// checkPartition returns whether the row is dispatched to the expected partition.
+// The row of the table dispatched by the adaptive dispatcher is checked by the
+// latest partition mapping change before it.
+func (w *writer) checkPartition(row *model.RowChangedEvent, partitionID, target int32) bool {
+	changes := w.partitionMappings[row.TableInfo.TableName.String()]
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].CommitTs <= row.CommitTs {
+			return slices.Contains(changes[i].Current, partitionID)
+		}
+	}
+	return partitionID == target
+}
+
 // WriteMessage is to decode kafka message to event.
 func (w *writer) WriteMessage(ctx context.Context, message *kafka.Message) bool {
 	var (
@@ -274,6 +536,27 @@ func (w *writer) WriteMessage(ctx context.Context, message *kafka.Message) bool
 		partition = message.TopicPartition.Partition
 	)
 
+	if topic := message.TopicPartition.Topic; topic != nil && slices.Contains(w.ddlTopics, *topic) {
+		return w.writeDDLTopicMessage(ctx, message)
+	}
+
+	// the partition mapping change marker is sent to all partitions involved.
+	if string(key) == mq.PartitionMappingMarkerKey {
+		w.appendPartitionMapping(partition, w.decodeControlMessage(partition, value))
+		return false
+	}
+	// the DDL fence is broadcast to all partitions, and the DDL is sent to the DDL topic.
+	if strings.HasPrefix(string(key), ddlmq.DDLFenceKeyPrefix) {
+		if len(w.ddlTopics) == 0 {
+			log.Panic("DDL fence received, but the DDL topic is not consumed, "+
+				"set the ddl-topic of the dispatch rules in the config file",
+				zap.Int32("partition", partition), zap.ByteString("key", key))
+		}
+		progress := w.getFenceProgress(w.decodeDDLFence(partition, value))
+		progress.reached[partition] = struct{}{}
+		return w.tryApplyFence(ctx, progress)
+	}
+
 	progress := w.progresses[partition]
 	decoder := progress.decoder
 	eventGroup := progress.eventGroups
@@ -322,20 +605,11 @@ func (w *writer) WriteMessage(ctx context.Context, message *kafka.Message) bool
 					zap.Error(err))
 			}
 
-			if simple, ok := decoder.(*simple.Decoder); ok {
-				cachedEvents := simple.GetCachedEvents()
-				for _, row := range cachedEvents {
-					row.TableInfo.TableName.TableID = row.PhysicalTableID
-					group, ok := eventGroup[row.PhysicalTableID]
-					if !ok {
-						group = NewEventsGroup()
-						eventGroup[row.PhysicalTableID] = group
-					}
-					group.Append(row)
-				}
-			}
+			w.appendCachedEvents(progress)
+			progress.announceSchemaVersion(ddl.TableInfo)
 
-			// the Query maybe empty if using simple protocol, it's comes from `bootstrap` event.
+			// the Query is empty if it's a bootstrap message, which only announces the table
+			// schema, by the simple protocol or the protocols encoding the schema version.
 			if partition == 0 && ddl.Query != "" {
 				w.appendDDL(ddl)
 				needFlush = true
@@ -358,6 +632,13 @@ func (w *writer) WriteMessage(ctx context.Context, message *kafka.Message) bool
 			if w.option.protocol == config.ProtocolSimple && row == nil {
 				continue
 			}
+			if w.option.codecConfig.EnableSchemaVersion && !progress.checkSchemaVersion(row) {
+				log.Panic("RowChangedEvent encoded with the schema version not announced",
+					zap.Int32("partition", partition), zap.Any("offset", message.TopicPartition.Offset),
+					zap.Uint64("schemaVersion", row.TableInfo.UpdateTS),
+					zap.String("schema", row.TableInfo.GetSchemaName()),
+					zap.String("table", row.TableInfo.GetTableName()))
+			}
 
 			tableID := row.PhysicalTableID
 			// simple protocol decoder should have set the table id already.
@@ -374,7 +655,7 @@ func (w *writer) WriteMessage(ctx context.Context, message *kafka.Message) bool
 					zap.Int32("partitionNum", w.option.partitionNum), zap.Int64("tableID", tableID),
 					zap.Error(err), zap.Any("event", row))
 			}
-			if partition != target {
+			if !w.checkPartition(row, partition, target) {
 				log.Panic("RowChangedEvent dispatched to wrong partition",
 					zap.Int32("partition", partition), zap.Int32("expected", target),
 					zap.Int32("partitionNum", w.option.partitionNum),
@@ -411,6 +692,7 @@ func (w *writer) WriteMessage(ctx context.Context, message *kafka.Message) bool
 				eventGroup[tableID] = group
 			}
 			group.Append(row)
+			w.tableInfos[tableID] = row.TableInfo
 			log.Debug("DML event received",
 				zap.Int32("partition", partition),
 				zap.Any("offset", message.TopicPartition.Offset),
@@ -0,0 +1,257 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/confluentinc/confluent-kafka-go/v2/kafka"
+	"github.com/pingcap/tiflow/cdc/entry"
+	"github.com/pingcap/tiflow/cdc/model"
+	ddlmq "github.com/pingcap/tiflow/cdc/sink/ddlsink/mq"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/canal"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/codec/simple"
+	"github.com/stretchr/testify/require"
+)
+
+// recordDDLSink records the DDLs applied to the downstream.
+type recordDDLSink struct {
+	ddls []*model.DDLEvent
+}
+
+func (s *recordDDLSink) WriteDDLEvent(_ context.Context, ddl *model.DDLEvent) error {
+	s.ddls = append(s.ddls, ddl)
+	return nil
+}
+
+func (s *recordDDLSink) WriteCheckpointTs(_ context.Context, _ uint64, _ []*model.TableInfo) error {
+	return nil
+}
+
+func (s *recordDDLSink) Close() {}
+
+// testKafka encodes the events into the messages of the data topic and the DDL topic.
+type testKafka struct {
+	t       *testing.T
+	encoder codec.RowEventEncoder
+	offset  kafka.Offset
+}
+
+func (k *testKafka) newMessage(topic string, partition int32, key, value []byte) *kafka.Message {
+	k.offset++
+	return &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: k.offset},
+		Key:            key,
+		Value:          value,
+	}
+}
+
+func (k *testKafka) ddl(topic string, partition int32, ddl *model.DDLEvent) *kafka.Message {
+	m, err := k.encoder.EncodeDDLEvent(ddl)
+	require.NoError(k.t, err)
+	return k.newMessage(topic, partition, m.Key, m.Value)
+}
+
+func (k *testKafka) fence(topic string, partition int32, fence *ddlmq.DDLFence) *kafka.Message {
+	value, err := json.Marshal(fence)
+	require.NoError(k.t, err)
+	m, err := k.encoder.(codec.ControlMessageEncoder).EncodeControlMessage(fence.Key(), value, fence.CommitTs)
+	require.NoError(k.t, err)
+	return k.newMessage(topic, partition, m.Key, m.Value)
+}
+
+func (k *testKafka) row(topic string, partition int32, row *model.RowChangedEvent) *kafka.Message {
+	err := k.encoder.AppendRowChangedEvent(context.Background(), topic, row, func() {})
+	require.NoError(k.t, err)
+	messages := k.encoder.Build()
+	require.Len(k.t, messages, 1)
+	return k.newMessage(topic, partition, messages[0].Key, messages[0].Value)
+}
+
+func (k *testKafka) watermark(topic string, partition int32, ts uint64) *kafka.Message {
+	m, err := k.encoder.EncodeCheckpointEvent(ts)
+	require.NoError(k.t, err)
+	return k.newMessage(topic, partition, m.Key, m.Value)
+}
+
+func newTestWriter(t *testing.T, ctx context.Context, protocol, config string) (*writer, *recordDDLSink) {
+	configFile := filepath.Join(t.TempDir(), "changefeed.toml")
+	require.NoError(t, os.WriteFile(configFile, []byte(config), 0o600))
+
+	upstreamURI, err := url.Parse("kafka://127.0.0.1:9092/data?partition-num=2&protocol=" + protocol)
+	require.NoError(t, err)
+	o := newOption()
+	o.downstreamURI = "blackhole://"
+	require.NoError(t, o.Adjust(upstreamURI, configFile))
+
+	w := newWriter(ctx, o)
+	t.Cleanup(w.close)
+	ddlSink := &recordDDLSink{}
+	w.ddlSink = ddlSink
+	return w, ddlSink
+}
+
+func TestWriteMessageWithDDLTopic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, ddlSink := newTestWriter(t, ctx, "simple", `
+[sink]
+dispatchers = [
+	{matcher = ['test.*'], partition = "table", ddl-topic = "ddl"},
+]
+`)
+	require.Equal(t, []string{"ddl"}, w.ddlTopics)
+
+	builder, err := simple.NewBuilder(ctx, w.option.codecConfig)
+	require.NoError(t, err)
+	k := &testKafka{t: t, encoder: builder.Build()}
+
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	createTable := helper.DDL2Event("create table test.t(a int primary key, b int)")
+	row := helper.DML2Event("insert into test.t values (1, 1)", "test", "t")
+	row.CommitTs = createTable.CommitTs + 1
+	addColumn := helper.DDL2Event("alter table test.t add column c int")
+	require.Greater(t, addColumn.CommitTs, row.CommitTs)
+	target, _, err := w.eventRouter.GetPartitionForRowChange(row, 2)
+	require.NoError(t, err)
+
+	newFence := func(ddl *model.DDLEvent) *ddlmq.DDLFence {
+		return &ddlmq.DDLFence{
+			Schema:       "test",
+			Table:        "t",
+			CommitTs:     ddl.CommitTs,
+			DDLTopic:     "ddl",
+			Topic:        "data",
+			PartitionNum: 2,
+		}
+	}
+
+	// the fence may reach a partition before the DDL is received from the DDL topic.
+	w.WriteMessage(ctx, k.fence("data", 0, newFence(createTable)))
+	w.WriteMessage(ctx, k.ddl("ddl", 0, createTable))
+	w.WriteMessage(ctx, k.fence("ddl", 0, newFence(createTable)))
+	// the fences of the other data topics are ignored.
+	otherFence := newFence(createTable)
+	otherFence.Topic = "other"
+	w.WriteMessage(ctx, k.fence("ddl", 0, otherFence))
+	require.Empty(t, w.ddlList)
+	w.WriteMessage(ctx, k.fence("data", 1, newFence(createTable)))
+	// all partitions reached the fence, but the DDL waits for the watermark.
+	require.Len(t, w.ddlList, 1)
+	require.Empty(t, ddlSink.ddls)
+
+	// the row is decoded by the table schema received from the DDL topic.
+	w.WriteMessage(ctx, k.row("data", target, row))
+	require.Len(t, w.progresses[target].eventGroups, 1)
+	w.WriteMessage(ctx, k.watermark("data", 0, row.CommitTs))
+	w.WriteMessage(ctx, k.watermark("data", 1, row.CommitTs))
+	require.Len(t, ddlSink.ddls, 1)
+	require.Equal(t, createTable.Query, ddlSink.ddls[0].Query)
+
+	// the DDL waits for all partitions to reach the fence, even if the watermark allows.
+	w.WriteMessage(ctx, k.ddl("ddl", 0, addColumn))
+	w.WriteMessage(ctx, k.fence("ddl", 0, newFence(addColumn)))
+	w.WriteMessage(ctx, k.fence("data", 0, newFence(addColumn)))
+	w.WriteMessage(ctx, k.watermark("data", 0, addColumn.CommitTs))
+	w.WriteMessage(ctx, k.watermark("data", 1, addColumn.CommitTs))
+	require.Len(t, ddlSink.ddls, 1)
+	w.WriteMessage(ctx, k.fence("data", 1, newFence(addColumn)))
+	require.Len(t, ddlSink.ddls, 2)
+	require.Equal(t, addColumn.Query, ddlSink.ddls[1].Query)
+	require.Empty(t, w.fences)
+}
+
+func TestWriteMessageFenceWithoutDDLTopic(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, _ := newTestWriter(t, ctx, "simple", "")
+	require.Empty(t, w.ddlTopics)
+
+	builder, err := simple.NewBuilder(ctx, common.NewConfig(w.option.protocol))
+	require.NoError(t, err)
+	k := &testKafka{t: t, encoder: builder.Build()}
+	// the DDLs fenced are never received, so the consumer refuses to go on.
+	require.Panics(t, func() {
+		w.WriteMessage(ctx, k.fence("data", 0, &ddlmq.DDLFence{
+			Schema:       "test",
+			Table:        "t",
+			CommitTs:     1,
+			DDLTopic:     "ddl",
+			Topic:        "data",
+			PartitionNum: 2,
+		}))
+	})
+}
+
+func TestWriteMessageWithSchemaVersion(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	w, ddlSink := newTestWriter(t, ctx, "canal-json&enable-tidb-extension=true", `
+[sink]
+enable-schema-version = true
+`)
+	require.True(t, w.option.codecConfig.EnableSchemaVersion)
+
+	builder, err := canal.NewJSONRowEventEncoderBuilder(ctx, w.option.codecConfig)
+	require.NoError(t, err)
+	k := &testKafka{t: t, encoder: builder.Build()}
+
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	createTable := helper.DDL2Event("create table test.t(a int primary key, b int)")
+	row := helper.DML2Event("insert into test.t values (1, 1)", "test", "t")
+	addColumn := helper.DDL2Event("alter table test.t add column c int")
+	newRow := helper.DML2Event("insert into test.t values (2, 2, 2)", "test", "t")
+	require.Greater(t, newRow.TableInfo.UpdateTS, row.TableInfo.UpdateTS)
+	target, _, err := w.eventRouter.GetPartitionForRowChange(row, 2)
+	require.NoError(t, err)
+
+	// the bootstrap message announces the schema version, but it's not applied.
+	w.WriteMessage(ctx, k.ddl("data", target, model.NewBootstrapDDLEvent(createTable.TableInfo)))
+	require.Empty(t, w.ddlList)
+	w.WriteMessage(ctx, k.row("data", target, row))
+	require.Len(t, w.progresses[target].eventGroups, 1)
+
+	// the row encoded with the schema version announced by the DDL.
+	for partition := int32(0); partition < 2; partition++ {
+		w.WriteMessage(ctx, k.ddl("data", partition, addColumn))
+	}
+	require.Len(t, w.ddlList, 1)
+	w.WriteMessage(ctx, k.row("data", target, newRow))
+	for _, group := range w.progresses[target].eventGroups {
+		require.Len(t, group.events, 2)
+	}
+	require.Empty(t, ddlSink.ddls)
+
+	// the row is encoded with a schema version whose DDL is lost.
+	helper.DDL2Event("alter table test.t drop column c")
+	lostRow := helper.DML2Event("insert into test.t values (3, 3)", "test", "t")
+	require.Greater(t, lostRow.TableInfo.UpdateTS, newRow.TableInfo.UpdateTS)
+	require.Panics(t, func() {
+		w.WriteMessage(ctx, k.row("data", target, lostRow))
+	})
+}
@@ -15,6 +15,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"math"
 	"net/url"
@@ -46,6 +47,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/sink/codec"
 	"github.com/pingcap/tiflow/pkg/sink/codec/canal"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/codec/debezium"
 	tpulsar "github.com/pingcap/tiflow/pkg/sink/pulsar"
 	"github.com/pingcap/tiflow/pkg/spanz"
 	"github.com/pingcap/tiflow/pkg/util"
@@ -81,6 +83,10 @@ type ConsumerOption struct {
 
 	downstreamURI string
 	partitionNum  int
+
+	// upstreamTiDBDSN is the dsn of the upstream TiDB cluster, which is used to
+	// query the row of the message encoded with the handle key only.
+	upstreamTiDBDSN string
 }
 
 func newConsumerOption() *ConsumerOption {
@@ -116,7 +122,7 @@ func (o *ConsumerOption) Adjust(upstreamURI *url.URL, configFile string) {
 			log.Panic("invalid protocol", zap.Error(err), zap.String("protocol", s))
 		}
 		if !sutil.IsPulsarSupportedProtocols(protocol) {
-			log.Panic("unsupported protocol, pulsar sink currently only support these protocols: [canal-json, canal, maxwell]",
+			log.Panic("unsupported protocol, pulsar sink currently only support these protocols: [canal-json, debezium]",
 				zap.String("protocol", s))
 		}
 		o.protocol = protocol
@@ -129,8 +135,9 @@ func (o *ConsumerOption) Adjust(upstreamURI *url.URL, configFile string) {
 			log.Panic("invalid enable-tidb-extension of upstream-uri")
 		}
 		if enableTiDBExtension {
-			if o.protocol != config.ProtocolCanalJSON && o.protocol != config.ProtocolAvro {
-				log.Panic("enable-tidb-extension only work with canal-json / avro")
+			if o.protocol != config.ProtocolCanalJSON && o.protocol != config.ProtocolAvro &&
+				o.protocol != config.ProtocolDebezium {
+				log.Panic("enable-tidb-extension only work with canal-json / avro / debezium")
 			}
 		}
 		o.enableTiDBExtension = enableTiDBExtension
@@ -159,6 +166,7 @@ func main() {
 	cmd.Flags().StringVar(&configFile, "config", "", "config file for changefeed")
 	cmd.Flags().StringVar(&upstreamURIStr, "upstream-uri", "", "pulsar uri")
 	cmd.Flags().StringVar(&consumerOption.downstreamURI, "downstream-uri", "", "downstream sink uri")
+	cmd.Flags().StringVar(&consumerOption.upstreamTiDBDSN, "upstream-tidb-dsn", "", "upstream TiDB DSN")
 	cmd.Flags().StringVar(&consumerOption.timezone, "tz", "System", "Specify time zone of pulsar consumer")
 	cmd.Flags().StringVar(&consumerOption.ca, "ca", "", "CA certificate path for pulsar SSL connection")
 	cmd.Flags().StringVar(&consumerOption.cert, "cert", "", "Certificate path for pulsar SSL connection")
@@ -354,6 +362,9 @@ type Consumer struct {
 	tz *time.Location
 
 	codecConfig *common.Config
+	// decoder is reused by all messages, since all messages are handled
+	// by one goroutine.
+	decoder codec.RowEventDecoder
 
 	option *ConsumerOption
 }
@@ -378,9 +389,34 @@ func NewConsumer(ctx context.Context, o *ConsumerOption) (*Consumer, error) {
 
 	c.codecConfig = common.NewConfig(o.protocol)
 	c.codecConfig.EnableTiDBExtension = o.enableTiDBExtension
+	c.codecConfig.TimeZone = tz
 	if c.codecConfig.Protocol == config.ProtocolAvro {
 		c.codecConfig.AvroEnableWatermark = true
 	}
+	// the large message handle config should be the same as the changefeed,
+	// to replay the claim-check and handle-key-only messages.
+	if o.replicaConfig.Sink.PulsarConfig != nil && o.replicaConfig.Sink.PulsarConfig.LargeMessageHandle != nil {
+		c.codecConfig.LargeMessageHandle = o.replicaConfig.Sink.PulsarConfig.LargeMessageHandle
+	}
+
+	var db *sql.DB
+	if o.upstreamTiDBDSN != "" {
+		db, err = openDB(ctx, o.upstreamTiDBDSN)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	switch c.codecConfig.Protocol {
+	case config.ProtocolCanalJSON:
+		c.decoder, err = canal.NewBatchDecoder(ctx, c.codecConfig, db)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	case config.ProtocolDebezium:
+		c.decoder = debezium.NewDecoder(c.codecConfig, db)
+	default:
+		log.Panic("Protocol not supported", zap.Any("Protocol", c.codecConfig.Protocol))
+	}
 
 	c.sinks = make([]*partitionSinks, o.partitionNum)
 	ctx, cancel := context.WithCancel(ctx)
@@ -454,24 +490,7 @@ func (c *Consumer) HandleMsg(msg pulsar.Message) error {
 		panic("sink should initialized")
 	}
 
-	ctx := context.Background()
-	var (
-		decoder codec.RowEventDecoder
-		err     error
-	)
-
-	switch c.codecConfig.Protocol {
-	case config.ProtocolCanalJSON:
-		decoder, err = canal.NewBatchDecoder(ctx, c.codecConfig, nil)
-		if err != nil {
-			return err
-		}
-	default:
-		log.Panic("Protocol not supported", zap.Any("Protocol", c.codecConfig.Protocol))
-	}
-	if err != nil {
-		return errors.Trace(err)
-	}
+	decoder := c.decoder
 
 	if err := decoder.AddKeyValue([]byte(msg.Key()), msg.Payload()); err != nil {
 		log.Error("add key value to the decoder failed", zap.Error(err))
@@ -590,6 +609,27 @@ func (c *Consumer) HandleMsg(msg pulsar.Message) error {
 	return nil
 }
 
+func openDB(ctx context.Context, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		log.Error("open db failed", zap.Error(err))
+		return nil, errors.Trace(err)
+	}
+
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(10)
+	db.SetConnMaxLifetime(10 * time.Minute)
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err = db.PingContext(ctx); err != nil {
+		log.Error("ping db failed", zap.String("dsn", dsn), zap.Error(err))
+		return nil, errors.Trace(err)
+	}
+	log.Info("open db success", zap.String("dsn", dsn))
+	return db, nil
+}
+
 // append DDL wait to be handled, only consider the constraint among DDLs.
 // for DDL a / b received in the order, a.CommitTs < b.CommitTs should be true.
 func (c *Consumer) appendDDL(ddl *model.DDLEvent) {
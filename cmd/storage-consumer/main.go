@@ -47,6 +47,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/sink/codec/canal"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"github.com/pingcap/tiflow/pkg/sink/codec/csv"
+	"github.com/pingcap/tiflow/pkg/sink/codec/parquet"
 	"github.com/pingcap/tiflow/pkg/spanz"
 	putil "github.com/pingcap/tiflow/pkg/util"
 	"github.com/pingcap/tiflow/pkg/version"
@@ -134,7 +135,7 @@ type consumer struct {
 }
 
 func newConsumer(ctx context.Context) (*consumer, error) {
-	_, err := putil.GetTimezone(timezone)
+	tz, err := putil.GetTimezone(timezone)
 	if err != nil {
 		return nil, errors.Annotate(err, "can not load timezone")
 	}
@@ -159,6 +160,7 @@ func newConsumer(ctx context.Context) (*consumer, error) {
 	switch putil.GetOrZero(replicaConfig.Sink.Protocol) {
 	case config.ProtocolCsv.String():
 	case config.ProtocolCanalJSON.String():
+	case config.ProtocolParquet.String():
 	default:
 		return nil, fmt.Errorf(
 			"data encoded in protocol %s is not supported yet",
@@ -176,6 +178,8 @@ func newConsumer(ctx context.Context) (*consumer, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the timestamp values in parquet files are decoded in this timezone.
+	codecConfig.TimeZone = tz
 
 	extension := sinkutil.GetFileExtension(protocol)
 
@@ -310,6 +314,11 @@ func (c *consumer) emitDMLEvents(
 		if err != nil {
 			return errors.Trace(err)
 		}
+	case config.ProtocolParquet:
+		decoder, err = parquet.NewBatchDecoder(ctx, c.codecCfg, tableInfo, content)
+		if err != nil {
+			return errors.Trace(err)
+		}
 	case config.ProtocolCanalJSON:
 		// Always enable tidb extension for canal-json protocol
 		// because we need to get the commit ts from the extension field.
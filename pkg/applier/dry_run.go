@@ -0,0 +1,239 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tidb/pkg/parser/charset"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/quotes"
+	"github.com/pingcap/tiflow/pkg/sqlmodel"
+)
+
+// sqlWriter writes the SQL statements of the redo logs to a file in the dry-run
+// mode, instead of executing them. The rows are written as the statements
+// executed by the MySQL sink in the safe mode, and grouped by transactions.
+type sqlWriter struct {
+	// closer is nil if the underlying writer isn't owned by the sqlWriter.
+	closer io.Closer
+	w      *bufio.Writer
+	// startTs is the start ts of the current transaction, it's 0 if
+	// there is no open transaction.
+	startTs model.Ts
+}
+
+func newSQLWriter(path string) (*sqlWriter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrRedoFileOp, err)
+	}
+	s := newSQLStreamWriter(file)
+	s.closer = file
+	return s, nil
+}
+
+// newSQLStreamWriter creates a sqlWriter which writes the SQL statements to w,
+// w is not closed when the sqlWriter is closed.
+func newSQLStreamWriter(w io.Writer) *sqlWriter {
+	return &sqlWriter{w: bufio.NewWriter(w)}
+}
+
+func (s *sqlWriter) writeRow(row *model.RowChangedEvent) error {
+	if s.startTs != row.StartTs {
+		s.endTxn()
+		fmt.Fprintf(s.w, "-- start-ts: %d, commit-ts: %d\nBEGIN;\n", row.StartTs, row.CommitTs)
+		s.startTs = row.StartTs
+	}
+	query, args := genRowSQL(row)
+	if query == "" {
+		return nil
+	}
+	query, err := interpolateSQL(query, args)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.WriteString(query + ";\n")
+	return errors.WrapError(errors.ErrRedoFileOp, err)
+}
+
+func (s *sqlWriter) writeDDL(ddl *model.DDLEvent) error {
+	s.endTxn()
+	fmt.Fprintf(s.w, "-- start-ts: %d, commit-ts: %d\n", ddl.StartTs, ddl.CommitTs)
+	if ddl.TableInfo != nil && ddl.TableInfo.TableName.Schema != "" {
+		fmt.Fprintf(s.w, "USE %s;\n", quotes.QuoteName(ddl.TableInfo.TableName.Schema))
+	}
+	_, err := s.w.WriteString(strings.TrimSuffix(strings.TrimSpace(ddl.Query), ";") + ";\n")
+	return errors.WrapError(errors.ErrRedoFileOp, err)
+}
+
+func (s *sqlWriter) endTxn() {
+	if s.startTs != 0 {
+		s.w.WriteString("COMMIT;\n") //nolint:errcheck
+		s.startTs = 0
+	}
+}
+
+func (s *sqlWriter) close() error {
+	s.endTxn()
+	err := s.w.Flush()
+	if s.closer != nil {
+		if closeErr := s.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return errors.WrapError(errors.ErrRedoFileOp, err)
+}
+
+// genRowSQL returns the statement of the row, the insert is translated to REPLACE
+// since the redo logs are applied in the safe mode.
+func genRowSQL(row *model.RowChangedEvent) (string, []interface{}) {
+	tidbTableInfo := row.TableInfo.TableInfo
+	if row.TableInfo.HasVirtualColumns() {
+		tidbTableInfo = model.BuildTiDBTableInfoWithoutVirtualColumns(tidbTableInfo)
+	}
+	preValues := columnValues(row.PreColumns, row.TableInfo)
+	postValues := columnValues(row.Columns, row.TableInfo)
+	change := sqlmodel.NewRowChange(
+		&row.TableInfo.TableName, nil, preValues, postValues, tidbTableInfo, nil, nil)
+	switch {
+	case row.IsInsert():
+		return change.GenSQL(sqlmodel.DMLReplace)
+	case row.IsDelete():
+		return change.GenSQL(sqlmodel.DMLDelete)
+	default:
+		return change.GenSQL(sqlmodel.DMLUpdate)
+	}
+}
+
+// columnValues returns the values of the columns, the bytes of the
+// non-binary columns are converted to strings.
+func columnValues(cols []*model.ColumnData, tableInfo *model.TableInfo) []interface{} {
+	if len(cols) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, len(cols))
+	for _, col := range cols {
+		if col == nil {
+			values = append(values, nil)
+			continue
+		}
+		value := col.Value
+		colInfo := tableInfo.ForceGetColumnInfo(col.ColumnID)
+		if b, ok := value.([]byte); ok &&
+			colInfo.GetCharset() != "" && colInfo.GetCharset() != charset.CharsetBin {
+			value = string(b)
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// interpolateSQL replaces the placeholders of the query with the literals of the args.
+func interpolateSQL(query string, args []interface{}) (string, error) {
+	var b strings.Builder
+	b.Grow(len(query) + len(args)*8)
+	argIdx := 0
+	inIdentifier := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '`':
+			inIdentifier = !inIdentifier
+		case c == '?' && !inIdentifier:
+			if argIdx >= len(args) {
+				return "", errors.ErrRedoFileOp.GenWithStack(
+					"the number of args %d doesn't match the query %s", len(args), query)
+			}
+			b.WriteString(sqlLiteral(args[argIdx]))
+			argIdx++
+			continue
+		}
+		b.WriteByte(c)
+	}
+	if argIdx != len(args) {
+		return "", errors.ErrRedoFileOp.GenWithStack(
+			"the number of args %d doesn't match the query %s", len(args), query)
+	}
+	return b.String(), nil
+}
+
+// sqlLiteral returns the SQL literal of the value.
+func sqlLiteral(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int:
+		return strconv.Itoa(v)
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		if len(v) == 0 {
+			return "''"
+		}
+		return "X'" + hex.EncodeToString(v) + "'"
+	case string:
+		return quoteString(v)
+	case time.Time:
+		return quoteString(v.Format("2006-01-02 15:04:05.999999"))
+	default:
+		return quoteString(fmt.Sprintf("%v", v))
+	}
+}
+
+// quoteString quotes the string with the backslash escapes, as the MySQL client does.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\x1a':
+			b.WriteString(`\Z`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
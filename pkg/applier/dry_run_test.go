@@ -0,0 +1,40 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateSQL(t *testing.T) {
+	t.Parallel()
+
+	query, err := interpolateSQL(
+		"UPDATE `db`.`t?` SET `a` = ?, `b` = ?, `c` = ?, `d` = ? WHERE `id` = ? AND `e` = ?",
+		[]interface{}{
+			nil, "a'b\\c\n", []byte{0x01, 0xff}, 1.5, uint64(18446744073709551615),
+			time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.UTC),
+		})
+	require.NoError(t, err)
+	require.Equal(t, "UPDATE `db`.`t?` SET `a` = NULL, `b` = 'a\\'b\\\\c\\n', `c` = X'01ff', "+
+		"`d` = 1.5 WHERE `id` = 18446744073709551615 AND `e` = '2024-01-02 03:04:05.6'", query)
+
+	_, err = interpolateSQL("DELETE FROM `t` WHERE `a` = ?", nil)
+	require.Error(t, err)
+	_, err = interpolateSQL("DELETE FROM `t` WHERE `a` = ?", []interface{}{1, 2})
+	require.Error(t, err)
+}
@@ -0,0 +1,390 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/filter"
+	"github.com/tikv/client-go/v2/oracle"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// DumpFormatJSON dumps a JSON object per line for each event.
+	DumpFormatJSON = "json"
+	// DumpFormatSQL dumps the SQL statements of the events.
+	DumpFormatSQL = "sql"
+
+	defaultStatsInterval = time.Minute
+)
+
+var errDumpFinished = errors.New("dump finished, can exit safely")
+
+// RedoDumperConfig is the configuration used by a redo log dumper
+type RedoDumperConfig struct {
+	Storage string
+	Dir     string
+
+	// StartTs and EndTs restrict the events to dump to the commit ts range
+	// (StartTs, EndTs], they default to the checkpoint ts and the resolved ts
+	// of the meta if they are 0. StartTs can be less than the checkpoint ts
+	// if the redo logs are retained by consistent.retention.
+	StartTs uint64
+	EndTs   uint64
+	// FilterRules restricts the tables to dump, all tables are dumped if it's empty.
+	FilterRules []string
+	// Format is the output format of the events, json or sql.
+	Format string
+	// StatsInterval is the length of the ts ranges in the statistics.
+	StatsInterval time.Duration
+}
+
+// RedoDumper dumps the events or the statistics of the redo logs
+type RedoDumper struct {
+	cfg    *RedoDumperConfig
+	filter filter.Filter
+}
+
+// NewRedoDumper creates a new RedoDumper instance
+func NewRedoDumper(cfg *RedoDumperConfig) *RedoDumper {
+	return &RedoDumper{cfg: cfg}
+}
+
+// dumpEvent is the JSON representation of an event in the redo logs.
+type dumpEvent struct {
+	// Type is one of insert, update, delete and ddl.
+	Type       string                 `json:"type"`
+	StartTs    uint64                 `json:"start-ts"`
+	CommitTs   uint64                 `json:"commit-ts"`
+	Schema     string                 `json:"schema,omitempty"`
+	Table      string                 `json:"table,omitempty"`
+	Columns    map[string]interface{} `json:"columns,omitempty"`
+	PreColumns map[string]interface{} `json:"pre-columns,omitempty"`
+	Query      string                 `json:"query,omitempty"`
+}
+
+// TableStatistics is the statistics of the events of a table.
+type TableStatistics struct {
+	Schema      string `json:"schema"`
+	Table       string `json:"table"`
+	Inserts     uint64 `json:"inserts"`
+	Updates     uint64 `json:"updates"`
+	Deletes     uint64 `json:"deletes"`
+	DDLs        uint64 `json:"ddls"`
+	MinCommitTs uint64 `json:"min-commit-ts"`
+	MaxCommitTs uint64 `json:"max-commit-ts"`
+}
+
+// TsRangeStatistics is the statistics of the events whose commit ts are
+// in the range [StartTs, EndTs).
+type TsRangeStatistics struct {
+	StartTs   uint64    `json:"start-ts"`
+	EndTs     uint64    `json:"end-ts"`
+	StartTime time.Time `json:"start-time"`
+	Rows      uint64    `json:"rows"`
+	DDLs      uint64    `json:"ddls"`
+}
+
+// RedoStatistics is the statistics of the events in the redo logs.
+type RedoStatistics struct {
+	CheckpointTs uint64               `json:"checkpoint-ts"`
+	ResolvedTs   uint64               `json:"resolved-ts"`
+	Rows         uint64               `json:"rows"`
+	DDLs         uint64               `json:"ddls"`
+	Tables       []*TableStatistics   `json:"tables"`
+	TsRanges     []*TsRangeStatistics `json:"ts-ranges"`
+}
+
+// Dump writes the events of the redo logs to w in the order of commit ts.
+func (d *RedoDumper) Dump(ctx context.Context, w io.Writer) error {
+	switch d.cfg.Format {
+	case "", DumpFormatJSON:
+		bw := bufio.NewWriter(w)
+		encoder := json.NewEncoder(bw)
+		encode := func(event *dumpEvent) error {
+			return errors.WrapError(errors.ErrRedoFileOp, encoder.Encode(event))
+		}
+		err := d.iterate(ctx, func(row *model.RowChangedEvent) error {
+			return encode(newRowDumpEvent(row))
+		}, func(ddl *model.DDLEvent) error {
+			return encode(newDDLDumpEvent(ddl))
+		})
+		if err != nil {
+			return err
+		}
+		return errors.WrapError(errors.ErrRedoFileOp, bw.Flush())
+	case DumpFormatSQL:
+		s := newSQLStreamWriter(w)
+		if err := d.iterate(ctx, s.writeRow, s.writeDDL); err != nil {
+			return err
+		}
+		return s.close()
+	default:
+		return errors.ErrRedoConfigInvalid.GenWithStack(
+			"unsupported dump format %s, it should be json or sql", d.cfg.Format)
+	}
+}
+
+// Statistics breaks down the events of the redo logs per table and per ts range.
+func (d *RedoDumper) Statistics(ctx context.Context) (*RedoStatistics, error) {
+	interval := d.cfg.StatsInterval
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+	intervalMs := interval.Milliseconds()
+	if intervalMs == 0 {
+		intervalMs = 1
+	}
+
+	stats := &RedoStatistics{}
+	tables := make(map[model.TableName]*TableStatistics)
+	tsRanges := make(map[int64]*TsRangeStatistics)
+	getTable := func(tableInfo *model.TableInfo, commitTs uint64) *TableStatistics {
+		name := tableInfo.TableName
+		name.TableID, name.IsPartition = 0, false
+		table, ok := tables[name]
+		if !ok {
+			table = &TableStatistics{Schema: name.Schema, Table: name.Table, MinCommitTs: commitTs}
+			tables[name] = table
+			stats.Tables = append(stats.Tables, table)
+		}
+		if commitTs < table.MinCommitTs {
+			table.MinCommitTs = commitTs
+		}
+		if commitTs > table.MaxCommitTs {
+			table.MaxCommitTs = commitTs
+		}
+		return table
+	}
+	getTsRange := func(commitTs uint64) *TsRangeStatistics {
+		start := oracle.ExtractPhysical(commitTs) / intervalMs * intervalMs
+		tsRange, ok := tsRanges[start]
+		if !ok {
+			tsRange = &TsRangeStatistics{
+				StartTs:   oracle.ComposeTS(start, 0),
+				EndTs:     oracle.ComposeTS(start+intervalMs, 0),
+				StartTime: oracle.GetTimeFromTS(oracle.ComposeTS(start, 0)),
+			}
+			tsRanges[start] = tsRange
+			stats.TsRanges = append(stats.TsRanges, tsRange)
+		}
+		return tsRange
+	}
+
+	err := d.iterate(ctx, func(row *model.RowChangedEvent) error {
+		stats.Rows++
+		getTsRange(row.CommitTs).Rows++
+		table := getTable(row.TableInfo, row.CommitTs)
+		switch {
+		case row.IsInsert():
+			table.Inserts++
+		case row.IsDelete():
+			table.Deletes++
+		default:
+			table.Updates++
+		}
+		return nil
+	}, func(ddl *model.DDLEvent) error {
+		stats.DDLs++
+		getTsRange(ddl.CommitTs).DDLs++
+		if ddl.TableInfo != nil {
+			getTable(ddl.TableInfo, ddl.CommitTs).DDLs++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(stats.Tables, func(i, j int) bool {
+		if stats.Tables[i].Schema != stats.Tables[j].Schema {
+			return stats.Tables[i].Schema < stats.Tables[j].Schema
+		}
+		return stats.Tables[i].Table < stats.Tables[j].Table
+	})
+	sort.Slice(stats.TsRanges, func(i, j int) bool {
+		return stats.TsRanges[i].StartTs < stats.TsRanges[j].StartTs
+	})
+	return stats, nil
+}
+
+// iterate reads the events in the ts range and not filtered, and calls
+// onRow and onDDL in the order of commit ts.
+func (d *RedoDumper) iterate(
+	ctx context.Context,
+	onRow func(*model.RowChangedEvent) error,
+	onDDL func(*model.DDLEvent) error,
+) (err error) {
+	if d.filter, err = newTableFilter(d.cfg.FilterRules); err != nil {
+		return err
+	}
+	rd, err := createRedoReader(ctx, &RedoApplierConfig{
+		Storage: d.cfg.Storage,
+		Dir:     d.cfg.Dir,
+		startTs: d.cfg.StartTs,
+	})
+	if err != nil {
+		return err
+	}
+	checkpointTs, resolvedTs, err := rd.ReadMeta(ctx)
+	if err != nil {
+		return err
+	}
+	startTs, endTs := checkpointTs, resolvedTs
+	if d.cfg.StartTs != 0 {
+		startTs = d.cfg.StartTs
+	}
+	if d.cfg.EndTs != 0 && d.cfg.EndTs < endTs {
+		endTs = d.cfg.EndTs
+	}
+	log.Info("dump redo log starts",
+		zap.Uint64("checkpointTs", checkpointTs),
+		zap.Uint64("resolvedTs", resolvedTs),
+		zap.Uint64("startTs", startTs),
+		zap.Uint64("endTs", endTs),
+		zap.Strings("filterRules", d.cfg.FilterRules))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return rd.Run(egCtx)
+	})
+	eg.Go(func() error {
+		readNextRow := func() (*model.RowChangedEvent, error) {
+			for {
+				row, err := rd.ReadNextRow(egCtx)
+				if err != nil || row == nil || row.CommitTs > endTs {
+					return nil, err
+				}
+				if row.CommitTs > startTs && !d.ignoreTable(row.TableInfo) {
+					return row, nil
+				}
+			}
+		}
+		readNextDDL := func() (*model.DDLEvent, error) {
+			for {
+				ddl, err := rd.ReadNextDDL(egCtx)
+				if err != nil || ddl == nil || ddl.CommitTs > endTs {
+					return nil, err
+				}
+				if ddl.CommitTs > startTs && !d.ignoreDDL(ddl) {
+					return ddl, nil
+				}
+			}
+		}
+
+		row, err := readNextRow()
+		if err != nil {
+			return err
+		}
+		ddl, err := readNextDDL()
+		if err != nil {
+			return err
+		}
+		// The rows are dumped before the DDL with the same commit ts,
+		// which is the same as the applier.
+		for row != nil || ddl != nil {
+			if ddl != nil && (row == nil || row.CommitTs > ddl.CommitTs) {
+				if err := onDDL(ddl); err != nil {
+					return err
+				}
+				if ddl, err = readNextDDL(); err != nil {
+					return err
+				}
+			} else {
+				if err := onRow(row); err != nil {
+					return err
+				}
+				if row, err = readNextRow(); err != nil {
+					return err
+				}
+			}
+		}
+		return errDumpFinished
+	})
+
+	err = eg.Wait()
+	if errors.Cause(err) != errDumpFinished {
+		return err
+	}
+	return nil
+}
+
+func (d *RedoDumper) ignoreTable(tableInfo *model.TableInfo) bool {
+	return d.filter != nil &&
+		d.filter.ShouldIgnoreTable(tableInfo.GetSchemaName(), tableInfo.GetTableName())
+}
+
+func (d *RedoDumper) ignoreDDL(ddl *model.DDLEvent) bool {
+	return d.filter != nil && ddl.TableInfo != nil &&
+		d.filter.ShouldDiscardDDL(ddl.Type, ddl.TableInfo.TableName.Schema, ddl.TableInfo.TableName.Table)
+}
+
+func newRowDumpEvent(row *model.RowChangedEvent) *dumpEvent {
+	event := &dumpEvent{
+		StartTs:    row.StartTs,
+		CommitTs:   row.CommitTs,
+		Schema:     row.TableInfo.GetSchemaName(),
+		Table:      row.TableInfo.GetTableName(),
+		Columns:    columnValueMap(row.Columns, row.TableInfo),
+		PreColumns: columnValueMap(row.PreColumns, row.TableInfo),
+	}
+	switch {
+	case row.IsInsert():
+		event.Type = "insert"
+	case row.IsDelete():
+		event.Type = "delete"
+	default:
+		event.Type = "update"
+	}
+	return event
+}
+
+func newDDLDumpEvent(ddl *model.DDLEvent) *dumpEvent {
+	event := &dumpEvent{
+		Type:     "ddl",
+		StartTs:  ddl.StartTs,
+		CommitTs: ddl.CommitTs,
+		Query:    ddl.Query,
+	}
+	if ddl.TableInfo != nil {
+		event.Schema = ddl.TableInfo.TableName.Schema
+		event.Table = ddl.TableInfo.TableName.Table
+	}
+	return event
+}
+
+// columnValueMap returns the values of the columns keyed by the column names.
+func columnValueMap(cols []*model.ColumnData, tableInfo *model.TableInfo) map[string]interface{} {
+	values := columnValues(cols, tableInfo)
+	if len(values) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(values))
+	for i, col := range cols {
+		if col != nil {
+			m[tableInfo.ForceGetColumnName(col.ColumnID)] = values[i]
+		}
+	}
+	return m
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applier
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	mysqlParser "github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/redo/reader"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+// mockDumpReader replaces the redo reader with a MockReader that emits the events.
+func mockDumpReader(t *testing.T, checkpointTs, resolvedTs uint64) {
+	tableInfo := func(schema, table string) *model.TableInfo {
+		return model.BuildTableInfo(schema, table, []*model.Column{
+			{Name: "a", Type: mysqlParser.TypeLong, Flag: model.HandleKeyFlag | model.PrimaryKeyFlag},
+			{Name: "b", Type: mysqlParser.TypeVarchar, Charset: "utf8mb4"},
+		}, [][]int{{0}})
+	}
+	t1, t2 := tableInfo("test", "t1"), tableInfo("other", "t2")
+	columns := func(tableInfo *model.TableInfo, a int, b string) []*model.ColumnData {
+		return model.Columns2ColumnDatas([]*model.Column{
+			{Name: "a", Value: a}, {Name: "b", Value: []byte(b)},
+		}, tableInfo)
+	}
+	base := oracle.ComposeTS(3600*1000, 0)
+	rows := []*model.RowChangedEvent{
+		{StartTs: base + 1, CommitTs: base + 2, TableInfo: t1, Columns: columns(t1, 1, "x")},
+		{StartTs: base + 3, CommitTs: base + 4, TableInfo: t2, Columns: columns(t2, 1, "y")},
+		{
+			StartTs: base + 5, CommitTs: base + 6, TableInfo: t1,
+			PreColumns: columns(t1, 1, "x"), Columns: columns(t1, 1, "z"),
+		},
+		{
+			StartTs: oracle.ComposeTS(3660*1000, 0), CommitTs: oracle.ComposeTS(3660*1000, 1),
+			TableInfo: t1, PreColumns: columns(t1, 1, "z"),
+		},
+	}
+	ddls := []*model.DDLEvent{
+		{
+			StartTs: base + 5, CommitTs: base + 6, TableInfo: t1,
+			Query: "alter table t1 add column c int", Type: timodel.ActionAddColumn,
+		},
+	}
+	redoLogCh := make(chan *model.RowChangedEvent, len(rows))
+	ddlEventCh := make(chan *model.DDLEvent, len(ddls))
+	for _, row := range rows {
+		redoLogCh <- row
+	}
+	for _, ddl := range ddls {
+		ddlEventCh <- ddl
+	}
+	close(redoLogCh)
+	close(ddlEventCh)
+
+	createRedoReaderBak := createRedoReader
+	createRedoReader = func(ctx context.Context, cfg *RedoApplierConfig) (reader.RedoLogReader, error) {
+		return NewMockReader(checkpointTs, resolvedTs, redoLogCh, ddlEventCh), nil
+	}
+	t.Cleanup(func() {
+		createRedoReader = createRedoReaderBak
+	})
+}
+
+func TestDumpJSON(t *testing.T) {
+	base := oracle.ComposeTS(3600*1000, 0)
+	mockDumpReader(t, base, oracle.ComposeTS(3700*1000, 0))
+
+	var buf bytes.Buffer
+	dumper := NewRedoDumper(&RedoDumperConfig{StartTs: base + 2, FilterRules: []string{"test.*"}})
+	require.NoError(t, dumper.Dump(context.Background(), &buf))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	require.JSONEq(t, `{"type":"update","start-ts":943718400005,"commit-ts":943718400006,`+
+		`"schema":"test","table":"t1","columns":{"a":1,"b":"z"},"pre-columns":{"a":1,"b":"x"}}`, lines[0])
+	require.JSONEq(t, `{"type":"ddl","start-ts":943718400005,"commit-ts":943718400006,`+
+		`"schema":"test","table":"t1","query":"alter table t1 add column c int"}`, lines[1])
+	require.JSONEq(t, `{"type":"delete","start-ts":959447040000,"commit-ts":959447040001,`+
+		`"schema":"test","table":"t1","pre-columns":{"a":1,"b":"z"}}`, lines[2])
+}
+
+func TestDumpSQL(t *testing.T) {
+	base := oracle.ComposeTS(3600*1000, 0)
+	mockDumpReader(t, base, oracle.ComposeTS(3700*1000, 0))
+
+	var buf bytes.Buffer
+	dumper := NewRedoDumper(&RedoDumperConfig{
+		EndTs:       base + 5,
+		FilterRules: []string{"test.*"},
+		Format:      DumpFormatSQL,
+	})
+	require.NoError(t, dumper.Dump(context.Background(), &buf))
+	require.Equal(t, "-- start-ts: 943718400001, commit-ts: 943718400002\n"+
+		"BEGIN;\n"+
+		"REPLACE INTO `test`.`t1` (`a`,`b`) VALUES (1,'x');\n"+
+		"COMMIT;\n", buf.String())
+
+	dumper = NewRedoDumper(&RedoDumperConfig{Format: "csv"})
+	require.ErrorIs(t, dumper.Dump(context.Background(), &buf), errors.ErrRedoConfigInvalid)
+}
+
+func TestDumpStatistics(t *testing.T) {
+	base := oracle.ComposeTS(3600*1000, 0)
+	mockDumpReader(t, base, oracle.ComposeTS(3700*1000, 0))
+
+	dumper := NewRedoDumper(&RedoDumperConfig{StatsInterval: time.Minute})
+	stats, err := dumper.Statistics(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), stats.Rows)
+	require.Equal(t, uint64(1), stats.DDLs)
+	require.Equal(t, []*TableStatistics{
+		{Schema: "other", Table: "t2", Inserts: 1, MinCommitTs: base + 4, MaxCommitTs: base + 4},
+		{
+			Schema: "test", Table: "t1", Inserts: 1, Updates: 1, Deletes: 1, DDLs: 1,
+			MinCommitTs: base + 2, MaxCommitTs: oracle.ComposeTS(3660*1000, 1),
+		},
+	}, stats.Tables)
+	require.Len(t, stats.TsRanges, 2)
+	require.Equal(t, base, stats.TsRanges[0].StartTs)
+	require.Equal(t, oracle.ComposeTS(3660*1000, 0), stats.TsRanges[0].EndTs)
+	require.Equal(t, uint64(3), stats.TsRanges[0].Rows)
+	require.Equal(t, uint64(1), stats.TsRanges[0].DDLs)
+	require.Equal(t, oracle.ComposeTS(3660*1000, 0), stats.TsRanges[1].StartTs)
+	require.Equal(t, uint64(1), stats.TsRanges[1].Rows)
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/pingcap/tiflow/cdc/sink/tablesink"
 	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/filter"
 	"github.com/pingcap/tiflow/pkg/pdutil"
 	"github.com/pingcap/tiflow/pkg/redo"
 	"github.com/pingcap/tiflow/pkg/sink/mysql"
@@ -61,6 +62,21 @@ type RedoApplierConfig struct {
 	SinkURI string
 	Storage string
 	Dir     string
+
+	// TargetTs is the commit ts up to which the redo logs are applied,
+	// the logs are applied up to the resolved ts of the meta if it's 0.
+	TargetTs uint64
+	// FilterRules restricts the tables to apply, all tables are applied if it's empty.
+	FilterRules []string
+	// DryRunFile is the file to output the SQL statements instead of executing them.
+	DryRunFile string
+	// ReplicaConfig is the changefeed config used to create the sinks, such as the
+	// dispatch rules and the protocol of MQ sinks. The default config is used if it's nil.
+	ReplicaConfig *config.ReplicaConfig
+
+	// startTs is the ts after which the redo logs are read, it's used by the
+	// dumper to read the redo logs retained before the checkpoint.
+	startTs uint64
 }
 
 // RedoApplier implements a redo log applier
@@ -72,6 +88,11 @@ type RedoApplier struct {
 	ddlSink         ddlsink.Sink
 	appliedDDLCount uint64
 
+	// filter is nil if all tables are applied.
+	filter filter.Filter
+	// sqlWriter is not nil in the dry-run mode, which replaces the sinks.
+	sqlWriter *sqlWriter
+
 	memQuota     *memquota.MemQuota
 	pendingQuota uint64
 
@@ -81,20 +102,23 @@ type RedoApplier struct {
 	// We create it when we need it, and close it after we finish applying the redo logs.
 	tableSinks         map[model.TableID]tablesink.TableSink
 	tableResolvedTsMap map[model.TableID]*memquota.MemConsumeRecord
-	appliedLogCount    uint64
+	// tableInfos is used to write the checkpoint ts to the DDL sink after applying.
+	tableInfos      map[model.TableID]*model.TableInfo
+	appliedLogCount uint64
 
 	errCh chan error
 
-	// changefeedID is used to identify the changefeed that this applier belongs to.
-	// not used for now.
+	// changefeedID is used to identify the changefeed that this applier belongs to,
+	// which is used by the sinks to create clients and metrics.
 	changefeedID model.ChangeFeedID
 }
 
 // NewRedoApplier creates a new RedoApplier instance
 func NewRedoApplier(cfg *RedoApplierConfig) *RedoApplier {
 	return &RedoApplier{
-		cfg:   cfg,
-		errCh: make(chan error, 1024),
+		cfg:          cfg,
+		errCh:        make(chan error, 1024),
+		changefeedID: model.DefaultChangeFeedID(applierChangefeed),
 	}
 }
 
@@ -112,6 +136,7 @@ func (rac *RedoApplierConfig) toLogReaderConfig() (string, *reader.LogReaderConf
 		URI:                *uri,
 		Dir:                rac.Dir,
 		UseExternalStorage: redo.IsExternalStorage(uri.Scheme),
+		StartTs:            rac.startTs,
 	}
 	return uri.Scheme, cfg, nil
 }
@@ -127,20 +152,81 @@ func (ra *RedoApplier) catchError(ctx context.Context) error {
 	}
 }
 
-func (ra *RedoApplier) initSink(ctx context.Context) (err error) {
+// adjustTargetTs returns the ts up to which the redo logs are applied.
+func (rac *RedoApplierConfig) adjustTargetTs(checkpointTs, resolvedTs uint64) (uint64, error) {
+	if rac.TargetTs == 0 || rac.TargetTs >= resolvedTs {
+		if rac.TargetTs > resolvedTs {
+			log.Warn("target ts is larger than the resolved ts of redo meta, "+
+				"the redo logs are applied up to the resolved ts",
+				zap.Uint64("targetTs", rac.TargetTs),
+				zap.Uint64("resolvedTs", resolvedTs))
+		}
+		return resolvedTs, nil
+	}
+	if rac.TargetTs < checkpointTs {
+		return 0, errors.ErrRedoConfigInvalid.GenWithStack(
+			"target ts %d is less than the checkpoint ts %d of redo meta", rac.TargetTs, checkpointTs)
+	}
+	return rac.TargetTs, nil
+}
+
+func (ra *RedoApplier) initFilter() (err error) {
+	ra.filter, err = newTableFilter(ra.cfg.FilterRules)
+	return err
+}
+
+// newTableFilter creates a filter with the table filter rules,
+// it returns nil if there is no rule.
+func newTableFilter(rules []string) (filter.Filter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
 	replicaConfig := config.GetDefaultReplicaConfig()
-	ra.sinkFactory, err = dmlfactory.New(ctx, ra.changefeedID, ra.cfg.SinkURI, replicaConfig, ra.errCh, nil)
+	replicaConfig.Filter.Rules = rules
+	return filter.NewFilter(replicaConfig, "")
+}
+
+func (ra *RedoApplier) initSink(ctx context.Context) (err error) {
+	ra.tableSinks = make(map[model.TableID]tablesink.TableSink)
+	ra.tableResolvedTsMap = make(map[model.TableID]*memquota.MemConsumeRecord)
+	ra.tableInfos = make(map[model.TableID]*model.TableInfo)
+	if ra.cfg.DryRunFile != "" {
+		ra.sqlWriter, err = newSQLWriter(ra.cfg.DryRunFile)
+		return err
+	}
+
+	sinkURI, err := url.Parse(ra.cfg.SinkURI)
 	if err != nil {
+		return errors.WrapError(errors.ErrSinkURIInvalid, err)
+	}
+	replicaConfig := ra.cfg.ReplicaConfig
+	if replicaConfig == nil {
+		replicaConfig = config.GetDefaultReplicaConfig()
+	}
+	if err := replicaConfig.ValidateAndAdjust(sinkURI); err != nil {
 		return err
 	}
-	ra.ddlSink, err = ddlfactory.New(ctx, ra.changefeedID, ra.cfg.SinkURI, replicaConfig)
+	ra.sinkFactory, err = dmlfactory.New(ctx, ra.changefeedID, ra.cfg.SinkURI, replicaConfig, ra.errCh, nil)
 	if err != nil {
 		return err
 	}
+	ra.ddlSink, err = ddlfactory.New(ctx, ra.changefeedID, ra.cfg.SinkURI, replicaConfig)
+	return err
+}
 
-	ra.tableSinks = make(map[model.TableID]tablesink.TableSink)
-	ra.tableResolvedTsMap = make(map[model.TableID]*memquota.MemConsumeRecord)
-	return nil
+func (ra *RedoApplier) closeSink() {
+	if ra.sinkFactory != nil {
+		ra.sinkFactory.Close()
+	}
+	if ra.ddlSink != nil {
+		ra.ddlSink.Close()
+	}
+	if ra.sqlWriter != nil {
+		if err := ra.sqlWriter.close(); err != nil {
+			log.Warn("close the dry-run file failed",
+				zap.String("file", ra.cfg.DryRunFile), zap.Error(err))
+		}
+	}
 }
 
 func (ra *RedoApplier) bgReleaseQuota(ctx context.Context) error {
@@ -164,13 +250,23 @@ func (ra *RedoApplier) consumeLogs(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	targetTs, err := ra.cfg.adjustTargetTs(checkpointTs, resolvedTs)
+	if err != nil {
+		return err
+	}
 	log.Info("apply redo log starts",
 		zap.Uint64("checkpointTs", checkpointTs),
-		zap.Uint64("resolvedTs", resolvedTs))
+		zap.Uint64("resolvedTs", resolvedTs),
+		zap.Uint64("targetTs", targetTs),
+		zap.Strings("filterRules", ra.cfg.FilterRules),
+		zap.String("dryRunFile", ra.cfg.DryRunFile))
+	if err := ra.initFilter(); err != nil {
+		return err
+	}
 	if err := ra.initSink(ctx); err != nil {
 		return err
 	}
-	defer ra.sinkFactory.Close()
+	defer ra.closeSink()
 
 	shouldApplyDDL := func(row *model.RowChangedEvent, ddl *model.DDLEvent) bool {
 		if ddl == nil {
@@ -184,11 +280,11 @@ func (ra *RedoApplier) consumeLogs(ctx context.Context) error {
 		return row.CommitTs > ddl.CommitTs
 	}
 
-	row, err := ra.updateSplitter.readNextRow(ctx)
+	row, err := ra.readNextRow(ctx, targetTs)
 	if err != nil {
 		return err
 	}
-	ddl, err := ra.rd.ReadNextDDL(ctx)
+	ddl, err := ra.readNextDDL(ctx, targetTs)
 	if err != nil {
 		return err
 	}
@@ -200,33 +296,84 @@ func (ra *RedoApplier) consumeLogs(ctx context.Context) error {
 			if err := ra.applyDDL(ctx, ddl, checkpointTs); err != nil {
 				return err
 			}
-			if ddl, err = ra.rd.ReadNextDDL(ctx); err != nil {
+			if ddl, err = ra.readNextDDL(ctx, targetTs); err != nil {
 				return err
 			}
 		} else {
 			if err := ra.applyRow(row, checkpointTs); err != nil {
 				return err
 			}
-			if row, err = ra.updateSplitter.readNextRow(ctx); err != nil {
+			if row, err = ra.readNextRow(ctx, targetTs); err != nil {
 				return err
 			}
 		}
 	}
 	// wait all tables to flush data
 	for tableID := range ra.tableResolvedTsMap {
-		if err := ra.waitTableFlush(ctx, tableID, resolvedTs); err != nil {
+		if err := ra.waitTableFlush(ctx, tableID, targetTs); err != nil {
 			return err
 		}
 		ra.tableSinks[tableID].Close()
 	}
+	// Some sinks, such as MQ and cloud storage sinks, record the checkpoint ts
+	// to tell the consumers that all events before it are written.
+	if ra.ddlSink != nil {
+		tables := make([]*model.TableInfo, 0, len(ra.tableInfos))
+		for _, tableInfo := range ra.tableInfos {
+			tables = append(tables, tableInfo)
+		}
+		if err := ra.ddlSink.WriteCheckpointTs(ctx, targetTs, tables); err != nil {
+			return err
+		}
+	}
 
 	log.Info("apply redo log finishes",
 		zap.Uint64("appliedLogCount", ra.appliedLogCount),
 		zap.Uint64("appliedDDLCount", ra.appliedDDLCount),
-		zap.Uint64("currentCheckpoint", resolvedTs))
+		zap.Uint64("currentCheckpoint", targetTs))
 	return errApplyFinished
 }
 
+// readNextRow returns the next row to apply, the rows of the filtered tables are
+// skipped, and it returns nil if the commit ts of the row exceeds the target ts.
+func (ra *RedoApplier) readNextRow(ctx context.Context, targetTs uint64) (*model.RowChangedEvent, error) {
+	for {
+		row, err := ra.updateSplitter.readNextRow(ctx)
+		if err != nil || row == nil {
+			return row, err
+		}
+		// The rows are read in the order of commit ts.
+		if row.CommitTs > targetTs {
+			return nil, nil
+		}
+		if ra.filter != nil &&
+			ra.filter.ShouldIgnoreTable(row.TableInfo.GetSchemaName(), row.TableInfo.GetTableName()) {
+			continue
+		}
+		return row, nil
+	}
+}
+
+// readNextDDL returns the next DDL to apply, the DDLs of the filtered tables are
+// skipped, and it returns nil if the commit ts of the DDL exceeds the target ts.
+func (ra *RedoApplier) readNextDDL(ctx context.Context, targetTs uint64) (*model.DDLEvent, error) {
+	for {
+		ddl, err := ra.rd.ReadNextDDL(ctx)
+		if err != nil || ddl == nil {
+			return ddl, err
+		}
+		if ddl.CommitTs > targetTs {
+			return nil, nil
+		}
+		if ra.filter != nil && ddl.TableInfo != nil &&
+			ra.filter.ShouldDiscardDDL(ddl.Type, ddl.TableInfo.TableName.Schema, ddl.TableInfo.TableName.Table) {
+			log.Info("skip DDL by filter", zap.String("query", ddl.Query), zap.Uint64("commitTs", ddl.CommitTs))
+			continue
+		}
+		return ddl, nil
+	}
+}
+
 func (ra *RedoApplier) resetQuota(rowSize uint64) error {
 	if rowSize >= config.DefaultChangefeedMemoryQuota || rowSize < ra.pendingQuota {
 		log.Panic("row size exceeds memory quota",
@@ -285,6 +432,10 @@ func (ra *RedoApplier) applyDDL(
 		return nil
 	}
 	log.Warn("apply DDL", zap.Any("ddl", ddl))
+	if ra.sqlWriter != nil {
+		ra.appliedDDLCount++
+		return ra.sqlWriter.writeDDL(ddl)
+	}
 	// Wait all tables to flush data before applying DDL.
 	// TODO: only block tables that are affected by this DDL.
 	for tableID := range ra.tableSinks {
@@ -302,6 +453,11 @@ func (ra *RedoApplier) applyDDL(
 func (ra *RedoApplier) applyRow(
 	row *model.RowChangedEvent, checkpointTs model.Ts,
 ) error {
+	if ra.sqlWriter != nil {
+		ra.appliedLogCount++
+		return ra.sqlWriter.writeRow(row)
+	}
+
 	rowSize := uint64(row.ApproximateBytes())
 	if rowSize > ra.pendingQuota {
 		if err := ra.resetQuota(uint64(row.ApproximateBytes())); err != nil {
@@ -313,7 +469,7 @@ func (ra *RedoApplier) applyRow(
 	tableID := row.GetTableID()
 	if _, ok := ra.tableSinks[tableID]; !ok {
 		tableSink := ra.sinkFactory.CreateTableSink(
-			model.DefaultChangeFeedID(applierChangefeed),
+			ra.changefeedID,
 			spanz.TableIDToComparableSpan(tableID),
 			checkpointTs,
 			pdutil.NewClock4Test(),
@@ -335,6 +491,7 @@ func (ra *RedoApplier) applyRow(
 	}
 
 	ra.tableSinks[tableID].AppendRowChangedEvents(row)
+	ra.tableInfos[tableID] = row.TableInfo
 	record := ra.tableResolvedTsMap[tableID]
 	record.Size += rowSize
 	if row.CommitTs > record.ResolvedTs.Ts {
@@ -731,7 +888,7 @@ func (ra *RedoApplier) Apply(egCtx context.Context) (err error) {
 	})
 	ra.updateSplitter = newUpdateEventSplitter(ra.rd, ra.cfg.Dir)
 
-	ra.memQuota = memquota.NewMemQuota(model.DefaultChangeFeedID(applierChangefeed),
+	ra.memQuota = memquota.NewMemQuota(ra.changefeedID,
 		config.DefaultChangefeedMemoryQuota, "sink")
 	defer ra.memQuota.Close()
 	eg.Go(func() error {
@@ -18,6 +18,7 @@ import (
 	"database/sql"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -29,7 +30,10 @@ import (
 	"github.com/pingcap/tiflow/cdc/redo/reader"
 	mysqlDDL "github.com/pingcap/tiflow/cdc/sink/ddlsink/mysql"
 	"github.com/pingcap/tiflow/cdc/sink/dmlsink/txn"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/errors"
 	pmysql "github.com/pingcap/tiflow/pkg/sink/mysql"
+	"github.com/pingcap/tiflow/pkg/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -645,3 +649,173 @@ func getMockDBForBigTxn(t *testing.T) *sql.DB {
 	mock.ExpectClose()
 	return db
 }
+
+func TestApplyDryRunWithTargetTsAndFilter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checkpointTs := uint64(1000)
+	resolvedTs := uint64(2000)
+	redoLogCh := make(chan *model.RowChangedEvent, 1024)
+	ddlEventCh := make(chan *model.DDLEvent, 1024)
+	createRedoReaderBak := createRedoReader
+	createRedoReader = func(ctx context.Context, cfg *RedoApplierConfig) (reader.RedoLogReader, error) {
+		return NewMockReader(checkpointTs, resolvedTs, redoLogCh, ddlEventCh), nil
+	}
+	defer func() {
+		createRedoReader = createRedoReaderBak
+	}()
+
+	newTableInfo := func(schema, table string) *model.TableInfo {
+		return model.BuildTableInfo(schema, table, []*model.Column{
+			{Name: "a", Type: mysqlParser.TypeLong, Flag: model.HandleKeyFlag | model.PrimaryKeyFlag},
+			{Name: "b", Type: mysqlParser.TypeVarchar},
+		}, [][]int{{0}})
+	}
+	t1, t2 := newTableInfo("test", "t1"), newTableInfo("other", "t2")
+	newRow := func(tableInfo *model.TableInfo, commitTs uint64, a int, b string) *model.RowChangedEvent {
+		return &model.RowChangedEvent{
+			StartTs:   commitTs - 10,
+			CommitTs:  commitTs,
+			TableInfo: tableInfo,
+			Columns: model.Columns2ColumnDatas([]*model.Column{
+				{Name: "a", Value: a}, {Name: "b", Value: b},
+			}, tableInfo),
+		}
+	}
+	deleteRow := newRow(t1, 1300, 1, "it's")
+	deleteRow.PreColumns, deleteRow.Columns = deleteRow.Columns, nil
+	for _, row := range []*model.RowChangedEvent{
+		newRow(t1, 1100, 1, "it's"),
+		newRow(t2, 1200, 2, "filtered"),
+		deleteRow,
+		newRow(t1, 1600, 3, "after target"),
+	} {
+		redoLogCh <- row
+	}
+	for _, ddl := range []*model.DDLEvent{
+		{
+			CommitTs:  1250,
+			TableInfo: t1,
+			Query:     "alter table t1 add column c int",
+			Type:      timodel.ActionAddColumn,
+		},
+		{
+			CommitTs:  1260,
+			TableInfo: t2,
+			Query:     "alter table t2 add column c int",
+			Type:      timodel.ActionAddColumn,
+		},
+	} {
+		ddlEventCh <- ddl
+	}
+	close(redoLogCh)
+	close(ddlEventCh)
+
+	dir := t.TempDir()
+	cfg := &RedoApplierConfig{
+		Dir:         dir,
+		TargetTs:    1500,
+		FilterRules: []string{"test.*"},
+		DryRunFile:  filepath.Join(dir, "redo.sql"),
+	}
+	ap := NewRedoApplier(cfg)
+	require.NoError(t, ap.Apply(ctx))
+	require.Equal(t, uint64(2), ap.appliedLogCount)
+	require.Equal(t, uint64(1), ap.appliedDDLCount)
+
+	data, err := os.ReadFile(cfg.DryRunFile)
+	require.NoError(t, err)
+	require.Equal(t, "-- start-ts: 1090, commit-ts: 1100\n"+
+		"BEGIN;\n"+
+		"REPLACE INTO `test`.`t1` (`a`,`b`) VALUES (1,'it\\'s');\n"+
+		"COMMIT;\n"+
+		"-- start-ts: 0, commit-ts: 1250\n"+
+		"USE `test`;\n"+
+		"alter table t1 add column c int;\n"+
+		"-- start-ts: 1290, commit-ts: 1300\n"+
+		"BEGIN;\n"+
+		"DELETE FROM `test`.`t1` WHERE `a` = 1 LIMIT 1;\n"+
+		"COMMIT;\n", string(data))
+}
+
+func TestAdjustTargetTs(t *testing.T) {
+	t.Parallel()
+
+	cfg := &RedoApplierConfig{}
+	targetTs, err := cfg.adjustTargetTs(1000, 2000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2000), targetTs)
+
+	cfg.TargetTs = 1500
+	targetTs, err = cfg.adjustTargetTs(1000, 2000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1500), targetTs)
+
+	cfg.TargetTs = 3000
+	targetTs, err = cfg.adjustTargetTs(1000, 2000)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2000), targetTs)
+
+	cfg.TargetTs = 500
+	_, err = cfg.adjustTargetTs(1000, 2000)
+	require.ErrorIs(t, err, errors.ErrRedoConfigInvalid)
+}
+
+func TestApplyToStorageSink(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checkpointTs := uint64(1000)
+	resolvedTs := uint64(2000)
+	redoLogCh := make(chan *model.RowChangedEvent, 1024)
+	ddlEventCh := make(chan *model.DDLEvent, 1024)
+	createRedoReaderBak := createRedoReader
+	createRedoReader = func(ctx context.Context, cfg *RedoApplierConfig) (reader.RedoLogReader, error) {
+		return NewMockReader(checkpointTs, resolvedTs, redoLogCh, ddlEventCh), nil
+	}
+	defer func() {
+		createRedoReader = createRedoReaderBak
+	}()
+
+	tableInfo := model.BuildTableInfo("test", "t1", []*model.Column{
+		{Name: "a", Type: mysqlParser.TypeLong, Flag: model.HandleKeyFlag | model.PrimaryKeyFlag},
+		{Name: "b", Type: mysqlParser.TypeVarchar, Charset: "utf8mb4"},
+	}, [][]int{{0}})
+	tableInfo.TableName.TableID = 1
+	tableInfo.TableInfo.ID = 1
+	redoLogCh <- &model.RowChangedEvent{
+		StartTs:   1100,
+		CommitTs:  1200,
+		TableInfo: tableInfo,
+		Columns: model.Columns2ColumnDatas([]*model.Column{
+			{Name: "a", Value: 1}, {Name: "b", Value: []byte("redo")},
+		}, tableInfo),
+	}
+	close(redoLogCh)
+	close(ddlEventCh)
+
+	// The protocol comes from the replica config instead of the sink URI.
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.Protocol = util.AddressOf(config.ProtocolCanalJSON.String())
+	dir := t.TempDir()
+	cfg := &RedoApplierConfig{
+		SinkURI:       "file://" + dir + "?flush-interval=2s",
+		Dir:           t.TempDir(),
+		ReplicaConfig: replicaConfig,
+	}
+	ap := NewRedoApplier(cfg)
+	require.NoError(t, ap.Apply(ctx))
+	require.Equal(t, uint64(1), ap.appliedLogCount)
+
+	metadata, err := os.ReadFile(filepath.Join(dir, "metadata"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"checkpoint-ts":2000}`, string(metadata))
+	// The data files are partitioned by table version and date.
+	dataFiles, err := filepath.Glob(filepath.Join(dir, "test", "t1", "*", "*", "CDC*.json"))
+	require.NoError(t, err)
+	require.Len(t, dataFiles, 1)
+	data, err := os.ReadFile(dataFiles[0])
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"data":[{"a":"1","b":"redo"}]`)
+}
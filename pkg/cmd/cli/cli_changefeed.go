@@ -26,6 +26,7 @@ func newCmdChangefeed(f factory.Factory) *cobra.Command {
 		Args:  cobra.NoArgs,
 	}
 
+	cmds.AddCommand(newCmdClaimCheckChangefeed(f))
 	cmds.AddCommand(newCmdCreateChangefeed(f))
 	cmds.AddCommand(newCmdUpdateChangefeed(f))
 	cmds.AddCommand(newCmdStatisticsChangefeed(f))
@@ -0,0 +1,171 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiflow/cdc/model"
+	apiv2client "github.com/pingcap/tiflow/pkg/api/v2"
+	"github.com/pingcap/tiflow/pkg/cmd/factory"
+	"github.com/pingcap/tiflow/pkg/cmd/util"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/kafka/claimcheck"
+	"github.com/spf13/cobra"
+)
+
+// claimCheckSummary is the output of the `cli changefeed claim-check` command.
+type claimCheckSummary struct {
+	Namespace    string            `json:"namespace"`
+	ID           string            `json:"id"`
+	CheckpointTs uint64            `json:"checkpoint_ts"`
+	Retention    string            `json:"retention"`
+	DryRun       bool              `json:"dry_run"`
+	TotalFiles   int               `json:"total_files"`
+	TotalBytes   int64             `json:"total_bytes"`
+	ExpiredFiles int               `json:"expired_files"`
+	ExpiredBytes int64             `json:"expired_bytes"`
+	RemovedFiles int               `json:"removed_files"`
+	Files        []claimcheck.File `json:"files,omitempty"`
+}
+
+// claimCheckChangefeedOptions defines flags for the `cli changefeed claim-check` command.
+type claimCheckChangefeedOptions struct {
+	apiClientV2 apiv2client.APIV2Interface
+
+	namespace    string
+	changefeedID string
+	storageURI   string
+	retention    string
+	dryRun       bool
+}
+
+// newClaimCheckChangefeedOptions creates new options for the `cli changefeed claim-check` command.
+func newClaimCheckChangefeedOptions() *claimCheckChangefeedOptions {
+	return &claimCheckChangefeedOptions{}
+}
+
+// addFlags receives a *cobra.Command reference and binds
+// flags related to template printing to it.
+func (o *claimCheckChangefeedOptions) addFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&o.namespace, "namespace", "n", "default", "Replication task (changefeed) Namespace")
+	cmd.PersistentFlags().StringVarP(&o.changefeedID, "changefeed-id", "c", "", "Replication task (changefeed) ID")
+	cmd.PersistentFlags().StringVar(&o.storageURI, "claim-check-storage-uri", "",
+		"Claim-check storage URI, overrides the masked one in the changefeed config")
+	cmd.PersistentFlags().StringVar(&o.retention, "retention", "",
+		"Retention of the claim-check files, overrides the one in the changefeed config")
+	cmd.PersistentFlags().BoolVar(&o.dryRun, "dry-run", true,
+		"List the claim-check files only, the expired files are removed if it's false")
+	_ = cmd.MarkPersistentFlagRequired("changefeed-id")
+}
+
+// complete adapts from the command line args to the data and client required.
+func (o *claimCheckChangefeedOptions) complete(f factory.Factory) error {
+	clientV2, err := f.APIV2Client()
+	if err != nil {
+		return err
+	}
+	o.apiClientV2 = clientV2
+	return nil
+}
+
+// run the `cli changefeed claim-check` command.
+func (o *claimCheckChangefeedOptions) run(cmd *cobra.Command) error {
+	ctx := context.Background()
+	if o.retention != "" {
+		if retention, err := time.ParseDuration(o.retention); err != nil || retention <= 0 {
+			return errors.Errorf("invalid retention %s, it should be a positive duration", o.retention)
+		}
+	}
+
+	detail, err := o.apiClientV2.Changefeeds().Get(ctx, o.namespace, o.changefeedID)
+	if err != nil {
+		return err
+	}
+	var largeMessageHandle *config.LargeMessageHandleConfig
+	if detail.Config != nil {
+		sinkConfig := detail.Config.ToInternalReplicaConfig().Sink
+		switch {
+		case sinkConfig.KafkaConfig != nil:
+			largeMessageHandle = sinkConfig.KafkaConfig.LargeMessageHandle
+		case sinkConfig.PulsarConfig != nil:
+			largeMessageHandle = sinkConfig.PulsarConfig.LargeMessageHandle
+		}
+	}
+	if !largeMessageHandle.EnableClaimCheck() {
+		return errors.Errorf("the claim-check is not enabled by the changefeed %s", o.changefeedID)
+	}
+	if o.storageURI != "" {
+		largeMessageHandle.ClaimCheckStorageURI = o.storageURI
+	}
+	if o.retention != "" {
+		largeMessageHandle.ClaimCheckRetention = o.retention
+	}
+
+	changefeedID := model.ChangeFeedID{Namespace: detail.Namespace, ID: detail.ID}
+	cleaner, err := claimcheck.NewCleaner(ctx, largeMessageHandle, changefeedID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer cleaner.Close()
+
+	files, err := cleaner.ListFiles(ctx, detail.CheckpointTs)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	summary := &claimCheckSummary{
+		Namespace:    changefeedID.Namespace,
+		ID:           changefeedID.ID,
+		CheckpointTs: detail.CheckpointTs,
+		Retention:    cleaner.Retention().String(),
+		DryRun:       o.dryRun,
+		TotalFiles:   len(files),
+		Files:        files,
+	}
+	for _, file := range files {
+		summary.TotalBytes += file.Size
+		if file.Expired {
+			summary.ExpiredFiles++
+			summary.ExpiredBytes += file.Size
+		}
+	}
+	if !o.dryRun {
+		summary.RemovedFiles, err = cleaner.RemoveExpiredFiles(ctx, detail.CheckpointTs)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return util.JSONPrint(cmd, summary)
+}
+
+// newCmdClaimCheckChangefeed creates the `cli changefeed claim-check` command.
+func newCmdClaimCheckChangefeed(f factory.Factory) *cobra.Command {
+	o := newClaimCheckChangefeedOptions()
+
+	command := &cobra.Command{
+		Use:   "claim-check",
+		Short: "List or remove the expired claim-check files of a replication task (changefeed)",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			util.CheckErr(o.complete(f))
+			util.CheckErr(o.run(cmd))
+		},
+	}
+
+	o.addFlags(command)
+
+	return command
+}
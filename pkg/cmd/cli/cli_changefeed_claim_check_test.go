@@ -0,0 +1,98 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	v2 "github.com/pingcap/tiflow/cdc/api/v2"
+	"github.com/pingcap/tiflow/pkg/api/v2/mock"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+func TestChangefeedClaimCheckCli(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cf := mock.NewMockChangefeedInterface(ctrl)
+	f := &mockFactory{changefeeds: cf}
+
+	dir := t.TempDir()
+	now := time.Now()
+	expired := "default_abc_" + strconv.FormatInt(now.Add(-2*time.Hour).UnixMilli(), 10) + "_1.json"
+	recent := "default_abc_" + strconv.FormatInt(now.UnixMilli(), 10) + "_2.json"
+	for _, name := range []string{expired, recent} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("value"), 0o644))
+	}
+
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.KafkaConfig = &config.KafkaConfig{
+		LargeMessageHandle: &config.LargeMessageHandleConfig{
+			LargeMessageHandleOption: config.LargeMessageHandleOptionClaimCheck,
+			ClaimCheckStorageURI:     "file://" + dir,
+		},
+	}
+	info := &v2.ChangeFeedInfo{
+		Namespace:    "default",
+		ID:           "abc",
+		Config:       v2.ToAPIReplicaConfig(replicaConfig),
+		CheckpointTs: oracle.GoTimeToTS(now),
+	}
+
+	// the files are listed only by default.
+	cmd := newCmdClaimCheckChangefeed(f)
+	b := bytes.NewBufferString("")
+	cmd.SetOut(b)
+	cf.EXPECT().Get(gomock.Any(), "default", "abc").Return(info, nil)
+	os.Args = []string{"claim-check", "--changefeed-id=abc", "--retention=1h"}
+	require.Nil(t, cmd.Execute())
+	var summary claimCheckSummary
+	require.NoError(t, json.Unmarshal(b.Bytes(), &summary))
+	require.True(t, summary.DryRun)
+	require.Equal(t, 2, summary.TotalFiles)
+	require.Equal(t, int64(10), summary.TotalBytes)
+	require.Equal(t, 1, summary.ExpiredFiles)
+	require.Equal(t, 0, summary.RemovedFiles)
+	_, err := os.Stat(filepath.Join(dir, expired))
+	require.NoError(t, err)
+
+	// the expired files are removed.
+	o := newClaimCheckChangefeedOptions()
+	require.NoError(t, o.complete(f))
+	o.changefeedID = "abc"
+	o.namespace = "default"
+	o.retention = "1h"
+	o.dryRun = false
+	cf.EXPECT().Get(gomock.Any(), "default", "abc").Return(info, nil)
+	b.Reset()
+	require.Nil(t, o.run(cmd))
+	require.NoError(t, json.Unmarshal(b.Bytes(), &summary))
+	require.Equal(t, 1, summary.RemovedFiles)
+	_, err = os.Stat(filepath.Join(dir, expired))
+	require.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, recent))
+	require.NoError(t, err)
+
+	// the retention is invalid.
+	o.retention = "abc"
+	require.NotNil(t, o.run(cmd))
+}
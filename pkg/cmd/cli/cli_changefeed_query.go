@@ -45,6 +45,8 @@ type cfMeta struct {
 	ErrorHis       []int64                   `json:"error_history,omitempty"`
 	CreatorVersion string                    `json:"creator_version"`
 	TaskStatus     []model.CaptureTaskStatus `json:"task_status,omitempty"`
+	SlowPartitions []v2.SlowPartition        `json:"slow_partitions,omitempty"`
+	ConflictCount  uint64                    `json:"conflict_count,omitempty"`
 }
 
 // queryChangefeedOptions defines flags for the `cli changefeed query` command.
@@ -115,6 +117,8 @@ func (o *queryChangefeedOptions) run(cmd *cobra.Command) error {
 		RunningError:   detail.Error,
 		CreatorVersion: detail.CreatorVersion,
 		TaskStatus:     detail.TaskStatus,
+		SlowPartitions: detail.SlowPartitions,
+		ConflictCount:  detail.ConflictCount,
 	}
 	return util.JSONPrint(cmd, meta)
 }
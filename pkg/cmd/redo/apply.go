@@ -23,9 +23,13 @@ import (
 	"github.com/pingcap/log"
 	"github.com/pingcap/tiflow/pkg/applier"
 	cmdcontext "github.com/pingcap/tiflow/pkg/cmd/context"
+	cmdutil "github.com/pingcap/tiflow/pkg/cmd/util"
+	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink"
 	"github.com/pingcap/tiflow/pkg/util"
 	"github.com/spf13/cobra"
+	"github.com/tikv/client-go/v2/oracle"
 	"go.uber.org/zap"
 )
 
@@ -35,6 +39,14 @@ type applyRedoOptions struct {
 	sinkURI              string
 	enableProfiling      bool
 	memoryLimitInGiBytes int64
+
+	targetTs    uint64
+	targetTime  string
+	filterRules []string
+	dryRunFile  string
+
+	configFile    string
+	replicaConfig *config.ReplicaConfig
 }
 
 // newapplyRedoOptions creates new applyRedoOptions for the `redo apply` command.
@@ -45,26 +57,52 @@ func newapplyRedoOptions() *applyRedoOptions {
 // addFlags receives a *cobra.Command reference and binds
 // flags related to template printing to it.
 func (o *applyRedoOptions) addFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&o.sinkURI, "sink-uri", "", "target database sink-uri")
-	// the possible error returned from MarkFlagRequired is `no such flag`
-	cmd.MarkFlagRequired("sink-uri") //nolint:errcheck
+	cmd.Flags().StringVar(&o.sinkURI, "sink-uri", "", "target sink-uri, such as mysql, kafka, pulsar or cloud storage, required if dry-run-file is not set")
 	cmd.Flags().BoolVar(&o.enableProfiling, "enable-profiling", true, "enable pprof profiling")
 	cmd.Flags().Int64Var(&o.memoryLimitInGiBytes, "memory-limit", 10, "memory limit in GiB")
+	cmd.Flags().Uint64Var(&o.targetTs, "target-ts", 0, "apply redo logs up to the TSO, default to the resolved ts of redo meta")
+	cmd.Flags().StringVar(&o.targetTime, "target-time", "",
+		"apply redo logs up to the time, such as \"2006-01-02 15:04:05\" in the local time zone or RFC3339")
+	cmd.Flags().StringSliceVar(&o.filterRules, "filter", nil, "table filter rules, such as \"db.*\", apply all tables if not set")
+	cmd.Flags().StringVar(&o.dryRunFile, "dry-run-file", "", "output the SQL statements to the file instead of executing them")
+	cmd.Flags().StringVar(&o.configFile, "config", "", "path of the changefeed configuration file used to create the sink, such as the dispatch rules and protocol")
 }
 
 //nolint:unparam
 func (o *applyRedoOptions) complete(cmd *cobra.Command) error {
-	// parse sinkURI as a URI
-	sinkURI, err := url.Parse(o.sinkURI)
-	if err != nil {
-		return cerror.WrapError(cerror.ErrSinkURIInvalid, err)
+	if o.targetTime != "" {
+		if o.targetTs != 0 {
+			return cerror.ErrRedoConfigInvalid.GenWithStack("target-ts and target-time can't be set together")
+		}
+		targetTs, err := parseTargetTime(o.targetTime)
+		if err != nil {
+			return err
+		}
+		o.targetTs = targetTs
+	}
+	if o.sinkURI == "" {
+		if o.dryRunFile == "" {
+			return cerror.ErrRedoConfigInvalid.GenWithStack("sink-uri is required if dry-run-file is not set")
+		}
+	} else {
+		// parse sinkURI as a URI
+		sinkURI, err := url.Parse(o.sinkURI)
+		if err != nil {
+			return cerror.WrapError(cerror.ErrSinkURIInvalid, err)
+		}
+		rawQuery := sinkURI.Query()
+		// set safe-mode to true if not set
+		if sink.IsMySQLCompatibleScheme(sink.GetScheme(sinkURI)) && rawQuery.Get("safe-mode") != "true" {
+			rawQuery.Set("safe-mode", "true")
+			sinkURI.RawQuery = rawQuery.Encode()
+			o.sinkURI = sinkURI.String()
+		}
 	}
-	rawQuery := sinkURI.Query()
-	// set safe-mode to true if not set
-	if rawQuery.Get("safe-mode") != "true" {
-		rawQuery.Set("safe-mode", "true")
-		sinkURI.RawQuery = rawQuery.Encode()
-		o.sinkURI = sinkURI.String()
+	if o.configFile != "" {
+		o.replicaConfig = config.GetDefaultReplicaConfig()
+		if err := cmdutil.StrictDecodeFile(o.configFile, "TiCDC changefeed", o.replicaConfig); err != nil {
+			return err
+		}
 	}
 
 	totalMemory, err := util.GetMemoryLimit()
@@ -99,19 +137,40 @@ func (o *applyRedoOptions) run(cmd *cobra.Command) error {
 	}
 
 	cfg := &applier.RedoApplierConfig{
-		Storage: o.storage,
-		SinkURI: o.sinkURI,
-		Dir:     o.dir,
+		Storage:       o.storage,
+		SinkURI:       o.sinkURI,
+		Dir:           o.dir,
+		TargetTs:      o.targetTs,
+		FilterRules:   o.filterRules,
+		DryRunFile:    o.dryRunFile,
+		ReplicaConfig: o.replicaConfig,
 	}
 	ap := applier.NewRedoApplier(cfg)
 	err := ap.Apply(ctx)
 	if err != nil {
 		return err
 	}
+	if o.dryRunFile != "" {
+		cmd.Printf("Write the SQL statements of redo log to %s successfully\n", o.dryRunFile)
+		return nil
+	}
 	cmd.Println("Apply redo log successfully")
 	return nil
 }
 
+// parseTargetTime parses the target time to TSO.
+func parseTargetTime(s string) (uint64, error) {
+	t, err := time.ParseInLocation(time.DateTime, s, time.Local)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, s)
+		if err != nil {
+			return 0, cerror.ErrRedoConfigInvalid.GenWithStack(
+				"target-time %s is invalid, it should be \"2006-01-02 15:04:05\" or RFC3339", s)
+		}
+	}
+	return oracle.GoTimeToTS(t), nil
+}
+
 // newCmdApply creates the `redo apply` command.
 func newCmdApply(opt *options) *cobra.Command {
 	o := newapplyRedoOptions()
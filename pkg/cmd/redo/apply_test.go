@@ -14,10 +14,15 @@
 package redo
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/oracle"
 )
 
 func TestComplete(t *testing.T) {
@@ -40,3 +45,60 @@ func TestComplete(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "mysql://root@127.0.0.1:3306?time-zone=UTC&safe-mode=true", o.sinkURI)
 }
+
+func TestCompleteTargetAndDryRun(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	o := newapplyRedoOptions()
+	err := o.complete(cmd)
+	require.ErrorIs(t, err, cerror.ErrRedoConfigInvalid)
+
+	o.dryRunFile = "redo.sql"
+	require.NoError(t, o.complete(cmd))
+	require.Equal(t, "", o.sinkURI)
+
+	o.targetTime = "2024-01-02T03:04:05Z"
+	require.NoError(t, o.complete(cmd))
+	require.Equal(t, oracle.GoTimeToTS(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), o.targetTs)
+
+	o.targetTime, o.targetTs = "2024-01-02 03:04:05", 0
+	require.NoError(t, o.complete(cmd))
+	require.Equal(t, oracle.GoTimeToTS(time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)), o.targetTs)
+
+	o.targetTime, o.targetTs = "yesterday", 0
+	require.ErrorIs(t, o.complete(cmd), cerror.ErrRedoConfigInvalid)
+
+	o.targetTime = "2024-01-02 03:04:05"
+	o.targetTs = 100
+	require.ErrorIs(t, o.complete(cmd), cerror.ErrRedoConfigInvalid)
+}
+
+func TestCompleteNonMySQLSink(t *testing.T) {
+	cmd := &cobra.Command{
+		Use: "test",
+	}
+	o := newapplyRedoOptions()
+	o.sinkURI = "kafka://127.0.0.1:9092/topic"
+	require.NoError(t, o.complete(cmd))
+	require.Equal(t, "kafka://127.0.0.1:9092/topic", o.sinkURI)
+	require.Nil(t, o.replicaConfig)
+
+	o.configFile = filepath.Join(t.TempDir(), "changefeed.toml")
+	err := os.WriteFile(o.configFile, []byte(`
+[sink]
+protocol = "canal-json"
+dispatchers = [
+	{matcher = ['test.*'], partition = "columns", columns = ["a"]},
+]
+`), 0o644)
+	require.NoError(t, err)
+	require.NoError(t, o.complete(cmd))
+	require.Equal(t, "canal-json", *o.replicaConfig.Sink.Protocol)
+	require.Len(t, o.replicaConfig.Sink.DispatchRules, 1)
+	require.Equal(t, "columns", o.replicaConfig.Sink.DispatchRules[0].PartitionRule)
+
+	err = os.WriteFile(o.configFile, []byte("unknown-item = 1\n"), 0o644)
+	require.NoError(t, err)
+	require.Error(t, o.complete(cmd))
+}
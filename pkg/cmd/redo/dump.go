@@ -0,0 +1,130 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redo
+
+import (
+	"os"
+	"time"
+
+	"github.com/pingcap/tiflow/pkg/applier"
+	cmdcontext "github.com/pingcap/tiflow/pkg/cmd/context"
+	cmdutil "github.com/pingcap/tiflow/pkg/cmd/util"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// dumpOptions defines flags for the `redo dump` command.
+type dumpOptions struct {
+	options
+	startTs       uint64
+	endTs         uint64
+	filterRules   []string
+	format        string
+	output        string
+	stats         bool
+	statsInterval time.Duration
+}
+
+// newDumpOptions creates new dumpOptions for the `redo dump` command.
+func newDumpOptions() *dumpOptions {
+	return &dumpOptions{}
+}
+
+// addFlags receives a *cobra.Command reference and binds
+// flags related to template printing to it.
+func (o *dumpOptions) addFlags(cmd *cobra.Command) {
+	cmd.Flags().Uint64Var(&o.startTs, "start-ts", 0, "dump events whose commit ts are greater than the TSO, default to the checkpoint ts of redo meta, "+
+		"it can be less than the checkpoint ts if the redo logs are retained by consistent.retention")
+	cmd.Flags().Uint64Var(&o.endTs, "end-ts", 0, "dump events up to the TSO, default to the resolved ts of redo meta")
+	cmd.Flags().StringSliceVar(&o.filterRules, "filter", nil, "table filter rules, such as \"db.*\", dump all tables if not set")
+	cmd.Flags().StringVar(&o.format, "format", applier.DumpFormatJSON, "output format of the events (json|sql)")
+	cmd.Flags().StringVar(&o.output, "output", "", "output file of the events, default to stdout")
+	cmd.Flags().BoolVar(&o.stats, "stats", false, "print the statistics per table and per ts range instead of the events")
+	cmd.Flags().DurationVar(&o.statsInterval, "stats-interval", time.Minute, "length of the ts ranges in the statistics")
+}
+
+func (o *dumpOptions) complete() error {
+	if o.endTs != 0 && o.endTs <= o.startTs {
+		return cerror.ErrRedoConfigInvalid.GenWithStack(
+			"end-ts %d should be greater than start-ts %d", o.endTs, o.startTs)
+	}
+	if o.format != applier.DumpFormatJSON && o.format != applier.DumpFormatSQL {
+		return cerror.ErrRedoConfigInvalid.GenWithStack(
+			"unsupported format %s, it should be json or sql", o.format)
+	}
+	if o.stats && o.statsInterval < time.Millisecond {
+		return cerror.ErrRedoConfigInvalid.GenWithStack(
+			"stats-interval %s should be at least 1ms", o.statsInterval)
+	}
+	return nil
+}
+
+// run runs the `redo dump` command.
+func (o *dumpOptions) run(cmd *cobra.Command) error {
+	ctx := cmdcontext.GetDefaultContext()
+
+	cfg := &applier.RedoDumperConfig{
+		Storage:       o.storage,
+		Dir:           o.dir,
+		StartTs:       o.startTs,
+		EndTs:         o.endTs,
+		FilterRules:   o.filterRules,
+		Format:        o.format,
+		StatsInterval: o.statsInterval,
+	}
+	dumper := applier.NewRedoDumper(cfg)
+	if o.stats {
+		stats, err := dumper.Statistics(ctx)
+		if err != nil {
+			return err
+		}
+		return cmdutil.JSONPrint(cmd, stats)
+	}
+
+	if o.output == "" {
+		return dumper.Dump(ctx, cmd.OutOrStdout())
+	}
+	f, err := os.Create(o.output)
+	if err != nil {
+		return cerror.WrapError(cerror.ErrRedoFileOp, err)
+	}
+	if err := dumper.Dump(ctx, f); err != nil {
+		f.Close() //nolint:errcheck
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return cerror.WrapError(cerror.ErrRedoFileOp, err)
+	}
+	cmd.Printf("Dump redo log to %s successfully\n", o.output)
+	return nil
+}
+
+// newCmdDump creates the `redo dump` command.
+func newCmdDump(opt *options) *cobra.Command {
+	o := newDumpOptions()
+	command := &cobra.Command{
+		Use:   "dump",
+		Short: "Dump events or statistics of redo logs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.options = *opt
+			if err := o.complete(); err != nil {
+				return err
+			}
+			return o.run(cmd)
+		},
+	}
+	o.addFlags(command)
+
+	return command
+}
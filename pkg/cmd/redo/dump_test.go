@@ -0,0 +1,40 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiflow/pkg/applier"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDumpComplete(t *testing.T) {
+	o := newDumpOptions()
+	o.format = applier.DumpFormatSQL
+	require.NoError(t, o.complete())
+
+	o.startTs, o.endTs = 100, 100
+	require.ErrorIs(t, o.complete(), cerror.ErrRedoConfigInvalid)
+
+	o.startTs, o.endTs = 0, 0
+	o.format = "csv"
+	require.ErrorIs(t, o.complete(), cerror.ErrRedoConfigInvalid)
+
+	o.format = applier.DumpFormatJSON
+	o.stats, o.statsInterval = true, time.Microsecond
+	require.ErrorIs(t, o.complete(), cerror.ErrRedoConfigInvalid)
+}
@@ -195,6 +195,11 @@ func TestAndWriteExampleReplicaTOML(t *testing.T) {
 			{Matcher: []string{"test1.*", "test2.*"}, Columns: []string{"column1", "column2"}},
 			{Matcher: []string{"test3.*", "test4.*"}, Columns: []string{"!a", "column3"}},
 		},
+		ColumnTransformers: []*config.ColumnTransformer{
+			{Matcher: []string{"test1.*"}, Columns: []string{"phone"}, Action: config.ColumnTransformMask, Length: 4},
+			{Matcher: []string{"test1.*"}, Columns: []string{"email"}, Action: config.ColumnTransformHash, Salt: "salt"},
+			{Matcher: []string{"test2.*"}, Columns: []string{"address"}, Action: config.ColumnTransformRename, NewName: "addr"},
+		},
 		CSVConfig: &config.CSVConfig{
 			Quote:                string(config.DoubleQuoteChar),
 			Delimiter:            string(config.Comma),
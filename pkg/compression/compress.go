@@ -15,9 +15,14 @@ package compression
 
 import (
 	"bytes"
+	"io"
+	"strconv"
+	"strings"
 	"sync"
 
+	"github.com/klauspost/compress/gzip"
 	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4/v4"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
 )
@@ -31,6 +36,18 @@ const (
 
 	// LZ4 compression
 	LZ4 string = "lz4"
+
+	// Zstd compression, the level can be specified by the suffix in the
+	// range of [1, 22], e.g. `zstd:9`, the default level is 3.
+	Zstd string = "zstd"
+
+	// Gzip compression
+	Gzip string = "gzip"
+
+	levelSeparator   = ":"
+	minZstdLevel     = 1
+	maxZstdLevel     = 22
+	defaultZstdLevel = 3
 )
 
 var (
@@ -45,12 +62,76 @@ var (
 			return new(bytes.Buffer)
 		},
 	}
+
+	gzipWriterPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(nil)
+		},
+	}
+
+	gzipReaderPool sync.Pool
+
+	// zstdEncoders caches an encoder for each level, the encoders and the
+	// decoder are safe to be used concurrently by EncodeAll and DecodeAll.
+	zstdEncoders sync.Map
+	// zstdWriterPools pools the streaming encoders of each level, a streaming
+	// encoder can't be shared, so it's reset to the writer of each file.
+	zstdWriterPools [maxZstdLevel + 1]sync.Pool
+	zstdDecoder     = sync.OnceValues(func() (*zstd.Decoder, error) {
+		return zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+	})
 )
 
+// parse splits the compression codec into the name and the level, the level
+// is only valid for zstd.
+func parse(cc string) (string, int, error) {
+	name, level, found := strings.Cut(cc, levelSeparator)
+	if name != Zstd {
+		if found {
+			return "", 0, cerror.ErrCompressionFailed.GenWithStack(
+				"compression %s does not support the level", name)
+		}
+		return name, 0, nil
+	}
+	if !found {
+		return name, defaultZstdLevel, nil
+	}
+	l, err := strconv.Atoi(level)
+	if err != nil || l < minZstdLevel || l > maxZstdLevel {
+		return "", 0, cerror.ErrCompressionFailed.GenWithStack(
+			"invalid zstd level %s, it should be in the range of [%d, %d]",
+			level, minZstdLevel, maxZstdLevel)
+	}
+	return name, l, nil
+}
+
+// Name returns the name of the compression codec without the level.
+func Name(cc string) string {
+	name, _, _ := strings.Cut(cc, levelSeparator)
+	return name
+}
+
+func getZstdEncoder(level int) (*zstd.Encoder, error) {
+	if encoder, ok := zstdEncoders.Load(level); ok {
+		return encoder.(*zstd.Encoder), nil
+	}
+	encoder, err := zstd.NewWriter(nil,
+		zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+	}
+	actual, _ := zstdEncoders.LoadOrStore(level, encoder)
+	return actual.(*zstd.Encoder), nil
+}
+
 // Supported return true if the given compression is supported.
 func Supported(cc string) bool {
-	switch cc {
-	case None, Snappy, LZ4:
+	name, _, err := parse(cc)
+	if err != nil {
+		return false
+	}
+	switch name {
+	case None, Snappy, LZ4, Zstd, Gzip:
 		return true
 	}
 	return false
@@ -58,7 +139,11 @@ func Supported(cc string) bool {
 
 // Encode the given data by the given compression codec.
 func Encode(cc string, data []byte) ([]byte, error) {
-	switch cc {
+	name, level, err := parse(cc)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
 	case None:
 		return data, nil
 	case Snappy:
@@ -73,6 +158,24 @@ func Encode(cc string, data []byte) ([]byte, error) {
 			return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
 		}
 		return buf.Bytes(), nil
+	case Zstd:
+		encoder, err := getZstdEncoder(level)
+		if err != nil {
+			return nil, err
+		}
+		return encoder.EncodeAll(data, nil), nil
+	case Gzip:
+		var buf bytes.Buffer
+		writer := gzipWriterPool.Get().(*gzip.Writer)
+		defer gzipWriterPool.Put(writer)
+		writer.Reset(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+		}
+		return buf.Bytes(), nil
 	default:
 	}
 
@@ -81,7 +184,7 @@ func Encode(cc string, data []byte) ([]byte, error) {
 
 // Decode the given data by the given compression codec.
 func Decode(cc string, data []byte) ([]byte, error) {
-	switch cc {
+	switch Name(cc) {
 	case None:
 		return data, nil
 	case Snappy:
@@ -104,8 +207,95 @@ func Decode(cc string, data []byte) ([]byte, error) {
 		bufferPool.Put(buffer)
 
 		return res, err
+	case Zstd:
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+		}
+		res, err := decoder.DecodeAll(data, nil)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+		}
+		return res, nil
+	case Gzip:
+		var (
+			reader *gzip.Reader
+			err    error
+		)
+		if r, ok := gzipReaderPool.Get().(*gzip.Reader); ok {
+			reader = r
+			err = reader.Reset(bytes.NewReader(data))
+		} else {
+			reader, err = gzip.NewReader(bytes.NewReader(data))
+		}
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+		}
+		defer gzipReaderPool.Put(reader)
+		res, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+		}
+		return res, nil
 	default:
 	}
 
 	return nil, cerror.ErrCompressionFailed.GenWithStack("Unsupported compression %s", cc)
 }
+
+// NewWriter returns a writer which compresses the data written to w by the
+// given compression codec, the data is flushed when the writer is closed.
+// The writer of zstd and gzip is pooled, it can't be used after it's closed.
+func NewWriter(cc string, w io.Writer) (io.WriteCloser, error) {
+	name, level, err := parse(cc)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case LZ4:
+		return lz4.NewWriter(w), nil
+	case Zstd:
+		pool := &zstdWriterPools[level]
+		encoder, ok := pool.Get().(*zstd.Encoder)
+		if ok {
+			encoder.Reset(w)
+		} else {
+			encoder, err = zstd.NewWriter(w,
+				zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+			if err != nil {
+				return nil, cerror.WrapError(cerror.ErrCompressionFailed, err)
+			}
+		}
+		return &pooledWriter[*zstd.Encoder]{writer: encoder, pool: pool}, nil
+	case Gzip:
+		writer := gzipWriterPool.Get().(*gzip.Writer)
+		writer.Reset(w)
+		return &pooledWriter[*gzip.Writer]{writer: writer, pool: &gzipWriterPool}, nil
+	default:
+	}
+
+	return nil, cerror.ErrCompressionFailed.GenWithStack("Unsupported streaming compression %s", cc)
+}
+
+// pooledWriter puts the writer back to the pool after it's closed.
+type pooledWriter[W io.WriteCloser] struct {
+	writer W
+	pool   *sync.Pool
+}
+
+func (p *pooledWriter[W]) Write(data []byte) (int, error) {
+	if p.pool == nil {
+		return 0, cerror.ErrCompressionFailed.GenWithStack("write to a closed writer")
+	}
+	return p.writer.Write(data)
+}
+
+func (p *pooledWriter[W]) Close() error {
+	if p.pool == nil {
+		return nil
+	}
+	err := p.writer.Close()
+	p.pool.Put(p.writer)
+	p.pool = nil
+	return err
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupported(t *testing.T) {
+	t.Parallel()
+
+	for _, cc := range []string{None, Snappy, LZ4, Zstd, Gzip, "zstd:1", "zstd:22"} {
+		require.True(t, Supported(cc), cc)
+	}
+	for _, cc := range []string{"", "brotli", "zstd:0", "zstd:23", "zstd:fast", "gzip:9"} {
+		require.False(t, Supported(cc), cc)
+	}
+	require.Equal(t, Zstd, Name("zstd:9"))
+	require.Equal(t, LZ4, Name(LZ4))
+}
+
+func TestEncodeAndDecode(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("tidb-cdc-compression", 1024))
+	for _, cc := range []string{None, Snappy, LZ4, Zstd, "zstd:19", Gzip} {
+		// run twice to make sure the pooled encoders and decoders are reusable.
+		for i := 0; i < 2; i++ {
+			encoded, err := Encode(cc, data)
+			require.NoError(t, err, cc)
+			if cc != None {
+				require.Less(t, len(encoded), len(data), cc)
+			}
+			decoded, err := Decode(cc, encoded)
+			require.NoError(t, err, cc)
+			require.Equal(t, data, decoded, cc)
+		}
+	}
+
+	_, err := Encode("brotli", data)
+	require.Error(t, err)
+	_, err = Encode("zstd:23", data)
+	require.Error(t, err)
+	_, err = Decode(Gzip, data)
+	require.Error(t, err)
+}
+
+func TestNewWriter(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("tidb-cdc-compression", 1024))
+	for _, cc := range []string{LZ4, Zstd, "zstd:9", Gzip} {
+		var buf bytes.Buffer
+		w, err := NewWriter(cc, &buf)
+		require.NoError(t, err, cc)
+		// the data written in several calls is decoded as a whole.
+		_, err = w.Write(data[:100])
+		require.NoError(t, err, cc)
+		_, err = w.Write(data[100:])
+		require.NoError(t, err, cc)
+		require.NoError(t, w.Close(), cc)
+
+		decoded, err := Decode(cc, buf.Bytes())
+		require.NoError(t, err, cc)
+		require.Equal(t, data, decoded, cc)
+	}
+
+	_, err := NewWriter(Snappy, &bytes.Buffer{})
+	require.Error(t, err)
+}
+
+func TestNewWriterReusesEncoder(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(strings.Repeat("tidb-cdc-compression", 1024))
+	for _, cc := range []string{"zstd:5", Gzip} {
+		// the pooled encoders are reset to the writer of each file.
+		for i := 0; i < 3; i++ {
+			var buf bytes.Buffer
+			w, err := NewWriter(cc, &buf)
+			require.NoError(t, err, cc)
+			_, err = w.Write(data)
+			require.NoError(t, err, cc)
+			require.NoError(t, w.Close(), cc)
+			// the writer can't be used after it's closed.
+			_, err = w.Write(data)
+			require.Error(t, err, cc)
+			require.NoError(t, w.Close(), cc)
+
+			decoded, err := Decode(cc, buf.Bytes())
+			require.NoError(t, err, cc)
+			require.Equal(t, data, decoded, cc)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"strings"
+
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+)
+
+const (
+	// ConflictPolicyNone means the conflicts are not detected, the incoming row always wins.
+	ConflictPolicyNone string = "none"
+	// ConflictPolicyNewerVersionWins means the row with the greater value of the
+	// version column wins. It's not decided by the commit ts, since the commit ts
+	// of the rows written by the downstream cluster is unknown to the sink.
+	ConflictPolicyNewerVersionWins string = "newer-version-wins"
+	// ConflictPolicySourcePriority means the row from the prior source wins.
+	ConflictPolicySourcePriority string = "source-priority"
+	// ConflictPolicyLogAndSkip means the incoming row is skipped and the conflict is logged.
+	ConflictPolicyLogAndSkip string = "log-and-skip"
+
+	// ConflictPriorityUpstream means the rows from the upstream win the conflicts.
+	ConflictPriorityUpstream string = "upstream"
+	// ConflictPriorityDownstream means the rows in the downstream win the conflicts.
+	ConflictPriorityDownstream string = "downstream"
+)
+
+// ConflictResolutionConfig is the configuration for detecting and resolving the
+// conflicts of the rows updated concurrently by the clusters in the BDR mode.
+type ConflictResolutionConfig struct {
+	Policy string `toml:"policy" json:"policy"`
+	// VersionColumn is the column to detect the conflicts, its value must be changed
+	// by every write in both clusters, such as `TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)`,
+	// and it's compared as is by the newer-version-wins policy. The rows of the tables
+	// without the column, or without a handle key, are replicated without the conflict
+	// detection, and such tables are logged once.
+	VersionColumn string `toml:"version-column" json:"version-column"`
+	// Priority is the prior source of the source-priority policy.
+	Priority string `toml:"priority" json:"priority,omitempty"`
+	// ConflictTable is the `schema.table` in the downstream to record the conflicts,
+	// it's created if not exists.
+	ConflictTable string `toml:"conflict-table" json:"conflict-table,omitempty"`
+	// ConflictFile is the local file to record the conflicts in the JSON lines format.
+	ConflictFile string `toml:"conflict-file" json:"conflict-file,omitempty"`
+}
+
+// Enabled returns true if the conflicts are detected.
+func (c *ConflictResolutionConfig) Enabled() bool {
+	return c != nil && c.Policy != "" && c.Policy != ConflictPolicyNone
+}
+
+// ConflictTableName returns the schema and the table of the conflict table.
+func (c *ConflictResolutionConfig) ConflictTableName() (string, string) {
+	if c == nil || c.ConflictTable == "" {
+		return "", ""
+	}
+	schema, table, _ := strings.Cut(c.ConflictTable, ".")
+	return schema, table
+}
+
+// ValidateAndAdjust validates the config.
+func (c *ConflictResolutionConfig) ValidateAndAdjust() error {
+	if c.Policy == "" {
+		c.Policy = ConflictPolicyNone
+	}
+	switch c.Policy {
+	case ConflictPolicyNone:
+		return nil
+	case ConflictPolicyNewerVersionWins, ConflictPolicyLogAndSkip:
+	case ConflictPolicySourcePriority:
+		if c.Priority == "" {
+			c.Priority = ConflictPriorityUpstream
+		}
+		if c.Priority != ConflictPriorityUpstream && c.Priority != ConflictPriorityDownstream {
+			return cerror.ErrInvalidReplicaConfig.GenWithStack(
+				"conflict resolution priority %s is invalid, it should be %s or %s",
+				c.Priority, ConflictPriorityUpstream, ConflictPriorityDownstream)
+		}
+	default:
+		return cerror.ErrInvalidReplicaConfig.GenWithStack(
+			"conflict resolution policy %s is not supported", c.Policy)
+	}
+
+	if c.VersionColumn == "" {
+		return cerror.ErrInvalidReplicaConfig.GenWithStack(
+			"conflict resolution policy is set to %s, but the version-column is empty", c.Policy)
+	}
+	if c.ConflictTable != "" {
+		schema, table := c.ConflictTableName()
+		if schema == "" || table == "" || strings.Contains(table, ".") {
+			return cerror.ErrInvalidReplicaConfig.GenWithStack(
+				"conflict-table %s is invalid, it should be in the format of schema.table", c.ConflictTable)
+		}
+	}
+	return nil
+}
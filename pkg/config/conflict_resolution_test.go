@@ -0,0 +1,64 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflictResolutionConfigValidateAndAdjust(t *testing.T) {
+	t.Parallel()
+
+	c := &ConflictResolutionConfig{}
+	require.NoError(t, c.ValidateAndAdjust())
+	require.Equal(t, ConflictPolicyNone, c.Policy)
+	require.False(t, c.Enabled())
+
+	c = &ConflictResolutionConfig{Policy: ConflictPolicySourcePriority, VersionColumn: "updated_at"}
+	require.NoError(t, c.ValidateAndAdjust())
+	require.Equal(t, ConflictPriorityUpstream, c.Priority)
+	require.True(t, c.Enabled())
+
+	c = &ConflictResolutionConfig{
+		Policy:        ConflictPolicyNewerVersionWins,
+		VersionColumn: "updated_at",
+		ConflictTable: "audit.conflicts",
+	}
+	require.NoError(t, c.ValidateAndAdjust())
+	schema, table := c.ConflictTableName()
+	require.Equal(t, "audit", schema)
+	require.Equal(t, "conflicts", table)
+
+	for _, c := range []*ConflictResolutionConfig{
+		{Policy: "first-writer-wins", VersionColumn: "updated_at"},
+		{Policy: ConflictPolicyNewerVersionWins},
+		{Policy: ConflictPolicySourcePriority, VersionColumn: "updated_at", Priority: "local"},
+		{Policy: ConflictPolicyLogAndSkip, VersionColumn: "updated_at", ConflictTable: "conflicts"},
+		{Policy: ConflictPolicyLogAndSkip, VersionColumn: "updated_at", ConflictTable: "a.b.c"},
+	} {
+		require.ErrorIs(t, c.ValidateAndAdjust(), cerror.ErrInvalidReplicaConfig, "%+v", c)
+	}
+
+	sinkURI, err := url.Parse("mysql://127.0.0.1:3306/")
+	require.NoError(t, err)
+	s := GetDefaultReplicaConfig()
+	s.Sink.MySQLConfig = &MySQLConfig{
+		ConflictResolution: &ConflictResolutionConfig{Policy: ConflictPolicyLogAndSkip},
+	}
+	require.ErrorIs(t, s.ValidateAndAdjust(sinkURI), cerror.ErrInvalidReplicaConfig)
+}
@@ -54,7 +54,8 @@ type ConsistentConfig struct {
 	UseFileBackend bool `toml:"use-file-backend" json:"use-file-backend"`
 	// Compression is the compression algorithm used for redo log.
 	// Default is "", it means no compression, equals to `none`.
-	// Supported compression algorithms are `none` and `lz4`.
+	// Supported compression algorithms are `none`, `lz4`, `gzip` and `zstd`,
+	// the level of zstd can be specified like `zstd:9`.
 	Compression string `toml:"compression" json:"compression"`
 	// FlushConcurrency is the concurrency of flushing a single log file.
 	// Default is 1. It means a single log file will be flushed by only one worker.
@@ -63,6 +64,13 @@ type ConsistentConfig struct {
 	// MemoryUsage represents the percentage of ReplicaConfig.MemoryQuota
 	// that can be utilized by the redo log module.
 	MemoryUsage *ConsistentMemoryUsage `toml:"memory-usage" json:"memory-usage"`
+	// Compaction enables merging the small redo log segments into larger segments
+	// sorted by commit ts in background. Default is nil, it means no compaction.
+	Compaction *ConsistentCompaction `toml:"compaction" json:"compaction,omitempty"`
+	// RetentionInMs is the duration(ms) of the redo logs retained before the checkpoint,
+	// so that the redo logs can be used as a time-travel buffer.
+	// Default is 0, it means the redo logs before the checkpoint are removed.
+	RetentionInMs int64 `toml:"retention" json:"retention,omitempty"`
 }
 
 // ConsistentMemoryUsage represents memory usage of Consistent module.
@@ -71,6 +79,16 @@ type ConsistentMemoryUsage struct {
 	MemoryQuotaPercentage uint64 `toml:"memory-quota-percentage" json:"memory-quota-percentage"`
 }
 
+// ConsistentCompaction represents the compaction config of redo log segments.
+type ConsistentCompaction struct {
+	// IntervalInMs is the interval(ms) of compacting the redo log segments.
+	// Default is 300000ms.
+	IntervalInMs int64 `toml:"interval" json:"interval"`
+	// MaxSegmentSize is the max size(MiB) of a compacted segment.
+	// Default is 512MiB.
+	MaxSegmentSize int64 `toml:"max-segment-size" json:"max-segment-size"`
+}
+
 // ValidateAndAdjust validates the consistency config and adjusts it if necessary.
 func (c *ConsistentConfig) ValidateAndAdjust() error {
 	if !redo.IsConsistentEnabled(c.Level) {
@@ -98,10 +116,37 @@ func (c *ConsistentConfig) ValidateAndAdjust() error {
 			fmt.Sprintf("The consistent.meta-flush-interval:%d must be equal or greater than %d",
 				c.MetaFlushIntervalInMs, redo.MinFlushIntervalInMs))
 	}
-	if len(c.Compression) > 0 &&
-		c.Compression != compression.None && c.Compression != compression.LZ4 {
+	if len(c.Compression) > 0 {
+		// snappy is not supported since the redo log is compressed in streaming.
+		name := compression.Name(c.Compression)
+		if !compression.Supported(c.Compression) || name == compression.Snappy {
+			return cerror.ErrInvalidReplicaConfig.FastGenByArgs(
+				fmt.Sprintf("The consistent.compression:%s must be 'none', 'lz4', 'gzip' or 'zstd'",
+					c.Compression))
+		}
+	}
+
+	if c.Compaction != nil {
+		if c.Compaction.IntervalInMs == 0 {
+			c.Compaction.IntervalInMs = redo.DefaultCompactionIntervalInMs
+		}
+		if c.Compaction.IntervalInMs < redo.MinCompactionIntervalInMs {
+			return cerror.ErrInvalidReplicaConfig.FastGenByArgs(
+				fmt.Sprintf("The consistent.compaction.interval:%d must be equal or greater than %d",
+					c.Compaction.IntervalInMs, redo.MinCompactionIntervalInMs))
+		}
+		if c.Compaction.MaxSegmentSize == 0 {
+			c.Compaction.MaxSegmentSize = redo.DefaultCompactedSegmentSize
+		}
+		if c.Compaction.MaxSegmentSize < 0 {
+			return cerror.ErrInvalidReplicaConfig.FastGenByArgs(
+				fmt.Sprintf("The consistent.compaction.max-segment-size:%d must be greater than 0",
+					c.Compaction.MaxSegmentSize))
+		}
+	}
+	if c.RetentionInMs < 0 {
 		return cerror.ErrInvalidReplicaConfig.FastGenByArgs(
-			fmt.Sprintf("The consistent.compression:%s must be 'none' or 'lz4'", c.Compression))
+			fmt.Sprintf("The consistent.retention:%d must be equal or greater than 0", c.RetentionInMs))
 	}
 
 	if c.EncodingWorkerNum == 0 {
@@ -14,6 +14,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/pingcap/tiflow/pkg/compression"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
 )
@@ -25,6 +27,12 @@ const (
 	LargeMessageHandleOptionClaimCheck string = "claim-check"
 	// LargeMessageHandleOptionHandleKeyOnly means handling large message by sending only handle key columns.
 	LargeMessageHandleOptionHandleKeyOnly string = "handle-key-only"
+	// LargeMessageHandleOptionChunking means handling large message by splitting it into chunks,
+	// which is only supported by the pulsar sink.
+	LargeMessageHandleOptionChunking string = "chunking"
+
+	// DefaultClaimCheckCleanupCronSpec is the default cron spec to remove the expired claim-check files.
+	DefaultClaimCheckCleanupCronSpec = "0 0 2 * * *"
 )
 
 // LargeMessageHandleConfig is the configuration for handling large message.
@@ -33,6 +41,13 @@ type LargeMessageHandleConfig struct {
 	LargeMessageHandleCompression string `toml:"large-message-handle-compression" json:"large-message-handle-compression"`
 	ClaimCheckStorageURI          string `toml:"claim-check-storage-uri" json:"claim-check-storage-uri"`
 	ClaimCheckRawValue            bool   `toml:"claim-check-raw-value" json:"claim-check-raw-value"`
+
+	// ClaimCheckRetention is how long the claim-check files are kept, such as `168h`.
+	// If it's empty, the retention.ms of the kafka topic is used, and the files are
+	// never removed if the retention is unknown.
+	ClaimCheckRetention string `toml:"claim-check-retention" json:"claim-check-retention,omitempty"`
+	// ClaimCheckCleanupCronSpec is the cron spec to remove the expired claim-check files.
+	ClaimCheckCleanupCronSpec string `toml:"claim-check-cleanup-cron-spec" json:"claim-check-cleanup-cron-spec,omitempty"`
 }
 
 // NewDefaultLargeMessageHandleConfig return the default Config.
@@ -58,7 +73,10 @@ func (c *LargeMessageHandleConfig) AdjustAndValidate(protocol Protocol, enableTi
 		return cerror.ErrInvalidReplicaConfig.GenWithStack(
 			"large message handle compression is not supported, got %s", c.LargeMessageHandleCompression)
 	}
-	if c.LargeMessageHandleOption == LargeMessageHandleOptionNone {
+	// The chunks are assembled by the consumer transparently, so the message is
+	// not changed and all protocols are supported.
+	if c.LargeMessageHandleOption == LargeMessageHandleOptionNone ||
+		c.LargeMessageHandleOption == LargeMessageHandleOptionChunking {
 		return nil
 	}
 
@@ -85,11 +103,34 @@ func (c *LargeMessageHandleConfig) AdjustAndValidate(protocol Protocol, enableTi
 			return cerror.ErrInvalidReplicaConfig.GenWithStack(
 				"large message handle is set to claim-check, raw value is not supported for the open protocol")
 		}
+		if c.ClaimCheckRetention != "" {
+			retention, err := time.ParseDuration(c.ClaimCheckRetention)
+			if err != nil || retention <= 0 {
+				return cerror.ErrInvalidReplicaConfig.GenWithStack(
+					"claim-check-retention %s is invalid, it should be a positive duration", c.ClaimCheckRetention)
+			}
+		}
+		if c.ClaimCheckCleanupCronSpec == "" {
+			c.ClaimCheckCleanupCronSpec = DefaultClaimCheckCleanupCronSpec
+		}
 	}
 
 	return nil
 }
 
+// GetClaimCheckRetention returns the retention of the claim-check files,
+// it's 0 if the retention is not set.
+func (c *LargeMessageHandleConfig) GetClaimCheckRetention() time.Duration {
+	if c == nil || c.ClaimCheckRetention == "" {
+		return 0
+	}
+	retention, err := time.ParseDuration(c.ClaimCheckRetention)
+	if err != nil {
+		return 0
+	}
+	return retention
+}
+
 // HandleKeyOnly returns true if handle large message by encoding handle key only.
 func (c *LargeMessageHandleConfig) HandleKeyOnly() bool {
 	if c == nil {
@@ -106,6 +147,14 @@ func (c *LargeMessageHandleConfig) EnableClaimCheck() bool {
 	return c.LargeMessageHandleOption == LargeMessageHandleOptionClaimCheck
 }
 
+// EnableChunking returns true if handle large message by splitting it into chunks.
+func (c *LargeMessageHandleConfig) EnableChunking() bool {
+	if c == nil {
+		return false
+	}
+	return c.LargeMessageHandleOption == LargeMessageHandleOptionChunking
+}
+
 // Disabled returns true if disable large message handle.
 func (c *LargeMessageHandleConfig) Disabled() bool {
 	if c == nil {
@@ -15,6 +15,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/pingcap/tiflow/pkg/compression"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
@@ -27,11 +28,28 @@ func TestLargeMessageHandle4Compression(t *testing.T) {
 	largeMessageHandle := NewDefaultLargeMessageHandleConfig()
 
 	// unsupported compression, return error
-	largeMessageHandle.LargeMessageHandleCompression = "zstd"
+	largeMessageHandle.LargeMessageHandleCompression = "brotli"
 
 	err := largeMessageHandle.AdjustAndValidate(ProtocolCanalJSON, false)
 	require.ErrorIs(t, err, cerror.ErrInvalidReplicaConfig)
 
+	// invalid zstd level, return error
+	largeMessageHandle.LargeMessageHandleCompression = "zstd:23"
+	err = largeMessageHandle.AdjustAndValidate(ProtocolCanalJSON, false)
+	require.ErrorIs(t, err, cerror.ErrInvalidReplicaConfig)
+
+	largeMessageHandle.LargeMessageHandleCompression = compression.Zstd
+	err = largeMessageHandle.AdjustAndValidate(ProtocolCanalJSON, false)
+	require.NoError(t, err)
+
+	largeMessageHandle.LargeMessageHandleCompression = "zstd:9"
+	err = largeMessageHandle.AdjustAndValidate(ProtocolCanalJSON, false)
+	require.NoError(t, err)
+
+	largeMessageHandle.LargeMessageHandleCompression = compression.Gzip
+	err = largeMessageHandle.AdjustAndValidate(ProtocolCanalJSON, false)
+	require.NoError(t, err)
+
 	largeMessageHandle.LargeMessageHandleCompression = compression.LZ4
 	err = largeMessageHandle.AdjustAndValidate(ProtocolCanalJSON, false)
 	require.NoError(t, err)
@@ -201,3 +219,45 @@ func TestClaimCheck4SimpleProtocol(t *testing.T) {
 	err = largeMessageHandle.AdjustAndValidate(ProtocolSimple, true)
 	require.NoError(t, err)
 }
+
+func TestClaimCheckRetention(t *testing.T) {
+	t.Parallel()
+
+	largeMessageHandle := NewDefaultLargeMessageHandleConfig()
+	largeMessageHandle.LargeMessageHandleOption = LargeMessageHandleOptionClaimCheck
+	largeMessageHandle.ClaimCheckStorageURI = "file:///tmp/claim-check"
+
+	// the files are never expired if the retention is not set.
+	err := largeMessageHandle.AdjustAndValidate(ProtocolSimple, true)
+	require.NoError(t, err)
+	require.Equal(t, time.Duration(0), largeMessageHandle.GetClaimCheckRetention())
+	require.Equal(t, DefaultClaimCheckCleanupCronSpec, largeMessageHandle.ClaimCheckCleanupCronSpec)
+
+	largeMessageHandle.ClaimCheckRetention = "24h"
+	err = largeMessageHandle.AdjustAndValidate(ProtocolSimple, true)
+	require.NoError(t, err)
+	require.Equal(t, 24*time.Hour, largeMessageHandle.GetClaimCheckRetention())
+
+	for _, retention := range []string{"abc", "-1h", "0s"} {
+		largeMessageHandle.ClaimCheckRetention = retention
+		err = largeMessageHandle.AdjustAndValidate(ProtocolSimple, true)
+		require.ErrorIs(t, err, cerror.ErrInvalidReplicaConfig)
+	}
+}
+
+func TestChunking(t *testing.T) {
+	t.Parallel()
+
+	largeMessageHandle := NewDefaultLargeMessageHandleConfig()
+	largeMessageHandle.LargeMessageHandleOption = LargeMessageHandleOptionChunking
+
+	// the message is not changed, so all protocols are supported.
+	for _, protocol := range []Protocol{ProtocolCanalJSON, ProtocolDebezium, ProtocolCanal} {
+		err := largeMessageHandle.AdjustAndValidate(protocol, false)
+		require.NoError(t, err)
+	}
+	require.True(t, largeMessageHandle.EnableChunking())
+	require.False(t, largeMessageHandle.Disabled())
+	require.False(t, largeMessageHandle.HandleKeyOnly())
+	require.False(t, largeMessageHandle.EnableClaimCheck())
+}
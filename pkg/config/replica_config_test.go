@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/compression"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/integrity"
+	"github.com/pingcap/tiflow/pkg/redo"
 	"github.com/pingcap/tiflow/pkg/util"
 	"github.com/stretchr/testify/require"
 )
@@ -306,6 +307,34 @@ func TestValidateAndAdjust(t *testing.T) {
 	require.NoError(t, cfg.ValidateAndAdjust(sinkURL))
 }
 
+func TestValidateAndAdjustConsistentCompaction(t *testing.T) {
+	sinkURL, err := url.Parse("blackhole://")
+	require.NoError(t, err)
+
+	cfg := GetDefaultReplicaConfig()
+	cfg.Consistent.Level = "eventual"
+	cfg.Consistent.Storage = "blackhole://"
+	cfg.Consistent.Compaction = &ConsistentCompaction{}
+	cfg.Consistent.RetentionInMs = 3600000
+	require.NoError(t, cfg.ValidateAndAdjust(sinkURL))
+	require.Equal(t, int64(redo.DefaultCompactionIntervalInMs), cfg.Consistent.Compaction.IntervalInMs)
+	require.Equal(t, redo.DefaultCompactedSegmentSize, cfg.Consistent.Compaction.MaxSegmentSize)
+
+	cfg.Consistent.Compaction.IntervalInMs = redo.MinCompactionIntervalInMs - 1
+	err = cfg.ValidateAndAdjust(sinkURL)
+	require.ErrorContains(t, err, "consistent.compaction.interval")
+
+	cfg.Consistent.Compaction.IntervalInMs = redo.MinCompactionIntervalInMs
+	cfg.Consistent.Compaction.MaxSegmentSize = -1
+	err = cfg.ValidateAndAdjust(sinkURL)
+	require.ErrorContains(t, err, "consistent.compaction.max-segment-size")
+
+	cfg.Consistent.Compaction.MaxSegmentSize = 64
+	cfg.Consistent.RetentionInMs = -1
+	err = cfg.ValidateAndAdjust(sinkURL)
+	require.ErrorContains(t, err, "consistent.retention")
+}
+
 func TestIsSinkCompatibleWithSpanReplication(t *testing.T) {
 	t.Parallel()
 
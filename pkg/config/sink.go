@@ -93,6 +93,10 @@ const (
 	// to send all tables bootstrap message at changefeed start.
 	DefaultSendAllBootstrapAtStart = false
 
+	// DefaultMaxTopicsPerDispatchRule is the default max number of the topics
+	// generated by the topic expression with the column placeholders.
+	DefaultMaxTopicsPerDispatchRule = 64
+
 	// DefaultMaxReconnectToPulsarBroker is the default max reconnect times to pulsar broker.
 	// The pulsar client uses an exponential backoff with jitter to reconnect to the broker.
 	// Based on test, when the max reconnect times is 3,
@@ -139,6 +143,8 @@ type SinkConfig struct {
 	DispatchRules []*DispatchRule `toml:"dispatchers" json:"dispatchers,omitempty"`
 
 	ColumnSelectors []*ColumnSelector `toml:"column-selectors" json:"column-selectors,omitempty"`
+	// ColumnTransformers is available for the MQ, Storage and DB downstream.
+	ColumnTransformers []*ColumnTransformer `toml:"column-transformers" json:"column-transformers,omitempty"`
 	// SchemaRegistry is only available when the downstream is MQ using avro protocol.
 	SchemaRegistry *string `toml:"schema-registry" json:"schema-registry,omitempty"`
 	// EncoderConcurrency is only available when the downstream is MQ.
@@ -165,6 +171,11 @@ type SinkConfig struct {
 	// ContentCompatible is only available when the downstream is MQ.
 	ContentCompatible *bool `toml:"content-compatible" json:"content-compatible,omitempty"`
 
+	// EnableSchemaVersion is only available for the canal-json, open-protocol and maxwell protocol.
+	// If it's true, every row message carries the schema version of its table, and a bootstrap
+	// message with the table definition is sent before the rows of a new schema version.
+	EnableSchemaVersion *bool `toml:"enable-schema-version" json:"enable-schema-version,omitempty"`
+
 	// TiDBSourceID is the source ID of the upstream TiDB,
 	// which is used to set the `tidb_cdc_write_source` session variable.
 	// Note: This field is only used internally and only used in the MySQL sink.
@@ -216,6 +227,11 @@ func (s *SinkConfig) MaskSensitiveData() {
 	if s.PulsarConfig != nil {
 		s.PulsarConfig.MaskSensitiveData()
 	}
+	for _, rule := range s.ColumnTransformers {
+		if rule.Salt != "" {
+			rule.Salt = "******"
+		}
+	}
 }
 
 // ShouldSendBootstrapMsg returns whether the sink should send bootstrap message.
@@ -232,6 +248,19 @@ func (s *SinkConfig) ShouldSendBootstrapMsg() bool {
 		util.GetOrZero(s.SendBootstrapInMsgCount) > 0
 }
 
+// ShouldEncodeSchemaVersion returns whether the schema version should be encoded in the messages.
+// Only enable it for the canal-json, open-protocol and maxwell protocol.
+func (s *SinkConfig) ShouldEncodeSchemaVersion() bool {
+	if s == nil || !util.GetOrZero(s.EnableSchemaVersion) {
+		return false
+	}
+	protocol, err := ParseSinkProtocolFromString(util.GetOrZero(s.Protocol))
+	if err != nil {
+		return false
+	}
+	return protocol.SupportSchemaVersion()
+}
+
 // ShouldSendAllBootstrapAtStart returns whether the should send all bootstrap message at changefeed start.
 func (s *SinkConfig) ShouldSendAllBootstrapAtStart() bool {
 	if s == nil {
@@ -383,6 +412,10 @@ type DispatchRule struct {
 	DispatcherRule string `toml:"dispatcher" json:"dispatcher"`
 	// PartitionRule is an alias added for DispatcherRule to mitigate confusions.
 	// In the future release, the DispatcherRule is expected to be removed .
+	// The `adaptive` rule is only supported by the simple protocol with the json
+	// encoding format, the partition mapping changes are sent as control messages
+	// keyed `ticdc-partition-mapping`, which the consumers must skip if they
+	// don't rebuild the order of the rows by them.
 	PartitionRule string `toml:"partition" json:"partition"`
 
 	// IndexName is set when using index-value dispatcher with specified index.
@@ -392,6 +425,25 @@ type DispatchRule struct {
 	Columns []string `toml:"columns" json:"columns"`
 
 	TopicRule string `toml:"topic" json:"topic"`
+
+	// DDLTopic is the topic which the DDL events and the bootstrap messages of
+	// the tables are sent to, instead of the topics of the tables. It must be a
+	// fixed topic name, the topic is created with compaction if not exists.
+	// It's only supported by the simple protocol with the json encoding format.
+	// Each DDL is followed by the fences keyed `ticdc-ddl-fence/...`, which are
+	// also sent to all partitions of the data topics as control messages, the
+	// consumers should apply the DDL after all partitions reach its fence.
+	DDLTopic string `toml:"ddl-topic" json:"ddl-topic"`
+
+	// AllowedTopics, OverflowTopic and MaxTopics are used when the TopicRule contains
+	// the column placeholders or functions, such as `orders_{column:tenant_id}`.
+	// The rows are dispatched to the OverflowTopic, or the default topic if it's empty,
+	// if the topic generated from the row is not in the AllowedTopics. The AllowedTopics
+	// can be omitted only if every column is wrapped by the hash-mod function, and the
+	// number of the topics generated by the rule should not exceed the MaxTopics.
+	AllowedTopics []string `toml:"allowed-topics" json:"allowed-topics,omitempty"`
+	OverflowTopic string   `toml:"overflow-topic" json:"overflow-topic,omitempty"`
+	MaxTopics     int      `toml:"max-topics" json:"max-topics,omitempty"`
 }
 
 // ColumnSelector represents a column selector for a table.
@@ -400,6 +452,75 @@ type ColumnSelector struct {
 	Columns []string `toml:"columns" json:"columns"`
 }
 
+// Column transformer actions.
+const (
+	// ColumnTransformMask replaces all but the last `length` characters with '*'.
+	ColumnTransformMask = "mask"
+	// ColumnTransformHash replaces the value with the hex encoded SHA-256 digest
+	// of the salt and the value.
+	ColumnTransformHash = "hash"
+	// ColumnTransformTruncate retains the first `length` characters.
+	ColumnTransformTruncate = "truncate"
+	// ColumnTransformTokenize replaces the value with a token derived from
+	// the HMAC-SHA256 of the value keyed by the salt.
+	ColumnTransformTokenize = "tokenize"
+	// ColumnTransformRename renames the column.
+	ColumnTransformRename = "rename"
+)
+
+// ColumnTransformer represents a column transformer for a table.
+// Column names always refer to the upstream column names.
+type ColumnTransformer struct {
+	Matcher []string `toml:"matcher" json:"matcher"`
+	Columns []string `toml:"columns" json:"columns"`
+	Action  string   `toml:"action" json:"action"`
+	// Salt is used by the hash and tokenize actions.
+	Salt string `toml:"salt" json:"salt,omitempty"`
+	// Length is used by the mask and truncate actions.
+	Length int `toml:"length" json:"length,omitempty"`
+	// NewName is used by the rename action.
+	NewName string `toml:"new-name" json:"new-name,omitempty"`
+}
+
+// String implements fmt.Stringer, the salt is omitted since it's a secret.
+func (t *ColumnTransformer) String() string {
+	return fmt.Sprintf("{Matcher:%v Columns:%v Action:%s Length:%d NewName:%s}",
+		t.Matcher, t.Columns, t.Action, t.Length, t.NewName)
+}
+
+func (t *ColumnTransformer) validate() error {
+	if len(t.Matcher) == 0 || len(t.Columns) == 0 {
+		return cerror.ErrSinkInvalidConfig.GenWithStack(
+			"column transformer must have matcher and columns, rule: %s", t)
+	}
+	switch t.Action {
+	case ColumnTransformMask, ColumnTransformTruncate:
+		if t.Length < 0 {
+			return cerror.ErrSinkInvalidConfig.GenWithStack(
+				"column transformer length should not be negative, rule: %s", t)
+		}
+		if t.Action == ColumnTransformTruncate && t.Length == 0 {
+			return cerror.ErrSinkInvalidConfig.GenWithStack(
+				"column transformer length should be greater than 0 for truncate, rule: %s", t)
+		}
+	case ColumnTransformHash:
+	case ColumnTransformTokenize:
+		if t.Salt == "" {
+			return cerror.ErrSinkInvalidConfig.GenWithStack(
+				"column transformer salt should not be empty for tokenize, rule: %s", t)
+		}
+	case ColumnTransformRename:
+		if len(t.Columns) != 1 || t.NewName == "" {
+			return cerror.ErrSinkInvalidConfig.GenWithStack(
+				"column transformer rename should have exactly one column and a new name, rule: %s", t)
+		}
+	default:
+		return cerror.ErrSinkInvalidConfig.GenWithStack(
+			"column transformer action %s is not supported, rule: %s", t.Action, t)
+	}
+	return nil
+}
+
 // CodecConfig represents a MQ codec configuration
 type CodecConfig struct {
 	EnableTiDBExtension            *bool   `toml:"enable-tidb-extension" json:"enable-tidb-extension,omitempty"`
@@ -451,6 +572,26 @@ type KafkaConfig struct {
 
 	// OutputRawChangeEvent controls whether to split the update pk/uk events.
 	OutputRawChangeEvent *bool `toml:"output-raw-change-event" json:"output-raw-change-event,omitempty"`
+
+	// ExactlyOnce enables the transactional producer, the rows of each resolved batch
+	// of the tables are sent in a kafka transaction along with the progress of the
+	// tables, so that no duplicate rows are visible to the `read_committed` consumers
+	// after the sink restarts or the tables are moved between captures.
+	// The transactions of the tables are not split, so a large transaction of the
+	// upstream may use much memory. It's not supported by the kafka sink v2, that is,
+	// it can't be enabled along with `enable-kafka-sink-v2`, nor by the adaptive
+	// partition dispatcher or `enable-table-across-nodes`.
+	ExactlyOnce *bool `toml:"exactly-once" json:"exactly-once,omitempty"`
+	// TransactionMarkerTopic is the topic which stores the progress of the tables,
+	// only works when ExactlyOnce is enabled.
+	TransactionMarkerTopic *string `toml:"transaction-marker-topic" json:"transaction-marker-topic,omitempty"`
+
+	// SASLAWSRegion and SASLAWSProfile are used by the AWS MSK IAM authentication.
+	SASLAWSRegion  *string `toml:"sasl-aws-region" json:"sasl-aws-region,omitempty"`
+	SASLAWSProfile *string `toml:"sasl-aws-profile" json:"sasl-aws-profile,omitempty"`
+	// SASLEventHubsConnectionStringFile is the file which contains the connection string
+	// of the Azure Event Hubs.
+	SASLEventHubsConnectionStringFile *string `toml:"sasl-event-hubs-connection-string-file" json:"sasl-event-hubs-connection-string-file,omitempty"`
 }
 
 // GetOutputRawChangeEvent returns the value of OutputRawChangeEvent
@@ -497,6 +638,30 @@ func (p *PulsarCompressionType) Value() pulsar.CompressionType {
 	}
 }
 
+const (
+	// PulsarBatcherBuilderDefault batches the messages regardless of the key.
+	PulsarBatcherBuilderDefault = "default"
+	// PulsarBatcherBuilderKeyBased batches the messages with the same key together,
+	// which is required by the consumers with the Key_Shared subscription.
+	PulsarBatcherBuilderKeyBased = "key-based"
+)
+
+// PulsarBatcherBuilder is the batcher builder type for pulsar
+type PulsarBatcherBuilder string
+
+// Value returns the pulsar batcher builder type
+func (p *PulsarBatcherBuilder) Value() pulsar.BatcherBuilderType {
+	if p == nil {
+		return pulsar.DefaultBatchBuilder
+	}
+	switch strings.ToLower(string(*p)) {
+	case PulsarBatcherBuilderKeyBased:
+		return pulsar.KeyBasedBatchBuilder
+	default:
+		return pulsar.DefaultBatchBuilder
+	}
+}
+
 // TimeMill is the time in milliseconds
 type TimeMill int
 
@@ -616,6 +781,13 @@ type PulsarConfig struct {
 	// OutputRawChangeEvent controls whether to split the update pk/uk events.
 	OutputRawChangeEvent *bool `toml:"output-raw-change-event" json:"output-raw-change-event,omitempty"`
 
+	// BatcherBuilder is the batcher builder of the producer, it can be `default` or `key-based`.
+	BatcherBuilder *PulsarBatcherBuilder `toml:"batcher-builder" json:"batcher-builder,omitempty"`
+
+	// LargeMessageHandle is the way to handle the message larger than the max message size,
+	// besides the options of the kafka sink, `chunking` splits the message into chunks.
+	LargeMessageHandle *LargeMessageHandleConfig `toml:"large-message-handle" json:"large-message-handle,omitempty"`
+
 	// BrokerURL is used to configure service brokerUrl for the Pulsar service.
 	// This parameter is a part of the `sink-uri`. Internal use only.
 	BrokerURL string `toml:"-" json:"-"`
@@ -651,6 +823,20 @@ func (c *PulsarConfig) validate() (err error) {
 			return err
 		}
 	}
+	if c.BatcherBuilder != nil {
+		switch strings.ToLower(string(*c.BatcherBuilder)) {
+		case PulsarBatcherBuilderDefault, PulsarBatcherBuilderKeyBased:
+		default:
+			return cerror.ErrInvalidReplicaConfig.GenWithStack(
+				"pulsar batcher-builder %s is not supported", *c.BatcherBuilder)
+		}
+		// Chunking disables the batching, the batcher builder takes no effect.
+		if c.BatcherBuilder.Value() == pulsar.KeyBasedBatchBuilder &&
+			c.LargeMessageHandle.EnableChunking() {
+			return cerror.ErrInvalidReplicaConfig.GenWithStack(
+				"pulsar key-based batcher-builder cannot be used with large message chunking")
+		}
+	}
 	return nil
 }
 
@@ -677,6 +863,9 @@ type MySQLConfig struct {
 	EnableBatchDML               *bool   `toml:"enable-batch-dml" json:"enable-batch-dml,omitempty"`
 	EnableMultiStatement         *bool   `toml:"enable-multi-statement" json:"enable-multi-statement,omitempty"`
 	EnableCachePreparedStatement *bool   `toml:"enable-cache-prepared-statement" json:"enable-cache-prepared-statement,omitempty"`
+
+	// ConflictResolution detects and resolves the conflicts in the BDR mode.
+	ConflictResolution *ConflictResolutionConfig `toml:"conflict-resolution" json:"conflict-resolution,omitempty"`
 }
 
 // CloudStorageConfig represents a cloud storage sink configuration
@@ -707,24 +896,36 @@ func (s *SinkConfig) validateAndAdjust(sinkURI *url.URL) error {
 		return err
 	}
 
+	for _, rule := range s.ColumnTransformers {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+
 	if sink.IsMySQLCompatibleScheme(sinkURI.Scheme) {
+		if s.MySQLConfig != nil && s.MySQLConfig.ConflictResolution != nil {
+			return s.MySQLConfig.ConflictResolution.ValidateAndAdjust()
+		}
 		return nil
 	}
 
 	protocol, _ := ParseSinkProtocolFromString(util.GetOrZero(s.Protocol))
 
+	var enableTiDBExtension bool
+	if v := sinkURI.Query().Get("enable-tidb-extension"); v != "" {
+		var err error
+		enableTiDBExtension, err = strconv.ParseBool(v)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	if s.KafkaConfig != nil && s.KafkaConfig.LargeMessageHandle != nil {
-		var (
-			enableTiDBExtension bool
-			err                 error
-		)
-		if s := sinkURI.Query().Get("enable-tidb-extension"); s != "" {
-			enableTiDBExtension, err = strconv.ParseBool(s)
-			if err != nil {
-				return errors.Trace(err)
-			}
+		if s.KafkaConfig.LargeMessageHandle.EnableChunking() {
+			return cerror.ErrInvalidReplicaConfig.GenWithStack(
+				"large message handle chunking is only supported by the pulsar sink")
 		}
-		err = s.KafkaConfig.LargeMessageHandle.AdjustAndValidate(protocol, enableTiDBExtension)
+		err := s.KafkaConfig.LargeMessageHandle.AdjustAndValidate(protocol, enableTiDBExtension)
 		if err != nil {
 			return err
 		}
@@ -755,6 +956,12 @@ func (s *SinkConfig) validateAndAdjust(sinkURI *url.URL) error {
 		if err := s.PulsarConfig.validate(); err != nil {
 			return err
 		}
+		if s.PulsarConfig.LargeMessageHandle != nil {
+			err := s.PulsarConfig.LargeMessageHandle.AdjustAndValidate(protocol, enableTiDBExtension)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	for _, rule := range s.DispatchRules {
@@ -783,6 +990,12 @@ func (s *SinkConfig) validateAndAdjust(sinkURI *url.URL) error {
 		s.Terminator = util.AddressOf(CRLF)
 	}
 
+	if util.GetOrZero(s.EnableSchemaVersion) && !protocol.SupportSchemaVersion() {
+		return cerror.ErrSinkInvalidConfig.GenWithStack(
+			"enable-schema-version is only supported by the canal-json, open-protocol and maxwell protocol, "+
+				"but got %s", protocol)
+	}
+
 	if util.GetOrZero(s.DeleteOnlyOutputHandleKeyColumns) && protocol == ProtocolCsv {
 		return cerror.ErrSinkInvalidConfig.GenWithStack(
 			"CSV protocol always output all columns for the delete event, " +
@@ -853,7 +1066,12 @@ func (s *SinkConfig) validateAndAdjustSinkURI(sinkURI *url.URL) error {
 			"is incompatible with %s scheme", util.GetOrZero(s.Protocol), sinkURI.Scheme))
 	}
 	// For testing purposes, any protocol should be legal for blackhole.
-	if sink.IsMQScheme(sinkURI.Scheme) || sink.IsStorageScheme(sinkURI.Scheme) {
+	if sink.IsMQScheme(sinkURI.Scheme) || sink.IsStorageScheme(sinkURI.Scheme) ||
+		sink.IsWebhookScheme(sinkURI.Scheme) {
+		return s.ValidateProtocol(sinkURI.Scheme)
+	}
+	// The protocol is only required by the stream mode of redis sink.
+	if sink.IsRedisScheme(sinkURI.Scheme) && s.Protocol != nil {
 		return s.ValidateProtocol(sinkURI.Scheme)
 	}
 	return nil
@@ -40,6 +40,7 @@ const (
 	ProtocolCsv
 	ProtocolDebezium
 	ProtocolSimple
+	ProtocolParquet
 )
 
 // IsBatchEncode returns whether the protocol is a batch encoder.
@@ -47,6 +48,18 @@ func (p Protocol) IsBatchEncode() bool {
 	return p == ProtocolOpen || p == ProtocolCanal || p == ProtocolMaxwell || p == ProtocolCraft
 }
 
+// SupportSchemaVersion returns whether the protocol can encode the schema version in the messages.
+func (p Protocol) SupportSchemaVersion() bool {
+	return p == ProtocolOpen || p == ProtocolCanalJSON || p == ProtocolMaxwell
+}
+
+// SupportControlMessage returns whether the protocol can carry the control messages of TiCDC,
+// such as the partition mapping change markers of the adaptive partition dispatcher
+// and the fences of the DDLs sent to the DDL topics.
+func (p Protocol) SupportControlMessage() bool {
+	return p == ProtocolSimple
+}
+
 // ParseSinkProtocolFromString converts the protocol from string to Protocol enum type.
 func ParseSinkProtocolFromString(protocol string) (Protocol, error) {
 	switch strings.ToLower(protocol) {
@@ -72,6 +85,8 @@ func ParseSinkProtocolFromString(protocol string) (Protocol, error) {
 		return ProtocolDebezium, nil
 	case "simple":
 		return ProtocolSimple, nil
+	case "parquet":
+		return ProtocolParquet, nil
 	default:
 		return ProtocolUnknown, cerror.ErrSinkUnknownProtocol.GenWithStackByArgs(protocol)
 	}
@@ -100,6 +115,8 @@ func (p Protocol) String() string {
 		return "debezium"
 	case ProtocolSimple:
 		return "simple"
+	case ProtocolParquet:
+		return "parquet"
 	default:
 		panic("unreachable")
 	}
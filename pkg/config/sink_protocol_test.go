@@ -62,6 +62,10 @@ func TestParseSinkProtocolFromString(t *testing.T) {
 			protocol:             "open-protocol",
 			expectedProtocolEnum: ProtocolOpen,
 		},
+		{
+			protocol:             "parquet",
+			expectedProtocolEnum: ProtocolParquet,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -109,6 +113,10 @@ func TestString(t *testing.T) {
 			protocolEnum:     ProtocolOpen,
 			expectedProtocol: "open-protocol",
 		},
+		{
+			protocolEnum:     ProtocolParquet,
+			expectedProtocol: "parquet",
+		},
 	}
 
 	for _, tc := range testCases {
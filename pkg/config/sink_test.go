@@ -17,6 +17,8 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/apache/pulsar-client-go/pulsar"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/util"
 	"github.com/stretchr/testify/require"
 )
@@ -418,6 +420,78 @@ func TestValidateAndAdjustCSVConfig(t *testing.T) {
 	}
 }
 
+func TestValidateColumnTransformer(t *testing.T) {
+	t.Parallel()
+	matcher := []string{"test.*"}
+	columns := []string{"c"}
+	tests := []struct {
+		name    string
+		config  *ColumnTransformer
+		wantErr string
+	}{
+		{
+			name:   "valid mask",
+			config: &ColumnTransformer{Matcher: matcher, Columns: columns, Action: ColumnTransformMask},
+		},
+		{
+			name:   "valid hash",
+			config: &ColumnTransformer{Matcher: matcher, Columns: columns, Action: ColumnTransformHash},
+		},
+		{
+			name:    "no columns",
+			config:  &ColumnTransformer{Matcher: matcher, Action: ColumnTransformHash},
+			wantErr: "column transformer must have matcher and columns",
+		},
+		{
+			name:    "negative length",
+			config:  &ColumnTransformer{Matcher: matcher, Columns: columns, Action: ColumnTransformMask, Length: -1},
+			wantErr: "column transformer length should not be negative",
+		},
+		{
+			name:    "truncate without length",
+			config:  &ColumnTransformer{Matcher: matcher, Columns: columns, Action: ColumnTransformTruncate},
+			wantErr: "column transformer length should be greater than 0 for truncate",
+		},
+		{
+			name:    "tokenize without salt",
+			config:  &ColumnTransformer{Matcher: matcher, Columns: columns, Action: ColumnTransformTokenize},
+			wantErr: "column transformer salt should not be empty for tokenize",
+		},
+		{
+			name: "rename multiple columns",
+			config: &ColumnTransformer{
+				Matcher: matcher, Columns: []string{"a", "b"}, Action: ColumnTransformRename, NewName: "c",
+			},
+			wantErr: "column transformer rename should have exactly one column and a new name",
+		},
+		{
+			name:    "unknown action",
+			config:  &ColumnTransformer{Matcher: matcher, Columns: columns, Action: "encrypt"},
+			wantErr: "column transformer action encrypt is not supported",
+		},
+		{
+			name: "salt is not leaked",
+			config: &ColumnTransformer{
+				Matcher: matcher, Columns: columns, Action: "encrypt", Salt: "secret-salt",
+			},
+			wantErr: "column transformer action encrypt is not supported",
+		},
+	}
+	for _, c := range tests {
+		tc := c
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.config.validate()
+			if tc.wantErr == "" {
+				require.Nil(t, err)
+			} else {
+				require.Regexp(t, tc.wantErr, err)
+				require.NotContains(t, err.Error(), "secret-salt")
+			}
+		})
+	}
+}
+
 func TestValidateAndAdjustStorageConfig(t *testing.T) {
 	t.Parallel()
 
@@ -438,6 +512,72 @@ func TestValidateAndAdjustStorageConfig(t *testing.T) {
 	require.Equal(t, 16, util.GetOrZero(s.Sink.FileIndexWidth))
 }
 
+func TestValidateAndAdjustPulsarConfig(t *testing.T) {
+	t.Parallel()
+
+	sinkURI, err := url.Parse("pulsar://127.0.0.1:6650/topic?protocol=canal-json")
+	require.NoError(t, err)
+	s := GetDefaultReplicaConfig()
+	s.Sink.PulsarConfig = &PulsarConfig{
+		BatcherBuilder: util.AddressOf(PulsarBatcherBuilder(PulsarBatcherBuilderKeyBased)),
+	}
+	require.NoError(t, s.ValidateAndAdjust(sinkURI))
+	require.Equal(t, pulsar.KeyBasedBatchBuilder, s.Sink.PulsarConfig.BatcherBuilder.Value())
+
+	s.Sink.PulsarConfig.BatcherBuilder = util.AddressOf(PulsarBatcherBuilder("unknown"))
+	require.ErrorIs(t, s.ValidateAndAdjust(sinkURI), cerror.ErrInvalidReplicaConfig)
+
+	// the claim-check requires the tidb extension.
+	s.Sink.PulsarConfig.BatcherBuilder = nil
+	s.Sink.PulsarConfig.LargeMessageHandle = &LargeMessageHandleConfig{
+		LargeMessageHandleOption: LargeMessageHandleOptionClaimCheck,
+		ClaimCheckStorageURI:     "file:///tmp/claim-check",
+	}
+	require.ErrorIs(t, s.ValidateAndAdjust(sinkURI), cerror.ErrInvalidReplicaConfig)
+	sinkURI, err = url.Parse("pulsar://127.0.0.1:6650/topic?protocol=canal-json&enable-tidb-extension=true")
+	require.NoError(t, err)
+	require.NoError(t, s.ValidateAndAdjust(sinkURI))
+
+	// the chunking disables the batching.
+	s.Sink.PulsarConfig.LargeMessageHandle = &LargeMessageHandleConfig{
+		LargeMessageHandleOption: LargeMessageHandleOptionChunking,
+	}
+	require.NoError(t, s.ValidateAndAdjust(sinkURI))
+	s.Sink.PulsarConfig.BatcherBuilder = util.AddressOf(PulsarBatcherBuilder(PulsarBatcherBuilderKeyBased))
+	require.ErrorIs(t, s.ValidateAndAdjust(sinkURI), cerror.ErrInvalidReplicaConfig)
+
+	// the chunking is not supported by the kafka sink.
+	sinkURI, err = url.Parse("kafka://127.0.0.1:9092/topic?protocol=canal-json")
+	require.NoError(t, err)
+	s = GetDefaultReplicaConfig()
+	s.Sink.KafkaConfig = &KafkaConfig{
+		LargeMessageHandle: &LargeMessageHandleConfig{
+			LargeMessageHandleOption: LargeMessageHandleOptionChunking,
+		},
+	}
+	require.ErrorIs(t, s.ValidateAndAdjust(sinkURI), cerror.ErrInvalidReplicaConfig)
+}
+
+func TestValidateAndAdjustSchemaVersion(t *testing.T) {
+	t.Parallel()
+
+	sinkURI, err := url.Parse("kafka://127.0.0.1:9092/topic?protocol=canal-json")
+	require.NoError(t, err)
+	s := GetDefaultReplicaConfig()
+	s.Sink.EnableSchemaVersion = util.AddressOf(true)
+	require.NoError(t, s.ValidateAndAdjust(sinkURI))
+	require.True(t, s.Sink.ShouldEncodeSchemaVersion())
+	require.False(t, s.Sink.ShouldSendBootstrapMsg())
+
+	// the schema version is not supported by the avro protocol.
+	sinkURI, err = url.Parse("kafka://127.0.0.1:9092/topic?protocol=avro")
+	require.NoError(t, err)
+	s = GetDefaultReplicaConfig()
+	s.Sink.EnableSchemaVersion = util.AddressOf(true)
+	require.ErrorIs(t, s.ValidateAndAdjust(sinkURI), cerror.ErrSinkInvalidConfig)
+	require.False(t, s.Sink.ShouldEncodeSchemaVersion())
+}
+
 func TestShouldSendBootstrapMsg(t *testing.T) {
 	t.Parallel()
 	sinkConfig := GetDefaultReplicaConfig().Sink
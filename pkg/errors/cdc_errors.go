@@ -134,6 +134,10 @@ var (
 		"column selector failed",
 		errors.RFCCodeText("CDC:ErrColumnSelectorFailed"),
 	)
+	ErrColumnTransformerFailed = errors.Normalize(
+		"column transformer failed",
+		errors.RFCCodeText("CDC:ErrColumnTransformerFailed"),
+	)
 
 	// internal errors
 	ErrAdminStopProcessor = errors.Normalize(
@@ -182,6 +186,10 @@ var (
 		"kafka async send message failed",
 		errors.RFCCodeText("CDC:ErrKafkaAsyncSendMessage"),
 	)
+	ErrKafkaTransaction = errors.Normalize(
+		"kafka transaction failed",
+		errors.RFCCodeText("CDC:ErrKafkaTransaction"),
+	)
 	ErrKafkaInvalidPartitionNum = errors.Normalize(
 		"invalid partition num %d",
 		errors.RFCCodeText("CDC:ErrKafkaInvalidPartitionNum"),
@@ -292,6 +300,10 @@ var (
 		"redo file operation",
 		errors.RFCCodeText("CDC:ErrRedoFileOp"),
 	)
+	ErrRedoLogCorrupted = errors.Normalize(
+		"redo logs are corrupted, %d problems found",
+		errors.RFCCodeText("CDC:ErrRedoLogCorrupted"),
+	)
 	ErrRedoMetaFileNotFound = errors.Normalize(
 		"no redo meta file found in dir: %s",
 		errors.RFCCodeText("CDC:ErrRedoMetaFileNotFound"),
@@ -435,10 +447,22 @@ var (
 		"csv decode failed",
 		errors.RFCCodeText("CDC:ErrCSVDecodeFailed"),
 	)
+	ErrParquetEncodeFailed = errors.Normalize(
+		"parquet encode failed",
+		errors.RFCCodeText("CDC:ErrParquetEncodeFailed"),
+	)
+	ErrParquetDecodeFailed = errors.Normalize(
+		"parquet decode failed",
+		errors.RFCCodeText("CDC:ErrParquetDecodeFailed"),
+	)
 	ErrDebeziumEncodeFailed = errors.Normalize(
 		"debezium encode failed",
 		errors.RFCCodeText("CDC:ErrDebeziumEncodeFailed"),
 	)
+	ErrDebeziumDecodeFailed = errors.Normalize(
+		"debezium decode failed",
+		errors.RFCCodeText("CDC:ErrDebeziumDecodeFailed"),
+	)
 	ErrStorageSinkInvalidConfig = errors.Normalize(
 		"storage sink config invalid",
 		errors.RFCCodeText("CDC:ErrStorageSinkInvalidConfig"),
@@ -447,6 +471,46 @@ var (
 		"filename in storage sink is invalid",
 		errors.RFCCodeText("CDC:ErrStorageSinkInvalidFileName"),
 	)
+	ErrIcebergSinkInvalidConfig = errors.Normalize(
+		"iceberg sink config invalid",
+		errors.RFCCodeText("CDC:ErrIcebergSinkInvalidConfig"),
+	)
+	ErrIcebergEncodeFailed = errors.Normalize(
+		"iceberg encode failed",
+		errors.RFCCodeText("CDC:ErrIcebergEncodeFailed"),
+	)
+	ErrIcebergCommitConflict = errors.Normalize(
+		"iceberg table %s has been committed by others, version: %d",
+		errors.RFCCodeText("CDC:ErrIcebergCommitConflict"),
+	)
+	ErrWebhookSinkInvalidConfig = errors.Normalize(
+		"webhook sink config invalid",
+		errors.RFCCodeText("CDC:ErrWebhookSinkInvalidConfig"),
+	)
+	ErrWebhookSinkRequestFailed = errors.Normalize(
+		"webhook sink request failed, status code: %d, response: %s",
+		errors.RFCCodeText("CDC:ErrWebhookSinkRequestFailed"),
+	)
+	ErrRedisSinkInvalidConfig = errors.Normalize(
+		"redis sink config invalid",
+		errors.RFCCodeText("CDC:ErrRedisSinkInvalidConfig"),
+	)
+	ErrRedisSinkWriteFailed = errors.Normalize(
+		"redis sink write failed",
+		errors.RFCCodeText("CDC:ErrRedisSinkWriteFailed"),
+	)
+	ErrElasticsearchSinkInvalidConfig = errors.Normalize(
+		"elasticsearch sink config invalid",
+		errors.RFCCodeText("CDC:ErrElasticsearchSinkInvalidConfig"),
+	)
+	ErrElasticsearchRequestFailed = errors.Normalize(
+		"elasticsearch request failed, status code: %d, response: %s",
+		errors.RFCCodeText("CDC:ErrElasticsearchRequestFailed"),
+	)
+	ErrElasticsearchUnsupportedDDL = errors.Normalize(
+		"ddl can't be applied to index %s safely: %s",
+		errors.RFCCodeText("CDC:ErrElasticsearchUnsupportedDDL"),
+	)
 
 	// utilities related errors
 	ErrToTLSConfigFailed = errors.Normalize(
@@ -60,6 +60,15 @@ const (
 	// `8*64MB = 512MB` by default.
 	DefaultFlushWorkerNum = 8
 
+	// DefaultCompactionIntervalInMs is the default interval of compacting redo log segments.
+	DefaultCompactionIntervalInMs = 5 * 60 * 1000
+	// MinCompactionIntervalInMs is the minimum interval of compacting redo log segments.
+	MinCompactionIntervalInMs = 1000
+	// DefaultCompactedSegmentSize is the default max size(MiB) of a compacted segment.
+	DefaultCompactedSegmentSize = int64(512)
+	// CompactionBlockSize is the size of the blocks indexed in a compacted segment.
+	CompactionBlockSize = Megabyte
+
 	// DefaultFileMode is the default mode when operation files
 	DefaultFileMode = 0o644
 	// DefaultDirMode is the default mode when operation dir
@@ -73,6 +82,8 @@ const (
 	MetaEXT = ".meta"
 	// SortLogEXT is the sorted log file ext of log file after safely wrote to disk
 	SortLogEXT = ".sort"
+	// IndexEXT is the file ext of the index beside a compacted log file
+	IndexEXT = ".index"
 
 	// MinSectorSize is minimum sector size used when flushing log so that log can safely
 	// distinguish between torn writes and ordinary data corruption.
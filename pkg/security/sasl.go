@@ -37,6 +37,12 @@ const (
 	GSSAPIMechanism SASLMechanism = sarama.SASLTypeGSSAPI
 	// OAuthMechanism means the SASL mechanism is OAuth2.
 	OAuthMechanism SASLMechanism = sarama.SASLTypeOAuth
+	// AWSMSKIAMMechanism means the AWS MSK IAM authentication, which sends the
+	// signed request as the token by the SASL/OAUTHBEARER mechanism.
+	AWSMSKIAMMechanism SASLMechanism = "AWS_MSK_IAM"
+	// EventHubsMechanism means the Azure Event Hubs connection string authentication,
+	// which sends the connection string as the password by the SASL/PLAIN mechanism.
+	EventHubsMechanism SASLMechanism = "EVENT_HUBS"
 )
 
 // SASLMechanismFromString converts the string to SASL mechanism.
@@ -52,6 +58,10 @@ func SASLMechanismFromString(s string) (SASLMechanism, error) {
 		return GSSAPIMechanism, nil
 	case "oauthbearer":
 		return OAuthMechanism, nil
+	case "aws-msk-iam", "aws_msk_iam":
+		return AWSMSKIAMMechanism, nil
+	case "event-hubs", "event_hubs":
+		return EventHubsMechanism, nil
 	default:
 		return UnknownMechanism, errors.Errorf("unknown %s SASL mechanism", s)
 	}
@@ -64,6 +74,45 @@ type SASL struct {
 	SASLMechanism SASLMechanism
 	GSSAPI        GSSAPI
 	OAuth2        OAuth2
+	AWSMSKIAM     AWSMSKIAM
+}
+
+// AWSMSKIAM holds necessary parameters to support the AWS MSK IAM authentication.
+// The credentials are loaded from the environment variables, the shared config
+// and credentials files, or the instance role, in the default order of the AWS SDK.
+type AWSMSKIAM struct {
+	// Region is the region of the MSK cluster, the AWS_REGION environment variable
+	// or the region of the profile is used if it's empty.
+	Region string
+	// Profile is the profile in the shared config and credentials files.
+	Profile string
+}
+
+// EventHubsConnectionStringUser is the SASL/PLAIN user name when the
+// connection string of the Azure Event Hubs is used as the password.
+const EventHubsConnectionStringUser = "$ConnectionString"
+
+// ValidateEventHubsConnectionString checks the connection string of the Azure Event Hubs
+// is in the form of `Endpoint=sb://<namespace>.servicebus.windows.net/;SharedAccessKeyName=<name>;SharedAccessKey=<key>`.
+func ValidateEventHubsConnectionString(connectionString string) error {
+	fields := make(map[string]string)
+	for _, part := range strings.Split(connectionString, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			fields[strings.ToLower(key)] = value
+		}
+	}
+	if !strings.HasPrefix(fields["endpoint"], "sb://") {
+		return errors.New("the endpoint of the Event Hubs connection string should start with sb://")
+	}
+	if fields["sharedaccesssignature"] != "" {
+		return nil
+	}
+	if fields["sharedaccesskeyname"] == "" || fields["sharedaccesskey"] == "" {
+		return errors.New("the Event Hubs connection string should contain " +
+			"SharedAccessKeyName and SharedAccessKey, or SharedAccessSignature")
+	}
+	return nil
 }
 
 // OAuth2 holds necessary parameters to support sasl-oauth2.
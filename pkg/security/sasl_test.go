@@ -74,6 +74,21 @@ func TestSASLMechanismFromString(t *testing.T) {
 			s:                 "GSSAPI",
 			expectedMechanism: "GSSAPI",
 		},
+		{
+			name:              "aws msk iam mechanism",
+			s:                 "aws-msk-iam",
+			expectedMechanism: "AWS_MSK_IAM",
+		},
+		{
+			name:              "upper case AWS_MSK_IAM mechanism",
+			s:                 "AWS_MSK_IAM",
+			expectedMechanism: "AWS_MSK_IAM",
+		},
+		{
+			name:              "event hubs mechanism",
+			s:                 "event-hubs",
+			expectedMechanism: "EVENT_HUBS",
+		},
 	}
 	for _, test := range tests {
 		test := test
@@ -144,3 +159,46 @@ func TestAuthTypeFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateEventHubsConnectionString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		connectionString string
+		expectErr        string
+	}{
+		{
+			name: "shared access key",
+			connectionString: "Endpoint=sb://test.servicebus.windows.net/;" +
+				"SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=a2V5",
+		},
+		{
+			name: "shared access signature",
+			connectionString: "Endpoint=sb://test.servicebus.windows.net/;" +
+				"SharedAccessSignature=SharedAccessSignature sr=test&sig=c2ln",
+		},
+		{
+			name:             "invalid endpoint",
+			connectionString: "Endpoint=https://test.servicebus.windows.net/;SharedAccessKeyName=a;SharedAccessKey=b",
+			expectErr:        "should start with sb://",
+		},
+		{
+			name:             "missing key",
+			connectionString: "Endpoint=sb://test.servicebus.windows.net/;SharedAccessKeyName=a",
+			expectErr:        "SharedAccessKeyName and SharedAccessKey",
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidateEventHubsConnectionString(test.connectionString)
+			if test.expectErr != "" {
+				require.ErrorContains(t, err, test.expectErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/security"
+	"go.uber.org/zap"
+)
+
+// schemaTypeJSON is the schema type of JSON Schema in the confluent schema registry.
+const schemaTypeJSON = "JSON"
+
+// JSONSchemaManager is used to register JSON Schemas to the confluent schema registry,
+// for the protocols which encode messages in JSON, such as simple and debezium.
+// Each message is prefixed with the confluent wire format header, which is the
+// same as the confluent avro one.
+type JSONSchemaManager struct {
+	m *confluentSchemaManager
+
+	cacheRWLock sync.RWMutex
+	cache       map[string]*jsonSchemaCacheEntry
+}
+
+type jsonSchemaCacheEntry struct {
+	tableVersion uint64
+	schemaID     int
+	header       []byte
+}
+
+// NewConfluentJSONSchemaManager creates a JSONSchemaManager,
+// and test connectivity to the schema registry.
+func NewConfluentJSONSchemaManager(
+	ctx context.Context,
+	registryURL string,
+	credential *security.Credential,
+) (*JSONSchemaManager, error) {
+	m, err := newConfluentSchemaManager(ctx, registryURL, credential, schemaTypeJSON)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &JSONSchemaManager{
+		m:     m,
+		cache: make(map[string]*jsonSchemaCacheEntry),
+	}, nil
+}
+
+// GetCachedOrRegister checks if the JSON Schema of the subject has been registered
+// for the table version. If not, a new schema is generated, registered and cached.
+// It returns the wire format header which should be prepended to the JSON message.
+func (m *JSONSchemaManager) GetCachedOrRegister(
+	ctx context.Context,
+	subject string,
+	tableVersion uint64,
+	schemaGen SchemaGenerator,
+) ([]byte, error) {
+	m.cacheRWLock.RLock()
+	if entry, exists := m.cache[subject]; exists && entry.tableVersion == tableVersion {
+		m.cacheRWLock.RUnlock()
+		return entry.header, nil
+	}
+	m.cacheRWLock.RUnlock()
+
+	log.Info("JSON schema lookup cache miss",
+		zap.String("subject", subject),
+		zap.Uint64("tableVersion", tableVersion))
+
+	schema, err := schemaGen()
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid([]byte(schema)) {
+		return nil, cerror.ErrAvroSchemaAPIError.GenWithStack(
+			"invalid JSON Schema generated for subject %s", subject)
+	}
+
+	id, err := m.m.Register(ctx, subject, schema)
+	if err != nil {
+		log.Error("GetCachedOrRegister: Could not register JSON schema", zap.Error(err))
+		return nil, errors.Trace(err)
+	}
+	header, err := m.m.getMsgHeader(id.confluentSchemaID)
+	if err != nil {
+		return nil, err
+	}
+	// limit the capacity, so that appending the message to the shared header always copies.
+	header = header[:len(header):len(header)]
+
+	m.cacheRWLock.Lock()
+	m.cache[subject] = &jsonSchemaCacheEntry{
+		tableVersion: tableVersion,
+		schemaID:     id.confluentSchemaID,
+		header:       header,
+	}
+	m.cacheRWLock.Unlock()
+
+	log.Info("JSON schema GetCachedOrRegister successful with cache miss",
+		zap.String("subject", subject),
+		zap.Uint64("tableVersion", tableVersion),
+		zap.Int("schemaID", id.confluentSchemaID))
+	return header, nil
+}
+
+// ClearRegistry clears the Registry subject. Should be idempotent.
+func (m *JSONSchemaManager) ClearRegistry(ctx context.Context, subject string) error {
+	return m.m.ClearRegistry(ctx, subject)
+}
+
+// SplitConfluentJSONMessage splits the confluent wire format header from the JSON message,
+// and returns the schema ID and the JSON payload. A JSON payload never starts with the
+// magic byte, so the message is returned as is with the schema ID 0 if it has no header.
+func SplitConfluentJSONMessage(data []byte) (int, []byte, error) {
+	if len(data) == 0 || data[0] != magicByte {
+		return 0, data, nil
+	}
+	id, payload, err := extractConfluentSchemaIDAndBinaryData(data)
+	if err != nil {
+		return 0, nil, errors.Trace(err)
+	}
+	return id, payload, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package avro
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchemaRegistry(t *testing.T) {
+	startHTTPInterceptForTestingRegistry()
+	defer stopHTTPInterceptForTestingRegistry()
+
+	ctx := getTestingContext()
+	manager, err := NewConfluentJSONSchemaManager(ctx, "http://127.0.0.1:8081", nil)
+	require.NoError(t, err)
+
+	subject := "cdctest-test.t"
+	generated := 0
+	schemaGen := func(schema string) SchemaGenerator {
+		return func() (string, error) {
+			generated++
+			return schema, nil
+		}
+	}
+
+	header, err := manager.GetCachedOrRegister(ctx, subject, 1,
+		schemaGen(`{"type": "object", "properties": {"a": {"type": "string"}}}`))
+	require.NoError(t, err)
+	require.Len(t, header, 5)
+	require.Equal(t, magicByte, header[0])
+	require.Equal(t, 1, generated)
+
+	id, err := getConfluentSchemaIDFromHeader(header)
+	require.NoError(t, err)
+	resp, err := http.Get("http://127.0.0.1:8081/schemas/ids/" + strconv.Itoa(int(id)))
+	require.NoError(t, err)
+	var lookup lookupResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&lookup))
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, schemaTypeJSON, lookup.SchemaType)
+	require.JSONEq(t, `{"type": "object", "properties": {"a": {"type": "string"}}}`, lookup.Schema)
+
+	// the same table version hits the cache.
+	cached, err := manager.GetCachedOrRegister(ctx, subject, 1, schemaGen(`{}`))
+	require.NoError(t, err)
+	require.Equal(t, header, cached)
+	require.Equal(t, 1, generated)
+
+	// a new table version registers a new schema.
+	newHeader, err := manager.GetCachedOrRegister(ctx, subject, 2,
+		schemaGen(`{"type": "object", "properties": {"b": {"type": "string"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, 2, generated)
+	require.NotEqual(t, header, newHeader)
+
+	_, err = manager.GetCachedOrRegister(ctx, subject, 3, schemaGen(`{`))
+	require.Error(t, err)
+
+	require.NoError(t, manager.ClearRegistry(ctx, subject))
+}
+
+func TestSplitConfluentJSONMessage(t *testing.T) {
+	t.Parallel()
+
+	id, payload, err := SplitConfluentJSONMessage([]byte(`{"a":"1"}`))
+	require.NoError(t, err)
+	require.Equal(t, 0, id)
+	require.Equal(t, `{"a":"1"}`, string(payload))
+
+	id, payload, err = SplitConfluentJSONMessage(append([]byte{0, 0, 0, 1, 2}, `{"a":"1"}`...))
+	require.NoError(t, err)
+	require.Equal(t, 258, id)
+	require.Equal(t, `{"a":"1"}`, string(payload))
+
+	_, _, err = SplitConfluentJSONMessage([]byte{0, 0})
+	require.Error(t, err)
+}
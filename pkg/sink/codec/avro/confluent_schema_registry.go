@@ -52,12 +52,14 @@ type confluentSchemaManager struct {
 	cacheRWLock  sync.RWMutex
 	cache        map[string]*schemaCacheEntry
 	registryType string
+	// schemaType is empty for Avro schemas, so that the request is compatible
+	// with Confluent 5.4.x, which only supports Avro.
+	schemaType string
 }
 
 type registerRequest struct {
-	Schema string `json:"schema"`
-	// Commented out for compatibility with Confluent 5.4.x
-	// SchemaType string `json:"schemaType"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
 }
 
 type registerResponse struct {
@@ -65,9 +67,10 @@ type registerResponse struct {
 }
 
 type lookupResponse struct {
-	Name     string `json:"name"`
-	SchemaID int    `json:"id"`
-	Schema   string `json:"schema"`
+	Name       string `json:"name"`
+	SchemaID   int    `json:"id"`
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
 }
 
 // NewConfluentSchemaManager create schema managers,
@@ -77,6 +80,15 @@ func NewConfluentSchemaManager(
 	registryURL string,
 	credential *security.Credential,
 ) (SchemaManager, error) {
+	return newConfluentSchemaManager(ctx, registryURL, credential, "")
+}
+
+func newConfluentSchemaManager(
+	ctx context.Context,
+	registryURL string,
+	credential *security.Credential,
+	schemaType string,
+) (*confluentSchemaManager, error) {
 	registryURL = strings.TrimRight(registryURL, "/")
 	httpCli, err := httputil.NewClient(credential)
 	if err != nil {
@@ -111,6 +123,7 @@ func NewConfluentSchemaManager(
 		registryURL:  registryURL,
 		cache:        make(map[string]*schemaCacheEntry, 1),
 		registryType: common.SchemaRegistryTypeConfluent,
+		schemaType:   schemaType,
 	}, nil
 }
 
@@ -131,7 +144,8 @@ func (m *confluentSchemaManager) Register(
 		return id, cerror.WrapError(cerror.ErrAvroSchemaAPIError, err)
 	}
 	reqBody := registerRequest{
-		Schema: buffer.String(),
+		Schema:     buffer.String(),
+		SchemaType: m.schemaType,
 	}
 	payload, err := json.Marshal(&reqBody)
 	if err != nil {
@@ -23,9 +23,10 @@ import (
 )
 
 type mockConfluentRegistrySchema struct {
-	content string
-	version int
-	ID      int
+	content    string
+	schemaType string
+	version    int
+	ID         int
 }
 
 type mockRegistry struct {
@@ -69,9 +70,10 @@ func startHTTPInterceptForTestingRegistry() {
 			item, exists := registry.subjects[subject]
 			if !exists {
 				item = &mockConfluentRegistrySchema{
-					content: reqData.Schema,
-					version: 1,
-					ID:      registry.newID,
+					content:    reqData.Schema,
+					schemaType: reqData.SchemaType,
+					version:    1,
+					ID:         registry.newID,
 				}
 				registry.subjects[subject] = item
 				respData.SchemaID = registry.newID
@@ -80,6 +82,7 @@ func startHTTPInterceptForTestingRegistry() {
 					respData.SchemaID = item.ID
 				} else {
 					item.content = reqData.Schema
+					item.schemaType = reqData.SchemaType
 					item.version++
 					item.ID = registry.newID
 					respData.SchemaID = registry.newID
@@ -97,12 +100,15 @@ func startHTTPInterceptForTestingRegistry() {
 				return httpmock.NewStringResponse(500, "Internal Server Error"), err
 			}
 
+			registry.mu.Lock()
+			defer registry.mu.Unlock()
 			for key, item := range registry.subjects {
 				if item.ID == int(id) {
 					var respData lookupResponse
 					respData.Schema = item.content
 					respData.Name = key
 					respData.SchemaID = item.ID
+					respData.SchemaType = item.schemaType
 					return httpmock.NewJsonResponse(200, &respData)
 				}
 			}
@@ -144,3 +150,14 @@ func startHTTPInterceptForTestingRegistry() {
 func stopHTTPInterceptForTestingRegistry() {
 	httpmock.DeactivateAndReset()
 }
+
+// StartSchemaRegistry4Testing starts a local confluent schema registry at
+// http://127.0.0.1:8081 for testing.
+func StartSchemaRegistry4Testing() {
+	startHTTPInterceptForTestingRegistry()
+}
+
+// StopSchemaRegistry4Testing stops the local confluent schema registry for testing.
+func StopSchemaRegistry4Testing() {
+	stopHTTPInterceptForTestingRegistry()
+}
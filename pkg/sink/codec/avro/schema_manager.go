@@ -29,7 +29,7 @@ type SchemaManager interface {
 	ClearRegistry(ctx context.Context, schemaName string) error
 }
 
-// SchemaGenerator represents a function that returns an Avro schema in JSON.
+// SchemaGenerator represents a function that returns an Avro schema or a JSON Schema in JSON.
 // Used for lazy evaluation
 type SchemaGenerator func() (string, error)
 
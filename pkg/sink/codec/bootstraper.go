@@ -34,7 +34,7 @@ const (
 )
 
 // bootstrapWorker is used to send bootstrap message to the MQ sink worker.
-// It will be only used in simple protocol.
+// It's used in simple protocol, and the protocols encoding the schema version.
 type bootstrapWorker struct {
 	changefeedID                model.ChangeFeedID
 	activeTables                sync.Map
@@ -42,9 +42,15 @@ type bootstrapWorker struct {
 	sendBootstrapInterval       time.Duration
 	sendBootstrapInMsgCount     int32
 	sendBootstrapToAllPartition bool
+	// sendOnSchemaChange indicates the bootstrap message is sent only when
+	// a table is added or its schema is changed, instead of periodically.
+	sendOnSchemaChange bool
 	// maxInactiveDuration is the max duration that a table can be inactive
 	maxInactiveDuration time.Duration
 	outCh               chan<- *future
+	// ddlTopic returns the DDL topic of the table, if it's not empty,
+	// the bootstrap message is sent to the DDL topic instead.
+	ddlTopic func(schema, table string) string
 }
 
 // newBootstrapWorker creates a new bootstrapWorker instance
@@ -74,6 +80,28 @@ func newBootstrapWorker(
 	}
 }
 
+// newSchemaVersionBootstrapWorker creates a new bootstrapWorker instance, which sends
+// the bootstrap message once a table is added or its schema version is changed.
+func newSchemaVersionBootstrapWorker(
+	changefeedID model.ChangeFeedID,
+	outCh chan<- *future,
+	encoder RowEventEncoder,
+	sendBootstrapToAllPartition bool,
+	maxInactiveDuration time.Duration,
+) *bootstrapWorker {
+	log.Info("Sending bootstrap event on schema change is enabled, since enable-schema-version is true.",
+		zap.Stringer("changefeed", changefeedID))
+	return &bootstrapWorker{
+		changefeedID:                changefeedID,
+		outCh:                       outCh,
+		encoder:                     encoder,
+		activeTables:                sync.Map{},
+		sendBootstrapToAllPartition: sendBootstrapToAllPartition,
+		sendOnSchemaChange:          true,
+		maxInactiveDuration:         maxInactiveDuration,
+	}
+}
+
 func (b *bootstrapWorker) run(ctx context.Context) error {
 	sendTicker := time.NewTicker(bootstrapWorkerTickerInterval)
 	gcTicker := time.NewTicker(bootstrapWorkerGCInterval)
@@ -88,6 +116,9 @@ func (b *bootstrapWorker) run(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-sendTicker.C:
+			if b.sendOnSchemaChange {
+				continue
+			}
 			b.activeTables.Range(func(key, value interface{}) bool {
 				table := value.(*tableStatistic)
 				err = b.sendBootstrapMsg(ctx, table)
@@ -119,6 +150,12 @@ func (b *bootstrapWorker) addEvent(
 	} else {
 		// If the table is already in the activeTables, update its status.
 		table.(*tableStatistic).update(row, key.TotalPartition)
+		if b.sendOnSchemaChange {
+			// Send bootstrap message before the row if the schema is changed.
+			if err := b.sendBootstrapMsg(ctx, table.(*tableStatistic)); err != nil {
+				return errors.Trace(err)
+			}
+		}
 	}
 	return nil
 }
@@ -126,9 +163,15 @@ func (b *bootstrapWorker) addEvent(
 // sendBootstrapMsg sends a bootstrap message if the table meets the condition
 // 1. The time since last bootstrap message sent is larger than sendBootstrapInterval
 // 2. The received row event count since last bootstrap message sent is larger than sendBootstrapInMsgCount
+// If sendOnSchemaChange is true, it's sent only if the table schema is changed since
+// last bootstrap message sent.
 // Note: It is a blocking method, it will block if the outCh is full.
 func (b *bootstrapWorker) sendBootstrapMsg(ctx context.Context, table *tableStatistic) error {
-	if !table.shouldSendBootstrapMsg(
+	if b.sendOnSchemaChange {
+		if !table.schemaChanged.CompareAndSwap(true, false) {
+			return nil
+		}
+	} else if !table.shouldSendBootstrapMsg(
 		b.sendBootstrapInterval,
 		b.sendBootstrapInMsgCount) {
 		return nil
@@ -159,6 +202,16 @@ func (b *bootstrapWorker) generateEvents(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if b.ddlTopic != nil {
+		if ddlTopic := b.ddlTopic(tableInfo.GetSchemaName(), tableInfo.GetTableName()); ddlTopic != "" {
+			// The DDL topic is compacted, which rejects the messages without key.
+			if msg != nil && len(msg.Key) == 0 {
+				msg.Key = []byte(tableInfo.TableName.String())
+			}
+			topic = ddlTopic
+			totalPartition = 1
+		}
+	}
 	// If sendBootstrapToAllPartition is true, send bootstrap message to all partition
 	// Otherwise, send bootstrap message to partition 0.
 	if !b.sendBootstrapToAllPartition {
@@ -213,6 +266,9 @@ type tableStatistic struct {
 	// version is the table version
 	// It is used to check if the table schema is changed since last bootstrap message sent
 	version atomic.Uint64
+	// schemaChanged indicates the table schema is changed since last bootstrap message sent
+	// It is used to send bootstrap message on schema change
+	schemaChanged atomic.Bool
 	// tableInfo is the tableInfo of the table
 	// It is used to generate bootstrap message
 	tableInfo atomic.Value
@@ -229,6 +285,7 @@ func newTableStatistic(key model.TopicPartitionKey, row *model.RowChangedEvent)
 	res.lastSendTime.Store(time.Unix(0, 0))
 	res.version.Store(row.TableInfo.UpdateTS)
 	res.tableInfo.Store(row.TableInfo)
+	res.schemaChanged.Store(true)
 	return res
 }
 
@@ -251,6 +308,7 @@ func (t *tableStatistic) update(row *model.RowChangedEvent, totalPartition int32
 		t.tableInfo.Load().(*model.TableInfo).Name != row.TableInfo.Name {
 		t.version.Store(row.TableInfo.UpdateTS)
 		t.tableInfo.Store(row.TableInfo)
+		t.schemaChanged.Store(true)
 	}
 	if t.totalPartition.Load() != totalPartition {
 		t.totalPartition.Store(totalPartition)
@@ -178,6 +178,44 @@ l2:
 	require.Equal(t, key2.TotalPartition, msgCount)
 }
 
+func TestBootstrapWorkerWithDDLTopic(t *testing.T) {
+	t.Parallel()
+
+	builder := &MockRowEventEncoderBuilder{}
+	outCh := make(chan *future, defaultInputChanSize)
+	worker := newBootstrapWorker(
+		model.DefaultChangeFeedID("test"),
+		outCh,
+		builder.Build(),
+		config.DefaultSendBootstrapIntervalInSec,
+		config.DefaultSendBootstrapInMsgCount,
+		true,
+		defaultMaxInactiveDuration)
+	worker.ddlTopic = func(_, table string) string {
+		if table == "t1" {
+			return "ddl_topic"
+		}
+		return ""
+	}
+
+	// the bootstrap message is sent to the DDL topic only once.
+	key1, row1, _ := getMockTableStatus("t1", int64(1), int32(3))
+	events, err := worker.generateEvents(key1.Topic, key1.TotalPartition, row1.TableInfo)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, "ddl_topic", events[0].Key.Topic)
+	require.Equal(t, int32(0), events[0].Key.Partition)
+
+	// the table without the DDL topic is not affected.
+	key2, row2, _ := getMockTableStatus("t2", int64(2), int32(3))
+	events, err = worker.generateEvents(key2.Topic, key2.TotalPartition, row2.TableInfo)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	for _, event := range events {
+		require.Equal(t, key2.Topic, event.Key.Topic)
+	}
+}
+
 func TestUpdateTableStatistic(t *testing.T) {
 	helper := entry.NewSchemaTestHelper(t)
 	defer helper.Close()
@@ -221,3 +259,40 @@ func TestUpdateTableStatistic(t *testing.T) {
 	tableStatistic.update(row3, 1)
 	require.Equal(t, tableInfo3, tableStatistic.tableInfo.Load().(*model.TableInfo))
 }
+
+func TestSchemaVersionBootstrapWorker(t *testing.T) {
+	t.Parallel()
+
+	builder := &MockRowEventEncoderBuilder{}
+	outCh := make(chan *future, defaultInputChanSize)
+	worker := newSchemaVersionBootstrapWorker(
+		model.DefaultChangeFeedID("test"),
+		outCh,
+		builder.Build(),
+		false,
+		defaultMaxInactiveDuration)
+
+	ctx := context.Background()
+	// the bootstrap message is sent once the table is added.
+	key, row, _ := getMockTableStatus("t1", int64(1), int32(3))
+	require.NoError(t, worker.addEvent(ctx, key, row))
+	require.Len(t, outCh, 1)
+	<-outCh
+
+	// no bootstrap message is sent if the schema is not changed.
+	require.NoError(t, worker.addEvent(ctx, key, row))
+	require.Len(t, outCh, 0)
+
+	// the bootstrap message is sent before the row with the new schema version.
+	newTableInfo := *row.TableInfo
+	newTableInfo.TableInfo = &timodel.TableInfo{ID: 1, UpdateTS: 2}
+	newRow := &model.RowChangedEvent{
+		PhysicalTableID: row.PhysicalTableID,
+		TableInfo:       &newTableInfo,
+	}
+	require.NoError(t, worker.addEvent(ctx, key, newRow))
+	require.Len(t, outCh, 1)
+	<-outCh
+	require.NoError(t, worker.addEvent(ctx, key, newRow))
+	require.Len(t, outCh, 0)
+}
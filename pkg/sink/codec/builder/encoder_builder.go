@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/sink/codec/debezium"
 	"github.com/pingcap/tiflow/pkg/sink/codec/maxwell"
 	"github.com/pingcap/tiflow/pkg/sink/codec/open"
+	"github.com/pingcap/tiflow/pkg/sink/codec/parquet"
 	"github.com/pingcap/tiflow/pkg/sink/codec/simple"
 )
 
@@ -49,7 +50,7 @@ func NewRowEventEncoderBuilder(
 	case config.ProtocolCraft:
 		return craft.NewBatchEncoderBuilder(cfg), nil
 	case config.ProtocolDebezium:
-		return debezium.NewBatchEncoderBuilder(cfg, config.GetGlobalServerConfig().ClusterID), nil
+		return debezium.NewBatchEncoderBuilder(ctx, cfg, config.GetGlobalServerConfig().ClusterID)
 	case config.ProtocolSimple:
 		return simple.NewBuilder(ctx, cfg)
 	default:
@@ -66,6 +67,8 @@ func NewTxnEventEncoderBuilder(
 		return csv.NewTxnEventEncoderBuilder(c), nil
 	case config.ProtocolCanalJSON:
 		return canal.NewJSONTxnEventEncoderBuilder(c), nil
+	case config.ProtocolParquet:
+		return parquet.NewTxnEventEncoderBuilder(c), nil
 	default:
 		return nil, cerror.ErrSinkUnknownProtocol.GenWithStackByArgs(c.Protocol)
 	}
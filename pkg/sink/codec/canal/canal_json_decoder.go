@@ -103,7 +103,8 @@ func (b *batchDecoder) HasNext() (model.MessageType, bool, error) {
 		encodedData []byte
 	)
 
-	if b.config.EnableTiDBExtension {
+	// the schema version is encoded in the TiDB extension even if it's disabled.
+	if b.config.EnableTiDBExtension || b.config.EnableSchemaVersion {
 		msg = &canalJSONMessageWithTiDBExtension{
 			JSONMessage: &JSONMessage{},
 			Extensions:  &tidbExtension{},
@@ -222,7 +223,8 @@ func (b *batchDecoder) assembleHandleKeyOnlyRowChangedEvent(
 			EventType: eventType,
 		},
 		Extensions: &tidbExtension{
-			CommitTs: commitTs,
+			CommitTs:      commitTs,
+			SchemaVersion: message.Extensions.SchemaVersion,
 		},
 	}
 	switch eventType {
@@ -298,7 +300,10 @@ func (b *batchDecoder) NextDDLEvent() (*model.DDLEvent, error) {
 			GenWithStack("not found ddl event message")
 	}
 
-	result := canalJSONMessage2DDLEvent(b.msg)
+	result, err := canalJSONMessage2DDLEvent(b.msg)
+	if err != nil {
+		return nil, err
+	}
 	b.msg = nil
 	return result, nil
 }
@@ -37,6 +37,7 @@ type canalJSONMessageInterface interface {
 	getSchema() *string
 	getTable() *string
 	getCommitTs() uint64
+	getSchemaVersion() uint64
 	getQuery() string
 	getOld() map[string]interface{}
 	getData() map[string]interface{}
@@ -84,6 +85,10 @@ func (c *JSONMessage) getCommitTs() uint64 {
 	return 0
 }
 
+func (c *JSONMessage) getSchemaVersion() uint64 {
+	return 0
+}
+
 func (c *JSONMessage) getQuery() string {
 	return c.Query
 }
@@ -139,6 +144,7 @@ type tidbExtension struct {
 	WatermarkTs        uint64 `json:"watermarkTs,omitempty"`
 	OnlyHandleKey      bool   `json:"onlyHandleKey,omitempty"`
 	ClaimCheckLocation string `json:"claimCheckLocation,omitempty"`
+	SchemaVersion      uint64 `json:"schemaVersion,omitempty"`
 }
 
 type canalJSONMessageWithTiDBExtension struct {
@@ -154,6 +160,10 @@ func (c *canalJSONMessageWithTiDBExtension) getCommitTs() uint64 {
 	return c.Extensions.CommitTs
 }
 
+func (c *canalJSONMessageWithTiDBExtension) getSchemaVersion() uint64 {
+	return c.Extensions.SchemaVersion
+}
+
 func canalJSONMessage2RowChange(msg canalJSONMessageInterface) (*model.RowChangedEvent, error) {
 	result := new(model.RowChangedEvent)
 	result.CommitTs = msg.getCommitTs()
@@ -163,6 +173,7 @@ func canalJSONMessage2RowChange(msg canalJSONMessageInterface) (*model.RowChange
 		// for `DELETE` event, `data` contain the old data, set it as the `PreColumns`
 		preCols, err := canalJSONColumnMap2RowChangeColumns(msg.getData(), mysqlType)
 		result.TableInfo = model.BuildTableInfoWithPKNames4Test(*msg.getSchema(), *msg.getTable(), preCols, msg.pkNameSet())
+		result.TableInfo.UpdateTS = msg.getSchemaVersion()
 		result.PreColumns = model.Columns2ColumnDatas(preCols, result.TableInfo)
 		return result, err
 	}
@@ -170,6 +181,7 @@ func canalJSONMessage2RowChange(msg canalJSONMessageInterface) (*model.RowChange
 	// for `INSERT` and `UPDATE`, `data` contain fresh data, set it as the `Columns`
 	cols, err := canalJSONColumnMap2RowChangeColumns(msg.getData(), mysqlType)
 	result.TableInfo = model.BuildTableInfoWithPKNames4Test(*msg.getSchema(), *msg.getTable(), cols, msg.pkNameSet())
+	result.TableInfo.UpdateTS = msg.getSchemaVersion()
 	result.Columns = model.Columns2ColumnDatas(cols, result.TableInfo)
 	if err != nil {
 		return nil, err
@@ -293,16 +305,38 @@ func canalJSONFormatColumn(value interface{}, name string, mysqlTypeStr string)
 	return result
 }
 
-func canalJSONMessage2DDLEvent(msg canalJSONMessageInterface) *model.DDLEvent {
+func canalJSONMessage2DDLEvent(msg canalJSONMessageInterface) (*model.DDLEvent, error) {
 	result := new(model.DDLEvent)
 	// we lost the startTs from kafka message
 	result.CommitTs = msg.getCommitTs()
 
 	result.TableInfo = new(model.TableInfo)
+	// the bootstrap message is a table creation without SQL, which carries
+	// the definition of the table in the same way as the row message.
+	if msg.eventType() == canal.EventType_CREATE && msg.getQuery() == "" {
+		result.IsBootstrap = true
+		mysqlType := msg.getMySQLType()
+		definition := make(map[string]interface{}, len(mysqlType))
+		for name := range mysqlType {
+			definition[name] = nil
+		}
+		cols, err := canalJSONColumnMap2RowChangeColumns(definition, mysqlType)
+		if err != nil {
+			return nil, err
+		}
+		result.TableInfo = model.BuildTableInfoWithPKNames4Test(*msg.getSchema(), *msg.getTable(), cols, msg.pkNameSet())
+	}
 	result.TableInfo.TableName = model.TableName{
 		Schema: *msg.getSchema(),
 		Table:  *msg.getTable(),
 	}
+	// the schema version is kept in the table info as the row message does.
+	if version := msg.getSchemaVersion(); version != 0 {
+		if result.TableInfo.TableInfo == nil {
+			result.TableInfo.TableInfo = &timodel.TableInfo{}
+		}
+		result.TableInfo.UpdateTS = version
+	}
 
 	// we lost DDL type from canal json format, only got the DDL SQL.
 	result.Query = msg.getQuery()
@@ -310,7 +344,7 @@ func canalJSONMessage2DDLEvent(msg canalJSONMessageInterface) *model.DDLEvent {
 	// hack the DDL Type to be compatible with MySQL sink's logic
 	// see https://github.com/pingcap/tiflow/blob/0578db337d/cdc/sink/mysql.go#L362-L370
 	result.Type = getDDLActionType(result.Query)
-	return result
+	return result, nil
 }
 
 // return DDL ActionType by the prefix
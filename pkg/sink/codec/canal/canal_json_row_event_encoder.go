@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"github.com/pingcap/tiflow/pkg/sink/codec/utils"
 	"github.com/pingcap/tiflow/pkg/sink/kafka/claimcheck"
+	canal "github.com/pingcap/tiflow/proto/canal"
 	"go.uber.org/zap"
 )
 
@@ -258,26 +259,37 @@ func newJSONMessageForDML(
 		log.Panic("unreachable event type", zap.Any("event", e))
 	}
 
-	if config.EnableTiDBExtension {
+	if config.EnableTiDBExtension || config.EnableSchemaVersion {
 		const prefix string = ",\"_tidb\":"
 		out.RawString(prefix)
 		out.RawByte('{')
-		out.RawString("\"commitTs\":")
-		out.Uint64(e.CommitTs)
-
-		// only send handle key may happen in 2 cases:
-		// 1. delete event, and set only handle key config. no need to encode `onlyHandleKey` field
-		// 2. event larger than the max message size, and enable large message handle to the `handleKeyOnly`, encode `onlyHandleKey` field
-		if messageTooLarge {
-			if config.LargeMessageHandle.HandleKeyOnly() {
-				out.RawByte(',')
-				out.RawString("\"onlyHandleKey\":true")
+		if config.EnableTiDBExtension {
+			out.RawString("\"commitTs\":")
+			out.Uint64(e.CommitTs)
+
+			// only send handle key may happen in 2 cases:
+			// 1. delete event, and set only handle key config. no need to encode `onlyHandleKey` field
+			// 2. event larger than the max message size, and enable large message handle to the `handleKeyOnly`, encode `onlyHandleKey` field
+			if messageTooLarge {
+				if config.LargeMessageHandle.HandleKeyOnly() {
+					out.RawByte(',')
+					out.RawString("\"onlyHandleKey\":true")
+				}
+				if config.LargeMessageHandle.EnableClaimCheck() {
+					out.RawByte(',')
+					out.RawString("\"claimCheckLocation\":")
+					out.String(claimCheckFileName)
+				}
 			}
-			if config.LargeMessageHandle.EnableClaimCheck() {
+		}
+		// the schema version is encoded even if the TiDB extension is disabled,
+		// so the consumers can tell which table schema the row is encoded with.
+		if config.EnableSchemaVersion {
+			if config.EnableTiDBExtension {
 				out.RawByte(',')
-				out.RawString("\"claimCheckLocation\":")
-				out.String(claimCheckFileName)
 			}
+			out.RawString("\"schemaVersion\":")
+			out.Uint64(e.TableInfo.UpdateTS)
 		}
 		out.RawByte('}')
 	}
@@ -322,7 +334,7 @@ func newJSONRowEventEncoder(
 	}
 }
 
-func (c *JSONRowEventEncoder) newJSONMessageForDDL(e *model.DDLEvent) canalJSONMessageInterface {
+func (c *JSONRowEventEncoder) newJSONMessageForDDL(e *model.DDLEvent) (canalJSONMessageInterface, error) {
 	msg := &JSONMessage{
 		ID:            0, // ignored by both Canal Adapter and Flink
 		Schema:        e.TableInfo.TableName.Schema,
@@ -333,15 +345,53 @@ func (c *JSONRowEventEncoder) newJSONMessageForDDL(e *model.DDLEvent) canalJSONM
 		BuildTime:     time.Now().UnixMilli(), // timestamp
 		Query:         e.Query,
 	}
+	if e.IsBootstrap {
+		if err := c.fillTableDefinition(msg, e.TableInfo); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 
-	if !c.config.EnableTiDBExtension {
-		return msg
+	if !c.config.EnableTiDBExtension && !c.config.EnableSchemaVersion {
+		return msg, nil
 	}
 
+	extension := &tidbExtension{}
+	if c.config.EnableTiDBExtension {
+		extension.CommitTs = e.CommitTs
+	}
+	if c.config.EnableSchemaVersion {
+		extension.SchemaVersion = e.TableInfo.UpdateTS
+	}
 	return &canalJSONMessageWithTiDBExtension{
 		JSONMessage: msg,
-		Extensions:  &tidbExtension{CommitTs: e.CommitTs},
+		Extensions:  extension,
+	}, nil
+}
+
+// fillTableDefinition fills the bootstrap message with the definition of the table,
+// which has no SQL, and the columns are described in the same way as the row message.
+func (c *JSONRowEventEncoder) fillTableDefinition(msg *JSONMessage, tableInfo *model.TableInfo) error {
+	msg.EventType = canal.EventType_CREATE.String()
+	msg.PKNames = make([]string, 0)
+	msg.SQLType = make(map[string]int32, len(tableInfo.Columns))
+	msg.MySQLType = make(map[string]string, len(tableInfo.Columns))
+	for _, columnInfo := range tableInfo.Columns {
+		if !model.IsColCDCVisible(columnInfo) {
+			continue
+		}
+		colFlag := tableInfo.ForceGetColumnFlagType(columnInfo.ID)
+		javaType, err := getJavaSQLType(nil, columnInfo.GetType(), *colFlag)
+		if err != nil {
+			return cerror.WrapError(cerror.ErrCanalEncodeFailed, err)
+		}
+		colName := columnInfo.Name.O
+		if colFlag.IsPrimaryKey() {
+			msg.PKNames = append(msg.PKNames, colName)
+		}
+		msg.SQLType[colName] = int32(javaType)
+		msg.MySQLType[colName] = utils.GetMySQLType(columnInfo, c.config.ContentCompatible)
 	}
+	return nil
 }
 
 func (c *JSONRowEventEncoder) newJSONMessage4CheckpointEvent(
@@ -412,7 +462,8 @@ func (c *JSONRowEventEncoder) AppendRowChangedEvent(
 	m.IncRowsCount()
 
 	originLength := m.Length()
-	if m.Length() > c.config.MaxMessageBytes {
+	// the large message is split into chunks by the producer if chunking is enabled.
+	if m.Length() > c.config.MaxMessageBytes && !c.config.LargeMessageHandle.EnableChunking() {
 		// for single message that is longer than max-message-bytes, do not send it.
 		if c.config.LargeMessageHandle.Disabled() {
 			log.Error("Single message is too large for canal-json",
@@ -452,7 +503,7 @@ func (c *JSONRowEventEncoder) AppendRowChangedEvent(
 		}
 
 		if c.config.LargeMessageHandle.EnableClaimCheck() {
-			claimCheckFileName := claimcheck.NewFileName()
+			claimCheckFileName := c.claimCheck.NewFileName()
 			if err := c.claimCheck.WriteMessage(ctx, m.Key, m.Value, claimCheckFileName); err != nil {
 				return errors.Trace(err)
 			}
@@ -512,7 +563,10 @@ func (c *JSONRowEventEncoder) Build() []*common.Message {
 
 // EncodeDDLEvent encodes DDL events
 func (c *JSONRowEventEncoder) EncodeDDLEvent(e *model.DDLEvent) (*common.Message, error) {
-	message := c.newJSONMessageForDDL(e)
+	message, err := c.newJSONMessageForDDL(e)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	value, err := json.Marshal(message)
 	if err != nil {
 		return nil, cerror.WrapError(cerror.ErrCanalEncodeFailed, err)
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/pingcap/tidb/pkg/parser/mysql"
 	"github.com/pingcap/tiflow/cdc/entry"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/pkg/compression"
@@ -261,6 +262,36 @@ func TestCanalJSONClaimCheckE2E(t *testing.T) {
 	}
 }
 
+func TestNewCanalJSONMessageChunking4LargeMessage(t *testing.T) {
+	codecConfig := common.NewConfig(config.ProtocolCanalJSON)
+	codecConfig.LargeMessageHandle.LargeMessageHandleOption = config.LargeMessageHandleOptionChunking
+	codecConfig.MaxMessageBytes = 500
+
+	builder, err := NewJSONRowEventEncoderBuilder(context.Background(), codecConfig)
+	require.NoError(t, err)
+	encoder := builder.Build()
+
+	// the large message is sent as it is, and split into chunks by the producer.
+	_, insertEvent, _, _ := utils.NewLargeEvent4Test(t, config.GetDefaultReplicaConfig())
+	err = encoder.AppendRowChangedEvent(context.Background(), "", insertEvent, func() {})
+	require.NoError(t, err)
+
+	message := encoder.Build()[0]
+	require.Greater(t, message.Length(), codecConfig.MaxMessageBytes)
+
+	decoder, err := NewBatchDecoder(context.Background(), codecConfig, nil)
+	require.NoError(t, err)
+	err = decoder.AddKeyValue(message.Key, message.Value)
+	require.NoError(t, err)
+	messageType, ok, err := decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, model.MessageTypeRow, messageType)
+	decoded, err := decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.Equal(t, len(insertEvent.Columns), len(decoded.Columns))
+}
+
 func TestNewCanalJSONMessageHandleKeyOnly4LargeMessage(t *testing.T) {
 	codecConfig := common.NewConfig(config.ProtocolCanalJSON)
 	codecConfig.EnableTiDBExtension = true
@@ -322,7 +353,8 @@ func TestNewCanalJSONMessageFromDDL(t *testing.T) {
 	sql := `create table test.person(id int, name varchar(32), tiny tinyint unsigned, comment text, primary key(id))`
 	ddlEvent := helper.DDL2Event(sql)
 
-	message := encoder.newJSONMessageForDDL(ddlEvent)
+	message, err := encoder.newJSONMessageForDDL(ddlEvent)
+	require.NoError(t, err)
 	require.NotNil(t, message)
 
 	msg, ok := message.(*JSONMessage)
@@ -339,7 +371,8 @@ func TestNewCanalJSONMessageFromDDL(t *testing.T) {
 	require.NoError(t, err)
 
 	encoder = builder.Build().(*JSONRowEventEncoder)
-	message = encoder.newJSONMessageForDDL(ddlEvent)
+	message, err = encoder.newJSONMessageForDDL(ddlEvent)
+	require.NoError(t, err)
 	require.NotNil(t, message)
 
 	withExtension, ok := message.(*canalJSONMessageWithTiDBExtension)
@@ -509,6 +542,45 @@ func TestDDLEventWithExtension(t *testing.T) {
 	require.Equal(t, ddlEvent.TableInfo.TableName.Table, decodedDDL.TableInfo.TableName.Table)
 }
 
+func TestCanalJSONSchemaVersion(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	ctx := context.Background()
+	codecConfig := common.NewConfig(config.ProtocolCanalJSON)
+	codecConfig.EnableSchemaVersion = true
+	builder, err := NewJSONRowEventEncoderBuilder(ctx, codecConfig)
+	require.NoError(t, err)
+	encoder := builder.Build()
+
+	ddlEvent := helper.DDL2Event(`create table test.t(a int primary key, b varchar(32))`)
+	dmlEvent := helper.DML2Event(`insert into test.t values (1, "a")`, "test", "t")
+
+	// the schema version is encoded even if the tidb extension is disabled.
+	err = encoder.AppendRowChangedEvent(ctx, "", dmlEvent, nil)
+	require.NoError(t, err)
+	messages := encoder.Build()
+	require.Len(t, messages, 1)
+	var rowMessage canalJSONMessageWithTiDBExtension
+	require.NoError(t, json.Unmarshal(messages[0].Value, &rowMessage))
+	require.Equal(t, dmlEvent.TableInfo.UpdateTS, rowMessage.Extensions.SchemaVersion)
+	require.Zero(t, rowMessage.Extensions.CommitTs)
+
+	// the bootstrap message carries the table definition.
+	message, err := encoder.EncodeDDLEvent(model.NewBootstrapDDLEvent(ddlEvent.TableInfo))
+	require.NoError(t, err)
+	var ddlMessage canalJSONMessageWithTiDBExtension
+	require.NoError(t, json.Unmarshal(message.Value, &ddlMessage))
+	require.True(t, ddlMessage.IsDDL)
+	require.Equal(t, "CREATE", ddlMessage.EventType)
+	require.Empty(t, ddlMessage.Query)
+	require.Equal(t, []string{"a"}, ddlMessage.PKNames)
+	require.Equal(t, "int", ddlMessage.MySQLType["a"])
+	require.Equal(t, "varchar", ddlMessage.MySQLType["b"])
+	require.Len(t, ddlMessage.SQLType, 2)
+	require.Equal(t, ddlEvent.TableInfo.UpdateTS, ddlMessage.Extensions.SchemaVersion)
+}
+
 func TestCanalJSONAppendRowChangedEventWithCallback(t *testing.T) {
 	helper := entry.NewSchemaTestHelper(t)
 	defer helper.Close()
@@ -821,3 +893,66 @@ func TestCanalJSONBatchDecoderWithTerminator(t *testing.T) {
 	}
 	require.Equal(t, 3, cnt)
 }
+
+func TestCanalJSONSchemaVersionRoundTrip(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	ddlEvent := helper.DDL2Event(`create table test.t(a int primary key, b varchar(32))`)
+	dmlEvent := helper.DML2Event(`insert into test.t values (1, "a")`, "test", "t")
+
+	ctx := context.Background()
+	for _, enableTiDBExtension := range []bool{false, true} {
+		codecConfig := common.NewConfig(config.ProtocolCanalJSON)
+		codecConfig.EnableTiDBExtension = enableTiDBExtension
+		codecConfig.EnableSchemaVersion = true
+		builder, err := NewJSONRowEventEncoderBuilder(ctx, codecConfig)
+		require.NoError(t, err)
+		encoder := builder.Build()
+		decoder, err := NewBatchDecoder(ctx, codecConfig, nil)
+		require.NoError(t, err)
+
+		decodeDDL := func(ddl *model.DDLEvent) *model.DDLEvent {
+			message, err := encoder.EncodeDDLEvent(ddl)
+			require.NoError(t, err)
+			require.NoError(t, decoder.AddKeyValue(message.Key, message.Value))
+			tp, hasNext, err := decoder.HasNext()
+			require.NoError(t, err)
+			require.True(t, hasNext)
+			require.Equal(t, model.MessageTypeDDL, tp)
+			decoded, err := decoder.NextDDLEvent()
+			require.NoError(t, err)
+			return decoded
+		}
+
+		decoded := decodeDDL(ddlEvent)
+		require.False(t, decoded.IsBootstrap)
+		require.Equal(t, ddlEvent.Query, decoded.Query)
+		require.Equal(t, ddlEvent.TableInfo.UpdateTS, decoded.TableInfo.UpdateTS)
+
+		// the bootstrap message is decoded with the table definition.
+		decoded = decodeDDL(model.NewBootstrapDDLEvent(ddlEvent.TableInfo))
+		require.True(t, decoded.IsBootstrap)
+		require.Empty(t, decoded.Query)
+		require.Equal(t, "test", decoded.TableInfo.GetSchemaName())
+		require.Equal(t, "t", decoded.TableInfo.GetTableName())
+		require.Equal(t, ddlEvent.TableInfo.UpdateTS, decoded.TableInfo.UpdateTS)
+		require.Len(t, decoded.TableInfo.Columns, 2)
+		colInfo, ok := decoded.TableInfo.GetColumnInfo(decoded.TableInfo.ForceGetColumnIDByName("a"))
+		require.True(t, ok)
+		require.True(t, mysql.HasPriKeyFlag(colInfo.GetFlag()))
+
+		err = encoder.AppendRowChangedEvent(ctx, "", dmlEvent, nil)
+		require.NoError(t, err)
+		messages := encoder.Build()
+		require.Len(t, messages, 1)
+		require.NoError(t, decoder.AddKeyValue(messages[0].Key, messages[0].Value))
+		tp, hasNext, err := decoder.HasNext()
+		require.NoError(t, err)
+		require.True(t, hasNext)
+		require.Equal(t, model.MessageTypeRow, tp)
+		row, err := decoder.NextRowChangedEvent()
+		require.NoError(t, err)
+		require.Equal(t, dmlEvent.TableInfo.UpdateTS, row.TableInfo.UpdateTS)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/errors"
 	"github.com/pingcap/log"
 	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/compression"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/util"
@@ -64,6 +65,10 @@ type Config struct {
 	// canal-json only
 	ContentCompatible bool
 
+	// for canal-json, open-protocol and maxwell, encode the schema version of the table
+	// in the row messages and the bootstrap messages.
+	EnableSchemaVersion bool
+
 	// for sinking to cloud storage
 	Delimiter            string
 	Quote                string
@@ -99,6 +104,8 @@ const (
 	EncodingFormatJSON EncodingFormatType = "json"
 	// EncodingFormatAvro is the avro format
 	EncodingFormatAvro EncodingFormatType = "avro"
+	// EncodingFormatProtobuf is the protobuf format
+	EncodingFormatProtobuf EncodingFormatType = "protobuf"
 )
 
 // NewConfig return a Config for codec
@@ -237,6 +244,9 @@ func (c *Config) Apply(sinkURI *url.URL, replicaConfig *config.ReplicaConfig) er
 		if replicaConfig.Sink.KafkaConfig != nil && replicaConfig.Sink.KafkaConfig.LargeMessageHandle != nil {
 			c.LargeMessageHandle = replicaConfig.Sink.KafkaConfig.LargeMessageHandle
 		}
+		if replicaConfig.Sink.PulsarConfig != nil && replicaConfig.Sink.PulsarConfig.LargeMessageHandle != nil {
+			c.LargeMessageHandle = replicaConfig.Sink.PulsarConfig.LargeMessageHandle
+		}
 		if !c.LargeMessageHandle.Disabled() && replicaConfig.ForceReplicate {
 			return cerror.ErrCodecInvalidConfig.GenWithStack(
 				`force-replicate must be disabled, when the large message handle is enabled, large message handle: "%s"`,
@@ -245,6 +255,9 @@ func (c *Config) Apply(sinkURI *url.URL, replicaConfig *config.ReplicaConfig) er
 		if replicaConfig.Sink.OpenProtocol != nil {
 			c.OpenOutputOldValue = replicaConfig.Sink.OpenProtocol.OutputOldValue
 		}
+		if c.Protocol.SupportSchemaVersion() {
+			c.EnableSchemaVersion = util.GetOrZero(replicaConfig.Sink.EnableSchemaVersion)
+		}
 		if replicaConfig.Sink.Debezium != nil {
 			c.DebeziumOutputOldValue = replicaConfig.Sink.Debezium.OutputOldValue
 		}
@@ -275,7 +288,7 @@ func (c *Config) Apply(sinkURI *url.URL, replicaConfig *config.ReplicaConfig) er
 		if s != "" {
 			encodingFormat := EncodingFormatType(s)
 			switch encodingFormat {
-			case EncodingFormatJSON, EncodingFormatAvro:
+			case EncodingFormatJSON, EncodingFormatAvro, EncodingFormatProtobuf:
 				c.EncodingFormat = encodingFormat
 			default:
 				return cerror.ErrCodecInvalidConfig.GenWithStack(
@@ -339,9 +352,10 @@ func (c *Config) WithChangefeedID(id model.ChangeFeedID) *Config {
 // Validate the Config
 func (c *Config) Validate() error {
 	if c.EnableTiDBExtension &&
-		!(c.Protocol == config.ProtocolCanalJSON || c.Protocol == config.ProtocolAvro) {
+		!(c.Protocol == config.ProtocolCanalJSON || c.Protocol == config.ProtocolAvro ||
+			c.Protocol == config.ProtocolDebezium) {
 		log.Warn("ignore invalid config, enable-tidb-extension"+
-			"only supports canal-json/avro protocol",
+			"only supports canal-json/avro/debezium protocol",
 			zap.Bool("enableTidbExtension", c.EnableTiDBExtension),
 			zap.String("protocol", c.Protocol.String()))
 	}
@@ -396,6 +410,23 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.AvroConfluentSchemaRegistry != "" &&
+		(c.Protocol == config.ProtocolSimple || c.Protocol == config.ProtocolDebezium) {
+		if c.Protocol == config.ProtocolSimple && c.EncodingFormat != EncodingFormatJSON {
+			return cerror.ErrCodecInvalidConfig.GenWithStack(
+				`Simple protocol only supports "%s" with the "%s" encoding format`,
+				codecOPTAvroSchemaRegistry, EncodingFormatJSON)
+		}
+		if c.LargeMessageHandle != nil &&
+			c.LargeMessageHandle.LargeMessageHandleCompression != "" &&
+			c.LargeMessageHandle.LargeMessageHandleCompression != compression.None {
+			return cerror.ErrCodecInvalidConfig.GenWithStack(
+				`"%s" cannot be used together with the large message handle compression, `+
+					`since the message registered as JSON Schema must be plain JSON`,
+				codecOPTAvroSchemaRegistry)
+		}
+	}
+
 	if c.MaxMessageBytes <= 0 {
 		return cerror.ErrCodecInvalidConfig.Wrap(
 			errors.Errorf("invalid max-message-bytes %d", c.MaxMessageBytes),
@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pingcap/tiflow/pkg/compression"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/integrity"
@@ -474,6 +475,26 @@ func TestMergeConfig(t *testing.T) {
 	require.True(t, c.OnlyOutputUpdatedColumns)
 }
 
+func TestApplyConfig4PulsarLargeMessageHandle(t *testing.T) {
+	t.Parallel()
+
+	uri := "pulsar://127.0.0.1:6650/topic?protocol=canal-json&enable-tidb-extension=true"
+	sinkURI, err := url.Parse(uri)
+	require.NoError(t, err)
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.PulsarConfig = &config.PulsarConfig{
+		LargeMessageHandle: &config.LargeMessageHandleConfig{
+			LargeMessageHandleOption: config.LargeMessageHandleOptionClaimCheck,
+			ClaimCheckStorageURI:     "file:///claim-check",
+		},
+	}
+	c := NewConfig(config.ProtocolCanalJSON)
+	err = c.Apply(sinkURI, replicaConfig)
+	require.NoError(t, err)
+	require.True(t, c.LargeMessageHandle.EnableClaimCheck())
+	require.Equal(t, "file:///claim-check", c.LargeMessageHandle.ClaimCheckStorageURI)
+}
+
 func TestApplyConfig4CanalJSON(t *testing.T) {
 	uri := "kafka://127.0.0.1:9092/abc?protocol=canal-json&content-compatible=true"
 	sinkURI, err := url.Parse(uri)
@@ -505,6 +526,15 @@ func TestConfig4Simple(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, EncodingFormatAvro, codecConfig.EncodingFormat)
 
+	uri = "kafka://127.0.0.1:9092/abc?protocol=simple&encoding-format=protobuf"
+	sinkURL, err = url.Parse(uri)
+	require.NoError(t, err)
+
+	codecConfig = NewConfig(config.ProtocolSimple)
+	err = codecConfig.Apply(sinkURL, config.GetDefaultReplicaConfig())
+	require.NoError(t, err)
+	require.Equal(t, EncodingFormatProtobuf, codecConfig.EncodingFormat)
+
 	uri = "kafka://127.0.0.1:9092/abc?protocol=simple&encoding-format=xxx"
 	sinkURL, err = url.Parse(uri)
 	require.NoError(t, err)
@@ -513,3 +543,32 @@ func TestConfig4Simple(t *testing.T) {
 	err = codecConfig.Apply(sinkURL, config.GetDefaultReplicaConfig())
 	require.ErrorIs(t, err, cerror.ErrCodecInvalidConfig)
 }
+
+func TestConfig4JSONSchemaRegistry(t *testing.T) {
+	for _, uri := range []string{
+		"kafka://127.0.0.1:9092/abc?protocol=simple&schema-registry=http://127.0.0.1:8081",
+		"kafka://127.0.0.1:9092/abc?protocol=debezium&schema-registry=http://127.0.0.1:8081",
+	} {
+		sinkURL, err := url.Parse(uri)
+		require.NoError(t, err)
+		protocol, err := config.ParseSinkProtocolFromString(sinkURL.Query().Get("protocol"))
+		require.NoError(t, err)
+
+		codecConfig := NewConfig(protocol)
+		err = codecConfig.Apply(sinkURL, config.GetDefaultReplicaConfig())
+		require.NoError(t, err)
+		require.NoError(t, codecConfig.Validate())
+		require.Equal(t, "http://127.0.0.1:8081", codecConfig.AvroConfluentSchemaRegistry)
+
+		codecConfig.LargeMessageHandle.LargeMessageHandleCompression = compression.LZ4
+		require.ErrorIs(t, codecConfig.Validate(), cerror.ErrCodecInvalidConfig)
+	}
+
+	uri := "kafka://127.0.0.1:9092/abc?protocol=simple&encoding-format=avro&schema-registry=http://127.0.0.1:8081"
+	sinkURL, err := url.Parse(uri)
+	require.NoError(t, err)
+	codecConfig := NewConfig(config.ProtocolSimple)
+	err = codecConfig.Apply(sinkURL, config.GetDefaultReplicaConfig())
+	require.NoError(t, err)
+	require.ErrorIs(t, codecConfig.Validate(), cerror.ErrCodecInvalidConfig)
+}
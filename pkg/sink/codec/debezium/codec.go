@@ -40,6 +40,25 @@ type dbzCodec struct {
 	nowFunc   func() time.Time
 }
 
+// payloadOnly returns true if the schema registry is configured, then the schema is
+// registered as JSON Schema, and the message only carries the payload at the top level,
+// which is the same as the confluent JSON Schema serializer.
+func (c *dbzCodec) payloadOnly() bool {
+	return c.config.AvroConfluentSchemaRegistry != ""
+}
+
+// writeEnvelope writes the payload into the `payload` field of the envelope,
+// or at the top level if the message only carries the payload.
+func (c *dbzCodec) writeEnvelope(jWriter *util.JSONWriter, writePayload func()) {
+	if c.payloadOnly() {
+		jWriter.WriteObject(writePayload)
+		return
+	}
+	jWriter.WriteObject(func() {
+		jWriter.WriteObjectField("payload", writePayload)
+	})
+}
+
 func (c *dbzCodec) writeDebeziumFieldValues(
 	writer *util.JSONWriter,
 	fieldName string,
@@ -516,6 +535,79 @@ func (c *dbzCodec) writeBinaryField(writer *util.JSONWriter, fieldName string, v
 	writer.WriteBase64StringField(fieldName, value)
 }
 
+func (c *dbzCodec) writeSource(writer *util.JSONWriter, commitTs uint64, schema, table string) {
+	commitTime := oracle.GetTimeFromTS(commitTs)
+	writer.WriteObjectField("source", func() {
+		writer.WriteStringField("version", "2.4.0.Final")
+		writer.WriteStringField("connector", "TiCDC")
+		writer.WriteStringField("name", c.clusterID)
+		// ts_ms: In the source object, ts_ms indicates the time that the change was made in the database.
+		// https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-create-events
+		writer.WriteInt64Field("ts_ms", commitTime.UnixMilli())
+		// snapshot field is a string of true,last,false,incremental
+		writer.WriteStringField("snapshot", "false")
+		writer.WriteStringField("db", schema)
+		writer.WriteStringField("table", table)
+		writer.WriteInt64Field("server_id", 0)
+		writer.WriteNullField("gtid")
+		writer.WriteStringField("file", "")
+		writer.WriteInt64Field("pos", 0)
+		writer.WriteInt64Field("row", 0)
+		writer.WriteInt64Field("thread", 0)
+		writer.WriteNullField("query")
+
+		// The followings are TiDB extended fields
+		writer.WriteUint64Field("commit_ts", commitTs)
+		writer.WriteStringField("cluster_id", c.clusterID)
+	})
+}
+
+// writeRowPayload writes the fields of the row changed event payload.
+func (c *dbzCodec) writeRowPayload(jWriter *util.JSONWriter, e *model.RowChangedEvent) error {
+	var err error
+	c.writeSource(jWriter, e.CommitTs, e.TableInfo.GetSchemaName(), e.TableInfo.GetTableName())
+
+	// ts_ms: displays the time at which the connector processed the event
+	// https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-create-events
+	jWriter.WriteInt64Field("ts_ms", c.nowFunc().UnixMilli())
+	jWriter.WriteNullField("transaction")
+
+	if e.IsInsert() {
+		// op: Mandatory string that describes the type of operation that caused the connector to generate the event.
+		// Valid values are:
+		// c = create
+		// u = update
+		// d = delete
+		// r = read (applies to only snapshots)
+		// https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-create-events
+		jWriter.WriteStringField("op", "c")
+
+		// before: An optional field that specifies the state of the row before the event occurred.
+		// When the op field is c for create, the before field is null since this change event is for new content.
+		// In a delete event value, the before field contains the values that were in the row before
+		// it was deleted with the database commit.
+		jWriter.WriteNullField("before")
+
+		// after: An optional field that specifies the state of the row after the event occurred.
+		// Optional field that specifies the state of the row after the event occurred.
+		// In a delete event value, the after field is null, signifying that the row no longer exists.
+		err = c.writeDebeziumFieldValues(jWriter, "after", e.GetColumns(), e.TableInfo)
+	} else if e.IsDelete() {
+		jWriter.WriteStringField("op", "d")
+		jWriter.WriteNullField("after")
+		err = c.writeDebeziumFieldValues(jWriter, "before", e.GetPreColumns(), e.TableInfo)
+	} else if e.IsUpdate() {
+		jWriter.WriteStringField("op", "u")
+		if c.config.DebeziumOutputOldValue {
+			err = c.writeDebeziumFieldValues(jWriter, "before", e.GetPreColumns(), e.TableInfo)
+		}
+		if err == nil {
+			err = c.writeDebeziumFieldValues(jWriter, "after", e.GetColumns(), e.TableInfo)
+		}
+	}
+	return err
+}
+
 func (c *dbzCodec) EncodeRowChangedEvent(
 	e *model.RowChangedEvent,
 	dest io.Writer,
@@ -523,75 +615,18 @@ func (c *dbzCodec) EncodeRowChangedEvent(
 	jWriter := util.BorrowJSONWriter(dest)
 	defer util.ReturnJSONWriter(jWriter)
 
-	commitTime := oracle.GetTimeFromTS(e.CommitTs)
-
 	var err error
 
-	jWriter.WriteObject(func() {
-		jWriter.WriteObjectField("payload", func() {
-			jWriter.WriteObjectField("source", func() {
-				jWriter.WriteStringField("version", "2.4.0.Final")
-				jWriter.WriteStringField("connector", "TiCDC")
-				jWriter.WriteStringField("name", c.clusterID)
-				// ts_ms: In the source object, ts_ms indicates the time that the change was made in the database.
-				// https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-create-events
-				jWriter.WriteInt64Field("ts_ms", commitTime.UnixMilli())
-				// snapshot field is a string of true,last,false,incremental
-				jWriter.WriteStringField("snapshot", "false")
-				jWriter.WriteStringField("db", e.TableInfo.GetSchemaName())
-				jWriter.WriteStringField("table", e.TableInfo.GetTableName())
-				jWriter.WriteInt64Field("server_id", 0)
-				jWriter.WriteNullField("gtid")
-				jWriter.WriteStringField("file", "")
-				jWriter.WriteInt64Field("pos", 0)
-				jWriter.WriteInt64Field("row", 0)
-				jWriter.WriteInt64Field("thread", 0)
-				jWriter.WriteNullField("query")
-
-				// The followings are TiDB extended fields
-				jWriter.WriteUint64Field("commit_ts", e.CommitTs)
-				jWriter.WriteStringField("cluster_id", c.clusterID)
-			})
+	writePayload := func() {
+		err = c.writeRowPayload(jWriter, e)
+	}
+	if c.payloadOnly() {
+		jWriter.WriteObject(writePayload)
+		return err
+	}
 
-			// ts_ms: displays the time at which the connector processed the event
-			// https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-create-events
-			jWriter.WriteInt64Field("ts_ms", c.nowFunc().UnixMilli())
-			jWriter.WriteNullField("transaction")
-
-			if e.IsInsert() {
-				// op: Mandatory string that describes the type of operation that caused the connector to generate the event.
-				// Valid values are:
-				// c = create
-				// u = update
-				// d = delete
-				// r = read (applies to only snapshots)
-				// https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-create-events
-				jWriter.WriteStringField("op", "c")
-
-				// before: An optional field that specifies the state of the row before the event occurred.
-				// When the op field is c for create, the before field is null since this change event is for new content.
-				// In a delete event value, the before field contains the values that were in the row before
-				// it was deleted with the database commit.
-				jWriter.WriteNullField("before")
-
-				// after: An optional field that specifies the state of the row after the event occurred.
-				// Optional field that specifies the state of the row after the event occurred.
-				// In a delete event value, the after field is null, signifying that the row no longer exists.
-				err = c.writeDebeziumFieldValues(jWriter, "after", e.GetColumns(), e.TableInfo)
-			} else if e.IsDelete() {
-				jWriter.WriteStringField("op", "d")
-				jWriter.WriteNullField("after")
-				err = c.writeDebeziumFieldValues(jWriter, "before", e.GetPreColumns(), e.TableInfo)
-			} else if e.IsUpdate() {
-				jWriter.WriteStringField("op", "u")
-				if c.config.DebeziumOutputOldValue {
-					err = c.writeDebeziumFieldValues(jWriter, "before", e.GetPreColumns(), e.TableInfo)
-				}
-				if err == nil {
-					err = c.writeDebeziumFieldValues(jWriter, "after", e.GetColumns(), e.TableInfo)
-				}
-			}
-		})
+	jWriter.WriteObject(func() {
+		jWriter.WriteObjectField("payload", writePayload)
 
 		if !c.config.DebeziumDisableSchema {
 			jWriter.WriteObjectField("schema", func() {
@@ -789,3 +824,106 @@ func (c *dbzCodec) EncodeRowChangedEvent(
 
 	return err
 }
+
+// EncodeKey encodes the handle key columns of the row changed event in the
+// same way as the key of the Debezium MySQL connector. Nothing is written if
+// the table has no handle key.
+func (c *dbzCodec) EncodeKey(
+	e *model.RowChangedEvent,
+	dest io.Writer,
+) error {
+	cols := e.GetColumns()
+	if e.IsDelete() {
+		cols = e.GetPreColumns()
+	}
+	colInfos := e.TableInfo.GetColInfosForRowChangedEvent()
+	var (
+		keyCols []*model.Column
+		keyFts  []*types.FieldType
+	)
+	for i, col := range cols {
+		if col != nil && col.Flag.IsHandleKey() {
+			keyCols = append(keyCols, col)
+			keyFts = append(keyFts, colInfos[i].Ft)
+		}
+	}
+	if len(keyCols) == 0 {
+		return nil
+	}
+
+	jWriter := util.BorrowJSONWriter(dest)
+	defer util.ReturnJSONWriter(jWriter)
+
+	var err error
+	writePayload := func() {
+		for i, col := range keyCols {
+			err = c.writeDebeziumFieldValue(jWriter, col, keyFts[i])
+			if err != nil {
+				break
+			}
+		}
+	}
+	if c.payloadOnly() {
+		jWriter.WriteObject(writePayload)
+		return err
+	}
+	jWriter.WriteObject(func() {
+		jWriter.WriteObjectField("payload", writePayload)
+		if !c.config.DebeziumDisableSchema {
+			jWriter.WriteObjectField("schema", func() {
+				jWriter.WriteStringField("type", "struct")
+				jWriter.WriteStringField("name", fmt.Sprintf("%s.%s.%s.Key",
+					c.clusterID,
+					e.TableInfo.GetSchemaName(),
+					e.TableInfo.GetTableName()))
+				jWriter.WriteBoolField("optional", false)
+				jWriter.WriteArrayField("fields", func() {
+					for i, col := range keyCols {
+						c.writeDebeziumFieldSchema(jWriter, col, keyFts[i])
+					}
+				})
+			})
+		}
+	})
+	return err
+}
+
+// EncodeCheckpointEvent encodes the checkpoint ts as a message event, which
+// is a TiDB extension so that the consumer knows the events before the ts
+// are all received.
+func (c *dbzCodec) EncodeCheckpointEvent(ts uint64, dest io.Writer) error {
+	jWriter := util.BorrowJSONWriter(dest)
+	defer util.ReturnJSONWriter(jWriter)
+
+	c.writeEnvelope(jWriter, func() {
+		c.writeSource(jWriter, ts, "", "")
+		jWriter.WriteInt64Field("ts_ms", c.nowFunc().UnixMilli())
+		jWriter.WriteNullField("transaction")
+		// op m is used by Debezium for the generic logical decoding
+		// message, here it carries the checkpoint ts in the source.
+		jWriter.WriteStringField("op", "m")
+	})
+	return nil
+}
+
+// EncodeDDLEvent encodes the DDL event as a schema change event of the
+// Debezium MySQL connector.
+// https://debezium.io/documentation/reference/stable/connectors/mysql.html#mysql-schema-change-topic
+func (c *dbzCodec) EncodeDDLEvent(e *model.DDLEvent, dest io.Writer) error {
+	jWriter := util.BorrowJSONWriter(dest)
+	defer util.ReturnJSONWriter(jWriter)
+
+	var schema, table string
+	if e.TableInfo != nil {
+		schema, table = e.TableInfo.GetSchemaName(), e.TableInfo.GetTableName()
+	}
+	c.writeEnvelope(jWriter, func() {
+		c.writeSource(jWriter, e.CommitTs, schema, table)
+		jWriter.WriteInt64Field("ts_ms", c.nowFunc().UnixMilli())
+		jWriter.WriteStringField("databaseName", schema)
+		jWriter.WriteNullField("schemaName")
+		jWriter.WriteStringField("ddl", e.Query)
+		jWriter.WriteArrayField("tableChanges", func() {})
+	})
+	return nil
+}
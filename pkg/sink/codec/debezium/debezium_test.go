@@ -193,7 +193,9 @@ func (s *debeziumSuite) TestDataTypes() {
 	cfg := common.NewConfig(config.ProtocolDebezium)
 	cfg.TimeZone = time.UTC
 	cfg.DebeziumDisableSchema = s.disableSchema
-	encoder := NewBatchEncoderBuilder(cfg, "dbserver1").Build()
+	builder, err := NewBatchEncoderBuilder(context.Background(), cfg, "dbserver1")
+	s.Require().Nil(err)
+	encoder := builder.Build()
 	for _, row := range rows {
 		err := encoder.AppendRowChangedEvent(context.Background(), "", row, nil)
 		s.Require().Nil(err)
@@ -0,0 +1,575 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debezium
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/avro"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"go.uber.org/zap"
+)
+
+// field is the schema of a field in the Debezium envelope.
+type field struct {
+	Type       string            `json:"type"`
+	Name       string            `json:"name"`
+	Field      string            `json:"field"`
+	Optional   bool              `json:"optional"`
+	Parameters map[string]string `json:"parameters"`
+	Fields     []*field          `json:"fields"`
+	// unsigned is only recovered from the upstream, since the BIGINT UNSIGNED
+	// is encoded as int64 and there is no such information in the schema.
+	unsigned bool
+}
+
+type source struct {
+	DB    string `json:"db"`
+	Table string `json:"table"`
+	// CommitTs is a TiDB extended field.
+	CommitTs uint64 `json:"commit_ts"`
+}
+
+type payload struct {
+	Source source `json:"source"`
+	Op     string `json:"op"`
+	// Before and After are kept raw to preserve the order of the columns.
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+	// DDL is only set in the schema change events.
+	DDL string `json:"ddl"`
+}
+
+type message struct {
+	Payload payload `json:"payload"`
+	Schema  *field  `json:"schema"`
+}
+
+// Decoder decodes the Debezium messages into events. The column types are
+// recovered from the schema in the envelope, if the schema is disabled or
+// registered in the schema registry, they are recovered from the upstream
+// TiDB at the commit ts of the row, since the temporal and decimal values
+// can't be told from the JSON values.
+type Decoder struct {
+	config *common.Config
+
+	// upstreamTiDB is used to recover the schema of the messages without
+	// schema, the messages can't be decoded without it.
+	upstreamTiDB *sql.DB
+	// upstreamFields caches the schema recovered from the upstream, it's
+	// reset by the DDL events.
+	upstreamFields map[model.TableName][]*field
+
+	value []byte
+	// payloadOnly is true if the value carries the payload only,
+	// whose schema is registered in the schema registry.
+	payloadOnly bool
+	// keyNames are the names of the columns in the message key, which are
+	// treated as the primary key.
+	keyNames map[string]struct{}
+	msg      *message
+}
+
+// NewDecoder creates a new Debezium Decoder.
+func NewDecoder(config *common.Config, db *sql.DB) codec.RowEventDecoder {
+	return &Decoder{
+		config:         config,
+		upstreamTiDB:   db,
+		upstreamFields: make(map[model.TableName][]*field),
+	}
+}
+
+// AddKeyValue implements the RowEventDecoder interface
+func (d *Decoder) AddKeyValue(key, value []byte) error {
+	value, err := common.Decompress(d.config.LargeMessageHandle.LargeMessageHandleCompression, value)
+	if err != nil {
+		log.Error("decompress data failed",
+			zap.String("compression", d.config.LargeMessageHandle.LargeMessageHandleCompression),
+			zap.Error(err))
+		return errors.Trace(err)
+	}
+	// the message only carries the payload if it's prefixed with
+	// the wire format header of the registered JSON Schema.
+	schemaID, value, err := avro.SplitConfluentJSONMessage(value)
+	if err != nil {
+		return cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	d.value = value
+	d.payloadOnly = schemaID != 0
+
+	d.keyNames = nil
+	schemaID, key, err = avro.SplitConfluentJSONMessage(key)
+	if err != nil {
+		return cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	// the key is absent if the table has no handle key.
+	if len(key) == 0 || key[0] != '{' {
+		return nil
+	}
+	var keyMsg struct {
+		Payload map[string]json.RawMessage `json:"payload"`
+	}
+	if schemaID != 0 {
+		err = json.Unmarshal(key, &keyMsg.Payload)
+	} else {
+		err = json.Unmarshal(key, &keyMsg)
+	}
+	if err != nil {
+		return cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	d.keyNames = make(map[string]struct{}, len(keyMsg.Payload))
+	for name := range keyMsg.Payload {
+		d.keyNames[name] = struct{}{}
+	}
+	return nil
+}
+
+// HasNext implements the RowEventDecoder interface
+func (d *Decoder) HasNext() (model.MessageType, bool, error) {
+	if len(d.value) == 0 {
+		return model.MessageTypeUnknown, false, nil
+	}
+	msg := new(message)
+	var err error
+	if d.payloadOnly {
+		err = json.Unmarshal(d.value, &msg.Payload)
+	} else {
+		err = json.Unmarshal(d.value, msg)
+	}
+	d.value = nil
+	if err != nil {
+		return model.MessageTypeUnknown, false, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	d.msg = msg
+
+	switch {
+	case msg.Payload.DDL != "":
+		return model.MessageTypeDDL, true, nil
+	case msg.Payload.Op == "m":
+		return model.MessageTypeResolved, true, nil
+	case msg.Payload.Op == "c", msg.Payload.Op == "u",
+		msg.Payload.Op == "d", msg.Payload.Op == "r":
+		return model.MessageTypeRow, true, nil
+	}
+	return model.MessageTypeUnknown, false, cerror.ErrDebeziumDecodeFailed.
+		GenWithStack("unknown operation %s", msg.Payload.Op)
+}
+
+// NextResolvedEvent implements the RowEventDecoder interface
+func (d *Decoder) NextResolvedEvent() (uint64, error) {
+	if d.msg == nil || d.msg.Payload.Op != "m" {
+		return 0, cerror.ErrDebeziumDecodeFailed.
+			GenWithStack("not found resolved event message")
+	}
+	ts := d.msg.Payload.Source.CommitTs
+	d.msg = nil
+	return ts, nil
+}
+
+// NextRowChangedEvent implements the RowEventDecoder interface
+func (d *Decoder) NextRowChangedEvent() (*model.RowChangedEvent, error) {
+	if d.msg == nil || d.msg.Payload.DDL != "" || d.msg.Payload.Op == "m" {
+		return nil, cerror.ErrDebeziumDecodeFailed.
+			GenWithStack("not found row changed event message")
+	}
+	msg := d.msg
+	d.msg = nil
+
+	schema, table := msg.Payload.Source.DB, msg.Payload.Source.Table
+	var beforeFields, afterFields []*field
+	if msg.Schema != nil {
+		for _, f := range msg.Schema.Fields {
+			switch f.Field {
+			case "before":
+				beforeFields = f.Fields
+			case "after":
+				afterFields = f.Fields
+			}
+		}
+	} else {
+		if d.upstreamTiDB == nil {
+			return nil, cerror.ErrDebeziumDecodeFailed.GenWithStack(
+				"the message of %s.%s has no schema, the upstream TiDB is required "+
+					"to recover the column types", schema, table)
+		}
+		fields, err := d.queryUpstreamFields(schema, table, msg.Payload.Source.CommitTs)
+		if err != nil {
+			return nil, err
+		}
+		beforeFields, afterFields = fields, fields
+	}
+	before, err := d.decodeColumns(msg.Payload.Before, beforeFields)
+	if err != nil {
+		return nil, err
+	}
+	after, err := d.decodeColumns(msg.Payload.After, afterFields)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &model.RowChangedEvent{
+		CommitTs: msg.Payload.Source.CommitTs,
+	}
+	switch msg.Payload.Op {
+	case "d":
+		event.TableInfo = model.BuildTableInfoWithPKNames4Test(schema, table, before, d.pkNames(before))
+		event.PreColumns = model.Columns2ColumnDatas(before, event.TableInfo)
+	case "u":
+		event.TableInfo = model.BuildTableInfoWithPKNames4Test(schema, table, after, d.pkNames(after))
+		event.Columns = model.Columns2ColumnDatas(after, event.TableInfo)
+		// the before value is absent if the old value is not required,
+		// the after value is used instead.
+		if before == nil {
+			before = after
+		}
+		event.PreColumns = model.Columns2ColumnDatas(before, event.TableInfo)
+	default:
+		event.TableInfo = model.BuildTableInfoWithPKNames4Test(schema, table, after, d.pkNames(after))
+		event.Columns = model.Columns2ColumnDatas(after, event.TableInfo)
+	}
+	return event, nil
+}
+
+// NextDDLEvent implements the RowEventDecoder interface
+func (d *Decoder) NextDDLEvent() (*model.DDLEvent, error) {
+	if d.msg == nil || d.msg.Payload.DDL == "" {
+		return nil, cerror.ErrDebeziumDecodeFailed.
+			GenWithStack("not found ddl event message")
+	}
+	msg := d.msg
+	d.msg = nil
+	// the schema of the tables may be changed by the DDL.
+	d.upstreamFields = make(map[model.TableName][]*field)
+
+	return &model.DDLEvent{
+		CommitTs: msg.Payload.Source.CommitTs,
+		Query:    msg.Payload.DDL,
+		// the DDL type is lost, hack it to be compatible with the MySQL sink.
+		Type: getDDLActionType(msg.Payload.DDL),
+		TableInfo: &model.TableInfo{
+			TableName: model.TableName{
+				Schema: msg.Payload.Source.DB,
+				Table:  msg.Payload.Source.Table,
+			},
+		},
+	}, nil
+}
+
+// pkNames returns the names of the columns in the key, if there is no key,
+// all the columns are used to identify the row.
+func (d *Decoder) pkNames(cols []*model.Column) map[string]struct{} {
+	if len(d.keyNames) != 0 {
+		return d.keyNames
+	}
+	names := make(map[string]struct{}, len(cols))
+	for _, col := range cols {
+		names[col.Name] = struct{}{}
+	}
+	return names
+}
+
+// decodeColumns decodes the columns in the order they appear in the payload.
+func (d *Decoder) decodeColumns(raw json.RawMessage, fields []*field) ([]*model.Column, error) {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+	fieldsByName := make(map[string]*field, len(fields))
+	for _, f := range fields {
+		fieldsByName[f.Field] = f
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if _, err := decoder.Token(); err != nil {
+		return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	var cols []*model.Column
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+		}
+		name, ok := token.(string)
+		if !ok {
+			return nil, cerror.ErrDebeziumDecodeFailed.
+				GenWithStack("unexpected token %v", token)
+		}
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+		}
+		col, err := d.decodeColumn(name, value, fieldsByName[name])
+		if err != nil {
+			return nil, err
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// decodeColumn is the reverse of writeDebeziumFieldValue.
+func (d *Decoder) decodeColumn(name string, value interface{}, f *field) (*model.Column, error) {
+	col := &model.Column{Name: name}
+	if f == nil {
+		return nil, cerror.ErrDebeziumDecodeFailed.
+			GenWithStack("column %s not found in the schema", name)
+	}
+
+	var err error
+	switch f.Name {
+	case "io.debezium.data.Bits":
+		col.Type = mysql.TypeBit
+		col.Value, err = decodeBits(value)
+	case "io.debezium.data.Enum":
+		col.Type = mysql.TypeEnum
+		col.Value, err = decodeEnum(value, f.Parameters["allowed"])
+	case "io.debezium.data.EnumSet":
+		col.Type = mysql.TypeSet
+		col.Value, err = decodeSet(value, f.Parameters["allowed"])
+	case "io.debezium.time.Date":
+		col.Type = mysql.TypeDate
+		col.Value, err = decodeTime(value, func(v int64) string {
+			return time.Unix(0, 0).UTC().AddDate(0, 0, int(v)).Format("2006-01-02")
+		})
+	case "io.debezium.time.Timestamp":
+		col.Type = mysql.TypeDatetime
+		col.Value, err = decodeTime(value, func(v int64) string {
+			return time.UnixMilli(v).UTC().Format("2006-01-02 15:04:05.999")
+		})
+	case "io.debezium.time.MicroTimestamp":
+		col.Type = mysql.TypeDatetime
+		col.Value, err = decodeTime(value, func(v int64) string {
+			return time.UnixMicro(v).UTC().Format("2006-01-02 15:04:05.999999")
+		})
+	case "io.debezium.time.ZonedTimestamp":
+		col.Type = mysql.TypeTimestamp
+		col.Value, err = d.decodeZonedTimestamp(value)
+	case "io.debezium.time.MicroTime":
+		col.Type = mysql.TypeDuration
+		col.Value, err = decodeTime(value, func(v int64) string {
+			return types.Duration{
+				Duration: time.Duration(v) * time.Microsecond,
+				Fsp:      types.MaxFsp,
+			}.String()
+		})
+	case "io.debezium.data.Json":
+		col.Type = mysql.TypeJSON
+		col.Value, err = decodeString(value)
+	case "io.debezium.time.Year":
+		col.Type = mysql.TypeYear
+		col.Value, err = decodeInt64(value)
+	default:
+		switch f.Type {
+		case "boolean":
+			col.Type = mysql.TypeBit
+			col.Value, err = decodeBool(value)
+		case "int16":
+			col.Type = mysql.TypeShort
+			col.Value, err = decodeInt64(value)
+		case "int32":
+			col.Type = mysql.TypeLong
+			col.Value, err = decodeInt64(value)
+		case "int64":
+			col.Type = mysql.TypeLonglong
+			col.Value, err = decodeInt64(value)
+			if f.unsigned {
+				col.Flag.SetIsUnsigned()
+				if v, ok := col.Value.(int64); ok {
+					col.Value = uint64(v)
+				}
+			}
+		case "float", "double":
+			// the decimal is also encoded as double, so it's decoded as double.
+			col.Type = mysql.TypeDouble
+			if f.Type == "float" {
+				col.Type = mysql.TypeFloat
+			}
+			col.Value, err = decodeFloat64(value)
+		case upstreamDecimalType:
+			col.Type = mysql.TypeNewDecimal
+			col.Value, err = decodeDecimal(value)
+		case "bytes":
+			col.Type = mysql.TypeBlob
+			col.Flag.SetIsBinary()
+			col.Value, err = decodeBytes(value)
+		case "string":
+			col.Type = mysql.TypeVarchar
+			var s interface{}
+			s, err = decodeString(value)
+			if s != nil {
+				col.Value = []byte(s.(string))
+			}
+		default:
+			return nil, cerror.ErrDebeziumDecodeFailed.
+				GenWithStack("unsupported field type %s for column %s", f.Type, name)
+		}
+	}
+	if err != nil {
+		return nil, cerror.ErrDebeziumDecodeFailed.
+			GenWithStack("decode column %s failed: %s", name, err.Error())
+	}
+	return col, nil
+}
+
+func decodeInt64(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v, ok := value.(json.Number)
+	if !ok {
+		return nil, errors.Errorf("unexpected value %v", value)
+	}
+	return v.Int64()
+}
+
+func decodeFloat64(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v, ok := value.(json.Number)
+	if !ok {
+		return nil, errors.Errorf("unexpected value %v", value)
+	}
+	return v.Float64()
+}
+
+// decodeDecimal keeps the JSON number literal, which is parsed by the downstream.
+func decodeDecimal(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v, ok := value.(json.Number)
+	if !ok {
+		return nil, errors.Errorf("unexpected value %v", value)
+	}
+	return v.String(), nil
+}
+
+func decodeString(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v, ok := value.(string)
+	if !ok {
+		return nil, errors.Errorf("unexpected value %v", value)
+	}
+	return v, nil
+}
+
+func decodeBytes(value interface{}) (interface{}, error) {
+	s, err := decodeString(value)
+	if s == nil || err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(s.(string))
+}
+
+func decodeBool(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	v, ok := value.(bool)
+	if !ok {
+		return nil, errors.Errorf("unexpected value %v", value)
+	}
+	if v {
+		return uint64(1), nil
+	}
+	return uint64(0), nil
+}
+
+// decodeBits decodes the bits in little-endian form.
+func decodeBits(value interface{}) (interface{}, error) {
+	b, err := decodeBytes(value)
+	if b == nil || err != nil {
+		return nil, err
+	}
+	var buf [8]byte
+	copy(buf[:], b.([]byte))
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+func decodeEnum(value interface{}, allowed string) (interface{}, error) {
+	s, err := decodeString(value)
+	if s == nil || err != nil {
+		return nil, err
+	}
+	// the invalid enum value is encoded as an empty string.
+	if s.(string) == "" {
+		return uint64(0), nil
+	}
+	enum, err := types.ParseEnumName(strings.Split(allowed, ","), s.(string), mysql.DefaultCollationName)
+	if err != nil {
+		return nil, err
+	}
+	return enum.Value, nil
+}
+
+func decodeSet(value interface{}, allowed string) (interface{}, error) {
+	s, err := decodeString(value)
+	if s == nil || err != nil {
+		return nil, err
+	}
+	set, err := types.ParseSetName(strings.Split(allowed, ","), s.(string), mysql.DefaultCollationName)
+	if err != nil {
+		return nil, err
+	}
+	return set.Value, nil
+}
+
+func decodeTime(value interface{}, format func(int64) string) (interface{}, error) {
+	v, err := decodeInt64(value)
+	if v == nil || err != nil {
+		return nil, err
+	}
+	return format(v.(int64)), nil
+}
+
+func (d *Decoder) decodeZonedTimestamp(value interface{}) (interface{}, error) {
+	s, err := decodeString(value)
+	if s == nil || err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, s.(string))
+	if err != nil {
+		return nil, err
+	}
+	return t.In(d.config.TimeZone).Format("2006-01-02 15:04:05.999999"), nil
+}
+
+// getDDLActionType returns the DDL ActionType by the prefix of the query.
+func getDDLActionType(query string) timodel.ActionType {
+	query = strings.ToLower(query)
+	if strings.HasPrefix(query, "create schema") || strings.HasPrefix(query, "create database") {
+		return timodel.ActionCreateSchema
+	}
+	if strings.HasPrefix(query, "drop schema") || strings.HasPrefix(query, "drop database") {
+		return timodel.ActionDropSchema
+	}
+	return timodel.ActionNone
+}
@@ -0,0 +1,363 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debezium
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/stretchr/testify/require"
+)
+
+func newRowEvents(t *testing.T) []*model.RowChangedEvent {
+	helper := NewSQLTestHelper(t, "foo", `create table foo(
+		id int primary key, a tinyint, b bigint unsigned, c bit(10), d bit(1),
+		e enum('x', 'y'), f set('x', 'y'), g double, h date, i datetime(3),
+		j datetime(6), k timestamp(6) null, l time(6), m json, n year,
+		o varchar(16), p float, q varbinary(8), r decimal(10, 2))`)
+	t.Cleanup(helper.Close)
+
+	helper.MustExec(`set sql_mode = ''`)
+	helper.MustExec(`set time_zone = 'UTC'`)
+	helper.MustExec(`insert into foo values (1, -1, 18, b'101', b'1', 'y', 'x,y',
+		1.5, '2024-02-29', '2024-01-02 03:04:05.678', '2024-01-02 03:04:05.123456',
+		'2024-01-02 03:04:05.123456', '12:34:56', '{"k": 1}', 2024, 'hello', 2.5, 'world', 1.25)`)
+	rows := helper.ScanTable()
+	require.Len(t, rows, 1)
+	return rows
+}
+
+// upstreamColumns are the columns of the table created by newRowEvents
+// in the information schema of the upstream.
+var upstreamColumns = [][]driver.Value{
+	{"id", "int", "int(11)", 0},
+	{"a", "tinyint", "tinyint(4)", 0},
+	{"b", "bigint", "bigint(20) unsigned", 0},
+	{"c", "bit", "bit(10)", 0},
+	{"d", "bit", "bit(1)", 0},
+	{"e", "enum", "enum('x','y')", 0},
+	{"f", "set", "set('x','y')", 0},
+	{"g", "double", "double", 0},
+	{"h", "date", "date", 0},
+	{"i", "datetime", "datetime(3)", 3},
+	{"j", "datetime", "datetime(6)", 6},
+	{"k", "timestamp", "timestamp(6)", 6},
+	{"l", "time", "time(6)", 6},
+	{"m", "json", "json", 0},
+	{"n", "year", "year(4)", 0},
+	{"o", "varchar", "varchar(16)", 0},
+	{"p", "float", "float", 0},
+	{"q", "varbinary", "varbinary(8)", 0},
+	{"r", "decimal", "decimal(10,2)", 0},
+}
+
+// expectUpstreamQuery expects the columns of the table are queried at the commit ts.
+func expectUpstreamQuery(
+	mock sqlmock.Sqlmock, commitTs uint64, schema, table string, columns [][]driver.Value,
+) {
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf("set @@tidb_snapshot=%d", commitTs))).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE", "COLUMN_TYPE", "DATETIME_PRECISION"})
+	for _, col := range columns {
+		rows.AddRow(col...)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(queryColumnsSQL)).WithArgs(schema, table).WillReturnRows(rows)
+	mock.ExpectExec(regexp.QuoteMeta("set @@tidb_snapshot=''")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func encodeAndDecode(
+	t *testing.T, cfg *common.Config, db *sql.DB, e *model.RowChangedEvent,
+) *model.RowChangedEvent {
+	builder, err := NewBatchEncoderBuilder(context.Background(), cfg, "dbserver1")
+	require.NoError(t, err)
+	encoder := builder.Build()
+	err = encoder.AppendRowChangedEvent(context.Background(), "", e, nil)
+	require.NoError(t, err)
+	messages := encoder.Build()
+	require.Len(t, messages, 1)
+
+	decoder := NewDecoder(cfg, db)
+	require.NoError(t, decoder.AddKeyValue(messages[0].Key, messages[0].Value))
+	tp, hasNext, err := decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeRow, tp)
+	decoded, err := decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	_, hasNext, err = decoder.HasNext()
+	require.NoError(t, err)
+	require.False(t, hasNext)
+
+	require.Equal(t, e.CommitTs, decoded.CommitTs)
+	require.Equal(t, "test", decoded.TableInfo.GetSchemaName())
+	require.Equal(t, "foo", decoded.TableInfo.GetTableName())
+	return decoded
+}
+
+func columnValues(cols []*model.Column) map[string]interface{} {
+	values := make(map[string]interface{}, len(cols))
+	for _, col := range cols {
+		values[col.Name] = col.Value
+	}
+	return values
+}
+
+func TestDecodeWithSchema(t *testing.T) {
+	row := newRowEvents(t)[0]
+	cfg := common.NewConfig(config.ProtocolDebezium)
+	cfg.TimeZone = time.UTC
+
+	expected := map[string]interface{}{
+		"id": int64(1),
+		"a":  int64(-1),
+		"b":  int64(18),
+		"c":  uint64(5),
+		"d":  uint64(1),
+		"e":  uint64(2),
+		"f":  uint64(3),
+		"g":  float64(1.5),
+		"h":  "2024-02-29",
+		"i":  "2024-01-02 03:04:05.678",
+		"j":  "2024-01-02 03:04:05.123456",
+		"k":  "2024-01-02 03:04:05.123456",
+		"l":  "12:34:56.000000",
+		"m":  `{"k": 1}`,
+		"n":  int64(2024),
+		"o":  []byte("hello"),
+		"p":  float64(2.5),
+		// the binary string is encoded as the base64 string without the
+		// binary flag in the schema.
+		"q": []byte("d29ybGQ="),
+		// the decimal is encoded as double.
+		"r": float64(1.25),
+	}
+
+	decoded := encodeAndDecode(t, cfg, nil, row)
+	require.True(t, decoded.IsInsert())
+	cols := decoded.GetColumns()
+	require.Equal(t, expected, columnValues(cols))
+	for _, col := range cols {
+		// the primary key is recovered from the message key.
+		require.Equal(t, col.Name == "id", col.Flag.IsHandleKey(), col.Name)
+	}
+
+	update := *row
+	update.PreColumns = row.Columns
+	decoded = encodeAndDecode(t, cfg, nil, &update)
+	require.True(t, decoded.IsUpdate())
+	require.Equal(t, expected, columnValues(decoded.GetPreColumns()))
+	require.Equal(t, expected, columnValues(decoded.GetColumns()))
+
+	// the after value is used as the before value if the old value is absent.
+	cfg.DebeziumOutputOldValue = false
+	decoded = encodeAndDecode(t, cfg, nil, &update)
+	require.True(t, decoded.IsUpdate())
+	require.Equal(t, expected, columnValues(decoded.GetPreColumns()))
+
+	del := *row
+	del.PreColumns = row.Columns
+	del.Columns = nil
+	decoded = encodeAndDecode(t, cfg, nil, &del)
+	require.True(t, decoded.IsDelete())
+	require.Equal(t, expected, columnValues(decoded.GetPreColumns()))
+}
+
+func TestDecodeWithoutSchema(t *testing.T) {
+	row := newRowEvents(t)[0]
+	cfg := common.NewConfig(config.ProtocolDebezium)
+	cfg.TimeZone = time.UTC
+	cfg.DebeziumDisableSchema = true
+
+	builder, err := NewBatchEncoderBuilder(context.Background(), cfg, "dbserver1")
+	require.NoError(t, err)
+	encoder := builder.Build()
+	require.NoError(t, encoder.AppendRowChangedEvent(context.Background(), "", row, nil))
+	messages := encoder.Build()
+	require.Len(t, messages, 1)
+
+	// the column types can't be recovered without the upstream.
+	decoder := NewDecoder(cfg, nil)
+	require.NoError(t, decoder.AddKeyValue(messages[0].Key, messages[0].Value))
+	_, hasNext, err := decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	_, err = decoder.NextRowChangedEvent()
+	require.ErrorIs(t, err, cerror.ErrDebeziumDecodeFailed)
+
+	// the column types are recovered from the upstream.
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	expectUpstreamQuery(mock, row.CommitTs, "test", "foo", upstreamColumns)
+	expected := map[string]interface{}{
+		"id": int64(1),
+		"a":  int64(-1),
+		"b":  uint64(18),
+		"c":  uint64(5),
+		"d":  uint64(1),
+		"e":  uint64(2),
+		"f":  uint64(3),
+		"g":  float64(1.5),
+		"h":  "2024-02-29",
+		"i":  "2024-01-02 03:04:05.678",
+		"j":  "2024-01-02 03:04:05.123456",
+		"k":  "2024-01-02 03:04:05.123456",
+		"l":  "12:34:56.000000",
+		"m":  `{"k": 1}`,
+		"n":  int64(2024),
+		"o":  []byte("hello"),
+		"p":  float64(2.5),
+		"q":  []byte("world"),
+		"r":  "1.25",
+	}
+	decoded := encodeAndDecode(t, cfg, db, row)
+	require.True(t, decoded.IsInsert())
+	require.Equal(t, expected, columnValues(decoded.GetColumns()))
+	for _, col := range decoded.GetColumns() {
+		require.Equal(t, col.Name == "id", col.Flag.IsHandleKey(), col.Name)
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// all the columns are treated as the key if the key is absent.
+	expectUpstreamQuery(mock, 10, "test", "t", [][]driver.Value{
+		{"a", "int", "int(11)", 0},
+		{"b", "varchar", "varchar(16)", 0},
+	})
+	decoder = NewDecoder(cfg, db)
+	deleteMsg := []byte(`{"payload":{"op":"d","before":{"a":1,"b":"x"},` +
+		`"source":{"db":"test","table":"t","commit_ts":10}}}`)
+	require.NoError(t, decoder.AddKeyValue(nil, deleteMsg))
+	_, hasNext, err = decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	decoded, err = decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.True(t, decoded.IsDelete())
+	for _, col := range decoded.GetPreColumns() {
+		require.True(t, col.Flag.IsHandleKey())
+	}
+
+	// the recovered schema is cached until a DDL event.
+	require.NoError(t, decoder.AddKeyValue(nil, deleteMsg))
+	_, _, err = decoder.HasNext()
+	require.NoError(t, err)
+	_, err = decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	require.NoError(t, decoder.AddKeyValue(nil,
+		[]byte(`{"payload":{"ddl":"alter table t add column c int","source":{"db":"test","table":"t","commit_ts":11}}}`)))
+	_, _, err = decoder.HasNext()
+	require.NoError(t, err)
+	_, err = decoder.NextDDLEvent()
+	require.NoError(t, err)
+	expectUpstreamQuery(mock, 10, "test", "t", [][]driver.Value{
+		{"a", "int", "int(11)", 0},
+		{"b", "varchar", "varchar(16)", 0},
+	})
+	require.NoError(t, decoder.AddKeyValue(nil, deleteMsg))
+	_, _, err = decoder.HasNext()
+	require.NoError(t, err)
+	_, err = decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	// the bigint unsigned value above MaxInt64 is encoded as a negative int64.
+	expectUpstreamQuery(mock, 12, "test", "u", [][]driver.Value{
+		{"a", "bigint", "bigint(20) unsigned", 0},
+		{"b", "bigint", "bigint(20)", 0},
+	})
+	require.NoError(t, decoder.AddKeyValue(nil,
+		[]byte(`{"payload":{"op":"c","after":{"a":-1,"b":-1},`+
+			`"source":{"db":"test","table":"u","commit_ts":12}}}`)))
+	_, _, err = decoder.HasNext()
+	require.NoError(t, err)
+	decoded, err = decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"a": uint64(math.MaxUint64),
+		"b": int64(-1),
+	}, columnValues(decoded.GetColumns()))
+	require.True(t, decoded.GetColumns()[0].Flag.IsUnsigned())
+	require.False(t, decoded.GetColumns()[1].Flag.IsUnsigned())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDecodeCheckpointAndDDLEvent(t *testing.T) {
+	t.Parallel()
+
+	cfg := common.NewConfig(config.ProtocolDebezium)
+	builder, err := NewBatchEncoderBuilder(context.Background(), cfg, "dbserver1")
+	require.NoError(t, err)
+	encoder := builder.Build()
+	ddl := &model.DDLEvent{
+		CommitTs: 100,
+		Query:    "create database test",
+		TableInfo: &model.TableInfo{
+			TableName: model.TableName{Schema: "test"},
+		},
+	}
+
+	// the events are only emitted if the TiDB extension is enabled.
+	msg, err := encoder.EncodeCheckpointEvent(100)
+	require.NoError(t, err)
+	require.Nil(t, msg)
+	msg, err = encoder.EncodeDDLEvent(ddl)
+	require.NoError(t, err)
+	require.Nil(t, msg)
+
+	cfg.EnableTiDBExtension = true
+	builder, err = NewBatchEncoderBuilder(context.Background(), cfg, "dbserver1")
+	require.NoError(t, err)
+	encoder = builder.Build()
+	decoder := NewDecoder(cfg, nil)
+
+	msg, err = encoder.EncodeCheckpointEvent(100)
+	require.NoError(t, err)
+	require.NoError(t, decoder.AddKeyValue(msg.Key, msg.Value))
+	tp, hasNext, err := decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeResolved, tp)
+	ts, err := decoder.NextResolvedEvent()
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), ts)
+
+	msg, err = encoder.EncodeDDLEvent(ddl)
+	require.NoError(t, err)
+	require.NoError(t, decoder.AddKeyValue(msg.Key, msg.Value))
+	tp, hasNext, err = decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeDDL, tp)
+	decodedDDL, err := decoder.NextDDLEvent()
+	require.NoError(t, err)
+	require.Equal(t, ddl.CommitTs, decodedDDL.CommitTs)
+	require.Equal(t, ddl.Query, decodedDDL.Query)
+	require.Equal(t, timodel.ActionCreateSchema, decodedDDL.Type)
+	require.Equal(t, "test", decodedDDL.TableInfo.GetSchemaName())
+}
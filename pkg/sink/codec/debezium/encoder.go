@@ -22,6 +22,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/avro"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 )
 
@@ -31,18 +32,48 @@ type BatchEncoder struct {
 
 	config *common.Config
 	codec  *dbzCodec
+
+	// schemaM is set if the schema registry is configured, each message
+	// is prefixed with the wire format header of its JSON Schema.
+	schemaM       *avro.JSONSchemaManager
+	controlHeader []byte
+}
+
+func (d *BatchEncoder) withControlSchemaHeader(value []byte) []byte {
+	if d.schemaM == nil {
+		return value
+	}
+	return append(d.controlHeader, value...)
 }
 
 // EncodeCheckpointEvent implements the RowEventEncoder interface
 func (d *BatchEncoder) EncodeCheckpointEvent(ts uint64) (*common.Message, error) {
-	// Currently ignored. Debezium MySQL Connector does not emit such event.
-	return nil, nil
+	// Debezium MySQL Connector does not emit such event, so it's only
+	// emitted if the TiDB extension is enabled.
+	if !d.config.EnableTiDBExtension {
+		return nil, nil
+	}
+	valueBuf := bytes.Buffer{}
+	err := d.codec.EncodeCheckpointEvent(ts, &valueBuf)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	value, err := common.Compress(
+		d.config.ChangefeedID,
+		d.config.LargeMessageHandle.LargeMessageHandleCompression,
+		valueBuf.Bytes(),
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	value = d.withControlSchemaHeader(value)
+	return common.NewResolvedMsg(config.ProtocolDebezium, nil, value, ts), nil
 }
 
 // AppendRowChangedEvent implements the RowEventEncoder interface
 func (d *BatchEncoder) AppendRowChangedEvent(
-	_ context.Context,
-	_ string,
+	ctx context.Context,
+	topic string,
 	e *model.RowChangedEvent,
 	callback func(),
 ) error {
@@ -51,6 +82,15 @@ func (d *BatchEncoder) AppendRowChangedEvent(
 	if err != nil {
 		return errors.Trace(err)
 	}
+	keyBuf := bytes.Buffer{}
+	err = d.codec.EncodeKey(e, &keyBuf)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var key []byte
+	if keyBuf.Len() > 0 {
+		key = keyBuf.Bytes()
+	}
 	// TODO: Use a streaming compression is better.
 	value, err := common.Compress(
 		d.config.ChangefeedID,
@@ -60,8 +100,14 @@ func (d *BatchEncoder) AppendRowChangedEvent(
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if d.schemaM != nil {
+		key, value, err = d.withRowSchemaHeader(ctx, topic, e, key, value)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 	m := &common.Message{
-		Key:      nil,
+		Key:      key,
 		Value:    value,
 		Ts:       e.CommitTs,
 		Schema:   e.TableInfo.GetSchemaNamePtr(),
@@ -76,11 +122,56 @@ func (d *BatchEncoder) AppendRowChangedEvent(
 	return nil
 }
 
+func (d *BatchEncoder) withRowSchemaHeader(
+	ctx context.Context, topic string, e *model.RowChangedEvent, key, value []byte,
+) ([]byte, []byte, error) {
+	subject := d.codec.valueJSONSchemaSubject(topic, e.TableInfo)
+	header, err := d.schemaM.GetCachedOrRegister(ctx, subject, e.TableInfo.UpdateTS,
+		func() (string, error) {
+			return d.codec.valueJSONSchema(e)
+		})
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	value = append(header, value...)
+	if len(key) == 0 {
+		return key, value, nil
+	}
+
+	subject = d.codec.keyJSONSchemaSubject(topic, e.TableInfo)
+	header, err = d.schemaM.GetCachedOrRegister(ctx, subject, e.TableInfo.UpdateTS,
+		func() (string, error) {
+			return d.codec.keyJSONSchema(e)
+		})
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return append(header, key...), value, nil
+}
+
 // EncodeDDLEvent implements the RowEventEncoder interface
 // DDL message unresolved tso
 func (d *BatchEncoder) EncodeDDLEvent(e *model.DDLEvent) (*common.Message, error) {
-	// Schema Change Events are currently not supported.
-	return nil, nil
+	// Schema Change Events are only emitted if the TiDB extension is enabled,
+	// so that the consumer can replay the DDLs.
+	if !d.config.EnableTiDBExtension {
+		return nil, nil
+	}
+	valueBuf := bytes.Buffer{}
+	err := d.codec.EncodeDDLEvent(e, &valueBuf)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	value, err := common.Compress(
+		d.config.ChangefeedID,
+		d.config.LargeMessageHandle.LargeMessageHandleCompression,
+		valueBuf.Bytes(),
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	value = d.withControlSchemaHeader(value)
+	return common.NewDDLMsg(config.ProtocolDebezium, nil, value, e), nil
 }
 
 // Build implements the RowEventEncoder interface
@@ -95,7 +186,9 @@ func (d *BatchEncoder) Build() []*common.Message {
 }
 
 // newBatchEncoder creates a new Debezium BatchEncoder.
-func newBatchEncoder(c *common.Config, clusterID string) codec.RowEventEncoder {
+func newBatchEncoder(
+	c *common.Config, clusterID string, schemaM *avro.JSONSchemaManager, controlHeader []byte,
+) codec.RowEventEncoder {
 	batch := &BatchEncoder{
 		messages: nil,
 		config:   c,
@@ -104,6 +197,8 @@ func newBatchEncoder(c *common.Config, clusterID string) codec.RowEventEncoder {
 			clusterID: clusterID,
 			nowFunc:   time.Now,
 		},
+		schemaM:       schemaM,
+		controlHeader: controlHeader,
 	}
 	return batch
 }
@@ -111,19 +206,37 @@ func newBatchEncoder(c *common.Config, clusterID string) codec.RowEventEncoder {
 type batchEncoderBuilder struct {
 	config    *common.Config
 	clusterID string
+
+	schemaM       *avro.JSONSchemaManager
+	controlHeader []byte
 }
 
 // NewBatchEncoderBuilder creates a Debezium batchEncoderBuilder.
-func NewBatchEncoderBuilder(config *common.Config, clusterID string) codec.RowEventEncoderBuilder {
-	return &batchEncoderBuilder{
+func NewBatchEncoderBuilder(
+	ctx context.Context, config *common.Config, clusterID string,
+) (codec.RowEventEncoderBuilder, error) {
+	b := &batchEncoderBuilder{
 		config:    config,
 		clusterID: clusterID,
 	}
+	if config.AvroConfluentSchemaRegistry != "" {
+		var err error
+		b.schemaM, err = avro.NewConfluentJSONSchemaManager(ctx, config.AvroConfluentSchemaRegistry, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// the control events share the same schema, register it in advance.
+		b.controlHeader, err = b.schemaM.GetCachedOrRegister(ctx, controlJSONSchemaTitle, 0, newControlJSONSchema)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return b, nil
 }
 
 // Build a `BatchEncoder`
 func (b *batchEncoderBuilder) Build() codec.RowEventEncoder {
-	return newBatchEncoder(b.config, b.clusterID)
+	return newBatchEncoder(b.config, b.clusterID, b.schemaM, b.controlHeader)
 }
 
 // CleanMetrics do nothing
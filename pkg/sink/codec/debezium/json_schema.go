@@ -0,0 +1,164 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debezium
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+)
+
+const (
+	jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// controlJSONSchemaTitle is the title of the JSON Schema of the checkpoint
+	// and schema change events. Those events are not bound to a topic,
+	// so the title is used as the subject, which follows the RecordNameStrategy.
+	controlJSONSchemaTitle = "com.pingcap.debezium.Control"
+)
+
+// valueJSONSchemaSubject returns the subject of the table's value JSON Schema, which
+// follows the TopicRecordNameStrategy, since a topic may hold multiple tables.
+func (c *dbzCodec) valueJSONSchemaSubject(topic string, tableInfo *model.TableInfo) string {
+	return fmt.Sprintf("%s-%s.%s.%s.Envelope",
+		topic, c.clusterID, tableInfo.GetSchemaName(), tableInfo.GetTableName())
+}
+
+// keyJSONSchemaSubject returns the subject of the table's key JSON Schema.
+func (c *dbzCodec) keyJSONSchemaSubject(topic string, tableInfo *model.TableInfo) string {
+	return fmt.Sprintf("%s-%s.%s.%s.Key",
+		topic, c.clusterID, tableInfo.GetSchemaName(), tableInfo.GetTableName())
+}
+
+// withInlineSchema returns a codec which writes the Kafka Connect schema in the envelope.
+func (c *dbzCodec) withInlineSchema() *dbzCodec {
+	config := *c.config
+	config.AvroConfluentSchemaRegistry = ""
+	config.DebeziumDisableSchema = false
+	return &dbzCodec{
+		config:    &config,
+		clusterID: c.clusterID,
+		nowFunc:   c.nowFunc,
+	}
+}
+
+// valueJSONSchema returns the JSON Schema of the row changed event value,
+// which is converted from the Kafka Connect schema in the envelope.
+func (c *dbzCodec) valueJSONSchema(e *model.RowChangedEvent) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := c.withInlineSchema().EncodeRowChangedEvent(e, buf); err != nil {
+		return "", err
+	}
+	return envelopeToJSONSchema(buf.Bytes())
+}
+
+// keyJSONSchema returns the JSON Schema of the row changed event key.
+func (c *dbzCodec) keyJSONSchema(e *model.RowChangedEvent) (string, error) {
+	buf := &bytes.Buffer{}
+	if err := c.withInlineSchema().EncodeKey(e, buf); err != nil {
+		return "", err
+	}
+	return envelopeToJSONSchema(buf.Bytes())
+}
+
+func envelopeToJSONSchema(envelope []byte) (string, error) {
+	var msg struct {
+		Schema map[string]interface{} `json:"schema"`
+	}
+	if err := json.Unmarshal(envelope, &msg); err != nil {
+		return "", cerror.WrapError(cerror.ErrDebeziumEncodeFailed, err)
+	}
+	schema := connectSchemaToJSONSchema(msg.Schema)
+	schema["$schema"] = jsonSchemaDraft
+	value, err := json.Marshal(schema)
+	return string(value), cerror.WrapError(cerror.ErrDebeziumEncodeFailed, err)
+}
+
+// connectSchemaToJSONSchema converts the Kafka Connect schema into JSON Schema,
+// in the same way as the confluent JSON Schema converter. The logical type name
+// is kept as the title.
+func connectSchemaToJSONSchema(schema map[string]interface{}) map[string]interface{} {
+	var result map[string]interface{}
+	switch schema["type"] {
+	case "struct":
+		properties := make(map[string]interface{})
+		var required []string
+		fields, _ := schema["fields"].([]interface{})
+		for _, f := range fields {
+			field, ok := f.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := field["field"].(string)
+			properties[name] = connectSchemaToJSONSchema(field)
+			if optional, _ := field["optional"].(bool); !optional {
+				required = append(required, name)
+			}
+		}
+		result = map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+		if len(required) != 0 {
+			result["required"] = required
+		}
+	case "boolean":
+		result = map[string]interface{}{"type": "boolean"}
+	case "int8", "int16", "int32", "int64":
+		result = map[string]interface{}{"type": "integer"}
+	case "float", "double":
+		result = map[string]interface{}{"type": "number"}
+	default:
+		// the string and bytes, the bytes are encoded in base64.
+		result = map[string]interface{}{"type": "string"}
+	}
+	if name, _ := schema["name"].(string); name != "" {
+		result["title"] = name
+	}
+	if optional, _ := schema["optional"].(bool); optional {
+		return map[string]interface{}{
+			"oneOf": []interface{}{map[string]interface{}{"type": "null"}, result},
+		}
+	}
+	return result
+}
+
+// newControlJSONSchema returns the JSON Schema of the checkpoint and schema change events.
+func newControlJSONSchema() (string, error) {
+	integer := map[string]interface{}{"type": "integer"}
+	str := map[string]interface{}{"type": "string"}
+	schema := map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+		"title":   controlJSONSchemaTitle,
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"source": map[string]interface{}{
+				"type":  "object",
+				"title": "io.debezium.connector.mysql.Source",
+			},
+			"ts_ms":        integer,
+			"op":           str,
+			"databaseName": str,
+			"schemaName":   map[string]interface{}{"type": []string{"string", "null"}},
+			"ddl":          str,
+			"tableChanges": map[string]interface{}{"type": "array"},
+		},
+		"required": []string{"source", "ts_ms"},
+	}
+	value, err := json.Marshal(schema)
+	return string(value), cerror.WrapError(cerror.ErrDebeziumEncodeFailed, err)
+}
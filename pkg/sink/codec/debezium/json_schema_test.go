@@ -0,0 +1,148 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debezium
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/codec/avro"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeWithJSONSchemaRegistry(t *testing.T) {
+	avro.StartSchemaRegistry4Testing()
+	defer avro.StopSchemaRegistry4Testing()
+
+	row := newRowEvents(t)[0]
+	cfg := common.NewConfig(config.ProtocolDebezium)
+	cfg.TimeZone = time.UTC
+	cfg.EnableTiDBExtension = true
+	cfg.AvroConfluentSchemaRegistry = "http://127.0.0.1:8081"
+
+	ctx := context.Background()
+	builder, err := NewBatchEncoderBuilder(ctx, cfg, "dbserver1")
+	require.NoError(t, err)
+	encoder := builder.Build()
+
+	checkpoint, err := encoder.EncodeCheckpointEvent(100)
+	require.NoError(t, err)
+	controlID, payload, err := avro.SplitConfluentJSONMessage(checkpoint.Value)
+	require.NoError(t, err)
+	require.NotZero(t, controlID)
+	require.Equal(t, byte('{'), payload[0])
+
+	require.NoError(t, encoder.AppendRowChangedEvent(ctx, "topic", row, nil))
+	messages := encoder.Build()
+	require.Len(t, messages, 1)
+
+	valueID, value, err := avro.SplitConfluentJSONMessage(messages[0].Value)
+	require.NoError(t, err)
+	require.NotZero(t, valueID)
+	keyID, key, err := avro.SplitConfluentJSONMessage(messages[0].Key)
+	require.NoError(t, err)
+	require.NotZero(t, keyID)
+	require.NotEqual(t, valueID, keyID)
+	require.NotEqual(t, controlID, valueID)
+
+	// the envelope is not wrapped, since the schema is registered.
+	require.JSONEq(t, `{"id":1}`, string(key))
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(value, &fields))
+	require.NotContains(t, fields, "payload")
+	require.NotContains(t, fields, "schema")
+	require.Contains(t, fields, "after")
+
+	// the schema is registered, so the column types are recovered from the upstream.
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	expectUpstreamQuery(mock, row.CommitTs, "test", "foo", upstreamColumns)
+	decoder := NewDecoder(cfg, db)
+	require.NoError(t, decoder.AddKeyValue(messages[0].Key, messages[0].Value))
+	tp, hasNext, err := decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeRow, tp)
+	decoded, err := decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.True(t, decoded.IsInsert())
+	require.Equal(t, row.CommitTs, decoded.CommitTs)
+	values := columnValues(decoded.GetColumns())
+	require.Equal(t, int64(1), values["id"])
+	require.Equal(t, "2024-02-29", values["h"])
+	require.NoError(t, mock.ExpectationsWereMet())
+	for _, col := range decoded.GetColumns() {
+		require.Equal(t, col.Name == "id", col.Flag.IsHandleKey(), col.Name)
+	}
+
+	require.NoError(t, decoder.AddKeyValue(nil, checkpoint.Value))
+	tp, hasNext, err = decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeResolved, tp)
+	ts, err := decoder.NextResolvedEvent()
+	require.NoError(t, err)
+	require.Equal(t, uint64(100), ts)
+}
+
+func TestValueJSONSchema(t *testing.T) {
+	row := newRowEvents(t)[0]
+	cfg := common.NewConfig(config.ProtocolDebezium)
+	cfg.AvroConfluentSchemaRegistry = "http://127.0.0.1:8081"
+	c := &dbzCodec{config: cfg, clusterID: "dbserver1", nowFunc: time.Now}
+
+	require.Equal(t, "topic-dbserver1.test.foo.Envelope", c.valueJSONSchemaSubject("topic", row.TableInfo))
+	require.Equal(t, "topic-dbserver1.test.foo.Key", c.keyJSONSchemaSubject("topic", row.TableInfo))
+
+	value, err := c.valueJSONSchema(row)
+	require.NoError(t, err)
+	var schema struct {
+		Title      string                     `json:"title"`
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(value), &schema))
+	require.Equal(t, "dbserver1.test.foo.Envelope", schema.Title)
+	require.ElementsMatch(t, []string{"source", "op"}, schema.Required)
+	require.JSONEq(t, `{"oneOf":[{"type":"null"},{"type":"integer"}]}`, string(schema.Properties["ts_ms"]))
+
+	// the optional struct is converted to the union with null.
+	var after struct {
+		OneOf []struct {
+			Type       string                     `json:"type"`
+			Title      string                     `json:"title"`
+			Properties map[string]json.RawMessage `json:"properties"`
+		} `json:"oneOf"`
+	}
+	require.NoError(t, json.Unmarshal(schema.Properties["after"], &after))
+	require.Len(t, after.OneOf, 2)
+	require.Equal(t, "null", after.OneOf[0].Type)
+	require.Equal(t, "dbserver1.test.foo.Value", after.OneOf[1].Title)
+	require.JSONEq(t, `{"type":"integer"}`, string(after.OneOf[1].Properties["id"]))
+	require.JSONEq(t, `{"oneOf":[{"type":"null"},{"type":"integer","title":"io.debezium.time.Date"}]}`,
+		string(after.OneOf[1].Properties["h"]))
+	require.JSONEq(t, `{"oneOf":[{"type":"null"},{"type":"boolean"}]}`, string(after.OneOf[1].Properties["d"]))
+
+	key, err := c.keyJSONSchema(row)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object",
+		"title":"dbserver1.test.foo.Key","properties":{"id":{"type":"integer"}},"required":["id"]}`, key)
+}
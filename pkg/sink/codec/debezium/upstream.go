@@ -0,0 +1,165 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debezium
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+)
+
+// upstreamDecimalType is the type of the decimal fields recovered from the
+// upstream. It's not a Debezium type, the decimal is encoded as double, and
+// decoded from the JSON number literal to keep the digits.
+const upstreamDecimalType = "decimal"
+
+const queryColumnsSQL = "SELECT COLUMN_NAME, DATA_TYPE, COLUMN_TYPE, IFNULL(DATETIME_PRECISION, 0) " +
+	"FROM information_schema.columns WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? " +
+	"ORDER BY ORDINAL_POSITION"
+
+// queryUpstreamFields recovers the schema of the table from the upstream TiDB
+// at the commit ts, the fields are the same as those in the message schema.
+func (d *Decoder) queryUpstreamFields(
+	schema, table string, commitTs uint64,
+) ([]*field, error) {
+	name := model.TableName{Schema: schema, Table: table}
+	if fields, ok := d.upstreamFields[name]; ok {
+		return fields, nil
+	}
+
+	ctx := context.Background()
+	conn, err := d.upstreamTiDB.Conn(ctx)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	defer conn.Close()
+	_, err = conn.ExecContext(ctx, fmt.Sprintf("set @@tidb_snapshot=%d", commitTs))
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	// the connection is returned to the pool, so the snapshot must be reset.
+	defer func() {
+		_, _ = conn.ExecContext(ctx, "set @@tidb_snapshot=''")
+	}()
+
+	rows, err := conn.QueryContext(ctx, queryColumnsSQL, schema, table)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	defer rows.Close()
+	var fields []*field
+	for rows.Next() {
+		var colName, dataType, columnType string
+		var precision int
+		if err := rows.Scan(&colName, &dataType, &columnType, &precision); err != nil {
+			return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+		}
+		fields = append(fields, newUpstreamField(colName, dataType, columnType, precision))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, cerror.WrapError(cerror.ErrDebeziumDecodeFailed, err)
+	}
+	if len(fields) == 0 {
+		return nil, cerror.ErrDebeziumDecodeFailed.GenWithStack(
+			"table %s not found in the upstream at %d", name.String(), commitTs)
+	}
+	d.upstreamFields[name] = fields
+	return fields, nil
+}
+
+// newUpstreamField returns the field which the encoder writes for the column,
+// see writeDebeziumFieldSchema.
+func newUpstreamField(name, dataType, columnType string, precision int) *field {
+	f := &field{Field: name}
+	switch strings.ToLower(dataType) {
+	case "bit":
+		if strings.EqualFold(columnType, "bit(1)") {
+			f.Type = "boolean"
+		} else {
+			f.Type, f.Name = "bytes", "io.debezium.data.Bits"
+		}
+	case "enum":
+		f.Type, f.Name = "string", "io.debezium.data.Enum"
+		f.Parameters = map[string]string{"allowed": strings.Join(parseElems(columnType), ",")}
+	case "set":
+		f.Type, f.Name = "string", "io.debezium.data.EnumSet"
+		f.Parameters = map[string]string{"allowed": strings.Join(parseElems(columnType), ",")}
+	case "decimal":
+		f.Type = upstreamDecimalType
+	case "date":
+		f.Type, f.Name = "int32", "io.debezium.time.Date"
+	case "datetime":
+		f.Type, f.Name = "int64", "io.debezium.time.Timestamp"
+		if precision > 3 {
+			f.Name = "io.debezium.time.MicroTimestamp"
+		}
+	case "timestamp":
+		f.Type, f.Name = "string", "io.debezium.time.ZonedTimestamp"
+	case "time":
+		f.Type, f.Name = "int64", "io.debezium.time.MicroTime"
+	case "json":
+		f.Type, f.Name = "string", "io.debezium.data.Json"
+	case "year":
+		f.Type, f.Name = "int32", "io.debezium.time.Year"
+	case "tinyint", "smallint":
+		f.Type = "int16"
+	case "mediumint", "int":
+		f.Type = "int32"
+	case "bigint":
+		f.Type = "int64"
+		f.unsigned = strings.Contains(strings.ToLower(columnType), "unsigned")
+	case "float":
+		f.Type = "float"
+	case "double":
+		f.Type = "double"
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob":
+		f.Type = "bytes"
+	default:
+		f.Type = "string"
+	}
+	return f
+}
+
+// parseElems parses the elements of the enum or set column type,
+// such as enum('a','b'), the quote in the element is escaped by doubling it.
+func parseElems(columnType string) []string {
+	start, end := strings.IndexByte(columnType, '('), strings.LastIndexByte(columnType, ')')
+	if start < 0 || end <= start {
+		return nil
+	}
+	var elems []string
+	var elem strings.Builder
+	quoted := false
+	s := columnType[start+1 : end]
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\'' && quoted && i+1 < len(s) && s[i+1] == '\'':
+			elem.WriteByte(c)
+			i++
+		case c == '\'':
+			quoted = !quoted
+			if !quoted {
+				elems = append(elems, elem.String())
+				elem.Reset()
+			}
+		case quoted:
+			elem.WriteByte(c)
+		}
+	}
+	return elems
+}
@@ -35,3 +35,10 @@ type RowEventDecoder interface {
 	// NextDDLEvent returns the next DDL event if exists
 	NextDDLEvent() (*model.DDLEvent, error)
 }
+
+// ControlMessageDecoder is implemented by the decoders of the protocols which
+// can carry the control messages, see ControlMessageEncoder.
+type ControlMessageDecoder interface {
+	// DecodeControlMessage returns the key and the JSON value of the control message.
+	DecodeControlMessage(value []byte) (string, []byte, error)
+}
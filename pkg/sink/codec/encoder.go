@@ -53,6 +53,15 @@ type RowEventEncoder interface {
 	MessageBuilder
 }
 
+// ControlMessageEncoder is implemented by the encoders whose protocol can carry
+// the control messages of TiCDC, such as the partition mapping change markers,
+// so the consumers can tell them from the change events.
+type ControlMessageEncoder interface {
+	// EncodeControlMessage encodes the JSON value of the control message, the
+	// key of the returned message is the key of the control message.
+	EncodeControlMessage(key string, value []byte, ts uint64) (*common.Message, error)
+}
+
 // RowEventEncoderBuilder builds row encoder with context.
 type RowEventEncoderBuilder interface {
 	Build() RowEventEncoder
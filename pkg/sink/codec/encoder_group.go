@@ -41,6 +41,9 @@ type EncoderGroup interface {
 	// AddEvents add events into the group and encode them by one of the encoders in the group.
 	// Note: The caller should make sure all events should belong to the same topic and partition.
 	AddEvents(ctx context.Context, key model.TopicPartitionKey, events ...*dmlsink.RowChangeCallbackableEvent) error
+	// AddMessages add the encoded messages into the group, they are output
+	// in the same order as the events added before and after them.
+	AddMessages(ctx context.Context, key model.TopicPartitionKey, messages ...*common.Message) error
 	// Output returns a channel produce futures
 	Output() <-chan *future
 }
@@ -86,6 +89,14 @@ func NewEncoderGroup(
 			util.GetOrZero(cfg.SendBootstrapToAllPartition),
 			defaultMaxInactiveDuration,
 		)
+	} else if cfg.ShouldEncodeSchemaVersion() {
+		bootstrapWorker = newSchemaVersionBootstrapWorker(
+			changefeedID,
+			outCh,
+			builder.Build(),
+			util.GetOrZero(cfg.SendBootstrapToAllPartition),
+			defaultMaxInactiveDuration,
+		)
 	}
 
 	return &encoderGroup{
@@ -99,6 +110,16 @@ func NewEncoderGroup(
 	}
 }
 
+// WithDDLTopic sets the function which returns the DDL topic of a table, the
+// bootstrap messages of the table are sent to its DDL topic if it's not empty.
+// It should be called before Run.
+func (g *encoderGroup) WithDDLTopic(ddlTopic func(schema, table string) string) *encoderGroup {
+	if g.bootstrapWorker != nil {
+		g.bootstrapWorker.ddlTopic = ddlTopic
+	}
+	return g
+}
+
 func (g *encoderGroup) Run(ctx context.Context) error {
 	defer func() {
 		g.cleanMetrics()
@@ -175,11 +196,29 @@ func (g *encoderGroup) AddEvents(
 	key model.TopicPartitionKey,
 	events ...*dmlsink.RowChangeCallbackableEvent,
 ) error {
-	// bootstrapWorker only not nil when the protocol is simple
+	// bootstrapWorker only not nil when the protocol is simple,
+	// or the schema version is encoded in the messages.
 	if g.bootstrapWorker != nil {
-		err := g.bootstrapWorker.addEvent(ctx, key, events[0].Event)
-		if err != nil {
-			return errors.Trace(err)
+		// The events of the batch protocols may belong to several tables or
+		// schema versions, all of them are bootstrapped before the events.
+		type tableVersion struct {
+			name    model.TableName
+			version uint64
+		}
+		added := make(map[tableVersion]struct{}, 1)
+		for _, event := range events {
+			tv := tableVersion{
+				name:    event.Event.TableInfo.TableName,
+				version: event.Event.TableInfo.UpdateTS,
+			}
+			if _, ok := added[tv]; ok {
+				continue
+			}
+			added[tv] = struct{}{}
+			err := g.bootstrapWorker.addEvent(ctx, key, event.Event)
+			if err != nil {
+				return errors.Trace(err)
+			}
 		}
 	}
 
@@ -200,6 +239,22 @@ func (g *encoderGroup) AddEvents(
 	return nil
 }
 
+func (g *encoderGroup) AddMessages(
+	ctx context.Context,
+	key model.TopicPartitionKey,
+	messages ...*common.Message,
+) error {
+	future := newFuture(key)
+	future.Messages = messages
+	close(future.done)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case g.outputCh <- future:
+	}
+	return nil
+}
+
 func (g *encoderGroup) Output() <-chan *future {
 	return g.outputCh
 }
@@ -237,3 +292,9 @@ func (p *future) Ready(ctx context.Context) error {
 	}
 	return nil
 }
+
+// EventCount returns the number of the row changed events encoded by the future,
+// it's zero for the future of the bootstrap messages and the added messages.
+func (p *future) EventCount() int {
+	return len(p.events)
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"context"
+	"testing"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncoderGroupBootstrapBatch(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.SinkConfig{
+		Protocol:            util.AddressOf(config.ProtocolOpen.String()),
+		EnableSchemaVersion: util.AddressOf(true),
+	}
+	group := NewEncoderGroup(cfg, &MockRowEventEncoderBuilder{}, model.DefaultChangeFeedID("test"))
+	require.NotNil(t, group.bootstrapWorker)
+
+	newEvent := func(table string, tableID int64, version uint64) *dmlsink.RowChangeCallbackableEvent {
+		tableInfo := &model.TableInfo{
+			TableName: model.TableName{Schema: "test", Table: table, TableID: tableID},
+			TableInfo: &timodel.TableInfo{ID: tableID, UpdateTS: version},
+		}
+		return &dmlsink.RowChangeCallbackableEvent{
+			Event: &model.RowChangedEvent{PhysicalTableID: tableID, TableInfo: tableInfo},
+		}
+	}
+	// a batch of the same partition holds the rows of two tables,
+	// and the schema of t1 is changed in the middle of the batch.
+	events := []*dmlsink.RowChangeCallbackableEvent{
+		newEvent("t1", 1, 1),
+		newEvent("t2", 2, 1),
+		newEvent("t1", 1, 1),
+		newEvent("t1", 1, 2),
+		newEvent("t2", 2, 1),
+	}
+	key := model.TopicPartitionKey{Topic: "test", Partition: 0, TotalPartition: 1}
+	ctx := context.Background()
+	require.NoError(t, group.AddEvents(ctx, key, events...))
+
+	// t1 at version 1, t2 at version 1 and t1 at version 2 are bootstrapped
+	// before the batch.
+	for i := 0; i < 3; i++ {
+		f := <-group.Output()
+		require.Equal(t, 0, f.EventCount())
+		require.Len(t, f.Messages, 1)
+	}
+	f := <-group.Output()
+	require.Equal(t, len(events), f.EventCount())
+	require.Len(t, group.Output(), 0)
+
+	// no bootstrap message is sent again for the latest versions of the tables.
+	require.NoError(t, group.AddEvents(ctx, key, events[3:]...))
+	f = <-group.Output()
+	require.Equal(t, 2, f.EventCount())
+	require.Len(t, group.Output(), 0)
+}
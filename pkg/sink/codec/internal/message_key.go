@@ -33,6 +33,9 @@ type MessageKey struct {
 
 	// Claim check location for the message
 	ClaimCheckLocation string `json:"ccl,omitempty"`
+
+	// Schema version of the table, the UpdateTS of the table info.
+	SchemaVersion uint64 `json:"sv,omitempty"`
 }
 
 // Encode encodes the message key to a byte slice.
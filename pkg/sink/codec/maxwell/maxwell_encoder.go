@@ -50,6 +50,9 @@ func (d *BatchEncoder) AppendRowChangedEvent(
 	callback func(),
 ) error {
 	_, valueMsg := rowChangeToMaxwellMsg(e, d.config.DeleteOnlyHandleKeyColumns)
+	if d.config.EnableSchemaVersion {
+		valueMsg.SchemaVersion = e.TableInfo.UpdateTS
+	}
 	value, err := valueMsg.encode()
 	if err != nil {
 		return errors.Trace(err)
@@ -66,6 +69,10 @@ func (d *BatchEncoder) AppendRowChangedEvent(
 // DDL message unresolved tso
 func (d *BatchEncoder) EncodeDDLEvent(e *model.DDLEvent) (*common.Message, error) {
 	keyMsg, valueMsg := ddlEventToMaxwellMsg(e)
+	if d.config.EnableSchemaVersion {
+		keyMsg.SchemaVersion = e.TableInfo.UpdateTS
+		valueMsg.SchemaVersion = e.TableInfo.UpdateTS
+	}
 	key, err := keyMsg.Encode()
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -15,6 +15,7 @@ package maxwell
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/pingcap/tiflow/cdc/entry"
@@ -120,3 +121,29 @@ func TestMaxwellAppendRowChangedEventWithCallback(t *testing.T) {
 	msgs[0].Callback()
 	require.Equal(t, 15, count, "expected all callbacks to be called")
 }
+
+func TestMaxwellSchemaVersion(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	ddlEvent := helper.DDL2Event("create table test.t(col1 int primary key)")
+	dmlEvent := helper.DML2Event("insert into test.t values (10)", "test", "t")
+
+	encoder := newBatchEncoder(&common.Config{EnableSchemaVersion: true})
+	err := encoder.AppendRowChangedEvent(context.Background(), "", dmlEvent, nil)
+	require.NoError(t, err)
+	messages := encoder.Build()
+	require.Len(t, messages, 1)
+	var rowMessage maxwellMessage
+	require.NoError(t, json.Unmarshal(messages[0].Value, &rowMessage))
+	require.Equal(t, dmlEvent.TableInfo.UpdateTS, rowMessage.SchemaVersion)
+
+	// the bootstrap message is a table creation with the primary key.
+	message, err := encoder.EncodeDDLEvent(model.NewBootstrapDDLEvent(ddlEvent.TableInfo))
+	require.NoError(t, err)
+	var ddlMessage ddlMaxwellMessage
+	require.NoError(t, json.Unmarshal(message.Value, &ddlMessage))
+	require.Equal(t, "table-create", ddlMessage.Type)
+	require.Equal(t, []string{"col1"}, ddlMessage.Def.PrimaryKey)
+	require.Equal(t, ddlEvent.TableInfo.UpdateTS, ddlMessage.SchemaVersion)
+}
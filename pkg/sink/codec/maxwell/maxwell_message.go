@@ -35,6 +35,8 @@ type maxwellMessage struct {
 	Gtid     string                 `json:"gtid,omitempty"`
 	Data     map[string]interface{} `json:"data,omitempty"`
 	Old      map[string]interface{} `json:"old,omitempty"`
+	// SchemaVersion is the version of the table schema the row is encoded with.
+	SchemaVersion uint64 `json:"schema_version,omitempty"`
 }
 
 // Encode encodes the message to bytes
@@ -173,6 +175,8 @@ type ddlMaxwellMessage struct {
 	Ts       uint64      `json:"ts"`
 	SQL      string      `json:"sql"`
 	Position string      `json:"position,omitempty"`
+	// SchemaVersion is the version of the table schema after the ddl.
+	SchemaVersion uint64 `json:"schema_version,omitempty"`
 }
 
 // Encode encodes the message to bytes
@@ -199,6 +203,12 @@ func ddlEventToMaxwellMsg(e *model.DDLEvent) (*internal.MessageKey, *ddlMaxwellM
 	}
 
 	value.Type = ddlToMaxwellType(e.Type)
+	// the bootstrap message describes the current definition of the table,
+	// so it's always treated as a table creation.
+	if e.IsBootstrap {
+		value.Type = "table-create"
+		value.Def.PrimaryKey = e.TableInfo.GetPrimaryKeyColumnNames()
+	}
 
 	if e.PreTableInfo != nil {
 		value.Old.Database = e.PreTableInfo.TableName.Schema
@@ -302,6 +302,7 @@ func (b *BatchDecoder) assembleHandleKeyOnlyEvent(
 		preColumns := b.buildColumns(holder, conditions)
 		handleKeyOnlyEvent.PreColumns = model.Columns2ColumnDatas(preColumns, handleKeyOnlyEvent.TableInfo)
 	}
+	handleKeyOnlyEvent.TableInfo.UpdateTS = tableInfo.UpdateTS
 
 	return handleKeyOnlyEvent
 }
@@ -120,7 +120,7 @@ func (d *BatchEncoder) AppendRowChangedEvent(
 		if d.config.LargeMessageHandle.EnableClaimCheck() {
 			// send the large message to the external storage first, then
 			// create a new message contains the reference of the large message.
-			claimCheckFileName := claimcheck.NewFileName()
+			claimCheckFileName := d.claimCheck.NewFileName()
 			m := newMessage(key, value)
 			err = d.claimCheck.WriteMessage(ctx, m.Key, m.Value, claimCheckFileName)
 			if err != nil {
@@ -202,7 +202,7 @@ func newMessage(key, value []byte) *common.Message {
 
 // EncodeDDLEvent implements the RowEventEncoder interface
 func (d *BatchEncoder) EncodeDDLEvent(e *model.DDLEvent) (*common.Message, error) {
-	keyMsg, valueMsg := ddlEventToMsg(e)
+	keyMsg, valueMsg := ddlEventToMsg(e, d.config)
 	key, err := keyMsg.Encode()
 	if err != nil {
 		return nil, errors.Trace(err)
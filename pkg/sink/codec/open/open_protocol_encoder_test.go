@@ -18,6 +18,7 @@ import (
 	"database/sql"
 	"testing"
 
+	"github.com/pingcap/tidb/pkg/parser/mysql"
 	"github.com/pingcap/tiflow/cdc/entry"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/pkg/compression"
@@ -318,6 +319,35 @@ func TestE2EDDLCompression(t *testing.T) {
 	require.Equal(t, decodedWatermark, waterMark)
 }
 
+func TestSchemaVersion(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	ddlEvent := helper.DDL2Event(`create table test.t(a int primary key, b varchar(32))`)
+	dmlEvent := helper.DML2Event(`insert into test.t values (1, "a")`, "test", "t")
+
+	codecConfig := common.NewConfig(config.ProtocolOpen)
+	key, _, err := rowChangeToMsg(dmlEvent, codecConfig, false)
+	require.NoError(t, err)
+	require.Zero(t, key.SchemaVersion)
+
+	codecConfig.EnableSchemaVersion = true
+	key, _, err = rowChangeToMsg(dmlEvent, codecConfig, false)
+	require.NoError(t, err)
+	require.Equal(t, dmlEvent.TableInfo.UpdateTS, key.SchemaVersion)
+
+	key, value := ddlEventToMsg(ddlEvent, codecConfig)
+	require.Equal(t, ddlEvent.TableInfo.UpdateTS, key.SchemaVersion)
+	require.Empty(t, value.Columns)
+
+	// the bootstrap message carries the table definition.
+	_, value = ddlEventToMsg(model.NewBootstrapDDLEvent(ddlEvent.TableInfo), codecConfig)
+	require.Len(t, value.Columns, 2)
+	flag := value.Columns["a"].Flag
+	require.True(t, flag.IsPrimaryKey())
+	require.Equal(t, mysql.TypeVarchar, value.Columns["b"].Type)
+}
+
 func TestE2EHandleKeyOnlyEvent(t *testing.T) {
 	_, insertEvent, _, _ := utils.NewLargeEvent4Test(t, config.GetDefaultReplicaConfig())
 
@@ -373,7 +403,7 @@ func TestE2EClaimCheckMessage(t *testing.T) {
 	ctx := context.Background()
 	topic := ""
 
-	a := 263
+	a := 279
 	codecConfig := common.NewConfig(config.ProtocolOpen).WithMaxMessageBytes(a)
 	codecConfig.LargeMessageHandle.LargeMessageHandleOption = config.LargeMessageHandleOptionClaimCheck
 	codecConfig.LargeMessageHandle.LargeMessageHandleCompression = compression.LZ4
@@ -493,3 +523,63 @@ func TestNoHandleKeys(t *testing.T) {
 	require.Nil(t, value)
 	require.Nil(t, key)
 }
+
+func TestSchemaVersionRoundTrip(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	ddlEvent := helper.DDL2Event(`create table test.t(a int primary key, b varchar(32))`)
+	dmlEvent := helper.DML2Event(`insert into test.t values (1, "a")`, "test", "t")
+
+	ctx := context.Background()
+	codecConfig := common.NewConfig(config.ProtocolOpen)
+	codecConfig.EnableSchemaVersion = true
+	builder, err := NewBatchEncoderBuilder(ctx, codecConfig)
+	require.NoError(t, err)
+	encoder := builder.Build()
+	decoder, err := NewBatchDecoder(ctx, codecConfig, nil)
+	require.NoError(t, err)
+
+	decodeDDL := func(ddl *model.DDLEvent) *model.DDLEvent {
+		message, err := encoder.EncodeDDLEvent(ddl)
+		require.NoError(t, err)
+		require.NoError(t, decoder.AddKeyValue(message.Key, message.Value))
+		tp, hasNext, err := decoder.HasNext()
+		require.NoError(t, err)
+		require.True(t, hasNext)
+		require.Equal(t, model.MessageTypeDDL, tp)
+		decoded, err := decoder.NextDDLEvent()
+		require.NoError(t, err)
+		return decoded
+	}
+
+	decoded := decodeDDL(ddlEvent)
+	require.False(t, decoded.IsBootstrap)
+	require.Equal(t, ddlEvent.Query, decoded.Query)
+	require.Equal(t, ddlEvent.TableInfo.UpdateTS, decoded.TableInfo.UpdateTS)
+
+	// the bootstrap message is decoded with the table definition.
+	decoded = decodeDDL(model.NewBootstrapDDLEvent(ddlEvent.TableInfo))
+	require.True(t, decoded.IsBootstrap)
+	require.Empty(t, decoded.Query)
+	require.Equal(t, "test", decoded.TableInfo.GetSchemaName())
+	require.Equal(t, "t", decoded.TableInfo.GetTableName())
+	require.Equal(t, ddlEvent.TableInfo.UpdateTS, decoded.TableInfo.UpdateTS)
+	require.Len(t, decoded.TableInfo.Columns, 2)
+	colInfo, ok := decoded.TableInfo.GetColumnInfo(decoded.TableInfo.ForceGetColumnIDByName("a"))
+	require.True(t, ok)
+	require.True(t, mysql.HasPriKeyFlag(colInfo.GetFlag()))
+
+	err = encoder.AppendRowChangedEvent(ctx, "", dmlEvent, nil)
+	require.NoError(t, err)
+	messages := encoder.Build()
+	require.Len(t, messages, 1)
+	require.NoError(t, decoder.AddKeyValue(messages[0].Key, messages[0].Value))
+	tp, hasNext, err := decoder.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeRow, tp)
+	row, err := decoder.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.Equal(t, dmlEvent.TableInfo.UpdateTS, row.TableInfo.UpdateTS)
+}
@@ -101,6 +101,8 @@ func (m *messageRow) dropNotUpdatedColumns() {
 type messageDDL struct {
 	Query string             `json:"q"`
 	Type  timodel.ActionType `json:"t"`
+	// Columns is the definition of the table, only set in the bootstrap message.
+	Columns map[string]internal.Column `json:"c,omitempty"`
 }
 
 func (m *messageDDL) encode() ([]byte, error) {
@@ -137,6 +139,9 @@ func rowChangeToMsg(
 		Type:          model.MessageTypeRow,
 		OnlyHandleKey: largeMessageOnlyHandleKeyColumns,
 	}
+	if config.EnableSchemaVersion {
+		key.SchemaVersion = e.TableInfo.UpdateTS
+	}
 	value := &messageRow{}
 	if e.IsDelete() {
 		onlyHandleKeyColumns := config.DeleteOnlyHandleKeyColumns || largeMessageOnlyHandleKeyColumns
@@ -194,6 +199,7 @@ func msgToRowChange(key *internal.MessageKey, value *messageRow) *model.RowChang
 		e.PhysicalTableID = *key.Partition
 		e.TableInfo.TableName.IsPartition = true
 	}
+	e.TableInfo.UpdateTS = key.SchemaVersion
 
 	return e
 }
@@ -232,23 +238,53 @@ func codecColumns2RowChangeColumns(cols map[string]internal.Column) []*model.Col
 	return sinkCols
 }
 
-func ddlEventToMsg(e *model.DDLEvent) (*internal.MessageKey, *messageDDL) {
+func ddlEventToMsg(e *model.DDLEvent, config *common.Config) (*internal.MessageKey, *messageDDL) {
 	key := &internal.MessageKey{
 		Ts:     e.CommitTs,
 		Schema: e.TableInfo.TableName.Schema,
 		Table:  e.TableInfo.TableName.Table,
 		Type:   model.MessageTypeDDL,
 	}
+	if config.EnableSchemaVersion {
+		key.SchemaVersion = e.TableInfo.UpdateTS
+	}
 	value := &messageDDL{
 		Query: e.Query,
 		Type:  e.Type,
 	}
+	if e.IsBootstrap {
+		value.Columns = tableInfo2CodecColumns(e.TableInfo)
+	}
 	return key, value
 }
 
+// tableInfo2CodecColumns returns the definition of the columns of the table,
+// the value of each column is always nil.
+func tableInfo2CodecColumns(tableInfo *model.TableInfo) map[string]internal.Column {
+	columns := make(map[string]internal.Column, len(tableInfo.Columns))
+	for _, columnInfo := range tableInfo.Columns {
+		if !model.IsColCDCVisible(columnInfo) {
+			continue
+		}
+		columns[columnInfo.Name.O] = internal.Column{
+			Type: columnInfo.GetType(),
+			Flag: *tableInfo.ForceGetColumnFlagType(columnInfo.ID),
+		}
+	}
+	return columns
+}
+
 func msgToDDLEvent(key *internal.MessageKey, value *messageDDL) *model.DDLEvent {
 	e := new(model.DDLEvent)
 	e.TableInfo = new(model.TableInfo)
+	// the bootstrap message carries the definition of the table instead of the query.
+	if value.Columns != nil {
+		e.IsBootstrap = true
+		cols := codecColumns2RowChangeColumns(value.Columns)
+		sortColumnArrays(cols)
+		indexColumns := model.GetHandleAndUniqueIndexOffsets4Test(cols)
+		e.TableInfo = model.BuildTableInfo(key.Schema, key.Table, cols, indexColumns)
+	}
 	// TODO: we lost the startTs from kafka message
 	// startTs-based txn filter is out of work
 	e.CommitTs = key.Ts
@@ -256,6 +292,13 @@ func msgToDDLEvent(key *internal.MessageKey, value *messageDDL) *model.DDLEvent
 		Schema: key.Schema,
 		Table:  key.Table,
 	}
+	// the schema version is kept in the table info as the row message does.
+	if version := key.SchemaVersion; version != 0 {
+		if e.TableInfo.TableInfo == nil {
+			e.TableInfo.TableInfo = &timodel.TableInfo{}
+		}
+		e.TableInfo.UpdateTS = version
+	}
 	e.Type = value.Type
 	e.Query = value.Query
 	return e
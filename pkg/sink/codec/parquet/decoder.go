@@ -0,0 +1,189 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pingcap/errors"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+type batchDecoder struct {
+	codecConfig *common.Config
+	tableInfo   *model.TableInfo
+	// columns are the table columns of the data columns in the file, a column
+	// is nil if it does not exist in the table.
+	columns []*timodel.ColumnInfo
+	// zeroColumns maps the index of a column which records the zero dates
+	// to the index of the data column.
+	zeroColumns map[int]int
+	rows        [][]interface{}
+	// next is the index of the next row, the current row is rows[next-1].
+	next int
+}
+
+// NewBatchDecoder creates a new parquet BatchDecoder, all the rows of the
+// parquet file are read at once.
+func NewBatchDecoder(_ context.Context,
+	codecConfig *common.Config,
+	tableInfo *model.TableInfo,
+	value []byte,
+) (codec.RowEventDecoder, error) {
+	pf, err := buffer.NewBufferFile(value)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrParquetDecodeFailed, err)
+	}
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrParquetDecodeFailed, err)
+	}
+	defer pr.ReadStop()
+
+	// the first element of the infos is the root of the schema.
+	infos := pr.SchemaHandler.Infos[1:]
+	if len(infos) < metaColumnCount ||
+		infos[0].ExName != OperationColumn || infos[1].ExName != CommitTsColumn {
+		return nil, cerror.WrapError(cerror.ErrParquetDecodeFailed,
+			errors.New("the parquet file should start with the operation and commit ts columns"))
+	}
+
+	b := &batchDecoder{
+		codecConfig: codecConfig,
+		tableInfo:   tableInfo,
+		columns:     make([]*timodel.ColumnInfo, len(infos)-metaColumnCount),
+		zeroColumns: make(map[int]int),
+	}
+	byName := make(map[string]*timodel.ColumnInfo, len(tableInfo.Columns))
+	for _, col := range tableInfo.Columns {
+		byName[columnName(col.Name.O)] = col
+	}
+	dataColumns := make(map[string]int, len(b.columns))
+	for i, info := range infos[metaColumnCount:] {
+		if name, ok := strings.CutPrefix(info.ExName, ZeroTimeColumnPrefix); ok {
+			if idx, ok := dataColumns[name]; ok {
+				b.zeroColumns[i] = idx
+			}
+			continue
+		}
+		b.columns[i] = byName[info.ExName]
+		dataColumns[info.ExName] = i
+	}
+
+	num := pr.GetNumRows()
+	b.rows = make([][]interface{}, num)
+	for i := range infos {
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), num)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrParquetDecodeFailed, err)
+		}
+		if int64(len(values)) != num {
+			return nil, cerror.ErrParquetDecodeFailed.GenWithStack(
+				"column %s has %d values, but the file has %d rows",
+				infos[i].ExName, len(values), num)
+		}
+		for j, v := range values {
+			b.rows[j] = append(b.rows[j], v)
+		}
+	}
+	return b, nil
+}
+
+// AddKeyValue implements the RowEventDecoder interface.
+func (b *batchDecoder) AddKeyValue(_, _ []byte) error {
+	return nil
+}
+
+// HasNext implements the RowEventDecoder interface.
+func (b *batchDecoder) HasNext() (model.MessageType, bool, error) {
+	if b.next >= len(b.rows) {
+		return model.MessageTypeUnknown, false, nil
+	}
+	b.next++
+	return model.MessageTypeRow, true, nil
+}
+
+// NextResolvedEvent implements the RowEventDecoder interface.
+func (b *batchDecoder) NextResolvedEvent() (uint64, error) {
+	return 0, nil
+}
+
+// NextRowChangedEvent implements the RowEventDecoder interface.
+func (b *batchDecoder) NextRowChangedEvent() (*model.RowChangedEvent, error) {
+	if b.next == 0 {
+		return nil, cerror.WrapError(cerror.ErrParquetDecodeFailed,
+			errors.New("no parquet row can be found"))
+	}
+	row := b.rows[b.next-1]
+	op, ok := row[0].(string)
+	if !ok {
+		return nil, cerror.ErrParquetDecodeFailed.GenWithStack(
+			"invalid operation %v", row[0])
+	}
+	commitTs, ok := row[1].(int64)
+	if !ok {
+		return nil, cerror.ErrParquetDecodeFailed.GenWithStack(
+			"invalid commit ts %v", row[1])
+	}
+
+	zeroTimes := make(map[int]string)
+	for i, idx := range b.zeroColumns {
+		if v, ok := row[metaColumnCount+i].(string); ok {
+			zeroTimes[idx] = v
+		}
+	}
+	cols := make([]*model.ColumnData, 0, len(b.columns))
+	for i, col := range b.columns {
+		if col == nil {
+			continue
+		}
+		if zero, ok := zeroTimes[i]; ok {
+			cols = append(cols, &model.ColumnData{ColumnID: col.ID, Value: zero})
+			continue
+		}
+		value, err := fromParquetValue(&col.FieldType,
+			row[metaColumnCount+i], b.codecConfig.TimeZone)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrParquetDecodeFailed, err)
+		}
+		cols = append(cols, &model.ColumnData{ColumnID: col.ID, Value: value})
+	}
+
+	e := &model.RowChangedEvent{
+		CommitTs:  uint64(commitTs),
+		TableInfo: b.tableInfo,
+	}
+	switch op {
+	case "D":
+		e.PreColumns = cols
+	case "I", "U":
+		e.Columns = cols
+	default:
+		return nil, cerror.ErrParquetDecodeFailed.GenWithStack(
+			"invalid operation %s", op)
+	}
+	return e, nil
+}
+
+// NextDDLEvent implements the RowEventDecoder interface.
+func (b *batchDecoder) NextDDLEvent() (*model.DDLEvent, error) {
+	return nil, nil
+}
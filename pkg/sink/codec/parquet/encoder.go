@@ -0,0 +1,225 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"sort"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	pparquet "github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// Encoder encodes the row changed events of a table into a parquet file.
+// Unlike the other protocols, a parquet file can not be built by concatenating
+// the encoded messages, so all the rows of a data file are buffered and
+// encoded at once.
+type Encoder struct {
+	config  *common.Config
+	columns []*timodel.ColumnInfo
+	// offsets maps the column id to the index in columns.
+	offsets map[int64]int
+	rows    [][]interface{}
+	// zeroTimes are the zero dates of the rows by the column index, nil if
+	// the row has no zero date.
+	zeroTimes []map[int]string
+	// zeroColumns are the indexes of the columns which have zero dates.
+	zeroColumns map[int]struct{}
+}
+
+// NewEncoder creates a parquet Encoder for the table.
+func NewEncoder(config *common.Config, tableInfo *model.TableInfo) *Encoder {
+	e := &Encoder{
+		config:      config,
+		offsets:     make(map[int64]int),
+		zeroColumns: make(map[int]struct{}),
+	}
+	for _, col := range tableInfo.Columns {
+		if !model.IsColCDCVisible(col) {
+			continue
+		}
+		e.offsets[col.ID] = len(e.columns)
+		e.columns = append(e.columns, col)
+	}
+	return e
+}
+
+// AppendTxnEvent appends all the rows of the txn.
+func (e *Encoder) AppendTxnEvent(txn *model.SingleTableTxn) error {
+	for _, row := range txn.Rows {
+		if err := e.AppendRowChangedEvent(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendRowChangedEvent appends a row changed event. Like the csv protocol,
+// an update event is written as a "D" row and an "I" row if the old value is
+// required.
+func (e *Encoder) AppendRowChangedEvent(row *model.RowChangedEvent) error {
+	switch {
+	case row.IsDelete():
+		return e.appendRow("D", row.CommitTs, row.PreColumns)
+	case row.IsUpdate() && e.config.OutputOldValue:
+		if err := e.appendRow("D", row.CommitTs, row.PreColumns); err != nil {
+			return err
+		}
+		return e.appendRow("I", row.CommitTs, row.Columns)
+	case row.IsUpdate():
+		return e.appendRow("U", row.CommitTs, row.Columns)
+	default:
+		return e.appendRow("I", row.CommitTs, row.Columns)
+	}
+}
+
+func (e *Encoder) appendRow(op string, commitTs uint64, cols []*model.ColumnData) error {
+	record := make([]interface{}, metaColumnCount+len(e.columns))
+	record[0] = op
+	record[1] = int64(commitTs)
+	var zeroTimes map[int]string
+	for _, col := range cols {
+		// column could be nil in a condition described in
+		// https://github.com/pingcap/tiflow/issues/6198#issuecomment-1191132951
+		if col == nil {
+			continue
+		}
+		idx, ok := e.offsets[col.ColumnID]
+		if !ok {
+			continue
+		}
+		ft := &e.columns[idx].FieldType
+		if col.Value != nil && isTimeType(ft) && isZeroTime(toString(col.Value)) {
+			if zeroTimes == nil {
+				zeroTimes = make(map[int]string)
+			}
+			zeroTimes[idx] = toString(col.Value)
+			e.zeroColumns[idx] = struct{}{}
+			continue
+		}
+		v, err := toParquetValue(ft, col.Value, e.config.TimeZone)
+		if err != nil {
+			return cerror.WrapError(cerror.ErrParquetEncodeFailed, err)
+		}
+		record[metaColumnCount+idx] = v
+	}
+	e.rows = append(e.rows, record)
+	e.zeroTimes = append(e.zeroTimes, zeroTimes)
+	return nil
+}
+
+// Len returns the number of the buffered rows.
+func (e *Encoder) Len() int {
+	return len(e.rows)
+}
+
+// Encode encodes the buffered rows into a parquet file and resets the encoder.
+func (e *Encoder) Encode() ([]byte, error) {
+	md := make([]string, 0, metaColumnCount+len(e.columns))
+	md = append(md,
+		"name="+OperationColumn+", inname=Op, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=REQUIRED",
+		"name="+CommitTsColumn+", inname=CommitTs, type=INT64, convertedtype=UINT_64, repetitiontype=REQUIRED")
+	kvs := make([]*pparquet.KeyValue, 0, len(e.columns))
+	for i, col := range e.columns {
+		md = append(md, columnMeta(i, col.Name.O, &col.FieldType))
+		mysqlType := col.FieldType.InfoSchemaStr()
+		kvs = append(kvs, &pparquet.KeyValue{
+			Key:   mysqlTypeKeyPrefix + col.Name.O,
+			Value: &mysqlType,
+		})
+	}
+	// the zero dates are written to the extra columns after the data columns.
+	zeroColumns := make([]int, 0, len(e.zeroColumns))
+	for idx := range e.zeroColumns {
+		zeroColumns = append(zeroColumns, idx)
+	}
+	sort.Ints(zeroColumns)
+	for _, idx := range zeroColumns {
+		md = append(md, zeroTimeColumnMeta(idx, e.columns[idx].Name.O))
+	}
+
+	buf := new(bytes.Buffer)
+	w, err := writer.NewCSVWriterFromWriter(md, buf, 1)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrParquetEncodeFailed, err)
+	}
+	w.Footer.KeyValueMetadata = kvs
+	for i, row := range e.rows {
+		for _, idx := range zeroColumns {
+			var v interface{}
+			if zero, ok := e.zeroTimes[i][idx]; ok {
+				v = zero
+			}
+			row = append(row, v)
+		}
+		if err := w.Write(row); err != nil {
+			return nil, cerror.WrapError(cerror.ErrParquetEncodeFailed, err)
+		}
+	}
+	if err := w.WriteStop(); err != nil {
+		return nil, cerror.WrapError(cerror.ErrParquetEncodeFailed, err)
+	}
+	e.rows = nil
+	e.zeroTimes = nil
+	e.zeroColumns = make(map[int]struct{})
+	return buf.Bytes(), nil
+}
+
+// txnEventEncoder only builds the messages which carry the row count and the
+// callback of the txns, the rows are encoded by Encoder when the data file
+// is flushed.
+type txnEventEncoder struct {
+	messages []*common.Message
+}
+
+// AppendTxnEvent implements the TxnEventEncoder interface
+func (b *txnEventEncoder) AppendTxnEvent(
+	e *model.SingleTableTxn,
+	callback func(),
+) error {
+	if len(e.Rows) == 0 {
+		return nil
+	}
+	msg := common.NewMsg(config.ProtocolParquet, nil, nil, e.CommitTs,
+		model.MessageTypeRow, e.TableInfo.GetSchemaNamePtr(), e.TableInfo.GetTableNamePtr())
+	msg.SetRowsCount(len(e.Rows))
+	msg.Callback = callback
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+// Build implements the TxnEventEncoder interface
+func (b *txnEventEncoder) Build() []*common.Message {
+	ret := b.messages
+	b.messages = nil
+	return ret
+}
+
+type txnEventEncoderBuilder struct{}
+
+// NewTxnEventEncoderBuilder creates a parquet txnEventEncoderBuilder.
+func NewTxnEventEncoderBuilder(_ *common.Config) codec.TxnEventEncoderBuilder {
+	return &txnEventEncoderBuilder{}
+}
+
+// Build a parquet txnEventEncoder
+func (b *txnEventEncoderBuilder) Build() codec.TxnEventEncoder {
+	return &txnEventEncoder{}
+}
@@ -0,0 +1,421 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/pkg/parser/charset"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/types"
+	"github.com/pingcap/tiflow/cdc/model"
+)
+
+const (
+	// OperationColumn is the name of the column which records the operation
+	// type of the row, the value is one of "I", "U" and "D".
+	OperationColumn = "_tidb_op"
+	// CommitTsColumn is the name of the column which records the commit ts of
+	// the row.
+	CommitTsColumn = "_tidb_commit_ts"
+	// ZeroTimeColumnPrefix is the name prefix of the string columns which
+	// record the zero dates of the DATE, DATETIME and TIMESTAMP columns, e.g.
+	// `_tidb_zero_c1` for the column c1. The zero dates can not be represented
+	// by the parquet logical types, so they are written as null in the data
+	// column and as strings in this column. The column only exists in the
+	// files which contain zero dates of the data column.
+	ZeroTimeColumnPrefix = "_tidb_zero_"
+	// metaColumnCount is the number of metadata columns ahead of the data columns.
+	metaColumnCount = 2
+
+	// mysqlTypeKeyPrefix is the prefix of the key-value metadata in the file
+	// footer which records the MySQL type of each column, such as
+	// `tidb.mysql_type.c1 = bigint(20) unsigned`.
+	mysqlTypeKeyPrefix = "tidb.mysql_type."
+
+	dateLayout     = "2006-01-02"
+	datetimeLayout = "2006-01-02 15:04:05.999999"
+)
+
+var epoch = time.Unix(0, 0).UTC()
+
+// columnName returns the name of the column in the parquet schema, ',' and
+// '=' are the separators of the parquet-go metadata so they are replaced.
+func columnName(name string) string {
+	return strings.NewReplacer(",", "_", "=", "_").Replace(name)
+}
+
+// columnMeta returns the parquet-go metadata of a column. All the data
+// columns are optional since the value may be absent, e.g. a zero date which
+// can not be represented by the DATE logical type is written as null, see
+// ZeroTimeColumnPrefix.
+func columnMeta(index int, name string, ft *types.FieldType) string {
+	prefix := fmt.Sprintf("name=%s, inname=Col%d, repetitiontype=OPTIONAL",
+		columnName(name), index)
+	unsigned := mysql.HasUnsignedFlag(ft.GetFlag())
+	switch ft.GetType() {
+	case mysql.TypeTiny:
+		if unsigned {
+			return prefix + ", type=INT32, convertedtype=UINT_8"
+		}
+		return prefix + ", type=INT32, convertedtype=INT_8"
+	case mysql.TypeShort:
+		if unsigned {
+			return prefix + ", type=INT32, convertedtype=UINT_16"
+		}
+		return prefix + ", type=INT32, convertedtype=INT_16"
+	case mysql.TypeInt24, mysql.TypeLong:
+		if unsigned {
+			return prefix + ", type=INT32, convertedtype=UINT_32"
+		}
+		return prefix + ", type=INT32, convertedtype=INT_32"
+	case mysql.TypeLonglong:
+		if unsigned {
+			return prefix + ", type=INT64, convertedtype=UINT_64"
+		}
+		return prefix + ", type=INT64, convertedtype=INT_64"
+	case mysql.TypeYear:
+		return prefix + ", type=INT32, convertedtype=INT_16"
+	case mysql.TypeBit:
+		return prefix + ", type=INT64, convertedtype=UINT_64"
+	case mysql.TypeFloat:
+		return prefix + ", type=FLOAT"
+	case mysql.TypeDouble:
+		return prefix + ", type=DOUBLE"
+	case mysql.TypeNewDecimal:
+		precision, scale := decimalPrecisionAndScale(ft)
+		decimal := fmt.Sprintf("convertedtype=DECIMAL, precision=%d, scale=%d", precision, scale)
+		switch {
+		case precision <= 9:
+			return prefix + ", type=INT32, " + decimal
+		case precision <= 18:
+			return prefix + ", type=INT64, " + decimal
+		default:
+			return fmt.Sprintf("%s, type=FIXED_LEN_BYTE_ARRAY, length=%d, %s",
+				prefix, decimalLength(precision), decimal)
+		}
+	case mysql.TypeDate:
+		return prefix + ", type=INT32, convertedtype=DATE"
+	case mysql.TypeDatetime:
+		return prefix + ", type=INT64, logicaltype=TIMESTAMP, " +
+			"logicaltype.isadjustedtoutc=false, logicaltype.unit=MICROS"
+	case mysql.TypeTimestamp:
+		return prefix + ", type=INT64, logicaltype=TIMESTAMP, " +
+			"logicaltype.isadjustedtoutc=true, logicaltype.unit=MICROS"
+	case mysql.TypeJSON:
+		return prefix + ", type=BYTE_ARRAY, convertedtype=JSON"
+	case mysql.TypeEnum:
+		return prefix + ", type=BYTE_ARRAY, convertedtype=ENUM"
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString, mysql.TypeTinyBlob,
+		mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		if ft.GetCharset() == charset.CharsetBin {
+			return prefix + ", type=BYTE_ARRAY"
+		}
+		return prefix + ", type=BYTE_ARRAY, convertedtype=UTF8"
+	default:
+		// TIME, SET and the other types are written as strings.
+		return prefix + ", type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+}
+
+func decimalPrecisionAndScale(ft *types.FieldType) (int, int) {
+	precision, scale := ft.GetFlen(), ft.GetDecimal()
+	if precision <= 0 {
+		precision = mysql.MaxDecimalWidth
+	}
+	if scale < 0 {
+		scale = 0
+	}
+	return precision, scale
+}
+
+// decimalLength returns the minimum number of bytes to store a decimal with
+// the given precision.
+func decimalLength(precision int) int {
+	return int(math.Ceil((float64(precision)*math.Log2(10) + 1) / 8))
+}
+
+// toParquetValue converts the value of a row changed event column to the go
+// type used by parquet-go for the physical type of the column.
+func toParquetValue(ft *types.FieldType, value interface{}, tz *time.Location) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch ft.GetType() {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeYear:
+		v, err := toInt64(value)
+		if err != nil {
+			return nil, err
+		}
+		// the unsigned values are stored in the same bits.
+		return int32(v), nil
+	case mysql.TypeLonglong, mysql.TypeBit:
+		return toInt64(value)
+	case mysql.TypeFloat:
+		v, err := toFloat64(value)
+		return float32(v), err
+	case mysql.TypeDouble:
+		return toFloat64(value)
+	case mysql.TypeNewDecimal:
+		precision, scale := decimalPrecisionAndScale(ft)
+		return toDecimal(toString(value), precision, scale)
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		return toTimeValue(ft, toString(value), tz)
+	case mysql.TypeEnum:
+		if v, ok := value.(uint64); ok {
+			enum, err := types.ParseEnumValue(ft.GetElems(), v)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return enum.Name, nil
+		}
+	case mysql.TypeSet:
+		if v, ok := value.(uint64); ok {
+			set, err := types.ParseSetValue(ft.GetElems(), v)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return set.Name, nil
+		}
+	}
+	return toString(value), nil
+}
+
+// toTimeValue converts a DATE, DATETIME or TIMESTAMP value, a zero date is
+// converted to null and should be recorded by the caller.
+func toTimeValue(ft *types.FieldType, str string, tz *time.Location) (interface{}, error) {
+	if isZeroTime(str) {
+		return nil, nil
+	}
+	if ft.GetType() == mysql.TypeDate {
+		t, err := time.ParseInLocation(dateLayout, str, time.UTC)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return int32(t.Sub(epoch).Hours() / 24), nil
+	}
+	loc := time.UTC
+	if ft.GetType() == mysql.TypeTimestamp {
+		loc = tz
+	}
+	t, err := time.ParseInLocation(datetimeLayout, str, loc)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return t.UnixMicro(), nil
+}
+
+// isZeroTime returns true if the value is a zero date, such as `0000-00-00`
+// and `0000-00-00 00:00:00.000`.
+func isZeroTime(str string) bool {
+	return str != "" && strings.Trim(str, "0-:. ") == ""
+}
+
+// isTimeType returns true for the types which may have zero dates.
+func isTimeType(ft *types.FieldType) bool {
+	switch ft.GetType() {
+	case mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp:
+		return true
+	}
+	return false
+}
+
+// zeroTimeColumnMeta returns the parquet-go metadata of the column which
+// records the zero dates of the data column.
+func zeroTimeColumnMeta(index int, name string) string {
+	return fmt.Sprintf("name=%s%s, inname=Zero%d, type=BYTE_ARRAY, "+
+		"convertedtype=UTF8, repetitiontype=OPTIONAL",
+		ZeroTimeColumnPrefix, columnName(name), index)
+}
+
+// fromParquetValue converts the value read from parquet to the value of a
+// row changed event column, the result is the same as the csv decoder.
+func fromParquetValue(ft *types.FieldType, value interface{}, tz *time.Location) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	unsigned := mysql.HasUnsignedFlag(ft.GetFlag())
+	switch ft.GetType() {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeYear:
+		v, ok := value.(int32)
+		if !ok {
+			break
+		}
+		// YEAR has the unsigned flag, but its value is int64.
+		if unsigned && ft.GetType() != mysql.TypeYear {
+			return uint64(uint32(v)), nil
+		}
+		return int64(v), nil
+	case mysql.TypeLonglong, mysql.TypeBit:
+		v, ok := value.(int64)
+		if !ok {
+			break
+		}
+		if unsigned || ft.GetType() == mysql.TypeBit {
+			return uint64(v), nil
+		}
+		return v, nil
+	case mysql.TypeFloat:
+		if v, ok := value.(float32); ok {
+			return float64(v), nil
+		}
+	case mysql.TypeDouble:
+		if v, ok := value.(float64); ok {
+			return v, nil
+		}
+	case mysql.TypeNewDecimal:
+		_, scale := decimalPrecisionAndScale(ft)
+		return fromDecimal(value, scale)
+	case mysql.TypeDate:
+		if v, ok := value.(int32); ok {
+			return epoch.AddDate(0, 0, int(v)).Format(dateLayout), nil
+		}
+	case mysql.TypeDatetime:
+		if v, ok := value.(int64); ok {
+			return formatTime(time.UnixMicro(v).UTC(), ft.GetDecimal()), nil
+		}
+	case mysql.TypeTimestamp:
+		if v, ok := value.(int64); ok {
+			return formatTime(time.UnixMicro(v).In(tz), ft.GetDecimal()), nil
+		}
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString, mysql.TypeTinyBlob,
+		mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		if v, ok := value.(string); ok {
+			return []byte(v), nil
+		}
+	default:
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+	}
+	return nil, errors.Errorf("unexpected parquet value %v(%T) for type %s",
+		value, value, ft.String())
+}
+
+func formatTime(t time.Time, fsp int) string {
+	layout := "2006-01-02 15:04:05"
+	if fsp > 0 {
+		layout += "." + strings.Repeat("0", fsp)
+	}
+	return t.Format(layout)
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return model.ColumnValueString(value)
+	}
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	default:
+		return strconv.ParseInt(toString(value), 10, 64)
+	}
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return strconv.ParseFloat(toString(value), 64)
+	}
+}
+
+// toDecimal converts the value to the unscaled decimal value, which is stored
+// as INT32, INT64 or a big-endian two's complement FIXED_LEN_BYTE_ARRAY
+// according to the precision.
+func toDecimal(str string, precision, scale int) (interface{}, error) {
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid decimal value %s", str)
+	}
+
+	switch {
+	case precision <= 9:
+		return int32(unscaled.Int64()), nil
+	case precision <= 18:
+		return unscaled.Int64(), nil
+	}
+	length := decimalLength(precision)
+	b := make([]byte, length)
+	if unscaled.Sign() >= 0 {
+		unscaled.FillBytes(b)
+	} else {
+		// two's complement of the negative value.
+		complement := new(big.Int).Lsh(big.NewInt(1), uint(length*8))
+		complement.Add(complement, unscaled)
+		complement.FillBytes(b)
+	}
+	return string(b), nil
+}
+
+// fromDecimal converts the unscaled decimal value to the decimal string.
+func fromDecimal(value interface{}, scale int) (string, error) {
+	unscaled := new(big.Int)
+	switch v := value.(type) {
+	case int32:
+		unscaled.SetInt64(int64(v))
+	case int64:
+		unscaled.SetInt64(v)
+	case string:
+		unscaled.SetBytes([]byte(v))
+		if len(v) > 0 && v[0]&0x80 != 0 {
+			// the value is negative in two's complement.
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(len(v)*8)))
+		}
+	default:
+		return "", errors.Errorf("unexpected parquet decimal value %v(%T)", value, value)
+	}
+
+	digits := new(big.Int).Abs(unscaled).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if unscaled.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits, nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiflow/cdc/entry"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func decodeAll(
+	t *testing.T, codecConfig *common.Config, tableInfo *model.TableInfo, data []byte,
+) []*model.RowChangedEvent {
+	decoder, err := NewBatchDecoder(context.Background(), codecConfig, tableInfo, data)
+	require.NoError(t, err)
+	var events []*model.RowChangedEvent
+	for {
+		tp, hasNext, err := decoder.HasNext()
+		require.NoError(t, err)
+		if !hasNext {
+			break
+		}
+		require.Equal(t, model.MessageTypeRow, tp)
+		event, err := decoder.NextRowChangedEvent()
+		require.NoError(t, err)
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestParquetCodec(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	helper.Tk().MustExec("use test")
+	helper.Tk().MustExec("set sql_mode = ''")
+	helper.Tk().MustExec("set time_zone = 'UTC'")
+	ddl := helper.DDL2Event(`create table t(
+		id int primary key, a tinyint unsigned, b bigint unsigned, c bit(8), d year,
+		e float, f double, g decimal(10, 2), h decimal(30, 5), i date, j datetime(3),
+		k timestamp(6) null, l time, m json, n enum('x', 'y'), o set('x', 'y'),
+		p varchar(16), q blob, r varbinary(8), s int as (id + 1) virtual)`)
+	insert := helper.DML2Event(`insert into t(id, a, b, c, d, e, f, g, h, i, j, k, l, m, n, o, p, q, r)
+		values (1, 255, 18446744073709551615, b'101', 2024, 1.5, -2.25, -12.34,
+		123456789012345678901234.56789, '2024-02-29', '2024-01-02 03:04:05.678',
+		'2024-01-02 03:04:05.123456', '-838:59:59', '{"k": 1}', 'y', 'x,y',
+		'hello', 'world', x'0102')`, "test", "t")
+	update := helper.DML2Event(`update t set p = 'updated' where id = 1`, "test", "t")
+	zero := helper.DML2Event(`insert into t(id, i, j) values (2, '0000-00-00', '0000-00-00 00:00:00')`, "test", "t")
+	update.PreColumns = insert.Columns
+	del := &model.RowChangedEvent{
+		CommitTs:   update.CommitTs + 1,
+		TableInfo:  zero.TableInfo,
+		PreColumns: zero.Columns,
+	}
+
+	codecConfig := common.NewConfig(config.ProtocolParquet)
+	codecConfig.TimeZone = time.UTC
+	encoder := NewEncoder(codecConfig, ddl.TableInfo)
+	require.NoError(t, encoder.AppendTxnEvent(&model.SingleTableTxn{
+		TableInfo: ddl.TableInfo,
+		Rows:      []*model.RowChangedEvent{insert, zero, update, del},
+	}))
+	require.Equal(t, 4, encoder.Len())
+	data, err := encoder.Encode()
+	require.NoError(t, err)
+	require.Equal(t, 0, encoder.Len())
+
+	// the MySQL types are recorded in the footer.
+	pf, err := buffer.NewBufferFile(data)
+	require.NoError(t, err)
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	require.NoError(t, err)
+	mysqlTypes := make(map[string]string)
+	for _, kv := range pr.Footer.KeyValueMetadata {
+		mysqlTypes[kv.Key] = *kv.Value
+	}
+	pr.ReadStop()
+	require.Equal(t, "bigint(20) unsigned", mysqlTypes[mysqlTypeKeyPrefix+"b"])
+	require.Equal(t, "datetime(3)", mysqlTypes[mysqlTypeKeyPrefix+"j"])
+	require.Equal(t, "decimal(30,5)", mysqlTypes[mysqlTypeKeyPrefix+"h"])
+	require.NotContains(t, mysqlTypes, mysqlTypeKeyPrefix+"s")
+	// the zero dates are recorded in the extra columns.
+	var names []string
+	for _, info := range pr.SchemaHandler.Infos[1:] {
+		names = append(names, info.ExName)
+	}
+	require.Equal(t, []string{ZeroTimeColumnPrefix + "i", ZeroTimeColumnPrefix + "j"},
+		names[len(names)-2:])
+
+	events := decodeAll(t, codecConfig, ddl.TableInfo, data)
+	require.Len(t, events, 4)
+	values := func(cols []*model.ColumnData) map[string]interface{} {
+		res := make(map[string]interface{})
+		for _, col := range cols {
+			res[ddl.TableInfo.ForceGetColumnName(col.ColumnID)] = col.Value
+		}
+		return res
+	}
+	expected := map[string]interface{}{
+		"id": int64(1),
+		"a":  uint64(255),
+		"b":  uint64(18446744073709551615),
+		"c":  uint64(5),
+		"d":  int64(2024),
+		"e":  float64(1.5),
+		"f":  float64(-2.25),
+		"g":  "-12.34",
+		"h":  "123456789012345678901234.56789",
+		"i":  "2024-02-29",
+		"j":  "2024-01-02 03:04:05.678",
+		"k":  "2024-01-02 03:04:05.123456",
+		"l":  "-838:59:59",
+		"m":  `{"k": 1}`,
+		"n":  "y",
+		"o":  "x,y",
+		"p":  []byte("hello"),
+		"q":  []byte("world"),
+		"r":  []byte{1, 2},
+	}
+	require.Equal(t, insert.CommitTs, events[0].CommitTs)
+	require.Equal(t, expected, values(events[0].Columns))
+	require.Nil(t, events[0].PreColumns)
+
+	// the zero dates are read from the extra columns.
+	require.Equal(t, "0000-00-00", values(events[1].Columns)["i"])
+	require.Equal(t, "0000-00-00 00:00:00.000", values(events[1].Columns)["j"])
+	require.Nil(t, values(events[1].Columns)["k"])
+
+	require.Equal(t, update.CommitTs, events[2].CommitTs)
+	expected["p"] = []byte("updated")
+	require.Equal(t, expected, values(events[2].Columns))
+
+	require.True(t, events[3].IsDelete())
+	require.Equal(t, int64(2), values(events[3].PreColumns)["id"])
+	require.Equal(t, "0000-00-00", values(events[3].PreColumns)["i"])
+
+	// the invalid dates other than the zero dates can not be encoded.
+	invalid := &model.RowChangedEvent{
+		CommitTs:  del.CommitTs + 1,
+		TableInfo: ddl.TableInfo,
+		Columns: []*model.ColumnData{
+			{ColumnID: ddl.TableInfo.ForceGetColumnIDByName("i"), Value: "2024-13-45"},
+		},
+	}
+	err = encoder.AppendRowChangedEvent(invalid)
+	require.ErrorIs(t, err, cerror.ErrParquetEncodeFailed)
+}
+
+func TestParquetOutputOldValue(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	ddl := helper.DDL2Event(`create table test.t(id int primary key, v varchar(16))`)
+	insert := helper.DML2Event(`insert into test.t values (1, 'a')`, "test", "t")
+	update := helper.DML2Event(`update test.t set v = 'b' where id = 1`, "test", "t")
+	update.PreColumns = insert.Columns
+
+	codecConfig := common.NewConfig(config.ProtocolParquet)
+	codecConfig.OutputOldValue = true
+	encoder := NewEncoder(codecConfig, ddl.TableInfo)
+	require.NoError(t, encoder.AppendRowChangedEvent(update))
+	data, err := encoder.Encode()
+	require.NoError(t, err)
+
+	// the update event is split into a delete event and an insert event.
+	events := decodeAll(t, codecConfig, ddl.TableInfo, data)
+	require.Len(t, events, 2)
+	require.True(t, events[0].IsDelete())
+	require.Equal(t, []byte("a"), events[0].PreColumns[1].Value)
+	require.True(t, events[1].IsInsert())
+	require.Equal(t, []byte("b"), events[1].Columns[1].Value)
+}
+
+func TestTxnEventEncoder(t *testing.T) {
+	t.Parallel()
+
+	encoder := NewTxnEventEncoderBuilder(common.NewConfig(config.ProtocolParquet)).Build()
+	tableInfo := &model.TableInfo{TableName: model.TableName{Schema: "test", Table: "t"}}
+	count := 0
+	callback := func() { count++ }
+
+	require.NoError(t, encoder.AppendTxnEvent(&model.SingleTableTxn{
+		CommitTs:  1,
+		TableInfo: tableInfo,
+	}, callback))
+	require.Nil(t, encoder.Build())
+
+	require.NoError(t, encoder.AppendTxnEvent(&model.SingleTableTxn{
+		CommitTs:  2,
+		TableInfo: tableInfo,
+		Rows:      []*model.RowChangedEvent{{}, {}},
+	}, callback))
+	msgs := encoder.Build()
+	require.Len(t, msgs, 1)
+	require.Equal(t, 2, msgs[0].GetRowsCount())
+	require.Equal(t, uint64(2), msgs[0].Ts)
+	require.Equal(t, "t", msgs[0].GetTable())
+	require.Empty(t, msgs[0].Value)
+	msgs[0].Callback()
+	require.Equal(t, 1, count)
+}
+
+func TestDecimal(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		value     string
+		precision int
+		scale     int
+	}{
+		{"0.00", 5, 2},
+		{"-0.05", 5, 2},
+		{"123.45", 10, 2},
+		{"-99999999999999.9999", 18, 4},
+		{"-1", 40, 0},
+		{"12345678901234567890123456789012345.123", 38, 3},
+		{"-0.000001", 65, 6},
+	} {
+		encoded, err := toDecimal(tc.value, tc.precision, tc.scale)
+		require.NoError(t, err)
+		decoded, err := fromDecimal(encoded, tc.scale)
+		require.NoError(t, err)
+		require.Equal(t, tc.value, decoded)
+	}
+}
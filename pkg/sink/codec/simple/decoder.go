@@ -106,7 +106,13 @@ func (d *Decoder) HasNext() (model.MessageType, bool, error) {
 	d.msg = m
 	d.value = nil
 
-	if d.msg.Data != nil || d.msg.Old != nil {
+	// the control messages are not change events, see DecodeControlMessage.
+	if m.Type == MessageTypeControl {
+		d.msg = nil
+		return model.MessageTypeUnknown, false, nil
+	}
+
+	if d.msg.Data != nil || d.msg.Old != nil || d.msg.pendingValue != nil {
 		return model.MessageTypeRow, true, nil
 	}
 
@@ -117,6 +123,23 @@ func (d *Decoder) HasNext() (model.MessageType, bool, error) {
 	return model.MessageTypeDDL, true, nil
 }
 
+// DecodeControlMessage implement the ControlMessageDecoder interface.
+func (d *Decoder) DecodeControlMessage(value []byte) (string, []byte, error) {
+	value, err := common.Decompress(d.config.LargeMessageHandle.LargeMessageHandleCompression, value)
+	if err != nil {
+		return "", nil, err
+	}
+	m := new(message)
+	if err = d.marshaller.Unmarshal(value, m); err != nil {
+		return "", nil, cerror.WrapError(cerror.ErrDecodeFailed, err)
+	}
+	if m.Type != MessageTypeControl || m.Control == nil {
+		return "", nil, cerror.ErrCodecDecode.GenWithStack(
+			"not a control message, type: %s", m.Type)
+	}
+	return m.Control.Key, m.Control.Value, nil
+}
+
 // NextResolvedEvent returns the next resolved event if exists
 func (d *Decoder) NextResolvedEvent() (uint64, error) {
 	if d.msg.Type != MessageTypeWatermark {
@@ -132,11 +155,32 @@ func (d *Decoder) NextResolvedEvent() (uint64, error) {
 
 // NextRowChangedEvent returns the next row changed event if exists
 func (d *Decoder) NextRowChangedEvent() (*model.RowChangedEvent, error) {
-	if d.msg == nil || (d.msg.Data == nil && d.msg.Old == nil) {
+	if d.msg == nil || (d.msg.Data == nil && d.msg.Old == nil && d.msg.pendingValue == nil) {
 		return nil, cerror.ErrCodecDecode.GenWithStack(
 			"invalid row changed event message")
 	}
 
+	if d.msg.pendingValue != nil {
+		if d.memo.Read(d.msg.Schema, d.msg.Table, d.msg.SchemaVersion) == nil {
+			log.Debug("table schema not found for the event, cache the event until it's received",
+				zap.String("schema", d.msg.Schema),
+				zap.String("table", d.msg.Table),
+				zap.Uint64("version", d.msg.SchemaVersion))
+			d.cachedMessages.PushBack(d.msg)
+			d.msg = nil
+			return nil, nil
+		}
+		// the table schema has been received, decode the rows again.
+		if err := d.marshaller.Unmarshal(d.msg.pendingValue, d.msg); err != nil {
+			return nil, cerror.WrapError(cerror.ErrDecodeFailed, err)
+		}
+		if d.msg.pendingValue != nil {
+			return nil, cerror.ErrCodecDecode.GenWithStack(
+				"row message of table %s.%s version %d not found",
+				d.msg.Schema, d.msg.Table, d.msg.SchemaVersion)
+		}
+	}
+
 	if d.msg.ClaimCheckLocation != "" {
 		return d.assembleClaimCheckRowChangedEvent(d.msg.ClaimCheckLocation)
 	}
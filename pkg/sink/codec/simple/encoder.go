@@ -15,6 +15,7 @@ package simple
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/pingcap/errors"
 	"github.com/pingcap/log"
@@ -22,6 +23,7 @@ import (
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/sink/codec"
+	"github.com/pingcap/tiflow/pkg/sink/codec/avro"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"github.com/pingcap/tiflow/pkg/sink/kafka/claimcheck"
 	"go.uber.org/zap"
@@ -33,11 +35,40 @@ type encoder struct {
 	config     *common.Config
 	claimCheck *claimcheck.ClaimCheck
 	marshaller marshaller
+
+	// schemaM is set if the schema registry is configured, each message
+	// is prefixed with the wire format header of its JSON Schema.
+	schemaM       *avro.JSONSchemaManager
+	controlHeader []byte
+}
+
+func (e *encoder) withRowSchemaHeader(
+	ctx context.Context, topic string, event *model.RowChangedEvent, value []byte,
+) ([]byte, error) {
+	if e.schemaM == nil {
+		return value, nil
+	}
+	subject := rowJSONSchemaSubject(topic, event.TableInfo)
+	header, err := e.schemaM.GetCachedOrRegister(ctx, subject, event.TableInfo.UpdateTS,
+		func() (string, error) {
+			return newRowJSONSchema(event.TableInfo)
+		})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return append(header, value...), nil
+}
+
+func (e *encoder) withControlSchemaHeader(value []byte) []byte {
+	if e.schemaM == nil {
+		return value
+	}
+	return append(e.controlHeader, value...)
 }
 
 // AppendRowChangedEvent implement the RowEventEncoder interface
 func (e *encoder) AppendRowChangedEvent(
-	ctx context.Context, _ string, event *model.RowChangedEvent, callback func(),
+	ctx context.Context, topic string, event *model.RowChangedEvent, callback func(),
 ) error {
 	value, err := e.marshaller.MarshalRowChangedEvent(event, false, "")
 	if err != nil {
@@ -49,6 +80,10 @@ func (e *encoder) AppendRowChangedEvent(
 	if err != nil {
 		return err
 	}
+	value, err = e.withRowSchemaHeader(ctx, topic, event, value)
+	if err != nil {
+		return err
+	}
 
 	result := &common.Message{
 		Value:    value,
@@ -77,7 +112,7 @@ func (e *encoder) AppendRowChangedEvent(
 
 	var claimCheckLocation string
 	if e.config.LargeMessageHandle.EnableClaimCheck() {
-		fileName := claimcheck.NewFileName()
+		fileName := e.claimCheck.NewFileName()
 		claimCheckLocation = e.claimCheck.FileNameWithPrefix(fileName)
 		if err = e.claimCheck.WriteMessage(ctx, result.Key, result.Value, fileName); err != nil {
 			return errors.Trace(err)
@@ -93,6 +128,10 @@ func (e *encoder) AppendRowChangedEvent(
 	if err != nil {
 		return err
 	}
+	value, err = e.withRowSchemaHeader(ctx, topic, event, value)
+	if err != nil {
+		return err
+	}
 	result.Value = value
 
 	if result.Length() <= e.config.MaxMessageBytes {
@@ -131,7 +170,11 @@ func (e *encoder) EncodeCheckpointEvent(ts uint64) (*common.Message, error) {
 
 	value, err = common.Compress(e.config.ChangefeedID,
 		e.config.LargeMessageHandle.LargeMessageHandleCompression, value)
-	return common.NewResolvedMsg(config.ProtocolSimple, nil, value, ts), err
+	if err != nil {
+		return nil, err
+	}
+	value = e.withControlSchemaHeader(value)
+	return common.NewResolvedMsg(config.ProtocolSimple, nil, value, ts), nil
 }
 
 // EncodeDDLEvent implement the DDLEventBatchEncoder interface
@@ -146,6 +189,7 @@ func (e *encoder) EncodeDDLEvent(event *model.DDLEvent) (*common.Message, error)
 	if err != nil {
 		return nil, err
 	}
+	value = e.withControlSchemaHeader(value)
 	result := common.NewDDLMsg(config.ProtocolSimple, nil, value, event)
 
 	if result.Length() > e.config.MaxMessageBytes {
@@ -158,10 +202,36 @@ func (e *encoder) EncodeDDLEvent(event *model.DDLEvent) (*common.Message, error)
 	return result, nil
 }
 
+// jsonEncoder is the encoder of the JSON encoding format, which also carries
+// the control messages, the other formats have no place for them.
+type jsonEncoder struct {
+	*encoder
+}
+
+// EncodeControlMessage implement the ControlMessageEncoder interface,
+// it's safe to be called concurrently since no message is buffered.
+func (e *jsonEncoder) EncodeControlMessage(key string, value []byte, ts uint64) (*common.Message, error) {
+	value, err := json.Marshal(newControlMessage(key, value, ts))
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrEncodeFailed, err)
+	}
+	value, err = common.Compress(e.config.ChangefeedID,
+		e.config.LargeMessageHandle.LargeMessageHandleCompression, value)
+	if err != nil {
+		return nil, err
+	}
+	value = e.withControlSchemaHeader(value)
+	return common.NewMsg(config.ProtocolSimple, []byte(key), value, ts,
+		model.MessageTypeUnknown, nil, nil), nil
+}
+
 type builder struct {
 	config     *common.Config
 	claimCheck *claimcheck.ClaimCheck
 	marshaller marshaller
+
+	schemaM       *avro.JSONSchemaManager
+	controlHeader []byte
 }
 
 // NewBuilder returns a new builder
@@ -171,21 +241,43 @@ func NewBuilder(ctx context.Context, config *common.Config) (*builder, error) {
 		return nil, errors.Trace(err)
 	}
 	m, err := newMarshaller(config)
-	return &builder{
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	b := &builder{
 		config:     config,
 		claimCheck: claimCheck,
 		marshaller: m,
-	}, errors.Trace(err)
+	}
+	if config.AvroConfluentSchemaRegistry != "" {
+		b.schemaM, err = avro.NewConfluentJSONSchemaManager(ctx, config.AvroConfluentSchemaRegistry, nil)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		// the control messages share the same schema, register it in advance.
+		b.controlHeader, err = b.schemaM.GetCachedOrRegister(ctx, controlJSONSchemaTitle, 0, newControlJSONSchema)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return b, nil
 }
 
 // Build implement the RowEventEncoderBuilder interface
 func (b *builder) Build() codec.RowEventEncoder {
-	return &encoder{
+	e := &encoder{
 		messages:   make([]*common.Message, 0, 1),
 		config:     b.config,
 		claimCheck: b.claimCheck,
 		marshaller: b.marshaller,
+
+		schemaM:       b.schemaM,
+		controlHeader: b.controlHeader,
+	}
+	if b.config.EncodingFormat == common.EncodingFormatJSON {
+		return &jsonEncoder{encoder: e}
 	}
+	return e
 }
 
 // CleanMetrics implement the RowEventEncoderBuilder interface
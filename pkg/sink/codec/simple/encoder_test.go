@@ -33,12 +33,68 @@ import (
 	"github.com/pingcap/tiflow/pkg/config"
 	"github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/integrity"
+	"github.com/pingcap/tiflow/pkg/sink/codec"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	mock_simple "github.com/pingcap/tiflow/pkg/sink/codec/simple/mock"
 	"github.com/pingcap/tiflow/pkg/sink/codec/utils"
 	"github.com/stretchr/testify/require"
 )
 
+// unwrapEncoder returns the underlying encoder of the JSON encoding format.
+func unwrapEncoder(enc codec.RowEventEncoder) *encoder {
+	if e, ok := enc.(*jsonEncoder); ok {
+		return e.encoder
+	}
+	return enc.(*encoder)
+}
+
+func TestEncodeControlMessage(t *testing.T) {
+	ctx := context.Background()
+	for _, format := range []common.EncodingFormatType{
+		common.EncodingFormatAvro,
+		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
+	} {
+		codecConfig := common.NewConfig(config.ProtocolSimple)
+		codecConfig.EncodingFormat = format
+		b, err := NewBuilder(ctx, codecConfig)
+		require.NoError(t, err)
+
+		enc, ok := b.Build().(codec.ControlMessageEncoder)
+		// only the JSON encoding format carries the control messages.
+		if format != common.EncodingFormatJSON {
+			require.False(t, ok)
+			continue
+		}
+		require.True(t, ok)
+
+		m, err := enc.EncodeControlMessage("ticdc-test", []byte(`{"a":1}`), 123)
+		require.NoError(t, err)
+		require.Equal(t, []byte("ticdc-test"), m.Key)
+		require.Equal(t, uint64(123), m.Ts)
+
+		dec, err := NewDecoder(ctx, codecConfig, nil)
+		require.NoError(t, err)
+		// the control message is skipped as an event.
+		err = dec.AddKeyValue(m.Key, m.Value)
+		require.NoError(t, err)
+		messageType, hasNext, err := dec.HasNext()
+		require.NoError(t, err)
+		require.False(t, hasNext)
+		require.Equal(t, model.MessageTypeUnknown, messageType)
+
+		key, value, err := dec.DecodeControlMessage(m.Value)
+		require.NoError(t, err)
+		require.Equal(t, "ticdc-test", key)
+		require.JSONEq(t, `{"a":1}`, string(value))
+
+		checkpoint, err := b.Build().EncodeCheckpointEvent(123)
+		require.NoError(t, err)
+		_, _, err = dec.DecodeControlMessage(checkpoint.Value)
+		require.ErrorIs(t, err, errors.ErrCodecDecode)
+	}
+}
+
 func TestEncodeCheckpoint(t *testing.T) {
 	t.Parallel()
 
@@ -47,6 +103,7 @@ func TestEncodeCheckpoint(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 
@@ -97,6 +154,7 @@ func TestEncodeDMLEnableChecksum(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		for _, compressionType := range []string{
@@ -270,6 +328,7 @@ func TestEncodeDDLSequence(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		for _, compressionType := range []string{
@@ -780,6 +839,7 @@ func TestEncodeDDLEvent(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		for _, compressionType := range []string{
@@ -934,6 +994,7 @@ func TestColumnFlags(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		b, err := NewBuilder(ctx, codecConfig)
@@ -1008,6 +1069,7 @@ func TestEncodeIntegerTypes(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		b, err := NewBuilder(ctx, codecConfig)
@@ -1090,6 +1152,7 @@ func TestEncoderOtherTypes(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		b, err := NewBuilder(ctx, codecConfig)
@@ -1229,6 +1292,7 @@ func TestEncodeBootstrapEvent(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		for _, compressionType := range []string{
@@ -1301,6 +1365,7 @@ func TestEncodeLargeEventsNormal(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		for _, compressionType := range []string{
@@ -1414,6 +1479,7 @@ func TestDDLMessageTooLarge(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		b, err := NewBuilder(context.Background(), codecConfig)
@@ -1434,6 +1500,7 @@ func TestDMLMessageTooLarge(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatAvro,
 		common.EncodingFormatJSON,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 
@@ -1478,6 +1545,7 @@ func TestLargerMessageHandleClaimCheck(t *testing.T) {
 		for _, format := range []common.EncodingFormatType{
 			common.EncodingFormatAvro,
 			common.EncodingFormatJSON,
+			common.EncodingFormatProtobuf,
 		} {
 			codecConfig.EncodingFormat = format
 			for _, compressionType := range []string{
@@ -1509,7 +1577,7 @@ func TestLargerMessageHandleClaimCheck(t *testing.T) {
 				_, err = dec.NextDDLEvent()
 				require.NoError(t, err)
 
-				enc.(*encoder).config.MaxMessageBytes = 500
+				unwrapEncoder(enc).config.MaxMessageBytes = 500
 				err = enc.AppendRowChangedEvent(ctx, "", updateEvent, func() {})
 				require.NoError(t, err)
 
@@ -1582,6 +1650,7 @@ func TestLargeMessageHandleKeyOnly(t *testing.T) {
 	for _, format := range []common.EncodingFormatType{
 		common.EncodingFormatJSON,
 		common.EncodingFormatAvro,
+		common.EncodingFormatProtobuf,
 	} {
 		codecConfig.EncodingFormat = format
 		for _, compressionType := range []string{
@@ -1599,8 +1668,13 @@ func TestLargeMessageHandleKeyOnly(t *testing.T) {
 			dec, err := NewDecoder(ctx, codecConfig, db)
 			require.NoError(t, err)
 
-			enc.(*encoder).config.MaxMessageBytes = 500
-			dec.config.MaxMessageBytes = 500
+			maxMessageBytes := 500
+			if format == common.EncodingFormatProtobuf {
+				// the protobuf encoded message is much smaller.
+				maxMessageBytes = 200
+			}
+			unwrapEncoder(enc).config.MaxMessageBytes = maxMessageBytes
+			dec.config.MaxMessageBytes = maxMessageBytes
 			for _, event = range events {
 				err = enc.AppendRowChangedEvent(ctx, "", event, func() {})
 				require.NoError(t, err)
@@ -1617,47 +1691,52 @@ func TestLargeMessageHandleKeyOnly(t *testing.T) {
 				require.Equal(t, model.MessageTypeRow, messageType)
 				require.True(t, dec.msg.HandleKeyOnly)
 
-				obtainedValues := make(map[string]interface{}, len(dec.msg.Data))
-				for name, value := range dec.msg.Data {
-					obtainedValues[name] = value
-				}
-				for _, col := range event.Columns {
-					colName := event.TableInfo.ForceGetColumnName(col.ColumnID)
-					colFlag := event.TableInfo.ForceGetColumnFlagType(col.ColumnID)
-					if colFlag.IsHandleKey() {
-						require.Contains(t, dec.msg.Data, colName)
-						obtained := obtainedValues[colName]
-						switch v := obtained.(type) {
-						case string:
-							var err error
-							obtained, err = strconv.ParseInt(v, 10, 64)
-							require.NoError(t, err)
+				if format == common.EncodingFormatProtobuf {
+					// the rows are decoded after the table schema is received.
+					require.NotNil(t, dec.msg.pendingValue)
+				} else {
+					obtainedValues := make(map[string]interface{}, len(dec.msg.Data))
+					for name, value := range dec.msg.Data {
+						obtainedValues[name] = value
+					}
+					for _, col := range event.Columns {
+						colName := event.TableInfo.ForceGetColumnName(col.ColumnID)
+						colFlag := event.TableInfo.ForceGetColumnFlagType(col.ColumnID)
+						if colFlag.IsHandleKey() {
+							require.Contains(t, dec.msg.Data, colName)
+							obtained := obtainedValues[colName]
+							switch v := obtained.(type) {
+							case string:
+								var err error
+								obtained, err = strconv.ParseInt(v, 10, 64)
+								require.NoError(t, err)
+							}
+							require.EqualValues(t, col.Value, obtained)
+						} else {
+							require.NotContains(t, dec.msg.Data, colName)
 						}
-						require.EqualValues(t, col.Value, obtained)
-					} else {
-						require.NotContains(t, dec.msg.Data, colName)
 					}
-				}
 
-				clear(obtainedValues)
-				for name, value := range dec.msg.Old {
-					obtainedValues[name] = value
-				}
-				for _, col := range event.PreColumns {
-					colName := event.TableInfo.ForceGetColumnName(col.ColumnID)
-					colFlag := event.TableInfo.ForceGetColumnFlagType(col.ColumnID)
-					if colFlag.IsHandleKey() {
-						require.Contains(t, dec.msg.Old, colName)
-						obtained := obtainedValues[colName]
-						switch v := obtained.(type) {
-						case string:
-							var err error
-							obtained, err = strconv.ParseInt(v, 10, 64)
-							require.NoError(t, err)
+					clear(obtainedValues)
+					for name, value := range dec.msg.Old {
+						obtainedValues[name] = value
+					}
+					for _, col := range event.PreColumns {
+						colName := event.TableInfo.ForceGetColumnName(col.ColumnID)
+						colFlag := event.TableInfo.ForceGetColumnFlagType(col.ColumnID)
+						if colFlag.IsHandleKey() {
+							require.Contains(t, dec.msg.Old, colName)
+							obtained := obtainedValues[colName]
+							switch v := obtained.(type) {
+							case string:
+								var err error
+								obtained, err = strconv.ParseInt(v, 10, 64)
+								require.NoError(t, err)
+							}
+							require.EqualValues(t, col.Value, obtained)
+						} else {
+							require.NotContains(t, dec.msg.Data, colName)
 						}
-						require.EqualValues(t, col.Value, obtained)
-					} else {
-						require.NotContains(t, dec.msg.Data, colName)
 					}
 				}
 
@@ -1666,7 +1745,7 @@ func TestLargeMessageHandleKeyOnly(t *testing.T) {
 				require.Nil(t, decodedRow)
 			}
 
-			enc.(*encoder).config.MaxMessageBytes = config.DefaultMaxMessageBytes
+			unwrapEncoder(enc).config.MaxMessageBytes = config.DefaultMaxMessageBytes
 			dec.config.MaxMessageBytes = config.DefaultMaxMessageBytes
 			m, err := enc.EncodeDDLEvent(ddlEvent)
 			require.NoError(t, err)
@@ -1797,7 +1876,7 @@ func TestMarshallerError(t *testing.T) {
 	enc := b.Build()
 
 	mockMarshaller := mock_simple.NewMockmarshaller(gomock.NewController(t))
-	enc.(*encoder).marshaller = mockMarshaller
+	unwrapEncoder(enc).marshaller = mockMarshaller
 
 	mockMarshaller.EXPECT().MarshalCheckpoint(gomock.Any()).Return(nil, errors.ErrEncodeFailed)
 	_, err = enc.EncodeCheckpointEvent(123)
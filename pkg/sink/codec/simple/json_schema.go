@@ -0,0 +1,134 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simple
+
+import (
+	"encoding/json"
+
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/errors"
+)
+
+const (
+	jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+	// controlJSONSchemaTitle is the title of the JSON Schema of the watermark,
+	// bootstrap, DDL and control messages. Those messages are not bound to a topic,
+	// so the title is used as the subject, which follows the RecordNameStrategy.
+	controlJSONSchemaTitle = "com.pingcap.simple.Control"
+)
+
+// rowJSONSchemaSubject returns the subject of the table's JSON Schema, which
+// follows the TopicRecordNameStrategy, since a topic may hold multiple tables.
+func rowJSONSchemaSubject(topic string, tableInfo *model.TableInfo) string {
+	return topic + "-" + rowJSONSchemaTitle(tableInfo)
+}
+
+func rowJSONSchemaTitle(tableInfo *model.TableInfo) string {
+	return tableInfo.GetSchemaName() + "." + tableInfo.GetTableName()
+}
+
+func newEnvelopeJSONSchema(title string) map[string]interface{} {
+	str := map[string]interface{}{"type": "string"}
+	integer := map[string]interface{}{"type": "integer"}
+	return map[string]interface{}{
+		"$schema": jsonSchemaDraft,
+		"title":   title,
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"version":            integer,
+			"database":           str,
+			"table":              str,
+			"tableID":            integer,
+			"type":               str,
+			"sql":                str,
+			"commitTs":           integer,
+			"buildTs":            integer,
+			"schemaVersion":      integer,
+			"claimCheckLocation": str,
+			"handleKeyOnly":      map[string]interface{}{"type": "boolean"},
+			"checksum": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"version":   integer,
+					"corrupted": map[string]interface{}{"type": "boolean"},
+					"current":   integer,
+					"previous":  integer,
+				},
+			},
+			"tableSchema":    map[string]interface{}{"type": "object"},
+			"preTableSchema": map[string]interface{}{"type": "object"},
+			"control":        map[string]interface{}{"type": "object"},
+		},
+		"required": []string{"version", "type", "commitTs", "buildTs"},
+	}
+}
+
+// newControlJSONSchema returns the JSON Schema of the watermark, bootstrap, DDL and control messages.
+func newControlJSONSchema() (string, error) {
+	schema := newEnvelopeJSONSchema(controlJSONSchemaTitle)
+	value, err := json.Marshal(schema)
+	return string(value), errors.WrapError(errors.ErrEncodeFailed, err)
+}
+
+// newRowJSONSchema returns the JSON Schema of the table's DML messages,
+// the `data` and `old` fields only accept the columns of the table.
+func newRowJSONSchema(tableInfo *model.TableInfo) (string, error) {
+	columns := make(map[string]interface{})
+	for _, info := range tableInfo.GetColInfosForRowChangedEvent() {
+		nullable := !mysql.HasNotNullFlag(info.Ft.GetFlag())
+		columns[tableInfo.ForceGetColumnName(info.ID)] = newColumnJSONSchema(info.Ft.GetType(), nullable)
+	}
+	row := map[string]interface{}{
+		"type":                 "object",
+		"properties":           columns,
+		"additionalProperties": false,
+	}
+
+	schema := newEnvelopeJSONSchema(rowJSONSchemaTitle(tableInfo))
+	properties := schema["properties"].(map[string]interface{})
+	properties["type"] = map[string]interface{}{
+		"type": "string",
+		"enum": []MessageType{DMLTypeInsert, DMLTypeUpdate, DMLTypeDelete},
+	}
+	properties["data"] = row
+	properties["old"] = row
+
+	value, err := json.Marshal(schema)
+	return string(value), errors.WrapError(errors.ErrEncodeFailed, err)
+}
+
+// newColumnJSONSchema returns the JSON Schema of the column value, see encodeValue,
+// the timestamp value is an object with the location, others are all strings.
+func newColumnJSONSchema(tp byte, nullable bool) map[string]interface{} {
+	if tp == mysql.TypeTimestamp {
+		result := map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"location": map[string]interface{}{"type": "string"},
+				"value":    map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"location", "value"},
+		}
+		if nullable {
+			result["type"] = []string{"object", "null"}
+		}
+		return result
+	}
+	if nullable {
+		return map[string]interface{}{"type": []string{"string", "null"}}
+	}
+	return map[string]interface{}{"type": "string"}
+}
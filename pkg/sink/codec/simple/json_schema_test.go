@@ -0,0 +1,117 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simple
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/codec/avro"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"github.com/pingcap/tiflow/pkg/sink/codec/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeWithJSONSchemaRegistry(t *testing.T) {
+	avro.StartSchemaRegistry4Testing()
+	defer avro.StopSchemaRegistry4Testing()
+
+	ddlEvent, insertEvent, _, _ := utils.NewLargeEvent4Test(t, config.GetDefaultReplicaConfig())
+
+	ctx := context.Background()
+	codecConfig := common.NewConfig(config.ProtocolSimple)
+	codecConfig.AvroConfluentSchemaRegistry = "http://127.0.0.1:8081"
+	b, err := NewBuilder(ctx, codecConfig)
+	require.NoError(t, err)
+	enc := b.Build()
+
+	dec, err := NewDecoder(ctx, codecConfig, nil)
+	require.NoError(t, err)
+
+	m, err := enc.EncodeCheckpointEvent(100)
+	require.NoError(t, err)
+	controlID, _, err := avro.SplitConfluentJSONMessage(m.Value)
+	require.NoError(t, err)
+	require.NotZero(t, controlID)
+
+	m, err = enc.EncodeDDLEvent(ddlEvent)
+	require.NoError(t, err)
+	id, _, err := avro.SplitConfluentJSONMessage(m.Value)
+	require.NoError(t, err)
+	require.Equal(t, controlID, id)
+
+	require.NoError(t, dec.AddKeyValue(m.Key, m.Value))
+	messageType, hasNext, err := dec.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeDDL, messageType)
+	_, err = dec.NextDDLEvent()
+	require.NoError(t, err)
+
+	err = enc.AppendRowChangedEvent(ctx, "test-topic", insertEvent, func() {})
+	require.NoError(t, err)
+	messages := enc.Build()
+	require.Len(t, messages, 1)
+	rowID, payload, err := avro.SplitConfluentJSONMessage(messages[0].Value)
+	require.NoError(t, err)
+	require.NotZero(t, rowID)
+	require.NotEqual(t, controlID, rowID)
+
+	// the same table version reuses the registered schema.
+	err = enc.AppendRowChangedEvent(ctx, "test-topic", insertEvent, func() {})
+	require.NoError(t, err)
+	id, _, err = avro.SplitConfluentJSONMessage(enc.Build()[0].Value)
+	require.NoError(t, err)
+	require.Equal(t, rowID, id)
+
+	require.NoError(t, dec.AddKeyValue(messages[0].Key, messages[0].Value))
+	messageType, hasNext, err = dec.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeRow, messageType)
+	decoded, err := dec.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.Equal(t, insertEvent.CommitTs, decoded.CommitTs)
+
+	// all columns in the payload are described by the registered schema.
+	var schema struct {
+		Title      string `json:"title"`
+		Properties struct {
+			Data struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"data"`
+		} `json:"properties"`
+	}
+	rowSchema, err := newRowJSONSchema(insertEvent.TableInfo)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(rowSchema), &schema))
+	require.Equal(t, "test.t", schema.Title)
+
+	var msg message
+	require.NoError(t, json.Unmarshal(payload, &msg))
+	require.Len(t, schema.Properties.Data.Properties, len(msg.Data))
+	for name := range msg.Data {
+		require.Contains(t, schema.Properties.Data.Properties, name)
+	}
+}
+
+func TestRowJSONSchemaSubject(t *testing.T) {
+	t.Parallel()
+
+	tableInfo := model.BuildTableInfo("test", "t", nil, nil)
+	require.Equal(t, "topic-test.t", rowJSONSchemaSubject("topic", tableInfo))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/linkedin/goavro/v2"
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec/avro"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 )
 
@@ -51,6 +52,8 @@ func newMarshaller(config *common.Config) (marshaller, error) {
 		result = newJSONMarshaller(config)
 	case common.EncodingFormatAvro:
 		result, err = newAvroMarshaller(config, string(avroSchemaBytes))
+	case common.EncodingFormatProtobuf:
+		result = newProtobufMarshaller(config)
 	}
 	return result, errors.Trace(err)
 }
@@ -96,6 +99,11 @@ func (m *jsonMarshaller) MarshalRowChangedEvent(
 
 // Unmarshal implement the marshaller interface
 func (m *jsonMarshaller) Unmarshal(data []byte, v any) error {
+	// the message may be prefixed with the wire format header of the JSON Schema.
+	_, data, err := avro.SplitConfluentJSONMessage(data)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	return json.Unmarshal(data, v)
 }
 
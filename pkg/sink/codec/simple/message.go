@@ -16,6 +16,7 @@ package simple
 import (
 	"database/sql"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"sort"
 	"strconv"
@@ -51,6 +52,10 @@ const (
 	MessageTypeDDL MessageType = "DDL"
 	// MessageTypeDML is the type of the row event.
 	MessageTypeDML MessageType = "DML"
+	// MessageTypeControl is the type of the control message of TiCDC, such as
+	// the partition mapping change marker, the consumers should skip it if
+	// they don't recognize the key of the control message.
+	MessageTypeControl MessageType = "CONTROL"
 )
 
 // DML Message types
@@ -430,6 +435,8 @@ func adjustTimestampValue(column *model.ColumnData, flag types.FieldType) {
 			ts = v["value"]
 		case map[string]interface{}:
 			ts = v["value"].(string)
+		case string:
+			ts = v
 		}
 		column.Value = ts
 	}
@@ -499,6 +506,19 @@ type message struct {
 	TableSchema *TableSchema `json:"tableSchema,omitempty"`
 	// PreTableSchema holds schema information before the DDL executed.
 	PreTableSchema *TableSchema `json:"preTableSchema,omitempty"`
+	// Control is only for the control message.
+	Control *controlMessage `json:"control,omitempty"`
+
+	// pendingValue is the raw value of the DML event whose rows can't be
+	// decoded until the table schema is received, it's only used by
+	// the protobuf encoding format.
+	pendingValue []byte
+}
+
+// controlMessage is the payload of the control message, the value is in JSON.
+type controlMessage struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
 }
 
 func newResolvedMessage(ts uint64) *message {
@@ -510,6 +530,19 @@ func newResolvedMessage(ts uint64) *message {
 	}
 }
 
+func newControlMessage(key string, value []byte, ts uint64) *message {
+	return &message{
+		Version:  defaultVersion,
+		Type:     MessageTypeControl,
+		CommitTs: ts,
+		BuildTs:  time.Now().UnixMilli(),
+		Control: &controlMessage{
+			Key:   key,
+			Value: value,
+		},
+	}
+}
+
 func newBootstrapMessage(tableInfo *model.TableInfo) *message {
 	schema := newTableSchema(tableInfo)
 	msg := &message{
@@ -0,0 +1,601 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simple
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	pb "github.com/pingcap/tiflow/proto/simple"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+const (
+	// rowMessagePackage is the package of the generated row messages.
+	rowMessagePackage = "com.pingcap.simple.row"
+
+	// the field numbers 19000 to 19999 are reserved by protobuf.
+	firstReservedFieldNumber = 19000
+	lastReservedFieldNumber  = 19999
+)
+
+// invalidIdentifierCharRE matches the characters which can't be used in the
+// protobuf identifiers.
+var invalidIdentifierCharRE = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// rowDescriptor is the generated protobuf message of a version of the table.
+type rowDescriptor struct {
+	version uint64
+	// file is the serialized FileDescriptorProto which holds the message.
+	file    []byte
+	message protoreflect.MessageDescriptor
+	// fields maps the column ID to the field of the message.
+	fields map[int64]protoreflect.FieldDescriptor
+}
+
+// newRowDescriptor generates the row message from the table info.
+// Each column is mapped to an optional field, so the NULL value can be told
+// from the zero value. The column ID is used as the field number, so the
+// field of a column keeps the same number across the versions of the table.
+func newRowDescriptor(tableInfo *model.TableInfo) (*rowDescriptor, error) {
+	columns := make([]*timodel.ColumnInfo, len(tableInfo.Columns))
+	copy(columns, tableInfo.Columns)
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].ID < columns[j].ID
+	})
+
+	schema, table := tableInfo.TableName.Schema, tableInfo.TableName.Table
+	messageName := fmt.Sprintf("Row_%s_%s_%d",
+		invalidIdentifierCharRE.ReplaceAllString(schema, "_"),
+		invalidIdentifierCharRE.ReplaceAllString(table, "_"),
+		tableInfo.UpdateTS)
+	message := &descriptorpb.DescriptorProto{Name: proto.String(messageName)}
+	names := make(map[string]struct{}, len(columns))
+	for i, col := range columns {
+		if col.ID <= 0 || col.ID > int64(protowire.MaxValidNumber) ||
+			(col.ID >= firstReservedFieldNumber && col.ID <= lastReservedFieldNumber) {
+			return nil, errors.ErrEncodeFailed.GenWithStack(
+				"column %s of table %s.%s has id %d which can't be used as the field number",
+				col.Name.O, schema, table, col.ID)
+		}
+		name := fieldName(col, names)
+		names[name] = struct{}{}
+		message.OneofDecl = append(message.OneofDecl,
+			&descriptorpb.OneofDescriptorProto{Name: proto.String("_" + name)})
+		message.Field = append(message.Field, &descriptorpb.FieldDescriptorProto{
+			Name:           proto.String(name),
+			Number:         proto.Int32(int32(col.ID)),
+			Label:          descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:           protobufFieldType(&col.FieldType).Enum(),
+			JsonName:       proto.String(col.Name.O),
+			OneofIndex:     proto.Int32(int32(i)),
+			Proto3Optional: proto.Bool(true),
+		})
+	}
+
+	file := &descriptorpb.FileDescriptorProto{
+		Name:        proto.String(fmt.Sprintf("%s/%s/%d.proto", schema, table, tableInfo.UpdateTS)),
+		Package:     proto.String(rowMessagePackage),
+		Syntax:      proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{message},
+	}
+	return buildRowDescriptor(file, tableInfo.UpdateTS)
+}
+
+// buildRowDescriptor builds the row message from the file descriptor.
+func buildRowDescriptor(
+	file *descriptorpb.FileDescriptorProto, version uint64,
+) (*rowDescriptor, error) {
+	fd, err := protodesc.NewFile(file, new(protoregistry.Files))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if fd.Messages().Len() != 1 {
+		return nil, errors.ErrDecodeFailed.GenWithStack(
+			"the descriptor %s has %d messages", fd.Path(), fd.Messages().Len())
+	}
+	raw, err := proto.Marshal(file)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	message := fd.Messages().Get(0)
+	fields := make(map[int64]protoreflect.FieldDescriptor, message.Fields().Len())
+	for i := 0; i < message.Fields().Len(); i++ {
+		field := message.Fields().Get(i)
+		fields[int64(field.Number())] = field
+	}
+	return &rowDescriptor{
+		version: version,
+		file:    raw,
+		message: message,
+		fields:  fields,
+	}, nil
+}
+
+// fieldName returns the field name of the column, the column name is used if
+// it's a valid identifier, the original name is kept in the json name.
+func fieldName(col *timodel.ColumnInfo, used map[string]struct{}) string {
+	name := invalidIdentifierCharRE.ReplaceAllString(col.Name.O, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	if _, ok := used[name]; ok {
+		name = fmt.Sprintf("%s_%d", name, col.ID)
+	}
+	return name
+}
+
+// protobufFieldType returns the field type of the column.
+// The types which may lose precision, such as decimal and datetime, are
+// encoded as strings in the same way as the json format.
+func protobufFieldType(ft *types.FieldType) descriptorpb.FieldDescriptorProto_Type {
+	switch ft.GetType() {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong,
+		mysql.TypeLonglong, mysql.TypeYear:
+		if mysql.HasUnsignedFlag(ft.GetFlag()) {
+			return descriptorpb.FieldDescriptorProto_TYPE_UINT64
+		}
+		return descriptorpb.FieldDescriptorProto_TYPE_INT64
+	case mysql.TypeBit, mysql.TypeEnum, mysql.TypeSet:
+		return descriptorpb.FieldDescriptorProto_TYPE_UINT64
+	case mysql.TypeFloat:
+		return descriptorpb.FieldDescriptorProto_TYPE_FLOAT
+	case mysql.TypeDouble:
+		return descriptorpb.FieldDescriptorProto_TYPE_DOUBLE
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString,
+		mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		if mysql.HasBinaryFlag(ft.GetFlag()) {
+			return descriptorpb.FieldDescriptorProto_TYPE_BYTES
+		}
+	}
+	return descriptorpb.FieldDescriptorProto_TYPE_STRING
+}
+
+type protobufMarshaller struct {
+	config *common.Config
+
+	mu sync.Mutex
+	// encodeDescriptors caches the latest row message of each table.
+	encodeDescriptors map[int64]*rowDescriptor
+	// decodeDescriptors holds the row messages received from the bootstrap
+	// and DDL messages.
+	decodeDescriptors map[tableSchemaKey]*rowDescriptor
+}
+
+func newProtobufMarshaller(config *common.Config) *protobufMarshaller {
+	return &protobufMarshaller{
+		config:            config,
+		encodeDescriptors: make(map[int64]*rowDescriptor),
+		decodeDescriptors: make(map[tableSchemaKey]*rowDescriptor),
+	}
+}
+
+// MarshalCheckpoint implement the marshaller interface
+func (m *protobufMarshaller) MarshalCheckpoint(ts uint64) ([]byte, error) {
+	msg := &pb.Message{
+		Version:  defaultVersion,
+		Type:     string(MessageTypeWatermark),
+		CommitTs: ts,
+		BuildTs:  time.Now().UnixMilli(),
+	}
+	value, err := msg.Marshal()
+	return value, errors.WrapError(errors.ErrEncodeFailed, err)
+}
+
+// MarshalDDLEvent implement the marshaller interface
+func (m *protobufMarshaller) MarshalDDLEvent(event *model.DDLEvent) ([]byte, error) {
+	var (
+		msg *message
+		err error
+	)
+	if event.IsBootstrap {
+		msg = newBootstrapMessage(event.TableInfo)
+	} else {
+		msg = newDDLMessage(event)
+	}
+	result := &pb.Message{
+		Version:  int32(msg.Version),
+		Type:     string(msg.Type),
+		Sql:      msg.SQL,
+		CommitTs: msg.CommitTs,
+		BuildTs:  msg.BuildTs,
+	}
+	// the pre table schema goes first, so the latest version is cached.
+	if msg.PreTableSchema != nil {
+		result.PreTableSchema, err = m.newTableSchema(msg.PreTableSchema, event.PreTableInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if msg.TableSchema != nil {
+		result.TableSchema, err = m.newTableSchema(msg.TableSchema, event.TableInfo)
+		if err != nil {
+			return nil, err
+		}
+	}
+	value, err := result.Marshal()
+	return value, errors.WrapError(errors.ErrEncodeFailed, err)
+}
+
+// MarshalRowChangedEvent implement the marshaller interface
+func (m *protobufMarshaller) MarshalRowChangedEvent(
+	event *model.RowChangedEvent,
+	handleKeyOnly bool, claimCheckFileName string,
+) ([]byte, error) {
+	desc, err := m.getEncodeDescriptor(event.TableInfo)
+	if err != nil {
+		return nil, err
+	}
+	msg := &pb.Message{
+		Version:            defaultVersion,
+		Database:           event.TableInfo.GetSchemaName(),
+		Table:              event.TableInfo.GetTableName(),
+		TableId:            event.TableInfo.ID,
+		CommitTs:           event.CommitTs,
+		BuildTs:            time.Now().UnixMilli(),
+		SchemaVersion:      event.TableInfo.UpdateTS,
+		HandleKeyOnly:      handleKeyOnly,
+		ClaimCheckLocation: claimCheckFileName,
+		TimeZone:           m.config.TimeZone.String(),
+	}
+	if event.IsInsert() {
+		msg.Type = string(DMLTypeInsert)
+		msg.Data, err = m.encodeRow(desc, event.Columns, event.TableInfo, handleKeyOnly)
+	} else if event.IsDelete() {
+		msg.Type = string(DMLTypeDelete)
+		msg.Old, err = m.encodeRow(desc, event.PreColumns, event.TableInfo, handleKeyOnly)
+	} else if event.IsUpdate() {
+		msg.Type = string(DMLTypeUpdate)
+		msg.Data, err = m.encodeRow(desc, event.Columns, event.TableInfo, handleKeyOnly)
+		if err == nil {
+			msg.Old, err = m.encodeRow(desc, event.PreColumns, event.TableInfo, handleKeyOnly)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.config.EnableRowChecksum && event.Checksum != nil {
+		msg.Checksum = &pb.Checksum{
+			Version:   int32(event.Checksum.Version),
+			Corrupted: event.Checksum.Corrupted,
+			Current:   event.Checksum.Current,
+			Previous:  event.Checksum.Previous,
+		}
+	}
+	value, err := msg.Marshal()
+	return value, errors.WrapError(errors.ErrEncodeFailed, err)
+}
+
+// Unmarshal implement the marshaller interface.
+// The row messages are registered when the table schemas are received. The
+// rows of a DML message are kept undecoded if the schema of its version has
+// not been received yet, it can be unmarshalled again after that.
+func (m *protobufMarshaller) Unmarshal(data []byte, v any) error {
+	msg := new(pb.Message)
+	if err := msg.Unmarshal(data); err != nil {
+		return errors.Trace(err)
+	}
+
+	result := v.(*message)
+	*result = message{
+		Version:            int(msg.Version),
+		Schema:             msg.Database,
+		Table:              msg.Table,
+		TableID:            msg.TableId,
+		Type:               MessageType(msg.Type),
+		SQL:                msg.Sql,
+		CommitTs:           msg.CommitTs,
+		BuildTs:            msg.BuildTs,
+		SchemaVersion:      msg.SchemaVersion,
+		ClaimCheckLocation: msg.ClaimCheckLocation,
+		HandleKeyOnly:      msg.HandleKeyOnly,
+	}
+	if msg.Checksum != nil {
+		result.Checksum = &checksum{
+			Version:   int(msg.Checksum.Version),
+			Corrupted: msg.Checksum.Corrupted,
+			Current:   msg.Checksum.Current,
+			Previous:  msg.Checksum.Previous,
+		}
+	}
+
+	var err error
+	if msg.TableSchema != nil {
+		if result.TableSchema, err = m.decodeTableSchema(msg.TableSchema); err != nil {
+			return err
+		}
+	}
+	if msg.PreTableSchema != nil {
+		if result.PreTableSchema, err = m.decodeTableSchema(msg.PreTableSchema); err != nil {
+			return err
+		}
+	}
+
+	switch result.Type {
+	case DMLTypeInsert, DMLTypeUpdate, DMLTypeDelete:
+	default:
+		return nil
+	}
+	m.mu.Lock()
+	desc := m.decodeDescriptors[tableSchemaKey{
+		schema:  msg.Database,
+		table:   msg.Table,
+		version: msg.SchemaVersion,
+	}]
+	m.mu.Unlock()
+	if desc == nil {
+		result.pendingValue = data
+		return nil
+	}
+	if result.Type != DMLTypeDelete {
+		if result.Data, err = decodeRow(desc, msg.Data, msg.HandleKeyOnly); err != nil {
+			return err
+		}
+	}
+	if result.Type != DMLTypeInsert {
+		if result.Old, err = decodeRow(desc, msg.Old, msg.HandleKeyOnly); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *protobufMarshaller) getEncodeDescriptor(tableInfo *model.TableInfo) (*rowDescriptor, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	desc, ok := m.encodeDescriptors[tableInfo.ID]
+	if ok && desc.version == tableInfo.UpdateTS {
+		return desc, nil
+	}
+	desc, err := newRowDescriptor(tableInfo)
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrEncodeFailed, err)
+	}
+	m.encodeDescriptors[tableInfo.ID] = desc
+	return desc, nil
+}
+
+func (m *protobufMarshaller) newTableSchema(
+	schema *TableSchema, tableInfo *model.TableInfo,
+) (*pb.TableSchema, error) {
+	desc, err := m.getEncodeDescriptor(tableInfo)
+	if err != nil {
+		return nil, err
+	}
+	result := &pb.TableSchema{
+		Schema:        schema.Schema,
+		Table:         schema.Table,
+		TableId:       schema.TableID,
+		Version:       schema.Version,
+		Columns:       make([]*pb.ColumnSchema, 0, len(schema.Columns)),
+		Indexes:       make([]*pb.IndexSchema, 0, len(schema.Indexes)),
+		RowDescriptor: desc.file,
+		RowMessage:    string(desc.message.FullName()),
+	}
+	for _, col := range schema.Columns {
+		column := &pb.ColumnSchema{
+			Name: col.Name,
+			DataType: &pb.DataType{
+				MysqlType: col.DataType.MySQLType,
+				Charset:   col.DataType.Charset,
+				Collate:   col.DataType.Collate,
+				Length:    int32(col.DataType.Length),
+				Decimal:   int32(col.DataType.Decimal),
+				Elements:  col.DataType.Elements,
+				Unsigned:  col.DataType.Unsigned,
+				Zerofill:  col.DataType.Zerofill,
+			},
+			Nullable: col.Nullable,
+		}
+		if col.Default != nil {
+			// the default value of the bit column is an integer,
+			// the others are strings.
+			column.DefaultOneof = &pb.ColumnSchema_DefaultValue{
+				DefaultValue: fmt.Sprintf("%v", col.Default),
+			}
+		}
+		result.Columns = append(result.Columns, column)
+	}
+	for _, idx := range schema.Indexes {
+		result.Indexes = append(result.Indexes, &pb.IndexSchema{
+			Name:     idx.Name,
+			Unique:   idx.Unique,
+			Primary:  idx.Primary,
+			Nullable: idx.Nullable,
+			Columns:  idx.Columns,
+		})
+	}
+	return result, nil
+}
+
+// decodeTableSchema converts the table schema and registers its row message.
+func (m *protobufMarshaller) decodeTableSchema(schema *pb.TableSchema) (*TableSchema, error) {
+	result := &TableSchema{
+		Schema:  schema.Schema,
+		Table:   schema.Table,
+		TableID: schema.TableId,
+		Version: schema.Version,
+		Columns: make([]*columnSchema, 0, len(schema.Columns)),
+		Indexes: make([]*IndexSchema, 0, len(schema.Indexes)),
+	}
+	for _, col := range schema.Columns {
+		column := &columnSchema{
+			Name:     col.Name,
+			Nullable: col.Nullable,
+		}
+		if tp := col.DataType; tp != nil {
+			column.DataType = dataType{
+				MySQLType: tp.MysqlType,
+				Charset:   tp.Charset,
+				Collate:   tp.Collate,
+				Length:    int(tp.Length),
+				Decimal:   int(tp.Decimal),
+				Elements:  tp.Elements,
+				Unsigned:  tp.Unsigned,
+				Zerofill:  tp.Zerofill,
+			}
+		}
+		if d, ok := col.DefaultOneof.(*pb.ColumnSchema_DefaultValue); ok {
+			column.Default = d.DefaultValue
+			if column.DataType.MySQLType == "bit" {
+				v, err := strconv.ParseUint(d.DefaultValue, 10, 64)
+				if err != nil {
+					return nil, errors.WrapError(errors.ErrDecodeFailed, err)
+				}
+				column.Default = float64(v)
+			}
+		}
+		result.Columns = append(result.Columns, column)
+	}
+	for _, idx := range schema.Indexes {
+		result.Indexes = append(result.Indexes, &IndexSchema{
+			Name:     idx.Name,
+			Unique:   idx.Unique,
+			Primary:  idx.Primary,
+			Nullable: idx.Nullable,
+			Columns:  idx.Columns,
+		})
+	}
+
+	key := tableSchemaKey{schema: schema.Schema, table: schema.Table, version: schema.Version}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.decodeDescriptors[key]; ok {
+		return result, nil
+	}
+	file := new(descriptorpb.FileDescriptorProto)
+	if err := proto.Unmarshal(schema.RowDescriptor, file); err != nil {
+		return nil, errors.WrapError(errors.ErrDecodeFailed, err)
+	}
+	desc, err := buildRowDescriptor(file, schema.Version)
+	if err != nil {
+		return nil, errors.WrapError(errors.ErrDecodeFailed, err)
+	}
+	if string(desc.message.FullName()) != schema.RowMessage {
+		return nil, errors.ErrDecodeFailed.GenWithStack(
+			"row message %s not found in the descriptor", schema.RowMessage)
+	}
+	m.decodeDescriptors[key] = desc
+	return result, nil
+}
+
+// encodeRow encodes the columns by the row message, the NULL values are left unset.
+func (m *protobufMarshaller) encodeRow(
+	desc *rowDescriptor, columns []*model.ColumnData,
+	tableInfo *model.TableInfo, onlyHandleKey bool,
+) ([]byte, error) {
+	row := dynamicpb.NewMessage(desc.message)
+	colInfos := tableInfo.GetColInfosForRowChangedEvent()
+	for i, col := range columns {
+		if col == nil || col.Value == nil {
+			continue
+		}
+		if onlyHandleKey && !tableInfo.ForceGetColumnFlagType(col.ColumnID).IsHandleKey() {
+			continue
+		}
+		field, ok := desc.fields[col.ColumnID]
+		if !ok {
+			return nil, errors.ErrEncodeFailed.GenWithStack(
+				"column %d not found in the row message %s", col.ColumnID, desc.message.FullName())
+		}
+		value, err := m.protobufValue(col.Value, colInfos[i].Ft, field.Kind())
+		if err != nil {
+			return nil, errors.WrapError(errors.ErrEncodeFailed, err)
+		}
+		row.Set(field, value)
+	}
+	return proto.Marshal(row)
+}
+
+// protobufValue converts the column value to the value of the field.
+// The value is formatted in the same way as the json format, and then it's
+// parsed as the field kind.
+func (m *protobufMarshaller) protobufValue(
+	value interface{}, ft *types.FieldType, kind protoreflect.Kind,
+) (protoreflect.Value, error) {
+	if kind == protoreflect.BytesKind {
+		switch v := value.(type) {
+		case []byte:
+			return protoreflect.ValueOfBytes(v), nil
+		case string:
+			return protoreflect.ValueOfBytes([]byte(v)), nil
+		}
+	}
+
+	var s string
+	switch v := encodeValue(value, ft, m.config.TimeZone.String()).(type) {
+	case string:
+		s = v
+	case map[string]string:
+		// the timestamp value comes with the location.
+		s = v["value"]
+	}
+	switch kind {
+	case protoreflect.Int64Kind:
+		v, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint64Kind:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(s)), nil
+	default:
+		return protoreflect.ValueOfString(s), nil
+	}
+}
+
+// decodeRow decodes the row into the values keyed by the column names.
+// The unset fields are NULL values, they are omitted if only the handle key
+// columns are encoded.
+func decodeRow(desc *rowDescriptor, data []byte, onlyHandleKey bool) (map[string]interface{}, error) {
+	row := dynamicpb.NewMessage(desc.message)
+	if err := proto.Unmarshal(data, row); err != nil {
+		return nil, errors.WrapError(errors.ErrDecodeFailed, err)
+	}
+	fields := desc.message.Fields()
+	result := make(map[string]interface{}, fields.Len())
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if !row.Has(field) {
+			if !onlyHandleKey {
+				result[field.JSONName()] = nil
+			}
+			continue
+		}
+		result[field.JSONName()] = row.Get(field).Interface()
+	}
+	return result, nil
+}
@@ -0,0 +1,159 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simple
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pingcap/tiflow/cdc/entry"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	pb "github.com/pingcap/tiflow/proto/simple"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newRowMessage builds the row message from the table schema like a consumer
+// which doesn't know the TiCDC types.
+func newRowMessage(t *testing.T, schema *pb.TableSchema) protoreflect.MessageDescriptor {
+	file := new(descriptorpb.FileDescriptorProto)
+	require.NoError(t, proto.Unmarshal(schema.RowDescriptor, file))
+	fd, err := protodesc.NewFile(file, new(protoregistry.Files))
+	require.NoError(t, err)
+	message := fd.Messages().Get(0)
+	require.Equal(t, schema.RowMessage, string(message.FullName()))
+	return message
+}
+
+func TestProtobufRowDescriptor(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	helper.Tk().MustExec("use test")
+	createTableDDL := helper.DDL2Event("create table t (id int primary key, `my col` varchar(10), `1st` double, b varbinary(10))")
+	insertEvent := helper.DML2Event("insert into t values (1, '', 0, null)", "test", "t")
+	addColumnDDL := helper.DDL2Event("alter table t add column c bigint unsigned")
+
+	codecConfig := common.NewConfig(config.ProtocolSimple)
+	codecConfig.EncodingFormat = common.EncodingFormatProtobuf
+	m := newProtobufMarshaller(codecConfig)
+
+	value, err := m.MarshalDDLEvent(createTableDDL)
+	require.NoError(t, err)
+	ddl := new(pb.Message)
+	require.NoError(t, ddl.Unmarshal(value))
+	require.Equal(t, string(DDLTypeCreate), ddl.Type)
+	rowMessage := newRowMessage(t, ddl.TableSchema)
+	fields := rowMessage.Fields()
+	require.Equal(t, 4, fields.Len())
+	for i, name := range []string{"id", "my col", "1st", "b"} {
+		field := fields.Get(i)
+		require.Equal(t, name, field.JSONName())
+		require.Equal(t, protoreflect.FieldNumber(createTableDDL.TableInfo.ForceGetColumnIDByName(name)), field.Number())
+		require.True(t, field.HasPresence())
+	}
+	require.Equal(t, protoreflect.Name("my_col"), fields.Get(1).Name())
+	require.Equal(t, protoreflect.Name("_1st"), fields.Get(2).Name())
+	require.Equal(t, protoreflect.BytesKind, fields.Get(3).Kind())
+
+	value, err = m.MarshalRowChangedEvent(insertEvent, false, "")
+	require.NoError(t, err)
+	dml := new(pb.Message)
+	require.NoError(t, dml.Unmarshal(value))
+	require.Equal(t, string(DMLTypeInsert), dml.Type)
+	require.Equal(t, ddl.TableSchema.Version, dml.SchemaVersion)
+	row := dynamicpb.NewMessage(rowMessage)
+	require.NoError(t, proto.Unmarshal(dml.Data, row))
+	require.Equal(t, int64(1), row.Get(fields.ByJSONName("id")).Int())
+	// the zero values are told from the NULL values.
+	require.True(t, row.Has(fields.ByJSONName("my col")))
+	require.Equal(t, "", row.Get(fields.ByJSONName("my col")).String())
+	require.True(t, row.Has(fields.ByJSONName("1st")))
+	require.False(t, row.Has(fields.ByJSONName("b")))
+
+	// the row message is versioned by the DDL, the existing fields keep their numbers.
+	value, err = m.MarshalDDLEvent(addColumnDDL)
+	require.NoError(t, err)
+	ddl = new(pb.Message)
+	require.NoError(t, ddl.Unmarshal(value))
+	require.NotEqual(t, dml.SchemaVersion, ddl.TableSchema.Version)
+	newRowMessage := newRowMessage(t, ddl.TableSchema)
+	require.NotEqual(t, rowMessage.FullName(), newRowMessage.FullName())
+	require.Equal(t, 5, newRowMessage.Fields().Len())
+	for i := 0; i < fields.Len(); i++ {
+		field := newRowMessage.Fields().ByJSONName(fields.Get(i).JSONName())
+		require.Equal(t, fields.Get(i).Number(), field.Number())
+	}
+	require.Equal(t, protoreflect.Uint64Kind, newRowMessage.Fields().ByJSONName("c").Kind())
+	require.NotNil(t, ddl.PreTableSchema)
+	require.Equal(t, dml.SchemaVersion, ddl.PreTableSchema.Version)
+}
+
+func TestProtobufDecodeBeforeSchema(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	helper.Tk().MustExec("use test")
+	createTableDDL := helper.DDL2Event("create table t (id int primary key, a varchar(10))")
+	insertEvent := helper.DML2Event("insert into t values (1, null)", "test", "t")
+
+	ctx := context.Background()
+	codecConfig := common.NewConfig(config.ProtocolSimple)
+	codecConfig.EncodingFormat = common.EncodingFormatProtobuf
+	b, err := NewBuilder(ctx, codecConfig)
+	require.NoError(t, err)
+	enc := b.Build()
+	dec, err := NewDecoder(ctx, codecConfig, nil)
+	require.NoError(t, err)
+
+	// the row is cached until the table schema is received.
+	require.NoError(t, enc.AppendRowChangedEvent(ctx, "", insertEvent, func() {}))
+	messages := enc.Build()
+	require.Len(t, messages, 1)
+	require.NoError(t, dec.AddKeyValue(messages[0].Key, messages[0].Value))
+	messageType, hasNext, err := dec.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeRow, messageType)
+	row, err := dec.NextRowChangedEvent()
+	require.NoError(t, err)
+	require.Nil(t, row)
+
+	createTableDDL.IsBootstrap = true
+	message, err := enc.EncodeDDLEvent(createTableDDL)
+	require.NoError(t, err)
+	require.NoError(t, dec.AddKeyValue(message.Key, message.Value))
+	messageType, hasNext, err = dec.HasNext()
+	require.NoError(t, err)
+	require.True(t, hasNext)
+	require.Equal(t, model.MessageTypeDDL, messageType)
+	_, err = dec.NextDDLEvent()
+	require.NoError(t, err)
+
+	rows := dec.GetCachedEvents()
+	require.Len(t, rows, 1)
+	require.Equal(t, insertEvent.CommitTs, rows[0].CommitTs)
+	values := make(map[string]interface{})
+	for _, col := range rows[0].Columns {
+		values[rows[0].TableInfo.ForceGetColumnName(col.ColumnID)] = col.Value
+	}
+	require.Equal(t, map[string]interface{}{"id": int64(1), "a": nil}, values)
+}
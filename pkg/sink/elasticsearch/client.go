@@ -0,0 +1,318 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/retry"
+	"go.uber.org/zap"
+)
+
+const (
+	// versionType makes the document with a smaller version, which is the
+	// commit ts of the row, be rejected, so the stale updates can't win.
+	// The equal version is accepted because the delete and the insert split
+	// from the same update share the commit ts.
+	versionType = "external_gte"
+
+	// maxResponseBodySize is the max size of the response body kept in the error.
+	maxResponseBodySize = 1024
+)
+
+// BulkRequest is the request of the bulk API, which is a sequence of the
+// actions and the document sources as newline delimited JSON.
+type BulkRequest struct {
+	buf   bytes.Buffer
+	count int
+}
+
+type bulkAction struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     uint64 `json:"version"`
+	VersionType string `json:"version_type"`
+}
+
+// Index appends an action which replaces the whole document.
+func (r *BulkRequest) Index(index, id string, version uint64, doc map[string]interface{}) error {
+	if err := r.appendAction("index", index, id, version); err != nil {
+		return err
+	}
+	return r.appendLine(doc)
+}
+
+// Delete appends an action which deletes the document.
+func (r *BulkRequest) Delete(index, id string, version uint64) error {
+	return r.appendAction("delete", index, id, version)
+}
+
+// Len returns the number of the actions in the request.
+func (r *BulkRequest) Len() int {
+	return r.count
+}
+
+func (r *BulkRequest) appendAction(op, index, id string, version uint64) error {
+	r.count++
+	return r.appendLine(map[string]bulkAction{op: {
+		Index:       index,
+		ID:          id,
+		Version:     version,
+		VersionType: versionType,
+	}})
+}
+
+func (r *BulkRequest) appendLine(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+	}
+	r.buf.Write(data)
+	r.buf.WriteByte('\n')
+	return nil
+}
+
+type bulkResponse struct {
+	Errors bool                          `json:"errors"`
+	Items  []map[string]bulkResponseItem `json:"items"`
+}
+
+type bulkResponseItem struct {
+	Index  string          `json:"_index"`
+	ID     string          `json:"_id"`
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// Client sends the requests to the elasticsearch cluster.
+type Client struct {
+	changefeedID model.ChangeFeedID
+	config       *Config
+	client       *http.Client
+}
+
+// NewClient creates an elasticsearch client.
+func NewClient(changefeedID model.ChangeFeedID, cfg *Config) *Client {
+	return &Client{
+		changefeedID: changefeedID,
+		config:       cfg,
+		client:       &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Bulk sends the bulk request, it retries with backoff until all the actions
+// succeed or the max retries is reached. The actions are idempotent thanks to
+// the external versioning, so the whole request is retried.
+func (c *Client) Bulk(ctx context.Context, req *BulkRequest) error {
+	return c.withRetry(ctx, func() error {
+		body, err := c.do(ctx, http.MethodPost, "/_bulk", req.buf.Bytes())
+		if err != nil {
+			return err
+		}
+		resp := &bulkResponse{}
+		if err := json.Unmarshal(body, resp); err != nil {
+			return errors.Trace(err)
+		}
+		if !resp.Errors {
+			return nil
+		}
+		return checkBulkItems(resp.Items)
+	})
+}
+
+// checkBulkItems returns the first unexpected error of the actions.
+// The version conflicts are ignored since they mean a newer version of the
+// document has been written, and deleting a missing document is a no-op.
+func checkBulkItems(items []map[string]bulkResponseItem) error {
+	for _, item := range items {
+		for op, result := range item {
+			if result.Status >= 200 && result.Status < 300 {
+				continue
+			}
+			if result.Status == http.StatusConflict {
+				continue
+			}
+			if op == "delete" && result.Status == http.StatusNotFound {
+				continue
+			}
+			return cerror.ErrElasticsearchRequestFailed.Wrap(&statusError{code: result.Status}).
+				GenWithStackByArgs(result.Status, string(result.Error))
+		}
+	}
+	return nil
+}
+
+// CreateIndex creates the index with the field mappings,
+// it returns false if the index already exists.
+func (c *Client) CreateIndex(
+	ctx context.Context, index string, properties map[string]interface{},
+) (bool, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"mappings": map[string]interface{}{"properties": properties},
+	})
+	if err != nil {
+		return false, cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+	}
+	created := true
+	err = c.withRetry(ctx, func() error {
+		_, err := c.do(ctx, http.MethodPut, "/"+url.PathEscape(index), body)
+		if statusCode(err) == http.StatusBadRequest && isIndexExists(err) {
+			created = false
+			return nil
+		}
+		return err
+	})
+	return created, err
+}
+
+// PutMapping adds the fields to the mapping of the index.
+func (c *Client) PutMapping(
+	ctx context.Context, index string, properties map[string]interface{},
+) error {
+	body, err := json.Marshal(map[string]interface{}{"properties": properties})
+	if err != nil {
+		return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+	}
+	return c.withRetry(ctx, func() error {
+		_, err := c.do(ctx, http.MethodPut, "/"+url.PathEscape(index)+"/_mapping", body)
+		return err
+	})
+}
+
+// GetMapping returns the field mappings of the index,
+// it returns nil if the index doesn't exist.
+func (c *Client) GetMapping(ctx context.Context, index string) (map[string]interface{}, error) {
+	var properties map[string]interface{}
+	err := c.withRetry(ctx, func() error {
+		body, err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_mapping", nil)
+		if statusCode(err) == http.StatusNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp := make(map[string]struct {
+			Mappings struct {
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"mappings"`
+		})
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return errors.Trace(err)
+		}
+		properties = make(map[string]interface{})
+		for _, mapping := range resp {
+			for name, field := range mapping.Mappings.Properties {
+				properties[name] = field
+			}
+		}
+		return nil
+	})
+	return properties, err
+}
+
+func (c *Client) withRetry(ctx context.Context, fn func() error) error {
+	return retry.Do(ctx, func() error {
+		err := fn()
+		if err != nil {
+			log.Warn("elasticsearch sink request failed, retrying",
+				zap.String("namespace", c.changefeedID.Namespace),
+				zap.String("changefeed", c.changefeedID.ID),
+				zap.Error(err))
+		}
+		return err
+	}, retry.WithBackoffBaseDelay(c.config.RetryBackoffBaseInMs),
+		retry.WithBackoffMaxDelay(c.config.RetryBackoffMaxInMs),
+		retry.WithMaxTries(uint64(c.config.MaxRetries)+1),
+		retry.WithIsRetryableErr(isRetryableError))
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.config.Endpoint+path, reader)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if path == "/_bulk" {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		respBody, err := io.ReadAll(resp.Body)
+		return respBody, errors.Trace(err)
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	return nil, cerror.ErrElasticsearchRequestFailed.Wrap(&statusError{code: resp.StatusCode}).
+		GenWithStackByArgs(resp.StatusCode, string(respBody))
+}
+
+// Close closes the idle connections of the client.
+func (c *Client) Close() {
+	c.client.CloseIdleConnections()
+}
+
+// statusError is the cause of ErrElasticsearchRequestFailed.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return http.StatusText(e.code)
+}
+
+func statusCode(err error) int {
+	if e, ok := errors.Cause(err).(*statusError); ok {
+		return e.code
+	}
+	return 0
+}
+
+func isIndexExists(err error) bool {
+	return strings.Contains(err.Error(), "resource_already_exists_exception")
+}
+
+// isRetryableError returns false if the cluster rejects the request,
+// which will fail again after retries.
+func isRetryableError(err error) bool {
+	if errors.Cause(err) == context.Canceled {
+		return false
+	}
+	if code := statusCode(err); code != 0 {
+		return code >= http.StatusInternalServerError ||
+			code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+	}
+	return true
+}
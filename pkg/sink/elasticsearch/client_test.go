@@ -0,0 +1,175 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	sinkURI, err := url.Parse(strings.Replace(server.URL, "http://", "elasticsearch://elastic:secret@", 1) +
+		"?max-retries=2&retry-backoff-base-in-ms=10")
+	require.NoError(t, err)
+	cfg := NewConfig()
+	require.NoError(t, cfg.Apply(sinkURI))
+	client := NewClient(model.DefaultChangeFeedID("test"), cfg)
+	t.Cleanup(client.Close)
+	return client
+}
+
+func TestBulk(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/_bulk", r.URL.Path)
+		require.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		username, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "elastic", username)
+		require.Equal(t, "secret", password)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSuffix(string(body), "\n"), "\n")
+		require.Len(t, lines, 3)
+		require.JSONEq(t, `{"delete":{"_index":"test.t","_id":"1","version":100,"version_type":"external_gte"}}`, lines[0])
+		require.JSONEq(t, `{"index":{"_index":"test.t","_id":"2","version":100,"version_type":"external_gte"}}`, lines[1])
+		require.JSONEq(t, `{"id":2}`, lines[2])
+
+		// the missing document and the version conflict are ignored.
+		_, _ = w.Write([]byte(`{"errors":true,"items":[` +
+			`{"delete":{"_index":"test.t","_id":"1","status":404}},` +
+			`{"index":{"_index":"test.t","_id":"2","status":409,"error":{"type":"version_conflict_engine_exception"}}}]}`))
+	})
+
+	req := &BulkRequest{}
+	require.NoError(t, req.Delete("test.t", "1", 100))
+	require.NoError(t, req.Index("test.t", "2", 100, map[string]interface{}{"id": 2}))
+	require.Equal(t, 2, req.Len())
+	require.NoError(t, client.Bulk(context.Background(), req))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestBulkRejected(t *testing.T) {
+	t.Parallel()
+
+	var calls int32
+	client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"errors":true,"items":[` +
+			`{"index":{"_index":"test.t","_id":"1","status":400,"error":{"type":"mapper_parsing_exception"}}}]}`))
+	})
+
+	req := &BulkRequest{}
+	require.NoError(t, req.Index("test.t", "1", 100, map[string]interface{}{"id": "a"}))
+	err := client.Bulk(context.Background(), req)
+	require.ErrorIs(t, err, cerror.ErrElasticsearchRequestFailed)
+	require.Contains(t, err.Error(), "mapper_parsing_exception")
+	// the rejected actions are not retried.
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestIndexMapping(t *testing.T) {
+	t.Parallel()
+
+	var mapping map[string]interface{}
+	client := newClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/test.t/_mapping":
+			if mapping == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"test.t": map[string]interface{}{"mappings": map[string]interface{}{"properties": mapping}},
+			})
+		case r.Method == http.MethodPut && r.URL.Path == "/test.t":
+			if mapping != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error":{"type":"resource_already_exists_exception"}}`))
+				return
+			}
+			req := make(map[string]map[string]map[string]interface{})
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			mapping = req["mappings"]["properties"]
+		case r.Method == http.MethodPut && r.URL.Path == "/test.t/_mapping":
+			req := make(map[string]map[string]interface{})
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			for name, field := range req["properties"] {
+				mapping[name] = field
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	ctx := context.Background()
+	properties, err := client.GetMapping(ctx, "test.t")
+	require.NoError(t, err)
+	require.Nil(t, properties)
+
+	created, err := client.CreateIndex(ctx, "test.t",
+		map[string]interface{}{"id": map[string]interface{}{"type": "long"}})
+	require.NoError(t, err)
+	require.True(t, created)
+	created, err = client.CreateIndex(ctx, "test.t",
+		map[string]interface{}{"id": map[string]interface{}{"type": "long"}})
+	require.NoError(t, err)
+	require.False(t, created)
+
+	require.NoError(t, client.PutMapping(ctx, "test.t",
+		map[string]interface{}{"name": map[string]interface{}{"type": "keyword"}}))
+	properties, err = client.GetMapping(ctx, "test.t")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":   map[string]interface{}{"type": "long"},
+		"name": map[string]interface{}{"type": "keyword"},
+	}, properties)
+}
+
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	newErr := func(code int) error {
+		return cerror.ErrElasticsearchRequestFailed.Wrap(&statusError{code: code}).
+			GenWithStackByArgs(code, "")
+	}
+	require.True(t, isRetryableError(errors.New("connection refused")))
+	require.True(t, isRetryableError(newErr(http.StatusServiceUnavailable)))
+	require.True(t, isRetryableError(newErr(http.StatusTooManyRequests)))
+	require.True(t, isRetryableError(newErr(http.StatusRequestTimeout)))
+	require.False(t, isRetryableError(newErr(http.StatusBadRequest)))
+	require.False(t, isRetryableError(newErr(http.StatusUnauthorized)))
+	require.False(t, isRetryableError(errors.Trace(context.Canceled)))
+}
@@ -0,0 +1,207 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/sink/dmlsink/mq/dispatcher/topic"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	psink "github.com/pingcap/tiflow/pkg/sink"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultIndex is the default value of index.
+	defaultIndex = "{schema}.{table}"
+	// defaultWorkerCount is the default value of worker-count.
+	defaultWorkerCount = 16
+	// the upper limit of worker-count.
+	maxWorkerCount = 512
+	// defaultMaxBatchSize is the default value of max-batch-size.
+	defaultMaxBatchSize = 512
+	// the upper limit of max-batch-size.
+	maxMaxBatchSize = 10000
+	// defaultTimeout is the default value of timeout.
+	defaultTimeout = 30 * time.Second
+	// defaultMaxRetries is the default value of max-retries.
+	defaultMaxRetries = 10
+	// defaultRetryBackoffBaseInMs is the default value of retry-backoff-base-in-ms.
+	defaultRetryBackoffBaseInMs = 500
+	// defaultRetryBackoffMaxInMs is the default value of retry-backoff-max-in-ms.
+	defaultRetryBackoffMaxInMs = 30 * 1000
+
+	// the max length of an index name in bytes.
+	indexNameMaxLength = 255
+)
+
+type urlConfig struct {
+	Index                *string `form:"index"`
+	WorkerCount          *int    `form:"worker-count"`
+	MaxBatchSize         *int    `form:"max-batch-size"`
+	Timeout              *string `form:"timeout"`
+	MaxRetries           *int    `form:"max-retries"`
+	RetryBackoffBaseInMs *int64  `form:"retry-backoff-base-in-ms"`
+	RetryBackoffMaxInMs  *int64  `form:"retry-backoff-max-in-ms"`
+}
+
+// Config is the configuration for elasticsearch sink.
+type Config struct {
+	// Endpoint is the base url of the cluster, e.g. https://127.0.0.1:9200.
+	Endpoint string
+	Username string
+	Password string
+	// Index is the expression of the index names, it is in the form of the
+	// topic expressions, e.g. "{schema}.{table}".
+	Index                topic.Expression
+	WorkerCount          int
+	MaxBatchSize         int
+	Timeout              time.Duration
+	MaxRetries           int
+	RetryBackoffBaseInMs int64
+	RetryBackoffMaxInMs  int64
+}
+
+// NewConfig returns the default elasticsearch sink config.
+func NewConfig() *Config {
+	return &Config{
+		Index:                defaultIndex,
+		WorkerCount:          defaultWorkerCount,
+		MaxBatchSize:         defaultMaxBatchSize,
+		Timeout:              defaultTimeout,
+		MaxRetries:           defaultMaxRetries,
+		RetryBackoffBaseInMs: defaultRetryBackoffBaseInMs,
+		RetryBackoffMaxInMs:  defaultRetryBackoffMaxInMs,
+	}
+}
+
+// Apply applies the sink URI parameters to the config.
+// The sink URI is in the form of
+// elasticsearch[+ssl]://[user:password@]host:port[/path]?index={schema}.{table}
+// and the opensearch[+ssl] schemes are accepted as well.
+func (c *Config) Apply(sinkURI *url.URL) error {
+	if sinkURI == nil {
+		return cerror.ErrElasticsearchSinkInvalidConfig.GenWithStack(
+			"failed to open elasticsearch sink, empty SinkURI")
+	}
+	scheme := strings.ToLower(sinkURI.Scheme)
+	if !psink.IsElasticsearchScheme(scheme) {
+		return cerror.ErrElasticsearchSinkInvalidConfig.GenWithStack(
+			"can't create elasticsearch sink with unsupported scheme: %s", scheme)
+	}
+
+	req := &http.Request{URL: sinkURI}
+	urlParameter := &urlConfig{}
+	if err := binding.Query.Bind(req, urlParameter); err != nil {
+		return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+	}
+
+	if urlParameter.Index != nil && len(*urlParameter.Index) > 0 {
+		index := topic.Expression(*urlParameter.Index)
+		if err := index.Validate(); err != nil {
+			return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+		}
+		c.Index = index
+	}
+	if urlParameter.WorkerCount != nil {
+		count := *urlParameter.WorkerCount
+		if count <= 0 {
+			return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig,
+				fmt.Errorf("invalid worker-count %d, it must be greater than 0", count))
+		}
+		if count > maxWorkerCount {
+			log.Warn("worker-count is too large",
+				zap.Int("original", count), zap.Int("override", maxWorkerCount))
+			count = maxWorkerCount
+		}
+		c.WorkerCount = count
+	}
+	if urlParameter.MaxBatchSize != nil {
+		size := *urlParameter.MaxBatchSize
+		if size <= 0 {
+			return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig,
+				fmt.Errorf("invalid max-batch-size %d, it must be greater than 0", size))
+		}
+		if size > maxMaxBatchSize {
+			log.Warn("max-batch-size is too large",
+				zap.Int("original", size), zap.Int("override", maxMaxBatchSize))
+			size = maxMaxBatchSize
+		}
+		c.MaxBatchSize = size
+	}
+	if urlParameter.Timeout != nil && len(*urlParameter.Timeout) > 0 {
+		d, err := time.ParseDuration(*urlParameter.Timeout)
+		if err != nil {
+			return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+		}
+		if d <= 0 {
+			return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig,
+				fmt.Errorf("invalid timeout %s, it must be greater than 0", d))
+		}
+		c.Timeout = d
+	}
+	if urlParameter.MaxRetries != nil {
+		if *urlParameter.MaxRetries < 0 {
+			return cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig,
+				fmt.Errorf("invalid max-retries %d, it must not be negative", *urlParameter.MaxRetries))
+		}
+		c.MaxRetries = *urlParameter.MaxRetries
+	}
+	if urlParameter.RetryBackoffBaseInMs != nil && *urlParameter.RetryBackoffBaseInMs > 0 {
+		c.RetryBackoffBaseInMs = *urlParameter.RetryBackoffBaseInMs
+	}
+	if urlParameter.RetryBackoffMaxInMs != nil && *urlParameter.RetryBackoffMaxInMs > 0 {
+		c.RetryBackoffMaxInMs = *urlParameter.RetryBackoffMaxInMs
+	}
+	if c.RetryBackoffMaxInMs < c.RetryBackoffBaseInMs {
+		c.RetryBackoffMaxInMs = c.RetryBackoffBaseInMs
+	}
+
+	httpScheme := "http"
+	if strings.HasSuffix(scheme, "+ssl") {
+		httpScheme = "https"
+	}
+	endpoint := url.URL{
+		Scheme: httpScheme,
+		Host:   sinkURI.Host,
+		Path:   strings.TrimSuffix(sinkURI.Path, "/"),
+	}
+	c.Endpoint = endpoint.String()
+	if sinkURI.User != nil {
+		c.Username = sinkURI.User.Username()
+		c.Password, _ = sinkURI.User.Password()
+	}
+	return nil
+}
+
+// IndexName returns the name of the index which holds the rows of the table.
+// The index names must be lowercase and must not start with '-', '_' or '+'.
+func (c *Config) IndexName(schema, table string) (string, error) {
+	name := strings.ToLower(c.Index.Substitute(schema, table))
+	name = strings.TrimLeft(name, "-_+")
+	if len(name) > indexNameMaxLength {
+		name = name[:indexNameMaxLength]
+	}
+	if name == "" || name == "." || name == ".." {
+		return "", cerror.ErrElasticsearchSinkInvalidConfig.GenWithStack(
+			"invalid index name for table %s.%s with expression %s", schema, table, c.Index)
+	}
+	return name, nil
+}
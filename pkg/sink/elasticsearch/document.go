@@ -0,0 +1,152 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"strings"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+)
+
+// idSeparator joins the values of the handle key columns into the document id.
+const idSeparator = "_"
+
+// DocumentID returns the document id of the row, which is built from the
+// values of the handle key columns.
+func DocumentID(tableInfo *model.TableInfo, cols []*model.ColumnData) (string, error) {
+	values := make([]string, 0, 1)
+	for _, col := range cols {
+		if col != nil && tableInfo.ForceGetColumnFlagType(col.ColumnID).IsHandleKey() {
+			values = append(values, model.ColumnValueString(col.Value))
+		}
+	}
+	if len(values) == 0 {
+		return "", cerror.ErrElasticsearchSinkInvalidConfig.GenWithStack(
+			"table %s has no handle key, the document id can't be generated",
+			tableInfo.TableName.String())
+	}
+	return strings.Join(values, idSeparator), nil
+}
+
+// Document converts the columns of the row to the document source.
+func Document(tableInfo *model.TableInfo, cols []*model.ColumnData) (map[string]interface{}, error) {
+	doc := make(map[string]interface{}, len(cols))
+	for _, col := range cols {
+		if col == nil {
+			continue
+		}
+		colInfo, ok := tableInfo.GetColumnInfo(col.ColumnID)
+		if !ok {
+			continue
+		}
+		value, err := documentValue(col.Value, &colInfo.FieldType)
+		if err != nil {
+			return nil, err
+		}
+		doc[colInfo.Name.O] = value
+	}
+	return doc, nil
+}
+
+// documentValue converts the column value to the value in the document.
+// The binary values are kept as []byte, so they are encoded in base64 as
+// required by the binary field type.
+func documentValue(value interface{}, ft *types.FieldType) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch ft.GetType() {
+	case mysql.TypeEnum:
+		if v, ok := value.(uint64); ok {
+			enumVar, err := types.ParseEnumValue(ft.GetElems(), v)
+			if err != nil {
+				return nil, cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+			}
+			return enumVar.Name, nil
+		}
+	case mysql.TypeSet:
+		if v, ok := value.(uint64); ok {
+			setVar, err := types.ParseSetValue(ft.GetElems(), v)
+			if err != nil {
+				return nil, cerror.WrapError(cerror.ErrElasticsearchSinkInvalidConfig, err)
+			}
+			return setVar.Name, nil
+		}
+	}
+	if v, ok := value.([]byte); ok {
+		if mysql.HasBinaryFlag(ft.GetFlag()) {
+			return v, nil
+		}
+		return string(v), nil
+	}
+	return value, nil
+}
+
+// FieldMapping returns the mapping of the field which holds the column.
+func FieldMapping(ft *types.FieldType) map[string]interface{} {
+	switch ft.GetType() {
+	case mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeYear:
+		return map[string]interface{}{"type": "long"}
+	case mysql.TypeLonglong:
+		if mysql.HasUnsignedFlag(ft.GetFlag()) {
+			return map[string]interface{}{"type": "unsigned_long"}
+		}
+		return map[string]interface{}{"type": "long"}
+	case mysql.TypeBit:
+		return map[string]interface{}{"type": "unsigned_long"}
+	case mysql.TypeFloat:
+		return map[string]interface{}{"type": "float"}
+	case mysql.TypeDouble, mysql.TypeNewDecimal:
+		return map[string]interface{}{"type": "double"}
+	case mysql.TypeDate:
+		return map[string]interface{}{"type": "date", "format": "yyyy-MM-dd"}
+	case mysql.TypeDatetime, mysql.TypeTimestamp:
+		format := "yyyy-MM-dd HH:mm:ss"
+		if fsp := ft.GetDecimal(); fsp > 0 {
+			format += "." + strings.Repeat("S", fsp)
+		}
+		return map[string]interface{}{"type": "date", "format": format}
+	case mysql.TypeVarchar, mysql.TypeString, mysql.TypeVarString:
+		if mysql.HasBinaryFlag(ft.GetFlag()) {
+			return map[string]interface{}{"type": "binary"}
+		}
+		return map[string]interface{}{"type": "keyword"}
+	case mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob:
+		if mysql.HasBinaryFlag(ft.GetFlag()) {
+			return map[string]interface{}{"type": "binary"}
+		}
+		return map[string]interface{}{"type": "text"}
+	case mysql.TypeJSON:
+		return map[string]interface{}{"type": "text"}
+	default:
+		// enum, set, time and the other types are kept as strings.
+		return map[string]interface{}{"type": "keyword"}
+	}
+}
+
+// Mapping returns the properties of the index mapping for the columns.
+func Mapping(columns []*timodel.ColumnInfo) map[string]interface{} {
+	properties := make(map[string]interface{}, len(columns))
+	for _, col := range columns {
+		if !model.IsColCDCVisible(col) {
+			continue
+		}
+		properties[col.Name.O] = FieldMapping(&col.FieldType)
+	}
+	return properties
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package elasticsearch
+
+import (
+	"testing"
+
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	pmodel "github.com/pingcap/tidb/pkg/parser/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/parser/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func newTableInfo(withHandle bool) *model.TableInfo {
+	id := types.NewFieldType(mysql.TypeLonglong)
+	id.SetFlag(mysql.UnsignedFlag | mysql.NotNullFlag)
+	name := types.NewFieldType(mysql.TypeVarchar)
+	name.SetFlag(mysql.NotNullFlag)
+	if withHandle {
+		id.AddFlag(mysql.PriKeyFlag)
+		name.AddFlag(mysql.PriKeyFlag)
+	}
+	state := types.NewFieldType(mysql.TypeEnum)
+	state.SetElems([]string{"on", "off"})
+	data := types.NewFieldType(mysql.TypeBlob)
+	data.SetFlag(mysql.BinaryFlag)
+	updated := types.NewFieldType(mysql.TypeDatetime)
+	updated.SetDecimal(3)
+
+	tidbTableInfo := &timodel.TableInfo{
+		ID:   20,
+		Name: pmodel.NewCIStr("t"),
+		Columns: []*timodel.ColumnInfo{
+			{ID: 1, Name: pmodel.NewCIStr("id"), FieldType: *id, State: timodel.StatePublic},
+			{ID: 2, Name: pmodel.NewCIStr("name"), FieldType: *name, State: timodel.StatePublic},
+			{ID: 3, Name: pmodel.NewCIStr("state"), FieldType: *state, State: timodel.StatePublic},
+			{ID: 4, Name: pmodel.NewCIStr("data"), FieldType: *data, State: timodel.StatePublic},
+			{ID: 5, Name: pmodel.NewCIStr("updated"), FieldType: *updated, State: timodel.StatePublic},
+		},
+	}
+	if withHandle {
+		tidbTableInfo.Indices = []*timodel.IndexInfo{{
+			ID:      1,
+			Name:    pmodel.NewCIStr("primary"),
+			Primary: true,
+			Unique:  true,
+			State:   timodel.StatePublic,
+			Columns: []*timodel.IndexColumn{
+				{Name: pmodel.NewCIStr("id"), Offset: 0},
+				{Name: pmodel.NewCIStr("name"), Offset: 1},
+			},
+		}}
+		tidbTableInfo.IsCommonHandle = true
+	}
+	return model.WrapTableInfo(100, "test", 33, tidbTableInfo)
+}
+
+func newColumns() []*model.ColumnData {
+	return []*model.ColumnData{
+		{ColumnID: 1, Value: uint64(1)},
+		{ColumnID: 2, Value: []byte("alice")},
+		{ColumnID: 3, Value: uint64(2)},
+		{ColumnID: 4, Value: []byte{0x01, 0x02}},
+		{ColumnID: 5, Value: "2024-01-02 03:04:05.678"},
+	}
+}
+
+func TestDocument(t *testing.T) {
+	t.Parallel()
+
+	tableInfo := newTableInfo(true)
+	id, err := DocumentID(tableInfo, newColumns())
+	require.NoError(t, err)
+	require.Equal(t, "1_alice", id)
+
+	doc, err := Document(tableInfo, newColumns())
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"id":      uint64(1),
+		"name":    "alice",
+		"state":   "off",
+		"data":    []byte{0x01, 0x02},
+		"updated": "2024-01-02 03:04:05.678",
+	}, doc)
+
+	_, err = DocumentID(newTableInfo(false), newColumns())
+	require.ErrorIs(t, err, cerror.ErrElasticsearchSinkInvalidConfig)
+}
+
+func TestMapping(t *testing.T) {
+	t.Parallel()
+
+	properties := Mapping(newTableInfo(true).Columns)
+	require.Equal(t, map[string]interface{}{
+		"id":    map[string]interface{}{"type": "unsigned_long"},
+		"name":  map[string]interface{}{"type": "keyword"},
+		"state": map[string]interface{}{"type": "keyword"},
+		"data":  map[string]interface{}{"type": "binary"},
+		"updated": map[string]interface{}{
+			"type": "date", "format": "yyyy-MM-dd HH:mm:ss.SSS",
+		},
+	}, properties)
+}
@@ -0,0 +1,375 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/pingcap/errors"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+)
+
+// Batch collects the row changes of a table which are committed in the same
+// snapshot.
+//
+// Iceberg equality deletes only apply to the data files committed before
+// them, so the changes of the same row in a batch are collapsed: only the
+// last image of a row is written to the data file, and every row touched by
+// the batch is deleted by the equality delete file. Applying the same batch
+// more than once leads to the same table content, which makes the sink
+// idempotent after a restart.
+//
+// The rows of a large batch can be flushed to data files before the snapshot
+// is committed. A flushed row which is changed again in the batch is deleted
+// by a position delete, since the equality deletes don't apply to the data
+// files committed in the same snapshot.
+type Batch struct {
+	schema *Schema
+	tz     *time.Location
+	// equalityFields are the fields used by the equality deletes, they are
+	// the identifier fields if any, otherwise all the fields.
+	equalityFields []NestedField
+	keyed          bool
+
+	// rows are the last images of the rows of a keyed table, nil means the
+	// row is deleted.
+	rows map[string][]interface{}
+	// order keeps the insertion order of the rows to make the data file
+	// deterministic.
+	order []string
+	// appended are the rows of a keyless table in insertion order, nil means
+	// the row is deleted later in the batch. Identical rows are all kept since
+	// a keyless table can have duplicate rows.
+	appended [][]interface{}
+	// pending maps a keyless row to its indexes in appended.
+	pending map[string][]int
+	// deletes are the equality delete rows.
+	deletes     map[string][]interface{}
+	deleteOrder []string
+
+	// flushed maps a row to its positions in the data files flushed by the
+	// batch, and posDeletes are the positions of the flushed rows which have
+	// been changed since.
+	flushed    map[string][]filePosition
+	posDeletes []filePosition
+	// files are the flushed files which have not been committed.
+	files []DataFile
+
+	commitTs uint64
+	// size is the approximate size of the rows which have not been flushed,
+	// and bytes is the approximate size of all the rows.
+	size   int
+	bytes  int64
+	events int
+}
+
+// filePosition is the position of a row in a data file.
+type filePosition struct {
+	filePath string
+	pos      int64
+}
+
+// NewBatch creates a batch for the schema.
+func NewBatch(schema *Schema, tz *time.Location) *Batch {
+	b := &Batch{
+		schema:  schema,
+		tz:      tz,
+		rows:    make(map[string][]interface{}),
+		pending: make(map[string][]int),
+		deletes: make(map[string][]interface{}),
+		flushed: make(map[string][]filePosition),
+	}
+	for _, id := range schema.IdentifierFieldIDs {
+		for _, f := range schema.Fields {
+			if f.ID == id {
+				b.equalityFields = append(b.equalityFields, f)
+			}
+		}
+	}
+	b.keyed = len(b.equalityFields) > 0
+	if !b.keyed {
+		b.equalityFields = schema.Fields
+	}
+	return b
+}
+
+// Schema returns the schema of the batch.
+func (b *Batch) Schema() *Schema {
+	return b.schema
+}
+
+// CommitTs returns the max commit ts of the rows in the batch.
+func (b *Batch) CommitTs() uint64 {
+	return b.commitTs
+}
+
+// Size returns the approximate size of the rows in the batch which have not
+// been flushed to data files.
+func (b *Batch) Size() int {
+	return b.size
+}
+
+// Bytes returns the approximate size of all the rows in the batch.
+func (b *Batch) Bytes() int64 {
+	return b.bytes
+}
+
+// Len returns the number of row changed events in the batch.
+func (b *Batch) Len() int {
+	return b.events
+}
+
+// AppendRow appends a row changed event to the batch, the table info of the
+// row must match the schema of the batch.
+func (b *Batch) AppendRow(row *model.RowChangedEvent) error {
+	if row.IsDelete() || row.IsUpdate() {
+		values, err := b.convert(row.TableInfo, row.PreColumns)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := b.delete(values); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if row.IsInsert() || row.IsUpdate() {
+		values, err := b.convert(row.TableInfo, row.Columns)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := b.upsert(values); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if row.CommitTs > b.commitTs {
+		b.commitTs = row.CommitTs
+	}
+	size := row.ApproximateBytes()
+	b.size += size
+	b.bytes += int64(size)
+	b.events++
+	return nil
+}
+
+func (b *Batch) delete(values []interface{}) error {
+	key, deleteRow, err := b.key(values)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !b.keyed {
+		// only one of the identical rows is deleted, the row inserted by
+		// the batch is removed directly if any.
+		if indexes := b.pending[key]; len(indexes) > 0 {
+			b.appended[indexes[len(indexes)-1]] = nil
+			b.pending[key] = indexes[:len(indexes)-1]
+			return nil
+		}
+		if b.deleteFlushed(key) {
+			return nil
+		}
+		// the equality delete removes all the identical rows written by
+		// previous batches, which is the best we can do without a key.
+		b.addDelete(key, deleteRow)
+		return nil
+	}
+
+	if _, ok := b.rows[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	b.rows[key] = nil
+	b.deleteFlushed(key)
+	b.addDelete(key, deleteRow)
+	return nil
+}
+
+func (b *Batch) upsert(values []interface{}) error {
+	key, deleteRow, err := b.key(values)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !b.keyed {
+		b.pending[key] = append(b.pending[key], len(b.appended))
+		b.appended = append(b.appended, values)
+		return nil
+	}
+
+	if _, ok := b.rows[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	b.rows[key] = values
+	b.deleteFlushed(key)
+	// delete the row written by previous batches if any.
+	b.addDelete(key, deleteRow)
+	return nil
+}
+
+func (b *Batch) addDelete(key string, deleteRow []interface{}) {
+	if _, ok := b.deletes[key]; !ok {
+		b.deleteOrder = append(b.deleteOrder, key)
+	}
+	b.deletes[key] = deleteRow
+}
+
+// deleteFlushed deletes the last flushed row of the key by position, it
+// returns false if the row has not been flushed.
+func (b *Batch) deleteFlushed(key string) bool {
+	positions := b.flushed[key]
+	if len(positions) == 0 {
+		return false
+	}
+	b.posDeletes = append(b.posDeletes, positions[len(positions)-1])
+	if len(positions) == 1 {
+		delete(b.flushed, key)
+	} else {
+		b.flushed[key] = positions[:len(positions)-1]
+	}
+	return true
+}
+
+// key returns the key of the row and the values of the equality fields.
+func (b *Batch) key(values []interface{}) (string, []interface{}, error) {
+	var deleteRow []interface{}
+	if !b.keyed {
+		deleteRow = values
+	} else {
+		deleteRow = make([]interface{}, 0, len(b.equalityFields))
+		for _, f := range b.equalityFields {
+			for i, field := range b.schema.Fields {
+				if field.ID == f.ID {
+					deleteRow = append(deleteRow, values[i])
+					break
+				}
+			}
+		}
+	}
+	// the values are primitive types, so the json encoding is deterministic.
+	key, err := json.Marshal(deleteRow)
+	if err != nil {
+		return "", nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+	}
+	return string(key), deleteRow, nil
+}
+
+func (b *Batch) convert(
+	tableInfo *model.TableInfo, columns []*model.ColumnData,
+) ([]interface{}, error) {
+	type column struct {
+		info  *timodel.ColumnInfo
+		value interface{}
+	}
+	byName := make(map[string]column, len(columns))
+	for _, col := range columns {
+		if col == nil {
+			continue
+		}
+		info := tableInfo.ForceGetColumnInfo(col.ColumnID)
+		byName[info.Name.O] = column{info: info, value: col.Value}
+	}
+
+	values := make([]interface{}, 0, len(b.schema.Fields))
+	for _, field := range b.schema.Fields {
+		col, ok := byName[field.Name]
+		if !ok {
+			return nil, cerror.ErrIcebergEncodeFailed.GenWithStack(
+				"column %s not found in table %s", field.Name, tableInfo.TableName)
+		}
+		value, err := toParquetValue(field, col.info, col.value, b.tz)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// dataRows returns the rows which have not been flushed and their keys.
+func (b *Batch) dataRows() ([]string, [][]interface{}, error) {
+	var (
+		keys []string
+		rows [][]interface{}
+	)
+	if !b.keyed {
+		for _, row := range b.appended {
+			if row == nil {
+				continue
+			}
+			key, _, err := b.key(row)
+			if err != nil {
+				return nil, nil, errors.Trace(err)
+			}
+			keys = append(keys, key)
+			rows = append(rows, row)
+		}
+		return keys, rows, nil
+	}
+	for _, key := range b.order {
+		if row := b.rows[key]; row != nil {
+			keys = append(keys, key)
+			rows = append(rows, row)
+		}
+	}
+	return keys, rows, nil
+}
+
+// deleteRows returns the rows written to the equality delete file.
+func (b *Batch) deleteRows() [][]interface{} {
+	rows := make([][]interface{}, 0, len(b.deleteOrder))
+	for _, key := range b.deleteOrder {
+		rows = append(rows, b.deletes[key])
+	}
+	return rows
+}
+
+// positionDeleteRows returns the rows written to the position delete file,
+// which are sorted by the file path and the position.
+func (b *Batch) positionDeleteRows() [][]interface{} {
+	positions := append([]filePosition(nil), b.posDeletes...)
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].filePath != positions[j].filePath {
+			return positions[i].filePath < positions[j].filePath
+		}
+		return positions[i].pos < positions[j].pos
+	})
+	rows := make([][]interface{}, 0, len(positions))
+	for _, p := range positions {
+		rows = append(rows, []interface{}{p.filePath, p.pos})
+	}
+	return rows
+}
+
+// markFlushed records the rows written to the data file and resets the rows
+// which have not been flushed.
+func (b *Batch) markFlushed(filePath string, keys []string) {
+	for i, key := range keys {
+		b.flushed[key] = append(b.flushed[key], filePosition{filePath: filePath, pos: int64(i)})
+	}
+	b.rows = make(map[string][]interface{})
+	b.order = nil
+	b.appended = nil
+	b.pending = make(map[string][]int)
+	b.deletes = make(map[string][]interface{})
+	b.deleteOrder = nil
+	b.size = 0
+}
+
+func (b *Batch) equalityFieldIDs() []int {
+	ids := make([]int, 0, len(b.equalityFields))
+	for _, f := range b.equalityFields {
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
@@ -0,0 +1,104 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	psink "github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/sink/cloudstorage"
+	"github.com/pingcap/tiflow/pkg/util"
+)
+
+// Config is the configuration for iceberg sink.
+type Config struct {
+	WorkerCount int
+	// FileSize is the size of the rows of a table which are flushed to a data
+	// file before they are committed.
+	FileSize       int
+	OutputColumnID bool
+	// TimeZone is used to convert the TIMESTAMP columns to UTC.
+	TimeZone *time.Location
+	// StorageURI is the uri of the external storage which holds all tables,
+	// e.g. "s3://bucket/prefix" for "iceberg+s3://bucket/prefix".
+	StorageURI *url.URL
+	// Location is the root location recorded in the iceberg table metadata,
+	// it is the StorageURI without any query parameters.
+	Location string
+}
+
+// NewConfig returns the default iceberg sink config.
+func NewConfig() *Config {
+	storageCfg := cloudstorage.NewConfig()
+	return &Config{
+		WorkerCount: storageCfg.WorkerCount,
+		FileSize:    storageCfg.FileSize,
+		TimeZone:    time.UTC,
+	}
+}
+
+// Apply applies the sink URI parameters to the config.
+// The iceberg sink shares the `worker-count` and `file-size` parameters with
+// the cloud storage sink. The `flush-interval` parameter is accepted but not
+// used, since the snapshots are committed when the resolved ts advances.
+func (c *Config) Apply(
+	ctx context.Context,
+	sinkURI *url.URL,
+	replicaConfig *config.ReplicaConfig,
+) error {
+	if sinkURI == nil {
+		return cerror.ErrIcebergSinkInvalidConfig.GenWithStack(
+			"failed to open iceberg sink, empty SinkURI")
+	}
+	scheme := strings.ToLower(sinkURI.Scheme)
+	if !psink.IsIcebergScheme(scheme) {
+		return cerror.ErrIcebergSinkInvalidConfig.GenWithStack(
+			"can't create iceberg sink with unsupported scheme: %s", scheme)
+	}
+
+	c.StorageURI = GetStorageURI(sinkURI)
+	storageCfg := cloudstorage.NewConfig()
+	if err := storageCfg.Apply(ctx, c.StorageURI, replicaConfig); err != nil {
+		return cerror.WrapError(cerror.ErrIcebergSinkInvalidConfig, err)
+	}
+	c.WorkerCount = storageCfg.WorkerCount
+	c.FileSize = storageCfg.FileSize
+	c.OutputColumnID = storageCfg.OutputColumnID
+
+	location := *c.StorageURI
+	location.RawQuery = ""
+	location.Fragment = ""
+	location.User = nil
+	c.Location = strings.TrimSuffix(location.String(), "/")
+
+	tz, err := util.GetTimezone(config.GetGlobalServerConfig().TZ)
+	if err != nil {
+		return cerror.WrapError(cerror.ErrIcebergSinkInvalidConfig, err)
+	}
+	c.TimeZone = tz
+	return nil
+}
+
+// GetStorageURI returns the uri of the external storage which holds the
+// iceberg tables, the "iceberg+" prefix of the scheme is removed.
+func GetStorageURI(sinkURI *url.URL) *url.URL {
+	storageURI := *sinkURI
+	storageURI.Scheme = psink.GetIcebergStorageScheme(strings.ToLower(sinkURI.Scheme))
+	return &storageURI
+}
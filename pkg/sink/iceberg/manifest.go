@@ -0,0 +1,306 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/pingcap/errors"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+)
+
+// The avro schemas of the manifest file and the manifest list file, only the
+// required fields and the equality ids are written.
+// See https://iceberg.apache.org/spec/#manifests.
+const (
+	manifestEntrySchema = `{
+  "type": "record",
+  "name": "manifest_entry",
+  "fields": [
+    {"name": "status", "type": "int", "field-id": 0},
+    {"name": "snapshot_id", "type": ["null", "long"], "default": null, "field-id": 1},
+    {"name": "sequence_number", "type": ["null", "long"], "default": null, "field-id": 3},
+    {"name": "file_sequence_number", "type": ["null", "long"], "default": null, "field-id": 4},
+    {"name": "data_file", "field-id": 2, "type": {
+      "type": "record",
+      "name": "r2",
+      "fields": [
+        {"name": "content", "type": "int", "field-id": 134},
+        {"name": "file_path", "type": "string", "field-id": 100},
+        {"name": "file_format", "type": "string", "field-id": 101},
+        {"name": "partition", "field-id": 102, "type": {"type": "record", "name": "r102", "fields": []}},
+        {"name": "record_count", "type": "long", "field-id": 103},
+        {"name": "file_size_in_bytes", "type": "long", "field-id": 104},
+        {"name": "equality_ids", "default": null, "field-id": 135,
+         "type": ["null", {"type": "array", "items": "int", "element-id": 136}]}
+      ]
+    }}
+  ]
+}`
+
+	manifestFileSchema = `{
+  "type": "record",
+  "name": "manifest_file",
+  "fields": [
+    {"name": "manifest_path", "type": "string", "field-id": 500},
+    {"name": "manifest_length", "type": "long", "field-id": 501},
+    {"name": "partition_spec_id", "type": "int", "field-id": 502},
+    {"name": "content", "type": "int", "field-id": 517},
+    {"name": "sequence_number", "type": "long", "field-id": 515},
+    {"name": "min_sequence_number", "type": "long", "field-id": 516},
+    {"name": "added_snapshot_id", "type": "long", "field-id": 503},
+    {"name": "added_files_count", "type": "int", "field-id": 504},
+    {"name": "existing_files_count", "type": "int", "field-id": 505},
+    {"name": "deleted_files_count", "type": "int", "field-id": 506},
+    {"name": "added_rows_count", "type": "long", "field-id": 512},
+    {"name": "existing_rows_count", "type": "long", "field-id": 513},
+    {"name": "deleted_rows_count", "type": "long", "field-id": 514}
+  ]
+}`
+)
+
+// FileContent is the content type of a data file or a manifest.
+type FileContent int32
+
+const (
+	// ContentData is for data files and data manifests.
+	ContentData FileContent = 0
+	// ContentPositionDeletes is for position delete files.
+	ContentPositionDeletes FileContent = 1
+	// ContentEqualityDeletes is for equality delete files.
+	ContentEqualityDeletes FileContent = 2
+)
+
+const (
+	entryStatusAdded = 1
+
+	// manifestContentData and manifestContentDeletes are the content types
+	// of the manifest files.
+	manifestContentData    = 0
+	manifestContentDeletes = 1
+
+	fileFormatParquet = "PARQUET"
+)
+
+// positionDeleteFields are the fields of the position delete files, the field
+// ids are reserved by the iceberg spec.
+var positionDeleteFields = []NestedField{
+	{ID: 2147483546, Name: "file_path", Required: true, Type: typeString},
+	{ID: 2147483545, Name: "pos", Required: true, Type: typeLong},
+}
+
+// DataFile is a data file or a delete file tracked by manifests.
+type DataFile struct {
+	Content         FileContent
+	FilePath        string
+	RecordCount     int64
+	FileSizeInBytes int64
+	// EqualityIDs is only set for equality delete files.
+	EqualityIDs []int
+}
+
+// ManifestFile is an entry of the manifest list.
+type ManifestFile struct {
+	Path               string
+	Length             int64
+	PartitionSpecID    int32
+	Content            int32
+	SequenceNumber     int64
+	MinSequenceNumber  int64
+	AddedSnapshotID    int64
+	AddedFilesCount    int32
+	ExistingFilesCount int32
+	DeletedFilesCount  int32
+	AddedRowsCount     int64
+	ExistingRowsCount  int64
+	DeletedRowsCount   int64
+}
+
+// encodeManifest encodes the data files added by the snapshot into an avro
+// manifest file, all files must have the same content type.
+func encodeManifest(
+	schema *Schema, snapshotID, sequenceNumber int64, files []DataFile,
+) ([]byte, error) {
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+	}
+	content := "data"
+	if len(files) > 0 && files[0].Content != ContentData {
+		content = "deletes"
+	}
+
+	records := make([]interface{}, 0, len(files))
+	for _, f := range files {
+		var equalityIDs interface{}
+		if len(f.EqualityIDs) > 0 {
+			ids := make([]interface{}, 0, len(f.EqualityIDs))
+			for _, id := range f.EqualityIDs {
+				ids = append(ids, int32(id))
+			}
+			equalityIDs = goavro.Union("array", ids)
+		}
+		records = append(records, map[string]interface{}{
+			"status":               int32(entryStatusAdded),
+			"snapshot_id":          goavro.Union("long", snapshotID),
+			"sequence_number":      goavro.Union("long", sequenceNumber),
+			"file_sequence_number": goavro.Union("long", sequenceNumber),
+			"data_file": map[string]interface{}{
+				"content":            int32(f.Content),
+				"file_path":          f.FilePath,
+				"file_format":        fileFormatParquet,
+				"partition":          map[string]interface{}{},
+				"record_count":       f.RecordCount,
+				"file_size_in_bytes": f.FileSizeInBytes,
+				"equality_ids":       equalityIDs,
+			},
+		})
+	}
+	return encodeAvro(manifestEntrySchema, map[string][]byte{
+		"schema":            schemaJSON,
+		"schema-id":         []byte(strconv.Itoa(schema.SchemaID)),
+		"partition-spec":    []byte("[]"),
+		"partition-spec-id": []byte("0"),
+		"format-version":    []byte(strconv.Itoa(formatVersion)),
+		"content":           []byte(content),
+	}, records)
+}
+
+// encodeManifestList encodes the manifest list of a snapshot.
+func encodeManifestList(
+	snapshotID int64, parentSnapshotID *int64, sequenceNumber int64, manifests []ManifestFile,
+) ([]byte, error) {
+	records := make([]interface{}, 0, len(manifests))
+	for _, m := range manifests {
+		records = append(records, map[string]interface{}{
+			"manifest_path":        m.Path,
+			"manifest_length":      m.Length,
+			"partition_spec_id":    m.PartitionSpecID,
+			"content":              m.Content,
+			"sequence_number":      m.SequenceNumber,
+			"min_sequence_number":  m.MinSequenceNumber,
+			"added_snapshot_id":    m.AddedSnapshotID,
+			"added_files_count":    m.AddedFilesCount,
+			"existing_files_count": m.ExistingFilesCount,
+			"deleted_files_count":  m.DeletedFilesCount,
+			"added_rows_count":     m.AddedRowsCount,
+			"existing_rows_count":  m.ExistingRowsCount,
+			"deleted_rows_count":   m.DeletedRowsCount,
+		})
+	}
+	parent := "null"
+	if parentSnapshotID != nil {
+		parent = strconv.FormatInt(*parentSnapshotID, 10)
+	}
+	return encodeAvro(manifestFileSchema, map[string][]byte{
+		"snapshot-id":        []byte(strconv.FormatInt(snapshotID, 10)),
+		"parent-snapshot-id": []byte(parent),
+		"sequence-number":    []byte(strconv.FormatInt(sequenceNumber, 10)),
+		"format-version":     []byte(strconv.Itoa(formatVersion)),
+	}, records)
+}
+
+// decodeManifestList decodes the manifest list written by encodeManifestList.
+func decodeManifestList(data []byte) ([]ManifestFile, error) {
+	records, err := decodeAvro(data)
+	if err != nil {
+		return nil, err
+	}
+	manifests := make([]ManifestFile, 0, len(records))
+	for _, r := range records {
+		m := ManifestFile{
+			Path:               r["manifest_path"].(string),
+			Length:             r["manifest_length"].(int64),
+			PartitionSpecID:    r["partition_spec_id"].(int32),
+			Content:            r["content"].(int32),
+			SequenceNumber:     r["sequence_number"].(int64),
+			MinSequenceNumber:  r["min_sequence_number"].(int64),
+			AddedSnapshotID:    r["added_snapshot_id"].(int64),
+			AddedFilesCount:    r["added_files_count"].(int32),
+			ExistingFilesCount: r["existing_files_count"].(int32),
+			DeletedFilesCount:  r["deleted_files_count"].(int32),
+			AddedRowsCount:     r["added_rows_count"].(int64),
+			ExistingRowsCount:  r["existing_rows_count"].(int64),
+			DeletedRowsCount:   r["deleted_rows_count"].(int64),
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// decodeManifest decodes the data files from the manifest written by encodeManifest.
+func decodeManifest(data []byte) ([]DataFile, error) {
+	records, err := decodeAvro(data)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]DataFile, 0, len(records))
+	for _, r := range records {
+		dataFile := r["data_file"].(map[string]interface{})
+		f := DataFile{
+			Content:         FileContent(dataFile["content"].(int32)),
+			FilePath:        dataFile["file_path"].(string),
+			RecordCount:     dataFile["record_count"].(int64),
+			FileSizeInBytes: dataFile["file_size_in_bytes"].(int64),
+		}
+		if ids, ok := dataFile["equality_ids"].(map[string]interface{}); ok {
+			for _, id := range ids["array"].([]interface{}) {
+				f.EqualityIDs = append(f.EqualityIDs, int(id.(int32)))
+			}
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+func encodeAvro(
+	schema string, metadata map[string][]byte, records []interface{},
+) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:               buf,
+		Schema:          schema,
+		CompressionName: goavro.CompressionDeflateLabel,
+		MetaData:        metadata,
+	})
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+	}
+	// goavro writes an empty block for empty records, which can not be read back.
+	if len(records) > 0 {
+		if err := writer.Append(records); err != nil {
+			return nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeAvro(data []byte) ([]map[string]interface{}, error) {
+	reader, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var records []map[string]interface{}
+	for reader.Scan() {
+		record, err := reader.Read()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		records = append(records, record.(map[string]interface{}))
+	}
+	return records, errors.Trace(reader.Err())
+}
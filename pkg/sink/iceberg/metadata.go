@@ -0,0 +1,203 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"github.com/google/uuid"
+)
+
+const (
+	formatVersion = 2
+
+	// noSnapshotID is the current snapshot id of a table without any snapshot.
+	noSnapshotID = int64(-1)
+	// lastPartitionID is the initial value of last-partition-id, since all
+	// tables are unpartitioned.
+	lastPartitionID = 999
+	// mainBranch is the name of the default branch.
+	mainBranch = "main"
+
+	// Snapshot operations, see https://iceberg.apache.org/spec/#snapshots.
+	operationAppend    = "append"
+	operationOverwrite = "overwrite"
+	operationDelete    = "delete"
+
+	// The following properties are recorded in the snapshot summary.
+	summaryOperation = "operation"
+	// summaryCommitTs is the max commit ts of the rows in the snapshot.
+	summaryCommitTs        = "tidb-commit-ts"
+	summaryAddedDataFiles  = "added-data-files"
+	summaryAddedDeleteFile = "added-delete-files"
+	summaryAddedRecords    = "added-records"
+	summaryAddedEqDeletes  = "added-equality-deletes"
+	summaryAddedPosDeletes = "added-position-deletes"
+
+	// The following properties are recorded in the table properties.
+	propertyChangefeed   = "tidb.changefeed"
+	propertyWriteFormat  = "write.format.default"
+	propertyUpsert       = "write.upsert.enabled"
+	propertyTiDBTableVer = "tidb.table-version"
+)
+
+// PartitionSpec is the iceberg partition spec, all tables are unpartitioned.
+type PartitionSpec struct {
+	SpecID int           `json:"spec-id"`
+	Fields []interface{} `json:"fields"`
+}
+
+// SortOrder is the iceberg sort order, all tables are unsorted.
+type SortOrder struct {
+	OrderID int           `json:"order-id"`
+	Fields  []interface{} `json:"fields"`
+}
+
+// Snapshot is the iceberg snapshot.
+type Snapshot struct {
+	SnapshotID       int64             `json:"snapshot-id"`
+	ParentSnapshotID *int64            `json:"parent-snapshot-id,omitempty"`
+	SequenceNumber   int64             `json:"sequence-number"`
+	TimestampMs      int64             `json:"timestamp-ms"`
+	ManifestList     string            `json:"manifest-list"`
+	Summary          map[string]string `json:"summary"`
+	SchemaID         int               `json:"schema-id"`
+}
+
+// SnapshotLogEntry is an entry of the snapshot log.
+type SnapshotLogEntry struct {
+	SnapshotID  int64 `json:"snapshot-id"`
+	TimestampMs int64 `json:"timestamp-ms"`
+}
+
+// MetadataLogEntry is an entry of the metadata log.
+type MetadataLogEntry struct {
+	MetadataFile string `json:"metadata-file"`
+	TimestampMs  int64  `json:"timestamp-ms"`
+}
+
+// SnapshotRef is a named reference to a snapshot.
+type SnapshotRef struct {
+	SnapshotID int64  `json:"snapshot-id"`
+	Type       string `json:"type"`
+}
+
+// TableMetadata is the iceberg table metadata of format version 2,
+// see https://iceberg.apache.org/spec/#table-metadata-fields.
+type TableMetadata struct {
+	FormatVersion      int                    `json:"format-version"`
+	TableUUID          string                 `json:"table-uuid"`
+	Location           string                 `json:"location"`
+	LastSequenceNumber int64                  `json:"last-sequence-number"`
+	LastUpdatedMs      int64                  `json:"last-updated-ms"`
+	LastColumnID       int                    `json:"last-column-id"`
+	CurrentSchemaID    int                    `json:"current-schema-id"`
+	Schemas            []*Schema              `json:"schemas"`
+	DefaultSpecID      int                    `json:"default-spec-id"`
+	PartitionSpecs     []PartitionSpec        `json:"partition-specs"`
+	LastPartitionID    int                    `json:"last-partition-id"`
+	DefaultSortOrderID int                    `json:"default-sort-order-id"`
+	SortOrders         []SortOrder            `json:"sort-orders"`
+	Properties         map[string]string      `json:"properties"`
+	CurrentSnapshotID  int64                  `json:"current-snapshot-id"`
+	Snapshots          []*Snapshot            `json:"snapshots"`
+	SnapshotLog        []SnapshotLogEntry     `json:"snapshot-log"`
+	MetadataLog        []MetadataLogEntry     `json:"metadata-log"`
+	Refs               map[string]SnapshotRef `json:"refs"`
+}
+
+func newTableMetadata(location string, schema *Schema, lastColumnID int) *TableMetadata {
+	return &TableMetadata{
+		FormatVersion:   formatVersion,
+		TableUUID:       uuid.New().String(),
+		Location:        location,
+		LastColumnID:    lastColumnID,
+		CurrentSchemaID: schema.SchemaID,
+		Schemas:         []*Schema{schema},
+		PartitionSpecs:  []PartitionSpec{{SpecID: 0, Fields: []interface{}{}}},
+		LastPartitionID: lastPartitionID,
+		SortOrders:      []SortOrder{{OrderID: 0, Fields: []interface{}{}}},
+		Properties: map[string]string{
+			propertyWriteFormat: "parquet",
+		},
+		CurrentSnapshotID: noSnapshotID,
+		Snapshots:         []*Snapshot{},
+		SnapshotLog:       []SnapshotLogEntry{},
+		MetadataLog:       []MetadataLogEntry{},
+		Refs:              map[string]SnapshotRef{},
+	}
+}
+
+// CurrentSchema returns the current schema of the table.
+func (m *TableMetadata) CurrentSchema() *Schema {
+	for _, s := range m.Schemas {
+		if s.SchemaID == m.CurrentSchemaID {
+			return s
+		}
+	}
+	return nil
+}
+
+// CurrentSnapshot returns the current snapshot of the table,
+// nil is returned if the table has no snapshot.
+func (m *TableMetadata) CurrentSnapshot() *Snapshot {
+	if m.CurrentSnapshotID == noSnapshotID {
+		return nil
+	}
+	for _, s := range m.Snapshots {
+		if s.SnapshotID == m.CurrentSnapshotID {
+			return s
+		}
+	}
+	return nil
+}
+
+// evolveSchema sets the given schema as the current schema if it is different
+// from the current one. It returns true if the metadata is changed.
+func (m *TableMetadata) evolveSchema(schema *Schema, lastColumnID int) bool {
+	current := m.CurrentSchema()
+	if current != nil && current.sameAs(schema) {
+		return false
+	}
+	// reuse the schema id if the schema has been used before.
+	for _, s := range m.Schemas {
+		if s.sameAs(schema) {
+			m.CurrentSchemaID = s.SchemaID
+			return true
+		}
+	}
+	maxSchemaID := 0
+	for _, s := range m.Schemas {
+		if s.SchemaID > maxSchemaID {
+			maxSchemaID = s.SchemaID
+		}
+	}
+	schema.SchemaID = maxSchemaID + 1
+	m.Schemas = append(m.Schemas, schema)
+	m.CurrentSchemaID = schema.SchemaID
+	if lastColumnID > m.LastColumnID {
+		m.LastColumnID = lastColumnID
+	}
+	return true
+}
+
+// addSnapshot adds a snapshot and sets it as the current snapshot.
+func (m *TableMetadata) addSnapshot(snapshot *Snapshot) {
+	m.Snapshots = append(m.Snapshots, snapshot)
+	m.CurrentSnapshotID = snapshot.SnapshotID
+	m.LastSequenceNumber = snapshot.SequenceNumber
+	m.SnapshotLog = append(m.SnapshotLog, SnapshotLogEntry{
+		SnapshotID:  snapshot.SnapshotID,
+		TimestampMs: snapshot.TimestampMs,
+	})
+	m.Refs[mainBranch] = SnapshotRef{SnapshotID: snapshot.SnapshotID, Type: "branch"}
+}
@@ -0,0 +1,275 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/errors"
+	timodel "github.com/pingcap/tidb/pkg/meta/model"
+	"github.com/pingcap/tidb/pkg/parser/mysql"
+	"github.com/pingcap/tidb/pkg/types"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	datetimeLayout = "2006-01-02 15:04:05.999999"
+)
+
+var epoch = time.Unix(0, 0).UTC()
+
+// parquetFieldMeta returns the parquet-go metadata of the iceberg field, the
+// field id is recorded in the parquet schema so that iceberg readers can
+// resolve the columns by id instead of by name.
+func parquetFieldMeta(field NestedField) string {
+	// ',' and '=' are the separators of the metadata, the column name is
+	// only informational since the columns are resolved by field id.
+	name := strings.NewReplacer(",", "_", "=", "_").Replace(field.Name)
+	repetition := "OPTIONAL"
+	if field.Required {
+		repetition = "REQUIRED"
+	}
+	prefix := fmt.Sprintf("name=%s, inname=Field%d, fieldid=%d, repetitiontype=%s",
+		name, field.ID, field.ID, repetition)
+
+	switch field.Type {
+	case typeInt:
+		return prefix + ", type=INT32"
+	case typeLong:
+		return prefix + ", type=INT64"
+	case typeFloat:
+		return prefix + ", type=FLOAT"
+	case typeDouble:
+		return prefix + ", type=DOUBLE"
+	case typeDate:
+		return prefix + ", type=INT32, convertedtype=DATE"
+	case typeTimestamp:
+		return prefix + ", type=INT64, logicaltype=TIMESTAMP, " +
+			"logicaltype.isadjustedtoutc=false, logicaltype.unit=MICROS"
+	case typeTimestampTz:
+		return prefix + ", type=INT64, logicaltype=TIMESTAMP, " +
+			"logicaltype.isadjustedtoutc=true, logicaltype.unit=MICROS"
+	case typeBinary:
+		return prefix + ", type=BYTE_ARRAY"
+	case typeString:
+		return prefix + ", type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+	precision, scale, _ := parseDecimalType(field.Type)
+	decimal := fmt.Sprintf("convertedtype=DECIMAL, precision=%d, scale=%d", precision, scale)
+	switch {
+	case precision <= 9:
+		return prefix + ", type=INT32, " + decimal
+	case precision <= 18:
+		return prefix + ", type=INT64, " + decimal
+	default:
+		return fmt.Sprintf("%s, type=FIXED_LEN_BYTE_ARRAY, length=%d, %s",
+			prefix, decimalLength(precision), decimal)
+	}
+}
+
+// decimalLength returns the minimum number of bytes to store a decimal with
+// the given precision.
+func decimalLength(precision int) int {
+	return int(math.Ceil((float64(precision)*math.Log2(10) + 1) / 8))
+}
+
+// encodeParquet encodes the rows into a parquet file, each row must contain
+// the values of the fields in order, which are converted by toParquetValue.
+func encodeParquet(fields []NestedField, rows [][]interface{}) ([]byte, error) {
+	md := make([]string, 0, len(fields))
+	for _, f := range fields {
+		md = append(md, parquetFieldMeta(f))
+	}
+	buf := new(bytes.Buffer)
+	w, err := writer.NewCSVWriterFromWriter(md, buf, 1)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+	}
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			return nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+		}
+	}
+	if err := w.WriteStop(); err != nil {
+		return nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// toParquetValue converts the value of a row changed event column to the go
+// type used by parquet-go for the physical type of the iceberg field.
+func toParquetValue(
+	field NestedField, colInfo *timodel.ColumnInfo, value interface{}, tz *time.Location,
+) (interface{}, error) {
+	if value == nil {
+		if field.Required {
+			return nil, cerror.ErrIcebergEncodeFailed.GenWithStack(
+				"null value for required field %s", field.Name)
+		}
+		return nil, nil
+	}
+
+	var (
+		result interface{}
+		err    error
+	)
+	switch field.Type {
+	case typeInt:
+		var v int64
+		v, err = toInt64(value)
+		result = int32(v)
+	case typeLong:
+		result, err = toInt64(value)
+	case typeFloat:
+		var v float64
+		v, err = toFloat64(value)
+		result = float32(v)
+	case typeDouble:
+		result, err = toFloat64(value)
+	case typeDate:
+		var t time.Time
+		t, err = time.ParseInLocation(dateLayout, toString(value), time.UTC)
+		result = int32(t.Sub(epoch).Hours() / 24)
+	case typeTimestamp:
+		var t time.Time
+		t, err = time.ParseInLocation(datetimeLayout, toString(value), time.UTC)
+		result = t.UnixMicro()
+	case typeTimestampTz:
+		var t time.Time
+		t, err = time.ParseInLocation(datetimeLayout, toString(value), tz)
+		result = t.UnixMicro()
+	case typeBinary:
+		result = toString(value)
+	case typeString:
+		result, err = columnValueToString(colInfo, value)
+	default:
+		precision, scale, ok := parseDecimalType(field.Type)
+		if !ok {
+			return nil, cerror.ErrIcebergEncodeFailed.GenWithStack(
+				"unsupported iceberg type %s", field.Type)
+		}
+		result, err = toDecimal(value, precision, scale)
+	}
+	if err != nil {
+		if field.Required {
+			return nil, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+		}
+		// The value can not be represented in iceberg, such as a zero date,
+		// it is written as null.
+		return nil, nil
+	}
+	return result, nil
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	default:
+		return model.ColumnValueString(value)
+	}
+}
+
+func columnValueToString(colInfo *timodel.ColumnInfo, value interface{}) (string, error) {
+	v, ok := value.(uint64)
+	if !ok || colInfo == nil {
+		return toString(value), nil
+	}
+	switch colInfo.GetType() {
+	case mysql.TypeEnum:
+		enum, err := types.ParseEnumValue(colInfo.GetElems(), v)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		return enum.Name, nil
+	case mysql.TypeSet:
+		set, err := types.ParseSetValue(colInfo.GetElems(), v)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		return set.Name, nil
+	}
+	return toString(value), nil
+}
+
+func toInt64(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int64:
+		return v, nil
+	case uint64:
+		return int64(v), nil
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	default:
+		return strconv.ParseInt(toString(value), 10, 64)
+	}
+}
+
+func toFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	default:
+		return strconv.ParseFloat(toString(value), 64)
+	}
+}
+
+// toDecimal converts the value to the unscaled decimal value, which is stored
+// as INT32, INT64 or a big-endian two's complement FIXED_LEN_BYTE_ARRAY
+// according to the precision.
+func toDecimal(value interface{}, precision, scale int) (interface{}, error) {
+	str := toString(value)
+	intPart, fracPart, _ := strings.Cut(str, ".")
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+	unscaled, ok := new(big.Int).SetString(intPart+fracPart, 10)
+	if !ok {
+		return nil, errors.Errorf("invalid decimal value %s", str)
+	}
+
+	switch {
+	case precision <= 9:
+		return int32(unscaled.Int64()), nil
+	case precision <= 18:
+		return unscaled.Int64(), nil
+	}
+	length := decimalLength(precision)
+	b := make([]byte, length)
+	if unscaled.Sign() >= 0 {
+		unscaled.FillBytes(b)
+	} else {
+		// two's complement of the negative value.
+		complement := new(big.Int).Lsh(big.NewInt(1), uint(length*8))
+		complement.Add(complement, unscaled)
+		complement.FillBytes(b)
+	}
+	return string(b), nil
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/sink/cloudstorage"
+)
+
+const (
+	typeInt         = "int"
+	typeLong        = "long"
+	typeFloat       = "float"
+	typeDouble      = "double"
+	typeDate        = "date"
+	typeTimestamp   = "timestamp"
+	typeTimestampTz = "timestamptz"
+	typeString      = "string"
+	typeBinary      = "binary"
+
+	// maxDecimalPrecision is the max precision of the iceberg decimal type.
+	maxDecimalPrecision = 38
+)
+
+// NestedField is a field of the iceberg schema.
+type NestedField struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Type     string `json:"type"`
+}
+
+// Schema is the iceberg table schema, only primitive types are used.
+type Schema struct {
+	Type               string        `json:"type"`
+	SchemaID           int           `json:"schema-id"`
+	IdentifierFieldIDs []int         `json:"identifier-field-ids,omitempty"`
+	Fields             []NestedField `json:"fields"`
+}
+
+// FieldByName returns the field with the given name.
+func (s *Schema) FieldByName(name string) (NestedField, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return NestedField{}, false
+}
+
+// sameAs returns true if the two schemas have the same fields and identifiers,
+// the schema id is ignored.
+func (s *Schema) sameAs(other *Schema) bool {
+	if len(s.Fields) != len(other.Fields) ||
+		len(s.IdentifierFieldIDs) != len(other.IdentifierFieldIDs) {
+		return false
+	}
+	for i := range s.Fields {
+		if s.Fields[i] != other.Fields[i] {
+			return false
+		}
+	}
+	for i := range s.IdentifierFieldIDs {
+		if s.IdentifierFieldIDs[i] != other.IdentifierFieldIDs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// NewSchema builds an iceberg schema from the table definition, which is the
+// same one written to the `schema.json` files by the cloud storage sink.
+//
+// The field ids of the previous schema are reused for the columns with the
+// same name and a compatible type, so that the data files written before the
+// DDL can still be read. Otherwise, a new field id is assigned, which means
+// the column is dropped and added again from the view of iceberg.
+// It returns the new schema and the last assigned field id.
+func NewSchema(
+	def *cloudstorage.TableDefinition,
+	identifiers []string,
+	prev *Schema,
+	lastColumnID int,
+) (*Schema, int) {
+	schema := &Schema{Type: "struct"}
+	if prev != nil {
+		schema.SchemaID = prev.SchemaID + 1
+	}
+	identifierSet := make(map[string]struct{}, len(identifiers))
+	for _, name := range identifiers {
+		identifierSet[name] = struct{}{}
+	}
+
+	for _, col := range def.Columns {
+		_, isIdentifier := identifierSet[col.Name]
+		// Only the identifier fields are required, the NOT NULL constraint of
+		// other columns is not kept, because TiDB may store zero values which
+		// can not be represented in iceberg, such as '0000-00-00'.
+		field := NestedField{
+			Name:     col.Name,
+			Required: isIdentifier && col.Nullable == "false",
+			Type:     toIcebergType(col),
+		}
+		if prev != nil {
+			if prevField, ok := prev.FieldByName(col.Name); ok &&
+				isTypePromotionAllowed(prevField.Type, field.Type) {
+				field.ID = prevField.ID
+				// Iceberg only allows to relax the nullability of an existing field.
+				field.Required = field.Required && prevField.Required
+			}
+		}
+		if field.ID == 0 {
+			lastColumnID++
+			field.ID = lastColumnID
+			// Iceberg does not allow to add a required field to an existing
+			// table, since the old data files have no value for it.
+			field.Required = field.Required && prev == nil
+		}
+		schema.Fields = append(schema.Fields, field)
+		if field.Required {
+			schema.IdentifierFieldIDs = append(schema.IdentifierFieldIDs, field.ID)
+		}
+	}
+	// all identifier fields must be required, otherwise the rows can not be
+	// identified by them.
+	if len(schema.IdentifierFieldIDs) != len(identifiers) {
+		schema.IdentifierFieldIDs = nil
+	}
+	return schema, lastColumnID
+}
+
+// NewSchemaFromTableInfo builds an iceberg schema from the table info, the
+// handle key columns are used as the identifier fields.
+func NewSchemaFromTableInfo(
+	tableInfo *model.TableInfo, prev *Schema, lastColumnID int,
+) (*Schema, int) {
+	var def cloudstorage.TableDefinition
+	def.FromTableInfo(tableInfo, tableInfo.Version, false)
+	// virtual generated columns are not replicated.
+	visibleColumns := make([]cloudstorage.TableCol, 0, len(def.Columns))
+	for i, col := range tableInfo.Columns {
+		if model.IsColCDCVisible(col) {
+			visibleColumns = append(visibleColumns, def.Columns[i])
+		}
+	}
+	def.Columns = visibleColumns
+	def.TotalColumns = len(visibleColumns)
+	return NewSchema(&def, handleKeyColumnNames(tableInfo), prev, lastColumnID)
+}
+
+func handleKeyColumnNames(tableInfo *model.TableInfo) []string {
+	var names []string
+	for _, col := range tableInfo.Columns {
+		if !model.IsColCDCVisible(col) {
+			continue
+		}
+		if tableInfo.ForceGetColumnFlagType(col.ID).IsHandleKey() {
+			names = append(names, col.Name.O)
+		}
+	}
+	return names
+}
+
+// toIcebergType converts the column type in the table definition to the
+// iceberg primitive type.
+func toIcebergType(col cloudstorage.TableCol) string {
+	unsigned := strings.HasSuffix(col.Tp, " UNSIGNED")
+	tp := strings.TrimSuffix(col.Tp, " UNSIGNED")
+	switch tp {
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "YEAR":
+		return typeInt
+	case "INT":
+		if unsigned {
+			return typeLong
+		}
+		return typeInt
+	case "BIGINT":
+		if unsigned {
+			// the unsigned bigint may overflow the iceberg long type.
+			return decimalType(20, 0)
+		}
+		return typeLong
+	case "BIT":
+		return typeLong
+	case "FLOAT":
+		return typeFloat
+	case "DOUBLE":
+		return typeDouble
+	case "DECIMAL":
+		precision, err1 := strconv.Atoi(col.Precision)
+		scale, err2 := strconv.Atoi(col.Scale)
+		if err1 != nil || err2 != nil || precision > maxDecimalPrecision {
+			return typeString
+		}
+		return decimalType(precision, scale)
+	case "DATE":
+		return typeDate
+	case "DATETIME":
+		return typeTimestamp
+	case "TIMESTAMP":
+		return typeTimestampTz
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return typeBinary
+	default:
+		// CHAR, VARCHAR, TEXT, ENUM, SET, JSON, TIME and so on.
+		return typeString
+	}
+}
+
+func decimalType(precision, scale int) string {
+	return fmt.Sprintf("decimal(%d, %d)", precision, scale)
+}
+
+// parseDecimalType parses the precision and scale from the decimal type,
+// ok is false if the type is not a decimal.
+func parseDecimalType(tp string) (precision, scale int, ok bool) {
+	if !strings.HasPrefix(tp, "decimal(") {
+		return 0, 0, false
+	}
+	_, err := fmt.Sscanf(tp, "decimal(%d, %d)", &precision, &scale)
+	return precision, scale, err == nil
+}
+
+// isTypePromotionAllowed returns true if the field can be evolved from the old
+// type to the new type without rewriting the data files.
+// See https://iceberg.apache.org/spec/#schema-evolution.
+func isTypePromotionAllowed(oldType, newType string) bool {
+	if oldType == newType {
+		return true
+	}
+	switch oldType {
+	case typeInt:
+		return newType == typeLong
+	case typeFloat:
+		return newType == typeDouble
+	}
+	oldPrecision, oldScale, ok1 := parseDecimalType(oldType)
+	newPrecision, newScale, ok2 := parseDecimalType(newType)
+	return ok1 && ok2 && oldScale == newScale && oldPrecision <= newPrecision
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"testing"
+
+	"github.com/pingcap/tiflow/cdc/entry"
+	"github.com/pingcap/tiflow/pkg/sink/cloudstorage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSchemaFromTableInfo(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	helper.Tk().MustExec("use test")
+	ddl := helper.DDL2Event(`create table t(
+		a int primary key, b bigint unsigned, c decimal(10, 2), d datetime,
+		e timestamp, f varchar(10), g blob, h double, i float, j date,
+		k enum('x', 'y'), l decimal(65, 0), m int unsigned,
+		n int as (a + 1) virtual)`)
+
+	schema, lastColumnID := NewSchemaFromTableInfo(ddl.TableInfo, nil, 0)
+	require.Equal(t, 13, lastColumnID)
+	require.Equal(t, 0, schema.SchemaID)
+	require.Equal(t, []int{1}, schema.IdentifierFieldIDs)
+	expected := []NestedField{
+		{ID: 1, Name: "a", Required: true, Type: typeInt},
+		{ID: 2, Name: "b", Type: "decimal(20, 0)"},
+		{ID: 3, Name: "c", Type: "decimal(10, 2)"},
+		{ID: 4, Name: "d", Type: typeTimestamp},
+		{ID: 5, Name: "e", Type: typeTimestampTz},
+		{ID: 6, Name: "f", Type: typeString},
+		{ID: 7, Name: "g", Type: typeBinary},
+		{ID: 8, Name: "h", Type: typeDouble},
+		{ID: 9, Name: "i", Type: typeFloat},
+		{ID: 10, Name: "j", Type: typeDate},
+		{ID: 11, Name: "k", Type: typeString},
+		{ID: 12, Name: "l", Type: typeString},
+		{ID: 13, Name: "m", Type: typeLong},
+	}
+	require.Equal(t, expected, schema.Fields)
+}
+
+func TestSchemaEvolution(t *testing.T) {
+	t.Parallel()
+
+	def := &cloudstorage.TableDefinition{
+		Columns: []cloudstorage.TableCol{
+			{Name: "id", Tp: "INT", Nullable: "false", IsPK: "true"},
+			{Name: "v1", Tp: "INT"},
+			{Name: "v2", Tp: "VARCHAR"},
+		},
+	}
+	schema, lastColumnID := NewSchema(def, []string{"id"}, nil, 0)
+	require.Equal(t, 3, lastColumnID)
+	metadata := newTableMetadata("file:///tmp/test/t", schema, lastColumnID)
+
+	// widen v1, drop v2 and add v3, v2 is added back with another type.
+	def = &cloudstorage.TableDefinition{
+		Columns: []cloudstorage.TableCol{
+			{Name: "id", Tp: "BIGINT", Nullable: "false", IsPK: "true"},
+			{Name: "v1", Tp: "BIGINT"},
+			{Name: "v3", Tp: "INT", Nullable: "false"},
+			{Name: "v2", Tp: "DOUBLE"},
+		},
+	}
+	evolved, lastColumnID := NewSchema(def, []string{"id"}, metadata.CurrentSchema(), metadata.LastColumnID)
+	require.Equal(t, 5, lastColumnID)
+	require.Equal(t, []NestedField{
+		{ID: 1, Name: "id", Required: true, Type: typeLong},
+		{ID: 2, Name: "v1", Type: typeLong},
+		{ID: 4, Name: "v3", Type: typeInt},
+		{ID: 5, Name: "v2", Type: typeDouble},
+	}, evolved.Fields)
+	require.Equal(t, []int{1}, evolved.IdentifierFieldIDs)
+
+	require.True(t, metadata.evolveSchema(evolved, lastColumnID))
+	require.Equal(t, 1, metadata.CurrentSchemaID)
+	require.Equal(t, 5, metadata.LastColumnID)
+	require.Len(t, metadata.Schemas, 2)
+
+	// the same schema does not change the metadata.
+	same, _ := NewSchema(def, []string{"id"}, metadata.CurrentSchema(), metadata.LastColumnID)
+	require.False(t, metadata.evolveSchema(same, lastColumnID))
+
+	// the schema used before is reused.
+	require.True(t, metadata.evolveSchema(schema, 3))
+	require.Equal(t, 0, metadata.CurrentSchemaID)
+	require.Len(t, metadata.Schemas, 2)
+	require.Equal(t, 5, metadata.LastColumnID)
+
+	// the identifier fields are removed if the primary key is nullable.
+	def = &cloudstorage.TableDefinition{
+		Columns: []cloudstorage.TableCol{
+			{Name: "id", Tp: "INT", IsPK: "true"},
+		},
+	}
+	schema, _ = NewSchema(def, []string{"id"}, nil, 0)
+	require.Empty(t, schema.IdentifierFieldIDs)
+}
+
+func TestIsTypePromotionAllowed(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, isTypePromotionAllowed(typeInt, typeLong))
+	require.True(t, isTypePromotionAllowed(typeFloat, typeDouble))
+	require.True(t, isTypePromotionAllowed("decimal(10, 2)", "decimal(12, 2)"))
+	require.False(t, isTypePromotionAllowed("decimal(10, 2)", "decimal(12, 3)"))
+	require.False(t, isTypePromotionAllowed("decimal(10, 2)", "decimal(9, 2)"))
+	require.False(t, isTypePromotionAllowed(typeLong, typeInt))
+	require.False(t, isTypePromotionAllowed(typeString, typeBinary))
+}
@@ -0,0 +1,465 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	metadataDir     = "metadata"
+	dataDir         = "data"
+	versionHintFile = "version-hint.text"
+
+	// maxCommitRetry is the max number of retries when the commit conflicts
+	// with other writers.
+	maxCommitRetry = 3
+)
+
+// Table is an iceberg table stored in the external storage. The layout is
+// the same as the hadoop catalog, so that the tables can be read by the
+// query engines directly:
+//
+//	<schema>/<table>/metadata/v<N>.metadata.json
+//	<schema>/<table>/metadata/version-hint.text
+//	<schema>/<table>/metadata/snap-<snapshot-id>-<uuid>.avro
+//	<schema>/<table>/metadata/<uuid>-m0.avro
+//	<schema>/<table>/data/<uuid>.parquet
+//
+// A new metadata file is written for every schema change and every snapshot.
+type Table struct {
+	changefeedID model.ChangeFeedID
+	storage      storage.ExternalStorage
+	// dir is the path of the table relative to the storage root.
+	dir string
+	// location is the absolute location of the table.
+	location string
+
+	// version is the version of the loaded metadata file, 0 means the table
+	// does not exist.
+	version  int
+	metadata *TableMetadata
+}
+
+// NewTable creates a table, the metadata is not loaded until Load is called.
+func NewTable(
+	changefeedID model.ChangeFeedID,
+	storage storage.ExternalStorage,
+	rootLocation string,
+	schema, table string,
+) *Table {
+	dir := path.Join(schema, table)
+	return &Table{
+		changefeedID: changefeedID,
+		storage:      storage,
+		dir:          dir,
+		location:     rootLocation + "/" + dir,
+	}
+}
+
+// Metadata returns the loaded metadata, nil is returned if the table does
+// not exist.
+func (t *Table) Metadata() *TableMetadata {
+	return t.metadata
+}
+
+// Load loads the latest metadata of the table.
+func (t *Table) Load(ctx context.Context) error {
+	version := 0
+	hintPath := path.Join(t.dir, metadataDir, versionHintFile)
+	exist, err := t.storage.FileExists(ctx, hintPath)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exist {
+		hint, err := t.storage.ReadFile(ctx, hintPath)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		version, err = strconv.Atoi(strings.TrimSpace(string(hint)))
+		if err != nil {
+			return cerror.WrapError(cerror.ErrIcebergSinkInvalidConfig, err)
+		}
+	}
+	// the version hint may be stale, since it is written after the metadata.
+	for {
+		exist, err := t.storage.FileExists(ctx, path.Join(t.dir, t.metadataFilePath(version+1)))
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !exist {
+			break
+		}
+		version++
+	}
+	if version == 0 {
+		t.version, t.metadata = 0, nil
+		return nil
+	}
+
+	data, err := t.readFile(ctx, t.metadataFilePath(version))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	metadata := &TableMetadata{}
+	if err := json.Unmarshal(data, metadata); err != nil {
+		return cerror.WrapError(cerror.ErrIcebergSinkInvalidConfig, err)
+	}
+	t.version, t.metadata = version, metadata
+	return nil
+}
+
+// UpdateSchema creates the table with the schema if it does not exist, or
+// sets the schema as the current schema of the table.
+func (t *Table) UpdateSchema(
+	ctx context.Context, schema *Schema, lastColumnID int, tableVersion uint64,
+) error {
+	return t.commit(ctx, func(m *TableMetadata) (*TableMetadata, bool, error) {
+		if m == nil {
+			m = newTableMetadata(t.location, schema, lastColumnID)
+			m.Properties[propertyChangefeed] = t.changefeedID.String()
+		} else if !m.evolveSchema(schema, lastColumnID) {
+			return m, false, nil
+		}
+		m.Properties[propertyUpsert] = strconv.FormatBool(len(schema.IdentifierFieldIDs) > 0)
+		m.Properties[propertyTiDBTableVer] = strconv.FormatUint(tableVersion, 10)
+		return m, true, nil
+	})
+}
+
+// FlushBatch writes the rows of the batch which have not been flushed to the
+// data file and the equality delete file, the files are committed by the
+// next WriteBatch of the batch.
+func (t *Table) FlushBatch(ctx context.Context, batch *Batch) error {
+	if rows := batch.deleteRows(); len(rows) > 0 {
+		file, err := t.writeDataFile(ctx, ContentEqualityDeletes,
+			batch.equalityFields, rows, batch.equalityFieldIDs())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		batch.files = append(batch.files, file)
+	}
+	keys, rows, err := batch.dataRows()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var filePath string
+	if len(rows) > 0 {
+		file, err := t.writeDataFile(ctx, ContentData, batch.schema.Fields, rows, nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		batch.files = append(batch.files, file)
+		filePath = file.FilePath
+	}
+	batch.markFlushed(filePath, keys)
+	return nil
+}
+
+// WriteBatch flushes the batch, and then commits a new snapshot with all the
+// files written by the batch. The batch can not be reused after it is written.
+// The schema of the batch must be the current schema of the table.
+func (t *Table) WriteBatch(ctx context.Context, batch *Batch) error {
+	if err := t.FlushBatch(ctx, batch); err != nil {
+		return errors.Trace(err)
+	}
+	files := batch.files
+	if rows := batch.positionDeleteRows(); len(rows) > 0 {
+		file, err := t.writeDataFile(ctx, ContentPositionDeletes,
+			positionDeleteFields, rows, nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return t.commitSnapshot(ctx, batch.schema, files, batch.commitTs, false)
+}
+
+// Truncate commits a new snapshot which removes all the files of the table.
+func (t *Table) Truncate(ctx context.Context, commitTs uint64) error {
+	return t.commitSnapshot(ctx, nil, nil, commitTs, true)
+}
+
+func (t *Table) writeDataFile(
+	ctx context.Context,
+	content FileContent,
+	fields []NestedField,
+	rows [][]interface{},
+	equalityIDs []int,
+) (DataFile, error) {
+	data, err := encodeParquet(fields, rows)
+	if err != nil {
+		return DataFile{}, errors.Trace(err)
+	}
+	name := uuid.New().String()
+	switch content {
+	case ContentEqualityDeletes:
+		name += "-eq-delete"
+	case ContentPositionDeletes:
+		name += "-pos-delete"
+	}
+	filePath := path.Join(dataDir, name+".parquet")
+	if err := t.writeFile(ctx, filePath, data); err != nil {
+		return DataFile{}, errors.Trace(err)
+	}
+	return DataFile{
+		Content:         content,
+		FilePath:        t.absPath(filePath),
+		RecordCount:     int64(len(rows)),
+		FileSizeInBytes: int64(len(data)),
+		EqualityIDs:     equalityIDs,
+	}, nil
+}
+
+// commitSnapshot commits a snapshot which adds the files to the current
+// snapshot, or replaces all the files of the current snapshot if replace is
+// true.
+func (t *Table) commitSnapshot(
+	ctx context.Context, schema *Schema, files []DataFile, commitTs uint64, replace bool,
+) error {
+	return t.commit(ctx, func(m *TableMetadata) (*TableMetadata, bool, error) {
+		if m == nil {
+			if replace {
+				return nil, false, nil
+			}
+			return nil, false, cerror.ErrIcebergSinkInvalidConfig.GenWithStack(
+				"iceberg table %s does not exist", t.dir)
+		}
+		current := m.CurrentSchema()
+		if schema != nil && !current.sameAs(schema) {
+			return nil, false, cerror.ErrIcebergSinkInvalidConfig.GenWithStack(
+				"the schema of iceberg table %s has been changed, current schema id: %d",
+				t.dir, m.CurrentSchemaID)
+		}
+
+		snapshot := &Snapshot{
+			SnapshotID:     rand.Int63(),
+			SequenceNumber: m.LastSequenceNumber + 1,
+			TimestampMs:    time.Now().UnixMilli(),
+			SchemaID:       m.CurrentSchemaID,
+			Summary:        make(map[string]string),
+		}
+		var manifests []ManifestFile
+		if parent := m.CurrentSnapshot(); parent != nil {
+			parentID := parent.SnapshotID
+			snapshot.ParentSnapshotID = &parentID
+			if !replace {
+				data, err := t.readFile(ctx, t.relPath(parent.ManifestList))
+				if err != nil {
+					return nil, false, errors.Trace(err)
+				}
+				manifests, err = decodeManifestList(data)
+				if err != nil {
+					return nil, false, cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+				}
+			}
+		}
+
+		var dataFiles, deleteFiles []DataFile
+		var addedRecords, addedEqDeletes, addedPosDeletes int64
+		for _, f := range files {
+			switch f.Content {
+			case ContentData:
+				dataFiles = append(dataFiles, f)
+				addedRecords += f.RecordCount
+			case ContentEqualityDeletes:
+				deleteFiles = append(deleteFiles, f)
+				addedEqDeletes += f.RecordCount
+			case ContentPositionDeletes:
+				deleteFiles = append(deleteFiles, f)
+				addedPosDeletes += f.RecordCount
+			}
+		}
+		for _, group := range [][]DataFile{dataFiles, deleteFiles} {
+			if len(group) == 0 {
+				continue
+			}
+			manifest, err := t.writeManifest(ctx, current, snapshot, group)
+			if err != nil {
+				return nil, false, errors.Trace(err)
+			}
+			manifests = append(manifests, manifest)
+		}
+
+		manifestList, err := encodeManifestList(
+			snapshot.SnapshotID, snapshot.ParentSnapshotID, snapshot.SequenceNumber, manifests)
+		if err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		manifestListPath := path.Join(metadataDir,
+			fmt.Sprintf("snap-%d-%s.avro", snapshot.SnapshotID, uuid.New().String()))
+		if err := t.writeFile(ctx, manifestListPath, manifestList); err != nil {
+			return nil, false, errors.Trace(err)
+		}
+		snapshot.ManifestList = t.absPath(manifestListPath)
+
+		operation := operationAppend
+		switch {
+		case replace || len(dataFiles) == 0:
+			operation = operationDelete
+		case len(deleteFiles) > 0:
+			operation = operationOverwrite
+		}
+		snapshot.Summary[summaryOperation] = operation
+		snapshot.Summary[summaryCommitTs] = strconv.FormatUint(commitTs, 10)
+		snapshot.Summary[summaryAddedDataFiles] = strconv.Itoa(len(dataFiles))
+		snapshot.Summary[summaryAddedDeleteFile] = strconv.Itoa(len(deleteFiles))
+		snapshot.Summary[summaryAddedRecords] = strconv.FormatInt(addedRecords, 10)
+		snapshot.Summary[summaryAddedEqDeletes] = strconv.FormatInt(addedEqDeletes, 10)
+		snapshot.Summary[summaryAddedPosDeletes] = strconv.FormatInt(addedPosDeletes, 10)
+		m.addSnapshot(snapshot)
+		return m, true, nil
+	})
+}
+
+func (t *Table) writeManifest(
+	ctx context.Context, schema *Schema, snapshot *Snapshot, files []DataFile,
+) (ManifestFile, error) {
+	data, err := encodeManifest(schema, snapshot.SnapshotID, snapshot.SequenceNumber, files)
+	if err != nil {
+		return ManifestFile{}, errors.Trace(err)
+	}
+	manifestPath := path.Join(metadataDir, uuid.New().String()+"-m0.avro")
+	if err := t.writeFile(ctx, manifestPath, data); err != nil {
+		return ManifestFile{}, errors.Trace(err)
+	}
+
+	manifest := ManifestFile{
+		Path:              t.absPath(manifestPath),
+		Length:            int64(len(data)),
+		Content:           manifestContentData,
+		SequenceNumber:    snapshot.SequenceNumber,
+		MinSequenceNumber: snapshot.SequenceNumber,
+		AddedSnapshotID:   snapshot.SnapshotID,
+		AddedFilesCount:   int32(len(files)),
+	}
+	if files[0].Content != ContentData {
+		manifest.Content = manifestContentDeletes
+	}
+	for _, f := range files {
+		manifest.AddedRowsCount += f.RecordCount
+	}
+	return manifest, nil
+}
+
+// commit reloads the latest metadata, applies the update to it and writes
+// the updated metadata as a new version. The update is retried if the new
+// version has been written by others.
+func (t *Table) commit(
+	ctx context.Context,
+	update func(m *TableMetadata) (*TableMetadata, bool, error),
+) error {
+	var err error
+	for i := 0; i < maxCommitRetry; i++ {
+		if err = t.Load(ctx); err != nil {
+			return errors.Trace(err)
+		}
+		metadata, changed, err1 := update(t.metadata)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if !changed {
+			return nil
+		}
+		err = t.writeMetadata(ctx, metadata)
+		if err == nil {
+			return nil
+		}
+		if !cerror.ErrIcebergCommitConflict.Equal(errors.Cause(err)) {
+			return errors.Trace(err)
+		}
+		log.Warn("iceberg commit conflicts, retry",
+			zap.String("namespace", t.changefeedID.Namespace),
+			zap.String("changefeed", t.changefeedID.ID),
+			zap.String("table", t.dir),
+			zap.Int("version", t.version+1),
+			zap.Error(err))
+	}
+	return errors.Trace(err)
+}
+
+// writeMetadata writes the metadata as the next version of the table.
+// The external storage does not support conditional writes, so the conflict
+// detection is best effort: the version is checked before the write.
+func (t *Table) writeMetadata(ctx context.Context, metadata *TableMetadata) error {
+	version := t.version + 1
+	metadataPath := t.metadataFilePath(version)
+	exist, err := t.storage.FileExists(ctx, path.Join(t.dir, metadataPath))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if exist {
+		return cerror.ErrIcebergCommitConflict.GenWithStackByArgs(t.dir, version)
+	}
+
+	now := time.Now().UnixMilli()
+	if t.version > 0 {
+		metadata.MetadataLog = append(metadata.MetadataLog, MetadataLogEntry{
+			MetadataFile: t.absPath(t.metadataFilePath(t.version)),
+			TimestampMs:  metadata.LastUpdatedMs,
+		})
+	}
+	metadata.LastUpdatedMs = now
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return cerror.WrapError(cerror.ErrIcebergEncodeFailed, err)
+	}
+	if err := t.writeFile(ctx, metadataPath, data); err != nil {
+		return errors.Trace(err)
+	}
+	t.version, t.metadata = version, metadata
+
+	return errors.Trace(t.writeFile(ctx,
+		path.Join(metadataDir, versionHintFile), []byte(strconv.Itoa(version))))
+}
+
+func (t *Table) metadataFilePath(version int) string {
+	return path.Join(metadataDir, fmt.Sprintf("v%d.metadata.json", version))
+}
+
+// absPath returns the absolute location of the file in the table.
+func (t *Table) absPath(p string) string {
+	return t.location + "/" + p
+}
+
+// relPath returns the path of the file relative to the table directory.
+func (t *Table) relPath(location string) string {
+	return strings.TrimPrefix(location, t.location+"/")
+}
+
+func (t *Table) readFile(ctx context.Context, p string) ([]byte, error) {
+	return t.storage.ReadFile(ctx, path.Join(t.dir, p))
+}
+
+func (t *Table) writeFile(ctx context.Context, p string, data []byte) error {
+	return t.storage.WriteFile(ctx, path.Join(t.dir, p), data)
+}
@@ -0,0 +1,321 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iceberg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tiflow/cdc/entry"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+func readParquet(t *testing.T, data []byte, columns int) [][]interface{} {
+	pf, err := buffer.NewBufferFile(data)
+	require.NoError(t, err)
+	pr, err := reader.NewParquetColumnReader(pf, 1)
+	require.NoError(t, err)
+	defer pr.ReadStop()
+
+	num := pr.GetNumRows()
+	rows := make([][]interface{}, num)
+	for i := 0; i < columns; i++ {
+		values, _, _, err := pr.ReadColumnByIndex(int64(i), num)
+		require.NoError(t, err)
+		require.Len(t, values, int(num))
+		for j, v := range values {
+			rows[j] = append(rows[j], v)
+		}
+	}
+	return rows
+}
+
+func readFileByLocation(
+	t *testing.T, s storage.ExternalStorage, root, location string,
+) []byte {
+	require.Contains(t, location, root+"/")
+	data, err := s.ReadFile(context.Background(), location[len(root)+1:])
+	require.NoError(t, err)
+	return data
+}
+
+func TestTableWriteBatch(t *testing.T) {
+	helper := entry.NewSchemaTestHelper(t)
+	defer helper.Close()
+
+	helper.Tk().MustExec("use test")
+	helper.Tk().MustExec("set sql_mode = ''")
+	ddl := helper.DDL2Event(`create table t(
+		id int primary key, name varchar(32), price decimal(22, 2),
+		created datetime(6), birthday date, kind enum('a', 'b'))`)
+	insert1 := helper.DML2Event(`insert into t values
+		(1, 'alice', 12.5, '2024-01-02 03:04:05.123456', '2000-01-01', 'a')`, "test", "t")
+	insert2 := helper.DML2Event(`insert into t values
+		(2, 'bob', -3.25, '2024-01-02 03:04:05', '0000-00-00', 'b')`, "test", "t")
+	update2 := helper.DML2Event(`update t set name = 'bob2' where id = 2`, "test", "t")
+	update2.PreColumns = insert2.Columns
+	delete1 := &model.RowChangedEvent{
+		CommitTs:   update2.CommitTs + 1,
+		TableInfo:  insert1.TableInfo,
+		PreColumns: insert1.Columns,
+	}
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	root := "file://" + dir
+	s, err := util.GetExternalStorageFromURI(ctx, root)
+	require.NoError(t, err)
+	changefeedID := model.DefaultChangeFeedID("test")
+
+	table := NewTable(changefeedID, s, root, "test", "t")
+	require.NoError(t, table.Load(ctx))
+	require.Nil(t, table.Metadata())
+	schema, lastColumnID := NewSchemaFromTableInfo(ddl.TableInfo, nil, 0)
+	require.NoError(t, table.UpdateSchema(ctx, schema, lastColumnID, ddl.TableInfo.Version))
+	require.Equal(t, 1, table.version)
+	// the same schema does not create a new version.
+	require.NoError(t, table.UpdateSchema(ctx, schema, lastColumnID, ddl.TableInfo.Version))
+	require.Equal(t, 1, table.version)
+
+	// the first batch inserts two rows and updates one of them.
+	batch := NewBatch(table.Metadata().CurrentSchema(), time.UTC)
+	for _, row := range []*model.RowChangedEvent{insert1, insert2, update2} {
+		require.NoError(t, batch.AppendRow(row))
+	}
+	require.Equal(t, 3, batch.Len())
+	require.NoError(t, table.WriteBatch(ctx, batch))
+
+	reloaded := NewTable(changefeedID, s, root, "test", "t")
+	require.NoError(t, reloaded.Load(ctx))
+	require.Equal(t, 2, reloaded.version)
+	metadata := reloaded.Metadata()
+	require.Equal(t, root+"/test/t", metadata.Location)
+	require.Equal(t, "true", metadata.Properties[propertyUpsert])
+	require.Len(t, metadata.MetadataLog, 1)
+	snapshot := metadata.CurrentSnapshot()
+	require.NotNil(t, snapshot)
+	require.Nil(t, snapshot.ParentSnapshotID)
+	require.Equal(t, int64(1), snapshot.SequenceNumber)
+	require.Equal(t, operationOverwrite, snapshot.Summary[summaryOperation])
+	require.Equal(t, fmt.Sprint(update2.CommitTs), snapshot.Summary[summaryCommitTs])
+	require.Equal(t, "2", snapshot.Summary[summaryAddedRecords])
+	require.Equal(t, "2", snapshot.Summary[summaryAddedEqDeletes])
+
+	hint, err := s.ReadFile(ctx, path.Join("test", "t", metadataDir, versionHintFile))
+	require.NoError(t, err)
+	require.Equal(t, "2", string(hint))
+
+	manifests, err := decodeManifestList(readFileByLocation(t, s, root, snapshot.ManifestList))
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+	require.Equal(t, int32(manifestContentData), manifests[0].Content)
+	require.Equal(t, int32(manifestContentDeletes), manifests[1].Content)
+
+	dataFiles, err := decodeManifest(readFileByLocation(t, s, root, manifests[0].Path))
+	require.NoError(t, err)
+	require.Len(t, dataFiles, 1)
+	require.Equal(t, ContentData, dataFiles[0].Content)
+	require.Equal(t, int64(2), dataFiles[0].RecordCount)
+	rows := readParquet(t, readFileByLocation(t, s, root, dataFiles[0].FilePath), 6)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC).UnixMicro()
+	birthday := int32(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+	require.Equal(t, [][]interface{}{
+		{int32(1), "alice", "\x00\x00\x00\x00\x00\x00\x00\x00\x04\xe2", created, birthday, "a"},
+		{int32(2), "bob2", "\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xbb", created / 1e6 * 1e6, nil, "b"},
+	}, rows)
+
+	deleteFiles, err := decodeManifest(readFileByLocation(t, s, root, manifests[1].Path))
+	require.NoError(t, err)
+	require.Len(t, deleteFiles, 1)
+	require.Equal(t, ContentEqualityDeletes, deleteFiles[0].Content)
+	require.Equal(t, []int{1}, deleteFiles[0].EqualityIDs)
+	rows = readParquet(t, readFileByLocation(t, s, root, deleteFiles[0].FilePath), 1)
+	require.Equal(t, [][]interface{}{{int32(1)}, {int32(2)}}, rows)
+
+	// the second batch deletes a row.
+	batch = NewBatch(reloaded.Metadata().CurrentSchema(), time.UTC)
+	require.NoError(t, batch.AppendRow(delete1))
+	require.NoError(t, reloaded.WriteBatch(ctx, batch))
+	require.NoError(t, table.Load(ctx))
+	snapshot = table.Metadata().CurrentSnapshot()
+	require.Equal(t, int64(2), snapshot.SequenceNumber)
+	require.Equal(t, operationDelete, snapshot.Summary[summaryOperation])
+	require.Equal(t, metadata.CurrentSnapshotID, *snapshot.ParentSnapshotID)
+	manifests, err = decodeManifestList(readFileByLocation(t, s, root, snapshot.ManifestList))
+	require.NoError(t, err)
+	require.Len(t, manifests, 3)
+	require.Equal(t, int64(2), manifests[2].SequenceNumber)
+
+	// truncate removes all the files.
+	require.NoError(t, table.Truncate(ctx, delete1.CommitTs+1))
+	snapshot = table.Metadata().CurrentSnapshot()
+	manifests, err = decodeManifestList(readFileByLocation(t, s, root, snapshot.ManifestList))
+	require.NoError(t, err)
+	require.Empty(t, manifests)
+
+	// the metadata is valid json which can be read by other engines.
+	data, err := s.ReadFile(ctx, path.Join("test", "t", metadataDir, "v4.metadata.json"))
+	require.NoError(t, err)
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &m))
+	require.Equal(t, float64(formatVersion), m["format-version"])
+}
+
+func TestTableCommitConflict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	root := "file://" + dir
+	s, err := util.GetExternalStorageFromURI(ctx, root)
+	require.NoError(t, err)
+	changefeedID := model.DefaultChangeFeedID("test")
+
+	schema := &Schema{Type: "struct", Fields: []NestedField{{ID: 1, Name: "a", Type: typeLong}}}
+	t1 := NewTable(changefeedID, s, root, "test", "t")
+	require.NoError(t, t1.UpdateSchema(ctx, schema, 1, 1))
+	t2 := NewTable(changefeedID, s, root, "test", "t")
+	require.NoError(t, t2.Load(ctx))
+
+	// t1 commits version 2, so t2 conflicts on version 2 and retries.
+	newBatch := func() *Batch {
+		batch := NewBatch(schema, time.UTC)
+		require.NoError(t, batch.upsert([]interface{}{int64(1)}))
+		batch.commitTs, batch.events = 1, 1
+		return batch
+	}
+	require.NoError(t, t1.WriteBatch(ctx, newBatch()))
+	err = t2.writeMetadata(ctx, t2.Metadata())
+	require.True(t, cerror.ErrIcebergCommitConflict.Equal(err))
+	require.NoError(t, t2.WriteBatch(ctx, newBatch()))
+	require.Equal(t, 3, t2.version)
+	require.Equal(t, int64(2), t2.Metadata().LastSequenceNumber)
+}
+
+func readSnapshotFiles(
+	t *testing.T, s storage.ExternalStorage, root string, snapshot *Snapshot,
+) map[FileContent][]DataFile {
+	manifests, err := decodeManifestList(readFileByLocation(t, s, root, snapshot.ManifestList))
+	require.NoError(t, err)
+	files := make(map[FileContent][]DataFile)
+	for _, m := range manifests {
+		entries, err := decodeManifest(readFileByLocation(t, s, root, m.Path))
+		require.NoError(t, err)
+		for _, f := range entries {
+			files[f.Content] = append(files[f.Content], f)
+		}
+	}
+	return files
+}
+
+func TestTableFlushBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	root := "file://" + t.TempDir()
+	s, err := util.GetExternalStorageFromURI(ctx, root)
+	require.NoError(t, err)
+
+	schema := &Schema{
+		Type:               "struct",
+		IdentifierFieldIDs: []int{1},
+		Fields: []NestedField{
+			{ID: 1, Name: "id", Required: true, Type: typeLong},
+			{ID: 2, Name: "v", Type: typeString},
+		},
+	}
+	table := NewTable(model.DefaultChangeFeedID("test"), s, root, "test", "t")
+	require.NoError(t, table.UpdateSchema(ctx, schema, 2, 1))
+
+	// the rows are flushed before the batch is committed, and then changed.
+	batch := NewBatch(schema, time.UTC)
+	require.NoError(t, batch.upsert([]interface{}{int64(1), "a"}))
+	require.NoError(t, batch.upsert([]interface{}{int64(2), "b"}))
+	require.NoError(t, table.FlushBatch(ctx, batch))
+	require.Nil(t, table.Metadata().CurrentSnapshot())
+	require.NoError(t, batch.upsert([]interface{}{int64(1), "c"}))
+	require.NoError(t, batch.delete([]interface{}{int64(2), "b"}))
+	batch.commitTs, batch.events = 1, 4
+	require.NoError(t, table.WriteBatch(ctx, batch))
+
+	snapshot := table.Metadata().CurrentSnapshot()
+	require.NotNil(t, snapshot)
+	require.Equal(t, "3", snapshot.Summary[summaryAddedRecords])
+	require.Equal(t, "4", snapshot.Summary[summaryAddedEqDeletes])
+	require.Equal(t, "2", snapshot.Summary[summaryAddedPosDeletes])
+
+	files := readSnapshotFiles(t, s, root, snapshot)
+	require.Len(t, files[ContentData], 2)
+	first := files[ContentData][0]
+	require.Equal(t, [][]interface{}{{int64(1), "a"}, {int64(2), "b"}},
+		readParquet(t, readFileByLocation(t, s, root, first.FilePath), 2))
+	require.Equal(t, [][]interface{}{{int64(1), "c"}},
+		readParquet(t, readFileByLocation(t, s, root, files[ContentData][1].FilePath), 2))
+	require.Len(t, files[ContentEqualityDeletes], 2)
+	// the equality deletes don't apply to the data files of the same
+	// snapshot, so the flushed rows are deleted by positions.
+	require.Len(t, files[ContentPositionDeletes], 1)
+	require.Nil(t, files[ContentPositionDeletes][0].EqualityIDs)
+	require.Equal(t, [][]interface{}{{first.FilePath, int64(0)}, {first.FilePath, int64(1)}},
+		readParquet(t, readFileByLocation(t, s, root, files[ContentPositionDeletes][0].FilePath), 2))
+}
+
+func TestBatchKeyless(t *testing.T) {
+	t.Parallel()
+
+	schema := &Schema{Type: "struct", Fields: []NestedField{
+		{ID: 1, Name: "a", Type: typeLong},
+		{ID: 2, Name: "b", Type: typeString},
+	}}
+	batch := NewBatch(schema, time.UTC)
+	x := []interface{}{int64(1), "x"}
+	y := []interface{}{int64(2), "y"}
+	z := []interface{}{int64(3), "z"}
+	// identical rows are all kept.
+	require.NoError(t, batch.upsert(x))
+	require.NoError(t, batch.upsert(y))
+	require.NoError(t, batch.upsert(x))
+	require.NoError(t, batch.upsert(x))
+	// only one of the identical rows inserted by the batch is deleted.
+	require.NoError(t, batch.delete(x))
+	// the row not inserted by the batch is deleted by equality delete.
+	require.NoError(t, batch.delete(z))
+
+	keys, rows, err := batch.dataRows()
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+	require.Equal(t, [][]interface{}{x, y, x}, rows)
+	require.Equal(t, [][]interface{}{z}, batch.deleteRows())
+
+	// the flushed row is deleted by position.
+	batch.markFlushed("f1", keys)
+	require.NoError(t, batch.delete(y))
+	require.NoError(t, batch.delete(x))
+	require.Equal(t, [][]interface{}{{"f1", int64(1)}, {"f1", int64(2)}},
+		batch.positionDeleteRows())
+	_, rows, err = batch.dataRows()
+	require.NoError(t, err)
+	require.Empty(t, rows)
+	require.Empty(t, batch.deleteRows())
+}
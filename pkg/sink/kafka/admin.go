@@ -166,6 +166,13 @@ func (a *saramaAdminClient) CreateTopic(
 		NumPartitions:     detail.NumPartitions,
 		ReplicationFactor: detail.ReplicationFactor,
 	}
+	if len(detail.ConfigEntries) != 0 {
+		request.ConfigEntries = make(map[string]*string, len(detail.ConfigEntries))
+		for name, value := range detail.ConfigEntries {
+			value := value
+			request.ConfigEntries[name] = &value
+		}
+	}
 
 	err := a.admin.CreateTopic(detail.Name, request, validateOnly)
 	// Ignore the already exists error because it's not harmful.
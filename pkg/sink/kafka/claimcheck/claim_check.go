@@ -16,6 +16,7 @@ package claimcheck
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"strings"
 	"time"
 
@@ -119,9 +120,15 @@ func (c *ClaimCheck) CleanMetrics() {
 }
 
 // NewFileName return the file name for the message which is delivered to the external storage system.
-// UUID V4 is used to generate random and unique file names.
+// UUID V4 is used to generate random and unique file names, which is prefixed by the changefeed ID
+// and the write time, so that the expired files of the changefeed can be found by the Cleaner.
 // This should not exceed the S3 object name length limit.
 // ref https://docs.aws.amazon.com/AmazonS3/latest/userguide/object-keys.html
-func NewFileName() string {
-	return uuid.NewString() + ".json"
+func (c *ClaimCheck) NewFileName() string {
+	return newFileName(c.changefeedID, time.Now())
+}
+
+func newFileName(changefeedID model.ChangeFeedID, now time.Time) string {
+	return fileNamePrefix(changefeedID) + strconv.FormatInt(now.UnixMilli(), 10) +
+		fileNameSeparator + uuid.NewString() + fileNameSuffix
 }
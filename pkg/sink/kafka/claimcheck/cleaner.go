@@ -0,0 +1,194 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package claimcheck
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/tidb/br/pkg/storage"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/util"
+	"github.com/tikv/client-go/v2/oracle"
+	"go.uber.org/zap"
+)
+
+const (
+	fileNameSeparator = "_"
+	fileNameSuffix    = ".json"
+)
+
+// fileNamePrefix returns the prefix of the claim-check files of the changefeed,
+// the namespace and the changefeed ID never contain the separator.
+func fileNamePrefix(changefeedID model.ChangeFeedID) string {
+	return changefeedID.Namespace + fileNameSeparator + changefeedID.ID + fileNameSeparator
+}
+
+// parseWriteTime returns the write time of the claim-check file of the changefeed,
+// it returns false if the file is not written by the changefeed.
+func parseWriteTime(changefeedID model.ChangeFeedID, name string) (time.Time, bool) {
+	prefix := fileNamePrefix(changefeedID)
+	if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, fileNameSuffix) {
+		return time.Time{}, false
+	}
+	unixMilli, _, ok := strings.Cut(strings.TrimPrefix(name, prefix), fileNameSeparator)
+	if !ok {
+		return time.Time{}, false
+	}
+	ms, err := strconv.ParseInt(unixMilli, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(ms), true
+}
+
+// File is a claim-check file written by a changefeed.
+type File struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	WriteTime time.Time `json:"write-time"`
+	Expired   bool      `json:"expired"`
+}
+
+// Cleaner removes the claim-check files of a changefeed from the external storage.
+// A file is expired if it's written before the retention ahead of both the checkpoint
+// of the changefeed and now, at that time the message referring to it has been
+// removed from the topic by the topic retention.
+type Cleaner struct {
+	changefeedID model.ChangeFeedID
+	storage      storage.ExternalStorage
+	retention    time.Duration
+	now          func() time.Time
+}
+
+// NewCleaner creates a new Cleaner, it returns nil if the claim-check is disabled.
+func NewCleaner(
+	ctx context.Context, config *config.LargeMessageHandleConfig, changefeedID model.ChangeFeedID,
+) (*Cleaner, error) {
+	if !config.EnableClaimCheck() {
+		return nil, nil
+	}
+	externalStorage, err := util.GetExternalStorageWithTimeout(ctx, config.ClaimCheckStorageURI, defaultTimeout)
+	if err != nil {
+		log.Error("create external storage for the claim-check cleaner failed",
+			zap.String("namespace", changefeedID.Namespace),
+			zap.String("changefeed", changefeedID.ID),
+			zap.String("storageURI", util.MaskSensitiveDataInURI(config.ClaimCheckStorageURI)),
+			zap.Error(err))
+		return nil, errors.Trace(err)
+	}
+	return newCleaner(externalStorage, changefeedID, config.GetClaimCheckRetention()), nil
+}
+
+func newCleaner(
+	storage storage.ExternalStorage, changefeedID model.ChangeFeedID, retention time.Duration,
+) *Cleaner {
+	return &Cleaner{
+		changefeedID: changefeedID,
+		storage:      storage,
+		retention:    retention,
+		now:          time.Now,
+	}
+}
+
+// Retention returns the retention of the files, the files are never expired if it's 0.
+func (c *Cleaner) Retention() time.Duration {
+	return c.retention
+}
+
+// SetRetention sets the retention of the files if it's not set by the config.
+func (c *Cleaner) SetRetention(retention time.Duration) {
+	if c.retention == 0 {
+		c.retention = retention
+	}
+}
+
+// ListFiles returns all claim-check files of the changefeed.
+func (c *Cleaner) ListFiles(ctx context.Context, checkpointTs model.Ts) ([]File, error) {
+	deadline, ok := c.deadline(checkpointTs)
+	var files []File
+	err := c.storage.WalkDir(ctx, &storage.WalkOption{
+		ObjPrefix: fileNamePrefix(c.changefeedID),
+	}, func(path string, size int64) error {
+		path = strings.TrimPrefix(path, "/")
+		writeTime, isClaimCheck := parseWriteTime(c.changefeedID, path)
+		if !isClaimCheck {
+			return nil
+		}
+		files = append(files, File{
+			Name:      path,
+			Size:      size,
+			WriteTime: writeTime,
+			Expired:   ok && writeTime.Before(deadline),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.ErrExternalStorageAPI.Wrap(err).GenWithStackByArgs("ListClaimCheckFiles")
+	}
+	return files, nil
+}
+
+// RemoveExpiredFiles removes the expired claim-check files of the changefeed,
+// and returns the number of the removed files.
+func (c *Cleaner) RemoveExpiredFiles(ctx context.Context, checkpointTs model.Ts) (int, error) {
+	files, err := c.ListFiles(ctx, checkpointTs)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	var toRemove []string
+	for _, file := range files {
+		if file.Expired {
+			toRemove = append(toRemove, file.Name)
+		}
+	}
+	return len(toRemove), util.DeleteFilesInExtStorage(ctx, c.storage, toRemove)
+}
+
+// RemoveAllFiles removes all claim-check files of the changefeed, it's called
+// when the changefeed is removed.
+func (c *Cleaner) RemoveAllFiles(ctx context.Context) (int, error) {
+	files, err := c.ListFiles(ctx, 0)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	toRemove := make([]string, 0, len(files))
+	for _, file := range files {
+		toRemove = append(toRemove, file.Name)
+	}
+	return len(toRemove), util.DeleteFilesInExtStorage(ctx, c.storage, toRemove)
+}
+
+// Close closes the external storage.
+func (c *Cleaner) Close() {
+	c.storage.Close()
+}
+
+// deadline returns the time before which the files are expired, it returns
+// false if no file is expired.
+func (c *Cleaner) deadline(checkpointTs model.Ts) (time.Time, bool) {
+	if c.retention <= 0 || checkpointTs == 0 {
+		return time.Time{}, false
+	}
+	deadline := c.now()
+	if checkpoint := oracle.GetTimeFromTS(checkpointTs); checkpoint.Before(deadline) {
+		deadline = checkpoint
+	}
+	return deadline.Add(-c.retention), true
+}
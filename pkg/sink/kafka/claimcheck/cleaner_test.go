@@ -0,0 +1,104 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package claimcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/pingcap/tiflow/pkg/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tikv/client-go/v2/oracle"
+)
+
+func TestParseWriteTime(t *testing.T) {
+	t.Parallel()
+
+	changefeedID := model.DefaultChangeFeedID("test")
+	now := time.UnixMilli(time.Now().UnixMilli())
+	name := newFileName(changefeedID, now)
+	writeTime, ok := parseWriteTime(changefeedID, name)
+	require.True(t, ok)
+	require.Equal(t, now, writeTime)
+
+	// the files written by other changefeeds or the old versions are skipped.
+	_, ok = parseWriteTime(model.DefaultChangeFeedID("test2"), name)
+	require.False(t, ok)
+	_, ok = parseWriteTime(changefeedID, "1f5ec8a4-4b19-4e58-9d3e-7c8d2b2ab3a3.json")
+	require.False(t, ok)
+	_, ok = parseWriteTime(changefeedID, "default_test_abc_1f5ec8a4.json")
+	require.False(t, ok)
+}
+
+func TestCleaner(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	changefeedID := model.DefaultChangeFeedID("test")
+	cfg := config.NewDefaultLargeMessageHandleConfig()
+	cleaner, err := NewCleaner(ctx, cfg, changefeedID)
+	require.NoError(t, err)
+	require.Nil(t, cleaner)
+
+	cfg.LargeMessageHandleOption = config.LargeMessageHandleOptionClaimCheck
+	cfg.ClaimCheckStorageURI = "file://" + t.TempDir()
+	cfg.ClaimCheckRetention = "1h"
+	cleaner, err = NewCleaner(ctx, cfg, changefeedID)
+	require.NoError(t, err)
+	defer cleaner.Close()
+	require.Equal(t, time.Hour, cleaner.Retention())
+	cleaner.SetRetention(time.Minute)
+	require.Equal(t, time.Hour, cleaner.Retention())
+
+	now := time.Now()
+	cleaner.now = func() time.Time { return now }
+	expired := newFileName(changefeedID, now.Add(-4*time.Hour))
+	behindCheckpoint := newFileName(changefeedID, now.Add(-90*time.Minute))
+	recent := newFileName(changefeedID, now.Add(-time.Minute))
+	other := newFileName(model.DefaultChangeFeedID("other"), now.Add(-4*time.Hour))
+	for _, name := range []string{expired, behindCheckpoint, recent, other} {
+		require.NoError(t, cleaner.storage.WriteFile(ctx, name, []byte("value")))
+	}
+
+	// the checkpoint is 2 hours ago, so only the file written before 3 hours ago is expired.
+	checkpointTs := oracle.GoTimeToTS(now.Add(-2 * time.Hour))
+	files, err := cleaner.ListFiles(ctx, checkpointTs)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+	for _, file := range files {
+		require.Equal(t, file.Name == expired, file.Expired, file.Name)
+		require.Equal(t, int64(5), file.Size)
+	}
+
+	// no file is expired before the checkpoint is known.
+	count, err := cleaner.RemoveExpiredFiles(ctx, 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, count)
+
+	count, err = cleaner.RemoveExpiredFiles(ctx, checkpointTs)
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+	exists, err := cleaner.storage.FileExists(ctx, expired)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	count, err = cleaner.RemoveAllFiles(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	exists, err = cleaner.storage.FileExists(ctx, other)
+	require.NoError(t, err)
+	require.True(t, exists)
+}
@@ -22,6 +22,19 @@ type TopicDetail struct {
 	Name              string
 	NumPartitions     int32
 	ReplicationFactor int16
+	// ConfigEntries is the topic level configuration used to create the topic.
+	ConfigEntries map[string]string
+}
+
+// CompactedTopicConfig returns the configuration to create a topic with one
+// partition, in which only the latest message of each key is kept by compaction.
+func CompactedTopicConfig(topic string, replicationFactor int16) *TopicDetail {
+	return &TopicDetail{
+		Name:              topic,
+		NumPartitions:     1,
+		ReplicationFactor: replicationFactor,
+		ConfigEntries:     map[string]string{"cleanup.policy": "compact"},
+	}
 }
 
 // Broker represents a Kafka broker.
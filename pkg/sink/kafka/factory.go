@@ -37,6 +37,9 @@ type Factory interface {
 	AsyncProducer(ctx context.Context, failpointCh chan error) (AsyncProducer, error)
 	// MetricsCollector returns the kafka metrics collector
 	MetricsCollector(role util.Role, adminClient ClusterAdminClient) MetricsCollector
+	// TxnMarkerReader creates a reader to read the committed transaction markers,
+	// only works when the exactly-once is enabled.
+	TxnMarkerReader(ctx context.Context) (TxnMarkerReader, error)
 }
 
 // FactoryCreator defines the type of factory creator.
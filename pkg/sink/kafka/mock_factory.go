@@ -70,6 +70,9 @@ func (f *MockFactory) AsyncProducer(
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	if f.o.ExactlyOnce {
+		applyTransactionalConfig(config, f.o)
+	}
 	t := ctx.Value("testing.T").(*testing.T)
 	asyncProducer := mocks.NewAsyncProducer(t, config)
 	return &MockSaramaAsyncProducer{
@@ -85,6 +88,36 @@ func (f *MockFactory) MetricsCollector(
 	return &mockMetricsCollector{}
 }
 
+// TxnMarkerReader returns a mocked transaction marker reader without any marker.
+func (f *MockFactory) TxnMarkerReader(_ context.Context) (TxnMarkerReader, error) {
+	return NewMockTxnMarkerReader(nil), nil
+}
+
+// MockTxnMarkerReader is a mock implementation of TxnMarkerReader interface.
+type MockTxnMarkerReader struct {
+	Markers map[int64]TxnMarker
+	// Reads is the number of calls to ReadMarkers.
+	Reads int
+}
+
+// NewMockTxnMarkerReader creates a MockTxnMarkerReader which returns the markers.
+func NewMockTxnMarkerReader(markers map[int64]TxnMarker) *MockTxnMarkerReader {
+	return &MockTxnMarkerReader{Markers: markers}
+}
+
+// ReadMarkers implement the TxnMarkerReader interface.
+func (r *MockTxnMarkerReader) ReadMarkers(_ context.Context) (map[int64]TxnMarker, error) {
+	r.Reads++
+	markers := make(map[int64]TxnMarker, len(r.Markers))
+	for tableID, marker := range r.Markers {
+		markers[tableID] = marker
+	}
+	return markers, nil
+}
+
+// Close implement the TxnMarkerReader interface.
+func (r *MockTxnMarkerReader) Close() {}
+
 // MockSaramaSyncProducer is a mock implementation of SyncProducer interface.
 type MockSaramaSyncProducer struct {
 	Producer *mocks.SyncProducer
@@ -180,6 +213,21 @@ func (p *MockSaramaAsyncProducer) AsyncSend(ctx context.Context, topic string, p
 	return nil
 }
 
+// BeginTxn implement the TransactionalProducer interface.
+func (p *MockSaramaAsyncProducer) BeginTxn() error {
+	return p.AsyncProducer.BeginTxn()
+}
+
+// CommitTxn implement the TransactionalProducer interface.
+func (p *MockSaramaAsyncProducer) CommitTxn() error {
+	return p.AsyncProducer.CommitTxn()
+}
+
+// AbortTxn implement the TransactionalProducer interface.
+func (p *MockSaramaAsyncProducer) AbortTxn() error {
+	return p.AsyncProducer.AbortTxn()
+}
+
 // Close implement the AsyncProducer interface.
 func (p *MockSaramaAsyncProducer) Close() {
 	if p.closed {
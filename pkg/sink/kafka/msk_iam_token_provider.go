@@ -0,0 +1,149 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tiflow/pkg/security"
+)
+
+const (
+	// mskIAMSigningName is the service name to sign the request of the MSK IAM authentication.
+	mskIAMSigningName = "kafka-cluster"
+	// mskIAMAction is the action to connect to the MSK cluster.
+	mskIAMAction = "kafka-cluster:Connect"
+	// mskIAMTokenExpiry is the lifetime of the signed request.
+	mskIAMTokenExpiry = 15 * time.Minute
+	// mskIAMUserAgent is added to the signed request to identify the client.
+	mskIAMUserAgent = "tiflow"
+	// emptyPayloadHash is the SHA-256 hash of the empty payload.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	// mskIAMRequestTimeout is the timeout to retrieve the credentials.
+	mskIAMRequestTimeout = 10 * time.Second
+)
+
+// MSKIAMTokenProvider generates the tokens for the AWS MSK IAM authentication,
+// the token is a base64 encoded presigned request of the `kafka-cluster:Connect`
+// action, which is sent by the SASL/OAUTHBEARER mechanism.
+type MSKIAMTokenProvider struct {
+	region      string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	now         func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiredAt time.Time
+}
+
+var _ sarama.AccessTokenProvider = (*MSKIAMTokenProvider)(nil)
+
+// NewMSKIAMTokenProvider creates a MSKIAMTokenProvider, the credentials and the region
+// are loaded by the default AWS config chain, the region and the profile can be overridden.
+func NewMSKIAMTokenProvider(ctx context.Context, o *security.AWSMSKIAM) (*MSKIAMTokenProvider, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if o.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(o.Region))
+	}
+	if o.Profile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(o.Profile))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("the region of the MSK cluster is unknown, " +
+			"please set sasl-aws-region or the AWS_REGION environment variable")
+	}
+	return newMSKIAMTokenProvider(cfg.Region, cfg.Credentials), nil
+}
+
+func newMSKIAMTokenProvider(region string, credentials aws.CredentialsProvider) *MSKIAMTokenProvider {
+	return &MSKIAMTokenProvider{
+		region:      region,
+		credentials: credentials,
+		signer:      v4.NewSigner(),
+		now:         time.Now,
+	}
+}
+
+// Token implements the sarama.AccessTokenProvider interface.
+func (p *MSKIAMTokenProvider) Token() (*sarama.AccessToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mskIAMRequestTimeout)
+	defer cancel()
+	token, err := p.GenerateToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sarama.AccessToken{Token: token}, nil
+}
+
+// GenerateToken returns the token, it's reused until 80% of its lifetime passed.
+func (p *MSKIAMTokenProvider) GenerateToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if p.token != "" && now.Before(p.expiredAt) {
+		return p.token, nil
+	}
+	token, err := p.sign(ctx, now)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	p.token = token
+	p.expiredAt = now.Add(mskIAMTokenExpiry * 4 / 5)
+	return token, nil
+}
+
+func (p *MSKIAMTokenProvider) sign(ctx context.Context, signTime time.Time) (string, error) {
+	credentials, err := p.credentials.Retrieve(ctx)
+	if err != nil {
+		return "", errors.Annotate(err, "retrieve the AWS credentials failed")
+	}
+	query := url.Values{}
+	query.Set("Action", mskIAMAction)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(mskIAMTokenExpiry.Seconds())))
+	endpoint := fmt.Sprintf("https://kafka.%s.amazonaws.com/?%s", p.region, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	signedURL, _, err := p.signer.PresignHTTP(
+		ctx, credentials, req, emptyPayloadHash, mskIAMSigningName, p.region, signTime)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	u, err := url.Parse(signedURL)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	signedQuery := u.Query()
+	signedQuery.Set("User-Agent", mskIAMUserAgent)
+	u.RawQuery = signedQuery.Encode()
+	return base64.RawURLEncoding.EncodeToString([]byte(u.String())), nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/pingcap/tiflow/pkg/security"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMSKIAMTokenProvider(t *testing.T) {
+	t.Parallel()
+
+	p := newMSKIAMTokenProvider("us-west-2",
+		credentials.NewStaticCredentialsProvider("AKIDEXAMPLE", "secret", "session-token"))
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+
+	token, err := p.Token()
+	require.NoError(t, err)
+	decoded, err := base64.RawURLEncoding.DecodeString(token.Token)
+	require.NoError(t, err)
+	u, err := url.Parse(string(decoded))
+	require.NoError(t, err)
+	require.Equal(t, "https", u.Scheme)
+	require.Equal(t, "kafka.us-west-2.amazonaws.com", u.Host)
+
+	query := u.Query()
+	require.Equal(t, "kafka-cluster:Connect", query.Get("Action"))
+	require.Equal(t, "AWS4-HMAC-SHA256", query.Get("X-Amz-Algorithm"))
+	require.Equal(t, "AKIDEXAMPLE/20240101/us-west-2/kafka-cluster/aws4_request",
+		query.Get("X-Amz-Credential"))
+	require.Equal(t, "20240101T000000Z", query.Get("X-Amz-Date"))
+	require.Equal(t, "900", query.Get("X-Amz-Expires"))
+	require.Equal(t, "session-token", query.Get("X-Amz-Security-Token"))
+	require.Equal(t, "host", query.Get("X-Amz-SignedHeaders"))
+	require.Len(t, query.Get("X-Amz-Signature"), 64)
+	require.Equal(t, mskIAMUserAgent, query.Get("User-Agent"))
+
+	// The token is reused before 80% of its lifetime passed.
+	now = now.Add(11 * time.Minute)
+	cached, err := p.GenerateToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, token.Token, cached)
+
+	now = now.Add(2 * time.Minute)
+	renewed, err := p.GenerateToken(context.Background())
+	require.NoError(t, err)
+	require.NotEqual(t, token.Token, renewed)
+}
+
+func TestNewMSKIAMTokenProvider(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_CONFIG_FILE", "/nonexistent")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/nonexistent")
+
+	_, err := NewMSKIAMTokenProvider(context.Background(), &security.AWSMSKIAM{})
+	require.ErrorContains(t, err, "region")
+
+	p, err := NewMSKIAMTokenProvider(context.Background(), &security.AWSMSKIAM{Region: "eu-west-1"})
+	require.NoError(t, err)
+	token, err := p.GenerateToken(context.Background())
+	require.NoError(t, err)
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	require.NoError(t, err)
+	require.Contains(t, string(decoded), "kafka.eu-west-1.amazonaws.com")
+	require.Contains(t, string(decoded), "AKIDEXAMPLE%2F")
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
@@ -32,6 +33,8 @@ import (
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
 	"github.com/pingcap/tiflow/pkg/security"
+	psink "github.com/pingcap/tiflow/pkg/sink"
+	"github.com/pingcap/tiflow/pkg/util"
 	"go.uber.org/zap"
 )
 
@@ -75,8 +78,16 @@ const (
 	SASLTypeGSSAPI = "GSSAPI"
 	// SASLTypeOAuth represents the SASL/OAUTHBEARER mechanism (Kafka 2.0.0+)
 	SASLTypeOAuth = "OAUTHBEARER"
+	// SASLTypeAWSMSKIAM represents the AWS MSK IAM authentication.
+	SASLTypeAWSMSKIAM = "AWS_MSK_IAM"
+	// SASLTypeEventHubs represents the Azure Event Hubs connection string authentication.
+	SASLTypeEventHubs = "EVENT_HUBS"
 )
 
+// EventHubsConnectionStringEnv is the environment variable to read the connection
+// string of the Azure Event Hubs, if it's not set in the sink URI or the config file.
+const EventHubsConnectionStringEnv = "AZURE_EVENT_HUBS_CONNECTION_STRING"
+
 // RequiredAcks is used in Produce Requests to tell the broker how many replica acknowledgements
 // it must see before responding. Any of the constants defined here are valid. On broker versions
 // prior to 0.8.2.0 any other positive int16 is also valid (the broker will wait for that many
@@ -133,11 +144,15 @@ type urlConfig struct {
 	SASLGssAPIPassword           *string `form:"sasl-gssapi-password"`
 	SASLGssAPIRealm              *string `form:"sasl-gssapi-realm"`
 	SASLGssAPIDisablePafxfast    *bool   `form:"sasl-gssapi-disable-pafxfast"`
+	SASLAWSRegion                *string `form:"sasl-aws-region"`
+	SASLAWSProfile               *string `form:"sasl-aws-profile"`
 	EnableTLS                    *bool   `form:"enable-tls"`
 	CA                           *string `form:"ca"`
 	Cert                         *string `form:"cert"`
 	Key                          *string `form:"key"`
 	InsecureSkipVerify           *bool   `form:"insecure-skip-verify"`
+	ExactlyOnce                  *bool   `form:"exactly-once"`
+	TransactionMarkerTopic       *string `form:"transaction-marker-topic"`
 }
 
 // Options stores user specified configurations
@@ -170,6 +185,13 @@ type Options struct {
 	DialTimeout  time.Duration
 	WriteTimeout time.Duration
 	ReadTimeout  time.Duration
+
+	// ExactlyOnce indicates the DML producer sends messages in kafka transactions,
+	// TransactionalID identifies the producer, and the progress of the tables is
+	// committed to the TransactionMarkerTopic along with the messages.
+	ExactlyOnce            bool
+	TransactionalID        string
+	TransactionMarkerTopic string
 }
 
 // NewOptions returns a default Kafka configuration
@@ -318,7 +340,12 @@ func (o *Options) Apply(changefeedID model.ChangeFeedID,
 		return err
 	}
 
-	return nil
+	err = o.applyCloudSASL(urlParameter, replicaConfig)
+	if err != nil {
+		return err
+	}
+
+	return o.applyExactlyOnce(changefeedID, urlParameter, replicaConfig)
 }
 
 func mergeConfig(
@@ -350,11 +377,15 @@ func mergeConfig(
 		dest.SASLGssAPIRealm = fileConifg.SASLGssAPIRealm
 		dest.SASLGssAPIUser = fileConifg.SASLGssAPIUser
 		dest.SASLGssAPIPassword = fileConifg.SASLGssAPIPassword
+		dest.SASLAWSRegion = fileConifg.SASLAWSRegion
+		dest.SASLAWSProfile = fileConifg.SASLAWSProfile
 		dest.EnableTLS = fileConifg.EnableTLS
 		dest.CA = fileConifg.CA
 		dest.Cert = fileConifg.Cert
 		dest.Key = fileConifg.Key
 		dest.InsecureSkipVerify = fileConifg.InsecureSkipVerify
+		dest.ExactlyOnce = fileConifg.ExactlyOnce
+		dest.TransactionMarkerTopic = fileConifg.TransactionMarkerTopic
 	}
 	if err := mergo.Merge(dest, urlParameters, mergo.WithOverride); err != nil {
 		return nil, err
@@ -409,6 +440,52 @@ func (o *Options) applyTLS(params *urlConfig) error {
 	return nil
 }
 
+func (o *Options) applyExactlyOnce(
+	changefeedID model.ChangeFeedID,
+	params *urlConfig, replicaConfig *config.ReplicaConfig,
+) error {
+	if params.ExactlyOnce == nil || !*params.ExactlyOnce {
+		return nil
+	}
+	// the transactional producer is built on the idempotent producer,
+	// which requires all in-sync replicas to acknowledge the messages.
+	if o.RequiredAcks != WaitForAll {
+		return cerror.ErrKafkaInvalidConfig.GenWithStack(
+			"exactly-once requires the required-acks to be %d, but got %d", WaitForAll, o.RequiredAcks)
+	}
+	if replicaConfig.Sink != nil && util.GetOrZero(replicaConfig.Sink.EnableKafkaSinkV2) {
+		return cerror.ErrKafkaInvalidConfig.GenWithStack(
+			"exactly-once is not supported by the kafka sink v2")
+	}
+	// the progress is tracked by table, a table must be replicated by only one capture.
+	if replicaConfig.Scheduler != nil && replicaConfig.Scheduler.EnableTableAcrossNodes {
+		return cerror.ErrKafkaInvalidConfig.GenWithStack(
+			"exactly-once is not supported when enable-table-across-nodes is enabled")
+	}
+	// the mapping change markers of the adaptive dispatcher are not sent in transactions.
+	if replicaConfig.Sink != nil {
+		for _, rule := range replicaConfig.Sink.DispatchRules {
+			if strings.EqualFold(rule.PartitionRule, "adaptive") ||
+				strings.EqualFold(rule.DispatcherRule, "adaptive") {
+				return cerror.ErrKafkaInvalidConfig.GenWithStack(
+					"exactly-once is not supported by the adaptive partition dispatcher")
+			}
+		}
+	}
+
+	transactionalID, err := NewKafkaTransactionalID(
+		config.GetGlobalServerConfig().AdvertiseAddr, changefeedID)
+	if err != nil {
+		return err
+	}
+	o.ExactlyOnce = true
+	o.TransactionalID = transactionalID
+	if params.TransactionMarkerTopic != nil {
+		o.TransactionMarkerTopic = *params.TransactionMarkerTopic
+	}
+	return nil
+}
+
 func (o *Options) applySASL(urlParameter *urlConfig, replicaConfig *config.ReplicaConfig) error {
 	if urlParameter.SASLUser != nil && *urlParameter.SASLUser != "" {
 		o.SASL.SASLUser = *urlParameter.SASLUser
@@ -527,6 +604,70 @@ func (o *Options) applySASL(urlParameter *urlConfig, replicaConfig *config.Repli
 	return nil
 }
 
+// applyCloudSASL completes the SASL options of the managed Kafka services,
+// which require the TLS to be enabled.
+func (o *Options) applyCloudSASL(
+	urlParameter *urlConfig, replicaConfig *config.ReplicaConfig,
+) error {
+	switch o.SASL.SASLMechanism {
+	case security.AWSMSKIAMMechanism:
+		if urlParameter.SASLAWSRegion != nil {
+			o.SASL.AWSMSKIAM.Region = *urlParameter.SASLAWSRegion
+		}
+		if urlParameter.SASLAWSProfile != nil {
+			o.SASL.AWSMSKIAM.Profile = *urlParameter.SASLAWSProfile
+		}
+	case security.EventHubsMechanism:
+		connectionString, err := resolveEventHubsConnectionString(o.SASL.SASLPassword, replicaConfig)
+		if err != nil {
+			return err
+		}
+		o.SASL.SASLUser = security.EventHubsConnectionStringUser
+		o.SASL.SASLPassword = connectionString
+	default:
+		return nil
+	}
+
+	if urlParameter.EnableTLS != nil && !*urlParameter.EnableTLS {
+		return cerror.ErrKafkaInvalidConfig.GenWithStack(
+			"SASL mechanism %s requires TLS, but 'enable-tls' is set to false",
+			o.SASL.SASLMechanism)
+	}
+	o.EnableTLS = true
+	return nil
+}
+
+// resolveEventHubsConnectionString returns the connection string of the Azure Event Hubs,
+// it's read from the sasl-password, the connection string file in the config file, and the
+// environment variable in order.
+func resolveEventHubsConnectionString(
+	password string, replicaConfig *config.ReplicaConfig,
+) (string, error) {
+	connectionString := password
+	if connectionString == "" && replicaConfig.Sink != nil && replicaConfig.Sink.KafkaConfig != nil &&
+		replicaConfig.Sink.KafkaConfig.SASLEventHubsConnectionStringFile != nil {
+		path := *replicaConfig.Sink.KafkaConfig.SASLEventHubsConnectionStringFile
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", cerror.WrapError(cerror.ErrKafkaInvalidConfig, err)
+		}
+		connectionString = strings.TrimSpace(string(content))
+	}
+	if connectionString == "" {
+		connectionString = os.Getenv(EventHubsConnectionStringEnv)
+	}
+	if connectionString == "" {
+		return "", cerror.ErrKafkaInvalidConfig.GenWithStack(
+			"the connection string of the Event Hubs is not found, please set sasl-password, "+
+				"sasl-event-hubs-connection-string-file or the %s environment variable",
+			EventHubsConnectionStringEnv)
+	}
+	if err := security.ValidateEventHubsConnectionString(connectionString); err != nil {
+		return "", cerror.WrapError(cerror.ErrKafkaInvalidConfig, err)
+	}
+	return connectionString, nil
+}
+
 // AutoCreateTopicConfig is used to create topic configuration.
 type AutoCreateTopicConfig struct {
 	AutoCreate        bool
@@ -566,6 +707,41 @@ func NewKafkaClientID(captureAddr string,
 	return
 }
 
+// IsExactlyOnceEnabled returns whether the exactly-once is enabled for the
+// kafka sink by the sink URI or the changefeed config.
+func IsExactlyOnceEnabled(sinkURI *url.URL, replicaConfig *config.ReplicaConfig) bool {
+	scheme := psink.GetScheme(sinkURI)
+	if scheme != psink.KafkaScheme && scheme != psink.KafkaSSLScheme {
+		return false
+	}
+	urlParameter := &urlConfig{}
+	if err := binding.Query.Bind(&http.Request{URL: sinkURI}, urlParameter); err != nil {
+		return false
+	}
+	urlParameter, err := mergeConfig(replicaConfig, urlParameter)
+	if err != nil {
+		return false
+	}
+	return util.GetOrZero(urlParameter.ExactlyOnce)
+}
+
+// NewKafkaTransactionalID generates the transactional id of the kafka producer,
+// it's stable for the changefeed on the capture, so that the producer of the
+// previous run is fenced out after the sink restarts.
+// It can't fence the producer of another capture when a table is moved, the
+// table is fenced by the mq dml sink worker instead, which never commits the
+// rows of a stopped table sink.
+func NewKafkaTransactionalID(captureAddr string, changefeedID model.ChangeFeedID) (string, error) {
+	transactionalID := fmt.Sprintf("TiCDC_txn_%s_%s_%s",
+		captureAddr, changefeedID.Namespace, changefeedID.ID)
+	transactionalID = commonInvalidChar.ReplaceAllString(transactionalID, "_")
+	if !validClientID.MatchString(transactionalID) {
+		return "", cerror.ErrKafkaInvalidConfig.GenWithStack(
+			"invalid kafka transactional id %s", transactionalID)
+	}
+	return transactionalID, nil
+}
+
 // AdjustOptions adjust the `Options` and `sarama.Config` by condition.
 func AdjustOptions(
 	ctx context.Context,
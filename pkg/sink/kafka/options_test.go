@@ -17,6 +17,8 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -28,6 +30,7 @@ import (
 	"github.com/pingcap/tiflow/cdc/model"
 	"github.com/pingcap/tiflow/pkg/config"
 	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/security"
 	"github.com/pingcap/tiflow/pkg/sink/codec/common"
 	"github.com/stretchr/testify/require"
 )
@@ -191,6 +194,124 @@ func TestTimeout(t *testing.T) {
 	require.Equal(t, 2*time.Minute, options.WriteTimeout)
 }
 
+func TestExactlyOnce(t *testing.T) {
+	options := NewOptions()
+	sinkURI, err := url.Parse("kafka://127.0.0.1:9092/kafka-test")
+	require.NoError(t, err)
+	err = options.Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.NoError(t, err)
+	require.False(t, options.ExactlyOnce)
+	require.Empty(t, options.TransactionalID)
+
+	options = NewOptions()
+	sinkURI, err = url.Parse("kafka://127.0.0.1:9092/kafka-test?exactly-once=true" +
+		"&transaction-marker-topic=marker")
+	require.NoError(t, err)
+	err = options.Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.NoError(t, err)
+	require.True(t, options.ExactlyOnce)
+	require.Equal(t, "marker", options.TransactionMarkerTopic)
+	require.True(t, strings.HasPrefix(options.TransactionalID, "TiCDC_txn_"))
+	require.True(t, strings.HasSuffix(options.TransactionalID, "_default_test"))
+
+	// the configuration file works too.
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.KafkaConfig = &config.KafkaConfig{ExactlyOnce: aws.Bool(true)}
+	sinkURI, err = url.Parse("kafka://127.0.0.1:9092/kafka-test")
+	require.NoError(t, err)
+	options = NewOptions()
+	require.NoError(t, options.Apply(model.DefaultChangeFeedID("test"), sinkURI, replicaConfig))
+	require.True(t, options.ExactlyOnce)
+	require.Empty(t, options.TransactionMarkerTopic)
+
+	sinkURI, err = url.Parse("kafka://127.0.0.1:9092/kafka-test?exactly-once=true&required-acks=1")
+	require.NoError(t, err)
+	err = NewOptions().Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.ErrorContains(t, err, "required-acks")
+
+	sinkURI, err = url.Parse("kafka://127.0.0.1:9092/kafka-test?exactly-once=true")
+	require.NoError(t, err)
+	replicaConfig = config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.EnableKafkaSinkV2 = aws.Bool(true)
+	err = NewOptions().Apply(model.DefaultChangeFeedID("test"), sinkURI, replicaConfig)
+	require.ErrorContains(t, err, "kafka sink v2")
+
+	replicaConfig = config.GetDefaultReplicaConfig()
+	replicaConfig.Scheduler.EnableTableAcrossNodes = true
+	err = NewOptions().Apply(model.DefaultChangeFeedID("test"), sinkURI, replicaConfig)
+	require.ErrorContains(t, err, "enable-table-across-nodes")
+
+	replicaConfig = config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.DispatchRules = []*config.DispatchRule{
+		{Matcher: []string{"test.*"}, PartitionRule: "adaptive"},
+	}
+	err = NewOptions().Apply(model.DefaultChangeFeedID("test"), sinkURI, replicaConfig)
+	require.ErrorContains(t, err, "adaptive")
+}
+
+func TestCloudSASL(t *testing.T) {
+	connectionString := "Endpoint=sb://test.servicebus.windows.net/;" +
+		"SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=a2V5"
+
+	// AWS MSK IAM requires TLS.
+	options := NewOptions()
+	sinkURI, err := url.Parse("kafka://127.0.0.1:9098/kafka-test?sasl-mechanism=aws-msk-iam" +
+		"&sasl-aws-region=us-east-1&sasl-aws-profile=msk")
+	require.NoError(t, err)
+	err = options.Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.NoError(t, err)
+	require.Equal(t, security.AWSMSKIAMMechanism, options.SASL.SASLMechanism)
+	require.Equal(t, "us-east-1", options.SASL.AWSMSKIAM.Region)
+	require.Equal(t, "msk", options.SASL.AWSMSKIAM.Profile)
+	require.True(t, options.EnableTLS)
+
+	sinkURI, err = url.Parse("kafka://127.0.0.1:9098/kafka-test?sasl-mechanism=aws-msk-iam&enable-tls=false")
+	require.NoError(t, err)
+	err = NewOptions().Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.ErrorContains(t, err, "requires TLS")
+
+	// The connection string of the Event Hubs is read from the sasl-password.
+	options = NewOptions()
+	sinkURI, err = url.Parse("kafka://test.servicebus.windows.net:9093/kafka-test?sasl-mechanism=event-hubs" +
+		"&sasl-password=" + url.QueryEscape(connectionString))
+	require.NoError(t, err)
+	err = options.Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.NoError(t, err)
+	require.Equal(t, security.EventHubsConnectionStringUser, options.SASL.SASLUser)
+	require.Equal(t, connectionString, options.SASL.SASLPassword)
+	require.True(t, options.EnableTLS)
+
+	// The connection string file in the configuration file.
+	path := filepath.Join(t.TempDir(), "connection-string")
+	require.NoError(t, os.WriteFile(path, []byte(connectionString+"\n"), 0o600))
+	replicaConfig := config.GetDefaultReplicaConfig()
+	replicaConfig.Sink.KafkaConfig = &config.KafkaConfig{
+		SASLMechanism:                     aws.String("event-hubs"),
+		SASLEventHubsConnectionStringFile: aws.String(path),
+	}
+	sinkURI, err = url.Parse("kafka://test.servicebus.windows.net:9093/kafka-test")
+	require.NoError(t, err)
+	options = NewOptions()
+	require.NoError(t, options.Apply(model.DefaultChangeFeedID("test"), sinkURI, replicaConfig))
+	require.Equal(t, connectionString, options.SASL.SASLPassword)
+
+	// The environment variable.
+	sinkURI, err = url.Parse("kafka://test.servicebus.windows.net:9093/kafka-test?sasl-mechanism=event-hubs")
+	require.NoError(t, err)
+	t.Setenv(EventHubsConnectionStringEnv, "")
+	err = NewOptions().Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.ErrorContains(t, err, "not found")
+
+	t.Setenv(EventHubsConnectionStringEnv, "Endpoint=sb://test.servicebus.windows.net/")
+	err = NewOptions().Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig())
+	require.ErrorContains(t, err, "SharedAccessKey")
+
+	t.Setenv(EventHubsConnectionStringEnv, connectionString)
+	options = NewOptions()
+	require.NoError(t, options.Apply(model.DefaultChangeFeedID("test"), sinkURI, config.GetDefaultReplicaConfig()))
+	require.Equal(t, connectionString, options.SASL.SASLPassword)
+}
+
 func TestAdjustConfigTopicNotExist(t *testing.T) {
 	// When the topic does not exist, use the broker's configuration to create the topic.
 	adminClient := NewClusterAdminClientMockImpl()
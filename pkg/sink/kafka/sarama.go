@@ -182,6 +182,17 @@ func completeSaramaSASLConfig(ctx context.Context, config *sarama.Config, o *Opt
 				return errors.Trace(err)
 			}
 			config.Net.SASL.TokenProvider = p
+		case SASLTypeAWSMSKIAM:
+			p, err := NewMSKIAMTokenProvider(ctx, &o.SASL.AWSMSKIAM)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			config.Net.SASL.Mechanism = sarama.SASLTypeOAuth
+			config.Net.SASL.TokenProvider = p
+		case SASLTypeEventHubs:
+			config.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+			config.Net.SASL.User = o.SASL.SASLUser
+			config.Net.SASL.Password = o.SASL.SASLPassword
 		}
 	}
 
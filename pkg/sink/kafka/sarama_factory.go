@@ -118,6 +118,9 @@ func (f *saramaFactory) AsyncProducer(
 		return nil, err
 	}
 	config.MetricRegistry = f.registry
+	if f.option.ExactlyOnce {
+		applyTransactionalConfig(config, f.option)
+	}
 
 	client, err := sarama.NewClient(f.option.BrokerEndpoints, config)
 	if err != nil {
@@ -135,6 +138,24 @@ func (f *saramaFactory) AsyncProducer(
 	}, nil
 }
 
+// TxnMarkerReader returns a reader of the transaction marker topic,
+// it should be the caller's responsibility to close the reader.
+func (f *saramaFactory) TxnMarkerReader(ctx context.Context) (TxnMarkerReader, error) {
+	config, err := NewSaramaConfig(ctx, f.option)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sarama.NewClient(f.option.BrokerEndpoints, config)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &saramaTxnMarkerReader{
+		changefeedID: f.changefeedID,
+		topic:        f.option.TransactionMarkerTopic,
+		client:       client,
+	}, nil
+}
+
 func (f *saramaFactory) MetricsCollector(
 	role util.Role,
 	adminClient ClusterAdminClient,
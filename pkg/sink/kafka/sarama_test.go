@@ -380,6 +380,27 @@ func TestCompleteSaramaSASLConfig(t *testing.T) {
 	options.SASL.SASLMechanism = "SCRAM-SHA-512"
 	completeSaramaSASLConfig(ctx, saramaConfig, options)
 	require.NotNil(t, saramaConfig.Net.SASL.SCRAMClientGeneratorFunc)
+
+	// The cloud mechanisms are mapped to the mechanisms supported by sarama.
+	options = NewOptions()
+	options.SASL = &security.SASL{
+		SASLUser:      security.EventHubsConnectionStringUser,
+		SASLPassword:  "Endpoint=sb://test.servicebus.windows.net/;SharedAccessSignature=sig",
+		SASLMechanism: security.EventHubsMechanism,
+	}
+	saramaConfig = sarama.NewConfig()
+	require.NoError(t, completeSaramaSASLConfig(ctx, saramaConfig, options))
+	require.Equal(t, sarama.SASLMechanism(sarama.SASLTypePlaintext), saramaConfig.Net.SASL.Mechanism)
+	require.Equal(t, security.EventHubsConnectionStringUser, saramaConfig.Net.SASL.User)
+
+	options.SASL = &security.SASL{
+		SASLMechanism: security.AWSMSKIAMMechanism,
+		AWSMSKIAM:     security.AWSMSKIAM{Region: "us-east-1"},
+	}
+	saramaConfig = sarama.NewConfig()
+	require.NoError(t, completeSaramaSASLConfig(ctx, saramaConfig, options))
+	require.Equal(t, sarama.SASLMechanism(sarama.SASLTypeOAuth), saramaConfig.Net.SASL.Mechanism)
+	require.IsType(t, &MSKIAMTokenProvider{}, saramaConfig.Net.SASL.TokenProvider)
 }
 
 func TestSaramaTimeout(t *testing.T) {
@@ -0,0 +1,283 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// txnMarkerPartition is the only partition of the transaction marker topic.
+	txnMarkerPartition = 0
+	// txnMarkerFetchMaxBytes is the max bytes of each fetch from the marker topic.
+	txnMarkerFetchMaxBytes = 16 * 1024 * 1024
+	// txnMarkerRetryInterval is the interval to wait for the open transactions.
+	txnMarkerRetryInterval = 100 * time.Millisecond
+	// controlRecordTypeAbort is the type of the abort control record, which is
+	// stored in the key of the record, after the version.
+	controlRecordTypeAbort = 0
+)
+
+// TransactionalProducer is the kafka async producer which sends messages in
+// transactions, the messages are visible to the `read_committed` consumers
+// only after the transaction is committed.
+type TransactionalProducer interface {
+	AsyncProducer
+
+	// BeginTxn begins a new transaction.
+	BeginTxn() error
+	// CommitTxn flushes all messages sent in the transaction and commits it.
+	CommitTxn() error
+	// AbortTxn aborts the transaction.
+	AbortTxn() error
+}
+
+// TxnMarker is the progress of a table, which is committed along with
+// the messages in the same kafka transaction.
+type TxnMarker struct {
+	// CommitTs is the commit ts of the last committed row of the table, all
+	// the rows of the table with the same commit ts are committed together.
+	CommitTs uint64 `json:"commit-ts"`
+}
+
+// TxnMarkerReader reads the committed transaction markers.
+type TxnMarkerReader interface {
+	// ReadMarkers returns the last committed marker of each table of the changefeed.
+	ReadMarkers(ctx context.Context) (map[int64]TxnMarker, error)
+	// Close closes the reader.
+	Close()
+}
+
+// DefaultTxnMarkerTopic returns the default transaction marker topic of the topic.
+func DefaultTxnMarkerTopic(topic string) string {
+	return topic + "_ticdc_txn_marker"
+}
+
+// TxnMarkerTopicConfig returns the configuration to create the transaction
+// marker topic, only the latest marker of each table is kept by compaction.
+func TxnMarkerTopicConfig(topic string, replicationFactor int16) *TopicDetail {
+	return CompactedTopicConfig(topic, replicationFactor)
+}
+
+// NewTxnMarkerMessage returns the message of the table's transaction marker,
+// which should be sent to the only partition of the transaction marker topic.
+func NewTxnMarkerMessage(
+	changefeedID model.ChangeFeedID, tableID int64, marker TxnMarker,
+) (int32, []byte, []byte, error) {
+	value, err := json.Marshal(marker)
+	if err != nil {
+		return 0, nil, nil, cerror.WrapError(cerror.ErrMarshalFailed, err)
+	}
+	key := fmt.Sprintf("%s%d", txnMarkerKeyPrefix(changefeedID), tableID)
+	return txnMarkerPartition, []byte(key), value, nil
+}
+
+func txnMarkerKeyPrefix(changefeedID model.ChangeFeedID) string {
+	return fmt.Sprintf("%s/%s/", changefeedID.Namespace, changefeedID.ID)
+}
+
+// applyTransactionalConfig makes the producer transactional,
+// see the requirements in sarama.Config.Validate.
+func applyTransactionalConfig(config *sarama.Config, o *Options) {
+	config.Producer.Idempotent = true
+	config.Producer.Transaction.ID = o.TransactionalID
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Net.MaxOpenRequests = 1
+}
+
+type saramaTxnMarkerReader struct {
+	changefeedID model.ChangeFeedID
+	topic        string
+	client       sarama.Client
+}
+
+// ReadMarkers fetches the marker topic in the `read_committed` isolation level, the
+// records of the aborted transactions are filtered out in the same way as the kafka
+// consumer. It reads until the high watermark at the beginning, so it waits for
+// the open transactions, such as the one of the capture which the table is moved
+// from, to be committed or aborted.
+func (r *saramaTxnMarkerReader) ReadMarkers(ctx context.Context) (map[int64]TxnMarker, error) {
+	offset, err := r.client.GetOffset(r.topic, txnMarkerPartition, sarama.OffsetOldest)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrKafkaTransaction, err)
+	}
+	highWatermark, err := r.client.GetOffset(r.topic, txnMarkerPartition, sarama.OffsetNewest)
+	if err != nil {
+		return nil, cerror.WrapError(cerror.ErrKafkaTransaction, err)
+	}
+
+	markers := make(map[int64]TxnMarker)
+	prefix := txnMarkerKeyPrefix(r.changefeedID)
+	for offset < highWatermark {
+		broker, err := r.client.Leader(r.topic, txnMarkerPartition)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrKafkaTransaction, err)
+		}
+		request := &sarama.FetchRequest{
+			Version:   4,
+			Isolation: sarama.ReadCommitted,
+			MaxBytes:  txnMarkerFetchMaxBytes,
+		}
+		request.AddBlock(r.topic, txnMarkerPartition, offset, txnMarkerFetchMaxBytes, -1)
+		response, err := broker.Fetch(request)
+		if err != nil {
+			return nil, cerror.WrapError(cerror.ErrKafkaTransaction, err)
+		}
+		block := response.GetBlock(r.topic, txnMarkerPartition)
+		if block == nil {
+			return nil, cerror.ErrKafkaTransaction.GenWithStack(
+				"no response of the transaction marker topic %s", r.topic)
+		}
+		if block.Err != sarama.ErrNoError {
+			return nil, cerror.WrapError(cerror.ErrKafkaTransaction, block.Err)
+		}
+		// the records after the last stable offset are not decided yet.
+		if offset >= block.LastStableOffset {
+			select {
+			case <-ctx.Done():
+				return nil, errors.Trace(ctx.Err())
+			case <-time.After(txnMarkerRetryInterval):
+			}
+			continue
+		}
+
+		next, err := applyTxnMarkerRecords(block, offset, prefix, markers)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if next <= offset {
+			return nil, cerror.ErrKafkaTransaction.GenWithStack(
+				"fetch the transaction marker topic %s makes no progress at offset %d",
+				r.topic, offset)
+		}
+		offset = next
+	}
+	log.Info("kafka transaction markers loaded",
+		zap.String("namespace", r.changefeedID.Namespace),
+		zap.String("changefeed", r.changefeedID.ID),
+		zap.String("topic", r.topic),
+		zap.Int64("offset", offset),
+		zap.Int("tables", len(markers)))
+	return markers, nil
+}
+
+// applyTxnMarkerRecords applies the committed markers of the changefeed in the
+// fetch response, and returns the offset of the next fetch.
+func applyTxnMarkerRecords(
+	block *sarama.FetchResponseBlock, offset int64,
+	prefix string, markers map[int64]TxnMarker,
+) (int64, error) {
+	abortedTxns := make([]*sarama.AbortedTransaction, len(block.AbortedTransactions))
+	copy(abortedTxns, block.AbortedTransactions)
+	sort.Slice(abortedTxns, func(i, j int) bool {
+		return abortedTxns[i].FirstOffset < abortedTxns[j].FirstOffset
+	})
+	abortedProducers := make(map[int64]struct{})
+
+	next := offset
+	for _, records := range block.RecordsSet {
+		batch := records.RecordBatch
+		// the transactional producer always sends record batches.
+		if batch == nil {
+			continue
+		}
+		if batch.PartialTrailingRecord {
+			break
+		}
+		for len(abortedTxns) != 0 && abortedTxns[0].FirstOffset <= batch.LastOffset() {
+			abortedProducers[abortedTxns[0].ProducerID] = struct{}{}
+			abortedTxns = abortedTxns[1:]
+		}
+		if batch.LastOffset() >= next {
+			next = batch.LastOffset() + 1
+		}
+
+		_, aborted := abortedProducers[batch.ProducerID]
+		if batch.Control {
+			// the abort control record ends the aborted transaction of the producer.
+			if aborted && len(batch.Records) != 0 && len(batch.Records[0].Key) >= 4 &&
+				binary.BigEndian.Uint16(batch.Records[0].Key[2:4]) == controlRecordTypeAbort {
+				delete(abortedProducers, batch.ProducerID)
+			}
+			continue
+		}
+		if batch.IsTransactional && aborted {
+			continue
+		}
+
+		for _, record := range batch.Records {
+			if batch.FirstOffset+record.OffsetDelta < offset {
+				continue
+			}
+			key := string(record.Key)
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			tableID, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64)
+			if err != nil {
+				log.Warn("ignore the invalid kafka transaction marker", zap.String("key", key))
+				continue
+			}
+			// the tombstone deletes the marker.
+			if len(record.Value) == 0 {
+				delete(markers, tableID)
+				continue
+			}
+			var marker TxnMarker
+			if err := json.Unmarshal(record.Value, &marker); err != nil {
+				return 0, cerror.WrapError(cerror.ErrUnmarshalFailed, err)
+			}
+			markers[tableID] = marker
+		}
+	}
+	return next, nil
+}
+
+func (r *saramaTxnMarkerReader) Close() {
+	if err := r.client.Close(); err != nil {
+		log.Warn("close kafka transaction marker reader client meet error",
+			zap.String("namespace", r.changefeedID.Namespace),
+			zap.String("changefeed", r.changefeedID.ID),
+			zap.Error(err))
+	}
+}
+
+// BeginTxn implements the TransactionalProducer interface.
+func (p *saramaAsyncProducer) BeginTxn() error {
+	return cerror.WrapError(cerror.ErrKafkaTransaction, p.producer.BeginTxn())
+}
+
+// CommitTxn implements the TransactionalProducer interface.
+func (p *saramaAsyncProducer) CommitTxn() error {
+	return cerror.WrapError(cerror.ErrKafkaTransaction, p.producer.CommitTxn())
+}
+
+// AbortTxn implements the TransactionalProducer interface.
+func (p *saramaAsyncProducer) AbortTxn() error {
+	return cerror.WrapError(cerror.ErrKafkaTransaction, p.producer.AbortTxn())
+}
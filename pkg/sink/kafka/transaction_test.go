@@ -0,0 +1,103 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/pingcap/tiflow/cdc/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newTxnMarkerBatch(
+	t *testing.T, firstOffset, producerID int64,
+	changefeedID model.ChangeFeedID, tableID int64, marker TxnMarker,
+) *sarama.Records {
+	_, key, value, err := NewTxnMarkerMessage(changefeedID, tableID, marker)
+	require.NoError(t, err)
+	return &sarama.Records{RecordBatch: &sarama.RecordBatch{
+		FirstOffset:     firstOffset,
+		ProducerID:      producerID,
+		IsTransactional: true,
+		Records:         []*sarama.Record{{Key: key, Value: value}},
+	}}
+}
+
+func newControlBatch(offset, producerID int64, abort bool) *sarama.Records {
+	recordType := byte(1)
+	if abort {
+		recordType = 0
+	}
+	return &sarama.Records{RecordBatch: &sarama.RecordBatch{
+		FirstOffset:     offset,
+		ProducerID:      producerID,
+		IsTransactional: true,
+		Control:         true,
+		Records:         []*sarama.Record{{Key: []byte{0, 0, 0, recordType}}},
+	}}
+}
+
+func TestApplyTxnMarkerRecords(t *testing.T) {
+	t.Parallel()
+
+	changefeedID := model.DefaultChangeFeedID("test")
+	other := model.DefaultChangeFeedID("other")
+	block := &sarama.FetchResponseBlock{
+		RecordsSet: []*sarama.Records{
+			// committed by producer 1.
+			newTxnMarkerBatch(t, 10, 1, changefeedID, 100, TxnMarker{CommitTs: 5}),
+			newTxnMarkerBatch(t, 11, 1, other, 100, TxnMarker{CommitTs: 50}),
+			newControlBatch(12, 1, false),
+			// aborted by producer 2.
+			newTxnMarkerBatch(t, 13, 2, changefeedID, 100, TxnMarker{CommitTs: 7}),
+			newControlBatch(14, 2, true),
+			// committed by producer 2.
+			newTxnMarkerBatch(t, 15, 2, changefeedID, 101, TxnMarker{CommitTs: 6}),
+			newControlBatch(16, 2, false),
+		},
+		AbortedTransactions: []*sarama.AbortedTransaction{{ProducerID: 2, FirstOffset: 13}},
+	}
+
+	markers := make(map[int64]TxnMarker)
+	next, err := applyTxnMarkerRecords(block, 10, txnMarkerKeyPrefix(changefeedID), markers)
+	require.NoError(t, err)
+	require.Equal(t, int64(17), next)
+	require.Equal(t, map[int64]TxnMarker{
+		100: {CommitTs: 5},
+		101: {CommitTs: 6},
+	}, markers)
+
+	// the records before the fetch offset are skipped.
+	markers = make(map[int64]TxnMarker)
+	next, err = applyTxnMarkerRecords(block, 15, txnMarkerKeyPrefix(changefeedID), markers)
+	require.NoError(t, err)
+	require.Equal(t, int64(17), next)
+	require.Equal(t, map[int64]TxnMarker{101: {CommitTs: 6}}, markers)
+}
+
+func TestTxnMarkerMessage(t *testing.T) {
+	t.Parallel()
+
+	partition, key, value, err := NewTxnMarkerMessage(
+		model.DefaultChangeFeedID("test"), 100, TxnMarker{CommitTs: 5})
+	require.NoError(t, err)
+	require.Equal(t, int32(0), partition)
+	require.Equal(t, "default/test/100", string(key))
+	require.JSONEq(t, `{"commit-ts":5}`, string(value))
+
+	detail := TxnMarkerTopicConfig("marker", 3)
+	require.Equal(t, int32(1), detail.NumPartitions)
+	require.Equal(t, "compact", detail.ConfigEntries["cleanup.policy"])
+}
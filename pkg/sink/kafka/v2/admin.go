@@ -205,14 +205,19 @@ func (a *admin) CreateTopic(
 	detail *pkafka.TopicDetail,
 	validateOnly bool,
 ) error {
+	topicConfig := kafka.TopicConfig{
+		Topic:             detail.Name,
+		NumPartitions:     int(detail.NumPartitions),
+		ReplicationFactor: int(detail.ReplicationFactor),
+	}
+	for name, value := range detail.ConfigEntries {
+		topicConfig.ConfigEntries = append(topicConfig.ConfigEntries, kafka.ConfigEntry{
+			ConfigName:  name,
+			ConfigValue: value,
+		})
+	}
 	request := &kafka.CreateTopicsRequest{
-		Topics: []kafka.TopicConfig{
-			{
-				Topic:             detail.Name,
-				NumPartitions:     int(detail.NumPartitions),
-				ReplicationFactor: int(detail.ReplicationFactor),
-			},
-		},
+		Topics:       []kafka.TopicConfig{topicConfig},
 		ValidateOnly: validateOnly,
 	}
 
@@ -157,6 +157,17 @@ func completeSASLConfig(o *pkafka.Options) (sasl.Mechanism, error) {
 		case pkafka.SASLTypeOAuth:
 			return nil, errors.ErrKafkaInvalidConfig.GenWithStack(
 				"OAuth is not yet supported in Kafka sink v2")
+		case pkafka.SASLTypeAWSMSKIAM:
+			p, err := pkafka.NewMSKIAMTokenProvider(context.Background(), &o.SASL.AWSMSKIAM)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			return newMSKIAMMechanism(p), nil
+		case pkafka.SASLTypeEventHubs:
+			return plain.Mechanism{
+				Username: o.SASL.SASLUser,
+				Password: o.SASL.SASLPassword,
+			}, nil
 		}
 	}
 	return nil, nil
@@ -268,6 +279,13 @@ func (f *factory) MetricsCollector(
 	return NewMetricsCollector(f.changefeedID, role, f.writer)
 }
 
+// TxnMarkerReader implements the pkafka.Factory interface,
+// the kafka-go client does not support transactions.
+func (f *factory) TxnMarkerReader(_ context.Context) (pkafka.TxnMarkerReader, error) {
+	return nil, errors.ErrKafkaInvalidConfig.GenWithStack(
+		"exactly-once is not supported by the kafka sink v2")
+}
+
 type syncWriter struct {
 	changefeedID model.ChangeFeedID
 	w            Writer
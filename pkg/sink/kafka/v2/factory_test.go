@@ -203,6 +203,57 @@ func TestCompleteSASLConfig(t *testing.T) {
 	})
 	require.NotNil(t, err)
 	require.Contains(t, err.Error(), "OAuth is not yet supported in Kafka sink v2")
+
+	m, err = completeSASLConfig(&pkafka.Options{
+		SASL: &security.SASL{
+			SASLUser:      security.EventHubsConnectionStringUser,
+			SASLPassword:  "Endpoint=sb://test.servicebus.windows.net/;SharedAccessSignature=sig",
+			SASLMechanism: security.EventHubsMechanism,
+		},
+	})
+	require.NoError(t, err)
+	pm, ok = m.(plain.Mechanism)
+	require.True(t, ok)
+	require.Equal(t, security.EventHubsConnectionStringUser, pm.Username)
+
+	m, err = completeSASLConfig(&pkafka.Options{
+		SASL: &security.SASL{
+			SASLMechanism: security.AWSMSKIAMMechanism,
+			AWSMSKIAM:     security.AWSMSKIAM{Region: "us-east-1"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, pkafka.SASLTypeOAuth, m.Name())
+}
+
+type mockTokenGenerator struct {
+	token string
+	err   error
+}
+
+func (g *mockTokenGenerator) GenerateToken(_ context.Context) (string, error) {
+	return g.token, g.err
+}
+
+func TestMSKIAMMechanism(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := newMSKIAMMechanism(&mockTokenGenerator{token: "token"})
+	sm, ir, err := m.Start(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "n,,\x01auth=Bearer token\x01\x01", string(ir))
+	done, resp, err := sm.Next(ctx, nil)
+	require.NoError(t, err)
+	require.True(t, done)
+	require.Nil(t, resp)
+
+	_, _, err = sm.Next(ctx, []byte(`{"status":"invalid_token"}`))
+	require.ErrorContains(t, err, "invalid_token")
+
+	m = newMSKIAMMechanism(&mockTokenGenerator{err: errors.New("no credentials")})
+	_, _, err = m.Start(ctx)
+	require.ErrorContains(t, err, "no credentials")
 }
 
 func TestSyncWriterSendMessage(t *testing.T) {
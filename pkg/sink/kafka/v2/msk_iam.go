@@ -0,0 +1,58 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v2
+
+import (
+	"context"
+
+	"github.com/pingcap/errors"
+	"github.com/segmentio/kafka-go/sasl"
+)
+
+// tokenGenerator generates the token sent by the SASL/OAUTHBEARER mechanism.
+type tokenGenerator interface {
+	GenerateToken(ctx context.Context) (string, error)
+}
+
+// mskIAMMechanism implements the SASL/OAUTHBEARER mechanism for the AWS MSK IAM
+// authentication, the token is generated for each new connection.
+type mskIAMMechanism struct {
+	generator tokenGenerator
+}
+
+func newMSKIAMMechanism(generator tokenGenerator) sasl.Mechanism {
+	return &mskIAMMechanism{generator: generator}
+}
+
+func (m *mskIAMMechanism) Name() string {
+	return "OAUTHBEARER"
+}
+
+// Start sends the client initial response defined in https://tools.ietf.org/html/rfc7628#section-3.1
+func (m *mskIAMMechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	token, err := m.generator.GenerateToken(ctx)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	return m, []byte("n,,\x01auth=Bearer " + token + "\x01\x01"), nil
+}
+
+// Next completes the authentication, the server only sends a challenge if the
+// authentication fails, which contains the error details.
+func (m *mskIAMMechanism) Next(_ context.Context, challenge []byte) (bool, []byte, error) {
+	if len(challenge) > 0 {
+		return false, nil, errors.Errorf("AWS MSK IAM authentication failed: %s", challenge)
+	}
+	return true, nil, nil
+}
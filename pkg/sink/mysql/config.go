@@ -122,6 +122,9 @@ type Config struct {
 	BatchDMLEnable  bool
 	MultiStmtEnable bool
 	CachePrepStmts  bool
+
+	// ConflictResolution is nil if the conflicts are not detected.
+	ConflictResolution *config.ConflictResolutionConfig
 }
 
 // NewConfig returns the default mysql backend config.
@@ -200,6 +203,10 @@ func (c *Config) Apply(
 	getMultiStmtEnable(urlParameter, &c.MultiStmtEnable)
 	getCachePrepStmts(urlParameter, &c.CachePrepStmts)
 	c.ForceReplicate = replicaConfig.ForceReplicate
+	if replicaConfig.Sink.MySQLConfig != nil &&
+		replicaConfig.Sink.MySQLConfig.ConflictResolution.Enabled() {
+		c.ConflictResolution = replicaConfig.Sink.MySQLConfig.ConflictResolution
+	}
 
 	// Note(dongmen): The TiDBSourceID should never be 0 here, but we have found that
 	// in some problematic cases, the TiDBSourceID is 0 since something went wrong in the
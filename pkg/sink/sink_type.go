@@ -76,12 +76,38 @@ const (
 	PulsarHTTPScheme = "pulsar+http"
 	// PulsarHTTPSScheme indicates the schema is pulsar with https protocol
 	PulsarHTTPSScheme = "pulsar+https"
+	// IcebergS3Scheme indicates the scheme is iceberg tables on s3.
+	IcebergS3Scheme = "iceberg+s3"
+	// IcebergFileScheme indicates the scheme is iceberg tables on local fs or NFS.
+	IcebergFileScheme = "iceberg+file"
+	// IcebergGCSScheme indicates the scheme is iceberg tables on gcs.
+	IcebergGCSScheme = "iceberg+gcs"
+	// IcebergAzblobScheme indicates the scheme is iceberg tables on azure blob storage.
+	IcebergAzblobScheme = "iceberg+azblob"
+	// WebhookScheme indicates the scheme is an http webhook.
+	WebhookScheme = "http"
+	// WebhookSSLScheme indicates the scheme is an https webhook.
+	WebhookSSLScheme = "https"
+	// RedisScheme indicates the scheme is redis.
+	RedisScheme = "redis"
+	// RedisSSLScheme indicates the scheme is redis over TLS.
+	RedisSSLScheme = "rediss"
+	// ElasticsearchScheme indicates the scheme is elasticsearch over http.
+	ElasticsearchScheme = "elasticsearch"
+	// ElasticsearchSSLScheme indicates the scheme is elasticsearch over https.
+	ElasticsearchSSLScheme = "elasticsearch+ssl"
+	// OpenSearchScheme indicates the scheme is opensearch over http.
+	OpenSearchScheme = "opensearch"
+	// OpenSearchSSLScheme indicates the scheme is opensearch over https.
+	OpenSearchSSLScheme = "opensearch+ssl"
+
+	// icebergSchemePrefix is the common prefix of all iceberg schemes.
+	icebergSchemePrefix = "iceberg+"
 )
 
 // IsMQScheme returns true if the scheme belong to mq scheme.
 func IsMQScheme(scheme string) bool {
-	return scheme == KafkaScheme || scheme == KafkaSSLScheme ||
-		scheme == PulsarScheme || scheme == PulsarSSLScheme
+	return scheme == KafkaScheme || scheme == KafkaSSLScheme || IsPulsarScheme(scheme)
 }
 
 // IsMySQLCompatibleScheme returns true if the scheme is compatible with MySQL.
@@ -101,6 +127,35 @@ func IsPulsarScheme(scheme string) bool {
 	return scheme == PulsarScheme || scheme == PulsarSSLScheme || scheme == PulsarHTTPScheme || scheme == PulsarHTTPSScheme
 }
 
+// IsIcebergScheme returns true if the scheme belong to iceberg scheme.
+func IsIcebergScheme(scheme string) bool {
+	return scheme == IcebergS3Scheme || scheme == IcebergFileScheme ||
+		scheme == IcebergGCSScheme || scheme == IcebergAzblobScheme
+}
+
+// GetIcebergStorageScheme returns the scheme of the external storage
+// which holds the iceberg tables, e.g. "s3" for "iceberg+s3".
+func GetIcebergStorageScheme(scheme string) string {
+	return strings.TrimPrefix(scheme, icebergSchemePrefix)
+}
+
+// IsWebhookScheme returns true if the scheme belong to webhook scheme.
+func IsWebhookScheme(scheme string) bool {
+	return scheme == WebhookScheme || scheme == WebhookSSLScheme
+}
+
+// IsRedisScheme returns true if the scheme belong to redis scheme.
+func IsRedisScheme(scheme string) bool {
+	return scheme == RedisScheme || scheme == RedisSSLScheme
+}
+
+// IsElasticsearchScheme returns true if the scheme belong to elasticsearch scheme.
+// OpenSearch shares the same bulk API with elasticsearch.
+func IsElasticsearchScheme(scheme string) bool {
+	return scheme == ElasticsearchScheme || scheme == ElasticsearchSSLScheme ||
+		scheme == OpenSearchScheme || scheme == OpenSearchSSLScheme
+}
+
 // IsBlackHoleScheme returns true if the scheme belong to blackhole scheme.
 func IsBlackHoleScheme(scheme string) bool {
 	return scheme == BlackHoleScheme
@@ -0,0 +1,153 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/cdc/model"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/pingcap/tiflow/pkg/retry"
+	"github.com/pingcap/tiflow/pkg/sink/codec/common"
+	"go.uber.org/zap"
+)
+
+const (
+	// IdempotencyKeyHeader is the header which carries the idempotency key of
+	// the request, the receiver can use it to drop the duplicated requests
+	// caused by retries or the changefeed restarts.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// ContentType is the content type of the request body.
+	ContentType = "application/x-ndjson"
+
+	// maxResponseBodySize is the max size of the response body kept in the error.
+	maxResponseBodySize = 1024
+)
+
+// Client posts the encoded messages to the webhook endpoint.
+type Client struct {
+	changefeedID model.ChangeFeedID
+	config       *Config
+	client       *http.Client
+}
+
+// NewClient creates a webhook client.
+func NewClient(changefeedID model.ChangeFeedID, cfg *Config) *Client {
+	return &Client{
+		changefeedID: changefeedID,
+		config:       cfg,
+		client:       &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Send posts the messages as one request, it retries with backoff until a
+// 2xx response is received or the max retries is reached.
+func (c *Client) Send(ctx context.Context, idempotencyKey string, msgs []*common.Message) error {
+	body := EncodeBody(msgs)
+	return retry.Do(ctx, func() error {
+		err := c.post(ctx, idempotencyKey, body)
+		if err != nil {
+			log.Warn("webhook sink request failed, retrying",
+				zap.String("namespace", c.changefeedID.Namespace),
+				zap.String("changefeed", c.changefeedID.ID),
+				zap.String("idempotencyKey", idempotencyKey),
+				zap.Error(err))
+		}
+		return err
+	}, retry.WithBackoffBaseDelay(c.config.RetryBackoffBaseInMs),
+		retry.WithBackoffMaxDelay(c.config.RetryBackoffMaxInMs),
+		retry.WithMaxTries(uint64(c.config.MaxRetries)+1),
+		retry.WithIsRetryableErr(isRetryableError))
+}
+
+func (c *Client) post(ctx context.Context, idempotencyKey string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.Header.Set("Content-Type", ContentType)
+	req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return cerror.ErrWebhookSinkRequestFailed.Wrap(&statusError{code: resp.StatusCode}).
+		GenWithStackByArgs(resp.StatusCode, string(respBody))
+}
+
+// statusError is the cause of ErrWebhookSinkRequestFailed.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return http.StatusText(e.code)
+}
+
+// Close closes the idle connections of the client.
+func (c *Client) Close() {
+	c.client.CloseIdleConnections()
+}
+
+// isRetryableError returns false if the endpoint rejects the request,
+// which will fail again after retries.
+func isRetryableError(err error) bool {
+	if errors.Cause(err) == context.Canceled {
+		return false
+	}
+	if e, ok := errors.Cause(err).(*statusError); ok {
+		return e.code >= http.StatusInternalServerError ||
+			e.code == http.StatusRequestTimeout || e.code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// EncodeBody concatenates the message values as newline delimited JSON.
+func EncodeBody(msgs []*common.Message) []byte {
+	var buf bytes.Buffer
+	for _, msg := range msgs {
+		buf.Write(msg.Value)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// IdempotencyKey returns the idempotency key of the events in the given table
+// at the given commit ts. The sequence distinguishes the transactions split
+// from the same upstream transaction.
+func IdempotencyKey(table model.TableName, commitTs uint64, seq int) string {
+	return fmt.Sprintf("dml-%s.%s-%d-%d-%d", table.Schema, table.Table, table.TableID, commitTs, seq)
+}
+
+// DDLIdempotencyKey returns the idempotency key of the DDL event.
+func DDLIdempotencyKey(table model.TableName, commitTs uint64) string {
+	return fmt.Sprintf("ddl-%s.%s-%d", table.Schema, table.Table, commitTs)
+}
+
+// CheckpointIdempotencyKey returns the idempotency key of the checkpoint event.
+func CheckpointIdempotencyKey(ts uint64) string {
+	return fmt.Sprintf("checkpoint-%d", ts)
+}
@@ -0,0 +1,41 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	statusErr := func(code int) error {
+		return cerror.ErrWebhookSinkRequestFailed.Wrap(&statusError{code: code}).
+			GenWithStackByArgs(code, "")
+	}
+	require.False(t, isRetryableError(context.Canceled))
+	require.True(t, isRetryableError(errors.New("connection refused")))
+	require.True(t, isRetryableError(statusErr(http.StatusInternalServerError)))
+	require.True(t, isRetryableError(statusErr(http.StatusBadGateway)))
+	require.True(t, isRetryableError(statusErr(http.StatusRequestTimeout)))
+	require.True(t, isRetryableError(statusErr(http.StatusTooManyRequests)))
+	require.False(t, isRetryableError(statusErr(http.StatusBadRequest)))
+	require.False(t, isRetryableError(statusErr(http.StatusUnauthorized)))
+}
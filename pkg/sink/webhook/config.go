@@ -0,0 +1,150 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pingcap/log"
+	"github.com/pingcap/tiflow/pkg/config"
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	psink "github.com/pingcap/tiflow/pkg/sink"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultWorkerCount is the default value of worker-count.
+	defaultWorkerCount = 16
+	// the upper limit of worker-count.
+	maxWorkerCount = 512
+	// defaultTimeout is the default value of timeout.
+	defaultTimeout = 10 * time.Second
+	// defaultMaxRetries is the default value of max-retries.
+	defaultMaxRetries = 10
+	// defaultRetryBackoffBaseInMs is the default value of retry-backoff-base-in-ms.
+	defaultRetryBackoffBaseInMs = 500
+	// defaultRetryBackoffMaxInMs is the default value of retry-backoff-max-in-ms.
+	defaultRetryBackoffMaxInMs = 30 * 1000
+)
+
+type urlConfig struct {
+	WorkerCount          *int    `form:"worker-count"`
+	Timeout              *string `form:"timeout"`
+	MaxRetries           *int    `form:"max-retries"`
+	RetryBackoffBaseInMs *int64  `form:"retry-backoff-base-in-ms"`
+	RetryBackoffMaxInMs  *int64  `form:"retry-backoff-max-in-ms"`
+}
+
+// Config is the configuration for webhook sink.
+type Config struct {
+	// Endpoint is the url which the events are posted to,
+	// it is the sink URI without any query parameters.
+	Endpoint             string
+	WorkerCount          int
+	Timeout              time.Duration
+	MaxRetries           int
+	RetryBackoffBaseInMs int64
+	RetryBackoffMaxInMs  int64
+}
+
+// NewConfig returns the default webhook sink config.
+func NewConfig() *Config {
+	return &Config{
+		WorkerCount:          defaultWorkerCount,
+		Timeout:              defaultTimeout,
+		MaxRetries:           defaultMaxRetries,
+		RetryBackoffBaseInMs: defaultRetryBackoffBaseInMs,
+		RetryBackoffMaxInMs:  defaultRetryBackoffMaxInMs,
+	}
+}
+
+// Apply applies the sink URI parameters to the config.
+func (c *Config) Apply(sinkURI *url.URL) error {
+	if sinkURI == nil {
+		return cerror.ErrWebhookSinkInvalidConfig.GenWithStack(
+			"failed to open webhook sink, empty SinkURI")
+	}
+	scheme := strings.ToLower(sinkURI.Scheme)
+	if !psink.IsWebhookScheme(scheme) {
+		return cerror.ErrWebhookSinkInvalidConfig.GenWithStack(
+			"can't create webhook sink with unsupported scheme: %s", scheme)
+	}
+
+	req := &http.Request{URL: sinkURI}
+	urlParameter := &urlConfig{}
+	if err := binding.Query.Bind(req, urlParameter); err != nil {
+		return cerror.WrapError(cerror.ErrWebhookSinkInvalidConfig, err)
+	}
+
+	if urlParameter.WorkerCount != nil {
+		count := *urlParameter.WorkerCount
+		if count <= 0 {
+			return cerror.WrapError(cerror.ErrWebhookSinkInvalidConfig,
+				fmt.Errorf("invalid worker-count %d, it must be greater than 0", count))
+		}
+		if count > maxWorkerCount {
+			log.Warn("worker-count is too large",
+				zap.Int("original", count), zap.Int("override", maxWorkerCount))
+			count = maxWorkerCount
+		}
+		c.WorkerCount = count
+	}
+	if urlParameter.Timeout != nil && len(*urlParameter.Timeout) > 0 {
+		d, err := time.ParseDuration(*urlParameter.Timeout)
+		if err != nil {
+			return cerror.WrapError(cerror.ErrWebhookSinkInvalidConfig, err)
+		}
+		if d <= 0 {
+			return cerror.WrapError(cerror.ErrWebhookSinkInvalidConfig,
+				fmt.Errorf("invalid timeout %s, it must be greater than 0", d))
+		}
+		c.Timeout = d
+	}
+	if urlParameter.MaxRetries != nil {
+		if *urlParameter.MaxRetries < 0 {
+			return cerror.WrapError(cerror.ErrWebhookSinkInvalidConfig,
+				fmt.Errorf("invalid max-retries %d, it must not be negative", *urlParameter.MaxRetries))
+		}
+		c.MaxRetries = *urlParameter.MaxRetries
+	}
+	if urlParameter.RetryBackoffBaseInMs != nil && *urlParameter.RetryBackoffBaseInMs > 0 {
+		c.RetryBackoffBaseInMs = *urlParameter.RetryBackoffBaseInMs
+	}
+	if urlParameter.RetryBackoffMaxInMs != nil && *urlParameter.RetryBackoffMaxInMs > 0 {
+		c.RetryBackoffMaxInMs = *urlParameter.RetryBackoffMaxInMs
+	}
+	if c.RetryBackoffMaxInMs < c.RetryBackoffBaseInMs {
+		c.RetryBackoffMaxInMs = c.RetryBackoffBaseInMs
+	}
+
+	endpoint := *sinkURI
+	endpoint.RawQuery = ""
+	endpoint.Fragment = ""
+	c.Endpoint = endpoint.String()
+	return nil
+}
+
+// IsSupportedProtocol returns true if the protocol can be used by the webhook sink.
+// Only the protocols whose messages are JSON objects are supported, since the
+// messages are posted as newline delimited JSON.
+func IsSupportedProtocol(protocol config.Protocol) bool {
+	return protocol == config.ProtocolCanalJSON ||
+		protocol == config.ProtocolSimple ||
+		protocol == config.ProtocolDebezium
+}
@@ -0,0 +1,68 @@
+// Copyright 2024 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	cerror "github.com/pingcap/tiflow/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigApply(t *testing.T) {
+	t.Parallel()
+
+	sinkURI, err := url.Parse("https://example.com:8443/cdc/events?protocol=canal-json#ignored")
+	require.NoError(t, err)
+	cfg := NewConfig()
+	require.NoError(t, cfg.Apply(sinkURI))
+	require.Equal(t, "https://example.com:8443/cdc/events", cfg.Endpoint)
+	require.Equal(t, defaultWorkerCount, cfg.WorkerCount)
+	require.Equal(t, defaultTimeout, cfg.Timeout)
+	require.Equal(t, defaultMaxRetries, cfg.MaxRetries)
+	require.Equal(t, int64(defaultRetryBackoffBaseInMs), cfg.RetryBackoffBaseInMs)
+	require.Equal(t, int64(defaultRetryBackoffMaxInMs), cfg.RetryBackoffMaxInMs)
+
+	sinkURI, err = url.Parse("http://127.0.0.1/events?worker-count=1024&timeout=3s" +
+		"&max-retries=0&retry-backoff-base-in-ms=100&retry-backoff-max-in-ms=50")
+	require.NoError(t, err)
+	cfg = NewConfig()
+	require.NoError(t, cfg.Apply(sinkURI))
+	require.Equal(t, maxWorkerCount, cfg.WorkerCount)
+	require.Equal(t, 3*time.Second, cfg.Timeout)
+	require.Equal(t, 0, cfg.MaxRetries)
+	require.Equal(t, int64(100), cfg.RetryBackoffBaseInMs)
+	require.Equal(t, int64(100), cfg.RetryBackoffMaxInMs)
+}
+
+func TestConfigApplyInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, uri := range []string{
+		"kafka://127.0.0.1:9092/topic",
+		"http://127.0.0.1/events?worker-count=0",
+		"http://127.0.0.1/events?worker-count=abc",
+		"http://127.0.0.1/events?timeout=abc",
+		"http://127.0.0.1/events?timeout=-1s",
+		"http://127.0.0.1/events?max-retries=-1",
+	} {
+		sinkURI, err := url.Parse(uri)
+		require.NoError(t, err)
+		err = NewConfig().Apply(sinkURI)
+		require.ErrorIs(t, err, cerror.ErrWebhookSinkInvalidConfig, uri)
+	}
+	require.ErrorIs(t, NewConfig().Apply(nil), cerror.ErrWebhookSinkInvalidConfig)
+}
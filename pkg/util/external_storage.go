@@ -185,13 +185,30 @@ func (s *extStorageWithTimeout) DeleteFile(ctx context.Context, name string) err
 	return s.ExternalStorage.DeleteFile(ctx, name)
 }
 
-// Open a Reader by file path. path is relative path to storage base path
+// Open a Reader by file path. path is relative path to storage base path.
+// The timeout covers reading the whole file, so the context is canceled
+// when the reader is closed.
 func (s *extStorageWithTimeout) Open(
-	ctx context.Context, path string, _ *storage.ReaderOption,
+	ctx context.Context, path string, option *storage.ReaderOption,
 ) (storage.ExternalFileReader, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
-	defer cancel()
-	return s.ExternalStorage.Open(ctx, path, nil)
+	r, err := s.ExternalStorage.Open(ctx, path, option)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &fileReaderWithCancel{ExternalFileReader: r, cancel: cancel}, nil
+}
+
+type fileReaderWithCancel struct {
+	storage.ExternalFileReader
+	cancel context.CancelFunc
+}
+
+// Close closes the reader and cancels the context used to open it.
+func (r *fileReaderWithCancel) Close() error {
+	defer r.cancel()
+	return r.ExternalFileReader.Close()
 }
 
 // WalkDir traverse all the files in a dir.
@@ -56,6 +56,7 @@ done
 generate ./proto/canal ./proto/EntryProtocol.proto
 generate ./proto/canal ./proto/CanalProtocol.proto
 generate ./proto/benchmark ./proto/CraftBenchmark.proto
+generate ./proto/simple ./proto/SimpleProtocol.proto
 generate ./proto/p2p ./proto/CDCPeerToPeer.proto plugins=grpc
 generate ./dm/pb ./dm/proto/dmworker.proto plugins=grpc,protoc-gen-grpc-gateway="$GRPC_GATEWAY"
 generate ./dm/pb ./dm/proto/dmmaster.proto plugins=grpc,protoc-gen-grpc-gateway="$GRPC_GATEWAY"